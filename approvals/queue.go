@@ -0,0 +1,200 @@
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotPending is returned when resolving a request that is not waiting
+// for a decision, because it was never queued, was already decided, or
+// timed out.
+var ErrNotPending = errors.New("approval request is not pending")
+
+// Pending is a queued request waiting for a decision.
+type Pending struct {
+	Request
+
+	once     sync.Once
+	response chan Response
+}
+
+// Resolve decides the request and reports whether this was the first
+// decision.
+func (p *Pending) Resolve(resp Response) bool {
+	resolved := false
+	p.once.Do(func() {
+		p.response <- resp
+		resolved = true
+	})
+	return resolved
+}
+
+// Approve approves the request.
+func (p *Pending) Approve(reason string) bool {
+	return p.Resolve(Response{Approved: true, Reason: reason})
+}
+
+// Reject rejects the request.
+func (p *Pending) Reject(reason string) bool {
+	return p.Resolve(Response{Reason: reason})
+}
+
+// Queue is an Approver that queues requests for a person or another
+// system to decide, on a channel or over HTTP. It is safe for concurrent
+// use.
+type Queue struct {
+	requests chan *Pending
+
+	mu      sync.Mutex
+	pending map[string]*Pending
+}
+
+// NewQueue returns a Queue whose Requests channel buffers size requests.
+func NewQueue(size int) *Queue {
+	return &Queue{
+		requests: make(chan *Pending, size),
+		pending:  make(map[string]*Pending),
+	}
+}
+
+// Requests returns the channel on which queued requests are delivered. A
+// request that does not fit in the channel's buffer is still pending, and
+// can be found with Pending and decided with Resolve or the Handler.
+func (q *Queue) Requests() <-chan *Pending {
+	return q.requests
+}
+
+// Approve queues req and waits for its decision.
+func (q *Queue) Approve(ctx context.Context, req Request) (Response, error) {
+	p := &Pending{Request: req, response: make(chan Response, 1)}
+
+	q.mu.Lock()
+	q.pending[req.ID] = p
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		if q.pending[req.ID] == p {
+			delete(q.pending, req.ID)
+		}
+		q.mu.Unlock()
+	}()
+
+	select {
+	case q.requests <- p:
+	default:
+	}
+
+	select {
+	case resp := <-p.response:
+		return resp, nil
+	case <-ctx.Done():
+		// A decision made now is too late
+		p.once.Do(func() {})
+		return Response{}, ctx.Err()
+	}
+}
+
+// Pending returns the requests waiting for a decision, oldest first.
+func (q *Queue) Pending() []Request {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	requests := make([]Request, 0, len(q.pending))
+	for _, p := range q.pending {
+		requests = append(requests, p.Request)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].Time.Before(requests[j].Time) })
+	return requests
+}
+
+// Resolve decides the pending request with the given ID.
+func (q *Queue) Resolve(id string, resp Response) error {
+	q.mu.Lock()
+	p, ok := q.pending[id]
+	q.mu.Unlock()
+
+	if !ok || !p.Resolve(resp) {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// maxResponseSize limits the size of a Response posted to the Handler.
+const maxResponseSize = 1 << 20
+
+// Handler returns an HTTP handler for deciding requests from another
+// system, such as a chat bot or a review page. GET lists the pending
+// requests as JSON. POST with an "id" query parameter and a Response as
+// the JSON body decides one, and answers 404 if it is not pending.
+//
+// Approving a call can run any command on the host, so every request is
+// first passed to authorize, which returns the identity of the caller or
+// an error that rejects the request with 401 Unauthorized. The identity is
+// recorded as the Response's Approver, replacing any in the body.
+// Cross-origin requests and bodies that are not application/json are
+// rejected, so web pages cannot decide calls from a browser. Handler
+// panics if authorize is nil.
+func (q *Queue) Handler(authorize func(r *http.Request) (approver string, err error)) http.Handler {
+	if authorize == nil {
+		panic("approvals: Handler requires an authorize function")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkOrigin(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		approver, err := authorize(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(q.Pending())
+
+		case http.MethodPost:
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+			var resp Response
+			body := http.MaxBytesReader(w, r.Body, maxResponseSize)
+			if err := json.NewDecoder(body).Decode(&resp); err != nil {
+				http.Error(w, "invalid response: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			resp.Approver = approver
+			if err := q.Resolve(r.URL.Query().Get("id"), resp); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// checkOrigin rejects requests sent by a browser from another origin.
+func checkOrigin(r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			return fmt.Errorf("cross-origin request from %q", origin)
+		}
+	}
+	return nil
+}
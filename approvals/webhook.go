@@ -0,0 +1,71 @@
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Webhook is an Approver that posts each Request as JSON to a URL and
+// takes the Response from the reply's JSON body. The endpoint may hold
+// the request open until someone decides; the Gate's timeout bounds the
+// wait.
+//
+// For endpoints that only take notifications, use a Queue instead: post
+// from its Requests channel and decide through its Handler.
+type Webhook struct {
+	url string
+
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Header is added to every request, for example to authenticate it.
+	Header http.Header
+}
+
+// NewWebhook returns an Approver posting requests to url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, Header: make(http.Header)}
+}
+
+// Approve posts req and returns the decision in the reply. Replies other
+// than 2xx are errors, which reject the call.
+func (w *Webhook) Approve(ctx context.Context, req Request) (Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to marshal approval request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to create approval request: %w", err)
+	}
+	for key, values := range w.Header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to post approval request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		io.Copy(io.Discard, httpResp.Body)
+		return Response{}, fmt.Errorf("approval webhook returned %s", httpResp.Status)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("failed to decode approval response: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,355 @@
+// Package audit records every tool Claude calls, with its input, the
+// session it ran in, and the decision of any permission hook, to a
+// pluggable Sink such as a JSON Lines file, a webhook, or a SQL database.
+//
+//	logger := audit.New(audit.NewFileSink("audit.jsonl"))
+//	defer logger.Close()
+//
+//	stream, err := client.Query(ctx, prompt, logger.Instrument(options))
+//	for msg := range logger.Tee(stream.Messages()) {
+//		// ...
+//	}
+//
+// A tool call is written once its result arrives, or when the Logger is
+// closed if it never does.
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Decision is the permission decision made for a tool call by a PreToolUse
+// hook.
+type Decision string
+
+// Permission decisions. A Record without a decision was left to the CLI's
+// permission settings.
+const (
+	DecisionAllow Decision = "allow"
+	DecisionDeny  Decision = "deny"
+	DecisionAsk   Decision = "ask"
+)
+
+// Record is the audit entry for one tool call.
+type Record struct {
+	// Time is when the tool call was seen.
+	Time      time.Time      `json:"time"`
+	SessionID string         `json:"session_id,omitempty"`
+	ToolUseID string         `json:"tool_use_id"`
+	Tool      string         `json:"tool"`
+	Input     map[string]any `json:"input,omitempty"`
+
+	// Decision and Reason are those of the PreToolUse hooks, if any decided.
+	Decision Decision `json:"decision,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+
+	// Completed reports whether the tool's result arrived, and IsError
+	// whether it was an error, as it is for denied calls.
+	Completed bool          `json:"completed"`
+	IsError   bool          `json:"is_error,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+}
+
+// Sink stores audit records. Write may be called from several goroutines.
+// Sinks that are also io.Closers are closed by Logger.Close.
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+// decision is a decision made by a hook before its tool call was seen.
+type decision struct {
+	decision  Decision
+	reason    string
+	sessionID string
+}
+
+// Logger collects tool calls from messages and hook decisions and writes
+// them to a Sink. It is safe for concurrent use, and one Logger may audit
+// several queries.
+type Logger struct {
+	sink Sink
+
+	// OnError, if set, is called with each error returned by the sink.
+	// Errors are also kept for Err and Close.
+	OnError func(err error)
+
+	mu        sync.Mutex
+	pending   map[string]*Record
+	decisions map[string]decision
+	sessionID string
+	err       error
+}
+
+// New returns a Logger writing to sink.
+func New(sink Sink) *Logger {
+	return &Logger{
+		sink:      sink,
+		pending:   make(map[string]*Record),
+		decisions: make(map[string]decision),
+	}
+}
+
+// Instrument returns a copy of options whose PreToolUse hooks also report
+// their decisions to the Logger. Options without PreToolUse hooks are
+// returned as a copy with no change, since the CLI's permission settings
+// decide their tool calls.
+func (l *Logger) Instrument(options *types.Options) *types.Options {
+	if options == nil {
+		return types.NewOptions()
+	}
+	options = options.Clone()
+	matchers := options.Hooks[types.HookEventPreToolUse]
+	if len(matchers) == 0 {
+		return options
+	}
+
+	wrapped := make([]types.HookMatcher, len(matchers))
+	for i, matcher := range matchers {
+		hooks := make([]types.HookCallback, len(matcher.Hooks))
+		for j, hook := range matcher.Hooks {
+			hooks[j] = l.wrapHook(hook)
+		}
+		wrapped[i] = types.HookMatcher{Matcher: matcher.Matcher, Hooks: hooks}
+	}
+
+	hooks := make(map[types.HookEvent][]types.HookMatcher, len(options.Hooks))
+	for event, matchers := range options.Hooks {
+		hooks[event] = matchers
+	}
+	hooks[types.HookEventPreToolUse] = wrapped
+	options.Hooks = hooks
+	return options
+}
+
+// wrapHook returns a hook callback recording the decision of hook.
+func (l *Logger) wrapHook(hook types.HookCallback) types.HookCallback {
+	return func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		output, err := hook(ctx, input, toolUseID)
+		if err == nil && toolUseID != "" {
+			if d, reason, ok := hookDecision(output); ok {
+				l.decide(toolUseID, decision{decision: d, reason: reason, sessionID: input.SessionID})
+			}
+		}
+		return output, err
+	}
+}
+
+// hookDecision returns the permission decision in a PreToolUse hook's
+// output, including the older "approve" and "block" decisions.
+func hookDecision(output types.HookOutput) (Decision, string, bool) {
+	if d, ok := output.HookSpecificOutput["permissionDecision"].(string); ok && d != "" {
+		reason, _ := output.HookSpecificOutput["permissionDecisionReason"].(string)
+		return Decision(d), reason, true
+	}
+	switch output.Decision {
+	case "approve":
+		return DecisionAllow, output.Reason, true
+	case "block":
+		return DecisionDeny, output.Reason, true
+	}
+	return "", "", false
+}
+
+// precedence orders decisions as the CLI applies them when several hooks
+// decide on one tool call: a deny wins over an ask, and an ask over an
+// allow.
+func precedence(d Decision) int {
+	switch d {
+	case DecisionDeny:
+		return 3
+	case DecisionAsk:
+		return 2
+	case DecisionAllow:
+		return 1
+	}
+	return 0
+}
+
+// decide records a hook's decision for a tool call.
+func (l *Logger) decide(toolUseID string, d decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.pending[toolUseID]; ok {
+		if precedence(d.decision) > precedence(r.Decision) {
+			r.Decision, r.Reason = d.decision, d.reason
+		}
+		if r.SessionID == "" {
+			r.SessionID = d.sessionID
+		}
+		return
+	}
+	if prev, ok := l.decisions[toolUseID]; !ok || precedence(d.decision) > precedence(prev.decision) {
+		l.decisions[toolUseID] = d
+	}
+}
+
+// Observe records the tool calls and results in msg. It tracks the session
+// ID of a single stream; use Tee to audit several streams at once.
+func (l *Logger) Observe(msg types.Message) {
+	l.mu.Lock()
+	sessionID := l.sessionID
+	l.mu.Unlock()
+
+	l.observe(msg, &sessionID)
+
+	l.mu.Lock()
+	l.sessionID = sessionID
+	l.mu.Unlock()
+}
+
+// Tee records the tool calls in every message received from messages and
+// forwards it on the returned channel, which is closed once messages is.
+// The returned channel must be drained.
+func (l *Logger) Tee(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		var sessionID string
+		for msg := range messages {
+			l.observe(msg, &sessionID)
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// observe records the tool calls and results in msg, tracking the session
+// ID of its stream in sessionID.
+func (l *Logger) observe(msg types.Message, sessionID *string) {
+	switch m := msg.(type) {
+	case *types.SystemMessage:
+		if info, ok := m.Init(); ok && info.SessionID != "" {
+			*sessionID = info.SessionID
+		}
+
+	case *types.ResultMessage:
+		if m.SessionID != "" {
+			*sessionID = m.SessionID
+		}
+
+	case *types.AssistantMessage:
+		now := time.Now()
+		l.mu.Lock()
+		for _, block := range m.Content {
+			if use, ok := block.(*types.ToolUseBlock); ok {
+				l.start(use, *sessionID, now)
+			}
+		}
+		l.mu.Unlock()
+
+	case *types.UserMessage:
+		for _, block := range m.Blocks {
+			if result, ok := block.(*types.ToolResultBlock); ok {
+				l.finish(result)
+			}
+		}
+	}
+}
+
+// start adds a pending record for a tool call. l.mu must be held.
+func (l *Logger) start(use *types.ToolUseBlock, sessionID string, now time.Time) {
+	if _, ok := l.pending[use.ID]; ok {
+		return
+	}
+	r := &Record{
+		Time:      now,
+		SessionID: sessionID,
+		ToolUseID: use.ID,
+		Tool:      use.Name,
+		Input:     use.Input,
+	}
+	if d, ok := l.decisions[use.ID]; ok {
+		delete(l.decisions, use.ID)
+		r.Decision, r.Reason = d.decision, d.reason
+		if r.SessionID == "" {
+			r.SessionID = d.sessionID
+		}
+	}
+	l.pending[use.ID] = r
+}
+
+// finish writes the record of the tool call a result belongs to.
+func (l *Logger) finish(result *types.ToolResultBlock) {
+	l.mu.Lock()
+	r, ok := l.pending[result.ToolUseID]
+	delete(l.pending, result.ToolUseID)
+	l.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	r.Completed = true
+	r.IsError = result.IsError != nil && *result.IsError
+	r.Duration = time.Since(r.Time)
+	l.write(*r)
+}
+
+// write sends a record to the sink.
+func (l *Logger) write(r Record) {
+	if err := l.sink.Write(context.Background(), r); err != nil {
+		l.fail(err)
+	}
+}
+
+// fail keeps err and passes it to OnError.
+func (l *Logger) fail(err error) {
+	l.mu.Lock()
+	l.err = errors.Join(l.err, err)
+	l.mu.Unlock()
+	if l.OnError != nil {
+		l.OnError(err)
+	}
+}
+
+// Err returns the errors the sink has returned so far, joined.
+func (l *Logger) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Flush writes the records of tool calls whose results have not arrived,
+// with Completed false, in the order the calls were seen. Decisions for
+// tool calls that were never seen are dropped.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	pending := make([]Record, 0, len(l.pending))
+	for _, r := range l.pending {
+		pending = append(pending, *r)
+	}
+	clear(l.pending)
+	clear(l.decisions)
+	l.mu.Unlock()
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Time.Before(pending[j].Time) })
+
+	var errs []error
+	for _, r := range pending {
+		if err := l.sink.Write(context.Background(), r); err != nil {
+			l.fail(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes pending records, closes the sink if it is an io.Closer,
+// and returns any error the sink returned.
+func (l *Logger) Close() error {
+	l.Flush()
+	if closer, ok := l.sink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			l.fail(err)
+		}
+	}
+	return l.Err()
+}
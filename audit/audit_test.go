@@ -0,0 +1,294 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// memorySink keeps the records written to it.
+type memorySink struct {
+	mu      sync.Mutex
+	records []Record
+	err     error
+}
+
+func (s *memorySink) Write(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+func toolUse(id, name string, input map[string]any) *types.AssistantMessage {
+	return &types.AssistantMessage{Content: []types.ContentBlock{
+		&types.TextBlock{Text: "Running a tool"},
+		&types.ToolUseBlock{ID: id, Name: name, Input: input},
+	}}
+}
+
+func toolResult(id string, isError bool) *types.UserMessage {
+	return &types.UserMessage{Blocks: []types.ContentBlock{
+		&types.ToolResultBlock{ToolUseID: id, IsError: &isError},
+	}}
+}
+
+func TestLogger(t *testing.T) {
+	sink := &memorySink{}
+	logger := New(sink)
+
+	options := types.NewOptions().AddHook(types.HookEventPreToolUse, "Bash",
+		func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+			return types.PreToolUseOutput("deny", "no shell", nil), nil
+		})
+	instrumented := logger.Instrument(options)
+	hook := instrumented.Hooks[types.HookEventPreToolUse][0].Hooks[0]
+
+	init := &types.SystemMessage{Subtype: types.SystemSubtypeInit, Data: map[string]any{"session_id": "s1"}}
+	stream := []types.Message{
+		init,
+		toolUse("t1", "Read", map[string]any{"file_path": "a.go"}),
+		toolResult("t1", false),
+		toolUse("t2", "Bash", map[string]any{"command": "rm -rf /"}),
+		toolUse("t3", "Grep", nil),
+	}
+
+	// The hook decides before its tool call reaches the stream
+	if _, err := hook(context.Background(), types.HookInput{SessionID: "s1"}, "t2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range stream {
+		logger.Observe(msg)
+	}
+	logger.Observe(toolResult("t2", true))
+
+	if len(sink.records) != 2 {
+		t.Fatalf("Expected 2 completed records, got %d", len(sink.records))
+	}
+	read, bash := sink.records[0], sink.records[1]
+	if read.Tool != "Read" || read.SessionID != "s1" || read.Input["file_path"] != "a.go" || !read.Completed || read.Decision != "" {
+		t.Errorf("Unexpected record for the allowed call: %+v", read)
+	}
+	if bash.Decision != DecisionDeny || bash.Reason != "no shell" || !bash.IsError || bash.ToolUseID != "t2" {
+		t.Errorf("Expected the hook's denial in the record, got %+v", bash)
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 3 || sink.records[2].Tool != "Grep" || sink.records[2].Completed {
+		t.Errorf("Expected Close to write the unfinished call, got %+v", sink.records)
+	}
+	if options.Hooks[types.HookEventPreToolUse][0].Hooks[0] == nil || len(options.Hooks) != 1 {
+		t.Error("Expected the original options to be kept")
+	}
+}
+
+func TestDecisionPrecedence(t *testing.T) {
+	sink := &memorySink{}
+	logger := New(sink)
+	logger.Observe(toolUse("t1", "Edit", nil))
+
+	allow := func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		return types.PreToolUseOutput("allow", "", nil), nil
+	}
+	block := func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		return types.HookOutput{Decision: "block", Reason: "legacy"}, nil
+	}
+	options := logger.Instrument(types.NewOptions().
+		AddHook(types.HookEventPreToolUse, "", block).
+		AddHook(types.HookEventPreToolUse, "Edit", allow))
+
+	for _, matcher := range options.Hooks[types.HookEventPreToolUse] {
+		matcher.Hooks[0](context.Background(), types.HookInput{}, "t1")
+	}
+	logger.Observe(toolResult("t1", true))
+
+	if len(sink.records) != 1 || sink.records[0].Decision != DecisionDeny || sink.records[0].Reason != "legacy" {
+		t.Errorf("Expected a block to win over an allow, got %+v", sink.records)
+	}
+}
+
+func TestTee(t *testing.T) {
+	sink := &memorySink{}
+	logger := New(sink)
+
+	in := make(chan types.Message, 4)
+	in <- &types.ResultMessage{SessionID: "s2"}
+	in <- toolUse("t1", "Glob", nil)
+	in <- toolResult("t1", false)
+	close(in)
+
+	count := 0
+	for range logger.Tee(in) {
+		count++
+	}
+	if count != 3 {
+		t.Errorf("Expected every message to be forwarded, got %d", count)
+	}
+	if len(sink.records) != 1 || sink.records[0].SessionID != "s2" {
+		t.Errorf("Expected one record in session s2, got %+v", sink.records)
+	}
+}
+
+func TestSinkErrors(t *testing.T) {
+	failure := errors.New("disk full")
+	var reported []error
+	logger := New(&memorySink{err: failure})
+	logger.OnError = func(err error) { reported = append(reported, err) }
+
+	logger.Observe(toolUse("t1", "Read", nil))
+	logger.Observe(toolResult("t1", false))
+
+	if len(reported) != 1 || !errors.Is(logger.Err(), failure) || !errors.Is(logger.Close(), failure) {
+		t.Errorf("Expected the sink's error to be reported, got %v", reported)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink := NewFileSink(path)
+	logger := New(sink)
+
+	logger.Observe(toolUse("t1", "Read", map[string]any{"file_path": "a.go"}))
+	logger.Observe(toolResult("t1", false))
+	logger.Observe(toolUse("t2", "Write", nil))
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", data)
+	}
+	var record Record
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Tool != "Read" || record.Input["file_path"] != "a.go" || !record.Completed {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Record
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Tool == "Fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	sink.Header.Set("Authorization", "Bearer token")
+
+	if err := sink.Write(context.Background(), Record{ToolUseID: "t1", Tool: "Bash"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.ToolUseID != "t1" || auth != "Bearer token" {
+		t.Errorf("Expected the record and header to be posted, got %+v and %q", got, auth)
+	}
+	if err := sink.Write(context.Background(), Record{Tool: "Fail"}); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected an error for a 503 response, got %v", err)
+	}
+}
+
+// execDriver is a database/sql driver recording the statements executed.
+type execDriver struct {
+	mu    sync.Mutex
+	execs []string
+	args  [][]driver.Value
+}
+
+func (d *execDriver) Open(name string) (driver.Conn, error) { return &execConn{d}, nil }
+
+type execConn struct{ d *execDriver }
+
+func (c *execConn) Prepare(query string) (driver.Stmt, error) { return &execStmt{c.d, query}, nil }
+func (c *execConn) Close() error                              { return nil }
+func (c *execConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type execStmt struct {
+	d     *execDriver
+	query string
+}
+
+func (s *execStmt) Close() error  { return nil }
+func (s *execStmt) NumInput() int { return -1 }
+func (s *execStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, s.query)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *execStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestSQLSink(t *testing.T) {
+	d := &execDriver{}
+	sql.Register("audit_exec", d)
+	db, err := sql.Open("audit_exec", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := NewSQLSink(db, "audit; DROP TABLE users", nil); err == nil {
+		t.Error("Expected an invalid table name to be rejected")
+	}
+
+	sink, err := NewSQLSink(db, "tool_audit", func(n int) string { return "$" + strconv.Itoa(n) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.CreateTable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	record := Record{ToolUseID: "t1", Tool: "Bash", Input: map[string]any{"command": "ls"}, Decision: DecisionAllow, Completed: true}
+	if err := sink.Write(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.execs) != 2 || !strings.HasPrefix(d.execs[0], "CREATE TABLE IF NOT EXISTS tool_audit") {
+		t.Fatalf("Expected a create and an insert, got %q", d.execs)
+	}
+	if !strings.Contains(d.execs[1], "INSERT INTO tool_audit") || !strings.Contains(d.execs[1], "$1, $2") {
+		t.Errorf("Unexpected insert: %s", d.execs[1])
+	}
+	args := d.args[1]
+	if len(args) != 10 || args[3] != "Bash" || args[4] != `{"command":"ls"}` || args[5] != "allow" || args[7] != true {
+		t.Errorf("Unexpected insert arguments: %v", args)
+	}
+}
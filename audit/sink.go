@@ -0,0 +1,229 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+)
+
+// WriterSink writes records to an io.Writer as JSON Lines.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing one JSON object per line to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write writes record as one line.
+func (s *WriterSink) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// FileSink appends records to a file as JSON Lines, syncing each to disk
+// before Write returns.
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink returns a sink appending to the file at path, which is
+// created with mode 0600 on the first write if it does not exist.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Path returns the path of the file.
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+// Write appends record to the file.
+func (s *FileSink) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		s.file = file
+	}
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file. A later Write opens it again.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// WebhookSink posts each record as a JSON object to a URL.
+type WebhookSink struct {
+	url string
+
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Header is added to every request, for example to authenticate it.
+	Header http.Header
+}
+
+// NewWebhookSink returns a sink posting records to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, Header: make(http.Header)}
+}
+
+// Write posts record. Responses other than 2xx are errors.
+func (s *WebhookSink) Write(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create audit request: %w", err)
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post audit record: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// tableName matches the table names SQLSink accepts.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLSink inserts records into a table of a database/sql database, such as
+// SQLite or PostgreSQL. The caller registers the driver and owns db.
+//
+// Input is stored as JSON text, Time as a time.Time, and Duration in
+// nanoseconds.
+type SQLSink struct {
+	db     *sql.DB
+	table  string
+	insert string
+}
+
+// NewSQLSink returns a sink inserting into table. Placeholder returns the
+// driver's placeholder for the nth (1-based) argument; if nil, "?" is used,
+// as SQLite and MySQL expect. For PostgreSQL, pass a function returning
+// "$n".
+func NewSQLSink(db *sql.DB, table string, placeholder func(n int) string) (*SQLSink, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit table name: %q", table)
+	}
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+
+	var args bytes.Buffer
+	for n := 1; n <= 10; n++ {
+		if n > 1 {
+			args.WriteString(", ")
+		}
+		args.WriteString(placeholder(n))
+	}
+	return &SQLSink{
+		db:    db,
+		table: table,
+		insert: "INSERT INTO " + table + " (time, session_id, tool_use_id, tool, input, decision, reason, completed, is_error, duration_ns) " +
+			"VALUES (" + args.String() + ")",
+	}, nil
+}
+
+// CreateTable creates the sink's table if it does not exist.
+func (s *SQLSink) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+" ("+
+		"time TIMESTAMP NOT NULL, "+
+		"session_id TEXT, "+
+		"tool_use_id TEXT NOT NULL, "+
+		"tool TEXT NOT NULL, "+
+		"input TEXT, "+
+		"decision TEXT, "+
+		"reason TEXT, "+
+		"completed BOOLEAN NOT NULL, "+
+		"is_error BOOLEAN NOT NULL, "+
+		"duration_ns BIGINT NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create audit table: %w", err)
+	}
+	return nil
+}
+
+// Write inserts record.
+func (s *SQLSink) Write(ctx context.Context, record Record) error {
+	var input any
+	if record.Input != nil {
+		data, err := json.Marshal(record.Input)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tool input: %w", err)
+		}
+		input = string(data)
+	}
+
+	_, err := s.db.ExecContext(ctx, s.insert,
+		record.Time, record.SessionID, record.ToolUseID, record.Tool, input,
+		string(record.Decision), record.Reason, record.Completed, record.IsError,
+		int64(record.Duration))
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
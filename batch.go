@@ -0,0 +1,152 @@
+package claudecode
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchParallelism is the number of queries QueryBatch runs at once
+// when BatchOptions.Parallelism is not set.
+const DefaultBatchParallelism = 4
+
+// BatchItem is one query in a batch.
+type BatchItem struct {
+	// Prompt is the prompt to send.
+	Prompt string
+
+	// Options configures the query. It may be nil for defaults.
+	Options *Options
+}
+
+// BatchResult is the outcome of one query in a batch.
+type BatchResult struct {
+	// Result holds what was collected from the query, which may be partial
+	// when Err is set.
+	Result *QueryResult
+
+	// Err is the reason the query failed, if it did.
+	Err error
+}
+
+// BatchProgress reports the completion of one query in a batch.
+type BatchProgress struct {
+	// Index is the position of the completed item in the batch.
+	Index int
+
+	// Completed is the number of items finished so far, including this one.
+	Completed int
+
+	// Total is the number of items in the batch.
+	Total int
+
+	// Err is the item's error, if it failed.
+	Err error
+}
+
+// BatchOptions configures QueryBatch.
+type BatchOptions struct {
+	// Parallelism is the maximum number of queries running at once. If
+	// zero, DefaultBatchParallelism is used.
+	Parallelism int
+
+	// OnProgress, if set, is called as each item completes. Calls are made
+	// one at a time, so the callback needs no locking of its own.
+	OnProgress func(BatchProgress)
+
+	// StopOnError skips items that have not started once any item fails,
+	// and cancels those still running. Skipped items report
+	// context.Canceled.
+	StopOnError bool
+}
+
+// QueryBatch runs many prompts concurrently with bounded parallelism and
+// returns their results in the same order as items. Each query runs to
+// completion as with QuerySync; failures are reported per item rather than
+// stopping the batch.
+//
+// Example:
+//
+//	items := []claudecode.BatchItem{
+//		{Prompt: "Classify: 'great product'"},
+//		{Prompt: "Classify: 'arrived broken'"},
+//	}
+//	results := claudecode.QueryBatch(ctx, items, claudecode.BatchOptions{
+//		Parallelism: 8,
+//		OnProgress: func(p claudecode.BatchProgress) {
+//			log.Printf("%d/%d done", p.Completed, p.Total)
+//		},
+//	})
+//	for i, r := range results {
+//		if r.Err != nil {
+//			log.Printf("item %d failed: %v", i, r.Err)
+//			continue
+//		}
+//		fmt.Println(r.Result.Text)
+//	}
+func QueryBatch(ctx context.Context, items []BatchItem, opts BatchOptions) []BatchResult {
+	return defaultClient.QueryBatch(ctx, items, opts)
+}
+
+// QueryBatch runs many prompts concurrently with this client.
+// See the package-level QueryBatch for details.
+func (c *Client) QueryBatch(ctx context.Context, items []BatchItem, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(items))
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultBatchParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progressMu sync.Mutex
+	completed := 0
+	finish := func(index int, err error) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		completed++
+		if err != nil && opts.StopOnError {
+			cancel()
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(BatchProgress{Index: index, Completed: completed, Total: len(items), Err: err})
+		}
+	}
+
+	skip := func(index int) {
+		results[index] = BatchResult{Err: ctx.Err()}
+		finish(index, ctx.Err())
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			skip(i)
+			continue
+		}
+
+		if ctx.Err() != nil {
+			<-sem
+			skip(i)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := c.QuerySync(ctx, item.Prompt, item.Options)
+			results[i] = BatchResult{Result: result, Err: err}
+			finish(i, err)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,105 @@
+package claudecode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeEchoCLI writes a fake CLI that answers with its prompt, or fails
+// when the prompt is "fail".
+func writeEchoCLI(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	script := `#!/bin/sh
+for arg; do prompt="$arg"; done
+if [ "$prompt" = "fail" ]; then
+	echo "something broke" >&2
+	exit 1
+fi
+sleep 0.05
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$prompt"'"}]}}'
+echo '{"type":"result","subtype":"success","session_id":"s"}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cliPath
+}
+
+func TestQueryBatch(t *testing.T) {
+	client := NewClient(ClientOptions{CLIPath: writeEchoCLI(t)})
+
+	items := []BatchItem{{Prompt: "one"}, {Prompt: "fail"}, {Prompt: "three"}, {Prompt: "four"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var progress []BatchProgress
+	results := client.QueryBatch(ctx, items, BatchOptions{
+		Parallelism: 2,
+		OnProgress: func(p BatchProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p)
+		},
+	})
+
+	if len(results) != len(items) {
+		t.Fatalf("Expected %d results, got %d", len(items), len(results))
+	}
+	for i, item := range items {
+		if item.Prompt == "fail" {
+			if results[i].Err == nil {
+				t.Errorf("Expected item %d to fail", i)
+			}
+			continue
+		}
+		if results[i].Err != nil {
+			t.Errorf("Item %d failed: %v", i, results[i].Err)
+			continue
+		}
+		if results[i].Result.Text != item.Prompt {
+			t.Errorf("Expected item %d text %q, got %q", i, item.Prompt, results[i].Result.Text)
+		}
+	}
+
+	if len(progress) != len(items) {
+		t.Fatalf("Expected %d progress reports, got %d", len(items), len(progress))
+	}
+	for i, p := range progress {
+		if p.Completed != i+1 || p.Total != len(items) {
+			t.Errorf("Unexpected progress report %d: %+v", i, p)
+		}
+	}
+}
+
+func TestQueryBatchStopOnError(t *testing.T) {
+	client := NewClient(ClientOptions{CLIPath: writeEchoCLI(t)})
+
+	items := []BatchItem{{Prompt: "fail"}, {Prompt: "two"}, {Prompt: "three"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results := client.QueryBatch(ctx, items, BatchOptions{Parallelism: 1, StopOnError: true})
+
+	if results[0].Err == nil {
+		t.Error("Expected first item to fail")
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Err == nil || !strings.Contains(results[i].Err.Error(), "canceled") {
+			t.Errorf("Expected item %d to be skipped, got %v", i, results[i].Err)
+		}
+	}
+}
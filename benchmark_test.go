@@ -67,7 +67,7 @@ func BenchmarkContentBlockTypeChecking(b *testing.B) {
 	blocks := []ContentBlock{
 		&TextBlock{Text: "test content"},
 		&ToolUseBlock{ID: "1", Name: "test", Input: map[string]any{"key": "value"}},
-		&ToolResultBlock{ToolUseID: "1", Content: stringPtr("result")},
+		&ToolResultBlock{ToolUseID: "1", Content: []ToolResultContent{&TextContent{Text: "result"}}},
 	}
 	
 	b.ResetTimer()
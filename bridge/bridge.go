@@ -0,0 +1,295 @@
+// Package bridge serves Claude Code queries over HTTP, streaming each
+// message to the client as Server-Sent Events or newline-delimited JSON,
+// so web backends can proxy query streams to browsers.
+//
+//	client := claudecode.NewClient(claudecode.ClientOptions{})
+//	http.Handle("/query", bridge.Handler(client, bridge.Options{}))
+//
+// Clients POST a JSON Request. Responses are Server-Sent Events unless the
+// request accepts application/x-ndjson or sets format=ndjson. The query is
+// cancelled when the HTTP client disconnects.
+//
+// Each query costs money and may run tools on the host, so the handler
+// should not be exposed without Authorize. Cross-origin requests are
+// rejected, and the tools a request allows and the session it resumes are
+// ignored unless Options allow them.
+//
+// SessionHandler runs an interactive session over a WebSocket instead,
+// taking prompts from the socket for chat UIs.
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// MaxRequestSize limits the size of a request body.
+const MaxRequestSize = 1 << 20
+
+// Request is a query submitted to the handler.
+type Request struct {
+	Prompt       string   `json:"prompt"`
+	Model        string   `json:"model,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	MaxTurns     int      `json:"max_turns,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty"`
+
+	// Resume continues the session with this ID.
+	Resume string `json:"resume,omitempty"`
+}
+
+// Options configures the handler.
+type Options struct {
+	// Authorize, if set, is called before each query. An error rejects the
+	// request with 401 Unauthorized.
+	Authorize func(r *http.Request) error
+
+	// QueryOptions, if set, builds the options for each query, replacing
+	// the default of applying the Request's fields to NewOptions. An error
+	// rejects the request with 400 Bad Request.
+	QueryOptions func(r *http.Request, req *Request) (*claudecode.Options, error)
+
+	// AllowClientTools applies a Request's AllowedTools by default. Without
+	// it, any caller could allow itself tools such as Bash or Write, so the
+	// field is ignored and the CLI's permission settings apply.
+	AllowClientTools bool
+
+	// AllowResume applies a Request's Resume by default. Without it, any
+	// caller could continue a session whose ID it learned, so the field is
+	// ignored and every request starts a new session.
+	AllowResume bool
+}
+
+// buildOptions returns the options for a query.
+func (opts Options) buildOptions(r *http.Request, req *Request) (*claudecode.Options, error) {
+	if opts.QueryOptions != nil {
+		return opts.QueryOptions(r, req)
+	}
+
+	options := claudecode.NewOptions()
+	if req.Model != "" {
+		options.WithModel(req.Model)
+	}
+	if req.SystemPrompt != "" {
+		options.WithSystemPrompt(req.SystemPrompt)
+	}
+	if req.MaxTurns > 0 {
+		options.WithMaxTurns(req.MaxTurns)
+	}
+	if opts.AllowClientTools && len(req.AllowedTools) > 0 {
+		options.WithAllowedTools(req.AllowedTools...)
+	}
+	if opts.AllowResume && req.Resume != "" {
+		options.WithResume(req.Resume)
+	}
+	return options, nil
+}
+
+// Format is how events are written to the response.
+type Format string
+
+const (
+	// FormatSSE writes Server-Sent Events named by event type.
+	FormatSSE Format = "sse"
+
+	// FormatNDJSON writes one JSON event per line.
+	FormatNDJSON Format = "ndjson"
+)
+
+// Handler returns an HTTP handler that runs a query for each request with
+// client and streams its messages and errors as events, ending with a
+// "done" event.
+func Handler(client *claudecode.Client, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Authorize != nil {
+			if err := opts.Authorize(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		req, err := readRequest(w, r)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errMethod) {
+				status = http.StatusMethodNotAllowed
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		options, err := opts.buildOptions(r, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The request context ends when the client disconnects, which stops
+		// the CLI
+		stream, err := client.Query(r.Context(), req.Prompt, options)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer stream.Close()
+
+		ew := newEventWriter(w, requestFormat(r))
+		ew.writeStream(stream)
+	})
+}
+
+var errMethod = errors.New("method not allowed")
+
+// readRequest decodes the query from a POST body. Other methods are not
+// accepted, since browsers send GET requests for links and images on any
+// site.
+func readRequest(w http.ResponseWriter, r *http.Request) (*Request, error) {
+	if r.Method != http.MethodPost {
+		return nil, errMethod
+	}
+	if err := checkOrigin(r); err != nil {
+		return nil, err
+	}
+
+	req := &Request{}
+	body := http.MaxBytesReader(w, r.Body, MaxRequestSize)
+	if err := json.NewDecoder(body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+	return req, nil
+}
+
+// readParams reads the query from URL parameters.
+func readParams(query url.Values, req *Request) error {
+	req.Prompt = query.Get("prompt")
+	req.Model = query.Get("model")
+	req.Resume = query.Get("resume")
+	if turns := query.Get("max_turns"); turns != "" {
+		n, err := strconv.Atoi(turns)
+		if err != nil {
+			return fmt.Errorf("invalid max_turns: %w", err)
+		}
+		req.MaxTurns = n
+	}
+	return nil
+}
+
+// checkOrigin rejects cross-origin requests, which browsers send with the
+// user's cookies.
+func checkOrigin(r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			return fmt.Errorf("cross-origin request from %q", origin)
+		}
+	}
+	return nil
+}
+
+// requestFormat picks the response format from the format parameter or
+// the Accept header.
+func requestFormat(r *http.Request) Format {
+	switch Format(r.URL.Query().Get("format")) {
+	case FormatNDJSON:
+		return FormatNDJSON
+	case FormatSSE:
+		return FormatSSE
+	}
+	if strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		return FormatNDJSON
+	}
+	return FormatSSE
+}
+
+// eventWriter writes events in a format, flushing after each.
+type eventWriter struct {
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	format Format
+	err    error
+}
+
+func newEventWriter(w http.ResponseWriter, format Format) *eventWriter {
+	header := w.Header()
+	if format == FormatNDJSON {
+		header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		header.Set("Content-Type", "text/event-stream")
+	}
+	header.Set("Cache-Control", "no-cache")
+	// Ask proxies such as nginx not to buffer the stream
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	return &eventWriter{w: w, rc: http.NewResponseController(w), format: format}
+}
+
+// writeStream writes the stream's messages and errors until it ends.
+func (ew *eventWriter) writeStream(stream *claudecode.QueryStream) {
+	messages := stream.Messages()
+	errs := stream.Errors()
+
+	for messages != nil || errs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			ew.writeMessage(msg)
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			ew.write(errorEvent(err))
+		}
+	}
+
+	ew.write(Event{"type": "done"})
+}
+
+// writeMessage encodes and writes a message.
+func (ew *eventWriter) writeMessage(msg claudecode.Message) {
+	event, err := messageEvent(msg)
+	if err != nil {
+		event = errorEvent(fmt.Errorf("failed to encode %s message: %w", msg.Type(), err))
+	}
+	ew.write(event)
+}
+
+// write writes one event. After a write fails, such as when the client
+// has gone, further events are dropped while the stream winds down.
+func (ew *eventWriter) write(event Event) {
+	if ew.err != nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		data, _ = json.Marshal(errorEvent(err))
+	}
+
+	if ew.format == FormatNDJSON {
+		_, err = fmt.Fprintf(ew.w, "%s\n", data)
+	} else {
+		_, err = fmt.Fprintf(ew.w, "event: %s\ndata: %s\n\n", event["type"], data)
+	}
+	if err == nil {
+		err = ew.rc.Flush()
+	}
+	ew.err = err
+}
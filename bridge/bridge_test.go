@@ -0,0 +1,221 @@
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// newTestClient returns a client whose CLI echoes its prompt, then sleeps
+// before finishing when the prompt is "slow". In a session, it answers each
+// line of input with "turn N".
+func newTestClient(t *testing.T) *claudecode.Client {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	script := `#!/bin/sh
+streaming=
+for arg; do
+	[ "$arg" = "--input-format" ] && streaming=1
+	prompt="$arg"
+done
+if [ -n "$streaming" ]; then
+	n=0
+	while read -r line; do
+		n=$((n+1))
+		echo '{"type":"assistant","message":{"content":[{"type":"text","text":"turn '"$n"'"}]}}'
+		echo '{"type":"result","subtype":"success","session_id":"s"}'
+	done
+	exit 0
+fi
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$prompt"'"}]}}'
+if [ "$prompt" = "slow" ]; then
+	exec sleep 30
+fi
+echo '{"type":"result","subtype":"success","session_id":"s"}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return claudecode.NewClient(claudecode.ClientOptions{CLIPath: cliPath})
+}
+
+func TestHandlerSSE(t *testing.T) {
+	server := httptest.NewServer(Handler(newTestClient(t), Options{}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"prompt": "hello"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected event stream, got %q", ct)
+	}
+
+	var names []string
+	var assistant Event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok && assistant == nil {
+			if err := json.Unmarshal([]byte(data), &assistant); err != nil {
+				t.Fatalf("Invalid event data %q: %v", data, err)
+			}
+		}
+	}
+
+	if got := strings.Join(names, ","); got != "assistant,result,done" {
+		t.Errorf("Expected assistant,result,done events, got %s", got)
+	}
+	content, _ := assistant["content"].([]any)
+	if len(content) != 1 {
+		t.Fatalf("Expected one content block, got %v", assistant)
+	}
+	if block := content[0].(map[string]any); block["type"] != "text" || block["text"] != "hello" {
+		t.Errorf("Unexpected block: %v", block)
+	}
+}
+
+func TestHandlerNDJSON(t *testing.T) {
+	server := httptest.NewServer(Handler(newTestClient(t), Options{}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"prompt": "hi"}`))
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var types []string
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			break
+		}
+		types = append(types, event["type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "assistant,result,done" {
+		t.Errorf("Expected assistant,result,done events, got %s", got)
+	}
+}
+
+func TestHandlerRejectsRequests(t *testing.T) {
+	handler := Handler(newTestClient(t), Options{
+		Authorize: func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("invalid token")
+			}
+			return nil
+		},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		auth   string
+		origin string
+		status int
+	}{
+		{"unauthorized", http.MethodPost, `{"prompt": "hi"}`, "", "", http.StatusUnauthorized},
+		{"missing prompt", http.MethodPost, `{}`, "Bearer secret", "", http.StatusBadRequest},
+		{"invalid body", http.MethodPost, `{"prompt":`, "Bearer secret", "", http.StatusBadRequest},
+		{"wrong method", http.MethodPut, `{"prompt": "hi"}`, "Bearer secret", "", http.StatusMethodNotAllowed},
+		{"get", http.MethodGet, "", "Bearer secret", "", http.StatusMethodNotAllowed},
+		{"cross origin", http.MethodPost, `{"prompt": "hi"}`, "Bearer secret", "https://evil.example", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestBuildOptionsIgnoresClientGrants(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	req := &Request{Prompt: "hi", Model: "sonnet", AllowedTools: []string{"Bash"}, Resume: "other-session"}
+
+	options, err := Options{}.buildOptions(r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.AllowedTools) != 0 || options.Resume != nil {
+		t.Errorf("Expected the request's tools and resume to be ignored, got %v and %v", options.AllowedTools, options.Resume)
+	}
+	if options.Model == nil || *options.Model != "sonnet" {
+		t.Errorf("Expected the model to be applied, got %v", options.Model)
+	}
+
+	options, err = Options{AllowClientTools: true, AllowResume: true}.buildOptions(r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.AllowedTools) != 1 || options.AllowedTools[0] != "Bash" || options.Resume == nil || *options.Resume != "other-session" {
+		t.Errorf("Expected the request's tools and resume when allowed, got %v and %v", options.AllowedTools, options.Resume)
+	}
+}
+
+func TestHandlerCancelsOnDisconnect(t *testing.T) {
+	done := make(chan struct{})
+	handler := Handler(newTestClient(t), Options{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"prompt": "slow"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the first event, then disconnect
+	reader := bufio.NewReader(resp.Body)
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatalf("Failed to read first event: %v", err)
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Handler did not return after the client disconnected")
+	}
+}
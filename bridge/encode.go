@@ -0,0 +1,72 @@
+package bridge
+
+import (
+	"encoding/json"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// Event is a message or error sent to the HTTP client, encoded as JSON.
+// Every event has a "type" field: a message type ("assistant", "user",
+// "system", "result"), "error", or "done".
+type Event map[string]any
+
+// messageEvent encodes a message with its type, and the type of each of
+// its content blocks, so that browsers can dispatch on them.
+func messageEvent(msg claudecode.Message) (Event, error) {
+	event, err := typed(msg, msg.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	switch m := msg.(type) {
+	case *claudecode.AssistantMessage:
+		if event["content"], err = blockEvents(m.Content); err != nil {
+			return nil, err
+		}
+	case *claudecode.UserMessage:
+		if len(m.Blocks) > 0 {
+			if event["blocks"], err = blockEvents(m.Blocks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return event, nil
+}
+
+// errorEvent encodes an error reported on the stream.
+func errorEvent(err error) Event {
+	return Event{
+		"type":  "error",
+		"error": err.Error(),
+		"kind":  string(claudecode.ErrorKind(err)),
+	}
+}
+
+// blockEvents encodes content blocks with their types.
+func blockEvents(blocks []claudecode.ContentBlock) ([]Event, error) {
+	events := make([]Event, 0, len(blocks))
+	for _, block := range blocks {
+		event, err := typed(block, block.Type())
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// typed encodes v as a JSON object with the given type field.
+func typed(v any, typ string) (Event, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	event := Event{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	event["type"] = typ
+	return event, nil
+}
@@ -0,0 +1,161 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// SessionRequest is a frame sent by the client of a session socket.
+type SessionRequest struct {
+	// Type is "prompt" to send a prompt as a new turn, or "interrupt" to
+	// stop the current turn.
+	Type string `json:"type"`
+
+	// Prompt is the prompt to send.
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// SessionHandler returns an HTTP handler that upgrades each request to a
+// WebSocket and runs an interactive session over it, for chat UIs.
+//
+// Text frames from the client are SessionRequests. Each message and error
+// from the session is sent as a text frame holding the same JSON events
+// as Handler, with a "done" event when the session ends. A "prompt" URL
+// parameter is sent as the first turn; the other parameters configure the
+// session as for Handler. Closing the socket ends the session.
+//
+// Cross-origin handshakes are rejected; Authorize can apply further checks
+// before the upgrade.
+func SessionHandler(client *claudecode.Client, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Authorize != nil {
+			if err := opts.Authorize(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if err := checkUpgrade(r); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errMethod) {
+				status = http.StatusMethodNotAllowed
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		req := &Request{}
+		if err := readParams(r.URL.Query(), req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		options, err := opts.buildOptions(r, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Cancelled when the socket closes, which stops the CLI
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		session, err := client.NewSession(ctx, options)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer session.Close()
+
+		conn, err := upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close(closeNormal)
+
+		if req.Prompt != "" {
+			if err := session.Send(ctx, req.Prompt); err != nil {
+				writeSocketEvent(conn, errorEvent(err))
+			}
+		}
+
+		go readSession(ctx, cancel, conn, session)
+
+		messages := session.Receive()
+		errs := session.Errors()
+		for messages != nil || errs != nil {
+			var event Event
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					messages = nil
+					continue
+				}
+				if event, err = messageEvent(msg); err != nil {
+					event = errorEvent(fmt.Errorf("failed to encode %s message: %w", msg.Type(), err))
+				}
+
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				event = errorEvent(err)
+
+			case <-ctx.Done():
+				return
+			}
+
+			if err := writeSocketEvent(conn, event); err != nil {
+				return
+			}
+		}
+
+		writeSocketEvent(conn, Event{"type": "done"})
+	})
+}
+
+// readSession applies the client's frames to the session until the socket
+// closes, then calls cancel.
+func readSession(ctx context.Context, cancel context.CancelFunc, conn *wsConn, session *claudecode.Session) {
+	defer cancel()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req SessionRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			writeSocketEvent(conn, errorEvent(fmt.Errorf("invalid request: %w", err)))
+			continue
+		}
+
+		switch req.Type {
+		case "prompt":
+			err = session.Send(ctx, req.Prompt)
+		case "interrupt":
+			err = session.Interrupt(ctx)
+		default:
+			err = fmt.Errorf("unknown request type %q", req.Type)
+		}
+		if err != nil {
+			writeSocketEvent(conn, errorEvent(err))
+		}
+	}
+}
+
+// writeSocketEvent writes an event as a text frame.
+func writeSocketEvent(conn *wsConn, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		data, _ = json.Marshal(errorEvent(err))
+	}
+	return conn.WriteText(data)
+}
@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsClient is a minimal WebSocket client for testing the session handler.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dialSession opens a WebSocket to the server at path.
+func dialSession(t *testing.T, server *httptest.Server, path string) *wsClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	key := make([]byte, 16)
+	rand.Read(key)
+	encodedKey := base64.StdEncoding.EncodeToString(key)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", encodedKey)
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101 Switching Protocols, got %s", resp.Status)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != acceptKey(encodedKey) {
+		t.Fatalf("Unexpected accept key %q", got)
+	}
+	return &wsClient{t: t, conn: conn, br: br}
+}
+
+// writeFrame writes a masked, final frame.
+func (c *wsClient) writeFrame(opcode byte, payload []byte) {
+	c.t.Helper()
+
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// send writes a request as a text frame.
+func (c *wsClient) send(req SessionRequest) {
+	c.t.Helper()
+	data, _ := json.Marshal(req)
+	c.writeFrame(opText, data)
+}
+
+// readFrame reads an unmasked frame from the server.
+func (c *wsClient) readFrame() (byte, []byte) {
+	c.t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		c.t.Fatalf("Failed to read frame: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatalf("Failed to read frame: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+// readEvent reads a text frame as an event.
+func (c *wsClient) readEvent() Event {
+	c.t.Helper()
+
+	opcode, payload := c.readFrame()
+	if opcode != opText {
+		c.t.Fatalf("Expected text frame, got opcode %d", opcode)
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		c.t.Fatalf("Invalid event %q: %v", payload, err)
+	}
+	return event
+}
+
+// readTurn reads events through a result, returning the assistant text.
+func (c *wsClient) readTurn() string {
+	c.t.Helper()
+
+	var text string
+	for {
+		event := c.readEvent()
+		switch event["type"] {
+		case "assistant":
+			block := event["content"].([]any)[0].(map[string]any)
+			text, _ = block["text"].(string)
+		case "result":
+			return text
+		default:
+			c.t.Fatalf("Unexpected event %v", event)
+		}
+	}
+}
+
+func TestSessionHandler(t *testing.T) {
+	done := make(chan struct{})
+	handler := SessionHandler(newTestClient(t), Options{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := dialSession(t, server, "/?prompt=first")
+	if got := client.readTurn(); got != "turn 1" {
+		t.Errorf("Expected turn 1 for the prompt parameter, got %q", got)
+	}
+
+	client.send(SessionRequest{Type: "prompt", Prompt: "second"})
+	if got := client.readTurn(); got != "turn 2" {
+		t.Errorf("Expected turn 2, got %q", got)
+	}
+
+	client.writeFrame(opPing, []byte("hi"))
+	if opcode, payload := client.readFrame(); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("Expected pong with ping payload, got opcode %d %q", opcode, payload)
+	}
+
+	client.send(SessionRequest{Type: "bogus"})
+	if event := client.readEvent(); event["type"] != "error" {
+		t.Errorf("Expected error event for unknown request, got %v", event)
+	}
+
+	// Closing the socket ends the session
+	client.writeFrame(opClose, binary.BigEndian.AppendUint16(nil, closeNormal))
+	if opcode, _ := client.readFrame(); opcode != opClose {
+		t.Errorf("Expected close frame, got opcode %d", opcode)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Handler did not return after the socket closed")
+	}
+}
+
+func TestSessionHandlerRejectsRequests(t *testing.T) {
+	handler := SessionHandler(newTestClient(t), Options{
+		Authorize: func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("invalid token")
+			}
+			return nil
+		},
+	})
+
+	upgradeHeaders := map[string]string{
+		"Authorization":         "Bearer secret",
+		"Connection":            "keep-alive, Upgrade",
+		"Upgrade":               "websocket",
+		"Sec-WebSocket-Version": "13",
+		"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"unauthorized", http.MethodGet, map[string]string{"Authorization": ""}, http.StatusUnauthorized},
+		{"not an upgrade", http.MethodGet, map[string]string{"Upgrade": ""}, http.StatusBadRequest},
+		{"wrong version", http.MethodGet, map[string]string{"Sec-WebSocket-Version": "8"}, http.StatusBadRequest},
+		{"cross origin", http.MethodGet, map[string]string{"Origin": "https://evil.example"}, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for name, value := range upgradeHeaders {
+				req.Header.Set(name, value)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, section 1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected accept key %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+package bridge
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebSocket opcodes (RFC 6455, section 5.2).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// WebSocket close codes (RFC 6455, section 7.4.1).
+const (
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeTooBig        = 1009
+)
+
+// websocketGUID is appended to the client's key to compute the accept key.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var errFrameTooBig = errors.New("websocket message too large")
+
+// wsConn is the server side of a WebSocket connection. It supports what
+// the session handler needs: reading text messages, possibly fragmented,
+// writing unfragmented text messages, and answering pings and closes.
+// Writes are safe for concurrent use; reads are not.
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// isWebSocketRequest reports whether r asks to upgrade to a WebSocket.
+func isWebSocketRequest(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// checkUpgrade validates a WebSocket handshake request. Cross-origin
+// requests are rejected, since browsers send cookies with them.
+func checkUpgrade(r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return errMethod
+	}
+	if !isWebSocketRequest(r) {
+		return fmt.Errorf("websocket upgrade required")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return fmt.Errorf("unsupported websocket version")
+	}
+	if r.Header.Get("Sec-WebSocket-Key") == "" {
+		return fmt.Errorf("missing Sec-WebSocket-Key")
+	}
+	return checkOrigin(r)
+}
+
+// upgrade completes the handshake for a request accepted by checkUpgrade
+// and takes over its connection. If the connection cannot be taken over,
+// it responds with an error.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	_, err = fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to complete handshake: %w", err)
+	}
+
+	return &wsConn{conn: conn, br: rw.Reader}, nil
+}
+
+// acceptKey computes the Sec-WebSocket-Accept value for a client key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadMessage reads the next text or binary message, answering pings
+// along the way. It returns io.EOF when the client closes the connection.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			if errors.Is(err, errFrameTooBig) {
+				c.Close(closeTooBig)
+			}
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.Close(closeNormal)
+			return nil, io.EOF
+		case opText, opBinary:
+			if started {
+				c.Close(closeProtocolError)
+				return nil, fmt.Errorf("websocket message interrupted by a new message")
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				c.Close(closeProtocolError)
+				return nil, fmt.Errorf("unexpected websocket continuation frame")
+			}
+		default:
+			c.Close(closeProtocolError)
+			return nil, fmt.Errorf("unknown websocket opcode %d", opcode)
+		}
+
+		if len(message)+len(payload) > MaxRequestSize {
+			c.Close(closeTooBig)
+			return nil, errFrameTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readFrame reads and unmasks one frame.
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	// Clients must mask every frame
+	if !masked {
+		c.Close(closeProtocolError)
+		return false, 0, nil, fmt.Errorf("unmasked websocket frame")
+	}
+	if length > MaxRequestSize {
+		return false, 0, nil, errFrameTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteText writes a text message.
+func (c *wsConn) WriteText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// writeFrame writes one unmasked, final frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	return c.writeFrameLocked(opcode, payload)
+}
+
+func (c *wsConn) writeFrameLocked(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// Close sends a close frame with code, if one has not been sent, and
+// closes the connection. It's safe to call Close multiple times.
+func (c *wsConn) Close(code int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	c.writeFrameLocked(opClose, binary.BigEndian.AppendUint16(nil, uint16(code)))
+	return c.conn.Close()
+}
+
+// headerContains reports whether a comma-separated header contains token,
+// ignoring case.
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
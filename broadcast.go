@@ -0,0 +1,309 @@
+package claudecode
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBroadcastBufferSize is the number of messages, and separately of
+// errors, buffered for each subscriber when BroadcastOptions.BufferSize is
+// not set.
+const DefaultBroadcastBufferSize = 100
+
+// ErrSlowSubscriber is reported by Subscription.Err for a subscriber
+// disconnected under SlowSubscriberDisconnect.
+var ErrSlowSubscriber = errors.New("subscriber too slow")
+
+// SlowSubscriberPolicy decides what a Broadcaster does with a message or
+// error for a subscriber whose buffer is full.
+type SlowSubscriberPolicy string
+
+const (
+	// SlowSubscriberBlock waits for the subscriber to catch up, so the
+	// slowest subscriber paces the others and, in turn, the CLI. No
+	// subscriber misses anything. This is the default.
+	SlowSubscriberBlock SlowSubscriberPolicy = "block"
+
+	// SlowSubscriberDrop skips the subscriber for that message or error,
+	// counting it in Subscription.Dropped. It suits subscribers that can
+	// tolerate gaps, such as metrics.
+	SlowSubscriberDrop SlowSubscriberPolicy = "drop"
+
+	// SlowSubscriberDisconnect closes the subscriber's channels and
+	// unsubscribes it; its Err reports ErrSlowSubscriber.
+	SlowSubscriberDisconnect SlowSubscriberPolicy = "disconnect"
+)
+
+// BroadcastOptions configures a Broadcaster.
+type BroadcastOptions struct {
+	// BufferSize is the number of messages, and separately of errors,
+	// buffered for each subscriber. If zero, DefaultBroadcastBufferSize is
+	// used.
+	BufferSize int
+
+	// Policy decides what happens when a subscriber's buffer is full. If
+	// empty, SlowSubscriberBlock is used.
+	Policy SlowSubscriberPolicy
+}
+
+// bufferSize returns the per-subscriber buffer size.
+func (o BroadcastOptions) bufferSize() int {
+	if o.BufferSize > 0 {
+		return o.BufferSize
+	}
+	return DefaultBroadcastBufferSize
+}
+
+// Broadcaster delivers every message and error of one stream to any number
+// of subscribers, such as a UI renderer, a logger, and a metrics recorder,
+// each reading at its own pace.
+//
+// Example:
+//
+//	b := stream.Broadcast(claudecode.BroadcastOptions{})
+//	ui, metrics := b.Subscribe(), b.Subscribe()
+//	b.Start()
+//	go metrics.Subscribe(claudecode.HandlerFuncs{Result: recordCost})
+//	for msg := range ui.Messages() {
+//		render(msg)
+//	}
+type Broadcaster struct {
+	messages <-chan Message
+	errs     <-chan error
+	opts     BroadcastOptions
+
+	mu          sync.Mutex
+	subscribers []*Subscription
+	started     bool
+	done        bool
+}
+
+// NewBroadcaster returns a broadcaster for the given channels, which it
+// consumes once started.
+func NewBroadcaster(messages <-chan Message, errs <-chan error, opts BroadcastOptions) *Broadcaster {
+	return &Broadcaster{messages: messages, errs: errs, opts: opts}
+}
+
+// Broadcast returns a broadcaster for the stream's messages and errors.
+// Once it is started, Messages and Errors must not be read directly.
+func (qs *QueryStream) Broadcast(opts BroadcastOptions) *Broadcaster {
+	return NewBroadcaster(qs.Messages(), qs.Errors(), opts)
+}
+
+// Broadcast returns a broadcaster for the session's messages and errors.
+// Once it is started, Receive and Errors must not be read directly.
+func (s *Session) Broadcast(opts BroadcastOptions) *Broadcaster {
+	return NewBroadcaster(s.Receive(), s.Errors(), opts)
+}
+
+// Tee returns n subscriptions that each receive every message and error
+// of the stream, under SlowSubscriberBlock. Every subscription must be
+// drained, or closed once no longer needed.
+func (qs *QueryStream) Tee(n int) []*Subscription {
+	return tee(qs.Broadcast(BroadcastOptions{}), n)
+}
+
+// Tee returns n subscriptions that each receive every message and error
+// of the session, under SlowSubscriberBlock.
+func (s *Session) Tee(n int) []*Subscription {
+	return tee(s.Broadcast(BroadcastOptions{}), n)
+}
+
+// tee subscribes n times to b and starts it.
+func tee(b *Broadcaster, n int) []*Subscription {
+	subs := make([]*Subscription, n)
+	for i := range subs {
+		subs[i] = b.Subscribe()
+	}
+	b.Start()
+	return subs
+}
+
+// Subscribe adds a subscriber. Subscribers added before Start receive
+// everything; those added later receive what arrives after they subscribe,
+// and those added after the stream ended receive closed channels.
+func (b *Broadcaster) Subscribe() *Subscription {
+	size := b.opts.bufferSize()
+	sub := &Subscription{
+		messages: make(chan Message, size),
+		errors:   make(chan error, size),
+		closed:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		sub.end()
+		return sub
+	}
+	b.subscribers = append(b.subscribers, sub)
+	return sub
+}
+
+// Start begins delivering the stream's messages and errors to the
+// subscribers. The subscribers' channels are closed once the stream ends.
+// Calling Start again has no effect.
+func (b *Broadcaster) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		return
+	}
+	b.started = true
+	go b.run()
+}
+
+// run delivers each message and error until both channels are closed.
+func (b *Broadcaster) run() {
+	messages, errs := b.messages, b.errs
+	for messages != nil || errs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			b.publish(func(sub *Subscription) bool {
+				return deliver(sub, sub.messages, msg, b.opts.Policy)
+			})
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			b.publish(func(sub *Subscription) bool {
+				return deliver(sub, sub.errors, err, b.opts.Policy)
+			})
+		}
+	}
+
+	b.mu.Lock()
+	b.done = true
+	subs := b.subscribers
+	b.subscribers = nil
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.end()
+	}
+}
+
+// publish calls send for each subscriber, removing those it reports gone.
+func (b *Broadcaster) publish(send func(sub *Subscription) bool) {
+	b.mu.Lock()
+	subs := slices.Clone(b.subscribers)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		if send(sub) {
+			continue
+		}
+		b.mu.Lock()
+		b.subscribers = slices.DeleteFunc(b.subscribers, func(s *Subscription) bool { return s == sub })
+		b.mu.Unlock()
+		sub.end()
+	}
+}
+
+// deliver sends v on one of sub's channels under the policy, and reports
+// false if sub has unsubscribed or was disconnected.
+func deliver[T any](sub *Subscription, ch chan T, v T, policy SlowSubscriberPolicy) bool {
+	select {
+	case <-sub.closed:
+		return false
+	default:
+	}
+
+	switch policy {
+	case SlowSubscriberDrop:
+		select {
+		case ch <- v:
+		default:
+			sub.dropped.Add(1)
+		}
+		return true
+
+	case SlowSubscriberDisconnect:
+		select {
+		case ch <- v:
+			return true
+		default:
+			sub.err.Store(&ErrSlowSubscriber)
+			return false
+		}
+
+	default:
+		select {
+		case ch <- v:
+			return true
+		case <-sub.closed:
+			return false
+		}
+	}
+}
+
+// Subscription receives the messages and errors of a Broadcaster.
+type Subscription struct {
+	messages chan Message
+	errors   chan error
+
+	// closed is closed when the subscriber unsubscribes
+	closed    chan struct{}
+	closeOnce sync.Once
+	endOnce   sync.Once
+
+	dropped atomic.Int64
+	err     atomic.Pointer[error]
+}
+
+// Messages returns a channel that receives every message of the stream.
+// It is closed when the stream ends or the subscription does.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Errors returns a channel that receives every error of the stream. It is
+// closed when the stream ends or the subscription does.
+func (s *Subscription) Errors() <-chan error {
+	return s.errors
+}
+
+// Subscribe consumes the subscription, calling the handler's methods for
+// each event, as QueryStream.Subscribe does.
+func (s *Subscription) Subscribe(handler Handler) error {
+	return subscribe(s.Messages(), s.Errors(), handler)
+}
+
+// Dropped returns the number of messages and errors skipped under
+// SlowSubscriberDrop.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns ErrSlowSubscriber if the subscription was disconnected
+// under SlowSubscriberDisconnect, and nil otherwise.
+func (s *Subscription) Err() error {
+	if err := s.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Close unsubscribes, so the broadcaster no longer waits for or buffers
+// anything for this subscriber. Its channels are closed shortly after,
+// and need not be drained. It's safe to call Close multiple times.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+// end closes the subscription's channels. Only the broadcaster sends on
+// them, so it alone calls end, once it will send no more.
+func (s *Subscription) end() {
+	s.endOnce.Do(func() {
+		close(s.messages)
+		close(s.errors)
+	})
+}
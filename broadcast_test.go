@@ -0,0 +1,168 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// broadcastSource returns channels delivering n assistant messages and
+// then err, if set.
+func broadcastSource(n int, err error) (chan Message, chan error) {
+	messages := make(chan Message, n)
+	errs := make(chan error, 1)
+	for i := 0; i < n; i++ {
+		messages <- &AssistantMessage{}
+	}
+	if err != nil {
+		errs <- err
+	}
+	close(messages)
+	close(errs)
+	return messages, errs
+}
+
+// collect drains a subscription, returning how many messages and errors
+// it received.
+func collect(sub *Subscription) (messages, errs int) {
+	sub.Subscribe(HandlerFuncs{
+		Assistant: func(*AssistantMessage) { messages++ },
+		Error:     func(error) { errs++ },
+	})
+	return messages, errs
+}
+
+func TestBroadcasterBlock(t *testing.T) {
+	messages, errs := broadcastSource(250, errors.New("boom"))
+	b := NewBroadcaster(messages, errs, BroadcastOptions{})
+	subs := []*Subscription{b.Subscribe(), b.Subscribe(), b.Subscribe()}
+	b.Start()
+
+	var wg sync.WaitGroup
+	for i, sub := range subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 0 {
+				// A slow subscriber paces the others without losing anything
+				time.Sleep(20 * time.Millisecond)
+			}
+			if m, e := collect(sub); m != 250 || e != 1 {
+				t.Errorf("Subscriber %d: expected 250 messages and 1 error, got %d and %d", i, m, e)
+			}
+		}()
+	}
+	wg.Wait()
+
+	late := b.Subscribe()
+	if _, ok := <-late.Messages(); ok {
+		t.Error("Expected a subscriber added after the stream ended to get closed channels")
+	}
+}
+
+func TestBroadcasterSlowSubscriberPolicies(t *testing.T) {
+	tests := []struct {
+		policy      SlowSubscriberPolicy
+		wantDropped int64
+		wantErr     error
+	}{
+		{SlowSubscriberDrop, 8, nil},
+		{SlowSubscriberDisconnect, 0, ErrSlowSubscriber},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			messages := make(chan Message)
+			errs := make(chan error)
+			b := NewBroadcaster(messages, errs, BroadcastOptions{BufferSize: 2, Policy: tt.policy})
+			slow, fast := b.Subscribe(), b.Subscribe()
+			b.Start()
+
+			// Each message is sent once the fast subscriber has read the last
+			for i := 0; i < 10; i++ {
+				messages <- &AssistantMessage{}
+				<-fast.Messages()
+			}
+			close(messages)
+			close(errs)
+			if _, ok := <-fast.Messages(); ok {
+				t.Error("Expected the fast subscriber's channel to be closed")
+			}
+
+			if m, _ := collect(slow); m != 2 {
+				t.Errorf("Expected the slow subscriber to get its buffered 2 messages, got %d", m)
+			}
+			if slow.Dropped() != tt.wantDropped {
+				t.Errorf("Expected %d dropped, got %d", tt.wantDropped, slow.Dropped())
+			}
+			if !errors.Is(slow.Err(), tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, slow.Err())
+			}
+		})
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	messages := make(chan Message)
+	errs := make(chan error)
+	b := NewBroadcaster(messages, errs, BroadcastOptions{BufferSize: 1})
+	gone, kept := b.Subscribe(), b.Subscribe()
+	b.Start()
+
+	received := make(chan int)
+	go func() {
+		m, _ := collect(kept)
+		received <- m
+	}()
+
+	// The closed subscriber never reads, yet does not hold up the other
+	gone.Close()
+	for i := 0; i < 5; i++ {
+		messages <- &AssistantMessage{}
+	}
+	close(messages)
+	close(errs)
+
+	select {
+	case m := <-received:
+		if m != 5 {
+			t.Errorf("Expected 5 messages, got %d", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out: a closed subscription blocked the broadcaster")
+	}
+}
+
+func TestQueryStreamTee(t *testing.T) {
+	client := NewClient(ClientOptions{CLIPath: writeEchoCLI(t)})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.Query(ctx, "hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	subs := stream.Tee(2)
+	results := make(chan *QueryResult, len(subs))
+	for _, sub := range subs {
+		go func() {
+			result := &QueryResult{}
+			sub.Subscribe(HandlerFuncs{
+				Assistant: func(msg *AssistantMessage) { result.Text += msg.Text() },
+				Result:    func(msg *ResultMessage) { result.Result = msg },
+			})
+			results <- result
+		}()
+	}
+
+	for range subs {
+		result := <-results
+		if result.Text != "hello" || result.Result == nil {
+			t.Errorf("Expected each subscriber to see the text and result, got %q and %v", result.Text, result.Result)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+var (
+	_ claudecode.ResponseCache = (*Memory)(nil)
+	_ claudecode.ResponseCache = (*Disk)(nil)
+)
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	m := NewMemory(time.Minute, 0)
+	m.now = func() time.Time { return now }
+
+	if _, ok := m.Get("a"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	value := []byte("line\n")
+	m.Set("a", value)
+	value[0] = 'X'
+	got, ok := m.Get("a")
+	if !ok || string(got) != "line\n" {
+		t.Fatalf("Expected the stored value, got %q, %v", got, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := m.Get("a"); ok {
+		t.Error("Expected the entry to expire")
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected the expired entry to be removed, got %d entries", m.Len())
+	}
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	now := time.Now()
+	m := NewMemory(0, 2)
+	m.now = func() time.Time { return now }
+
+	for i := range 3 {
+		now = now.Add(time.Second)
+		m.Set(fmt.Sprint(i), []byte{byte(i)})
+	}
+
+	if _, ok := m.Get("0"); ok {
+		t.Error("Expected the oldest entry to be evicted")
+	}
+	for _, key := range []string{"1", "2"} {
+		if _, ok := m.Get(key); !ok {
+			t.Errorf("Expected entry %s to be kept", key)
+		}
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	d := NewDisk(dir, time.Hour)
+
+	if _, ok := d.Get("a"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	d.Set("a", []byte("line\n"))
+	got, ok := d.Get("a")
+	if !ok || string(got) != "line\n" {
+		t.Fatalf("Expected the stored value, got %q, %v", got, ok)
+	}
+
+	// A second cache over the same directory sees the entry
+	if _, ok := NewDisk(dir, time.Hour).Get("a"); !ok {
+		t.Error("Expected the entry to persist on disk")
+	}
+
+	d.Delete("a")
+	if _, ok := d.Get("a"); ok {
+		t.Error("Expected the deleted entry to be gone")
+	}
+
+	for _, key := range []string{"", "../escape", ".hidden"} {
+		d.Set(key, []byte("x"))
+		if _, ok := d.Get(key); ok {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected no files left behind, got %d", len(entries))
+	}
+}
+
+func TestDiskExpiry(t *testing.T) {
+	dir := t.TempDir()
+	d := NewDisk(dir, time.Hour)
+	d.Set("old", []byte("1"))
+	d.Set("new", []byte("2"))
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old"+diskSuffix), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := d.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("Expected one entry pruned, got %d, %v", removed, err)
+	}
+	if _, ok := d.Get("old"); ok {
+		t.Error("Expected the expired entry to be gone")
+	}
+	if _, ok := d.Get("new"); !ok {
+		t.Error("Expected the fresh entry to be kept")
+	}
+
+	d.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, ok := d.Get("new"); ok {
+		t.Error("Expected the entry to expire on read")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new"+diskSuffix)); !os.IsNotExist(err) {
+		t.Error("Expected the expired entry's file to be removed")
+	}
+
+	if removed, err := NewDisk(filepath.Join(dir, "missing"), time.Hour).Prune(); removed != 0 || err != nil {
+		t.Errorf("Expected pruning a missing directory to do nothing, got %d, %v", removed, err)
+	}
+}
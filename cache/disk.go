@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskSuffix names the files of a disk cache's entries.
+const diskSuffix = ".jsonl"
+
+// Disk is a response cache storing each entry as a file in a directory,
+// so that entries survive the process and can be shared between CI runs,
+// for example through the CI system's own cache. It is safe for
+// concurrent use, including by several processes sharing the directory.
+type Disk struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewDisk returns a cache storing entries in dir, which is created when
+// the first entry is stored. Entries expire ttl after they are stored, or
+// never if ttl is zero.
+func NewDisk(dir string, ttl time.Duration) *Disk {
+	return &Disk{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Dir returns the directory the cache stores entries in.
+func (d *Disk) Dir() string {
+	return d.dir
+}
+
+// Get returns the value stored under key, and false if there is none, it
+// has expired, or it cannot be read. Expired entries are removed.
+func (d *Disk) Get(key string) ([]byte, bool) {
+	path, ok := d.path(key)
+	if !ok {
+		return nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if d.expired(info) {
+		os.Remove(path)
+		return nil, false
+	}
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value under key. The entry is written to a temporary file
+// and renamed into place, so readers never see a partial entry. Errors
+// are ignored: the entry is simply not cached.
+func (d *Disk) Set(key string, value []byte) {
+	path, ok := d.path(key)
+	if !ok {
+		return
+	}
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return
+	}
+
+	f, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete removes the value stored under key.
+func (d *Disk) Delete(key string) {
+	if path, ok := d.path(key); ok {
+		os.Remove(path)
+	}
+}
+
+// Prune removes expired entries and returns how many it removed. It
+// returns nil if the directory does not exist.
+func (d *Disk) Prune() (int, error) {
+	entries, err := os.ReadDir(d.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), diskSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !d.expired(info) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.dir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// path returns the file of key's entry, and false if key cannot name a
+// file in the directory.
+func (d *Disk) path(key string) (string, bool) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", false
+	}
+	return filepath.Join(d.dir, key+diskSuffix), true
+}
+
+// expired reports whether the entry described by info has outlived the
+// TTL. An entry's age is that of its file's modification time.
+func (d *Disk) expired(info os.FileInfo) bool {
+	return d.ttl > 0 && d.now().Sub(info.ModTime()) >= d.ttl
+}
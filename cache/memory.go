@@ -0,0 +1,116 @@
+// Package cache provides implementations of claudecode.ResponseCache, so
+// that repeated identical queries, such as the same analysis run by every
+// CI job, are answered without starting the CLI again.
+//
+//	options := claudecode.NewOptions().
+//		WithResponseCache(cache.NewDisk(".claude-cache", 24*time.Hour))
+//
+// A query answered from the cache starts with a system message with
+// subtype claudecode.SystemSubtypeCacheHit.
+package cache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Memory is an in-memory response cache. It is safe for concurrent use.
+type Memory struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	value  []byte
+	stored time.Time
+}
+
+// NewMemory returns an in-memory cache whose entries expire ttl after they
+// are stored, or never if ttl is zero. If maxEntries is positive, storing
+// an entry beyond it evicts the oldest ones.
+func NewMemory(ttl time.Duration, maxEntries int) *Memory {
+	return &Memory{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]memoryEntry{},
+		now:        time.Now,
+	}
+}
+
+// Get returns the value stored under key, and false if there is none or it
+// has expired.
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if m.expired(entry.stored) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return slices.Clone(entry.value), true
+}
+
+// Set stores value under key, replacing any previous value.
+func (m *Memory) Set(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{value: slices.Clone(value), stored: m.now()}
+	if m.maxEntries > 0 && len(m.entries) > m.maxEntries {
+		m.evict()
+	}
+}
+
+// Delete removes the value stored under key.
+func (m *Memory) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// expired reports whether an entry stored at stored has outlived the TTL.
+func (m *Memory) expired(stored time.Time) bool {
+	return m.ttl > 0 && m.now().Sub(stored) >= m.ttl
+}
+
+// evict removes expired entries and then the oldest ones until the cache
+// holds maxEntries.
+func (m *Memory) evict() {
+	type aged struct {
+		key    string
+		stored time.Time
+	}
+	var live []aged
+	for key, entry := range m.entries {
+		if m.expired(entry.stored) {
+			delete(m.entries, key)
+			continue
+		}
+		live = append(live, aged{key, entry.stored})
+	}
+	if len(live) <= m.maxEntries {
+		return
+	}
+
+	slices.SortFunc(live, func(a, b aged) int { return a.stored.Compare(b.stored) })
+	for _, entry := range live[:len(live)-m.maxEntries] {
+		delete(m.entries, entry.key)
+	}
+}
@@ -0,0 +1,299 @@
+// Package chain composes sequential Claude Code queries, where each step
+// builds its prompt from the previous step's output: plan then execute,
+// map a prompt over files then reduce the answers, or extract structured
+// data then act on it.
+//
+// Steps share the chain's options, each with its own overrides, and the
+// usage of every query is aggregated:
+//
+//	result, err := chain.New(claudecode.NewOptions().WithCwd(repo)).
+//		Then("plan", chain.Prompt("Plan how to add retries to the HTTP client. Do not edit files."),
+//			claudecode.WithPermissionMode(claudecode.PermissionModePlan)).
+//		Then("execute", chain.Template("Carry out this plan:\n\n{{.Text}}"),
+//			claudecode.WithPermissionMode(claudecode.PermissionModeAcceptEdits)).
+//		Run(ctx)
+//	fmt.Println(result.Final().Text, result.Usage.TotalCostUSD)
+//
+// FanOut splits a list of files into shards reviewed in parallel, and Merge
+// builds the prompt of a final step that combines their findings:
+//
+//	result, err := chain.New(options).WithClient(pooledClient).
+//		FanOut("review", files, 4, func(shard []string, _ *chain.Output) (string, error) {
+//			return "Review these files for bugs:\n" + strings.Join(shard, "\n"), nil
+//		}).
+//		Then("report", chain.Merge("Combine these reviews into one report, most severe first.")).
+//		Run(ctx)
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// PromptFunc builds a step's prompt from the previous step's output, which
+// is nil for the first step.
+type PromptFunc func(prev *Output) (string, error)
+
+// ItemPromptFunc builds the prompt for one item of a Map step.
+type ItemPromptFunc func(item string, prev *Output) (string, error)
+
+// ShardPromptFunc builds the prompt for one shard of a FanOut step.
+type ShardPromptFunc func(shard []string, prev *Output) (string, error)
+
+// Runner runs one query to completion. By default a chain runs queries
+// with its client's QuerySync, or the package's QuerySync without one.
+type Runner func(ctx context.Context, prompt string, options *claudecode.Options) (*claudecode.QueryResult, error)
+
+// Prompt returns a PromptFunc that ignores the previous output.
+func Prompt(prompt string) PromptFunc {
+	return func(*Output) (string, error) {
+		return prompt, nil
+	}
+}
+
+// Template returns a PromptFunc that executes a text/template with the
+// previous step's Output, such as "Summarize:\n\n{{.Text}}". It panics if
+// the template does not parse, like template.Must.
+func Template(text string) PromptFunc {
+	tmpl := template.Must(template.New("prompt").Option("missingkey=error").Parse(text))
+	return func(prev *Output) (string, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, prev); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// step is one stage of a chain.
+type step struct {
+	name    string
+	prompt  PromptFunc
+	options []claudecode.Option
+
+	// Map and FanOut steps run shardPrompt once per shard; each shard of
+	// a Map step holds a single item
+	shards      [][]string
+	shardPrompt ShardPromptFunc
+	mapped      bool
+	parallelism int
+}
+
+// Chain is a sequence of query steps. Build one with New and Then or Map,
+// then call Run; a chain may be run more than once.
+type Chain struct {
+	options *claudecode.Options
+	client  *claudecode.Client
+	runner  Runner
+	steps   []step
+}
+
+// New creates a chain whose steps share options, which may be nil for
+// defaults. The options are copied; later changes to them do not affect
+// the chain.
+func New(options *claudecode.Options) *Chain {
+	if options == nil {
+		options = claudecode.NewOptions()
+	}
+	return &Chain{options: options.Clone()}
+}
+
+// WithClient runs the chain's queries with client, so that they share its
+// pool, usage tracker, and CLI path.
+func (c *Chain) WithClient(client *claudecode.Client) *Chain {
+	c.client = client
+	return c
+}
+
+// WithRunner runs the chain's queries with runner, such as one that adds
+// logging or serves canned results in tests.
+func (c *Chain) WithRunner(runner Runner) *Chain {
+	c.runner = runner
+	return c
+}
+
+// Then adds a step that runs one query. opts override the shared options
+// for this step only.
+func (c *Chain) Then(name string, prompt PromptFunc, opts ...claudecode.Option) *Chain {
+	c.steps = append(c.steps, step{name: name, prompt: prompt, options: opts})
+	return c
+}
+
+// Map adds a step that runs one query per item, such as per file, with up
+// to parallelism queries at once (claudecode.DefaultBatchParallelism if
+// zero). Its Output holds an Output per item in Items, and their texts
+// joined by blank lines in Text, ready for a reducing step. The step fails
+// if any item fails.
+func (c *Chain) Map(name string, items []string, prompt ItemPromptFunc, parallelism int, opts ...claudecode.Option) *Chain {
+	shards := make([][]string, len(items))
+	for i, item := range items {
+		shards[i] = []string{item}
+	}
+	shardPrompt := func(shard []string, prev *Output) (string, error) {
+		return prompt(shard[0], prev)
+	}
+	c.steps = append(c.steps, step{name: name, shards: shards, shardPrompt: shardPrompt, mapped: true, parallelism: parallelism, options: opts})
+	return c
+}
+
+// FanOut adds a step that splits items, such as files or directories to
+// review, into at most n shards with Shard and runs one query per shard,
+// all at once. Run the chain with a client whose ClientOptions.Pool
+// bounds how many CLI processes run together. Its Output holds an Output
+// per shard in Items, and their texts joined by blank lines in Text; a
+// following step built with Merge combines them into one report.
+//
+// If any shard fails, the queries of the others are cancelled and the
+// step fails with that shard's error.
+func (c *Chain) FanOut(name string, items []string, n int, prompt ShardPromptFunc, opts ...claudecode.Option) *Chain {
+	shards := Shard(items, n)
+	c.steps = append(c.steps, step{name: name, shards: shards, shardPrompt: prompt, parallelism: len(shards), options: opts})
+	return c
+}
+
+// Run runs the steps in order, stopping at the first that fails. The
+// result holds the output of every step that completed, and the usage of
+// every query that ran, including those of a failed step.
+func (c *Chain) Run(ctx context.Context) (*Result, error) {
+	result := &Result{}
+	tracker := claudecode.NewUsageTracker()
+
+	var prev *Output
+	for _, s := range c.steps {
+		out, err := c.runStep(ctx, s, prev, tracker)
+		result.Usage = tracker.Snapshot()
+		if err != nil {
+			return result, &StepError{Step: s.name, Err: err}
+		}
+		result.Steps = append(result.Steps, out)
+		prev = out
+	}
+
+	return result, nil
+}
+
+// runStep runs a single, Map, or FanOut step.
+func (c *Chain) runStep(ctx context.Context, s step, prev *Output, tracker *claudecode.UsageTracker) (*Output, error) {
+	options := claudecode.BuildOptions(append([]claudecode.Option{claudecode.WithOptions(c.options)}, s.options...)...)
+
+	if s.shardPrompt == nil {
+		prompt, err := s.prompt(prev)
+		if err != nil {
+			return nil, fmt.Errorf("building prompt: %w", err)
+		}
+		res, err := c.run(ctx, prompt, options)
+		if res != nil {
+			tracker.Record(res.Result)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Output{Step: s.name, Text: res.Text, Result: res}, nil
+	}
+
+	prompts := make([]string, len(s.shards))
+	for i, shard := range s.shards {
+		prompt, err := s.shardPrompt(shard, prev)
+		if err != nil {
+			return nil, fmt.Errorf("building prompt for %s: %w", s.label(i), err)
+		}
+		prompts[i] = prompt
+	}
+
+	out := &Output{Step: s.name}
+	var failed error
+	for i, r := range c.runItems(ctx, prompts, options, s.parallelism) {
+		if r.Result != nil {
+			tracker.Record(r.Result.Result)
+		}
+		if r.Err != nil {
+			// Report the error that cancelled the others, not theirs
+			if failed == nil || errors.Is(failed, context.Canceled) && !errors.Is(r.Err, context.Canceled) {
+				failed = fmt.Errorf("%s: %w", s.label(i), r.Err)
+			}
+			continue
+		}
+
+		item := &Output{Step: s.name, Text: r.Result.Text, Result: r.Result}
+		if s.mapped {
+			item.Item = s.shards[i][0]
+		} else {
+			item.Shard = s.shards[i]
+		}
+		out.Items = append(out.Items, item)
+	}
+	if failed != nil {
+		return nil, failed
+	}
+
+	texts := make([]string, len(out.Items))
+	for i, item := range out.Items {
+		texts[i] = item.Text
+	}
+	out.Text = strings.Join(texts, "\n\n")
+	return out, nil
+}
+
+// label names shard i of a Map or FanOut step in errors.
+func (s step) label(i int) string {
+	if s.mapped {
+		return fmt.Sprintf("item %q", s.shards[i][0])
+	}
+	return fmt.Sprintf("shard %d", i+1)
+}
+
+// run runs one query with the runner, or the client.
+func (c *Chain) run(ctx context.Context, prompt string, options *claudecode.Options) (*claudecode.QueryResult, error) {
+	switch {
+	case c.runner != nil:
+		return c.runner(ctx, prompt, options)
+	case c.client != nil:
+		return c.client.QuerySync(ctx, prompt, options)
+	}
+	return claudecode.QuerySync(ctx, prompt, options)
+}
+
+// runItems runs the queries of a Map step with bounded parallelism,
+// cancelling those still running once one fails, as QueryBatch does with
+// StopOnError.
+func (c *Chain) runItems(ctx context.Context, prompts []string, options *claudecode.Options, parallelism int) []claudecode.BatchResult {
+	if parallelism <= 0 {
+		parallelism = claudecode.DefaultBatchParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]claudecode.BatchResult, len(prompts))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, prompt := range prompts {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			results[i] = claudecode.BatchResult{Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := c.run(ctx, prompt, options.Clone())
+			results[i] = claudecode.BatchResult{Result: result, Err: err}
+			if err != nil {
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
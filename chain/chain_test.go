@@ -0,0 +1,210 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// fakeRunner answers each prompt with reply and records the calls.
+type fakeRunner struct {
+	mu      sync.Mutex
+	prompts []string
+	options []*claudecode.Options
+	reply   func(prompt string) (string, error)
+}
+
+func (f *fakeRunner) run(_ context.Context, prompt string, options *claudecode.Options) (*claudecode.QueryResult, error) {
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	f.options = append(f.options, options)
+	f.mu.Unlock()
+
+	cost := 0.01
+	result := &claudecode.QueryResult{Result: &claudecode.ResultMessage{Subtype: "success", NumTurns: 1, TotalCostUSD: &cost}}
+	text, err := f.reply(prompt)
+	if err != nil {
+		result.Result.IsError = true
+		return result, err
+	}
+	result.Text = text
+	return result, nil
+}
+
+func TestChainPassesOutputToNextStep(t *testing.T) {
+	fake := &fakeRunner{reply: func(prompt string) (string, error) {
+		if strings.HasPrefix(prompt, "Plan") {
+			return "1. Add a retry loop", nil
+		}
+		return "Done", nil
+	}}
+
+	shared := claudecode.NewOptions().WithModel("sonnet")
+	c := New(shared).WithRunner(fake.run).
+		Then("plan", Prompt("Plan the change"), claudecode.WithPermissionMode(claudecode.PermissionModePlan)).
+		Then("execute", Template("Carry out:\n{{.Text}}"))
+
+	result, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(fake.prompts) != 2 || fake.prompts[1] != "Carry out:\n1. Add a retry loop" {
+		t.Errorf("Unexpected prompts: %q", fake.prompts)
+	}
+	if final := result.Final(); final.Step != "execute" || final.Text != "Done" {
+		t.Errorf("Unexpected final output: %+v", final)
+	}
+	if plan := result.Step("plan"); plan == nil || plan.Result == nil {
+		t.Errorf("Expected the plan step's output, got %+v", plan)
+	}
+
+	planOptions, executeOptions := fake.options[0], fake.options[1]
+	if planOptions.PermissionMode == nil || *planOptions.PermissionMode != claudecode.PermissionModePlan {
+		t.Errorf("Expected the plan step's override, got %v", planOptions.PermissionMode)
+	}
+	if executeOptions.PermissionMode != nil {
+		t.Errorf("Expected the override to apply to one step, got %v", *executeOptions.PermissionMode)
+	}
+	for i, options := range fake.options {
+		if options.Model == nil || *options.Model != "sonnet" {
+			t.Errorf("Step %d did not get the shared model", i)
+		}
+	}
+	if shared.PermissionMode != nil {
+		t.Error("Expected the shared options to be unchanged")
+	}
+
+	if result.Usage.Queries != 2 || result.Usage.Turns != 2 {
+		t.Errorf("Unexpected usage: %+v", result.Usage)
+	}
+}
+
+func TestChainMapReduce(t *testing.T) {
+	fake := &fakeRunner{reply: func(prompt string) (string, error) {
+		if file, ok := strings.CutPrefix(prompt, "Review "); ok {
+			return "notes on " + file, nil
+		}
+		return "summary", nil
+	}}
+
+	files := []string{"a.go", "b.go", "c.go"}
+	c := New(nil).WithRunner(fake.run).
+		Map("review", files, func(item string, _ *Output) (string, error) {
+			return "Review " + item, nil
+		}, 2).
+		Then("reduce", Template("Summarize:\n{{.Text}}"))
+
+	result, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	review := result.Step("review")
+	if len(review.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(review.Items))
+	}
+	for i, item := range review.Items {
+		if item.Item != files[i] || item.Text != "notes on "+files[i] {
+			t.Errorf("Unexpected item %d: %+v", i, item)
+		}
+	}
+
+	want := "Summarize:\nnotes on a.go\n\nnotes on b.go\n\nnotes on c.go"
+	if got := fake.prompts[len(fake.prompts)-1]; got != want {
+		t.Errorf("Expected reduce prompt %q, got %q", want, got)
+	}
+	if result.Usage.Queries != 4 {
+		t.Errorf("Expected usage of 4 queries, got %d", result.Usage.Queries)
+	}
+}
+
+func TestChainStepError(t *testing.T) {
+	errBoom := errors.New("boom")
+	fake := &fakeRunner{reply: func(prompt string) (string, error) {
+		if prompt == "second" {
+			return "", errBoom
+		}
+		return "ok", nil
+	}}
+
+	c := New(nil).WithRunner(fake.run).
+		Then("first", Prompt("first")).
+		Then("second", Prompt("second")).
+		Then("third", Prompt("third"))
+
+	result, err := c.Run(context.Background())
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "second" || !errors.Is(err, errBoom) {
+		t.Fatalf("Expected a StepError for the second step wrapping errBoom, got %v", err)
+	}
+	if len(result.Steps) != 1 || len(fake.prompts) != 2 {
+		t.Errorf("Expected the chain to stop after the failed step, got %d steps and %d queries", len(result.Steps), len(fake.prompts))
+	}
+	if result.Usage.Queries != 2 || result.Usage.Errors != 1 {
+		t.Errorf("Expected usage to include the failed query, got %+v", result.Usage)
+	}
+}
+
+func TestChainMapItemError(t *testing.T) {
+	fake := &fakeRunner{reply: func(prompt string) (string, error) {
+		if prompt == "b" {
+			return "", fmt.Errorf("cannot read %s", prompt)
+		}
+		return prompt, nil
+	}}
+
+	c := New(nil).WithRunner(fake.run).
+		Map("each", []string{"a", "b"}, func(item string, _ *Output) (string, error) {
+			return item, nil
+		}, 1)
+
+	_, err := c.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `step "each": item "b": cannot read b`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestTemplateMissingField(t *testing.T) {
+	_, err := Template("{{.Missing}}")(&Output{})
+	if err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestOutputDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain", `{"files": ["a.go"]}`, []string{"a.go"}},
+		{"fenced", "Here they are:\n```json\n{\"files\": [\"draft.go\"]}\n```\nFinal:\n```json\n{\"files\": [\"b.go\"]}\n```", []string{"b.go"}},
+		{"embedded", `The files are {"files": ["c.go", "d.go"]} as requested.`, []string{"c.go", "d.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Files []string `json:"files"`
+			}
+			if err := (&Output{Text: tt.text}).Decode(&got); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if strings.Join(got.Files, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got.Files)
+			}
+		})
+	}
+
+	var v map[string]any
+	if err := (&Output{Text: "No JSON here."}).Decode(&v); !errors.Is(err, ErrNoJSON) {
+		t.Errorf("Expected ErrNoJSON, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// Shard splits items into at most n shards of nearly equal size, keeping
+// their order so that neighbouring files, such as those of one directory,
+// tend to share a shard. If n is zero or less,
+// claudecode.DefaultBatchParallelism is used.
+func Shard(items []string, n int) [][]string {
+	if n <= 0 {
+		n = claudecode.DefaultBatchParallelism
+	}
+	n = min(n, len(items))
+
+	shards := make([][]string, 0, n)
+	for i := range n {
+		start, end := i*len(items)/n, (i+1)*len(items)/n
+		shards = append(shards, items[start:end])
+	}
+	return shards
+}
+
+// Merge returns a PromptFunc for the step after a FanOut or Map step. It
+// gives instructions, such as "Combine these reviews into one report,
+// most severe findings first.", followed by each shard's or item's text
+// under a heading naming what it covered.
+func Merge(instructions string) PromptFunc {
+	return func(prev *Output) (string, error) {
+		if prev == nil {
+			return "", fmt.Errorf("nothing to merge")
+		}
+
+		var b strings.Builder
+		b.WriteString(strings.TrimRight(instructions, "\n"))
+		for i, item := range prev.Items {
+			fmt.Fprintf(&b, "\n\n## %s\n\n%s", heading(i, item), strings.TrimRight(item.Text, "\n"))
+		}
+		return b.String(), nil
+	}
+}
+
+// heading names what an item of a Map or FanOut step covered.
+func heading(i int, item *Output) string {
+	switch {
+	case item.Item != "":
+		return item.Item
+	case len(item.Shard) > 0:
+		return strings.Join(item.Shard, ", ")
+	}
+	return fmt.Sprintf("Part %d", i+1)
+}
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+func TestShard(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "[[a b c d e]]"},
+		{2, "[[a b] [c d e]]"},
+		{3, "[[a] [b c] [d e]]"},
+		{10, "[[a] [b] [c] [d] [e]]"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprint(Shard(items, tt.n)); got != tt.want {
+			t.Errorf("Shard(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+
+	if shards := Shard(nil, 4); len(shards) != 0 {
+		t.Errorf("Expected no shards for no items, got %v", shards)
+	}
+}
+
+func TestFanOutMerge(t *testing.T) {
+	fake := &fakeRunner{reply: func(prompt string) (string, error) {
+		if files, ok := strings.CutPrefix(prompt, "Review "); ok {
+			return "findings for " + files, nil
+		}
+		return "report", nil
+	}}
+
+	c := New(nil).WithRunner(fake.run).
+		FanOut("review", []string{"a.go", "b.go", "c.go", "d.go"}, 2, func(shard []string, _ *Output) (string, error) {
+			return "Review " + strings.Join(shard, " "), nil
+		}).
+		Then("merge", Merge("Combine these reviews."))
+
+	result, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	review := result.Step("review")
+	if len(review.Items) != 2 || strings.Join(review.Items[1].Shard, ",") != "c.go,d.go" {
+		t.Fatalf("Unexpected shard outputs: %+v", review.Items)
+	}
+
+	want := "Combine these reviews.\n\n## a.go, b.go\n\nfindings for a.go b.go\n\n## c.go, d.go\n\nfindings for c.go d.go"
+	if got := fake.prompts[2]; got != want {
+		t.Errorf("Expected merge prompt %q, got %q", want, got)
+	}
+	if result.Final().Text != "report" || result.Usage.Queries != 3 {
+		t.Errorf("Unexpected result: %q, %+v", result.Final().Text, result.Usage)
+	}
+}
+
+func TestFanOutCancelsOtherShards(t *testing.T) {
+	errReview := errors.New("review failed")
+	runner := func(ctx context.Context, prompt string, _ *claudecode.Options) (*claudecode.QueryResult, error) {
+		if prompt == "b" {
+			return nil, errReview
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return &claudecode.QueryResult{Text: prompt}, nil
+		}
+	}
+
+	merged := false
+	c := New(nil).WithRunner(runner).
+		FanOut("review", []string{"a", "b", "c"}, 3, func(shard []string, _ *Output) (string, error) {
+			return shard[0], nil
+		}).
+		Then("merge", func(*Output) (string, error) {
+			merged = true
+			return "", nil
+		})
+
+	start := time.Now()
+	_, err := c.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the other shards to be cancelled, took %v", elapsed)
+	}
+	if !errors.Is(err, errReview) || !strings.Contains(err.Error(), "shard 2") {
+		t.Errorf("Expected the failed shard's error, got %v", err)
+	}
+	if merged {
+		t.Error("Expected the merge step not to run")
+	}
+}
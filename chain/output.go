@@ -0,0 +1,128 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// ErrNoJSON is returned by Output.Decode when the output holds no JSON.
+var ErrNoJSON = errors.New("chain: no JSON in output")
+
+// Output is what a step produced.
+type Output struct {
+	// Step is the name of the step.
+	Step string
+
+	// Item is the item a Map step's query ran for; it is empty otherwise.
+	Item string
+
+	// Shard holds the items a FanOut step's query ran for.
+	Shard []string
+
+	// Text is the assistant's text. For Map and FanOut steps it joins the
+	// texts of Items, separated by blank lines.
+	Text string
+
+	// Result is the step's query result; it is nil for Map and FanOut
+	// steps.
+	Result *claudecode.QueryResult
+
+	// Items holds the output of each item of a Map step, or each shard of
+	// a FanOut step, in order.
+	Items []*Output
+}
+
+// Decode unmarshals structured output from the text into v. The JSON may
+// be the whole text, the last ```json fenced block, or the first object or
+// array in the text, so prompts can ask for JSON without the reply having
+// to be nothing else.
+func (o *Output) Decode(v any) error {
+	data, ok := extractJSON(o.Text)
+	if !ok {
+		return ErrNoJSON
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("chain: decoding output of step %q: %w", o.Step, err)
+	}
+	return nil
+}
+
+// extractJSON finds the JSON in text.
+func extractJSON(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text, true
+	}
+
+	if i := strings.LastIndex(text, "```json"); i >= 0 {
+		block := text[i+len("```json"):]
+		if end := strings.Index(block, "```"); end >= 0 {
+			block = strings.TrimSpace(block[:end])
+			if json.Valid([]byte(block)) {
+				return block, true
+			}
+		}
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", false
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	for end := strings.LastIndex(text, closing); end > start; end = strings.LastIndex(text[:end], closing) {
+		if span := text[start : end+1]; json.Valid([]byte(span)) {
+			return span, true
+		}
+	}
+	return "", false
+}
+
+// Result is the outcome of running a chain.
+type Result struct {
+	// Steps holds the output of each step that completed, in order.
+	Steps []*Output
+
+	// Usage totals the usage of every query the chain ran.
+	Usage claudecode.Usage
+}
+
+// Final returns the output of the last step that completed, or nil if
+// none did.
+func (r *Result) Final() *Output {
+	if len(r.Steps) == 0 {
+		return nil
+	}
+	return r.Steps[len(r.Steps)-1]
+}
+
+// Step returns the output of the named step, or nil if it did not
+// complete.
+func (r *Result) Step(name string) *Output {
+	for _, out := range r.Steps {
+		if out.Step == name {
+			return out
+		}
+	}
+	return nil
+}
+
+// StepError reports which step of a chain failed.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("chain: step %q: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
@@ -50,11 +50,10 @@ package claudecode
 import (
 	"context"
 	client2 "github.com/jrossi/claude-code-sdk-golang/client"
+	"github.com/jrossi/claude-code-sdk-golang/transport"
+	"time"
 )
 
-// defaultClient is the package-level client instance used by the Query function.
-var defaultClient = client2.NewClient()
-
 // Query initiates a query to Claude Code and returns a stream for receiving messages.
 // This is the main entry point for the SDK, providing a simple interface for most use cases.
 //
@@ -84,11 +83,18 @@ var defaultClient = client2.NewClient()
 //	defer cancel()
 //	stream, err := claudecode.Query(ctx, "Hello", nil)
 func Query(ctx context.Context, prompt string, options *Options) (*QueryStream, error) {
-	internal, err := defaultClient.Query(ctx, prompt, options)
-	if err != nil {
-		return nil, err
-	}
-	return wrapQueryStream(internal), nil
+	return defaultClient.Query(ctx, prompt, options)
+}
+
+// QueryPrompt initiates a query with a multi-part prompt built with
+// NewPrompt, such as text with images or file references.
+//
+// Example:
+//
+//	prompt := claudecode.NewPrompt("Describe this diagram").ImageFile("arch.png")
+//	stream, err := claudecode.QueryPrompt(ctx, prompt, nil)
+func QueryPrompt(ctx context.Context, prompt *Prompt, options *Options) (*QueryStream, error) {
+	return defaultClient.QueryPrompt(ctx, prompt, options)
 }
 
 // QueryWithCLIPath initiates a query using a specific Claude Code CLI binary path.
@@ -113,15 +119,68 @@ func Query(ctx context.Context, prompt string, options *Options) (*QueryStream,
 //		"/usr/local/bin/claude"
 //	)
 func QueryWithCLIPath(ctx context.Context, prompt string, options *Options, cliPath string) (*QueryStream, error) {
-	internal, err := defaultClient.QueryWithCLIPath(ctx, prompt, options, cliPath)
-	if err != nil {
-		return nil, err
-	}
-	return wrapQueryStream(internal), nil
+	return defaultClient.QueryWithCLIPath(ctx, prompt, options, cliPath)
+}
+
+// QueryWithTransport initiates a query over a caller-supplied transport.
+// This allows custom transports (SSH, containers, test doubles) to be used
+// without reimplementing the parsing and streaming pipeline.
+//
+// Transports that implement ConfigurableTransport receive the prompt and
+// options before connecting; other transports must already be set up for the
+// query. Options that need the control protocol, such as hooks, require a
+// transport that also implements InputTransport.
+//
+// Example:
+//
+//	t := transport.NewSubprocessTransport(&transport.Config{CLIPath: "/opt/claude"})
+//	stream, err := claudecode.QueryWithTransport(ctx, "Hello", nil, t)
+func QueryWithTransport(ctx context.Context, prompt string, options *Options, transport Transport) (*QueryStream, error) {
+	return defaultClient.QueryWithTransport(ctx, prompt, options, transport)
+}
+
+// CLIVersion returns the version of the installed Claude Code CLI, found
+// the same way as for Query, by running "claude --version".
+//
+// Example:
+//
+//	version, err := claudecode.CLIVersion(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if !version.AtLeast(claudecode.Version{Major: 1, Minor: 0, Patch: 50}) {
+//		log.Printf("Claude Code %s is outdated", version)
+//	}
+func CLIVersion(ctx context.Context) (Version, error) {
+	return transport.CLIVersion(ctx, "")
+}
+
+// EnsureOptions configures EnsureCLI.
+type EnsureOptions = transport.EnsureOptions
+
+// EnsureCLI returns the path of a usable Claude Code CLI, installing it with
+// npm, or downloading it, into a managed directory if none is found. This
+// lets services provision the CLI on fresh hosts instead of failing with a
+// CLINotFoundError.
+//
+// Example:
+//
+//	cliPath, err := claudecode.EnsureCLI(ctx, claudecode.EnsureOptions{
+//		Version: "1.0.51",
+//		SHA256:  "<checksum of the package tarball>",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	client := claudecode.NewClient(claudecode.ClientOptions{CLIPath: cliPath})
+func EnsureCLI(ctx context.Context, opts EnsureOptions) (string, error) {
+	return transport.EnsureCLI(ctx, opts)
 }
 
 // SetParserBufferSize configures the maximum buffer size for JSON parsing.
 // This affects all subsequent queries made with the package-level Query function.
+// Use NewClient to configure the buffer size for a subset of queries, or
+// Options.WithMaxBufferSize for a single query.
 //
 // The buffer size limits memory usage when processing large JSON responses from
 // Claude Code. If a single JSON message exceeds this size, it will be rejected
@@ -214,11 +273,39 @@ func (qs *QueryStream) Close() error {
 	return qs.internal.Close()
 }
 
+// Interrupt asks Claude to stop the current generation or tool call without
+// closing the stream. The stream still ends with a ResultMessage once the
+// CLI has stopped.
+func (qs *QueryStream) Interrupt(ctx context.Context) error {
+	return qs.internal.Interrupt(ctx)
+}
+
 // IsClosed returns true if the stream has been closed.
 func (qs *QueryStream) IsClosed() bool {
 	return qs.internal.IsClosed()
 }
 
+// Init returns the session information from the CLI's init message, or nil
+// if it has not arrived yet.
+func (qs *QueryStream) Init() *InitInfo {
+	return qs.internal.Init()
+}
+
+// LastActivity returns when the CLI last produced output, or when the
+// stream was created if it has produced none. Long-running consumers can
+// compare it with their own limits to tell a slow tool call from a wedged
+// process.
+func (qs *QueryStream) LastActivity() time.Time {
+	return qs.internal.LastActivity()
+}
+
+// Changes returns the files changed so far by the query's Write, Edit,
+// MultiEdit, and NotebookEdit tool uses, and simple rm commands, in the
+// order they were first changed.
+func (qs *QueryStream) Changes() []FileChange {
+	return qs.internal.Changes()
+}
+
 // wrapQueryStream wraps an internal QueryStream to provide the public API.
 func wrapQueryStream(internal *client2.QueryStream) *QueryStream {
 	return &QueryStream{internal: internal}
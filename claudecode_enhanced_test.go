@@ -408,7 +408,7 @@ func TestToolResultBlockEdgeCases(t *testing.T) {
 			name: "empty content string",
 			block: &ToolResultBlock{
 				ToolUseID: "tool_456",
-				Content:   stringPtr(""),
+				Content:   []ToolResultContent{&TextContent{}},
 				IsError:   boolPtr(false),
 			},
 			wantID: "tool_456",
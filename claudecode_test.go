@@ -238,7 +238,7 @@ func TestContentBlockTypes(t *testing.T) {
 	}
 	toolResultBlock := &ToolResultBlock{
 		ToolUseID: "tool_123",
-		Content:   stringPtr("File contents"),
+		Content:   []ToolResultContent{&TextContent{Text: "File contents"}},
 		IsError:   boolPtr(false),
 	}
 
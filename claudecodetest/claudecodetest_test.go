@@ -0,0 +1,179 @@
+package claudecodetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/claudecodetest"
+)
+
+func TestTransportPlaysScript(t *testing.T) {
+	fake := claudecodetest.NewTransport().
+		Add(claudecodetest.SystemInit("session-1")).
+		Add(claudecodetest.Assistant(
+			claudecodetest.Thinking("hmm", "sig"),
+			claudecodetest.ToolUse("tool-1", "Read", map[string]any{"file_path": "main.go"}),
+		)).
+		Add(claudecodetest.ToolResults(claudecodetest.ToolResult("tool-1", "package main", false))).
+		Add(claudecodetest.AssistantText("Done")).
+		Add(claudecodetest.Result("session-1", claudecodetest.WithCost(0.02), claudecodetest.WithTurns(2)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	messages, err := queryWith(ctx, fake)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if len(messages) != 5 {
+		t.Fatalf("Expected 5 messages, got %d", len(messages))
+	}
+
+	assistant, ok := messages[1].(*claudecode.AssistantMessage)
+	if !ok || len(assistant.Content) != 2 {
+		t.Fatalf("Expected assistant message with 2 blocks, got %#v", messages[1])
+	}
+	if tool, ok := assistant.Content[1].(*claudecode.ToolUseBlock); !ok || tool.Name != "Read" {
+		t.Errorf("Expected Read tool use, got %#v", assistant.Content[1])
+	}
+
+	result, ok := messages[4].(*claudecode.ResultMessage)
+	if !ok || result.NumTurns != 2 {
+		t.Fatalf("Expected result with 2 turns, got %#v", messages[4])
+	}
+	if result.TotalCostUSD == nil || *result.TotalCostUSD != 0.02 {
+		t.Errorf("Expected cost 0.02, got %v", result.TotalCostUSD)
+	}
+	if result.SessionID != "session-1" {
+		t.Errorf("Expected session ID 'session-1', got %q", result.SessionID)
+	}
+
+	if config := fake.Config(); config == nil || config.Prompt != "Hello" {
+		t.Errorf("Expected configured prompt 'Hello', got %#v", config)
+	}
+}
+
+func TestResultPermissionDenials(t *testing.T) {
+	fake := claudecodetest.NewTransport().
+		Add(claudecodetest.AssistantText("I need permission to run that")).
+		Add(claudecodetest.Result("session-1", claudecodetest.WithPermissionDenials(claudecode.PermissionDenial{
+			ToolName:  "Bash",
+			ToolUseID: "tool-1",
+			ToolInput: map[string]any{"command": "make"},
+		})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	messages, err := queryWith(ctx, fake)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	result, ok := messages[len(messages)-1].(*claudecode.ResultMessage)
+	if !ok {
+		t.Fatalf("Expected result message, got %#v", messages[len(messages)-1])
+	}
+	if !result.FailedOnPermissions() {
+		t.Errorf("Expected run to have failed on permissions, got %#v", result.PermissionDenials)
+	}
+	if denial := result.PermissionDenials[0]; denial.ToolInput["command"] != "make" {
+		t.Errorf("Expected denied command 'make', got %#v", denial)
+	}
+}
+
+func TestTransportInjectsErrors(t *testing.T) {
+	injected := errors.New("boom")
+	fake := claudecodetest.NewTransport().
+		Add(claudecodetest.AssistantText("partial")).
+		AddError(injected)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := claudecode.QueryWithTransport(ctx, "Hello", nil, fake)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	var gotErr error
+	for err := range stream.Errors() {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, injected) {
+		t.Errorf("Expected injected error, got %v", gotErr)
+	}
+}
+
+func TestTransportFailConnect(t *testing.T) {
+	fake := claudecodetest.NewTransport().FailConnect(claudecode.ErrCLINotFound)
+
+	_, err := claudecode.QueryWithTransport(context.Background(), "Hello", nil, fake)
+	if claudecode.ErrorKind(err) != claudecode.KindCLINotFound {
+		t.Errorf("Expected CLI not found error, got %v", err)
+	}
+}
+
+func TestTransportLatency(t *testing.T) {
+	fake := claudecodetest.NewTransport().
+		WithLatency(20 * time.Millisecond).
+		Add(claudecodetest.Conversation("session-1", "Hi")...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := queryWith(ctx, fake); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected at least 60ms of latency, took %v", elapsed)
+	}
+}
+
+func TestTransportRecordsInterrupts(t *testing.T) {
+	fake := claudecodetest.NewTransport().
+		Add(claudecodetest.AssistantText("working")).
+		AddDelay(time.Second).
+		Add(claudecodetest.Result("session-1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := claudecode.QueryWithTransport(ctx, "Hello", nil, fake)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	<-stream.Messages()
+	if err := stream.Interrupt(ctx); err != nil {
+		t.Fatalf("Interrupt failed: %v", err)
+	}
+	if fake.Interrupts() != 1 {
+		t.Errorf("Expected 1 interrupt, got %d", fake.Interrupts())
+	}
+}
+
+// queryWith runs a query over the fake transport and collects its messages.
+func queryWith(ctx context.Context, fake *claudecodetest.Transport) ([]claudecode.Message, error) {
+	stream, err := claudecode.QueryWithTransport(ctx, "Hello", nil, fake)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var messages []claudecode.Message
+	for msg := range stream.Messages() {
+		messages = append(messages, msg)
+	}
+	for err := range stream.Errors() {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,155 @@
+package claudecodetest
+
+import (
+	"encoding/json"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// The builders below produce lines in the CLI's stream-json output format,
+// ready to be added to a Transport script.
+
+// Line encodes an arbitrary value as an output line, for message types the
+// builders do not cover.
+func Line(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("claudecodetest: cannot encode line: " + err.Error())
+	}
+	return string(data)
+}
+
+// SystemInit builds the init system message the CLI sends first.
+func SystemInit(sessionID string) string {
+	return Line(map[string]any{
+		"type":       "system",
+		"subtype":    "init",
+		"session_id": sessionID,
+	})
+}
+
+// User builds a user message with text content.
+func User(text string) string {
+	return Line(map[string]any{
+		"type":    "user",
+		"message": map[string]any{"role": "user", "content": text},
+	})
+}
+
+// Assistant builds an assistant message from content blocks such as those
+// built by Text, Thinking, and ToolUse.
+func Assistant(blocks ...map[string]any) string {
+	content := make([]any, len(blocks))
+	for i, block := range blocks {
+		content[i] = block
+	}
+	return Line(map[string]any{
+		"type":    "assistant",
+		"message": map[string]any{"role": "assistant", "content": content},
+	})
+}
+
+// AssistantText builds an assistant message with a single text block.
+func AssistantText(text string) string {
+	return Assistant(Text(text))
+}
+
+// ToolResults builds the user message carrying tool results back to Claude.
+func ToolResults(blocks ...map[string]any) string {
+	content := make([]any, len(blocks))
+	for i, block := range blocks {
+		content[i] = block
+	}
+	return Line(map[string]any{
+		"type":    "user",
+		"message": map[string]any{"role": "user", "content": content},
+	})
+}
+
+// Text builds a text content block.
+func Text(text string) map[string]any {
+	return map[string]any{"type": "text", "text": text}
+}
+
+// Thinking builds a thinking content block.
+func Thinking(thinking, signature string) map[string]any {
+	return map[string]any{"type": "thinking", "thinking": thinking, "signature": signature}
+}
+
+// ToolUse builds a tool use content block.
+func ToolUse(id, name string, input map[string]any) map[string]any {
+	if input == nil {
+		input = map[string]any{}
+	}
+	return map[string]any{"type": "tool_use", "id": id, "name": name, "input": input}
+}
+
+// ToolResult builds a tool result content block.
+func ToolResult(toolUseID, content string, isError bool) map[string]any {
+	return map[string]any{"type": "tool_result", "tool_use_id": toolUseID, "content": content, "is_error": isError}
+}
+
+// ResultOption customizes a result message built by Result.
+type ResultOption func(map[string]any)
+
+// WithCost sets the result's total cost in USD.
+func WithCost(usd float64) ResultOption {
+	return func(m map[string]any) { m["total_cost_usd"] = usd }
+}
+
+// WithResultText sets the result's final text.
+func WithResultText(text string) ResultOption {
+	return func(m map[string]any) { m["result"] = text }
+}
+
+// WithTurns sets the result's number of turns.
+func WithTurns(turns int) ResultOption {
+	return func(m map[string]any) { m["num_turns"] = turns }
+}
+
+// WithUsage sets the result's token usage.
+func WithUsage(usage map[string]any) ResultOption {
+	return func(m map[string]any) { m["usage"] = usage }
+}
+
+// WithPermissionDenials sets the tool uses the result reports as denied.
+func WithPermissionDenials(denials ...types.PermissionDenial) ResultOption {
+	return func(m map[string]any) { m["permission_denials"] = denials }
+}
+
+// WithError marks the result as an error with the given subtype, such as
+// "error_max_turns" or "error_during_execution".
+func WithError(subtype string) ResultOption {
+	return func(m map[string]any) {
+		m["subtype"] = subtype
+		m["is_error"] = true
+	}
+}
+
+// Result builds the result message that ends a query. It reports success
+// unless WithError is given.
+func Result(sessionID string, opts ...ResultOption) string {
+	m := map[string]any{
+		"type":            "result",
+		"subtype":         "success",
+		"session_id":      sessionID,
+		"is_error":        false,
+		"num_turns":       1,
+		"duration_ms":     0,
+		"duration_api_ms": 0,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return Line(m)
+}
+
+// Conversation builds the lines of a complete single-turn query: the init
+// message, an assistant text reply, and a successful result.
+func Conversation(sessionID, reply string) []string {
+	return []string{
+		SystemInit(sessionID),
+		AssistantText(reply),
+		Result(sessionID, WithResultText(reply)),
+	}
+}
@@ -0,0 +1,261 @@
+// Package claudecodetest provides a scriptable fake transport and builders
+// for CLI messages, so applications can unit test code that consumes
+// Claude Code streams without running the CLI.
+//
+// Example:
+//
+//	fake := claudecodetest.NewTransport().
+//		Add(claudecodetest.SystemInit("session-1")).
+//		Add(claudecodetest.AssistantText("Hello!")).
+//		Add(claudecodetest.Result("session-1"))
+//
+//	stream, err := claudecode.QueryWithTransport(ctx, "Hi", nil, fake)
+package claudecodetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/transport"
+)
+
+// step is one scripted event: a line of output, an error, or a pause.
+type step struct {
+	line  []byte
+	err   error
+	delay time.Duration
+}
+
+// Transport is a fake transport that replays a script of CLI output lines,
+// errors, and pauses. It implements transport.Transport,
+// transport.InputTransport, transport.Interrupter, and
+// transport.Configurable, recording the query configuration, the input
+// written to it, and the interrupts it receives.
+//
+// Build the script before the transport is used. Each call to Stream plays
+// the whole script once.
+type Transport struct {
+	mu          sync.Mutex
+	script      []step
+	latency     time.Duration
+	connectErr  error
+	config      *transport.Config
+	connected   bool
+	inputClosed bool
+	written     [][]byte
+	interrupts  int
+	cancel      context.CancelFunc
+}
+
+// NewTransport creates a fake transport with an empty script.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Add appends output lines to the script, such as those built by
+// AssistantText or Result.
+func (t *Transport) Add(lines ...string) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, line := range lines {
+		t.script = append(t.script, step{line: []byte(line)})
+	}
+	return t
+}
+
+// AddError appends an error to the script. It is delivered on the error
+// channel at that point in the output.
+func (t *Transport) AddError(err error) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.script = append(t.script, step{err: err})
+	return t
+}
+
+// AddDelay appends a pause to the script.
+func (t *Transport) AddDelay(d time.Duration) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.script = append(t.script, step{delay: d})
+	return t
+}
+
+// WithLatency delays every output line by d, simulating a slow CLI.
+func (t *Transport) WithLatency(d time.Duration) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latency = d
+	return t
+}
+
+// FailConnect makes Connect return err, simulating a CLI that cannot start.
+func (t *Transport) FailConnect(err error) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectErr = err
+	return t
+}
+
+// Configure records the query's configuration.
+func (t *Transport) Configure(config *transport.Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config = config
+}
+
+// Config returns the configuration passed to Configure, which includes the
+// query's prompt and options, or nil if it was not called.
+func (t *Transport) Config() *transport.Config {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.config
+}
+
+// Connect marks the transport connected, or returns the error set with
+// FailConnect.
+func (t *Transport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connectErr != nil {
+		return t.connectErr
+	}
+	t.connected = true
+	return nil
+}
+
+// Stream plays the script. Both channels are closed when the script ends,
+// the context is cancelled, or the transport is closed.
+func (t *Transport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, 10)
+	errChan := make(chan error, 10)
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancel = cancel
+	script := append([]step(nil), t.script...)
+	latency := t.latency
+	connected := t.connected
+	t.mu.Unlock()
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+		defer cancel()
+
+		if !connected {
+			errChan <- errors.New("transport not connected")
+			return
+		}
+
+		for _, s := range script {
+			delay := s.delay
+			if s.line != nil {
+				delay += latency
+			}
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			switch {
+			case s.line != nil:
+				select {
+				case dataChan <- s.line:
+				case <-ctx.Done():
+					return
+				}
+			case s.err != nil:
+				select {
+				case errChan <- s.err:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+// Write records a message written to the CLI's stdin.
+func (t *Transport) Write(ctx context.Context, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return errors.New("transport not connected")
+	}
+	if t.inputClosed {
+		return errors.New("input closed")
+	}
+	t.written = append(t.written, append([]byte(nil), data...))
+	return nil
+}
+
+// CloseInput records that stdin was closed. It is safe to call multiple times.
+func (t *Transport) CloseInput() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inputClosed = true
+	return nil
+}
+
+// Interrupt records an interrupt.
+func (t *Transport) Interrupt() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return errors.New("transport not connected")
+	}
+	t.interrupts++
+	return nil
+}
+
+// Close stops playback. It is safe to call multiple times.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.connected = false
+	if t.cancel != nil {
+		t.cancel()
+	}
+	return nil
+}
+
+// IsConnected returns true between Connect and Close.
+func (t *Transport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected
+}
+
+// Written returns the messages written to the transport's input, in order.
+func (t *Transport) Written() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]byte(nil), t.written...)
+}
+
+// InputClosed reports whether CloseInput has been called.
+func (t *Transport) InputClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inputClosed
+}
+
+// Interrupts returns the number of times Interrupt has been called.
+func (t *Transport) Interrupts() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interrupts
+}
@@ -0,0 +1,186 @@
+package claudecode
+
+import (
+	"context"
+
+	client2 "github.com/jrossi/claude-code-sdk-golang/client"
+)
+
+// ClientOptions configures a Client.
+type ClientOptions = client2.ClientOptions
+
+// UsageTracker accumulates cost and usage across queries. Attach it to a
+// client with ClientOptions.UsageTracker.
+//
+// Example:
+//
+//	tracker := claudecode.NewUsageTracker()
+//	client := claudecode.NewClient(claudecode.ClientOptions{UsageTracker: tracker})
+//	// ... run queries ...
+//	fmt.Printf("spent $%.4f\n", tracker.Snapshot().TotalCostUSD)
+type UsageTracker = client2.UsageTracker
+
+// Usage is the aggregate cost and usage of a set of queries.
+type Usage = client2.Usage
+
+// NewUsageTracker creates an empty usage tracker.
+var NewUsageTracker = client2.NewUsageTracker
+
+// FileChange is the net change made to one file by a query's tool uses,
+// with a diff when the tool inputs describe it.
+//
+// Example:
+//
+//	result, err := claudecode.QuerySync(ctx, "Fix the failing test", options)
+//	for _, change := range result.Changes {
+//		fmt.Printf("%s %s\n", change.Op, change.Path)
+//	}
+type FileChange = client2.FileChange
+
+// ChangeOp is how a file was changed.
+type ChangeOp = client2.ChangeOp
+
+const (
+	// ChangeCreated means the file was created.
+	ChangeCreated = client2.ChangeCreated
+
+	// ChangeModified means an existing file was changed.
+	ChangeModified = client2.ChangeModified
+
+	// ChangeDeleted means the file was deleted.
+	ChangeDeleted = client2.ChangeDeleted
+)
+
+// ChangeTracker builds a changeset from the file-changing tool uses in a
+// stream of messages. Every QueryStream and Session tracks its own changes;
+// use a ChangeTracker to combine several.
+type ChangeTracker = client2.ChangeTracker
+
+// NewChangeTracker creates an empty change tracker.
+var NewChangeTracker = client2.NewChangeTracker
+
+// Pool limits the number of CLI processes running at once, queueing
+// further queries and sessions in arrival order. Attach it to one or more
+// clients with ClientOptions.Pool.
+//
+// Example:
+//
+//	pool := claudecode.NewPool(claudecode.PoolOptions{MaxConcurrent: 4, MaxQueued: 100})
+//	client := claudecode.NewClient(claudecode.ClientOptions{Pool: pool})
+//	// ... run queries concurrently ...
+//	stats := pool.Stats() // Active, Queued, Rejected, Started
+type Pool = client2.Pool
+
+// PoolOptions configures a Pool.
+type PoolOptions = client2.PoolOptions
+
+// PoolStats is a snapshot of a pool's activity.
+type PoolStats = client2.PoolStats
+
+// NewPool creates a pool with the given limits.
+var NewPool = client2.NewPool
+
+// ErrPoolFull is returned when a query cannot be queued because the pool's
+// queue is full.
+var ErrPoolFull = client2.ErrPoolFull
+
+// Client runs queries and sessions with its own configuration. Use separate
+// clients when different parts of a program need different settings; the
+// package-level functions such as Query use a shared default client.
+//
+// Example:
+//
+//	client := claudecode.NewClient(claudecode.ClientOptions{
+//		ParserBufferSize: 5 * 1024 * 1024,
+//		CLIPath:          "/opt/claude/bin/claude",
+//	})
+//	stream, err := client.Query(ctx, "Hello", nil)
+type Client struct {
+	internal *client2.Client
+}
+
+// defaultClient is the package-level client instance used by the Query function.
+var defaultClient = NewClient(ClientOptions{})
+
+// NewClient creates a client with the given configuration.
+func NewClient(opts ClientOptions) *Client {
+	return &Client{internal: client2.NewClientWithOptions(opts)}
+}
+
+// Query initiates a query to Claude Code and returns a stream for receiving messages.
+// See the package-level Query for details.
+func (c *Client) Query(ctx context.Context, prompt string, options *Options) (*QueryStream, error) {
+	internal, err := c.internal.Query(ctx, prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	return wrapQueryStream(internal), nil
+}
+
+// QueryPrompt initiates a query with a multi-part prompt.
+// See the package-level QueryPrompt for details.
+func (c *Client) QueryPrompt(ctx context.Context, prompt *Prompt, options *Options) (*QueryStream, error) {
+	internal, err := c.internal.QueryPrompt(ctx, prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	return wrapQueryStream(internal), nil
+}
+
+// QueryWithCLIPath initiates a query using a specific Claude Code CLI binary path,
+// overriding the client's CLIPath.
+func (c *Client) QueryWithCLIPath(ctx context.Context, prompt string, options *Options, cliPath string) (*QueryStream, error) {
+	internal, err := c.internal.QueryWithCLIPath(ctx, prompt, options, cliPath)
+	if err != nil {
+		return nil, err
+	}
+	return wrapQueryStream(internal), nil
+}
+
+// QueryWithTransport initiates a query over a caller-supplied transport.
+// See the package-level QueryWithTransport for details.
+func (c *Client) QueryWithTransport(ctx context.Context, prompt string, options *Options, transport Transport) (*QueryStream, error) {
+	internal, err := c.internal.QueryWithTransport(ctx, prompt, options, transport)
+	if err != nil {
+		return nil, err
+	}
+	return wrapQueryStream(internal), nil
+}
+
+// QuerySync runs a query to completion and returns the collected results.
+// See the package-level QuerySync for details.
+func (c *Client) QuerySync(ctx context.Context, prompt string, options *Options) (*QueryResult, error) {
+	stream, err := c.Query(ctx, prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return collectQueryResult(ctx, stream)
+}
+
+// NewSession starts an interactive session with Claude Code.
+// The session must be closed when done to terminate the CLI process.
+func (c *Client) NewSession(ctx context.Context, options *Options) (*Session, error) {
+	internal, err := c.internal.StartSession(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{internal: internal}, nil
+}
+
+// NewSessionWithCLIPath starts an interactive session using a specific CLI
+// binary path, overriding the client's CLIPath.
+func (c *Client) NewSessionWithCLIPath(ctx context.Context, options *Options, cliPath string) (*Session, error) {
+	internal, err := c.internal.StartSessionWithCLIPath(ctx, options, cliPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{internal: internal}, nil
+}
+
+// SetParserBufferSize configures the maximum buffer size for JSON parsing
+// for queries started by this client after the call.
+func (c *Client) SetParserBufferSize(size int) {
+	c.internal.SetParserBufferSize(size)
+}
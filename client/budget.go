@@ -0,0 +1,65 @@
+package client
+
+import (
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// budgetGuard tracks a stream's spending against Options.MaxCostUSD. The
+// CLI reports the session's total cost in each result; between results,
+// spending is estimated from the token usage of assistant messages.
+type budgetGuard struct {
+	limit float64
+
+	// reported is the cost from the latest result message
+	reported float64
+
+	// estimates holds the estimated cost of each API message since the
+	// latest result, keyed by message ID. The CLI repeats an API message's
+	// usage on every assistant message split from it, so only the latest
+	// estimate per ID counts.
+	estimates map[string]float64
+}
+
+// newBudgetGuard creates a guard for the given limit in US dollars.
+func newBudgetGuard(limit float64) *budgetGuard {
+	return &budgetGuard{
+		limit:     limit,
+		estimates: make(map[string]float64),
+	}
+}
+
+// observe updates spending with msg and returns a BudgetExceededError once
+// the limit has been exceeded.
+func (bg *budgetGuard) observe(msg types.Message) error {
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		if m.Usage == nil {
+			return nil
+		}
+		bg.estimates[m.ID] = types.EstimateCostUSD(m.Model, m.Usage)
+
+	case *types.ResultMessage:
+		if m.TotalCostUSD == nil {
+			return nil
+		}
+		bg.reported = *m.TotalCostUSD
+		clear(bg.estimates)
+
+	default:
+		return nil
+	}
+
+	spent := bg.reported
+	for _, estimate := range bg.estimates {
+		spent += estimate
+	}
+
+	if spent > bg.limit {
+		return &types.BudgetExceededError{
+			LimitUSD:  bg.limit,
+			SpentUSD:  spent,
+			Estimated: len(bg.estimates) > 0,
+		}
+	}
+	return nil
+}
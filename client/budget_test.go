@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestBudgetGuardObserve(t *testing.T) {
+	guard := newBudgetGuard(1.0)
+
+	// 50k output tokens on sonnet is an estimated $0.75
+	assistant := &types.AssistantMessage{
+		ID:    "msg_1",
+		Model: "claude-sonnet-4",
+		Usage: map[string]any{"output_tokens": float64(50000)},
+	}
+	if err := guard.observe(assistant); err != nil {
+		t.Fatalf("Expected no error under budget, got %v", err)
+	}
+
+	// The same API message repeated must not be counted twice
+	if err := guard.observe(assistant); err != nil {
+		t.Fatalf("Expected repeated message to be counted once, got %v", err)
+	}
+
+	// The reported cost replaces the estimate
+	cost := 0.5
+	if err := guard.observe(&types.ResultMessage{TotalCostUSD: &cost}); err != nil {
+		t.Fatalf("Expected no error at reported cost 0.5, got %v", err)
+	}
+
+	// A further estimate pushes the total over the limit
+	err := guard.observe(&types.AssistantMessage{
+		ID:    "msg_2",
+		Model: "claude-sonnet-4",
+		Usage: map[string]any{"output_tokens": float64(50000)},
+	})
+
+	var budgetErr *types.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Expected BudgetExceededError, got %v", err)
+	}
+	if !budgetErr.Estimated || budgetErr.SpentUSD < 1.24 || budgetErr.SpentUSD > 1.26 {
+		t.Errorf("Expected estimated spend of 1.25, got %+v", budgetErr)
+	}
+	if types.ErrorKind(err) != types.KindBudgetExceeded {
+		t.Errorf("Expected KindBudgetExceeded, got %v", types.ErrorKind(err))
+	}
+}
+
+func TestQueryStopsWhenOverBudget(t *testing.T) {
+	mt := newMockInputTransport()
+	mt.data <- []byte(`{"type": "assistant", "message": {"id": "msg_1", "model": "claude-opus-4", "usage": {"output_tokens": 100000}, "content": [{"type": "text", "text": "expensive"}]}}`)
+	mt.data <- []byte(`{"type": "assistant", "message": {"id": "msg_2", "content": [{"type": "text", "text": "dropped"}]}}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	options := types.NewOptions().WithMaxCostUSD(1.0)
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	var messages []types.Message
+	for msg := range stream.Messages() {
+		messages = append(messages, msg)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected only the message that exceeded the budget, got %d messages", len(messages))
+	}
+
+	var budgetErr *types.BudgetExceededError
+	for err := range stream.Errors() {
+		errors.As(err, &budgetErr)
+	}
+	if budgetErr == nil {
+		t.Fatal("Expected BudgetExceededError")
+	}
+	if budgetErr.LimitUSD != 1.0 {
+		t.Errorf("Expected limit 1.0, got %v", budgetErr.LimitUSD)
+	}
+	if mt.IsConnected() {
+		t.Error("Expected transport to be closed")
+	}
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// cacheKey returns the response cache key for a query, and false if the
+// query is not cached. The prompt is a string or the content blocks of a
+// multi-part prompt.
+func cacheKey(prompt any, options *types.Options) (string, bool) {
+	if options.ResponseCache == nil {
+		return "", false
+	}
+	return queryKey(prompt, options)
+}
+
+// queryKey returns the key identifying a query's output, and false if no
+// other query can share it: queries that resume or continue a
+// conversation, or use the control protocol for callbacks of their own.
+func queryKey(prompt any, options *types.Options) (string, bool) {
+	if needsControlProtocol(options) || options.Resume != nil || options.ContinueConversation {
+		return "", false
+	}
+
+	text, ok := prompt.(string)
+	if !ok {
+		encoded, err := json.Marshal(prompt)
+		if err != nil {
+			return "", false
+		}
+		text = string(encoded)
+	}
+	return types.CacheKey(text, options), true
+}
+
+// withCache returns a transport replaying the query's cached output, or
+// one caching the output of t once the query succeeds. Queries that are
+// not cached get t.
+func withCache(prompt any, options *types.Options, t transport2.Transport) transport2.Transport {
+	key, ok := cacheKey(prompt, options)
+	if !ok {
+		return t
+	}
+	if output, hit := options.ResponseCache.Get(key); hit {
+		return &cachedTransport{key: key, output: output}
+	}
+	return &cachingTransport{Transport: t, cache: options.ResponseCache, key: key}
+}
+
+// cachedTransport replays a query's output from the response cache,
+// announced by a system message line with subtype
+// types.SystemSubtypeCacheHit.
+type cachedTransport struct {
+	key    string
+	output []byte
+
+	mu        sync.Mutex
+	connected bool
+}
+
+func (ct *cachedTransport) Connect(ctx context.Context) error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.connected = true
+	return nil
+}
+
+// Stream sends the cache hit line and each cached line.
+func (ct *cachedTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	lines := bytes.Split(ct.output, []byte("\n"))
+	dataChan := make(chan []byte, len(lines)+1)
+	errChan := make(chan error)
+
+	hit, _ := json.Marshal(map[string]any{
+		"type":    "system",
+		"subtype": types.SystemSubtypeCacheHit,
+		"key":     ct.key,
+	})
+	dataChan <- hit
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) > 0 {
+			dataChan <- line
+		}
+	}
+	close(dataChan)
+	close(errChan)
+
+	return dataChan, errChan
+}
+
+func (ct *cachedTransport) Close() error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.connected = false
+	return nil
+}
+
+func (ct *cachedTransport) IsConnected() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.connected
+}
+
+// cachingTransport records the output of a query and stores it in the
+// response cache if the query ends with a successful result and no
+// errors.
+type cachingTransport struct {
+	transport2.Transport
+
+	cache types.ResponseCache
+	key   string
+}
+
+// Stream forwards the output of the wrapped transport, recording it.
+func (ct *cachingTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	data, errs := ct.Transport.Stream(ctx)
+	dataChan := make(chan []byte, cap(data))
+	errChan := make(chan error, cap(errs))
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+
+		var output bytes.Buffer
+		succeeded, failed := false, false
+		for data != nil || errs != nil {
+			select {
+			case line, ok := <-data:
+				if !ok {
+					data = nil
+					continue
+				}
+				line = bytes.TrimSpace(line)
+				output.Write(line)
+				output.WriteByte('\n')
+				succeeded = succeeded || isSuccessfulResult(line)
+				select {
+				case dataChan <- line:
+				case <-ctx.Done():
+					return
+				}
+
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				failed = true
+				select {
+				case errChan <- err:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if succeeded && !failed && ctx.Err() == nil {
+			ct.cache.Set(ct.key, output.Bytes())
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+// Interrupt interrupts the wrapped transport's CLI process.
+func (ct *cachingTransport) Interrupt() error {
+	if interrupter, ok := ct.Transport.(transport2.Interrupter); ok {
+		return interrupter.Interrupt()
+	}
+	return fmt.Errorf("transport does not support interrupts")
+}
+
+// Warnings returns the wrapped transport's warnings.
+func (ct *cachingTransport) Warnings() []string {
+	if warner, ok := ct.Transport.(transport2.Warner); ok {
+		return warner.Warnings()
+	}
+	return nil
+}
+
+// isSuccessfulResult reports whether line is a result message without an
+// error.
+func isSuccessfulResult(line []byte) bool {
+	if !bytes.Contains(line, []byte(`"result"`)) {
+		return false
+	}
+	var msg struct {
+		Type    string `json:"type"`
+		IsError bool   `json:"is_error"`
+	}
+	return json.Unmarshal(line, &msg) == nil && msg.Type == "result" && !msg.IsError
+}
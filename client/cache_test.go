@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// mapCache is a ResponseCache without expiry.
+type mapCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{entries: map[string][]byte{}}
+}
+
+func (mc *mapCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	value, ok := mc.entries[key]
+	return value, ok
+}
+
+func (mc *mapCache) Set(key string, value []byte) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.entries[key] = value
+}
+
+func (mc *mapCache) len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return len(mc.entries)
+}
+
+// drain collects a stream's messages and errors.
+func drain(t *testing.T, stream *QueryStream) ([]types.Message, []error) {
+	t.Helper()
+	var messages []types.Message
+	var errs []error
+	timeout := time.After(5 * time.Second)
+	msgs, errChan := stream.Messages(), stream.Errors()
+	for msgs != nil || errChan != nil {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				msgs = nil
+				continue
+			}
+			messages = append(messages, msg)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			errs = append(errs, err)
+		case <-timeout:
+			t.Fatal("Timed out waiting for the stream to end")
+		}
+	}
+	return messages, errs
+}
+
+func TestResponseCache(t *testing.T) {
+	cache := newMapCache()
+	tracker := NewUsageTracker()
+	client := NewClientWithOptions(ClientOptions{UsageTracker: tracker})
+	options := types.NewOptions().WithResponseCache(cache)
+	output := []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Looks good"}]}}`,
+		`{"type":"result","subtype":"success","session_id":"s","total_cost_usd":0.25}`,
+	}
+	ctx := context.Background()
+
+	t.Run("miss", func(t *testing.T) {
+		tr := withCache("Review main.go", options, &mockMessageTransport{messages: output})
+		if _, ok := tr.(*cachingTransport); !ok {
+			t.Fatalf("Expected a caching transport on a miss, got %T", tr)
+		}
+		stream, err := client.QueryWithTransport(ctx, "Review main.go", options, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages, errs := drain(t, stream)
+		if len(messages) != 2 || len(errs) != 0 {
+			t.Fatalf("Expected 2 messages and no errors, got %d and %v", len(messages), errs)
+		}
+		if cache.len() != 1 {
+			t.Fatalf("Expected the output to be cached, got %d entries", cache.len())
+		}
+	})
+
+	t.Run("hit", func(t *testing.T) {
+		tr := withCache(" Review main.go\n", options, &mockErrorTransport{transportError: errors.New("CLI started")})
+		if _, ok := tr.(*cachedTransport); !ok {
+			t.Fatalf("Expected a cached transport on a hit, got %T", tr)
+		}
+		stream, err := client.QueryWithTransport(ctx, "Review main.go", options, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages, errs := drain(t, stream)
+		if len(messages) != 3 || len(errs) != 0 {
+			t.Fatalf("Expected 3 messages and no errors, got %d and %v", len(messages), errs)
+		}
+		hit, ok := messages[0].(*types.SystemMessage)
+		if !ok || hit.Subtype != types.SystemSubtypeCacheHit {
+			t.Fatalf("Expected a cache hit system message first, got %#v", messages[0])
+		}
+		if hit.Data["key"] != types.CacheKey("Review main.go", options) {
+			t.Errorf("Expected the cache key in the hit message, got %v", hit.Data["key"])
+		}
+		if result, ok := messages[2].(*types.ResultMessage); !ok || result.SessionID != "s" {
+			t.Errorf("Expected the cached result, got %#v", messages[2])
+		}
+	})
+
+	if usage := tracker.Snapshot(); usage.Queries != 1 {
+		t.Errorf("Expected only the uncached query to be recorded, got %d", usage.Queries)
+	}
+}
+
+func TestResponseCacheSkipsFailures(t *testing.T) {
+	client := NewClient()
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		transport func() *mockErrorTransport
+	}{
+		{"error result", func() *mockErrorTransport {
+			return &mockErrorTransport{messages: []string{`{"type":"result","subtype":"error_during_execution","is_error":true,"session_id":"s"}`}}
+		}},
+		{"transport error", func() *mockErrorTransport {
+			return &mockErrorTransport{
+				transportError: errors.New("CLI exited"),
+				messages:       []string{`{"type":"result","subtype":"success","session_id":"s"}`},
+			}
+		}},
+		{"no result", func() *mockErrorTransport {
+			return &mockErrorTransport{messages: []string{`{"type":"assistant","message":{"content":[]}}`}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newMapCache()
+			options := types.NewOptions().WithResponseCache(cache)
+			stream, err := client.QueryWithTransport(ctx, "prompt", options, withCache("prompt", options, tt.transport()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			drain(t, stream)
+			if cache.len() != 0 {
+				t.Error("Expected the failed query not to be cached")
+			}
+		})
+	}
+}
+
+func TestCacheKeyUncachedQueries(t *testing.T) {
+	cache := newMapCache()
+	sessionID := "s"
+
+	tests := []struct {
+		name    string
+		options *types.Options
+		cached  bool
+	}{
+		{"cached", types.NewOptions().WithResponseCache(cache), true},
+		{"no cache", types.NewOptions(), false},
+		{"resume", types.NewOptions().WithResponseCache(cache).WithResume(sessionID), false},
+		{"continue", types.NewOptions().WithResponseCache(cache).WithContinueConversation(), false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := cacheKey("prompt", tt.options); ok != tt.cached {
+			t.Errorf("%s: expected cached %v, got %v", tt.name, tt.cached, ok)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package client
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// ChangeOp is how a file was changed.
+type ChangeOp string
+
+const (
+	// ChangeCreated means the file was created.
+	ChangeCreated ChangeOp = "created"
+
+	// ChangeModified means an existing file was changed.
+	ChangeModified ChangeOp = "modified"
+
+	// ChangeDeleted means the file was deleted.
+	ChangeDeleted ChangeOp = "deleted"
+)
+
+// FileChange is the net change made to one file by a query's tool uses.
+type FileChange struct {
+	// Path is the file's path as given to the tool.
+	Path string `json:"path"`
+
+	// Op is the net change: a file created and then edited is created.
+	Op ChangeOp `json:"op"`
+
+	// Diff holds the edits in unified diff format, without line numbers,
+	// when the tool inputs describe them. Overwritten and deleted files
+	// have no diff.
+	Diff string `json:"diff,omitempty"`
+
+	// ToolUseIDs are the tool uses that changed the file, in order.
+	ToolUseIDs []string `json:"tool_use_ids"`
+}
+
+// ChangeTracker builds a changeset from the Write, Edit, MultiEdit, and
+// NotebookEdit tool uses in a stream of messages, and from simple rm
+// commands run with Bash. A change is recorded once its tool result
+// arrives without an error. It is safe for concurrent use.
+//
+// Every QueryStream tracks its own changes; use a ChangeTracker directly to
+// combine several streams or to track messages from other sources.
+type ChangeTracker struct {
+	mu sync.Mutex
+
+	// pending holds changes whose tool results have not arrived, by tool
+	// use ID
+	pending map[string]pendingChange
+
+	// files holds the changes by path, and order the paths in the order
+	// they were first changed
+	files map[string]*trackedFile
+	order []string
+}
+
+// trackedFile is a file's change as it is built.
+type trackedFile struct {
+	FileChange
+
+	// diffKnown is false once the file was changed in a way the diff
+	// cannot describe, such as being overwritten
+	diffKnown bool
+}
+
+// pendingChange is a change described by a tool use.
+type pendingChange struct {
+	tool  string
+	paths []string
+	op    ChangeOp
+	diff  string
+}
+
+// NewChangeTracker creates an empty change tracker.
+func NewChangeTracker() *ChangeTracker {
+	return &ChangeTracker{
+		pending: make(map[string]pendingChange),
+		files:   make(map[string]*trackedFile),
+	}
+}
+
+// Observe records the file-changing tool uses and tool results in a message.
+func (ct *ChangeTracker) Observe(msg types.Message) {
+	var blocks []types.ContentBlock
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		blocks = m.Content
+	case *types.UserMessage:
+		blocks = m.Blocks
+	default:
+		return
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *types.ToolUseBlock:
+			if change, ok := describeChange(b); ok {
+				ct.pending[b.ID] = change
+			}
+		case *types.ToolResultBlock:
+			change, ok := ct.pending[b.ToolUseID]
+			if !ok {
+				continue
+			}
+			delete(ct.pending, b.ToolUseID)
+			if b.Failed() {
+				continue
+			}
+			ct.apply(b.ToolUseID, change, b.Text())
+		}
+	}
+}
+
+// Changes returns the changed files in the order they were first changed.
+func (ct *ChangeTracker) Changes() []FileChange {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	changes := make([]FileChange, 0, len(ct.order))
+	for _, path := range ct.order {
+		change := ct.files[path].FileChange
+		change.ToolUseIDs = append([]string(nil), change.ToolUseIDs...)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// apply merges a completed change into the changeset.
+func (ct *ChangeTracker) apply(toolUseID string, change pendingChange, result string) {
+	op := change.op
+	if change.tool == "Write" {
+		// The CLI reports whether Write created the file or replaced it
+		if strings.Contains(result, "created") {
+			op = ChangeCreated
+		} else {
+			op = ChangeModified
+		}
+	}
+
+	for _, path := range change.paths {
+		file, ok := ct.files[path]
+		if !ok {
+			file = &trackedFile{FileChange: FileChange{Path: path, Op: op}, diffKnown: true}
+			ct.files[path] = file
+			ct.order = append(ct.order, path)
+		} else {
+			file.Op = mergeOp(file.Op, op)
+		}
+		file.ToolUseIDs = append(file.ToolUseIDs, toolUseID)
+
+		// A file created and then deleted was never changed
+		if file.Op == "" {
+			ct.remove(path)
+			continue
+		}
+
+		switch {
+		case change.tool == "Write" && file.Op == ChangeCreated:
+			// The whole content of a new file is known
+			file.Diff = diffHeader(path, file.Op) + change.diff
+			file.diffKnown = true
+		case change.tool == "Write" || change.diff == "":
+			// The file's content is no longer described by the edits
+			file.Diff = ""
+			file.diffKnown = false
+		case !file.diffKnown:
+		case file.Diff == "":
+			file.Diff = diffHeader(path, file.Op) + change.diff
+		default:
+			file.Diff += change.diff
+		}
+	}
+}
+
+// remove drops a path from the changeset.
+func (ct *ChangeTracker) remove(path string) {
+	delete(ct.files, path)
+	for i, p := range ct.order {
+		if p == path {
+			ct.order = append(ct.order[:i], ct.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// mergeOp combines a file's earlier change with a later one. It returns ""
+// when the changes cancel out.
+func mergeOp(earlier, later ChangeOp) ChangeOp {
+	switch {
+	case earlier == ChangeCreated && later == ChangeDeleted:
+		return ""
+	case earlier == ChangeCreated:
+		return ChangeCreated
+	case earlier == ChangeDeleted && later != ChangeDeleted:
+		// Deleted and then recreated
+		return ChangeModified
+	default:
+		return later
+	}
+}
+
+// multiEditInput is the input of the MultiEdit tool.
+type multiEditInput struct {
+	FilePath string `json:"file_path"`
+	Edits    []struct {
+		OldString string `json:"old_string"`
+		NewString string `json:"new_string"`
+	} `json:"edits"`
+}
+
+// notebookEditInput is the input of the NotebookEdit tool.
+type notebookEditInput struct {
+	NotebookPath string `json:"notebook_path"`
+	NewSource    string `json:"new_source"`
+	EditMode     string `json:"edit_mode"`
+}
+
+// describeChange describes the change a tool use will make, if any.
+func describeChange(block *types.ToolUseBlock) (pendingChange, bool) {
+	change := pendingChange{tool: block.Name, op: ChangeModified}
+
+	switch block.Name {
+	case "Write":
+		input, err := types.DecodeToolInput[types.WriteInput](block)
+		if err != nil || input.FilePath == "" {
+			return change, false
+		}
+		change.paths = []string{input.FilePath}
+		// Only used when the file turns out to be new
+		change.diff = diffHunk("", input.Content)
+
+	case "Edit":
+		input, err := types.DecodeToolInput[types.EditInput](block)
+		if err != nil || input.FilePath == "" {
+			return change, false
+		}
+		change.paths = []string{input.FilePath}
+		change.diff = diffHunk(input.OldString, input.NewString)
+
+	case "MultiEdit":
+		input, err := types.DecodeToolInput[multiEditInput](block)
+		if err != nil || input.FilePath == "" {
+			return change, false
+		}
+		change.paths = []string{input.FilePath}
+		for _, edit := range input.Edits {
+			change.diff += diffHunk(edit.OldString, edit.NewString)
+		}
+
+	case "NotebookEdit":
+		input, err := types.DecodeToolInput[notebookEditInput](block)
+		if err != nil || input.NotebookPath == "" {
+			return change, false
+		}
+		change.paths = []string{input.NotebookPath}
+		// Only inserted cells are fully described by the input
+		if input.EditMode == "insert" {
+			change.diff = diffHunk("", input.NewSource)
+		}
+
+	case "Bash":
+		input, err := types.DecodeToolInput[types.BashInput](block)
+		if err != nil {
+			return change, false
+		}
+		change.paths = removedPaths(input.Command)
+		change.op = ChangeDeleted
+		if len(change.paths) == 0 {
+			return change, false
+		}
+
+	default:
+		return change, false
+	}
+	return change, true
+}
+
+// removedPaths returns the files removed by a plain "rm" or "git rm"
+// command. Commands using shell syntax such as globs, variables, or
+// pipelines are not interpreted, and report no files.
+func removedPaths(command string) []string {
+	if strings.ContainsAny(command, "*?[]{}$`\"'\\;&|<>()~\n") {
+		return nil
+	}
+
+	fields := strings.Fields(command)
+	switch {
+	case len(fields) > 0 && fields[0] == "rm":
+		fields = fields[1:]
+	case len(fields) > 1 && fields[0] == "git" && fields[1] == "rm":
+		fields = fields[2:]
+	default:
+		return nil
+	}
+
+	var paths []string
+	for _, field := range fields {
+		if field == "--cached" {
+			// Only removed from the index
+			return nil
+		}
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		paths = append(paths, field)
+	}
+	return paths
+}
+
+// diffHeader returns the unified diff header for a file.
+func diffHeader(path string, op ChangeOp) string {
+	from := "a/" + strings.TrimPrefix(path, "/")
+	if op == ChangeCreated {
+		from = "/dev/null"
+	}
+	return "--- " + from + "\n+++ b/" + strings.TrimPrefix(path, "/") + "\n"
+}
+
+// diffHunk returns a hunk replacing the old text with the new.
+func diffHunk(old, new string) string {
+	var b strings.Builder
+	b.WriteString("@@ @@\n")
+	for _, line := range diffLines(old) {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range diffLines(new) {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
+
+// diffLines splits text into lines, without a trailing empty line.
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
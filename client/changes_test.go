@@ -0,0 +1,114 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// toolUse returns an assistant message with one tool use.
+func toolUse(id, name string, input map[string]any) *types.AssistantMessage {
+	return &types.AssistantMessage{Content: []types.ContentBlock{
+		&types.ToolUseBlock{ID: id, Name: name, Input: input},
+	}}
+}
+
+// toolResult returns a user message with one tool result.
+func toolResult(id, text string, isError bool) *types.UserMessage {
+	return &types.UserMessage{Blocks: []types.ContentBlock{
+		&types.ToolResultBlock{
+			ToolUseID: id,
+			Content:   []types.ToolResultContent{&types.TextContent{Text: text}},
+			IsError:   &isError,
+		},
+	}}
+}
+
+func TestChangeTracker(t *testing.T) {
+	tracker := NewChangeTracker()
+	messages := []types.Message{
+		// A new file, then edited
+		toolUse("1", "Write", map[string]any{"file_path": "/repo/new.go", "content": "package repo\n"}),
+		toolResult("1", "File created successfully at: /repo/new.go", false),
+		toolUse("2", "Edit", map[string]any{"file_path": "/repo/new.go", "old_string": "repo", "new_string": "main"}),
+		toolResult("2", "The file /repo/new.go has been updated.", false),
+
+		// An existing file edited twice
+		toolUse("3", "Edit", map[string]any{"file_path": "/repo/main.go", "old_string": "a\nb", "new_string": "c"}),
+		toolResult("3", "The file /repo/main.go has been updated.", false),
+		toolUse("4", "MultiEdit", map[string]any{"file_path": "/repo/main.go", "edits": []any{
+			map[string]any{"old_string": "x", "new_string": "y"},
+		}}),
+		toolResult("4", "Applied 1 edit", false),
+
+		// A failed edit is not a change
+		toolUse("5", "Edit", map[string]any{"file_path": "/repo/missing.go", "old_string": "a", "new_string": "b"}),
+		toolResult("5", "File does not exist.", true),
+
+		// An existing file overwritten has no diff
+		toolUse("6", "Write", map[string]any{"file_path": "/repo/README", "content": "hi\n"}),
+		toolResult("6", "The file /repo/README has been updated.", false),
+
+		// Deleted files, and a temporary file that cancels out
+		toolUse("7", "Bash", map[string]any{"command": "rm -f old.txt"}),
+		toolResult("7", "", false),
+		toolUse("8", "Write", map[string]any{"file_path": "/tmp/scratch", "content": "x"}),
+		toolResult("8", "File created successfully at: /tmp/scratch", false),
+		toolUse("9", "Bash", map[string]any{"command": "rm /tmp/scratch"}),
+		toolResult("9", "", false),
+
+		// Other tools and shell commands are ignored
+		toolUse("10", "Read", map[string]any{"file_path": "/repo/main.go"}),
+		toolResult("10", "contents", false),
+		toolUse("11", "Bash", map[string]any{"command": "rm *.log"}),
+		toolResult("11", "", false),
+		&types.ResultMessage{Subtype: "success"},
+	}
+	for _, msg := range messages {
+		tracker.Observe(msg)
+	}
+
+	want := []FileChange{
+		{
+			Path:       "/repo/new.go",
+			Op:         ChangeCreated,
+			Diff:       "--- /dev/null\n+++ b/repo/new.go\n@@ @@\n+package repo\n@@ @@\n-repo\n+main\n",
+			ToolUseIDs: []string{"1", "2"},
+		},
+		{
+			Path:       "/repo/main.go",
+			Op:         ChangeModified,
+			Diff:       "--- a/repo/main.go\n+++ b/repo/main.go\n@@ @@\n-a\n-b\n+c\n@@ @@\n-x\n+y\n",
+			ToolUseIDs: []string{"3", "4"},
+		},
+		{Path: "/repo/README", Op: ChangeModified, ToolUseIDs: []string{"6"}},
+		{Path: "old.txt", Op: ChangeDeleted, ToolUseIDs: []string{"7"}},
+	}
+	if got := tracker.Changes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected changes:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRemovedPaths(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"rm a.txt", []string{"a.txt"}},
+		{"rm -rf build dist", []string{"build", "dist"}},
+		{"git rm -q notes.md", []string{"notes.md"}},
+		{"git rm --cached notes.md", nil},
+		{"rm *.tmp", nil},
+		{"rm a && rm b", nil},
+		{"rm $FILE", nil},
+		{"ls -la", nil},
+		{"git status", nil},
+	}
+
+	for _, tt := range tests {
+		if got := removedPaths(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("removedPaths(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
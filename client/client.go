@@ -4,87 +4,322 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
+
 	"github.com/jrossi/claude-code-sdk-golang/parser"
 	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
 	"github.com/jrossi/claude-code-sdk-golang/types"
 )
 
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// ParserBufferSize is the maximum size in bytes of a single JSON message.
+	// If zero, parser.DefaultMaxBufferSize is used. Options.MaxBufferSize
+	// overrides it for individual queries.
+	ParserBufferSize int
+
+	// CLIPath is the Claude Code CLI binary used by Query and StartSession.
+	// If empty, the CLI is discovered automatically.
+	CLIPath string
+
+	// UsageTracker, if set, records the cost and usage of every query and
+	// session run by the client.
+	UsageTracker *UsageTracker
+
+	// Pool, if set, limits how many CLI processes the client runs at once.
+	// Queries and sessions wait for a free slot before starting.
+	Pool *Pool
+
+	// Coalesce, if set, runs a query identical to one already running,
+	// with the same CLI path, prompt, and options by types.CacheKey, as
+	// part of it: one CLI process serves both, and each caller receives
+	// its whole output. Closing one caller's stream leaves the process
+	// running for the others. The usage tracker records the query once,
+	// and coalesced queries cannot be interrupted. Queries that resume or
+	// continue a conversation or use the control protocol are never
+	// coalesced.
+	Coalesce bool
+}
+
 // Client coordinates between transport and parser to provide Claude Code functionality.
 type Client struct {
-	// Configuration for transport
+	// Configuration for transport of the latest query
 	transportConfig *transport2.Config
+	configMu        sync.Mutex
+
+	// Parser for JSON messages; each query gets its own parser with the
+	// same buffer size, since parsers hold per-stream state
+	parser   *parser.Parser
+	parserMu sync.RWMutex
+
+	// cliPath is used for queries that don't specify one
+	cliPath string
+
+	// usageTracker records the results of the client's queries, if set
+	usageTracker *UsageTracker
 
-	// Parser for JSON messages
-	parser *parser.Parser
+	// pool limits the client's concurrent CLI processes, if set
+	pool *Pool
+
+	// flights tracks running queries by key if the client coalesces them
+	flights *flightGroup
+
+	// interceptors wrap the start of queries and sessions, outermost first
+	interceptors   []Interceptor
+	interceptorsMu sync.RWMutex
 }
 
 // NewClient creates a new client with the given configuration.
 func NewClient() *Client {
-	return &Client{
-		parser: parser.NewParser(0), // Use default buffer size
+	return NewClientWithOptions(ClientOptions{})
+}
+
+// NewClientWithOptions creates a new client with instance-level configuration.
+func NewClientWithOptions(opts ClientOptions) *Client {
+	c := &Client{
+		parser:  parser.NewParser(opts.ParserBufferSize), // Zero uses the default buffer size
+		cliPath: opts.CLIPath,
+
+		usageTracker: opts.UsageTracker,
+		pool:         opts.Pool,
 	}
+	if opts.Coalesce {
+		c.flights = newFlightGroup()
+	}
+	return c
 }
 
 // Query initiates a query to Claude Code and returns a QueryStream for receiving messages.
 func (c *Client) Query(ctx context.Context, prompt string, options *types.Options) (*QueryStream, error) {
+	return c.query(ctx, prompt, options, c.cliPath)
+}
+
+// QueryWithCLIPath initiates a query with a specific CLI path.
+// This is useful for testing or when the CLI is installed in a non-standard location.
+func (c *Client) QueryWithCLIPath(ctx context.Context, prompt string, options *types.Options, cliPath string) (*QueryStream, error) {
+	return c.query(ctx, prompt, options, cliPath)
+}
+
+// QueryWithTransport initiates a query over a caller-supplied transport.
+// Transports implementing transport.Configurable receive the prompt and
+// options via Configure; others are expected to have been set up for the
+// query already. Features that need the control protocol, such as hooks,
+// require a transport.InputTransport. The options are not validated, since
+// the transport decides how they apply; call Options.Validate to check them.
+func (c *Client) QueryWithTransport(ctx context.Context, prompt string, options *types.Options, t transport2.Transport) (*QueryStream, error) {
+	return c.intercept(ctx, &Request{Kind: RequestQuery, Prompt: prompt, Options: options, Transport: t}, c.runQuery)
+}
+
+// query starts a one-shot query with a text prompt.
+func (c *Client) query(ctx context.Context, prompt string, options *types.Options, cliPath string) (*QueryStream, error) {
+	return c.intercept(ctx, &Request{Kind: RequestQuery, Prompt: prompt, Options: options, CLIPath: cliPath}, c.runQuery)
+}
+
+// QueryPrompt initiates a query with a multi-part prompt, such as one with
+// images. The prompt is sent over stdin in stream-json input mode.
+func (c *Client) QueryPrompt(ctx context.Context, prompt *types.Prompt, options *types.Options) (*QueryStream, error) {
+	content, err := prompt.Content()
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt: %w", err)
+	}
+	return c.intercept(ctx, &Request{Kind: RequestQuery, Content: content, Options: options, CLIPath: c.cliPath}, c.runQuery)
+}
+
+// runQuery creates the transport for a query, unless the request has
+// one, and starts streaming. It is the innermost handler of queries.
+func (c *Client) runQuery(ctx context.Context, req *Request) (*QueryStream, error) {
 	// Set default options if none provided
+	options := req.Options
 	if options == nil {
 		options = types.NewOptions()
 	}
 
-	// Create transport configuration
-	c.transportConfig = &transport2.Config{
-		Prompt:  prompt,
-		Options: options,
-		// CLIPath can be set later if needed
-		// MaxBufferSize will use transport defaults
+	if req.Transport != nil {
+		if configurable, ok := req.Transport.(transport2.Configurable); ok {
+			configurable.Configure(queryConfig(req.Prompt, options))
+		}
+		return c.start(ctx, req.Prompt, options, req.Transport)
 	}
 
-	// Create subprocess transport
-	subprocessTransport := transport2.NewSubprocessTransport(c.transportConfig)
+	if err := options.Validate(); err != nil {
+		reportFailure(options, err)
+		return nil, err
+	}
 
-	// Create query stream
-	stream := NewQueryStream(ctx, subprocessTransport, c.parser)
+	if req.Content != nil {
+		config := queryConfig("", options)
+		config.CLIPath = req.CLIPath
+		config.StreamingInput = true
+		if !needsControlProtocol(options) {
+			config.PromptContent = req.Content
+		}
 
-	// Start the streaming process
-	if err := stream.Start(); err != nil {
-		return nil, err
+		t := withCache(req.Content, options, c.queryTransport(config, options))
+		return c.start(ctx, req.Content, options, c.coalesce(req.Content, options, req.CLIPath, t))
 	}
 
-	return stream, nil
+	// Create transport configuration
+	// MaxBufferSize will use transport defaults
+	config := queryConfig(req.Prompt, options)
+	config.CLIPath = req.CLIPath
+
+	t := withCache(req.Prompt, options, c.queryTransport(config, options))
+	return c.start(ctx, req.Prompt, options, c.coalesce(req.Prompt, options, req.CLIPath, t))
 }
 
-// QueryWithCLIPath initiates a query with a specific CLI path.
-// This is useful for testing or when the CLI is installed in a non-standard location.
-func (c *Client) QueryWithCLIPath(ctx context.Context, prompt string, options *types.Options, cliPath string) (*QueryStream, error) {
-	// Set default options if none provided
-	if options == nil {
-		options = types.NewOptions()
+// queryTransport creates the subprocess transport for a one-shot query.
+func (c *Client) queryTransport(config *transport2.Config, options *types.Options) transport2.Transport {
+	// Queries may run concurrently, so only the record of the latest
+	// configuration is shared
+	c.configMu.Lock()
+	c.transportConfig = config
+	c.configMu.Unlock()
+
+	// Create subprocess transport
+	var t transport2.Transport = transport2.NewSubprocessTransport(config)
+
+	// Retried queries start a new process per attempt. Controlled queries
+	// have already exchanged messages with the CLI, so they are not retried.
+	if options.RetryPolicy != nil && options.RetryPolicy.MaxAttempts > 1 && !needsControlProtocol(options) {
+		rt := newRetryTransport(options.RetryPolicy, func() transport2.Transport {
+			return transport2.NewSubprocessTransport(config)
+		})
+		rt.limiter = options.RateLimiter
+		t = rt
 	}
 
-	// Create transport configuration with custom CLI path
-	c.transportConfig = &transport2.Config{
-		Prompt:  prompt,
-		Options: options,
-		CLIPath: cliPath,
+	// A process that fails after the session starts is replaced by one
+	// resuming the session, prompted to finish the task
+	if reconnects(options) {
+		policy := options.ReconnectPolicy
+		t = newReconnectTransport(policy, t, func(sessionID string) transport2.Transport {
+			resumed := queryConfig(policy.ResumePrompt(), resumeOptions(options, sessionID))
+			resumed.CLIPath = config.CLIPath
+			return transport2.NewSubprocessTransport(resumed)
+		}, true)
 	}
 
-	// Create subprocess transport
-	subprocessTransport := transport2.NewSubprocessTransport(c.transportConfig)
+	return t
+}
+
+// queryConfig builds the query-level transport configuration for a prompt.
+func queryConfig(prompt string, options *types.Options) *transport2.Config {
+	config := &transport2.Config{Options: options}
+
+	if needsControlProtocol(options) {
+		// The prompt is sent by the stream once the control protocol is
+		// initialized, and stdin stays open for control responses
+		config.StreamingInput = true
+		config.KeepInputOpen = true
+	} else {
+		config.Prompt = prompt
+		// Send long prompts over stdin to avoid argv limits
+		config.StreamingInput = len(prompt) > transport2.MaxPromptArgLength
+	}
+
+	return config
+}
 
+// start creates a query stream over the transport and begins streaming.
+// The prompt is a string or the content blocks of a multi-part prompt.
+func (c *Client) start(ctx context.Context, prompt any, options *types.Options, t transport2.Transport) (*QueryStream, error) {
 	// Create query stream
-	stream := NewQueryStream(ctx, subprocessTransport, c.parser)
+	stream := NewQueryStream(ctx, t, c.parserFor(options))
+	stream.applyOptions(options)
+
+	switch t := t.(type) {
+	case *cachedTransport:
+		// Output replayed from the response cache starts no process and
+		// costs nothing
+	case *sharedTransport:
+		// A coalesced query's process holds its own pool slot, and its
+		// cost is recorded once
+		if t.leader {
+			stream.usageTracker = c.usageTracker
+		}
+	default:
+		stream.usageTracker = c.usageTracker
+
+		if err := c.acquireSlot(ctx, stream); err != nil {
+			stream.complete(err)
+			return nil, err
+		}
+		if err := waitRateLimit(ctx, options); err != nil {
+			stream.releaseSlot()
+			stream.complete(err)
+			return nil, err
+		}
+	}
 
 	// Start the streaming process
 	if err := stream.Start(); err != nil {
+		stream.releaseSlot()
+		stream.complete(err)
 		return nil, err
 	}
 
+	if needsControlProtocol(options) {
+		if err := stream.startControlledQuery(prompt, options); err != nil {
+			stream.complete(err)
+			stream.Close()
+			return nil, err
+		}
+	}
+
 	return stream, nil
 }
 
+// acquireSlot waits for a free slot in the client's pool, if it has one,
+// and assigns it to the stream, which releases it when it ends.
+func (c *Client) acquireSlot(ctx context.Context, stream *QueryStream) error {
+	if c.pool == nil {
+		return nil
+	}
+
+	release, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	stream.release = release
+	return nil
+}
+
+// waitRateLimit waits on the options' rate limiter, if set.
+func waitRateLimit(ctx context.Context, options *types.Options) error {
+	if options.RateLimiter == nil {
+		return nil
+	}
+	if err := options.RateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limiter: %w", err)
+	}
+	return nil
+}
+
+// parserFor returns a new parser for a query, sized to the query's own
+// buffer limit when one is set and to the client's otherwise, and using the
+// query's parse mode.
+func (c *Client) parserFor(options *types.Options) *parser.Parser {
+	var size int
+	if options.MaxBufferSize != nil && *options.MaxBufferSize > 0 {
+		size = *options.MaxBufferSize
+	} else {
+		c.parserMu.RLock()
+		size = c.parser.MaxBufferSize()
+		c.parserMu.RUnlock()
+	}
+
+	p := parser.NewParser(size)
+	if options.ParseMode != nil {
+		p.SetParseMode(*options.ParseMode)
+	}
+	return p
+}
+
 // SetParserBufferSize configures the maximum buffer size for JSON parsing.
-// This should be called before making queries.
+// It applies to queries started after the call.
 func (c *Client) SetParserBufferSize(size int) {
+	c.parserMu.Lock()
+	defer c.parserMu.Unlock()
 	c.parser = parser.NewParser(size)
 }
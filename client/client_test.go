@@ -2,10 +2,12 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/jrossi/claude-code-sdk-golang/parser"
 	"github.com/jrossi/claude-code-sdk-golang/transport"
 	"github.com/jrossi/claude-code-sdk-golang/types"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -36,6 +38,41 @@ func TestClientSetParserBufferSize(t *testing.T) {
 	}
 }
 
+func TestClientParserForOptions(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{ParserBufferSize: 2048})
+
+	first := client.parserFor(types.NewOptions())
+	if first.MaxBufferSize() != 2048 {
+		t.Errorf("Expected client buffer size 2048, got %d", first.MaxBufferSize())
+	}
+	if first == client.parserFor(types.NewOptions()) {
+		t.Error("Expected a fresh parser per query")
+	}
+
+	p := client.parserFor(types.NewOptions().WithMaxBufferSize(8 * 1024 * 1024))
+	if p.MaxBufferSize() != 8*1024*1024 {
+		t.Errorf("Expected per-query buffer size, got %d", p.MaxBufferSize())
+	}
+
+	client.SetParserBufferSize(4096)
+	if got := client.parserFor(types.NewOptions()).MaxBufferSize(); got != 4096 {
+		t.Errorf("Expected updated buffer size 4096, got %d", got)
+	}
+}
+
+func TestClientOptionsCLIPath(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{CLIPath: "/nonexistent/claude"})
+
+	// The missing binary is reported when the process starts
+	stream, err := client.Query(context.Background(), "hello", nil)
+	if err == nil {
+		stream.Close()
+	}
+	if client.transportConfig.CLIPath != "/nonexistent/claude" {
+		t.Errorf("Expected client CLI path to be used, got %q", client.transportConfig.CLIPath)
+	}
+}
+
 func TestClientQueryConfiguration(t *testing.T) {
 	client := NewClient()
 	
@@ -725,3 +762,166 @@ func (mt *mockStreamingTransport) Close() error {
 func (mt *mockStreamingTransport) IsConnected() bool {
 	return mt.connected
 }
+
+func TestClientQueryLongPromptUsesStreamingInput(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		name      string
+		prompt    string
+		streaming bool
+	}{
+		{"short prompt", "hello", false},
+		{"long prompt", strings.Repeat("a", transport.MaxPromptArgLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			stream, _ := client.QueryWithCLIPath(ctx, tt.prompt, nil, "/nonexistent/claude")
+			if stream != nil {
+				stream.Close()
+			}
+
+			if client.transportConfig.StreamingInput != tt.streaming {
+				t.Errorf("Expected StreamingInput=%v, got %v", tt.streaming, client.transportConfig.StreamingInput)
+			}
+		})
+	}
+}
+
+func TestQueryRawMessageHandler(t *testing.T) {
+	lines := []string{
+		`{"type": "system", "subtype": "init", "session_id": "abc"}`,
+		`{"type": "future_type", "data": 1}`,
+		`{"type": "result", "subtype": "success", "session_id": "abc"}`,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var raw []string
+	options := types.NewOptions().WithRawMessageHandler(func(line json.RawMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		raw = append(raw, string(line))
+	})
+
+	client := NewClient()
+	stream, err := client.QueryWithTransport(ctx, "Hello", options, &mockMessageTransport{messages: lines})
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	parsed := 0
+	for range stream.Messages() {
+		parsed++
+	}
+	if parsed != 2 {
+		t.Errorf("Expected 2 parsed messages, got %d", parsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(raw) != len(lines) {
+		t.Fatalf("Expected %d raw lines, got %d", len(lines), len(raw))
+	}
+	for i := range lines {
+		if raw[i] != lines[i] {
+			t.Errorf("Expected raw line %q, got %q", lines[i], raw[i])
+		}
+	}
+}
+
+// warningTransport is a mockMessageTransport that reports connection warnings.
+type warningTransport struct {
+	mockMessageTransport
+	warnings []string
+}
+
+func (wt *warningTransport) Warnings() []string {
+	return wt.warnings
+}
+
+func TestQueryDeliversWarnings(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	mt := &warningTransport{
+		mockMessageTransport: mockMessageTransport{messages: []string{`{"type": "result", "subtype": "success"}`}},
+		warnings:             []string{"CLI version 0.2.0 is older than 1.0.0, required for the Go SDK"},
+	}
+
+	options := types.NewOptions().WithModel("claude-sonnet-9")
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	var messages []types.Message
+	for msg := range stream.Messages() {
+		messages = append(messages, msg)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected two warnings and result, got %d messages", len(messages))
+	}
+
+	want := append(options.Warnings(), mt.warnings...)
+	for i, text := range want {
+		warning, ok := messages[i].(*types.SystemMessage)
+		if !ok || warning.Subtype != types.SystemSubtypeWarning {
+			t.Fatalf("Expected warning %d, got %#v", i, messages[i])
+		}
+		if warning.Data["message"] != text {
+			t.Errorf("Expected warning %q, got %v", text, warning.Data["message"])
+		}
+	}
+}
+
+func TestQueryStreamInit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	lines := []string{
+		`{"type": "system", "subtype": "init", "session_id": "s1", "slash_commands": ["compact"], "plugins": [{"name": "deploy"}]}`,
+		`{"type": "result", "subtype": "success", "session_id": "s1"}`,
+	}
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", nil, &mockMessageTransport{messages: lines})
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	for range stream.Messages() {
+	}
+
+	info := stream.Init()
+	if info == nil {
+		t.Fatal("Expected init information")
+	}
+	if info.SessionID != "s1" || len(info.SlashCommands) != 1 || len(info.Plugins) != 1 || info.Plugins[0].Name != "deploy" {
+		t.Errorf("Unexpected init information: %+v", info)
+	}
+}
+
+func TestQueryValidatesOptions(t *testing.T) {
+	client := NewClient()
+	options := types.NewOptions().WithMaxTurns(-1)
+
+	// The CLI path is never used, since validation fails first
+	_, err := client.QueryWithCLIPath(context.Background(), "hello", options, "/nonexistent/claude")
+	var usageErr *types.UsageError
+	if !errors.As(err, &usageErr) {
+		t.Fatalf("Expected *UsageError from Query, got %v", err)
+	}
+
+	_, err = client.StartSessionWithCLIPath(context.Background(), options, "/nonexistent/claude")
+	if !errors.As(err, &usageErr) {
+		t.Errorf("Expected *UsageError from StartSession, got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// coalesce returns a transport sharing the output of an identical query
+// already running, if the client coalesces queries and t is not replaying
+// the response cache. The first caller's t runs for all of them.
+func (c *Client) coalesce(prompt any, options *types.Options, cliPath string, t transport2.Transport) transport2.Transport {
+	if c.flights == nil {
+		return t
+	}
+	if _, cached := t.(*cachedTransport); cached {
+		return t
+	}
+	key, ok := queryKey(prompt, options)
+	if !ok {
+		return t
+	}
+
+	return c.flights.join(cliPath+"\x00"+key, func() *flight {
+		return &flight{t: t, pool: c.pool, options: options}
+	})
+}
+
+// flightGroup tracks the running flights of a client by query key.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: map[string]*flight{}}
+}
+
+// join subscribes to the flight running under key, starting one with
+// newFlight if there is none.
+func (g *flightGroup) join(key string, newFlight func() *flight) *sharedTransport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f, ok := g.flights[key]
+	if !ok {
+		f = newFlight()
+		f.group, f.key = g, key
+		f.changed = make(chan struct{})
+		g.flights[key] = f
+	}
+	f.subscribers++
+	return &sharedTransport{flight: f, leader: !ok}
+}
+
+// leave unsubscribes from f, stopping it once no subscriber is left.
+func (g *flightGroup) leave(f *flight) {
+	g.mu.Lock()
+	f.subscribers--
+	last := f.subscribers == 0
+	if last {
+		g.remove(f)
+	}
+	g.mu.Unlock()
+
+	if last {
+		f.stop()
+	}
+}
+
+// remove forgets f, so that later queries start a flight of their own.
+// The caller holds g.mu.
+func (g *flightGroup) remove(f *flight) {
+	if g.flights[f.key] == f {
+		delete(g.flights, f.key)
+	}
+}
+
+// flightEvent is a line of output or an error from a flight's transport.
+type flightEvent struct {
+	line []byte
+	err  error
+}
+
+// flight is a CLI process shared by identical queries. It holds the pool
+// slot for the process and keeps every event it produces, so that a
+// subscriber joining late still receives the output from the start.
+type flight struct {
+	group   *flightGroup
+	key     string
+	t       transport2.Transport
+	pool    *Pool
+	options *types.Options
+
+	// subscribers is guarded by group.mu
+	subscribers int
+
+	connectOnce sync.Once
+	connectErr  error
+	stopOnce    sync.Once
+	cancel      context.CancelFunc
+	release     func()
+
+	mu      sync.Mutex
+	events  []flightEvent
+	done    bool
+	changed chan struct{}
+}
+
+// connect starts the flight's process on the first call, and returns the
+// outcome to every caller.
+func (f *flight) connect(ctx context.Context) error {
+	f.connectOnce.Do(func() {
+		f.connectErr = f.start(ctx)
+		if f.connectErr != nil {
+			f.finish()
+		}
+	})
+	return f.connectErr
+}
+
+// start acquires a pool slot, waits on the rate limiter, and connects the
+// transport. The process outlives ctx as long as it has subscribers.
+func (f *flight) start(ctx context.Context) error {
+	if f.pool != nil {
+		release, err := f.pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		f.release = release
+	}
+	if err := waitRateLimit(ctx, f.options); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	f.cancel = cancel
+	if err := f.t.Connect(runCtx); err != nil {
+		return err
+	}
+
+	data, errs := f.t.Stream(runCtx)
+	go f.pump(data, errs)
+	return nil
+}
+
+// pump records the transport's output until it ends.
+func (f *flight) pump(data <-chan []byte, errs <-chan error) {
+	defer f.finish()
+
+	for data != nil || errs != nil {
+		select {
+		case line, ok := <-data:
+			if !ok {
+				data = nil
+				continue
+			}
+			f.publish(flightEvent{line: line})
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			f.publish(flightEvent{err: err})
+		}
+	}
+}
+
+// publish records an event and wakes the subscribers.
+func (f *flight) publish(event flightEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// finish ends the flight once its output is complete or it failed to
+// start.
+func (f *flight) finish() {
+	f.group.mu.Lock()
+	f.group.remove(f)
+	f.group.mu.Unlock()
+
+	f.mu.Lock()
+	f.done = true
+	close(f.changed)
+	f.changed = make(chan struct{})
+	f.mu.Unlock()
+
+	f.stop()
+}
+
+// stop shuts down the transport and frees the pool slot.
+func (f *flight) stop() {
+	f.stopOnce.Do(func() {
+		if f.cancel != nil {
+			f.cancel()
+		}
+		f.t.Close()
+		if f.release != nil {
+			f.release()
+		}
+	})
+}
+
+// next returns the events after the first n, whether the flight is done,
+// and a channel closed when there are more.
+func (f *flight) next(n int) ([]flightEvent, bool, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.events[n:], f.done, f.changed
+}
+
+// sharedTransport delivers a flight's output to one of its subscribers.
+// Closing it leaves the process running for the other subscribers.
+type sharedTransport struct {
+	flight *flight
+
+	// leader is set for the subscriber that started the flight
+	leader bool
+
+	leaveOnce sync.Once
+
+	mu        sync.Mutex
+	connected bool
+}
+
+// Connect starts the flight's process, or waits for it to start.
+func (st *sharedTransport) Connect(ctx context.Context) error {
+	if err := st.flight.connect(ctx); err != nil {
+		return err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.connected = true
+	return nil
+}
+
+// Stream sends the flight's output from the start, leaving the flight
+// once it is done or ctx is cancelled.
+func (st *sharedTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+		defer st.leave()
+
+		sent := 0
+		for {
+			events, done, changed := st.flight.next(sent)
+			for _, event := range events {
+				if event.err != nil {
+					select {
+					case errChan <- event.err:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+				// Each subscriber parses its own copy of the line
+				select {
+				case dataChan <- slices.Clone(event.line):
+				case <-ctx.Done():
+					return
+				}
+			}
+			sent += len(events)
+
+			if done {
+				return
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+func (st *sharedTransport) Close() error {
+	st.mu.Lock()
+	st.connected = false
+	st.mu.Unlock()
+
+	st.leave()
+	return nil
+}
+
+func (st *sharedTransport) IsConnected() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.connected
+}
+
+// Warnings returns the warnings of the flight's transport.
+func (st *sharedTransport) Warnings() []string {
+	if warner, ok := st.flight.t.(transport2.Warner); ok {
+		return warner.Warnings()
+	}
+	return nil
+}
+
+// leave unsubscribes from the flight, once.
+func (st *sharedTransport) leave() {
+	st.leaveOnce.Do(func() {
+		st.flight.group.leave(st.flight)
+	})
+}
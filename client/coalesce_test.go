@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// gatedTransport sends its first message at once and the rest when
+// released, counting how often it is connected.
+type gatedTransport struct {
+	mockMessageTransport
+	gate     chan struct{}
+	connects atomic.Int32
+}
+
+func newGatedTransport(messages ...string) *gatedTransport {
+	return &gatedTransport{
+		mockMessageTransport: mockMessageTransport{messages: messages},
+		gate:                 make(chan struct{}),
+	}
+}
+
+func (gt *gatedTransport) Connect(ctx context.Context) error {
+	gt.connects.Add(1)
+	return gt.mockMessageTransport.Connect(ctx)
+}
+
+func (gt *gatedTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+
+		for i, msg := range gt.messages {
+			if i == 1 {
+				select {
+				case <-gt.gate:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case dataChan <- []byte(msg):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+var coalescedOutput = []string{
+	`{"type":"system","subtype":"init","session_id":"s"}`,
+	`{"type":"assistant","message":{"content":[{"type":"text","text":"Looks good"}]}}`,
+	`{"type":"result","subtype":"success","session_id":"s","total_cost_usd":0.25}`,
+}
+
+func TestCoalesce(t *testing.T) {
+	tracker := NewUsageTracker()
+	pool := NewPool(PoolOptions{MaxConcurrent: 1, MaxQueued: 1})
+	client := NewClientWithOptions(ClientOptions{Coalesce: true, UsageTracker: tracker, Pool: pool})
+	options := types.NewOptions()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := newGatedTransport(coalescedOutput...)
+	tr := client.coalesce("Review main.go", options, "claude", first)
+	leader, ok := tr.(*sharedTransport)
+	if !ok || !leader.leader {
+		t.Fatalf("Expected the first query to lead a flight, got %T", tr)
+	}
+	stream1, err := client.QueryWithTransport(ctx, "Review main.go", options, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for output, so the second query joins late
+	if msg := <-stream1.Messages(); msg.Type() != "system" {
+		t.Fatalf("Expected the init message, got %s", msg.Type())
+	}
+
+	second := newGatedTransport(coalescedOutput...)
+	tr = client.coalesce(" Review main.go\n", options, "claude", second)
+	if follower, ok := tr.(*sharedTransport); !ok || follower.leader || follower.flight != leader.flight {
+		t.Fatalf("Expected the identical query to join the flight, got %T", tr)
+	}
+	stream2, err := client.QueryWithTransport(ctx, "Review main.go", options, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other := client.coalesce("Review util.go", options, "claude", second).(*sharedTransport); !other.leader {
+		t.Error("Expected a different prompt to start a flight of its own")
+	}
+
+	close(first.gate)
+	rest, errs := drain(t, stream1)
+	if len(rest) != 2 || len(errs) != 0 {
+		t.Errorf("Expected the leader to receive the rest of the output, got %d messages and %v", len(rest), errs)
+	}
+	messages, errs := drain(t, stream2)
+	if len(messages) != 3 || len(errs) != 0 {
+		t.Fatalf("Expected the follower to receive the whole output, got %d messages and %v", len(messages), errs)
+	}
+	if messages[0].Type() != "system" || messages[2].Type() != "result" {
+		t.Errorf("Expected the output in order, got %s first and %s last", messages[0].Type(), messages[2].Type())
+	}
+
+	if n := first.connects.Load(); n != 1 {
+		t.Errorf("Expected one process, got %d", n)
+	}
+	if n := second.connects.Load(); n != 0 {
+		t.Errorf("Expected the follower's transport not to run, got %d connects", n)
+	}
+	if usage := tracker.Snapshot(); usage.Queries != 1 {
+		t.Errorf("Expected the coalesced query to be recorded once, got %d", usage.Queries)
+	}
+
+	waitForStats(t, pool, func(s PoolStats) bool { return s.Active == 0 && s.Started == 1 })
+	if tr := client.coalesce("Review main.go", options, "claude", second); tr.(*sharedTransport).flight == leader.flight {
+		t.Error("Expected a query after the flight ended to start a new one")
+	}
+}
+
+func TestCoalesceLeaderClose(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{Coalesce: true})
+	options := types.NewOptions()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := newGatedTransport(coalescedOutput...)
+	stream1, err := client.QueryWithTransport(ctx, "prompt", options, client.coalesce("prompt", options, "", first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream2, err := client.QueryWithTransport(ctx, "prompt", options, client.coalesce("prompt", options, "", first))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream1.Close()
+	close(first.gate)
+
+	messages, errs := drain(t, stream2)
+	if len(messages) != 3 || len(errs) != 0 {
+		t.Errorf("Expected the follower to finish after the leader closed, got %d messages and %v", len(messages), errs)
+	}
+}
+
+func TestCoalesceDisabled(t *testing.T) {
+	client := NewClient()
+	tr := newGatedTransport()
+	if got := client.coalesce("prompt", types.NewOptions(), "", tr); got != tr {
+		t.Errorf("Expected queries not to be coalesced by default, got %T", got)
+	}
+}
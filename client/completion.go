@@ -0,0 +1,62 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// completion reports how a stream ended to Options.CompletionCallback.
+type completion struct {
+	callback func(*types.ResultSummary)
+	once     sync.Once
+
+	mu     sync.Mutex
+	result *types.ResultMessage
+	err    error
+}
+
+func newCompletion(callback func(*types.ResultSummary)) *completion {
+	return &completion{callback: callback}
+}
+
+// observe records the stream's latest result.
+func (c *completion) observe(msg types.Message) {
+	if result, ok := msg.(*types.ResultMessage); ok {
+		c.mu.Lock()
+		c.result = result
+		c.mu.Unlock()
+	}
+}
+
+// observeError records the first error the stream reports.
+func (c *completion) observeError(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+}
+
+// finish calls the callback, the first time only. If the stream reported
+// neither a result nor an error, err is reported instead.
+func (c *completion) finish(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		result := c.result
+		if c.err != nil || result != nil {
+			err = c.err
+		}
+		c.mu.Unlock()
+
+		c.callback(types.NewResultSummary(result, err))
+	})
+}
+
+// reportFailure reports a query that failed before its stream was created
+// to the options' completion callback, if set.
+func reportFailure(options *types.Options, err error) {
+	if options.CompletionCallback != nil {
+		options.CompletionCallback(types.NewResultSummary(nil, err))
+	}
+}
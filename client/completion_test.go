@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// summaries collects the summaries passed to a completion callback.
+type summaries struct {
+	mu   sync.Mutex
+	list []*types.ResultSummary
+}
+
+func (s *summaries) callback(summary *types.ResultSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.list = append(s.list, summary)
+}
+
+func (s *summaries) get() []*types.ResultSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list
+}
+
+func TestCompletionCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		err    error
+		close  bool
+		status types.CompletionStatus
+		kind   types.Kind
+	}{
+		{
+			name: "success",
+			lines: []string{
+				`{"type": "result", "subtype": "success", "session_id": "s1", "num_turns": 2, "duration_ms": 1500, "total_cost_usd": 0.25}`,
+			},
+			status: types.CompletionSuccess,
+		},
+		{
+			name: "error result",
+			lines: []string{
+				`{"type": "result", "subtype": "error_max_turns", "is_error": true, "session_id": "s1", "num_turns": 2}`,
+			},
+			status: types.CompletionError,
+			kind:   types.KindUnknown,
+		},
+		{
+			name:   "process error",
+			err:    types.NewProcessError("CLI failed", 1, "boom"),
+			status: types.CompletionError,
+			kind:   types.KindProcess,
+		},
+		{
+			name:   "no result",
+			status: types.CompletionError,
+			kind:   types.KindUnknown,
+		},
+		{
+			name:   "closed",
+			close:  true,
+			status: types.CompletionCanceled,
+			kind:   types.KindCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := newMockInputTransport()
+			for _, line := range tt.lines {
+				mt.data <- []byte(line)
+			}
+			if tt.err != nil {
+				mt.errs <- tt.err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			var got summaries
+			options := types.NewOptions().WithCompletionCallback(got.callback)
+			stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+			if err != nil {
+				t.Fatalf("QueryWithTransport failed: %v", err)
+			}
+
+			if tt.close {
+				stream.Close()
+			} else {
+				mt.Close()
+			}
+			for range stream.Messages() {
+			}
+			for range stream.Errors() {
+			}
+			stream.Close()
+
+			deadline := time.Now().Add(time.Second)
+			for len(got.get()) == 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			list := got.get()
+			if len(list) != 1 {
+				t.Fatalf("Expected one summary, got %d", len(list))
+			}
+			summary := list[0]
+			if summary.Status != tt.status {
+				t.Errorf("Expected status %s, got %s (%v)", tt.status, summary.Status, summary.Err)
+			}
+			if tt.status == types.CompletionSuccess {
+				if summary.Err != nil || summary.ErrorKind != "" {
+					t.Errorf("Expected no error, got %v", summary.Err)
+				}
+				if summary.SessionID != "s1" || summary.CostUSD != 0.25 || summary.NumTurns != 2 || summary.DurationMs != 1500 {
+					t.Errorf("Unexpected summary: %+v", summary)
+				}
+			} else if summary.Err == nil || summary.ErrorKind != tt.kind {
+				t.Errorf("Expected an error of kind %s, got %v (%s)", tt.kind, summary.Err, summary.ErrorKind)
+			}
+		})
+	}
+}
+
+func TestCompletionCallbackStartFailure(t *testing.T) {
+	var got summaries
+	options := types.NewOptions().WithCompletionCallback(got.callback)
+
+	connectErr := types.NewConnectionError("failed to start", nil)
+	_, err := NewClient().QueryWithTransport(context.Background(), "Hello", options, &failedTransport{err: connectErr})
+	if !errors.Is(err, connectErr) {
+		t.Fatalf("Expected the connection error, got %v", err)
+	}
+
+	list := got.get()
+	if len(list) != 1 || list[0].Status != types.CompletionError || list[0].ErrorKind != types.KindConnection {
+		t.Errorf("Expected one connection failure, got %+v", list)
+	}
+}
@@ -0,0 +1,339 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// initializeTimeout bounds how long to wait for the CLI to acknowledge the
+// initialize control request.
+const initializeTimeout = 60 * time.Second
+
+// controlMessage is the envelope for control protocol messages exchanged
+// with the CLI over stream-json input/output.
+type controlMessage struct {
+	Type      string           `json:"type"`
+	RequestID string           `json:"request_id,omitempty"`
+	Request   map[string]any   `json:"request,omitempty"`
+	Response  *controlResponse `json:"response,omitempty"`
+}
+
+// controlResponse is the payload of a control_response message.
+type controlResponse struct {
+	Subtype   string         `json:"subtype"`
+	RequestID string         `json:"request_id"`
+	Response  map[string]any `json:"response,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// controlState tracks in-flight control requests for a stream.
+type controlState struct {
+	counter uint64
+	pending map[string]chan controlResponse
+
+	// done is closed when CLI output ends so waiting requests can give up.
+	done chan struct{}
+
+	// enabled is set once the CLI accepts control requests on stdin.
+	enabled bool
+}
+
+// isControlMessage reports whether a raw line is a control protocol message.
+// The substring check avoids decoding ordinary messages twice.
+func isControlMessage(line []byte) bool {
+	return bytes.Contains(line, []byte(`"control_`))
+}
+
+// routeControlMessages filters control protocol messages out of the raw CLI
+// output and forwards everything else to the returned channel for parsing.
+// Every line is first passed to the raw message handler, if one is set.
+func (qs *QueryStream) routeControlMessages(rawData <-chan []byte) <-chan []byte {
+	out := make(chan []byte, cap(rawData))
+
+	go func() {
+		defer close(out)
+		defer close(qs.control.done)
+
+		for {
+			select {
+			case <-qs.ctx.Done():
+				return
+			case line, ok := <-rawData:
+				if !ok {
+					return
+				}
+
+				if qs.rawMessageHandler != nil {
+					qs.rawMessageHandler(json.RawMessage(line))
+				}
+				qs.noteActivity()
+				if qs.timeouts != nil {
+					qs.timeouts.noteOutput()
+				}
+
+				if isControlMessage(line) {
+					var msg controlMessage
+					if err := json.Unmarshal(line, &msg); err == nil {
+						switch msg.Type {
+						case "control_response":
+							qs.deliverControlResponse(msg.Response)
+							continue
+						case "control_request":
+							go qs.handleControlRequest(msg)
+							continue
+						case "control_cancel_request":
+							continue
+						}
+					}
+				}
+
+				select {
+				case out <- line:
+				case <-qs.ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// deliverControlResponse hands a response to the goroutine waiting on it.
+func (qs *QueryStream) deliverControlResponse(resp *controlResponse) {
+	if resp == nil {
+		return
+	}
+
+	qs.controlMutex.Lock()
+	waiter, ok := qs.control.pending[resp.RequestID]
+	if ok {
+		delete(qs.control.pending, resp.RequestID)
+	}
+	qs.controlMutex.Unlock()
+
+	if ok {
+		waiter <- *resp
+	}
+}
+
+// handleControlRequest answers a control request initiated by the CLI.
+func (qs *QueryStream) handleControlRequest(msg controlMessage) {
+	subtype, _ := msg.Request["subtype"].(string)
+
+	switch subtype {
+	case "hook_callback":
+		response, err := qs.handleHookCallback(msg.Request)
+		qs.sendControlResponse(msg.RequestID, response, err)
+	default:
+		qs.sendControlResponse(msg.RequestID, nil, fmt.Errorf("unsupported control request subtype: %s", subtype))
+	}
+}
+
+// needsControlProtocol reports whether the options use features that require
+// the CLI to call back into the SDK over the control protocol.
+func needsControlProtocol(options *types.Options) bool {
+	return len(options.Hooks) > 0
+}
+
+// initialize registers SDK-side callbacks with the CLI. It must complete
+// before the first prompt is sent so callbacks apply to that turn.
+func (qs *QueryStream) initialize(options *types.Options) error {
+	ctx, cancel := context.WithTimeout(qs.ctx, initializeTimeout)
+	defer cancel()
+
+	request := map[string]any{"subtype": "initialize"}
+	if hooks := qs.registerHooks(options.Hooks); hooks != nil {
+		request["hooks"] = hooks
+	}
+
+	if _, err := qs.sendControlRequest(ctx, request); err != nil {
+		return fmt.Errorf("failed to initialize control protocol: %w", err)
+	}
+	return nil
+}
+
+// startControlledQuery initializes the control protocol and sends a one-shot
+// prompt. Stdin stays open until the result arrives so the CLI can keep
+// issuing control requests while the query runs.
+func (qs *QueryStream) startControlledQuery(prompt any, options *types.Options) error {
+	input, ok := qs.transport.(transport.InputTransport)
+	if !ok {
+		return fmt.Errorf("transport does not support control requests")
+	}
+
+	if err := qs.initialize(options); err != nil {
+		return err
+	}
+
+	data, err := transport.EncodeUserMessage(prompt, "")
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt: %w", err)
+	}
+
+	qs.controlMutex.Lock()
+	qs.control.enabled = true
+	qs.closeInputOnResult = true
+	qs.controlMutex.Unlock()
+
+	return input.Write(qs.ctx, data)
+}
+
+// closeInputIfDone closes stdin after the result of a controlled one-shot query.
+func (qs *QueryStream) closeInputIfDone(msg types.Message) {
+	if _, ok := msg.(*types.ResultMessage); !ok {
+		return
+	}
+
+	qs.controlMutex.Lock()
+	closeInput := qs.closeInputOnResult
+	qs.closeInputOnResult = false
+	qs.controlMutex.Unlock()
+
+	if closeInput {
+		qs.controlMutex.Lock()
+		qs.control.enabled = false
+		qs.controlMutex.Unlock()
+
+		if input, ok := qs.transport.(transport.InputTransport); ok {
+			input.CloseInput()
+		}
+	}
+}
+
+// enableControl marks the stream as able to send control requests.
+func (qs *QueryStream) enableControl() {
+	qs.controlMutex.Lock()
+	qs.control.enabled = true
+	qs.controlMutex.Unlock()
+}
+
+// controlEnabled reports whether control requests can be sent to the CLI.
+func (qs *QueryStream) controlEnabled() bool {
+	qs.controlMutex.Lock()
+	defer qs.controlMutex.Unlock()
+	return qs.control.enabled
+}
+
+// Interrupt asks the CLI to stop its current turn. When the stream uses the
+// control protocol, an interrupt control request is sent and the CLI reports
+// the end of the turn as usual; otherwise the CLI process is sent an
+// interrupt signal if the transport supports it.
+func (qs *QueryStream) Interrupt(ctx context.Context) error {
+	if qs.controlEnabled() {
+		_, err := qs.sendControlRequest(ctx, map[string]any{"subtype": "interrupt"})
+		return err
+	}
+
+	if interrupter, ok := qs.transport.(transport.Interrupter); ok {
+		return interrupter.Interrupt()
+	}
+	return fmt.Errorf("transport does not support interrupts")
+}
+
+// sendControlResponse writes a success or error response for a CLI-initiated request.
+func (qs *QueryStream) sendControlResponse(requestID string, response map[string]any, err error) {
+	input, ok := qs.transport.(transport.InputTransport)
+	if !ok {
+		return
+	}
+
+	resp := &controlResponse{
+		Subtype:   "success",
+		RequestID: requestID,
+		Response:  response,
+	}
+	if err != nil {
+		resp.Subtype = "error"
+		resp.Response = nil
+		resp.Error = err.Error()
+	}
+
+	data, marshalErr := json.Marshal(controlMessage{Type: "control_response", Response: resp})
+	if marshalErr != nil {
+		qs.reportError(fmt.Errorf("failed to encode control response: %w", marshalErr))
+		return
+	}
+
+	if writeErr := input.Write(qs.ctx, data); writeErr != nil {
+		qs.reportError(writeErr)
+	}
+}
+
+// sendControlRequest sends a control request to the CLI and waits for its response.
+// The transport must implement transport.InputTransport and be in streaming input mode.
+func (qs *QueryStream) sendControlRequest(ctx context.Context, request map[string]any) (map[string]any, error) {
+	input, ok := qs.transport.(transport.InputTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support control requests")
+	}
+
+	requestID := qs.nextControlRequestID()
+	waiter := make(chan controlResponse, 1)
+
+	qs.controlMutex.Lock()
+	qs.control.pending[requestID] = waiter
+	qs.controlMutex.Unlock()
+
+	defer func() {
+		qs.controlMutex.Lock()
+		delete(qs.control.pending, requestID)
+		qs.controlMutex.Unlock()
+	}()
+
+	data, err := json.Marshal(controlMessage{
+		Type:      "control_request",
+		RequestID: requestID,
+		Request:   request,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode control request: %w", err)
+	}
+
+	if err := input.Write(ctx, data); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-waiter:
+		if resp.Subtype == "error" {
+			return nil, fmt.Errorf("control request %s failed: %s", request["subtype"], resp.Error)
+		}
+		return resp.Response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-qs.control.done:
+		return nil, fmt.Errorf("control request %s failed: stream ended", request["subtype"])
+	}
+}
+
+// nextControlRequestID returns a unique identifier for an outgoing control request.
+func (qs *QueryStream) nextControlRequestID() string {
+	n := atomic.AddUint64(&qs.control.counter, 1)
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("req_%d", n)
+	}
+	return fmt.Sprintf("req_%d_%s", n, hex.EncodeToString(suffix))
+}
+
+// reportError forwards an internally generated error to the errors channel.
+// It never blocks; errors are dropped if the stream is shutting down or the
+// buffer is full.
+func (qs *QueryStream) reportError(err error) {
+	select {
+	case qs.internalErrors <- err:
+	case <-qs.ctx.Done():
+	default:
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// registerHooks assigns a callback ID to every hook callback and returns the
+// hooks configuration sent to the CLI in the initialize request.
+func (qs *QueryStream) registerHooks(hooks map[types.HookEvent][]types.HookMatcher) map[string]any {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	qs.controlMutex.Lock()
+	defer qs.controlMutex.Unlock()
+
+	if qs.hookCallbacks == nil {
+		qs.hookCallbacks = make(map[string]types.HookCallback)
+	}
+
+	config := make(map[string]any)
+	for event, matchers := range hooks {
+		var eventMatchers []map[string]any
+		for _, matcher := range matchers {
+			callbackIDs := make([]string, 0, len(matcher.Hooks))
+			for _, callback := range matcher.Hooks {
+				id := fmt.Sprintf("hook_%d", len(qs.hookCallbacks))
+				qs.hookCallbacks[id] = callback
+				callbackIDs = append(callbackIDs, id)
+			}
+
+			var pattern any
+			if matcher.Matcher != "" {
+				pattern = matcher.Matcher
+			}
+			entry := map[string]any{
+				"matcher":         pattern,
+				"hookCallbackIds": callbackIDs,
+			}
+			if matcher.Timeout > 0 {
+				entry["timeout"] = matcher.Timeout.Seconds()
+			}
+			eventMatchers = append(eventMatchers, entry)
+		}
+		config[string(event)] = eventMatchers
+	}
+
+	return config
+}
+
+// handleHookCallback runs the Go callback for a hook_callback control request
+// and returns its output in the form expected by the CLI.
+func (qs *QueryStream) handleHookCallback(request map[string]any) (map[string]any, error) {
+	callbackID, _ := request["callback_id"].(string)
+
+	qs.controlMutex.Lock()
+	callback, ok := qs.hookCallbacks[callbackID]
+	qs.controlMutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no hook callback found for ID: %s", callbackID)
+	}
+
+	rawInput, _ := request["input"].(map[string]any)
+	input, err := decodeHookInput(rawInput)
+	if err != nil {
+		return nil, err
+	}
+
+	toolUseID, _ := request["tool_use_id"].(string)
+
+	output, err := callback(qs.ctx, input, toolUseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeHookOutput(output)
+}
+
+// decodeHookInput converts the raw hook input into a typed HookInput.
+func decodeHookInput(raw map[string]any) (types.HookInput, error) {
+	var input types.HookInput
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return input, fmt.Errorf("failed to decode hook input: %w", err)
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return input, fmt.Errorf("failed to decode hook input: %w", err)
+	}
+
+	input.Raw = raw
+	return input, nil
+}
+
+// encodeHookOutput converts a HookOutput into the generic response payload.
+func encodeHookOutput(output types.HookOutput) (map[string]any, error) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook output: %w", err)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to encode hook output: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// waitForWritten polls until the transport has received n messages.
+func waitForWritten(t *testing.T, mt *mockInputTransport, n int) []map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if written := mt.writtenMessages(); len(written) >= n {
+			return written
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d written messages, got %v", n, mt.writtenMessages())
+	return nil
+}
+
+func TestControlledQueryRegistersHooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var gotInput types.HookInput
+	var gotToolUseID string
+	options := types.NewOptions().AddHook(types.HookEventPreToolUse, "Bash",
+		func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+			gotInput = input
+			gotToolUseID = toolUseID
+			return types.PreToolUseOutput("deny", "not allowed", nil), nil
+		})
+
+	mt := newMockInputTransport()
+	stream := NewQueryStream(ctx, mt, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	if err := stream.startControlledQuery("run ls", options); err != nil {
+		t.Fatalf("startControlledQuery failed: %v", err)
+	}
+
+	written := mt.writtenMessages()
+	if len(written) != 2 {
+		t.Fatalf("Expected initialize and prompt, got %v", written)
+	}
+
+	// Initialize must precede the prompt and carry the hook registration
+	request := written[0]["request"].(map[string]any)
+	if request["subtype"] != "initialize" {
+		t.Fatalf("Expected initialize request first, got %v", request)
+	}
+	hooks := request["hooks"].(map[string]any)
+	matchers := hooks["PreToolUse"].([]any)
+	matcher := matchers[0].(map[string]any)
+	if matcher["matcher"] != "Bash" {
+		t.Errorf("Expected matcher 'Bash', got %v", matcher["matcher"])
+	}
+	ids := matcher["hookCallbackIds"].([]any)
+	if len(ids) != 1 || ids[0] != "hook_0" {
+		t.Errorf("Unexpected callback IDs: %v", ids)
+	}
+	if written[1]["type"] != "user" {
+		t.Errorf("Expected prompt after initialize, got %v", written[1])
+	}
+
+	// The CLI invokes the hook
+	mt.data <- []byte(`{"type":"control_request","request_id":"cli_1","request":{"subtype":"hook_callback","callback_id":"hook_0","tool_use_id":"toolu_1","input":{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"ls"}}}}`)
+
+	written = waitForWritten(t, mt, 3)
+	resp := written[2]["response"].(map[string]any)
+	if resp["subtype"] != "success" || resp["request_id"] != "cli_1" {
+		t.Fatalf("Unexpected control response: %v", resp)
+	}
+	specific := resp["response"].(map[string]any)["hookSpecificOutput"].(map[string]any)
+	if specific["permissionDecision"] != "deny" || specific["permissionDecisionReason"] != "not allowed" {
+		t.Errorf("Unexpected hook output: %v", specific)
+	}
+
+	if gotInput.ToolName != "Bash" || gotInput.ToolInput["command"] != "ls" {
+		t.Errorf("Unexpected hook input: %+v", gotInput)
+	}
+	if gotInput.Raw["hook_event_name"] != "PreToolUse" {
+		t.Errorf("Expected raw input to be preserved, got %v", gotInput.Raw)
+	}
+	if gotToolUseID != "toolu_1" {
+		t.Errorf("Expected tool use ID 'toolu_1', got %q", gotToolUseID)
+	}
+}
+
+func TestRegisterHooksTimeout(t *testing.T) {
+	callback := func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		return types.HookOutput{}, nil
+	}
+	qs := &QueryStream{}
+	config := qs.registerHooks(map[types.HookEvent][]types.HookMatcher{
+		types.HookEventPreToolUse: {
+			{Hooks: []types.HookCallback{callback}},
+			{Matcher: "Bash", Hooks: []types.HookCallback{callback}, Timeout: 90 * time.Second},
+		},
+	})
+
+	matchers := config["PreToolUse"].([]map[string]any)
+	if _, ok := matchers[0]["timeout"]; ok {
+		t.Errorf("Expected no timeout for the default, got %v", matchers[0])
+	}
+	if matchers[1]["timeout"] != 90.0 {
+		t.Errorf("Expected a timeout of 90 seconds, got %v", matchers[1]["timeout"])
+	}
+}
+
+func TestHookCallbackErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := types.NewOptions().AddHook(types.HookEventPostToolUse, "",
+		func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+			return types.HookOutput{}, errors.New("hook failed")
+		})
+
+	mt := newMockInputTransport()
+	stream := NewQueryStream(ctx, mt, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	if err := stream.initialize(options); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	mt.data <- []byte(`{"type":"control_request","request_id":"cli_1","request":{"subtype":"hook_callback","callback_id":"hook_0","input":{}}}`)
+	mt.data <- []byte(`{"type":"control_request","request_id":"cli_2","request":{"subtype":"hook_callback","callback_id":"hook_99","input":{}}}`)
+
+	written := waitForWritten(t, mt, 3)
+	for _, msg := range written[1:] {
+		resp := msg["response"].(map[string]any)
+		if resp["subtype"] != "error" {
+			t.Errorf("Expected error response, got %v", resp)
+		}
+	}
+}
+
+func TestControlledQueryClosesInputOnResult(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := &closeTrackingTransport{mockInputTransport: newMockInputTransport()}
+	stream := NewQueryStream(ctx, mt, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	options := types.NewOptions().AddHook(types.HookEventStop, "",
+		func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+			return types.HookOutput{}, nil
+		})
+	if err := stream.startControlledQuery("hello", options); err != nil {
+		t.Fatalf("startControlledQuery failed: %v", err)
+	}
+
+	for msg := range stream.Messages() {
+		if _, ok := msg.(*types.ResultMessage); ok {
+			break
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !mt.inputClosed() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !mt.inputClosed() {
+		t.Error("Expected stdin to be closed after the result")
+	}
+}
+
+type closeTrackingTransport struct {
+	*mockInputTransport
+	closedInput bool
+}
+
+func (ct *closeTrackingTransport) CloseInput() error {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.closedInput = true
+	return nil
+}
+
+func (ct *closeTrackingTransport) inputClosed() bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.closedInput
+}
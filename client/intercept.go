@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// RequestKind is the kind of call a Request describes.
+type RequestKind string
+
+const (
+	// RequestQuery is a one-shot query.
+	RequestQuery RequestKind = "query"
+
+	// RequestSession is the start of an interactive session.
+	RequestSession RequestKind = "session"
+
+	// RequestSend is a prompt sent to a running session.
+	RequestSend RequestKind = "send"
+)
+
+// Request is a query, session, or session prompt about to start, as seen
+// by interceptors. Interceptors may change its fields before calling next.
+type Request struct {
+	Kind RequestKind
+
+	// Prompt is the text prompt of a query or sent prompt. It is empty for
+	// sessions and for multi-part prompts, whose blocks are in Content.
+	Prompt string
+
+	// Content holds the content blocks of a multi-part prompt, as built by
+	// types.Prompt, and is nil otherwise.
+	Content []map[string]any
+
+	// Options configures the query or session. Interceptors receive their
+	// own copy, so they may modify it. For RequestSend, it holds the
+	// session's options, and changes have no effect.
+	Options *types.Options
+
+	// CLIPath is the CLI binary to run, or empty to discover it.
+	CLIPath string
+
+	// Transport is the transport passed to QueryWithTransport, or nil.
+	Transport transport2.Transport
+}
+
+// Handler starts the call a Request describes and returns its stream. For
+// sessions and sent prompts, it is the session's stream.
+type Handler func(ctx context.Context, req *Request) (*QueryStream, error)
+
+// Interceptor wraps the start of queries, sessions, and sent prompts, like
+// a gRPC interceptor: it may inspect or change the request, call next to
+// proceed, act on the result, or return without calling next to refuse
+// the call or, for queries, answer it some other way. Sessions start only
+// if next is called, and the stream it returned must be returned with
+// them.
+//
+// Example:
+//
+//	client.Use(func(ctx context.Context, req *client.Request, next client.Handler) (*client.QueryStream, error) {
+//		start := time.Now()
+//		stream, err := next(ctx, req)
+//		log.Printf("%s started in %v: %v", req.Kind, time.Since(start), err)
+//		return stream, err
+//	})
+type Interceptor func(ctx context.Context, req *Request, next Handler) (*QueryStream, error)
+
+// Use adds interceptors to the client, for queries and sessions started
+// after the call. The first interceptor added is the outermost.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+	c.interceptors = append(c.interceptors[:len(c.interceptors):len(c.interceptors)], interceptors...)
+}
+
+// intercept runs the request through the client's interceptors, with
+// handler as the innermost.
+func (c *Client) intercept(ctx context.Context, req *Request, handler Handler) (*QueryStream, error) {
+	c.interceptorsMu.RLock()
+	interceptors := c.interceptors
+	c.interceptorsMu.RUnlock()
+
+	if len(interceptors) == 0 {
+		return handler(ctx, req)
+	}
+
+	switch {
+	case req.Options == nil:
+		req.Options = types.NewOptions()
+	case req.Kind != RequestSend:
+		req.Options = req.Options.Clone()
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req *Request) (*QueryStream, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	stream, err := handler(ctx, req)
+	if stream == nil && err == nil {
+		return nil, errNoStream
+	}
+	return stream, err
+}
+
+// errNoStream is returned when an interceptor returns neither a stream
+// nor an error.
+var errNoStream = errors.New("interceptor returned neither a stream nor an error")
+
+// errSessionNotStarted is returned when an interceptor does not return
+// the stream of the session it was asked to start.
+var errSessionNotStarted = errors.New("interceptor did not return the started session's stream")
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// configuredTransport records the configuration it receives.
+type configuredTransport struct {
+	mockMessageTransport
+	config *transport2.Config
+}
+
+func (ct *configuredTransport) Configure(config *transport2.Config) {
+	ct.config = config
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	client := NewClient()
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, req *Request, next Handler) (*QueryStream, error) {
+			calls = append(calls, name+" before")
+			stream, err := next(ctx, req)
+			calls = append(calls, name+" after")
+			return stream, err
+		}
+	}
+	client.Use(record("outer"))
+	client.Use(record("inner"))
+
+	stream, err := client.QueryWithTransport(context.Background(), "prompt", nil, &mockMessageTransport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drain(t, stream)
+
+	want := "outer before, inner before, inner after, outer after"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestInterceptorChangesRequest(t *testing.T) {
+	client := NewClient()
+	client.Use(func(ctx context.Context, req *Request, next Handler) (*QueryStream, error) {
+		if req.Kind != RequestQuery {
+			t.Errorf("Expected a query request, got %s", req.Kind)
+		}
+		req.Prompt = strings.ReplaceAll(req.Prompt, "hunter2", "[REDACTED]")
+		req.Options.WithSettings("/etc/claude/team.json")
+		return next(ctx, req)
+	})
+
+	options := types.NewOptions()
+	tr := &configuredTransport{}
+	stream, err := client.QueryWithTransport(context.Background(), "my password is hunter2", options, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drain(t, stream)
+
+	if tr.config.Prompt != "my password is [REDACTED]" {
+		t.Errorf("Expected the redacted prompt, got %q", tr.config.Prompt)
+	}
+	if settings := tr.config.Options.Settings; settings == nil || *settings != "/etc/claude/team.json" {
+		t.Errorf("Expected the injected settings, got %v", settings)
+	}
+	if options.Settings != nil {
+		t.Errorf("Expected the caller's options to be unchanged, got %v", *options.Settings)
+	}
+}
+
+func TestInterceptorRefuses(t *testing.T) {
+	client := NewClient()
+	refused := errors.New("over quota")
+	client.Use(func(ctx context.Context, req *Request, next Handler) (*QueryStream, error) {
+		return nil, refused
+	})
+
+	tr := &mockMessageTransport{}
+	if _, err := client.QueryWithTransport(context.Background(), "prompt", nil, tr); !errors.Is(err, refused) {
+		t.Errorf("Expected the interceptor's error, got %v", err)
+	}
+	if tr.connected {
+		t.Error("Expected the refused query not to start")
+	}
+
+	if _, err := client.StartSession(context.Background(), nil); !errors.Is(err, refused) {
+		t.Errorf("Expected the interceptor's error for a session, got %v", err)
+	}
+}
+
+func TestInterceptorMustReturnStream(t *testing.T) {
+	client := NewClient()
+	client.Use(func(ctx context.Context, req *Request, next Handler) (*QueryStream, error) {
+		return nil, nil
+	})
+
+	if _, err := client.QueryWithTransport(context.Background(), "prompt", nil, &mockMessageTransport{}); !errors.Is(err, errNoStream) {
+		t.Errorf("Expected errNoStream for a query, got %v", err)
+	}
+	if _, err := client.StartSession(context.Background(), nil); !errors.Is(err, errNoStream) {
+		t.Errorf("Expected errNoStream for a session, got %v", err)
+	}
+}
+
+func TestInterceptorSessionSend(t *testing.T) {
+	client := NewClient()
+	var kinds []RequestKind
+	client.Use(func(ctx context.Context, req *Request, next Handler) (*QueryStream, error) {
+		kinds = append(kinds, req.Kind)
+		req.Prompt = strings.ToUpper(req.Prompt)
+		return next(ctx, req)
+	})
+
+	ctx := context.Background()
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	session.intercept = client.intercept
+	if err := session.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	if err := session.Send(ctx, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := receiveUntilResult(t, session)
+	if text := msgs[0].(*types.AssistantMessage).Text(); text != "echo: HELLO" {
+		t.Errorf("Expected the intercepted prompt to be sent, got %q", text)
+	}
+	if len(kinds) != 1 || kinds[0] != RequestSend {
+		t.Errorf("Expected one send request, got %v", kinds)
+	}
+}
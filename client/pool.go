@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPoolFull is returned when a query cannot be queued because the pool's
+// queue is full.
+var ErrPoolFull = errors.New("pool queue is full")
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// MaxConcurrent is the maximum number of CLI processes running at
+	// once. Values below one are treated as one.
+	MaxConcurrent int
+
+	// MaxQueued limits how many queries may wait for a free slot; further
+	// queries fail with ErrPoolFull. If zero, the queue is unbounded.
+	MaxQueued int
+}
+
+// PoolStats is a snapshot of a pool's activity.
+type PoolStats struct {
+	// Active is the number of CLI processes holding a slot.
+	Active int
+
+	// Queued is the number of queries waiting for a slot.
+	Queued int
+
+	// Rejected is the total number of queries refused with ErrPoolFull.
+	Rejected int
+
+	// Started is the total number of queries that have been given a slot.
+	Started int
+}
+
+// Pool limits the number of CLI processes running at once. Attach one to
+// a client with ClientOptions.Pool; a pool may be shared by several
+// clients. Queries and sessions beyond the limit wait for a slot in the
+// order they arrived, and hold it until their stream ends. It is safe for
+// concurrent use.
+type Pool struct {
+	maxConcurrent int
+	maxQueued     int
+
+	mu       sync.Mutex
+	active   int
+	waiters  []*poolWaiter
+	rejected int
+	started  int
+}
+
+// poolWaiter is a query waiting for a slot.
+type poolWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewPool creates a pool with the given limits.
+func NewPool(opts PoolOptions) *Pool {
+	return &Pool{
+		maxConcurrent: max(opts.MaxConcurrent, 1),
+		maxQueued:     opts.MaxQueued,
+	}
+}
+
+// Acquire waits for a free slot and returns a function that releases it.
+// It fails with ErrPoolFull if the queue is full, or with the context's
+// error if ctx ends first.
+func (p *Pool) Acquire(ctx context.Context) (release func(), err error) {
+	p.mu.Lock()
+	if p.active < p.maxConcurrent && len(p.waiters) == 0 {
+		p.active++
+		p.started++
+		p.mu.Unlock()
+		return p.releaser(), nil
+	}
+
+	if p.maxQueued > 0 && len(p.waiters) >= p.maxQueued {
+		p.rejected++
+		p.mu.Unlock()
+		return nil, ErrPoolFull
+	}
+
+	w := &poolWaiter{ready: make(chan struct{})}
+	p.waiters = append(p.waiters, w)
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return p.releaser(), nil
+
+	case <-ctx.Done():
+		p.mu.Lock()
+		if w.granted {
+			// The slot was handed over as the context ended; pass it on
+			p.mu.Unlock()
+			p.release()
+			return nil, ctx.Err()
+		}
+		for i, waiter := range p.waiters {
+			if waiter == w {
+				p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+				break
+			}
+		}
+		p.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// releaser returns a function that releases a slot once.
+func (p *Pool) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(p.release)
+	}
+}
+
+// release hands a slot to the longest waiting query, or frees it.
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.waiters) == 0 {
+		p.active--
+		return
+	}
+
+	w := p.waiters[0]
+	p.waiters = p.waiters[1:]
+	w.granted = true
+	p.started++
+	close(w.ready)
+}
+
+// Stats returns a snapshot of the pool's activity.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Active:   p.active,
+		Queued:   len(p.waiters),
+		Rejected: p.rejected,
+		Started:  p.started,
+	}
+}
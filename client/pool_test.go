@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolQueuesInOrder(t *testing.T) {
+	pool := NewPool(PoolOptions{MaxConcurrent: 1})
+	ctx := context.Background()
+
+	release, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			release, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Acquire failed: %v", err)
+				return
+			}
+			order <- i
+			release()
+		}()
+		// Let each waiter join the queue before the next
+		waitForStats(t, pool, func(s PoolStats) bool { return s.Queued == i+1 })
+	}
+
+	release()
+	for want := 0; want < 3; want++ {
+		if got := <-order; got != want {
+			t.Errorf("Expected waiter %d to run next, got %d", want, got)
+		}
+	}
+
+	stats := pool.Stats()
+	if stats.Active != 0 || stats.Queued != 0 || stats.Started != 4 {
+		t.Errorf("Unexpected stats after all releases: %+v", stats)
+	}
+}
+
+func TestPoolRejectsWhenQueueFull(t *testing.T) {
+	pool := NewPool(PoolOptions{MaxConcurrent: 1, MaxQueued: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	go pool.Acquire(ctx)
+	waitForStats(t, pool, func(s PoolStats) bool { return s.Queued == 1 })
+
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("Expected ErrPoolFull, got %v", err)
+	}
+	if stats := pool.Stats(); stats.Rejected != 1 {
+		t.Errorf("Expected 1 rejection, got %d", stats.Rejected)
+	}
+}
+
+func TestPoolAcquireCancelled(t *testing.T) {
+	pool := NewPool(PoolOptions{MaxConcurrent: 1})
+
+	release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	release()
+	if stats := pool.Stats(); stats.Active != 0 || stats.Queued != 0 {
+		t.Errorf("Expected cancelled waiter to leave the queue, got %+v", stats)
+	}
+}
+
+func TestClientPoolLimitsQueries(t *testing.T) {
+	pool := NewPool(PoolOptions{MaxConcurrent: 1})
+	client := NewClientWithOptions(ClientOptions{Pool: pool})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	first := newMockInputTransport()
+	stream, err := client.QueryWithTransport(ctx, "first", nil, first)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	started := make(chan error, 1)
+	go func() {
+		stream, err := client.QueryWithTransport(ctx, "second", nil, newMockInputTransport())
+		if err == nil {
+			stream.Close()
+		}
+		started <- err
+	}()
+
+	waitForStats(t, pool, func(s PoolStats) bool { return s.Queued == 1 })
+
+	// The slot is freed once the first query's stream ends
+	first.data <- []byte(`{"type": "result", "subtype": "success"}`)
+	first.Close()
+	for range stream.Messages() {
+	}
+	for range stream.Errors() {
+	}
+
+	select {
+	case err := <-started:
+		if err != nil {
+			t.Errorf("Second query failed: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Second query did not start after the first ended")
+	}
+}
+
+// waitForStats waits until the pool's stats satisfy cond.
+func waitForStats(t *testing.T, pool *Pool, cond func(PoolStats) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond(pool.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for pool stats, have %+v", pool.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
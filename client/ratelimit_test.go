@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// countingLimiter counts waits and fails them with err, if set.
+type countingLimiter struct {
+	waits atomic.Int32
+	err   error
+}
+
+func (cl *countingLimiter) Wait(ctx context.Context) error {
+	cl.waits.Add(1)
+	return cl.err
+}
+
+func TestQueryWaitsOnRateLimiter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	limiter := &countingLimiter{}
+	options := types.NewOptions().WithRateLimiter(limiter)
+
+	mt := newMockInputTransport()
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	stream.Close()
+
+	if got := limiter.waits.Load(); got != 1 {
+		t.Errorf("Expected 1 wait, got %d", got)
+	}
+}
+
+func TestQueryRateLimiterError(t *testing.T) {
+	denied := errors.New("limit exceeded")
+	pool := NewPool(PoolOptions{MaxConcurrent: 1})
+	client := NewClientWithOptions(ClientOptions{Pool: pool})
+	options := types.NewOptions().WithRateLimiter(&countingLimiter{err: denied})
+
+	mt := newMockInputTransport()
+	_, err := client.QueryWithTransport(context.Background(), "Hello", options, mt)
+	if !errors.Is(err, denied) {
+		t.Fatalf("Expected limiter error, got %v", err)
+	}
+	if mt.IsConnected() {
+		t.Error("Expected transport not to be connected")
+	}
+	if stats := pool.Stats(); stats.Active != 0 {
+		t.Errorf("Expected pool slot to be released, got %+v", stats)
+	}
+}
+
+func TestSessionRateLimitsTurns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	limiter := &countingLimiter{}
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	session.limiter = limiter
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	for _, prompt := range []string{"one", "two"} {
+		if err := session.Send(ctx, prompt); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if got := limiter.waits.Load(); got != 2 {
+		t.Errorf("Expected 2 waits, got %d", got)
+	}
+
+	limiter.err = errors.New("limit exceeded")
+	if err := session.Send(ctx, "three"); !errors.Is(err, limiter.err) {
+		t.Errorf("Expected limiter error, got %v", err)
+	}
+	if got := len(mt.writtenMessages()); got != 2 {
+		t.Errorf("Expected 2 written messages, got %d", got)
+	}
+}
@@ -0,0 +1,314 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// reconnectTransport restarts a CLI process that fails after its session
+// has started, resuming the session in a new process whose output
+// continues on the same channels. Errors from a process are held back
+// until it ends and the reconnect policy decides whether to restart it,
+// except buffer overflows, which do not end the process.
+//
+// Each restart is announced by a system message line with subtype
+// types.SystemSubtypeReconnected, ahead of the new process's output.
+type reconnectTransport struct {
+	policy *types.ReconnectPolicy
+
+	// resume creates the transport for a process resuming a session
+	resume func(sessionID string) transport2.Transport
+
+	// oneShot is set for queries, which are done once their result
+	// arrives; a query process failing after its result is not restarted
+	oneShot bool
+
+	mu        sync.Mutex
+	current   transport2.Transport
+	closed    bool
+	connected bool
+	cancel    context.CancelFunc
+}
+
+// newReconnectTransport creates a transport that starts with first and
+// restarts failed processes according to policy.
+func newReconnectTransport(policy *types.ReconnectPolicy, first transport2.Transport, resume func(sessionID string) transport2.Transport, oneShot bool) *reconnectTransport {
+	return &reconnectTransport{
+		policy:  policy,
+		resume:  resume,
+		oneShot: oneShot,
+		current: first,
+	}
+}
+
+// reconnects reports whether a failed CLI process is restarted under the
+// options. The control protocol state of queries and sessions with hooks
+// would be lost, so they are not.
+func reconnects(options *types.Options) bool {
+	policy := options.ReconnectPolicy
+	return policy != nil && policy.MaxReconnects > 0 && !needsControlProtocol(options)
+}
+
+// resumeOptions returns a copy of options that resumes sessionID.
+func resumeOptions(options *types.Options, sessionID string) *types.Options {
+	resumed := options.Clone()
+	resumed.ContinueConversation = false
+	resumed.ForkSession = false
+	return resumed.WithResume(sessionID)
+}
+
+// Connect connects the first process's transport.
+func (rt *reconnectTransport) Connect(ctx context.Context) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.connected {
+		return nil
+	}
+	if err := rt.current.Connect(ctx); err != nil {
+		return err
+	}
+	rt.connected = true
+	return nil
+}
+
+// Stream forwards the output of each process in turn, restarting failed
+// processes while the policy allows.
+func (rt *reconnectTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, 10)
+	errChan := make(chan error, 10)
+
+	ctx, cancel := context.WithCancel(ctx)
+	rt.mu.Lock()
+	rt.cancel = cancel
+	t := rt.current
+	rt.mu.Unlock()
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+		defer cancel()
+
+		var sessionID string
+		for reconnect := 1; ; reconnect++ {
+			id, finished, errs := rt.runProcess(ctx, t, dataChan, errChan)
+			if id != "" {
+				sessionID = id
+			}
+			if len(errs) == 0 {
+				return
+			}
+
+			err := errors.Join(errs...)
+			if ctx.Err() != nil || sessionID == "" || (rt.oneShot && finished) || !rt.policy.ShouldReconnect(reconnect, err) {
+				for _, e := range errs {
+					select {
+					case errChan <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				return
+			}
+
+			timer := time.NewTimer(rt.policy.Delay(reconnect))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+
+			if t = rt.restart(ctx, sessionID); t == nil {
+				return
+			}
+			select {
+			case dataChan <- reconnectedLine(sessionID, reconnect, err):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+// runProcess forwards a process's output. It returns the session ID from
+// the process's init message, whether its result arrived, and the errors
+// it reported.
+func (rt *reconnectTransport) runProcess(ctx context.Context, t transport2.Transport, dataChan chan<- []byte, errChan chan<- error) (sessionID string, finished bool, held []error) {
+	data, errs := t.Stream(ctx)
+
+	for data != nil || errs != nil {
+		select {
+		case line, ok := <-data:
+			if !ok {
+				data = nil
+				continue
+			}
+			if id, result := scanSessionLine(line); id != "" {
+				sessionID = id
+			} else if result {
+				finished = true
+			}
+			select {
+			case dataChan <- line:
+			case <-ctx.Done():
+				return sessionID, finished, held
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if types.ErrorKind(err) != types.KindBufferOverflow {
+				held = append(held, err)
+				continue
+			}
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+				return sessionID, finished, held
+			}
+
+		case <-ctx.Done():
+			return sessionID, finished, held
+		}
+	}
+
+	return sessionID, finished, held
+}
+
+// scanSessionLine returns the session ID of an init message, or reports
+// whether line is a result message. The CLI writes compact JSON, so the
+// substring checks skip decoding every other line.
+func scanSessionLine(line []byte) (sessionID string, result bool) {
+	if !bytes.Contains(line, []byte(`"subtype":"init"`)) && !bytes.Contains(line, []byte(`"type":"result"`)) {
+		return "", false
+	}
+
+	var msg struct {
+		Type      string `json:"type"`
+		Subtype   string `json:"subtype"`
+		SessionID string `json:"session_id"`
+	}
+	if json.Unmarshal(line, &msg) != nil {
+		return "", false
+	}
+	switch {
+	case msg.Type == "system" && msg.Subtype == types.SystemSubtypeInit:
+		return msg.SessionID, false
+	case msg.Type == "result":
+		return "", true
+	}
+	return "", false
+}
+
+// reconnectedLine encodes the system message announcing a restart. It
+// starts with a newline so that an incomplete line the failed process left
+// behind ends there, and is reported as malformed, rather than being
+// joined to this one.
+func reconnectedLine(sessionID string, reconnect int, err error) []byte {
+	line, _ := json.Marshal(map[string]any{
+		"type":       "system",
+		"subtype":    types.SystemSubtypeReconnected,
+		"session_id": sessionID,
+		"reconnect":  reconnect,
+		"error":      err.Error(),
+	})
+	return append([]byte("\n"), line...)
+}
+
+// restart replaces the failed process with one resuming sessionID. It
+// returns nil once the transport has been closed.
+func (rt *reconnectTransport) restart(ctx context.Context, sessionID string) transport2.Transport {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.closed {
+		return nil
+	}
+
+	rt.current.Close()
+	rt.current = rt.resume(sessionID)
+	if err := rt.current.Connect(ctx); err != nil {
+		// Let the process report the failure through its error channel
+		return &failedTransport{err: err}
+	}
+	return rt.current
+}
+
+// Write sends a message to the current process's stdin.
+func (rt *reconnectTransport) Write(ctx context.Context, data []byte) error {
+	rt.mu.Lock()
+	input, ok := rt.current.(transport2.InputTransport)
+	rt.mu.Unlock()
+
+	if !ok {
+		return types.NewConnectionError("connection error: transport does not accept input", nil)
+	}
+	return input.Write(ctx, data)
+}
+
+// CloseInput closes the current process's stdin.
+func (rt *reconnectTransport) CloseInput() error {
+	rt.mu.Lock()
+	input, ok := rt.current.(transport2.InputTransport)
+	rt.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return input.CloseInput()
+}
+
+// Interrupt interrupts the current CLI process.
+func (rt *reconnectTransport) Interrupt() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if interrupter, ok := rt.current.(transport2.Interrupter); ok {
+		return interrupter.Interrupt()
+	}
+	return fmt.Errorf("transport does not support interrupts")
+}
+
+// Warnings returns the warnings of the current process's transport.
+func (rt *reconnectTransport) Warnings() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if warner, ok := rt.current.(transport2.Warner); ok {
+		return warner.Warnings()
+	}
+	return nil
+}
+
+// Close terminates the current process and stops further restarts.
+func (rt *reconnectTransport) Close() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.closed = true
+	rt.connected = false
+	if rt.cancel != nil {
+		rt.cancel()
+	}
+	return rt.current.Close()
+}
+
+// IsConnected returns true if the current process's transport is connected.
+func (rt *reconnectTransport) IsConnected() bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.connected && rt.current.IsConnected()
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+const (
+	initLine      = `{"type":"system","subtype":"init","session_id":"s1"}`
+	partialLine   = `{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]}}`
+	resultLine    = `{"type":"result","subtype":"success","session_id":"s1"}`
+	crashExitCode = 1
+)
+
+// scriptedResumes returns a resume function yielding the given processes
+// in order and recording the session IDs it was asked to resume.
+func scriptedResumes(processes ...*attemptTransport) (func(string) transport2.Transport, *[]string) {
+	var resumed []string
+	return func(sessionID string) transport2.Transport {
+		t := processes[len(resumed)]
+		resumed = append(resumed, sessionID)
+		return t
+	}, &resumed
+}
+
+func runReconnect(t *testing.T, policy *types.ReconnectPolicy, oneShot bool, first *attemptTransport, resume func(string) transport2.Transport) ([]types.Message, []error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := NewQueryStream(ctx, newReconnectTransport(policy, first, resume, oneShot), parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	return drainStream(t, stream)
+}
+
+func crash() error {
+	return types.NewProcessError("CLI process failed", crashExitCode, "")
+}
+
+func TestReconnectTransportResumesFailedProcess(t *testing.T) {
+	resume, resumed := scriptedResumes(
+		&attemptTransport{lines: []string{initLine, resultLine}},
+	)
+	policy := &types.ReconnectPolicy{
+		MaxReconnects: 2,
+		Backoff:       func(int) time.Duration { return time.Millisecond },
+	}
+
+	first := &attemptTransport{lines: []string{initLine, partialLine, `{"type":"assist`}, err: crash()}
+	msgs, errs := runReconnect(t, policy, true, first, resume)
+
+	// The fragment the crashed process left is reported, but does not
+	// swallow the reconnect event
+	if len(errs) != 1 || !errors.Is(errs[0], types.ErrJSONDecode) {
+		t.Errorf("Expected only the fragment's decode error, got %v", errs)
+	}
+	if len(*resumed) != 1 || (*resumed)[0] != "s1" {
+		t.Fatalf("Expected session s1 to be resumed once, got %v", *resumed)
+	}
+
+	var event *types.SystemMessage
+	for _, msg := range msgs {
+		if sm, ok := msg.(*types.SystemMessage); ok && sm.Subtype == types.SystemSubtypeReconnected {
+			event = sm
+		}
+	}
+	if event == nil {
+		t.Fatalf("Expected a reconnect event, got %#v", msgs)
+	}
+	if event.Data["session_id"] != "s1" || event.Data["reconnect"] != float64(1) || event.Data["error"] == "" {
+		t.Errorf("Unexpected reconnect event data: %v", event.Data)
+	}
+	if _, ok := msgs[len(msgs)-1].(*types.ResultMessage); !ok {
+		t.Errorf("Expected the resumed process's result last, got %#v", msgs[len(msgs)-1])
+	}
+}
+
+func TestReconnectTransportForwardsErrorsWithoutReconnect(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *types.ReconnectPolicy
+		oneShot bool
+		lines   []string
+		err     error
+	}{
+		{
+			name:    "no session ID",
+			policy:  &types.ReconnectPolicy{MaxReconnects: 1},
+			oneShot: true,
+			lines:   []string{partialLine},
+			err:     crash(),
+		},
+		{
+			name:    "query after its result",
+			policy:  &types.ReconnectPolicy{MaxReconnects: 1},
+			oneShot: true,
+			lines:   []string{initLine, resultLine},
+			err:     crash(),
+		},
+		{
+			name:   "not retryable",
+			policy: &types.ReconnectPolicy{MaxReconnects: 1, RetryableClassifier: func(error) bool { return false }},
+			lines:  []string{initLine},
+			err:    crash(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resume, resumed := scriptedResumes()
+			first := &attemptTransport{lines: tt.lines, err: tt.err}
+			_, errs := runReconnect(t, tt.policy, tt.oneShot, first, resume)
+
+			if len(*resumed) != 0 {
+				t.Errorf("Expected no reconnect, got %v", *resumed)
+			}
+			if len(errs) != 1 {
+				t.Errorf("Expected the process error, got %v", errs)
+			}
+		})
+	}
+}
+
+func TestReconnectTransportRespectsMaxReconnects(t *testing.T) {
+	resume, resumed := scriptedResumes(
+		&attemptTransport{lines: []string{initLine}, err: crash()},
+		&attemptTransport{lines: []string{initLine}, err: crash()},
+	)
+	policy := &types.ReconnectPolicy{
+		MaxReconnects: 2,
+		Backoff:       func(int) time.Duration { return time.Millisecond },
+	}
+
+	first := &attemptTransport{lines: []string{initLine}, err: crash()}
+	_, errs := runReconnect(t, policy, false, first, resume)
+
+	if len(*resumed) != 2 {
+		t.Errorf("Expected 2 reconnects, got %d", len(*resumed))
+	}
+	if len(errs) != 1 || types.ErrorKind(errs[0]) != types.KindProcess {
+		t.Errorf("Expected the last process error, got %v", errs)
+	}
+}
+
+func TestResumeOptions(t *testing.T) {
+	options := types.NewOptions().WithContinueConversation().WithForkSession(true)
+	resumed := resumeOptions(options, "s1")
+
+	if resumed.Resume == nil || *resumed.Resume != "s1" {
+		t.Errorf("Expected resume of s1, got %v", resumed.Resume)
+	}
+	if resumed.ContinueConversation || resumed.ForkSession {
+		t.Error("Expected continue and fork to be cleared")
+	}
+	if options.Resume != nil || !options.ContinueConversation {
+		t.Error("Expected the original options to be unchanged")
+	}
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// retryTransport runs a query over a fresh transport per attempt. Errors
+// from an attempt are held back until it either produces output, which
+// commits the query to that attempt, or ends, at which point the retry
+// policy decides whether to start another attempt.
+type retryTransport struct {
+	newTransport func() transport2.Transport
+	policy       *types.RetryPolicy
+
+	// limiter, if set, is waited on before each retry attempt
+	limiter types.RateLimiter
+
+	mu        sync.Mutex
+	current   transport2.Transport
+	closed    bool
+	connected bool
+	cancel    context.CancelFunc
+}
+
+// newRetryTransport creates a transport that retries according to policy.
+func newRetryTransport(policy *types.RetryPolicy, newTransport func() transport2.Transport) *retryTransport {
+	return &retryTransport{
+		newTransport: newTransport,
+		policy:       policy,
+	}
+}
+
+// Connect connects the transport for the first attempt. Connection errors
+// are configuration problems, such as a missing CLI, and are not retried.
+func (rt *retryTransport) Connect(ctx context.Context) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.connected {
+		return nil
+	}
+
+	t := rt.newTransport()
+	if err := t.Connect(ctx); err != nil {
+		return err
+	}
+
+	rt.current = t
+	rt.connected = true
+	return nil
+}
+
+// Stream runs attempts until one produces output, succeeds, or the retry
+// policy gives up.
+func (rt *retryTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, 10)
+	errChan := make(chan error, 10)
+
+	ctx, cancel := context.WithCancel(ctx)
+	rt.mu.Lock()
+	rt.cancel = cancel
+	rt.mu.Unlock()
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+		defer cancel()
+
+		for attempt := 1; ; attempt++ {
+			t := rt.attemptTransport(ctx, attempt)
+			if t == nil {
+				return
+			}
+
+			committed, errs := rt.runAttempt(ctx, t, dataChan, errChan)
+			if committed || len(errs) == 0 {
+				return
+			}
+
+			err := errors.Join(errs...)
+			if ctx.Err() != nil || !rt.policy.ShouldRetry(attempt, err) {
+				for _, e := range errs {
+					select {
+					case errChan <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				return
+			}
+
+			delay := rt.policy.Delay(attempt)
+			if rt.policy.OnRetry != nil {
+				rt.policy.OnRetry(types.RetryEvent{Attempt: attempt + 1, Err: err, Delay: delay})
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+// attemptTransport returns the transport for an attempt, creating and
+// connecting a new one for retries. It returns nil once the transport has
+// been closed.
+func (rt *retryTransport) attemptTransport(ctx context.Context, attempt int) transport2.Transport {
+	if attempt > 1 && rt.limiter != nil {
+		if err := rt.limiter.Wait(ctx); err != nil {
+			return &failedTransport{err: fmt.Errorf("rate limiter: %w", err)}
+		}
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.closed {
+		return nil
+	}
+	if attempt == 1 {
+		return rt.current
+	}
+
+	rt.current.Close()
+	rt.current = rt.newTransport()
+	if err := rt.current.Connect(ctx); err != nil {
+		// Let the attempt report the failure through its error channel
+		return &failedTransport{err: err}
+	}
+	return rt.current
+}
+
+// runAttempt forwards an attempt's output. It returns whether any output
+// was produced and, if not, the errors the attempt reported.
+func (rt *retryTransport) runAttempt(ctx context.Context, t transport2.Transport, dataChan chan<- []byte, errChan chan<- error) (bool, []error) {
+	data, errs := t.Stream(ctx)
+
+	committed := false
+	var held []error
+
+	for data != nil || errs != nil {
+		select {
+		case line, ok := <-data:
+			if !ok {
+				data = nil
+				continue
+			}
+			if !committed {
+				committed = true
+				for _, err := range held {
+					select {
+					case errChan <- err:
+					case <-ctx.Done():
+						return true, nil
+					}
+				}
+				held = nil
+			}
+			select {
+			case dataChan <- line:
+			case <-ctx.Done():
+				return true, nil
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if !committed {
+				held = append(held, err)
+				continue
+			}
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+				return true, nil
+			}
+
+		case <-ctx.Done():
+			return committed, held
+		}
+	}
+
+	return committed, held
+}
+
+// Interrupt interrupts the current attempt's CLI process.
+func (rt *retryTransport) Interrupt() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if interrupter, ok := rt.current.(transport2.Interrupter); ok {
+		return interrupter.Interrupt()
+	}
+	return fmt.Errorf("transport does not support interrupts")
+}
+
+// Warnings returns the warnings of the first attempt's transport.
+func (rt *retryTransport) Warnings() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if warner, ok := rt.current.(transport2.Warner); ok {
+		return warner.Warnings()
+	}
+	return nil
+}
+
+// Close terminates the current attempt and stops further retries.
+func (rt *retryTransport) Close() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.closed = true
+	rt.connected = false
+	if rt.cancel != nil {
+		rt.cancel()
+	}
+	if rt.current != nil {
+		return rt.current.Close()
+	}
+	return nil
+}
+
+// IsConnected returns true if the current attempt's transport is connected.
+func (rt *retryTransport) IsConnected() bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.connected && rt.current != nil && rt.current.IsConnected()
+}
+
+// failedTransport reports a connection error as the outcome of an attempt.
+type failedTransport struct {
+	err error
+}
+
+func (ft *failedTransport) Connect(ctx context.Context) error { return ft.err }
+
+func (ft *failedTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte)
+	errChan := make(chan error, 1)
+	errChan <- ft.err
+	close(dataChan)
+	close(errChan)
+	return dataChan, errChan
+}
+
+func (ft *failedTransport) Close() error { return nil }
+
+func (ft *failedTransport) IsConnected() bool { return false }
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// attemptTransport emits its lines, then its error.
+type attemptTransport struct {
+	lines     []string
+	err       error
+	connected bool
+}
+
+func (at *attemptTransport) Connect(ctx context.Context) error {
+	at.connected = true
+	return nil
+}
+
+func (at *attemptTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, len(at.lines))
+	errChan := make(chan error, 1)
+	for _, line := range at.lines {
+		dataChan <- []byte(line)
+	}
+	if at.err != nil {
+		errChan <- at.err
+	}
+	close(dataChan)
+	close(errChan)
+	return dataChan, errChan
+}
+
+func (at *attemptTransport) Close() error {
+	at.connected = false
+	return nil
+}
+
+func (at *attemptTransport) IsConnected() bool {
+	return at.connected
+}
+
+// scriptedAttempts returns a transport factory yielding the given attempts in order.
+func scriptedAttempts(attempts ...*attemptTransport) (func() transport2.Transport, *int) {
+	count := 0
+	return func() transport2.Transport {
+		t := attempts[count]
+		count++
+		return t
+	}, &count
+}
+
+func drainStream(t *testing.T, stream *QueryStream) ([]types.Message, []error) {
+	t.Helper()
+	var msgs []types.Message
+	var errs []error
+	messages, errors := stream.Messages(), stream.Errors()
+	timeout := time.After(2 * time.Second)
+	for messages != nil || errors != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			msgs = append(msgs, msg)
+		case err, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			errs = append(errs, err)
+		case <-timeout:
+			t.Fatal("Timed out draining stream")
+		}
+	}
+	return msgs, errs
+}
+
+func TestRetryTransportRetriesFailuresBeforeOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory, count := scriptedAttempts(
+		&attemptTransport{err: types.NewProcessError("CLI process failed", 1, "")},
+		&attemptTransport{lines: []string{`{"type":"result","subtype":"success","session_id":"s1"}`}},
+	)
+
+	var events []types.RetryEvent
+	policy := &types.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+		OnRetry:     func(e types.RetryEvent) { events = append(events, e) },
+	}
+
+	stream := NewQueryStream(ctx, newRetryTransport(policy, factory), parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	msgs, errs := drainStream(t, stream)
+	if len(errs) != 0 {
+		t.Errorf("Expected failed attempt's errors to be suppressed, got %v", errs)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(msgs))
+	}
+	if *count != 2 {
+		t.Errorf("Expected 2 attempts, got %d", *count)
+	}
+	if len(events) != 1 || events[0].Attempt != 2 || events[0].Delay != time.Millisecond {
+		t.Errorf("Unexpected retry events: %+v", events)
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory, count := scriptedAttempts(
+		&attemptTransport{err: types.NewConnectionError("first", nil)},
+		&attemptTransport{err: types.NewConnectionError("second", nil)},
+	)
+	policy := &types.RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return 0 },
+	}
+
+	stream := NewQueryStream(ctx, newRetryTransport(policy, factory), parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	_, errs := drainStream(t, stream)
+	if len(errs) != 1 || errs[0].Error() != "second" {
+		t.Errorf("Expected last attempt's error, got %v", errs)
+	}
+	if *count != 2 {
+		t.Errorf("Expected 2 attempts, got %d", *count)
+	}
+}
+
+func TestRetryTransportClassifier(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory, count := scriptedAttempts(
+		&attemptTransport{err: errors.New("invalid api key")},
+	)
+	policy := &types.RetryPolicy{
+		MaxAttempts: 3,
+		RetryableClassifier: func(err error) bool {
+			return !strings.Contains(err.Error(), "api key")
+		},
+	}
+
+	stream := NewQueryStream(ctx, newRetryTransport(policy, factory), parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	_, errs := drainStream(t, stream)
+	if len(errs) != 1 {
+		t.Errorf("Expected non-retryable error to be reported, got %v", errs)
+	}
+	if *count != 1 {
+		t.Errorf("Expected a single attempt, got %d", *count)
+	}
+}
+
+func TestRetryTransportNoRetryAfterOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory, count := scriptedAttempts(
+		&attemptTransport{
+			lines: []string{`{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]}}`},
+			err:   types.NewProcessError("CLI process failed", 1, ""),
+		},
+	)
+	policy := &types.RetryPolicy{MaxAttempts: 3}
+
+	stream := NewQueryStream(ctx, newRetryTransport(policy, factory), parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	msgs, errs := drainStream(t, stream)
+	if len(msgs) != 1 || len(errs) != 1 {
+		t.Errorf("Expected partial output and its error, got %d messages and %v", len(msgs), errs)
+	}
+	if *count != 1 {
+		t.Errorf("Expected no retry after output, got %d attempts", *count)
+	}
+}
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/models"
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	transport2 "github.com/jrossi/claude-code-sdk-golang/transport"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Session is an interactive, multi-turn conversation with Claude Code.
+// It keeps a single CLI process alive and writes each prompt to its stdin
+// using the stream-json input format.
+type Session struct {
+	// stream handles output parsing and the control protocol
+	stream *QueryStream
+
+	// input writes prompts and control requests to the CLI
+	input transport2.InputTransport
+
+	// limiter, if set, is waited on before each sent prompt
+	limiter types.RateLimiter
+
+	// intercept, if set, runs each sent prompt through the interceptors of
+	// the client that started the session, with its options
+	intercept func(ctx context.Context, req *Request, handler Handler) (*QueryStream, error)
+	options   *types.Options
+}
+
+// NewSession creates a new session with the given input-capable transport and parser.
+// The transport must be configured for streaming input.
+func NewSession(ctx context.Context, transport transport2.InputTransport, parser *parser.Parser) *Session {
+	return &Session{
+		stream: NewQueryStream(ctx, transport, parser),
+		input:  transport,
+	}
+}
+
+// Start launches the CLI process and begins streaming its output.
+func (s *Session) Start() error {
+	if err := s.stream.Start(); err != nil {
+		return err
+	}
+	s.stream.enableControl()
+
+	// No output is expected until the first prompt is sent
+	if s.stream.timeouts != nil {
+		s.stream.timeouts.setAwaiting(false)
+	}
+	return nil
+}
+
+// Send writes a user prompt to the CLI as a new conversation turn.
+// Responses arrive on the Receive channel.
+func (s *Session) Send(ctx context.Context, prompt string) error {
+	return s.send(ctx, prompt)
+}
+
+// SendPrompt writes a multi-part prompt, such as one with images, to the
+// CLI as a new conversation turn.
+func (s *Session) SendPrompt(ctx context.Context, prompt *types.Prompt) error {
+	content, err := prompt.Content()
+	if err != nil {
+		return fmt.Errorf("invalid prompt: %w", err)
+	}
+	return s.send(ctx, content)
+}
+
+// Compact asks the CLI to compact the conversation now, summarizing
+// earlier turns to free up the context window. It is sent as a /compact
+// turn: a system message with subtype types.SystemSubtypeCompactBoundary
+// reports the compaction, and the turn ends with a ResultMessage as usual.
+func (s *Session) Compact(ctx context.Context) error {
+	return s.send(ctx, "/compact")
+}
+
+// send writes a user turn whose content is a string or content blocks,
+// through the client's interceptors.
+func (s *Session) send(ctx context.Context, prompt any) error {
+	if s.intercept == nil {
+		return s.write(ctx, prompt)
+	}
+
+	req := &Request{Kind: RequestSend, Options: s.options}
+	switch p := prompt.(type) {
+	case string:
+		req.Prompt = p
+	case []map[string]any:
+		req.Content = p
+	}
+	_, err := s.intercept(ctx, req, func(ctx context.Context, req *Request) (*QueryStream, error) {
+		var prompt any = req.Prompt
+		if req.Content != nil {
+			prompt = req.Content
+		}
+		if err := s.write(ctx, prompt); err != nil {
+			return nil, err
+		}
+		return s.stream, nil
+	})
+	return err
+}
+
+// write writes a user turn whose content is a string or content blocks.
+func (s *Session) write(ctx context.Context, prompt any) error {
+	if s.stream.IsClosed() {
+		return fmt.Errorf("session closed")
+	}
+
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limiter: %w", err)
+		}
+	}
+
+	data, err := transport2.EncodeUserMessage(prompt, "")
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt: %w", err)
+	}
+
+	if s.stream.timeouts != nil {
+		s.stream.timeouts.setAwaiting(true)
+	}
+	return s.input.Write(ctx, data)
+}
+
+// Init returns the session information from the CLI's init message, such
+// as the available tools, slash commands, and plugins, or nil if it has not
+// arrived yet.
+func (s *Session) Init() *types.InitInfo {
+	return s.stream.Init()
+}
+
+// LastActivity returns when the CLI last produced output, or when the
+// session was created if it has produced none.
+func (s *Session) LastActivity() time.Time {
+	return s.stream.LastActivity()
+}
+
+// Changes returns the files changed so far by the session's tool uses,
+// across all turns.
+func (s *Session) Changes() []FileChange {
+	return s.stream.Changes()
+}
+
+// Receive returns a channel that receives parsed messages for all turns.
+// The channel will be closed when the session ends.
+func (s *Session) Receive() <-chan types.Message {
+	return s.stream.Messages()
+}
+
+// Errors returns a channel that receives errors during the session.
+// The channel will be closed when the session ends.
+func (s *Session) Errors() <-chan error {
+	return s.stream.Errors()
+}
+
+// Interrupt asks the CLI to stop the current turn.
+// The session remains usable for further prompts.
+func (s *Session) Interrupt(ctx context.Context) error {
+	return s.stream.Interrupt(ctx)
+}
+
+// SetPermissionMode changes the permission mode for the rest of the
+// session, starting with the next tool call. It waits for the CLI to
+// acknowledge the change.
+func (s *Session) SetPermissionMode(ctx context.Context, mode types.PermissionMode) error {
+	if mode == "" {
+		return fmt.Errorf("permission mode is required")
+	}
+	return s.control(ctx, map[string]any{"subtype": "set_permission_mode", "mode": string(mode)})
+}
+
+// SetModel switches the model for the rest of the session, starting with
+// the next request to the API. An empty model restores the CLI's default.
+// It waits for the CLI to acknowledge the change.
+func (s *Session) SetModel(ctx context.Context, model string) error {
+	request := map[string]any{"subtype": "set_model", "model": nil}
+	if model != "" {
+		request["model"] = models.Normalize(model)
+	}
+	return s.control(ctx, request)
+}
+
+// McpServerStatus asks the CLI for the current status of each configured
+// MCP server, so an application can notice that a server failed to start
+// or disconnected and react. The status at startup is also available
+// without a request in Init().McpServers.
+func (s *Session) McpServerStatus(ctx context.Context) ([]types.McpServerStatus, error) {
+	if s.stream.IsClosed() {
+		return nil, fmt.Errorf("session closed")
+	}
+	response, err := s.stream.sendControlRequest(ctx, map[string]any{"subtype": "mcp_status"})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MCP status: %w", err)
+	}
+	var status struct {
+		McpServers []types.McpServerStatus `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("invalid MCP status: %w", err)
+	}
+	return status.McpServers, nil
+}
+
+// control sends a control request that takes effect mid-conversation.
+func (s *Session) control(ctx context.Context, request map[string]any) error {
+	if s.stream.IsClosed() {
+		return fmt.Errorf("session closed")
+	}
+	_, err := s.stream.sendControlRequest(ctx, request)
+	return err
+}
+
+// Close ends the session, closing stdin and terminating the CLI process.
+// It's safe to call Close multiple times.
+func (s *Session) Close() error {
+	s.input.CloseInput()
+	return s.stream.Close()
+}
+
+// IsClosed returns true if the session has been closed.
+func (s *Session) IsClosed() bool {
+	return s.stream.IsClosed()
+}
+
+// StartSession launches an interactive session with Claude Code.
+func (c *Client) StartSession(ctx context.Context, options *types.Options) (*Session, error) {
+	return c.StartSessionWithCLIPath(ctx, options, c.cliPath)
+}
+
+// StartSessionWithCLIPath launches an interactive session using a specific CLI path.
+func (c *Client) StartSessionWithCLIPath(ctx context.Context, options *types.Options, cliPath string) (*Session, error) {
+	var session *Session
+	stream, err := c.intercept(ctx, &Request{Kind: RequestSession, Options: options, CLIPath: cliPath}, func(ctx context.Context, req *Request) (*QueryStream, error) {
+		s, err := c.startSession(ctx, req.Options, req.CLIPath)
+		if err != nil {
+			return nil, err
+		}
+		session = s
+		return s.stream, nil
+	})
+
+	switch {
+	case err != nil:
+		if session != nil {
+			session.Close()
+		}
+		return nil, err
+	case session == nil || stream != session.stream:
+		if session != nil {
+			session.Close()
+		}
+		return nil, errSessionNotStarted
+	}
+
+	session.intercept = c.intercept
+	return session, nil
+}
+
+// startSession launches a session. It is the innermost handler of
+// sessions.
+func (c *Client) startSession(ctx context.Context, options *types.Options, cliPath string) (*Session, error) {
+	// Set default options if none provided
+	if options == nil {
+		options = types.NewOptions()
+	}
+	if err := options.Validate(); err != nil {
+		reportFailure(options, err)
+		return nil, err
+	}
+
+	config := &transport2.Config{
+		Options:        options,
+		CLIPath:        cliPath,
+		StreamingInput: true,
+	}
+
+	var t transport2.InputTransport = transport2.NewSubprocessTransport(config)
+	if reconnects(options) {
+		t = newReconnectTransport(options.ReconnectPolicy, t, func(sessionID string) transport2.Transport {
+			resumed := *config
+			resumed.Options = resumeOptions(options, sessionID)
+			return transport2.NewSubprocessTransport(&resumed)
+		}, false)
+	}
+
+	session := NewSession(ctx, t, c.parserFor(options))
+	session.options = options
+	session.stream.applyOptions(options)
+	session.stream.usageTracker = c.usageTracker
+	if options.RateLimitTurns {
+		session.limiter = options.RateLimiter
+	}
+
+	if err := c.acquireSlot(ctx, session.stream); err != nil {
+		session.stream.complete(err)
+		return nil, err
+	}
+	if err := waitRateLimit(ctx, options); err != nil {
+		session.stream.releaseSlot()
+		session.stream.complete(err)
+		return nil, err
+	}
+
+	if err := session.Start(); err != nil {
+		session.stream.releaseSlot()
+		session.stream.complete(err)
+		return nil, err
+	}
+
+	if needsControlProtocol(options) {
+		if err := session.stream.initialize(options); err != nil {
+			session.stream.complete(err)
+			session.Close()
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
@@ -0,0 +1,513 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// mockInputTransport simulates a CLI in stream-json input mode. Each user
+// message written to it produces an assistant reply and a result, and
+// control requests are answered by the respond function.
+type mockInputTransport struct {
+	data      chan []byte
+	errs      chan error
+	connected bool
+
+	mu      sync.Mutex
+	written []map[string]any
+	closed  bool
+
+	// respond builds the control_response payload for a control request.
+	// If nil, requests succeed with an empty response.
+	respond func(request map[string]any) map[string]any
+}
+
+func newMockInputTransport() *mockInputTransport {
+	return &mockInputTransport{
+		data: make(chan []byte, 10),
+		errs: make(chan error, 1),
+	}
+}
+
+func (mt *mockInputTransport) Connect(ctx context.Context) error {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.connected = true
+	return nil
+}
+
+func (mt *mockInputTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	return mt.data, mt.errs
+}
+
+func (mt *mockInputTransport) Close() error {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if !mt.closed {
+		mt.closed = true
+		close(mt.data)
+		close(mt.errs)
+	}
+	mt.connected = false
+	return nil
+}
+
+func (mt *mockInputTransport) IsConnected() bool {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	return mt.connected
+}
+
+func (mt *mockInputTransport) Write(ctx context.Context, data []byte) error {
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if mt.closed {
+		return errors.New("transport closed")
+	}
+	mt.written = append(mt.written, msg)
+
+	switch msg["type"] {
+	case "user":
+		// Multi-part prompts are echoed by their number of blocks
+		content, ok := msg["message"].(map[string]any)["content"].(string)
+		if !ok {
+			parts, _ := msg["message"].(map[string]any)["content"].([]any)
+			content = fmt.Sprintf("%d parts", len(parts))
+		}
+		mt.data <- []byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"echo: ` + content + `"}]}}`)
+		mt.data <- []byte(`{"type":"result","subtype":"success","session_id":"s1"}`)
+	case "control_request":
+		request := msg["request"].(map[string]any)
+		resp := map[string]any{
+			"subtype":    "success",
+			"request_id": msg["request_id"],
+		}
+		if mt.respond != nil {
+			for k, v := range mt.respond(request) {
+				resp[k] = v
+			}
+		}
+		out, _ := json.Marshal(map[string]any{"type": "control_response", "response": resp})
+		mt.data <- out
+	}
+	return nil
+}
+
+func (mt *mockInputTransport) CloseInput() error {
+	return nil
+}
+
+func (mt *mockInputTransport) writtenMessages() []map[string]any {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	return append([]map[string]any(nil), mt.written...)
+}
+
+func receiveUntilResult(t *testing.T, session *Session) []types.Message {
+	t.Helper()
+	var msgs []types.Message
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg, ok := <-session.Receive():
+			if !ok {
+				t.Fatal("Session ended before result")
+			}
+			msgs = append(msgs, msg)
+			if _, ok := msg.(*types.ResultMessage); ok {
+				return msgs
+			}
+		case err := <-session.Errors():
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for result")
+		}
+	}
+}
+
+func TestSessionMultipleTurns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	for _, prompt := range []string{"first", "second"} {
+		if err := session.Send(ctx, prompt); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+
+		msgs := receiveUntilResult(t, session)
+		assistant, ok := msgs[0].(*types.AssistantMessage)
+		if !ok {
+			t.Fatalf("Expected AssistantMessage, got %T", msgs[0])
+		}
+		text := assistant.Content[0].(*types.TextBlock).Text
+		if text != "echo: "+prompt {
+			t.Errorf("Expected echo of %q, got %q", prompt, text)
+		}
+	}
+
+	written := mt.writtenMessages()
+	if len(written) != 2 {
+		t.Fatalf("Expected 2 written messages, got %d", len(written))
+	}
+	if written[0]["type"] != "user" || written[0]["session_id"] != "default" {
+		t.Errorf("Unexpected user message envelope: %v", written[0])
+	}
+}
+
+func TestSessionSendPrompt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	prompt := types.NewPrompt("Describe this").ImageURL("https://example.com/a.png")
+	if err := session.SendPrompt(ctx, prompt); err != nil {
+		t.Fatalf("SendPrompt failed: %v", err)
+	}
+
+	written := mt.writtenMessages()
+	if len(written) != 1 {
+		t.Fatalf("Expected 1 written message, got %d", len(written))
+	}
+	content, ok := written[0]["message"].(map[string]any)["content"].([]any)
+	if !ok || len(content) != 2 || content[1].(map[string]any)["type"] != "image" {
+		t.Errorf("Expected text and image blocks, got %v", written[0]["message"])
+	}
+
+	if err := session.SendPrompt(ctx, types.NewPrompt()); err == nil {
+		t.Error("Expected error for empty prompt")
+	}
+}
+
+func TestSessionCompact(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.Compact(ctx); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	written := mt.writtenMessages()
+	if len(written) != 1 || written[0]["message"].(map[string]any)["content"] != "/compact" {
+		t.Fatalf("Expected a /compact turn, got %v", written)
+	}
+
+	// Skip the mock's echo of the turn
+	<-session.Receive()
+	<-session.Receive()
+
+	mt.data <- []byte(`{"type":"system","subtype":"compact_boundary","compact_metadata":{"trigger":"manual","pre_tokens":150000}}`)
+	msg := <-session.Receive()
+	system, ok := msg.(*types.SystemMessage)
+	if !ok {
+		t.Fatalf("Expected system message, got %T", msg)
+	}
+	boundary, ok := system.CompactBoundary()
+	if !ok || *boundary != (types.CompactBoundary{Trigger: types.CompactTriggerManual, PreTokens: 150000}) {
+		t.Errorf("Unexpected compact boundary: %+v", boundary)
+	}
+}
+
+func TestSessionInterrupt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.Interrupt(ctx); err != nil {
+		t.Fatalf("Interrupt failed: %v", err)
+	}
+
+	written := mt.writtenMessages()
+	if len(written) != 1 || written[0]["type"] != "control_request" {
+		t.Fatalf("Expected a single control request, got %v", written)
+	}
+	request := written[0]["request"].(map[string]any)
+	if request["subtype"] != "interrupt" {
+		t.Errorf("Expected interrupt subtype, got %v", request["subtype"])
+	}
+	if id, _ := written[0]["request_id"].(string); !strings.HasPrefix(id, "req_1_") {
+		t.Errorf("Unexpected request ID: %v", written[0]["request_id"])
+	}
+}
+
+func TestSessionInterruptError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	mt.respond = func(request map[string]any) map[string]any {
+		return map[string]any{"subtype": "error", "error": "nothing to interrupt"}
+	}
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	err := session.Interrupt(ctx)
+	if err == nil || !strings.Contains(err.Error(), "nothing to interrupt") {
+		t.Errorf("Expected control error, got %v", err)
+	}
+}
+
+func TestSessionDynamicControls(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.SetPermissionMode(ctx, types.PermissionModePlan); err != nil {
+		t.Fatalf("SetPermissionMode failed: %v", err)
+	}
+	if err := session.SetModel(ctx, "opus"); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if err := session.SetModel(ctx, ""); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if err := session.SetPermissionMode(ctx, ""); err == nil {
+		t.Error("Expected error for empty permission mode")
+	}
+
+	want := []map[string]any{
+		{"subtype": "set_permission_mode", "mode": "plan"},
+		{"subtype": "set_model", "model": "opus"},
+		{"subtype": "set_model", "model": nil},
+	}
+	written := mt.writtenMessages()
+	if len(written) != len(want) {
+		t.Fatalf("Expected %d control requests, got %v", len(want), written)
+	}
+	for i, msg := range written {
+		if msg["type"] != "control_request" || !reflect.DeepEqual(msg["request"], want[i]) {
+			t.Errorf("Request %d = %v, want %v", i, msg["request"], want[i])
+		}
+	}
+}
+
+func TestSessionDynamicControlErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	mt.respond = func(request map[string]any) map[string]any {
+		return map[string]any{"subtype": "error", "error": "unknown model"}
+	}
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if err := session.SetModel(ctx, "nonexistent"); err == nil || !strings.Contains(err.Error(), "unknown model") {
+		t.Errorf("Expected control error, got %v", err)
+	}
+
+	session.Close()
+	if err := session.SetModel(ctx, "opus"); err == nil || !strings.Contains(err.Error(), "session closed") {
+		t.Errorf("Expected session closed error, got %v", err)
+	}
+}
+
+func TestSessionMcpServerStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	mt.respond = func(request map[string]any) map[string]any {
+		if request["subtype"] != "mcp_status" {
+			return map[string]any{"subtype": "error", "error": "unexpected request"}
+		}
+		return map[string]any{"response": map[string]any{"mcpServers": []any{
+			map[string]any{"name": "github", "status": "connected", "serverInfo": map[string]any{"name": "github-mcp", "version": "1.2.0"}},
+			map[string]any{"name": "postgres", "status": "failed"},
+		}}}
+	}
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	servers, err := session.McpServerStatus(ctx)
+	if err != nil {
+		t.Fatalf("McpServerStatus failed: %v", err)
+	}
+	want := []types.McpServerStatus{
+		{Name: "github", Status: types.McpStatusConnected, ServerInfo: &types.McpServerInfo{Name: "github-mcp", Version: "1.2.0"}},
+		{Name: "postgres", Status: types.McpStatusFailed},
+	}
+	if !reflect.DeepEqual(servers, want) {
+		t.Errorf("McpServerStatus = %+v, want %+v", servers, want)
+	}
+}
+
+func TestSessionSendAfterClose(t *testing.T) {
+	ctx := context.Background()
+
+	session := NewSession(ctx, newMockInputTransport(), parser.NewParser(0))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	session.Close()
+
+	if !session.IsClosed() {
+		t.Error("Expected session to be closed")
+	}
+	if err := session.Send(ctx, "hello"); err == nil {
+		t.Error("Expected error sending on closed session")
+	}
+	if err := session.Close(); err != nil {
+		t.Errorf("Second Close should succeed, got %v", err)
+	}
+}
+
+func TestControlMessagesFilteredFromStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	mt := &mockMessageTransport{
+		messages: []string{
+			`{"type":"control_response","response":{"subtype":"success","request_id":"req_9"}}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"mentions \"control_request\""}]}}`,
+		},
+	}
+	stream := NewQueryStream(ctx, mt, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	var received []types.Message
+	for msg := range stream.Messages() {
+		received = append(received, msg)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 message after filtering, got %d", len(received))
+	}
+	if _, ok := received[0].(*types.AssistantMessage); !ok {
+		t.Errorf("Expected AssistantMessage, got %T", received[0])
+	}
+}
+
+// interruptTransport records signal-based interrupts.
+type interruptTransport struct {
+	*mockMessageTransport
+	interrupts int
+}
+
+func (it *interruptTransport) Interrupt() error {
+	it.interrupts++
+	return nil
+}
+
+func TestQueryStreamInterruptSignal(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	it := &interruptTransport{mockMessageTransport: &mockMessageTransport{}}
+	stream := NewQueryStream(ctx, it, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	if err := stream.Interrupt(ctx); err != nil {
+		t.Fatalf("Interrupt failed: %v", err)
+	}
+	if it.interrupts != 1 {
+		t.Errorf("Expected transport to be interrupted once, got %d", it.interrupts)
+	}
+}
+
+func TestQueryStreamInterruptUnsupported(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := NewQueryStream(ctx, &mockMessageTransport{}, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	if err := stream.Interrupt(ctx); err == nil {
+		t.Error("Expected error interrupting a transport without interrupt support")
+	}
+}
+
+func TestQueryStreamInterruptControlled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	stream := NewQueryStream(ctx, mt, parser.NewParser(0))
+	if err := stream.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer stream.Close()
+
+	options := types.NewOptions().AddHook(types.HookEventStop, "",
+		func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+			return types.HookOutput{}, nil
+		})
+	if err := stream.startControlledQuery("hello", options); err != nil {
+		t.Fatalf("startControlledQuery failed: %v", err)
+	}
+
+	if err := stream.Interrupt(ctx); err != nil {
+		t.Fatalf("Interrupt failed: %v", err)
+	}
+
+	written := mt.writtenMessages()
+	last := written[len(written)-1]
+	if last["type"] != "control_request" || last["request"].(map[string]any)["subtype"] != "interrupt" {
+		t.Errorf("Expected interrupt control request, got %v", last)
+	}
+}
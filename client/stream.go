@@ -2,10 +2,13 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/jrossi/claude-code-sdk-golang/parser"
 	"github.com/jrossi/claude-code-sdk-golang/transport"
 	"github.com/jrossi/claude-code-sdk-golang/types"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // QueryStream provides a streaming interface for receiving messages from Claude Code.
@@ -21,6 +24,59 @@ type QueryStream struct {
 	messages chan types.Message
 	errors   chan error
 
+	// internalErrors carries errors raised by the stream itself, such as
+	// control protocol failures, into the errors channel
+	internalErrors chan error
+
+	// Control protocol state for streaming input mode
+	control      controlState
+	controlMutex sync.Mutex
+
+	// hookCallbacks maps callback IDs registered with the CLI to Go hooks
+	hookCallbacks map[string]types.HookCallback
+
+	// closeInputOnResult closes stdin once a one-shot query's result arrives
+	closeInputOnResult bool
+
+	// rawMessageHandler receives each line from the transport before routing
+	rawMessageHandler func(json.RawMessage)
+
+	// warnings holds the options' warnings, delivered when the stream starts
+	warnings []string
+
+	// usageTracker records result messages, if set
+	usageTracker *UsageTracker
+
+	// budget stops the stream once Options.MaxCostUSD is exceeded, if set
+	budget *budgetGuard
+
+	// timeouts stops the stream when a query or idle timeout expires, if set
+	timeouts *timeoutWatch
+
+	// lastActivity is when the CLI last produced output, in Unix
+	// nanoseconds, or when the stream was created
+	lastActivity atomic.Int64
+
+	// heartbeat is the interval between heartbeat messages, or zero
+	heartbeat time.Duration
+
+	// initInfo holds the session information from the CLI's init message
+	initInfo atomic.Pointer[types.InitInfo]
+
+	// changes records the files changed by the stream's tool uses
+	changes *ChangeTracker
+
+	// completion reports how the stream ended to Options.CompletionCallback,
+	// if set
+	completion *completion
+
+	// release frees the stream's pool slot, if it holds one
+	release func()
+
+	// messagesDone is closed once the messages channel has been closed, so
+	// errors raised while forwarding messages are still delivered
+	messagesDone chan struct{}
+
 	// Lifecycle management
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -33,13 +89,39 @@ func NewQueryStream(ctx context.Context, transport transport.Transport, parser *
 	// Create a cancellable context for this stream
 	streamCtx, cancel := context.WithCancel(ctx)
 
-	return &QueryStream{
+	qs := &QueryStream{
 		transport: transport,
 		parser:    parser,
 		messages:  make(chan types.Message, 50), // Buffered for performance
 		errors:    make(chan error, 20),         // Buffered for error reporting
 		ctx:       streamCtx,
 		cancel:    cancel,
+
+		internalErrors: make(chan error, 10),
+		messagesDone:   make(chan struct{}),
+		changes:        NewChangeTracker(),
+		control: controlState{
+			pending: make(map[string]chan controlResponse),
+			done:    make(chan struct{}),
+		},
+	}
+	qs.noteActivity()
+	return qs
+}
+
+// applyOptions configures the stream behavior controlled by query options.
+func (qs *QueryStream) applyOptions(options *types.Options) {
+	qs.rawMessageHandler = options.RawMessageHandler
+	qs.warnings = options.Warnings()
+	if options.MaxCostUSD != nil {
+		qs.budget = newBudgetGuard(*options.MaxCostUSD)
+	}
+	qs.timeouts = newTimeoutWatch(options)
+	if options.HeartbeatInterval != nil {
+		qs.heartbeat = *options.HeartbeatInterval
+	}
+	if options.CompletionCallback != nil {
+		qs.completion = newCompletion(options.CompletionCallback)
 	}
 }
 
@@ -50,19 +132,72 @@ func (qs *QueryStream) Start() error {
 		return err
 	}
 
+	// Deliver option and connection warnings ahead of the CLI's output
+	warnings := qs.warnings
+	if warner, ok := qs.transport.(transport.Warner); ok {
+		warnings = append(warnings[:len(warnings):len(warnings)], warner.Warnings()...)
+	}
+	for _, warning := range warnings {
+		qs.messages <- &types.SystemMessage{
+			Subtype: types.SystemSubtypeWarning,
+			Data:    map[string]any{"message": warning},
+		}
+	}
+
 	// Start streaming from transport
 	rawData, transportErrors := qs.transport.Stream(qs.ctx)
 
-	// Start parsing the raw data
-	parsedMessages, parseErrors := qs.parser.ParseMessages(qs.ctx, rawData)
+	// Separate control protocol traffic from regular messages, then parse
+	parsedMessages, parseErrors := qs.parser.ParseMessages(qs.ctx, qs.routeControlMessages(rawData))
 
 	// Start goroutines to merge the streams
 	go qs.mergeMessages(parsedMessages)
 	go qs.mergeErrors(transportErrors, parseErrors)
 
+	if qs.timeouts != nil {
+		go qs.watchTimeouts(qs.timeouts)
+	}
+
 	return nil
 }
 
+// Init returns the session information from the CLI's init message, or
+// nil if it has not arrived yet.
+func (qs *QueryStream) Init() *types.InitInfo {
+	return qs.initInfo.Load()
+}
+
+// LastActivity returns when the CLI last produced output, or when the
+// stream was created if it has produced none. Control protocol traffic
+// counts as output.
+func (qs *QueryStream) LastActivity() time.Time {
+	return time.Unix(0, qs.lastActivity.Load())
+}
+
+// noteActivity records that the CLI produced output.
+func (qs *QueryStream) noteActivity() {
+	qs.lastActivity.Store(time.Now().UnixNano())
+}
+
+// heartbeatMessage reports the stream's last activity as of now.
+func (qs *QueryStream) heartbeatMessage(now time.Time) *types.SystemMessage {
+	last := qs.LastActivity()
+	return &types.SystemMessage{
+		Subtype: types.SystemSubtypeHeartbeat,
+		Data: map[string]any{
+			"last_activity": last.Format(time.RFC3339Nano),
+			"idle_ms":       now.Sub(last).Milliseconds(),
+		},
+	}
+}
+
+// Changes returns the files changed so far by the stream's Write, Edit,
+// MultiEdit, and NotebookEdit tool uses, and simple rm commands, in the
+// order they were first changed.
+func (qs *QueryStream) Changes() []FileChange {
+	return qs.changes.Changes()
+}
+
 // Messages returns a channel that receives parsed messages from Claude.
 // The channel will be closed when the stream ends.
 func (qs *QueryStream) Messages() <-chan types.Message {
@@ -101,6 +236,40 @@ func (qs *QueryStream) Close() error {
 	return nil
 }
 
+// abort reports err and shuts down the transport. The stream then ends as
+// it would if the CLI had exited.
+func (qs *QueryStream) abort(err error) {
+	qs.reportError(err)
+	qs.transport.Close()
+}
+
+// complete reports to the completion callback, if set, that the stream
+// has ended. If the stream reported neither a result nor an error, err is
+// reported, or the context's error if err is nil.
+func (qs *QueryStream) complete(err error) {
+	if qs.completion == nil {
+		return
+	}
+	if err == nil {
+		err = qs.ctx.Err()
+	}
+	qs.completion.finish(err)
+}
+
+// noteError records an error about to be delivered on the errors channel.
+func (qs *QueryStream) noteError(err error) {
+	if qs.completion != nil {
+		qs.completion.observeError(err)
+	}
+}
+
+// releaseSlot frees the stream's pool slot, if it holds one.
+func (qs *QueryStream) releaseSlot() {
+	if qs.release != nil {
+		qs.release()
+	}
+}
+
 // IsClosed returns true if the stream has been closed.
 func (qs *QueryStream) IsClosed() bool {
 	qs.closeMutex.Lock()
@@ -113,24 +282,75 @@ func (qs *QueryStream) mergeMessages(parsedMessages <-chan types.Message) {
 	defer func() {
 		// When parsing is done, close messages channel
 		close(qs.messages)
+		close(qs.messagesDone)
 	}()
 
+	// overBudget is set once the cost limit is exceeded; remaining messages
+	// are drained without being forwarded while the CLI shuts down
+	overBudget := false
+
+	var heartbeats <-chan time.Time
+	if qs.heartbeat > 0 {
+		ticker := time.NewTicker(qs.heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
 	for {
 		select {
 		case <-qs.ctx.Done():
 			return
+		case now := <-heartbeats:
+			select {
+			case qs.messages <- qs.heartbeatMessage(now):
+			case <-qs.ctx.Done():
+				return
+			}
 		case msg, ok := <-parsedMessages:
 			if !ok {
 				// Parsed messages channel closed
 				return
 			}
 
+			qs.closeInputIfDone(msg)
+
+			if system, ok := msg.(*types.SystemMessage); ok {
+				if info, ok := system.Init(); ok {
+					qs.initInfo.Store(info)
+				}
+			}
+
+			qs.changes.Observe(msg)
+			if qs.completion != nil {
+				qs.completion.observe(msg)
+			}
+
+			if result, ok := msg.(*types.ResultMessage); ok {
+				if qs.usageTracker != nil {
+					qs.usageTracker.Record(result)
+				}
+				if qs.timeouts != nil {
+					qs.timeouts.setAwaiting(false)
+				}
+			}
+
+			if overBudget {
+				continue
+			}
+
 			// Forward the message (non-blocking)
 			select {
 			case qs.messages <- msg:
 			case <-qs.ctx.Done():
 				return
 			}
+
+			if qs.budget != nil {
+				if err := qs.budget.observe(msg); err != nil {
+					overBudget = true
+					qs.abort(err)
+				}
+			}
 		}
 	}
 }
@@ -140,13 +360,16 @@ func (qs *QueryStream) mergeErrors(transportErrors, parseErrors <-chan error) {
 	defer func() {
 		// When both error sources are done, close errors channel
 		close(qs.errors)
+		qs.releaseSlot()
+		qs.complete(nil)
 	}()
 
 	// Track if channels are still open
 	transportOpen := true
 	parseOpen := true
+	messagesDone := qs.messagesDone
 
-	for transportOpen || parseOpen {
+	for transportOpen || parseOpen || messagesDone != nil {
 		select {
 		case <-qs.ctx.Done():
 			return
@@ -156,6 +379,7 @@ func (qs *QueryStream) mergeErrors(transportErrors, parseErrors <-chan error) {
 				transportOpen = false
 				break
 			}
+			qs.noteError(err)
 			// Forward transport error (non-blocking)
 			select {
 			case qs.errors <- err:
@@ -168,12 +392,39 @@ func (qs *QueryStream) mergeErrors(transportErrors, parseErrors <-chan error) {
 				parseOpen = false
 				break
 			}
+			qs.noteError(err)
 			// Forward parse error (non-blocking)
 			select {
 			case qs.errors <- err:
 			case <-qs.ctx.Done():
 				return
 			}
+
+		case err := <-qs.internalErrors:
+			qs.noteError(err)
+			select {
+			case qs.errors <- err:
+			case <-qs.ctx.Done():
+				return
+			}
+
+		case <-messagesDone:
+			messagesDone = nil
+		}
+	}
+
+	// Deliver errors raised while the stream was finishing
+	for {
+		select {
+		case err := <-qs.internalErrors:
+			qs.noteError(err)
+			select {
+			case qs.errors <- err:
+			case <-qs.ctx.Done():
+				return
+			}
+		default:
+			return
 		}
 	}
 }
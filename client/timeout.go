@@ -0,0 +1,150 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// timeoutWatch enforces Options.QueryTimeout, Options.IdleTimeout, and
+// Options.StartupTimeout for a stream.
+type timeoutWatch struct {
+	query   time.Duration
+	idle    time.Duration
+	startup time.Duration
+
+	mu         sync.Mutex
+	lastOutput time.Time
+	sawOutput  bool
+	// awaiting is set while output is expected: for a query until its
+	// result, and for a session from each sent message until its result
+	awaiting bool
+}
+
+// newTimeoutWatch returns a watch for the options' timeouts, or nil if
+// none are set.
+func newTimeoutWatch(options *types.Options) *timeoutWatch {
+	tw := &timeoutWatch{lastOutput: time.Now(), awaiting: true}
+	if options.QueryTimeout != nil {
+		tw.query = *options.QueryTimeout
+	}
+	if options.IdleTimeout != nil {
+		tw.idle = *options.IdleTimeout
+	}
+	if options.StartupTimeout != nil {
+		tw.startup = *options.StartupTimeout
+	}
+
+	if tw.query <= 0 && tw.idle <= 0 && tw.startup <= 0 {
+		return nil
+	}
+	return tw
+}
+
+// noteOutput records that the CLI produced output.
+func (tw *timeoutWatch) noteOutput() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.lastOutput = time.Now()
+	tw.sawOutput = true
+}
+
+// setAwaiting records whether output is expected. Idle time is counted
+// from the moment output becomes expected.
+func (tw *timeoutWatch) setAwaiting(awaiting bool) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.awaiting = awaiting
+	tw.lastOutput = time.Now()
+}
+
+// idleRemaining returns how long until the idle timeout expires, or zero
+// if it has.
+func (tw *timeoutWatch) idleRemaining() time.Duration {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.awaiting {
+		return tw.idle
+	}
+	return max(tw.idle-time.Since(tw.lastOutput), 0)
+}
+
+// startupRemaining returns how long until the startup timeout expires, zero
+// if it has, or -1 once the CLI has produced output.
+func (tw *timeoutWatch) startupRemaining() time.Duration {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	switch {
+	case tw.sawOutput:
+		return -1
+	case !tw.awaiting:
+		return tw.startup
+	default:
+		// Until the first output, lastOutput is when output became expected
+		return max(tw.startup-time.Since(tw.lastOutput), 0)
+	}
+}
+
+// watchTimeouts aborts the stream when a timeout expires. It returns when
+// the stream ends.
+func (qs *QueryStream) watchTimeouts(tw *timeoutWatch) {
+	var queryExpired <-chan time.Time
+	if tw.query > 0 {
+		timer := time.NewTimer(tw.query)
+		defer timer.Stop()
+		queryExpired = timer.C
+	}
+
+	var idleTimer *time.Timer
+	var idleExpired <-chan time.Time
+	if tw.idle > 0 {
+		idleTimer = time.NewTimer(tw.idle)
+		defer idleTimer.Stop()
+		idleExpired = idleTimer.C
+	}
+
+	var startupTimer *time.Timer
+	var startupExpired <-chan time.Time
+	if tw.startup > 0 {
+		startupTimer = time.NewTimer(tw.startup)
+		defer startupTimer.Stop()
+		startupExpired = startupTimer.C
+	}
+
+	for {
+		select {
+		case <-qs.ctx.Done():
+			return
+		case <-qs.messagesDone:
+			return
+
+		case <-queryExpired:
+			qs.abort(&types.TimeoutError{Timeout: tw.query})
+			return
+
+		case <-idleExpired:
+			if remaining := tw.idleRemaining(); remaining > 0 {
+				idleTimer.Reset(remaining)
+				continue
+			}
+			qs.abort(&types.TimeoutError{Timeout: tw.idle, Idle: true})
+			return
+
+		case <-startupExpired:
+			remaining := tw.startupRemaining()
+			if remaining < 0 {
+				startupExpired = nil
+				continue
+			}
+			if remaining > 0 {
+				startupTimer.Reset(remaining)
+				continue
+			}
+			qs.abort(&types.StartupTimeoutError{Timeout: tw.startup})
+			return
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// collectTimeoutError drains a query stream and returns its TimeoutError, if any.
+func collectTimeoutError(t *testing.T, stream *QueryStream) *types.TimeoutError {
+	t.Helper()
+
+	for range stream.Messages() {
+	}
+
+	var timeoutErr *types.TimeoutError
+	for err := range stream.Errors() {
+		errors.As(err, &timeoutErr)
+	}
+	return timeoutErr
+}
+
+func TestQueryTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *types.Options
+		expected types.TimeoutError
+	}{
+		{
+			name:     "idle timeout",
+			options:  types.NewOptions().WithIdleTimeout(50 * time.Millisecond),
+			expected: types.TimeoutError{Timeout: 50 * time.Millisecond, Idle: true},
+		},
+		{
+			name:     "query timeout",
+			options:  types.NewOptions().WithQueryTimeout(50 * time.Millisecond),
+			expected: types.TimeoutError{Timeout: 50 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			mt := newMockInputTransport()
+			mt.data <- []byte(`{"type": "assistant", "message": {"content": [{"type": "text", "text": "thinking..."}]}}`)
+
+			start := time.Now()
+			stream, err := NewClient().QueryWithTransport(ctx, "Hello", tt.options, mt)
+			if err != nil {
+				t.Fatalf("QueryWithTransport failed: %v", err)
+			}
+			defer stream.Close()
+
+			timeoutErr := collectTimeoutError(t, stream)
+			if timeoutErr == nil {
+				t.Fatal("Expected TimeoutError")
+			}
+			if *timeoutErr != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *timeoutErr)
+			}
+			if !errors.Is(timeoutErr, context.DeadlineExceeded) {
+				t.Error("Expected TimeoutError to match context.DeadlineExceeded")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected timeout to stop the query promptly, took %v", elapsed)
+			}
+			if mt.IsConnected() {
+				t.Error("Expected transport to be closed")
+			}
+		})
+	}
+}
+
+func TestIdleTimeoutResetByOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", types.NewOptions().WithIdleTimeout(80*time.Millisecond), mt)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	// Keep producing output for longer than the idle timeout
+	for i := 0; i < 5; i++ {
+		mt.data <- []byte(`{"type": "assistant", "message": {"content": [{"type": "text", "text": "working"}]}}`)
+		time.Sleep(30 * time.Millisecond)
+	}
+	mt.data <- []byte(`{"type": "result", "subtype": "success"}`)
+	mt.Close()
+
+	if timeoutErr := collectTimeoutError(t, stream); timeoutErr != nil {
+		t.Errorf("Expected no timeout while output flows, got %v", timeoutErr)
+	}
+}
+
+func TestSessionIdleTimeoutOnlyWhileAwaitingOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	session := NewSession(ctx, mt, parser.NewParser(0))
+	session.stream.applyOptions(types.NewOptions().WithIdleTimeout(50 * time.Millisecond))
+	if err := session.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer session.Close()
+
+	// Waiting for the user is not idling
+	time.Sleep(120 * time.Millisecond)
+
+	if err := session.Send(ctx, "first"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	receiveUntilResult(t, session)
+
+	// Neither is waiting for the next prompt after a result
+	time.Sleep(120 * time.Millisecond)
+
+	if err := session.Send(ctx, "second"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	receiveUntilResult(t, session)
+}
+
+func TestStartupTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   bool
+		expected bool
+	}{
+		{name: "no output", output: false, expected: true},
+		{name: "output before timeout", output: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			mt := newMockInputTransport()
+			if tt.output {
+				mt.data <- []byte(`{"type": "system", "subtype": "init"}`)
+			}
+
+			options := types.NewOptions().WithStartupTimeout(50 * time.Millisecond)
+			stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+			if err != nil {
+				t.Fatalf("QueryWithTransport failed: %v", err)
+			}
+			defer stream.Close()
+
+			if tt.output {
+				// Slow responses after startup are not startup timeouts
+				time.Sleep(120 * time.Millisecond)
+				mt.data <- []byte(`{"type": "result", "subtype": "success"}`)
+				mt.Close()
+			}
+
+			for range stream.Messages() {
+			}
+			var startupErr *types.StartupTimeoutError
+			for err := range stream.Errors() {
+				errors.As(err, &startupErr)
+			}
+
+			if (startupErr != nil) != tt.expected {
+				t.Fatalf("Expected startup timeout %v, got %v", tt.expected, startupErr)
+			}
+			if startupErr == nil {
+				return
+			}
+			if startupErr.Timeout != 50*time.Millisecond {
+				t.Errorf("Expected timeout 50ms, got %v", startupErr.Timeout)
+			}
+			if !errors.Is(startupErr, context.DeadlineExceeded) {
+				t.Error("Expected StartupTimeoutError to match context.DeadlineExceeded")
+			}
+			if !types.IsRetryable(startupErr) {
+				t.Error("Expected StartupTimeoutError to be retryable")
+			}
+		})
+	}
+}
+
+func TestHeartbeatReportsLastActivity(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	mt := newMockInputTransport()
+	options := types.NewOptions().WithHeartbeatInterval(20 * time.Millisecond)
+	stream, err := NewClient().QueryWithTransport(ctx, "Hello", options, mt)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	created := stream.LastActivity()
+	mt.data <- []byte(`{"type": "assistant", "message": {"content": [{"type": "text", "text": "working"}]}}`)
+
+	var heartbeats []*types.SystemMessage
+	for msg := range stream.Messages() {
+		if system, ok := msg.(*types.SystemMessage); ok && system.Subtype == types.SystemSubtypeHeartbeat {
+			heartbeats = append(heartbeats, system)
+			if len(heartbeats) == 3 {
+				stream.Close()
+			}
+		}
+	}
+
+	if len(heartbeats) < 3 {
+		t.Fatalf("Expected 3 heartbeats, got %d", len(heartbeats))
+	}
+	if !stream.LastActivity().After(created) {
+		t.Error("Expected output to advance LastActivity")
+	}
+
+	last := heartbeats[len(heartbeats)-1]
+	if last.Data["last_activity"] != stream.LastActivity().Format(time.RFC3339Nano) {
+		t.Errorf("Expected heartbeat to report last activity %v, got %v", stream.LastActivity(), last.Data["last_activity"])
+	}
+	if idle, ok := last.Data["idle_ms"].(int64); !ok || idle < 20 {
+		t.Errorf("Expected heartbeat to report idle time, got %v", last.Data["idle_ms"])
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Usage is the aggregate cost and usage of a set of queries.
+type Usage struct {
+	// Queries is the number of result messages recorded.
+	Queries int
+
+	// Errors is the number of results that reported an error.
+	Errors int
+
+	// Turns is the total number of conversation turns.
+	Turns int
+
+	// TotalCostUSD is the total reported cost in US dollars.
+	TotalCostUSD float64
+
+	// Token counts summed from each result's usage.
+	InputTokens              int
+	OutputTokens             int
+	CacheCreationInputTokens int
+	CacheReadInputTokens     int
+
+	// DurationMs and DurationAPIMs are the total wall-clock and API time.
+	DurationMs    int
+	DurationAPIMs int
+}
+
+// add accumulates a result message into the usage.
+func (u *Usage) add(result *types.ResultMessage) {
+	u.Queries++
+	if result.IsError {
+		u.Errors++
+	}
+	u.Turns += result.NumTurns
+	if result.TotalCostUSD != nil {
+		u.TotalCostUSD += *result.TotalCostUSD
+	}
+	u.InputTokens += usageCount(result.Usage, "input_tokens")
+	u.OutputTokens += usageCount(result.Usage, "output_tokens")
+	u.CacheCreationInputTokens += usageCount(result.Usage, "cache_creation_input_tokens")
+	u.CacheReadInputTokens += usageCount(result.Usage, "cache_read_input_tokens")
+	u.DurationMs += result.DurationMs
+	u.DurationAPIMs += result.DurationAPIMs
+}
+
+// usageCount reads a token count from a result's usage map.
+func usageCount(usage map[string]any, key string) int {
+	if val, ok := usage[key].(float64); ok {
+		return int(val)
+	}
+	return 0
+}
+
+// UsageTracker accumulates cost and usage across queries. Attach one to a
+// client with ClientOptions.UsageTracker to record every query and session
+// the client runs; a tracker may be shared by several clients. It is safe
+// for concurrent use.
+type UsageTracker struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewUsageTracker creates an empty usage tracker.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{}
+}
+
+// Record adds a result message to the totals.
+func (ut *UsageTracker) Record(result *types.ResultMessage) {
+	if result == nil {
+		return
+	}
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+	ut.usage.add(result)
+}
+
+// Snapshot returns the current totals.
+func (ut *UsageTracker) Snapshot() Usage {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+	return ut.usage
+}
+
+// Reset clears the totals and returns the values they had, so that usage
+// can be collected per billing period without losing any results.
+func (ut *UsageTracker) Reset() Usage {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	usage := ut.usage
+	ut.usage = Usage{}
+	return usage
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestUsageTrackerRecord(t *testing.T) {
+	tracker := NewUsageTracker()
+
+	cost := 0.25
+	tracker.Record(&types.ResultMessage{
+		NumTurns:     2,
+		DurationMs:   1000,
+		TotalCostUSD: &cost,
+		Usage: map[string]any{
+			"input_tokens":                float64(100),
+			"output_tokens":               float64(50),
+			"cache_creation_input_tokens": float64(10),
+			"cache_read_input_tokens":     float64(5),
+		},
+	})
+	tracker.Record(&types.ResultMessage{IsError: true, NumTurns: 1, TotalCostUSD: &cost})
+	tracker.Record(nil)
+
+	usage := tracker.Snapshot()
+	want := Usage{
+		Queries:                  2,
+		Errors:                   1,
+		Turns:                    3,
+		TotalCostUSD:             0.5,
+		InputTokens:              100,
+		OutputTokens:             50,
+		CacheCreationInputTokens: 10,
+		CacheReadInputTokens:     5,
+		DurationMs:               1000,
+	}
+	if usage != want {
+		t.Errorf("Expected %+v, got %+v", want, usage)
+	}
+
+	if reset := tracker.Reset(); reset != want {
+		t.Errorf("Expected Reset to return %+v, got %+v", want, reset)
+	}
+	if usage := tracker.Snapshot(); usage != (Usage{}) {
+		t.Errorf("Expected empty usage after reset, got %+v", usage)
+	}
+}
+
+func TestClientRecordsUsage(t *testing.T) {
+	tracker := NewUsageTracker()
+	client := NewClientWithOptions(ClientOptions{UsageTracker: tracker})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		transport := &mockMessageTransport{messages: []string{
+			`{"type": "assistant", "message": {"content": [{"type": "text", "text": "Hi"}]}}`,
+			`{"type": "result", "subtype": "success", "num_turns": 1, "total_cost_usd": 0.1, "usage": {"output_tokens": 7}}`,
+		}}
+
+		stream, err := client.QueryWithTransport(ctx, "Hello", nil, transport)
+		if err != nil {
+			t.Fatalf("QueryWithTransport failed: %v", err)
+		}
+		for range stream.Messages() {
+		}
+		stream.Close()
+	}
+
+	usage := tracker.Snapshot()
+	if usage.Queries != 2 || usage.OutputTokens != 14 {
+		t.Errorf("Expected 2 queries and 14 output tokens, got %+v", usage)
+	}
+	if usage.TotalCostUSD < 0.2-1e-9 || usage.TotalCostUSD > 0.2+1e-9 {
+		t.Errorf("Expected total cost 0.2, got %v", usage.TotalCostUSD)
+	}
+}
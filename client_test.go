@@ -0,0 +1,38 @@
+package claudecode
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientQueryWithTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	client := NewClient(ClientOptions{ParserBufferSize: 64})
+
+	// A message larger than the client's buffer is rejected
+	large := `{"type":"assistant","message":{"content":[{"type":"text","text":"` + strings.Repeat("x", 128) + `"}]}}`
+	stream, err := client.QueryWithTransport(ctx, "hello", nil, &scriptedTransport{lines: []string{large}})
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	result, _ := collectQueryResult(ctx, stream)
+	stream.Close()
+	if len(result.AssistantMessages) != 0 || len(result.Errors) == 0 {
+		t.Errorf("Expected buffer overflow with the client's buffer size, got %+v", result)
+	}
+
+	// The default client is unaffected
+	stream, err = QueryWithTransport(ctx, "hello", nil, &scriptedTransport{lines: []string{large}})
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	result, _ = collectQueryResult(ctx, stream)
+	stream.Close()
+	if len(result.AssistantMessages) != 1 {
+		t.Errorf("Expected default client to parse the message, got %+v", result)
+	}
+}
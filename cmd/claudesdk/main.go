@@ -0,0 +1,353 @@
+// Command claudesdk runs Claude Code prompts through the SDK, for shell
+// pipelines and for checking the SDK against new CLI releases.
+//
+// Usage:
+//
+//	claudesdk [flags] [prompt...]
+//
+// The prompt is taken from the arguments, or read from stdin when there
+// are none. Assistant text is written to stdout; with -format ndjson, every
+// message and error is written as a JSON object with a "type" field.
+//
+// With -session, each line of stdin is sent as a turn of one interactive
+// session, after the prompt from the arguments if any. The session ID is
+// reported on stderr with -v, and can be passed to -resume to continue the
+// conversation later:
+//
+//	claudesdk -v "Remember the number 42" 2>&1 >/dev/null | grep session
+//	claudesdk -resume <id> "What number did I ask you to remember?"
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// config holds the parsed command line.
+type config struct {
+	prompt  string
+	format  string
+	session bool
+	verbose bool
+	timeout time.Duration
+	cliPath string
+	options *claudecode.Options
+}
+
+// parseFlags parses the command line into a config.
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	fs := flag.NewFlagSet("claudesdk", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: claudesdk [flags] [prompt...]")
+		fs.PrintDefaults()
+	}
+
+	cfg := &config{}
+	fs.StringVar(&cfg.format, "format", "text", "output format: text or ndjson")
+	fs.BoolVar(&cfg.session, "session", false, "run an interactive session, sending each line of stdin as a turn")
+	fs.BoolVar(&cfg.verbose, "v", false, "report the session ID, cost, and turns on stderr")
+	fs.DurationVar(&cfg.timeout, "timeout", 0, "stop after this long (0 for no limit)")
+	fs.StringVar(&cfg.cliPath, "cli", "", "path to the claude CLI (default: search PATH)")
+
+	model := fs.String("model", "", "model to use")
+	fallbackModel := fs.String("fallback-model", "", "model to use when the main model is overloaded")
+	systemPrompt := fs.String("system-prompt", "", "system prompt")
+	appendSystemPrompt := fs.String("append-system-prompt", "", "text appended to the default system prompt")
+	maxTurns := fs.Int("max-turns", 0, "maximum agentic turns per prompt")
+	allowedTools := fs.String("allowed-tools", "", "comma-separated tools to allow")
+	disallowedTools := fs.String("disallowed-tools", "", "comma-separated tools to disallow")
+	permissionMode := fs.String("permission-mode", "", "permission mode: default, acceptEdits, plan, or bypassPermissions")
+	outputStyle := fs.String("output-style", "", "output style, such as Explanatory or Learning")
+	mcpConfig := fs.String("mcp-config", "", "MCP configuration file, such as .mcp.json")
+	cwd := fs.String("cwd", "", "working directory for the CLI")
+	resume := fs.String("resume", "", "resume the session with this ID")
+	continueConversation := fs.Bool("continue", false, "continue the most recent conversation")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if cfg.format != "text" && cfg.format != "ndjson" {
+		return nil, fmt.Errorf("unknown format %q", cfg.format)
+	}
+	cfg.prompt = strings.Join(fs.Args(), " ")
+
+	options := claudecode.NewOptions()
+	if *model != "" {
+		options.WithModel(*model)
+	}
+	if *fallbackModel != "" {
+		options.WithFallbackModel(*fallbackModel)
+	}
+	if *systemPrompt != "" {
+		options.WithSystemPrompt(*systemPrompt)
+	}
+	if *appendSystemPrompt != "" {
+		options.WithAppendSystemPrompt(*appendSystemPrompt)
+	}
+	if *maxTurns > 0 {
+		options.WithMaxTurns(*maxTurns)
+	}
+	if *allowedTools != "" {
+		options.WithAllowedTools(splitList(*allowedTools)...)
+	}
+	if *disallowedTools != "" {
+		options.WithDisallowedTools(splitList(*disallowedTools)...)
+	}
+	if *permissionMode != "" {
+		options.WithPermissionMode(claudecode.PermissionMode(*permissionMode))
+	}
+	if *outputStyle != "" {
+		options.WithOutputStyle(*outputStyle)
+	}
+	if *mcpConfig != "" {
+		options.WithMcpConfigFile(*mcpConfig)
+	}
+	if *cwd != "" {
+		options.WithCwd(*cwd)
+	}
+	if *resume != "" {
+		options.WithResume(*resume)
+	}
+	if *continueConversation {
+		options.WithContinueConversation()
+	}
+	cfg.options = options
+
+	return cfg, nil
+}
+
+// run runs the command and returns its exit code: 0 on success, 1 if the
+// query failed, and 2 for invalid usage.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(stderr, "claudesdk:", err)
+		return 2
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	client := claudecode.NewClient(claudecode.ClientOptions{CLIPath: cfg.cliPath})
+	out := &output{w: stdout, stderr: stderr, format: cfg.format, verbose: cfg.verbose}
+
+	if cfg.session {
+		err = runSession(ctx, client, cfg, stdin, out)
+	} else {
+		err = runQuery(ctx, client, cfg, stdin, out)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "claudesdk:", err)
+		return 1
+	}
+	if out.failed {
+		return 1
+	}
+	return 0
+}
+
+// runQuery runs a single prompt.
+func runQuery(ctx context.Context, client *claudecode.Client, cfg *config, stdin io.Reader, out *output) error {
+	prompt := cfg.prompt
+	if prompt == "" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt: %w", err)
+		}
+		prompt = strings.TrimSpace(string(data))
+	}
+	if prompt == "" {
+		return fmt.Errorf("no prompt given")
+	}
+
+	stream, err := client.Query(ctx, prompt, cfg.options)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	messages := stream.Messages()
+	errs := stream.Errors()
+	for messages != nil || errs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			out.message(msg)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			out.error(err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// runSession sends the prompt, then each line of stdin, as turns of one
+// session, waiting for each turn to finish before sending the next.
+func runSession(ctx context.Context, client *claudecode.Client, cfg *config, stdin io.Reader, out *output) error {
+	session, err := client.NewSession(ctx, cfg.options)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	errs := session.Errors()
+	turn := func(prompt string) error {
+		if err := session.Send(ctx, prompt); err != nil {
+			return err
+		}
+		for {
+			select {
+			case msg, ok := <-session.Receive():
+				if !ok {
+					return fmt.Errorf("session ended")
+				}
+				out.message(msg)
+				if _, ok := msg.(*claudecode.ResultMessage); ok {
+					return nil
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				out.error(err)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	if cfg.prompt != "" {
+		if err := turn(cfg.prompt); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		prompt := strings.TrimSpace(scanner.Text())
+		if prompt == "" {
+			continue
+		}
+		if err := turn(prompt); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// output writes messages and errors in the chosen format.
+type output struct {
+	w       io.Writer
+	stderr  io.Writer
+	format  string
+	verbose bool
+
+	// failed is set when an error or failed result is reported
+	failed bool
+}
+
+// message writes a message: its text, or the whole message as JSON.
+func (o *output) message(msg claudecode.Message) {
+	if result, ok := msg.(*claudecode.ResultMessage); ok {
+		if result.IsError {
+			o.failed = true
+		}
+		if o.verbose {
+			cost := 0.0
+			if result.TotalCostUSD != nil {
+				cost = *result.TotalCostUSD
+			}
+			fmt.Fprintf(o.stderr, "session %s: %s, %d turns, $%.4f\n",
+				result.SessionID, result.Subtype, result.NumTurns, cost)
+		}
+	}
+
+	if o.format == "ndjson" {
+		// Messages encode with their type, and their content blocks' types
+		data, err := json.Marshal(msg)
+		if err != nil {
+			o.error(fmt.Errorf("failed to encode %s message: %w", msg.Type(), err))
+			return
+		}
+		fmt.Fprintf(o.w, "%s\n", data)
+		return
+	}
+
+	switch m := msg.(type) {
+	case *claudecode.AssistantMessage:
+		if text := m.Text(); text != "" {
+			fmt.Fprintln(o.w, text)
+		}
+	case *claudecode.ResultMessage:
+		if m.IsError && m.Result != nil {
+			fmt.Fprintln(o.stderr, *m.Result)
+		}
+	}
+}
+
+// error reports an error from the stream.
+func (o *output) error(err error) {
+	o.failed = true
+	if o.format == "ndjson" {
+		o.writeJSON(map[string]any{
+			"type":  "error",
+			"error": err.Error(),
+			"kind":  string(claudecode.ErrorKind(err)),
+		})
+		return
+	}
+	fmt.Fprintln(o.stderr, "claudesdk:", err)
+}
+
+func (o *output) writeJSON(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintln(o.stderr, "claudesdk:", err)
+		return
+	}
+	fmt.Fprintf(o.w, "%s\n", data)
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeCLI writes a CLI that echoes its prompt, or "resumed ID" when
+// resuming, and in a session answers each line of input with "turn N".
+func fakeCLI(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	script := `#!/bin/sh
+streaming=
+resume=
+prev=
+for arg; do
+	[ "$arg" = "--input-format" ] && streaming=1
+	[ "$prev" = "--resume" ] && resume="$arg"
+	prev="$arg"
+	prompt="$arg"
+done
+if [ -n "$streaming" ]; then
+	n=0
+	while read -r line; do
+		n=$((n+1))
+		echo '{"type":"assistant","message":{"content":[{"type":"text","text":"turn '"$n"'"}]}}'
+		echo '{"type":"result","subtype":"success","session_id":"s1","num_turns":'"$n"'}'
+	done
+	exit 0
+fi
+[ -n "$resume" ] && prompt="resumed $resume"
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$prompt"'"}]}}'
+echo '{"type":"result","subtype":"success","session_id":"s1","num_turns":1}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cliPath
+}
+
+func TestRun(t *testing.T) {
+	cli := fakeCLI(t)
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+	}{
+		{"prompt from args", []string{"hello", "there"}, "", 0, "hello there\n"},
+		{"prompt from stdin", nil, "from stdin\n", 0, "from stdin\n"},
+		{"resume", []string{"-resume", "abc", "again"}, "", 0, "resumed abc\n"},
+		{"session", []string{"-session", "first"}, "second\n\nthird\n", 0, "turn 1\nturn 2\nturn 3\n"},
+		{"no prompt", nil, "", 1, ""},
+		{"unknown format", []string{"-format", "xml", "hi"}, "", 2, ""},
+		{"unknown flag", []string{"-bogus", "hi"}, "", 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-cli", cli, "-timeout", "10s"}, tt.args...)
+			code := run(context.Background(), args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.code, code, stderr.String())
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("Expected stdout %q, got %q", tt.stdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunNDJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"-cli", fakeCLI(t), "-format", "ndjson", "-v", "hello"}
+	if code := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+
+	var types []string
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var event map[string]any
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("Invalid NDJSON output: %v", err)
+		}
+		types = append(types, event["type"].(string))
+
+		if event["type"] == "assistant" {
+			block := event["content"].([]any)[0].(map[string]any)
+			if block["type"] != "text" || block["text"] != "hello" {
+				t.Errorf("Unexpected block: %v", block)
+			}
+		}
+	}
+	if got := strings.Join(types, ","); got != "assistant,result" {
+		t.Errorf("Expected assistant,result, got %s", got)
+	}
+
+	if !strings.Contains(stderr.String(), "session s1: success, 1 turns") {
+		t.Errorf("Expected session summary on stderr, got %q", stderr.String())
+	}
+}
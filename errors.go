@@ -2,135 +2,191 @@
 package claudecode
 
 import (
-	"errors"
-	"fmt"
+	types2 "github.com/jrossi/claude-code-sdk-golang/types"
 )
 
 // Sentinel errors for common cases
 var (
 	// ErrCLINotFound indicates that the Claude Code CLI is not installed or not found
-	ErrCLINotFound = errors.New("claude code cli not found")
+	ErrCLINotFound = types2.ErrCLINotFound
 
 	// ErrCLIConnection indicates a connection error with the Claude Code CLI
-	ErrCLIConnection = errors.New("cli connection error")
+	ErrCLIConnection = types2.ErrCLIConnection
 
 	// ErrJSONDecode indicates a JSON decoding error from CLI output
-	ErrJSONDecode = errors.New("json decode error")
+	ErrJSONDecode = types2.ErrJSONDecode
 
 	// ErrStreamClosed indicates that the message stream has been closed
-	ErrStreamClosed = errors.New("message stream closed")
+	ErrStreamClosed = types2.ErrStreamClosed
 
 	// ErrInvalidWorkingDirectory indicates an invalid working directory
-	ErrInvalidWorkingDirectory = errors.New("invalid working directory")
+	ErrInvalidWorkingDirectory = types2.ErrInvalidWorkingDirectory
+
+	// ErrNoResult indicates that a query ended without a result message
+	ErrNoResult = types2.ErrNoResult
 )
 
-// CLINotFoundError represents an error when Claude Code CLI is not found.
-// It provides additional context about where the CLI was searched for.
-type CLINotFoundError struct {
-	Message string
-	CLIPath string
-	Err     error
-}
+// Re-export error types from internal package
+type (
+	// CLINotFoundError represents an error when Claude Code CLI is not found.
+	// It provides additional context about where the CLI was searched for.
+	CLINotFoundError = types2.CLINotFoundError
 
-func (e *CLINotFoundError) Error() string {
-	if e.CLIPath != "" {
-		return fmt.Sprintf("%s: %s", e.Message, e.CLIPath)
-	}
-	return e.Message
-}
+	// ProcessError represents an error from a failed CLI process.
+	// It includes the exit code and stderr output for debugging.
+	ProcessError = types2.ProcessError
 
-func (e *CLINotFoundError) Unwrap() error {
-	return e.Err
-}
+	// JSONDecodeError represents an error when unable to decode JSON from CLI output.
+	// It preserves the original line and underlying error for debugging.
+	JSONDecodeError = types2.JSONDecodeError
 
-// ProcessError represents an error from a failed CLI process.
-// It includes the exit code and stderr output for debugging.
-type ProcessError struct {
-	Message  string
-	ExitCode int
-	Stderr   string
-	Err      error
-}
+	// ConnectionError represents a connection-related error with additional context.
+	ConnectionError = types2.ConnectionError
 
-func (e *ProcessError) Error() string {
-	msg := fmt.Sprintf("%s (exit code: %d)", e.Message, e.ExitCode)
-	if e.Stderr != "" {
-		msg = fmt.Sprintf("%s\nError output: %s", msg, e.Stderr)
-	}
-	return msg
-}
+	// AuthError indicates the CLI could not authenticate with the API.
+	AuthError = types2.AuthError
 
-func (e *ProcessError) Unwrap() error {
-	return e.Err
-}
+	// RateLimitError indicates the API rejected a request because of rate
+	// limits or overload.
+	RateLimitError = types2.RateLimitError
 
-// JSONDecodeError represents an error when unable to decode JSON from CLI output.
-// It preserves the original line and underlying error for debugging.
-type JSONDecodeError struct {
-	Line         string
-	OriginalErr  error
-	BufferLength int
-}
+	// UsageError indicates the CLI was invoked incorrectly or cannot run in
+	// the current environment.
+	UsageError = types2.UsageError
 
-func (e *JSONDecodeError) Error() string {
-	truncated := e.Line
-	if len(truncated) > 100 {
-		truncated = truncated[:100] + "..."
-	}
-	return fmt.Sprintf("failed to decode JSON: %s", truncated)
-}
+	// UnknownTypeError indicates the CLI produced a message or content
+	// block of an unrecognized type, reported with ParseModeStrict.
+	UnknownTypeError = types2.UnknownTypeError
 
-func (e *JSONDecodeError) Unwrap() error {
-	return e.OriginalErr
-}
+	// BudgetExceededError indicates a query was stopped because its cost
+	// exceeded Options.MaxCostUSD.
+	BudgetExceededError = types2.BudgetExceededError
 
-// ConnectionError represents a connection-related error with additional context.
-type ConnectionError struct {
-	Message string
-	Err     error
-}
+	// TimeoutError indicates a query was stopped by its query or idle timeout.
+	TimeoutError = types2.TimeoutError
 
-func (e *ConnectionError) Error() string {
-	if e.Err != nil {
-		return fmt.Sprintf("%s: %v", e.Message, e.Err)
-	}
-	return e.Message
-}
+	// StartupTimeoutError indicates the CLI produced no output within the
+	// startup timeout.
+	StartupTimeoutError = types2.StartupTimeoutError
 
-func (e *ConnectionError) Unwrap() error {
-	return e.Err
-}
+	// CLIVersionError indicates the installed CLI is older than the
+	// options of a query require.
+	CLIVersionError = types2.CLIVersionError
+
+	// ResourceLimitError indicates the CLI was killed for exceeding one of
+	// its ResourceLimits.
+	ResourceLimitError = types2.ResourceLimitError
+
+	// BufferOverflowError reports a message from the CLI longer than the
+	// buffer limit, which was skipped.
+	BufferOverflowError = types2.BufferOverflowError
+
+	// Kind classifies an error by its cause.
+	Kind = types2.Kind
+)
+
+// Re-export error kind constants
+const (
+	// KindUnknown is an error that could not be classified.
+	KindUnknown = types2.KindUnknown
+
+	// KindCLINotFound means the Claude Code CLI could not be located.
+	KindCLINotFound = types2.KindCLINotFound
+
+	// KindConnection means communication with the CLI failed.
+	KindConnection = types2.KindConnection
+
+	// KindProcess means the CLI process exited unsuccessfully.
+	KindProcess = types2.KindProcess
+
+	// KindJSONDecode means the CLI produced output that could not be decoded.
+	KindJSONDecode = types2.KindJSONDecode
+
+	// KindAuth means the CLI could not authenticate with the API.
+	KindAuth = types2.KindAuth
+
+	// KindRateLimit means the API rejected the request due to rate limits.
+	KindRateLimit = types2.KindRateLimit
+
+	// KindPermissionDenied means an operation was not permitted.
+	KindPermissionDenied = types2.KindPermissionDenied
+
+	// KindUsage means the CLI was invoked with invalid arguments or options.
+	KindUsage = types2.KindUsage
+
+	// KindTimeout means a deadline was exceeded.
+	KindTimeout = types2.KindTimeout
+
+	// KindCanceled means the operation was cancelled by the caller.
+	KindCanceled = types2.KindCanceled
+
+	// KindBudgetExceeded means a query was stopped for exceeding its cost limit.
+	KindBudgetExceeded = types2.KindBudgetExceeded
+
+	// KindCLIVersion means the installed CLI is too old for the query.
+	KindCLIVersion = types2.KindCLIVersion
+
+	// KindResourceLimit means the CLI was killed for exceeding a resource limit.
+	KindResourceLimit = types2.KindResourceLimit
+
+	// KindBufferOverflow means a message from the CLI exceeded the buffer
+	// limit and was skipped.
+	KindBufferOverflow = types2.KindBufferOverflow
+)
+
+// Re-export resources reported by ResourceLimitError
+const (
+	ResourceMemory    = types2.ResourceMemory
+	ResourceWallClock = types2.ResourceWallClock
+)
 
 // NewCLINotFoundError creates a new CLINotFoundError with the given message and optional CLI path.
 func NewCLINotFoundError(message, cliPath string) *CLINotFoundError {
-	return &CLINotFoundError{
-		Message: message,
-		CLIPath: cliPath,
-		Err:     ErrCLINotFound,
-	}
+	return types2.NewCLINotFoundError(message, cliPath)
 }
 
 // NewProcessError creates a new ProcessError with the given details.
 func NewProcessError(message string, exitCode int, stderr string) *ProcessError {
-	return &ProcessError{
-		Message:  message,
-		ExitCode: exitCode,
-		Stderr:   stderr,
-	}
+	return types2.NewProcessError(message, exitCode, stderr)
 }
 
 // NewJSONDecodeError creates a new JSONDecodeError with the given line and original error.
 func NewJSONDecodeError(line string, originalErr error) *JSONDecodeError {
-	return &JSONDecodeError{
-		Line:        line,
-		OriginalErr: originalErr,
-	}
+	return types2.NewJSONDecodeError(line, originalErr)
 }
 
 // NewConnectionError creates a new ConnectionError with the given message and underlying error.
 func NewConnectionError(message string, err error) *ConnectionError {
-	return &ConnectionError{
-		Message: message,
-		Err:     err,
-	}
+	return types2.NewConnectionError(message, err)
+}
+
+// ErrorKind classifies err, looking through wrapped errors. It returns
+// KindUnknown for nil or unrecognized errors.
+//
+// Example:
+//
+//	switch claudecode.ErrorKind(err) {
+//	case claudecode.KindAuth:
+//		log.Fatal("check your API key")
+//	case claudecode.KindRateLimit:
+//		time.Sleep(time.Minute)
+//	}
+func ErrorKind(err error) Kind {
+	return types2.ErrorKind(err)
+}
+
+// IsRetryable reports whether err is likely transient, so that retrying
+// the same query may succeed.
+func IsRetryable(err error) bool {
+	return types2.IsRetryable(err)
+}
+
+// IsPermissionDenied reports whether err was caused by a denied permission.
+func IsPermissionDenied(err error) bool {
+	return types2.IsPermissionDenied(err)
+}
+
+// IsRateLimited reports whether err was caused by API rate limiting.
+func IsRateLimited(err error) bool {
+	return types2.IsRateLimited(err)
 }
@@ -0,0 +1,226 @@
+// Package events publishes every message of a stream to a Sink such as a
+// Kafka topic or a NATS subject, tagged with the host that ran it and its
+// session, so that the activity of a fleet of agents can be collected and
+// analyzed in one place.
+//
+//	conn, _ := nats.Connect(nats.DefaultURL)
+//	publisher := events.New(events.NewNATSSink(conn, "claude.events"))
+//	defer publisher.Close()
+//
+//	stream, err := client.Query(ctx, prompt, options)
+//	for msg := range publisher.Tee(stream.Messages()) {
+//		// ...
+//	}
+//
+// Events are encoded as JSON by default, with each message's "type" field
+// (see types.UnmarshalMessage). The grpcservice module provides a
+// protobuf Encoding for consumers in other languages.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Event is a message published with where and when it was seen.
+type Event struct {
+	Time time.Time `json:"time"`
+
+	// Source identifies the host or agent that ran the stream.
+	Source    string `json:"source,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+
+	// Seq numbers the events of a stream from 1, so that consumers can
+	// restore their order and detect gaps.
+	Seq     int64         `json:"seq"`
+	Message types.Message `json:"message"`
+}
+
+// UnmarshalJSON decodes an event encoded as JSON, restoring its message's
+// concrete type.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Time      time.Time       `json:"time"`
+		Source    string          `json:"source"`
+		SessionID string          `json:"session_id"`
+		Seq       int64           `json:"seq"`
+		Message   json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	msg, err := types.UnmarshalMessage(raw.Message)
+	if err != nil {
+		return err
+	}
+
+	*e = Event{Time: raw.Time, Source: raw.Source, SessionID: raw.SessionID, Seq: raw.Seq, Message: msg}
+	return nil
+}
+
+// Encoding serializes events for a sink.
+type Encoding struct {
+	// ContentType is the MIME type of the encoded events, sent to brokers
+	// that support headers.
+	ContentType string
+	Marshal     func(event Event) ([]byte, error)
+}
+
+// JSON encodes events as JSON objects.
+var JSON = Encoding{
+	ContentType: "application/json",
+	Marshal: func(event Event) ([]byte, error) {
+		return json.Marshal(event)
+	},
+}
+
+// encode encodes event with enc, or with JSON if enc has no Marshal
+// function.
+func encode(enc Encoding, event Event) ([]byte, string, error) {
+	if enc.Marshal == nil {
+		enc = JSON
+	}
+	data, err := enc.Marshal(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode %s event: %w", event.Message.Type(), err)
+	}
+	return data, enc.ContentType, nil
+}
+
+// Sink publishes events. Publish may be called from several goroutines.
+// Sinks that are also io.Closers are closed by Publisher.Close.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Publisher publishes the messages of streams to a Sink. It is safe for
+// concurrent use, and one Publisher may publish several streams.
+//
+// Heartbeats are not published.
+type Publisher struct {
+	sink Sink
+
+	// Source is set on every event. New sets it to the host name.
+	Source string
+
+	// OnError, if set, is called with each error returned by the sink.
+	// Errors are also kept for Err and Close.
+	OnError func(err error)
+
+	mu     sync.Mutex
+	stream stream
+	err    error
+}
+
+// stream is the state of one stream of messages.
+type stream struct {
+	sessionID string
+	seq       int64
+}
+
+// New returns a Publisher publishing to sink.
+func New(sink Sink) *Publisher {
+	source, _ := os.Hostname()
+	return &Publisher{sink: sink, Source: source}
+}
+
+// Publish publishes msg. It tracks the session ID and sequence number of
+// a single stream; use Tee to publish several streams at once.
+func (p *Publisher) Publish(ctx context.Context, msg types.Message) error {
+	p.mu.Lock()
+	event, ok := p.event(msg, &p.stream)
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return p.publish(ctx, event)
+}
+
+// Tee publishes every message received from messages and forwards it on
+// the returned channel, which is closed once messages is. Each message is
+// published before it is forwarded, so a slow sink slows the stream. The
+// returned channel must be drained.
+func (p *Publisher) Tee(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		var s stream
+		for msg := range messages {
+			if event, ok := p.event(msg, &s); ok {
+				p.publish(context.Background(), event)
+			}
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// event returns the event for msg in stream s, or false if msg is not
+// published.
+func (p *Publisher) event(msg types.Message, s *stream) (Event, bool) {
+	switch m := msg.(type) {
+	case nil:
+		return Event{}, false
+	case *types.SystemMessage:
+		if m.Subtype == types.SystemSubtypeHeartbeat {
+			return Event{}, false
+		}
+		if info, ok := m.Init(); ok && info.SessionID != "" {
+			s.sessionID = info.SessionID
+		}
+	case *types.ResultMessage:
+		if m.SessionID != "" {
+			s.sessionID = m.SessionID
+		}
+	}
+
+	s.seq++
+	return Event{Time: time.Now(), Source: p.Source, SessionID: s.sessionID, Seq: s.seq, Message: msg}, true
+}
+
+// publish sends event to the sink, keeping any error.
+func (p *Publisher) publish(ctx context.Context, event Event) error {
+	err := p.sink.Publish(ctx, event)
+	if err != nil {
+		p.fail(err)
+	}
+	return err
+}
+
+// fail keeps err and passes it to OnError.
+func (p *Publisher) fail(err error) {
+	p.mu.Lock()
+	p.err = errors.Join(p.err, err)
+	p.mu.Unlock()
+	if p.OnError != nil {
+		p.OnError(err)
+	}
+}
+
+// Err returns the errors the sink has returned so far, joined.
+func (p *Publisher) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close closes the sink if it is an io.Closer, and returns any error the
+// sink returned.
+func (p *Publisher) Close() error {
+	if closer, ok := p.sink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			p.fail(err)
+		}
+	}
+	return p.Err()
+}
@@ -0,0 +1,215 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// memorySink keeps the events published to it.
+type memorySink struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+	closed bool
+}
+
+func (s *memorySink) Publish(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func messages(msgs ...types.Message) <-chan types.Message {
+	ch := make(chan types.Message, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	return ch
+}
+
+var run = []types.Message{
+	&types.SystemMessage{Subtype: "init", Data: map[string]any{"type": "system", "subtype": "init", "session_id": "s1"}},
+	&types.SystemMessage{Subtype: types.SystemSubtypeHeartbeat, Data: map[string]any{"idle_ms": 10}},
+	&types.AssistantMessage{Content: []types.ContentBlock{&types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]any{"command": "ls"}}}},
+	&types.ResultMessage{Subtype: "success", SessionID: "s1", NumTurns: 1},
+}
+
+func TestPublisherTee(t *testing.T) {
+	sink := &memorySink{}
+	publisher := New(sink)
+	publisher.Source = "worker-1"
+
+	var forwarded int
+	for range publisher.Tee(messages(run...)) {
+		forwarded++
+	}
+	if forwarded != len(run) {
+		t.Errorf("Expected %d messages forwarded, got %d", len(run), forwarded)
+	}
+
+	if len(sink.events) != 3 {
+		t.Fatalf("Expected 3 events without the heartbeat, got %d", len(sink.events))
+	}
+	for i, event := range sink.events {
+		if event.Seq != int64(i+1) || event.SessionID != "s1" || event.Source != "worker-1" || event.Time.IsZero() {
+			t.Errorf("Event %d: unexpected metadata %+v", i, event)
+		}
+	}
+	if sink.events[1].Message != run[2] {
+		t.Errorf("Expected the assistant message, got %#v", sink.events[1].Message)
+	}
+
+	if err := publisher.Close(); err != nil || !sink.closed {
+		t.Errorf("Expected Close to close the sink, got %v", err)
+	}
+}
+
+func TestPublisherErrors(t *testing.T) {
+	sink := &memorySink{err: errors.New("broker down")}
+	publisher := New(sink)
+	var reported []error
+	publisher.OnError = func(err error) { reported = append(reported, err) }
+
+	if err := publisher.Publish(context.Background(), run[0]); err == nil {
+		t.Error("Expected the sink's error")
+	}
+	if err := publisher.Publish(context.Background(), run[1]); err != nil {
+		t.Errorf("Expected heartbeats to be skipped, got %v", err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected 1 reported error, got %v", reported)
+	}
+	if err := publisher.Close(); err == nil || !strings.Contains(err.Error(), "broker down") {
+		t.Errorf("Expected Close to return the error, got %v", err)
+	}
+}
+
+func TestEventJSONRoundTrip(t *testing.T) {
+	sink := &memorySink{}
+	publisher := New(sink)
+	for _, msg := range run {
+		publisher.Publish(context.Background(), msg)
+	}
+
+	for _, event := range sink.events {
+		data, err := JSON.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Event
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Time.Equal(event.Time) {
+			t.Errorf("Expected time %v, got %v", event.Time, decoded.Time)
+		}
+		decoded.Time = event.Time
+		if !reflect.DeepEqual(decoded, event) {
+			t.Errorf("Expected %#v, got %#v from %s", event, decoded, data)
+		}
+	}
+}
+
+type fakeProducer struct {
+	records []KafkaMessage
+	err     error
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, msg KafkaMessage) error {
+	p.records = append(p.records, msg)
+	return p.err
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &fakeProducer{}
+	publisher := New(NewKafkaSink(producer, "agent-events"))
+	publisher.Source = "worker-1"
+
+	publisher.Publish(context.Background(), &types.UserMessage{Content: "Hello"})
+	publisher.Publish(context.Background(), run[0])
+	if err := publisher.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(producer.records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(producer.records))
+	}
+	first, second := producer.records[0], producer.records[1]
+	if first.Topic != "agent-events" || string(first.Key) != "worker-1" || string(second.Key) != "s1" {
+		t.Errorf("Unexpected topics or keys: %+v, %+v", first, second)
+	}
+	expected := map[string]string{"message_type": "system", "source": "worker-1", "seq": "2", "content_type": "application/json"}
+	if !reflect.DeepEqual(second.Headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, second.Headers)
+	}
+
+	var event Event
+	if err := json.Unmarshal(first.Value, &event); err != nil {
+		t.Fatal(err)
+	}
+	if user, ok := event.Message.(*types.UserMessage); !ok || user.Content != "Hello" {
+		t.Errorf("Expected the user message, got %#v", event.Message)
+	}
+
+	producer.err = errors.New("not leader")
+	if err := publisher.Publish(context.Background(), run[2]); err == nil || !strings.Contains(err.Error(), "agent-events") {
+		t.Errorf("Expected a produce error naming the topic, got %v", err)
+	}
+}
+
+type fakeConn struct {
+	subjects []string
+	data     [][]byte
+}
+
+func (c *fakeConn) Publish(subject string, data []byte) error {
+	c.subjects = append(c.subjects, subject)
+	c.data = append(c.data, data)
+	return nil
+}
+
+func TestNATSSink(t *testing.T) {
+	conn := &fakeConn{}
+	sink := NewNATSSink(conn, "claude.events.")
+	sink.Encoding = Encoding{Marshal: func(event Event) ([]byte, error) {
+		return []byte(event.Message.Type()), nil
+	}}
+	publisher := New(sink)
+
+	for range publisher.Tee(messages(append(run, &types.UnknownMessage{MessageType: "a.b*"})...)) {
+	}
+	if err := publisher.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"claude.events.system", "claude.events.assistant", "claude.events.result", "claude.events.a_b_"}
+	if !reflect.DeepEqual(conn.subjects, expected) {
+		t.Errorf("Expected subjects %v, got %v", expected, conn.subjects)
+	}
+	if string(conn.data[1]) != "assistant" {
+		t.Errorf("Expected the custom encoding, got %s", conn.data[1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sink.Publish(ctx, Event{Message: run[0]}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled context to be reported, got %v", err)
+	}
+}
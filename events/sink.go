@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The sinks below publish through a small interface rather than a
+// particular client library, so that this package does not depend on one.
+// Pass the client you already use, or a few lines adapting it.
+
+// KafkaMessage is a record produced to a Kafka topic.
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+	Time    time.Time
+}
+
+// KafkaProducer produces records to Kafka. An adapter for
+// github.com/segmentio/kafka-go looks like:
+//
+//	type producer struct{ w *kafka.Writer }
+//
+//	func (p producer) Produce(ctx context.Context, msg events.KafkaMessage) error {
+//		record := kafka.Message{Topic: msg.Topic, Key: msg.Key, Value: msg.Value, Time: msg.Time}
+//		for k, v := range msg.Headers {
+//			record.Headers = append(record.Headers, kafka.Header{Key: k, Value: []byte(v)})
+//		}
+//		return p.w.WriteMessages(ctx, record)
+//	}
+type KafkaProducer interface {
+	Produce(ctx context.Context, msg KafkaMessage) error
+}
+
+// KafkaSink produces each event as a record on a topic. Records are keyed
+// by session ID, so that the events of a session land on one partition in
+// order; events before the session ID is known are keyed by source. The
+// headers carry the content type, message type, source, and sequence
+// number, so that consumers can filter without decoding the value.
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+
+	// Encoding encodes the events. If its Marshal function is nil, JSON
+	// is used.
+	Encoding Encoding
+}
+
+// NewKafkaSink returns a sink producing to topic with producer.
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic, Encoding: JSON}
+}
+
+// Publish produces event. It returns once the producer does, which for
+// batching producers may be before the broker acknowledges it.
+func (s *KafkaSink) Publish(ctx context.Context, event Event) error {
+	value, contentType, err := encode(s.Encoding, event)
+	if err != nil {
+		return err
+	}
+
+	key := event.SessionID
+	if key == "" {
+		key = event.Source
+	}
+	headers := map[string]string{
+		"message_type": event.Message.Type(),
+		"source":       event.Source,
+		"seq":          strconv.FormatInt(event.Seq, 10),
+	}
+	if contentType != "" {
+		headers["content_type"] = contentType
+	}
+
+	err = s.producer.Produce(ctx, KafkaMessage{
+		Topic:   s.topic,
+		Key:     []byte(key),
+		Value:   value,
+		Headers: headers,
+		Time:    event.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to produce event to %s: %w", s.topic, err)
+	}
+	return nil
+}
+
+// NATSPublisher publishes to NATS subjects. *nats.Conn from
+// github.com/nats-io/nats.go implements it.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes each event to a subject named after its message
+// type under a prefix, such as "claude.events.assistant" or
+// "claude.events.result", so that subscribers can choose the types they
+// receive: "claude.events.>" receives them all.
+type NATSSink struct {
+	conn   NATSPublisher
+	prefix string
+
+	// Encoding encodes the events. If its Marshal function is nil, JSON
+	// is used.
+	Encoding Encoding
+}
+
+// NewNATSSink returns a sink publishing with conn to subjects under
+// prefix.
+func NewNATSSink(conn NATSPublisher, prefix string) *NATSSink {
+	return &NATSSink{conn: conn, prefix: strings.TrimSuffix(prefix, "."), Encoding: JSON}
+}
+
+// Subject returns the subject event is published to.
+func (s *NATSSink) Subject(event Event) string {
+	return s.prefix + "." + subjectToken(event.Message.Type())
+}
+
+// subjectToken replaces the characters NATS does not allow in a subject
+// token, and its wildcards, in typ. Message types reported by the CLI are
+// already valid tokens.
+func subjectToken(typ string) string {
+	if typ == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '*' || r == '>' || r <= ' ' || r == 0x7f:
+			return '_'
+		default:
+			return r
+		}
+	}, typ)
+}
+
+// Publish publishes event. NATS core publishing does not wait for the
+// server, so ctx is only checked before publishing.
+func (s *NATSSink) Publish(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, _, err := encode(s.Encoding, event)
+	if err != nil {
+		return err
+	}
+
+	subject := s.Subject(event)
+	if err := s.conn.Publish(subject, data); err != nil {
+		return fmt.Errorf("failed to publish event to %s: %w", subject, err)
+	}
+	return nil
+}
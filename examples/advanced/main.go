@@ -156,9 +156,9 @@ func processAdvancedStream(stream *claudecode.QueryStream, ctx context.Context)
 						}
 					case *claudecode.ToolResultBlock:
 						if b.IsError != nil && *b.IsError {
-							fmt.Printf("Tool error for %s: %s\n", b.ToolUseID, safeStringValue(b.Content))
+							fmt.Printf("Tool error for %s: %s\n", b.ToolUseID, b.Text())
 						} else {
-							fmt.Printf("Tool result for %s: %s\n", b.ToolUseID, safeStringValue(b.Content))
+							fmt.Printf("Tool result for %s: %s\n", b.ToolUseID, b.Text())
 						}
 					}
 				}
@@ -217,10 +217,8 @@ func processStreamAndExtractSession(stream *claudecode.QueryStream, ctx context.
 
 			switch msg := message.(type) {
 			case *claudecode.AssistantMessage:
-				for _, block := range msg.Content {
-					if textBlock, ok := block.(*claudecode.TextBlock); ok {
-						fmt.Printf("Claude: %s\n", textBlock.Text)
-					}
+				if text := msg.Text(); text != "" {
+					fmt.Printf("Claude: %s\n", text)
 				}
 			case *claudecode.ResultMessage:
 				*sessionID = msg.SessionID
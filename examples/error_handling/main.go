@@ -98,10 +98,8 @@ func streamErrorHandlingExample() {
 
 			switch msg := message.(type) {
 			case *claudecode.AssistantMessage:
-				for _, block := range msg.Content {
-					if textBlock, ok := block.(*claudecode.TextBlock); ok {
-						fmt.Printf("Claude: %s\n", textBlock.Text)
-					}
+				if text := msg.Text(); text != "" {
+					fmt.Printf("Claude: %s\n", text)
 				}
 			case *claudecode.ResultMessage:
 				if msg.IsError {
@@ -119,7 +117,7 @@ func streamErrorHandlingExample() {
 			fmt.Printf("Stream error #%d: %v\n", errorCount, err)
 
 			// Demonstrate error classification and handling
-			if shouldRetry(err) && errorCount < maxErrors {
+			if claudecode.IsRetryable(err) && errorCount < maxErrors {
 				fmt.Println("Error appears recoverable, continuing...")
 				continue
 			}
@@ -170,47 +168,33 @@ func resourceCleanupExample() {
 }
 
 func robustQueryExample() {
-	fmt.Println("=== Robust Query with Retry Logic ===")
-
-	maxRetries := 3
-	baseTimeout := 5 * time.Second
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		fmt.Printf("Attempt %d/%d\n", attempt, maxRetries)
-
-		// Increase timeout on each retry
-		timeout := baseTimeout * time.Duration(attempt)
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-
-		success, err := attemptQuery(ctx, "What's the weather like?")
-		cancel()
-
-		if success {
-			fmt.Println("Query succeeded!")
-			return
-		}
-
-		if err != nil {
-			fmt.Printf("Attempt %d failed: %v\n", attempt, err)
-
-			if !shouldRetry(err) {
-				fmt.Println("Error is not retriable, stopping")
-				return
-			}
+	fmt.Println("=== Robust Query with Retry Policy ===")
+
+	// The client retries CLI failures that happen before any output,
+	// starting a fresh process for each attempt
+	options := claudecode.NewOptions().WithRetryPolicy(claudecode.RetryPolicy{
+		MaxAttempts:         3,
+		Backoff:             claudecode.ExponentialBackoff(time.Second, 10*time.Second),
+		RetryableClassifier: claudecode.IsRetryable,
+		OnRetry: func(event claudecode.RetryEvent) {
+			fmt.Printf("Attempt %d failed (%v), retrying in %v...\n", event.Attempt-1, event.Err, event.Delay)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
 
-			if attempt < maxRetries {
-				waitTime := time.Duration(attempt) * time.Second
-				fmt.Printf("Waiting %v before retry...\n", waitTime)
-				time.Sleep(waitTime)
-			}
-		}
+	success, err := attemptQuery(ctx, "What's the weather like?", options)
+	if success {
+		fmt.Println("Query succeeded!")
+		return
 	}
 
-	fmt.Printf("All %d attempts failed\n", maxRetries)
+	fmt.Printf("Query failed: %v\n", err)
 }
 
-func attemptQuery(ctx context.Context, prompt string) (bool, error) {
-	stream, err := claudecode.Query(ctx, prompt, nil)
+func attemptQuery(ctx context.Context, prompt string, options *claudecode.Options) (bool, error) {
+	stream, err := claudecode.Query(ctx, prompt, options)
 	if err != nil {
 		return false, err
 	}
@@ -288,56 +272,20 @@ func handleStreamError(err error) {
 	fmt.Printf("Stream error: %v\n", err)
 
 	// Classify and handle stream errors
-	switch {
-	case isTransientError(err):
+	switch claudecode.ErrorKind(err) {
+	case claudecode.KindConnection, claudecode.KindProcess, claudecode.KindTimeout:
 		fmt.Println("This appears to be a transient error")
-	case isConfigurationError(err):
+	case claudecode.KindRateLimit:
+		fmt.Println("Rate limited, try again later")
+	case claudecode.KindCLINotFound, claudecode.KindUsage:
 		fmt.Println("This appears to be a configuration error")
-	case isPermissionError(err):
+	case claudecode.KindAuth, claudecode.KindPermissionDenied:
 		fmt.Println("This appears to be a permission error")
 	default:
 		fmt.Println("Unknown stream error type")
 	}
 }
 
-func shouldRetry(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// Don't retry certain types of errors
-	if isConfigurationError(err) || isPermissionError(err) {
-		return false
-	}
-
-	// Retry transient errors and timeouts
-	return isTransientError(err) || errors.Is(err, context.DeadlineExceeded)
-}
-
-func isTransientError(err error) bool {
-	errStr := err.Error()
-	return contains(errStr, "timeout") ||
-		contains(errStr, "connection refused") ||
-		contains(errStr, "temporary failure") ||
-		contains(errStr, "service unavailable")
-}
-
-func isConfigurationError(err error) bool {
-	errStr := err.Error()
-	return contains(errStr, "CLI not found") ||
-		contains(errStr, "invalid argument") ||
-		contains(errStr, "unknown option") ||
-		contains(errStr, "invalid configuration")
-}
-
-func isPermissionError(err error) bool {
-	errStr := err.Error()
-	return contains(errStr, "permission denied") ||
-		contains(errStr, "access denied") ||
-		contains(errStr, "unauthorized") ||
-		contains(errStr, "forbidden")
-}
-
 func safeStringValue(s *string) string {
 	if s == nil {
 		return "<nil>"
@@ -345,24 +293,6 @@ func safeStringValue(s *string) string {
 	return *s
 }
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && 
-		(s == substr || 
-		 (len(s) > len(substr) && 
-		  (s[:len(substr)] == substr || 
-		   s[len(s)-len(substr):] == substr || 
-		   findSubstring(s, substr))))
-}
-
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
 func main() {
 	examples := []struct {
 		name string
@@ -36,10 +36,8 @@ func basicExample() error {
 
 			switch msg := message.(type) {
 			case *claudecode.AssistantMessage:
-				for _, block := range msg.Content {
-					if textBlock, ok := block.(*claudecode.TextBlock); ok {
-						fmt.Printf("Claude: %s\n", textBlock.Text)
-					}
+				if text := msg.Text(); text != "" {
+					fmt.Printf("Claude: %s\n", text)
 				}
 			case *claudecode.ResultMessage:
 				if msg.TotalCostUSD != nil && *msg.TotalCostUSD > 0 {
@@ -88,10 +86,8 @@ func withOptionsExample() error {
 
 			switch msg := message.(type) {
 			case *claudecode.AssistantMessage:
-				for _, block := range msg.Content {
-					if textBlock, ok := block.(*claudecode.TextBlock); ok {
-						fmt.Printf("Claude: %s\n", textBlock.Text)
-					}
+				if text := msg.Text(); text != "" {
+					fmt.Printf("Claude: %s\n", text)
 				}
 			case *claudecode.ResultMessage:
 				if msg.TotalCostUSD != nil && *msg.TotalCostUSD > 0 {
@@ -140,10 +136,8 @@ func withToolsExample() error {
 
 			switch msg := message.(type) {
 			case *claudecode.AssistantMessage:
-				for _, block := range msg.Content {
-					if textBlock, ok := block.(*claudecode.TextBlock); ok {
-						fmt.Printf("Claude: %s\n", textBlock.Text)
-					}
+				if text := msg.Text(); text != "" {
+					fmt.Printf("Claude: %s\n", text)
 				}
 			case *claudecode.ResultMessage:
 				if msg.TotalCostUSD != nil && *msg.TotalCostUSD > 0 {
@@ -0,0 +1,74 @@
+// Package ghactions reports Claude Code runs in GitHub Actions: as
+// workflow annotations, which appear on the run and the pull request, and
+// as a Markdown job summary with the run's result, cost, and duration.
+//
+// A Reporter reads a transcript of the run:
+//
+//	tr := transcript.New(prompt)
+//	for msg := range tr.Tee(stream.Messages()) {
+//		// ...
+//	}
+//	err := ghactions.NewReporter("Fix the build", tr).Publish()
+package ghactions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of an annotation.
+type Level string
+
+const (
+	// LevelError marks a failure.
+	LevelError Level = "error"
+
+	// LevelWarning marks a problem that did not fail the run.
+	LevelWarning Level = "warning"
+
+	// LevelNotice marks information.
+	LevelNotice Level = "notice"
+)
+
+// Annotation is a message GitHub Actions shows on the workflow run, and on
+// the file and line when they are set.
+type Annotation struct {
+	Level   Level
+	Title   string
+	Message string
+
+	// File is relative to the repository root; Line is 1-based.
+	File string
+	Line int
+}
+
+// String returns the annotation as a workflow command, such as
+// "::error title=Claude::Exceeded max turns".
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+		if a.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", a.Line))
+		}
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+
+	cmd := "::" + string(a.Level)
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// escapeData escapes a workflow command's message.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command's property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
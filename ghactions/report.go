@@ -0,0 +1,261 @@
+package ghactions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// maxMessage limits the length of annotation messages taken from tool
+// output; GitHub truncates longer ones.
+const maxMessage = 4096
+
+// Reporter converts the transcript of a run into annotations and a job
+// summary.
+type Reporter struct {
+	// Title names the run in the summary heading and annotation titles.
+	Title string
+
+	// IncludeTranscript adds the full transcript to the summary in a
+	// collapsed section.
+	IncludeTranscript bool
+
+	// MaxTranscriptBytes, if positive, truncates the included transcript;
+	// GitHub rejects summaries over 1 MiB.
+	MaxTranscriptBytes int
+
+	transcript *transcript.Transcript
+}
+
+// NewReporter creates a reporter for the run recorded in tr. If title is
+// empty, "Claude" is used.
+func NewReporter(title string, tr *transcript.Transcript) *Reporter {
+	if title == "" {
+		title = "Claude"
+	}
+	return &Reporter{Title: title, transcript: tr}
+}
+
+// Annotations returns an annotation for each failed tool call, denied
+// permission, and warning in the run, and one for how it ended: an error
+// if it failed or ended without a result, and a notice with its cost and
+// duration otherwise.
+func (r *Reporter) Annotations() []Annotation {
+	var annotations []Annotation
+	toolNames := make(map[string]string)
+
+	for _, msg := range r.transcript.Messages() {
+		switch m := msg.(type) {
+		case *types.AssistantMessage:
+			for _, block := range m.Content {
+				if use, ok := block.(*types.ToolUseBlock); ok {
+					toolNames[use.ID] = use.Name
+				}
+			}
+
+		case *types.UserMessage:
+			for _, block := range m.Blocks {
+				if result, ok := block.(*types.ToolResultBlock); ok && result.Failed() {
+					annotations = append(annotations, Annotation{
+						Level:   LevelWarning,
+						Title:   fmt.Sprintf("%s: %s failed", r.Title, toolName(toolNames, result.ToolUseID)),
+						Message: truncate(resultText(result), maxMessage),
+					})
+				}
+			}
+
+		case *types.SystemMessage:
+			if m.Subtype == types.SystemSubtypeWarning {
+				annotations = append(annotations, Annotation{
+					Level:   LevelWarning,
+					Title:   r.Title,
+					Message: fmt.Sprint(m.Data["message"]),
+				})
+			}
+		}
+	}
+
+	result := r.transcript.Result()
+	if result == nil {
+		return append(annotations, Annotation{Level: LevelError, Title: r.Title, Message: "Run ended without a result"})
+	}
+
+	for _, denial := range result.PermissionDenials {
+		annotations = append(annotations, Annotation{
+			Level:   LevelWarning,
+			Title:   r.Title,
+			Message: fmt.Sprintf("Permission denied for %s", denial.ToolName),
+		})
+	}
+
+	if result.IsError {
+		message := result.Subtype
+		if result.Result != nil && *result.Result != "" {
+			message = truncate(*result.Result, maxMessage)
+		}
+		return append(annotations, Annotation{Level: LevelError, Title: r.Title + " failed", Message: message})
+	}
+	return append(annotations, Annotation{Level: LevelNotice, Title: r.Title, Message: "Completed: " + strings.Join(resultFacts(result), ", ")})
+}
+
+// WriteAnnotations writes the annotations as workflow commands, one per
+// line, for the runner to read from a step's output.
+func (r *Reporter) WriteAnnotations(w io.Writer) error {
+	for _, a := range r.Annotations() {
+		if _, err := fmt.Fprintln(w, a.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary returns the job summary as Markdown: the outcome, a table of
+// duration, cost, turns, and tokens, the final result text, the files
+// changed, and optionally the transcript.
+func (r *Reporter) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", r.Title)
+
+	result := r.transcript.Result()
+	if result == nil {
+		b.WriteString("**Status:** ended without a result\n")
+	} else {
+		writeResultTable(&b, result)
+		if result.Result != nil && strings.TrimSpace(*result.Result) != "" {
+			fmt.Fprintf(&b, "\n### Result\n\n%s\n", strings.TrimSpace(*result.Result))
+		}
+	}
+
+	changes := claudecode.NewChangeTracker()
+	for _, msg := range r.transcript.Messages() {
+		changes.Observe(msg)
+	}
+	if files := changes.Changes(); len(files) > 0 {
+		b.WriteString("\n### Changed files\n\n")
+		for _, f := range files {
+			fmt.Fprintf(&b, "- `%s` (%s)\n", f.Path, f.Op)
+		}
+	}
+
+	if r.IncludeTranscript {
+		md := r.transcript.Markdown()
+		if r.MaxTranscriptBytes > 0 && len(md) > r.MaxTranscriptBytes {
+			md = truncate(md, r.MaxTranscriptBytes) + "\n\n_Transcript truncated_"
+		}
+		fmt.Fprintf(&b, "\n<details>\n<summary>Transcript</summary>\n\n%s\n\n</details>\n", strings.TrimSpace(md))
+	}
+
+	return b.String()
+}
+
+// writeResultTable writes the outcome and usage of a run.
+func writeResultTable(b *strings.Builder, result *types.ResultMessage) {
+	status := "Succeeded"
+	if result.IsError {
+		status = "Failed (" + result.Subtype + ")"
+	}
+
+	usage := claudecode.NewUsageTracker()
+	usage.Record(result)
+	totals := usage.Snapshot()
+
+	b.WriteString("| | |\n| --- | --- |\n")
+	fmt.Fprintf(b, "| Status | %s |\n", status)
+	fmt.Fprintf(b, "| Duration | %s |\n", duration(result.DurationMs))
+	if result.TotalCostUSD != nil {
+		fmt.Fprintf(b, "| Cost | $%.4f |\n", *result.TotalCostUSD)
+	}
+	fmt.Fprintf(b, "| Turns | %d |\n", result.NumTurns)
+	if totals.InputTokens > 0 || totals.OutputTokens > 0 {
+		fmt.Fprintf(b, "| Tokens | %d in, %d out, %d cache read |\n", totals.InputTokens, totals.OutputTokens, totals.CacheReadInputTokens)
+	}
+	if result.SessionID != "" {
+		fmt.Fprintf(b, "| Session | `%s` |\n", result.SessionID)
+	}
+}
+
+// WriteSummary writes the job summary.
+func (r *Reporter) WriteSummary(w io.Writer) error {
+	_, err := io.WriteString(w, r.Summary())
+	return err
+}
+
+// Publish writes the annotations to standard output and, when running in
+// GitHub Actions, appends the summary to the file named by
+// GITHUB_STEP_SUMMARY.
+func (r *Reporter) Publish() error {
+	return r.publish(os.Stdout)
+}
+
+// publish is Publish, writing the annotations to stdout.
+func (r *Reporter) publish(stdout io.Writer) error {
+	if err := r.WriteAnnotations(stdout); err != nil {
+		return err
+	}
+
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteSummary(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resultFacts describes the cost and length of a run.
+func resultFacts(result *types.ResultMessage) []string {
+	facts := []string{fmt.Sprintf("%d turns", result.NumTurns), duration(result.DurationMs)}
+	if result.TotalCostUSD != nil {
+		facts = append(facts, fmt.Sprintf("$%.4f", *result.TotalCostUSD))
+	}
+	return facts
+}
+
+// duration formats a duration in milliseconds to the second.
+func duration(ms int) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return d.String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// toolName returns the name of the tool a result belongs to, or its ID if
+// the call was not seen.
+func toolName(names map[string]string, id string) string {
+	if name := names[id]; name != "" {
+		return name
+	}
+	return id
+}
+
+// resultText returns the text of a tool result.
+func resultText(block *types.ToolResultBlock) string {
+	var parts []string
+	for _, part := range block.Content {
+		if text, ok := part.(*types.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// truncate shortens s to at most n bytes, marking the cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "…"
+}
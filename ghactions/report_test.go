@@ -0,0 +1,136 @@
+package ghactions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func runTranscript(result *types.ResultMessage) *transcript.Transcript {
+	failed := true
+	tr := transcript.New("Fix the build")
+	for _, msg := range []types.Message{
+		&types.SystemMessage{Subtype: types.SystemSubtypeWarning, Data: map[string]any{"message": "stderr line dropped"}},
+		&types.AssistantMessage{Content: []types.ContentBlock{
+			&types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]any{"command": "make"}},
+			&types.ToolUseBlock{ID: "t2", Name: "Write", Input: map[string]any{"file_path": "main.go", "content": "package main\n"}},
+		}},
+		&types.UserMessage{Content: "2 tool results", Blocks: []types.ContentBlock{
+			&types.ToolResultBlock{ToolUseID: "t1", Content: []types.ToolResultContent{&types.TextContent{Text: "make: *** [all] Error 1\n50% done"}}, IsError: &failed},
+			&types.ToolResultBlock{ToolUseID: "t2", Content: []types.ToolResultContent{&types.TextContent{Text: "ok"}}},
+		}},
+	} {
+		tr.Add(msg)
+	}
+	if result != nil {
+		tr.Add(result)
+	}
+	return tr
+}
+
+func TestAnnotations(t *testing.T) {
+	cost := 0.0123
+	text := "Fixed the build"
+	tests := []struct {
+		name   string
+		result *types.ResultMessage
+		last   string
+	}{
+		{
+			name:   "success",
+			result: &types.ResultMessage{Subtype: "success", NumTurns: 3, DurationMs: 61_400, TotalCostUSD: &cost, Result: &text},
+			last:   "::notice title=CI fix::Completed: 3 turns, 1m1s, $0.0123",
+		},
+		{
+			name:   "failure",
+			result: &types.ResultMessage{Subtype: "error_max_turns", IsError: true},
+			last:   "::error title=CI fix failed::error_max_turns",
+		},
+		{
+			name: "no result",
+			last: "::error title=CI fix::Run ended without a result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := NewReporter("CI fix", runTranscript(tt.result)).WriteAnnotations(&b); err != nil {
+				t.Fatalf("WriteAnnotations failed: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+
+			want := []string{
+				"::warning title=CI fix::stderr line dropped",
+				"::warning title=CI fix%3A Bash failed::make: *** [all] Error 1%0A50%25 done",
+				tt.last,
+			}
+			if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+				t.Errorf("Expected annotations:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+func TestAnnotationFileProperties(t *testing.T) {
+	a := Annotation{Level: LevelWarning, File: "a,b.go", Line: 3, Title: "t", Message: "m"}
+	if got := a.String(); got != "::warning file=a%2Cb.go,line=3,title=t::m" {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	cost := 0.5
+	text := "Fixed the build"
+	result := &types.ResultMessage{
+		Subtype: "success", NumTurns: 2, DurationMs: 1500, TotalCostUSD: &cost, SessionID: "s1", Result: &text,
+		Usage: map[string]any{"input_tokens": float64(100), "output_tokens": float64(20)},
+	}
+	reporter := NewReporter("", runTranscript(result))
+	reporter.IncludeTranscript = true
+
+	summary := reporter.Summary()
+	for _, want := range []string{
+		"## Claude\n",
+		"| Status | Succeeded |",
+		"| Duration | 2s |",
+		"| Cost | $0.5000 |",
+		"| Tokens | 100 in, 20 out, 0 cache read |",
+		"| Session | `s1` |",
+		"### Result\n\nFixed the build\n",
+		"### Changed files\n\n- `main.go` (modified)\n",
+		"<details>\n<summary>Transcript</summary>\n\n# Transcript",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestPublishAppendsSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("earlier step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	var stdout strings.Builder
+	if err := NewReporter("Run", runTranscript(&types.ResultMessage{Subtype: "success"})).publish(&stdout); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "::notice title=Run::") {
+		t.Errorf("Expected annotations on stdout, got:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "earlier step\n## Run\n") {
+		t.Errorf("Expected the summary to be appended, got:\n%s", data)
+	}
+}
@@ -0,0 +1,118 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+)
+
+// ErrNothingToCommit is returned by Commit when there are no changes.
+var ErrNothingToCommit = errors.New("gitops: nothing to commit")
+
+// CommitOptions configures Commit.
+type CommitOptions struct {
+	// Branch, if set, is created at the current commit and checked out
+	// before committing, carrying the working tree's changes with it. It
+	// must not already exist.
+	Branch string
+
+	// Subject is the first line of the commit message. It is required.
+	Subject string
+
+	// Body, if set, follows the subject.
+	Body string
+
+	// Transcript, if set, is appended to the message as Markdown, so the
+	// commit records how the change was made.
+	Transcript *transcript.Transcript
+
+	// MaxTranscriptBytes, if positive, truncates the transcript to this
+	// many bytes.
+	MaxTranscriptBytes int
+
+	// Paths, if set, limits the commit to these files, such as a Diff's
+	// Paths. Otherwise every change in the working tree is committed,
+	// including untracked files that are not ignored.
+	Paths []string
+
+	// Author, if set, overrides the commit author, as "Name <email>".
+	Author string
+}
+
+// Message returns the commit message the options describe.
+func (o CommitOptions) Message() string {
+	parts := []string{strings.TrimSpace(o.Subject)}
+	if body := strings.TrimSpace(o.Body); body != "" {
+		parts = append(parts, body)
+	}
+	if o.Transcript != nil {
+		parts = append(parts, truncate(strings.TrimSpace(o.Transcript.Markdown()), o.MaxTranscriptBytes))
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// truncate shortens text to at most max bytes, if max is positive, on a
+// line boundary where possible.
+func truncate(text string, max int) string {
+	const marker = "\n\n[transcript truncated]"
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+
+	cut := max - len(marker)
+	if cut <= 0 {
+		return strings.TrimSpace(marker)
+	}
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if nl := strings.LastIndexByte(text[:cut], '\n'); nl > 0 {
+		cut = nl
+	}
+	return strings.TrimRight(text[:cut], "\n") + marker
+}
+
+// Commit commits the working tree's changes, on a new branch if
+// opts.Branch is set, and returns the new commit. Changes staged before
+// the call are included unless opts.Paths limits the commit. It fails with
+// ErrNothingToCommit if there are no changes to commit.
+func (r *Repo) Commit(ctx context.Context, opts CommitOptions) (string, error) {
+	if strings.TrimSpace(opts.Subject) == "" {
+		return "", fmt.Errorf("gitops: commit subject is required")
+	}
+
+	if opts.Branch != "" {
+		if _, err := r.git(ctx, nil, "switch", "--create", opts.Branch); err != nil {
+			return "", err
+		}
+	}
+
+	add := append([]string{"add", "--all", "--"}, opts.Paths...)
+	if _, err := r.git(ctx, nil, add...); err != nil {
+		return "", err
+	}
+
+	staged := append([]string{"diff", "--cached", "--quiet", "--"}, opts.Paths...)
+	if _, err := r.git(ctx, nil, staged...); err == nil {
+		return "", ErrNothingToCommit
+	} else if !isExit(err, 1) {
+		return "", err
+	}
+
+	commit := []string{"commit", "--quiet", "--file=-"}
+	if opts.Author != "" {
+		commit = append(commit, "--author="+opts.Author)
+	}
+	if len(opts.Paths) > 0 {
+		commit = append(append(commit, "--"), opts.Paths...)
+	}
+	if _, err := r.gitInput(ctx, nil, opts.Message(), commit...); err != nil {
+		return "", err
+	}
+
+	return r.Head(ctx)
+}
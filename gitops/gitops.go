@@ -0,0 +1,138 @@
+// Package gitops helps automation that lets Claude edit a git repository:
+// snapshot the working tree before a query, see what the query changed,
+// and commit the changes on a branch with the run's transcript in the
+// commit message.
+//
+// A typical CI job:
+//
+//	repo, err := gitops.Open(ctx, dir)
+//	before, err := repo.Snapshot(ctx)
+//
+//	tr := transcript.New(prompt)
+//	stream, err := claudecode.Query(ctx, prompt, claudecode.NewOptions().WithCwd(repo.Dir()))
+//	for msg := range tr.Tee(stream.Messages()) {
+//		// ...
+//	}
+//
+//	diff, err := repo.Diff(ctx, before)
+//	if !diff.Empty() {
+//		commit, err := repo.Commit(ctx, gitops.CommitOptions{
+//			Branch:     "claude/fix-build",
+//			Subject:    "Fix the build",
+//			Transcript: tr,
+//		})
+//	}
+//
+// Commands run the git binary found on PATH.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Error is returned when a git command fails.
+type Error struct {
+	// Args are the arguments git was run with.
+	Args []string
+
+	// Stderr is what git wrote to standard error.
+	Stderr string
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := strings.TrimSpace(e.Stderr)
+	if msg == "" {
+		msg = e.Err.Error()
+	}
+	return fmt.Sprintf("gitops: git %s: %s", strings.Join(e.Args, " "), msg)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Repo is a git working tree.
+type Repo struct {
+	dir string
+}
+
+// Open returns the repository containing dir, which may be a
+// subdirectory of its working tree.
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	top, err := (&Repo{dir: dir}).git(ctx, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{dir: strings.TrimSpace(top)}, nil
+}
+
+// Dir returns the top-level directory of the working tree.
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Head returns the commit checked out, or "" in a repository with no
+// commits yet.
+func (r *Repo) Head(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, nil, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		if isExit(err, 1) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Branch returns the name of the branch checked out, or "" if HEAD is
+// detached.
+func (r *Repo) Branch(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		if isExit(err, 1) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// git runs a git command in the working tree and returns its output. env
+// is added to the environment.
+func (r *Repo) git(ctx context.Context, env []string, args ...string) (string, error) {
+	return r.gitInput(ctx, env, "", args...)
+}
+
+// gitInput runs a git command with input on its standard input.
+func (r *Repo) gitInput(ctx context.Context, env []string, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", &Error{Args: args, Stderr: stderr.String(), Err: err}
+	}
+	return stdout.String(), nil
+}
+
+// isExit reports whether err is a git command exiting with code.
+func isExit(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
@@ -0,0 +1,212 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// newRepo creates a repository with one commit holding the given files.
+func newRepo(t *testing.T, files map[string]string) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"add", "--all"},
+		{"commit", "--quiet", "--message=initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	repo, err := Open(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return repo
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t, map[string]string{
+		"main.go":    "package main\n",
+		"old.txt":    "old\n",
+		".gitignore": "*.log\n",
+	})
+
+	before, err := repo.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if before.Head == "" {
+		t.Error("Expected the snapshot to record HEAD")
+	}
+
+	writeFiles(t, repo.Dir(), map[string]string{
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"pkg/new.go": "package pkg\n",
+		"debug.log":  "ignored\n",
+	})
+	os.Remove(filepath.Join(repo.Dir(), "old.txt"))
+
+	diff, err := repo.Diff(ctx, before)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	var got []string
+	for _, f := range diff.Files {
+		got = append(got, f.Path+":"+string(f.Op))
+	}
+	want := "main.go:modified old.txt:deleted pkg/new.go:created"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected changes %q, got %q", want, strings.Join(got, " "))
+	}
+	if !strings.Contains(diff.Patch, "+func main() {}") {
+		t.Errorf("Patch missing the edit:\n%s", diff.Patch)
+	}
+
+	// The user's index is untouched
+	status, err := repo.git(ctx, nil, "diff", "--cached", "--name-only")
+	if err != nil || status != "" {
+		t.Errorf("Expected nothing staged, got %q (%v)", status, err)
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t, map[string]string{"a.txt": "a\n"})
+	writeFiles(t, repo.Dir(), map[string]string{"untracked.txt": "u\n"})
+
+	before, err := repo.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	diff, err := repo.Diff(ctx, before)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !diff.Empty() || diff.Patch != "" {
+		t.Errorf("Expected an empty diff, got %+v", diff)
+	}
+}
+
+func TestCommitOnBranchWithTranscript(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+	writeFiles(t, repo.Dir(), map[string]string{"a.txt": "changed\n", "b.txt": "also changed\n"})
+
+	tr := transcript.New("Fix a.txt")
+	tr.Add(&types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "Fixed."}}})
+
+	commit, err := repo.Commit(ctx, CommitOptions{
+		Branch:     "claude/fix",
+		Subject:    "Fix a.txt",
+		Transcript: tr,
+		Paths:      []string{"a.txt"},
+		Author:     "Claude <claude@example.com>",
+	})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if branch, _ := repo.Branch(ctx); branch != "claude/fix" {
+		t.Errorf("Expected to be on claude/fix, got %q", branch)
+	}
+	if head, _ := repo.Head(ctx); head != commit {
+		t.Errorf("Expected HEAD %s, got %s", commit, head)
+	}
+
+	message, err := repo.git(ctx, nil, "log", "-1", "--format=%an%n%B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Claude\nFix a.txt\n\n# Transcript", "## User\n\nFix a.txt", "## Assistant\n\nFixed."} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Commit message missing %q:\n%s", want, message)
+		}
+	}
+
+	files, _ := repo.git(ctx, nil, "show", "--name-only", "--format=", commit)
+	if strings.TrimSpace(files) != "a.txt" {
+		t.Errorf("Expected only a.txt committed, got %q", files)
+	}
+
+	if _, err := repo.Commit(ctx, CommitOptions{Subject: "Again", Paths: []string{"a.txt"}}); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Expected ErrNothingToCommit, got %v", err)
+	}
+}
+
+func TestCommitDeletedFile(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t, map[string]string{"a.txt": "a\n", "gone.txt": "x\n"})
+	before, _ := repo.Snapshot(ctx)
+	os.Remove(filepath.Join(repo.Dir(), "gone.txt"))
+
+	diff, err := repo.Diff(ctx, before)
+	if err != nil || len(diff.Files) != 1 || diff.Files[0].Op != claudecode.ChangeDeleted {
+		t.Fatalf("Unexpected diff %+v (%v)", diff, err)
+	}
+	if _, err := repo.Commit(ctx, CommitOptions{Subject: "Remove gone.txt", Paths: diff.Paths()}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+}
+
+func TestCommitMessageTruncatesTranscript(t *testing.T) {
+	tr := transcript.New(strings.Repeat("line\n", 1000))
+	msg := CommitOptions{Subject: "Subject", Body: "Body", Transcript: tr, MaxTranscriptBytes: 200}.Message()
+
+	if !strings.HasPrefix(msg, "Subject\n\nBody\n\n# Transcript") {
+		t.Errorf("Unexpected message start:\n%s", msg)
+	}
+	if !strings.HasSuffix(msg, "[transcript truncated]\n") || len(msg) > 220 {
+		t.Errorf("Expected a truncated transcript, got %d bytes:\n%s", len(msg), msg)
+	}
+}
+
+func TestGitError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	_, err := Open(context.Background(), t.TempDir())
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || !strings.Contains(err.Error(), "gitops: git rev-parse --show-toplevel: fatal:") {
+		t.Errorf("Expected a git error, got %v", err)
+	}
+}
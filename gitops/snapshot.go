@@ -0,0 +1,162 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// Snapshot is the state of a working tree at one moment: its commit and
+// the content of every file, including uncommitted and untracked files
+// that are not ignored. Taking a snapshot does not change the working
+// tree, the index, or any ref.
+type Snapshot struct {
+	// Head is the commit checked out, or "" if there were no commits.
+	Head string
+
+	// Tree is the git tree object holding the working tree's content.
+	Tree string
+}
+
+// Snapshot records the current state of the working tree.
+func (r *Repo) Snapshot(ctx context.Context) (*Snapshot, error) {
+	head, err := r.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Head: head, Tree: tree}, nil
+}
+
+// writeTree writes the working tree's content as a tree object, using a
+// scratch copy of the index so that the real one is left alone.
+func (r *Repo) writeTree(ctx context.Context) (string, error) {
+	scratch, err := os.MkdirTemp("", "gitops-index-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(scratch)
+
+	index := filepath.Join(scratch, "index")
+	if err := r.copyIndex(ctx, index); err != nil {
+		return "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := r.git(ctx, env, "add", "--all"); err != nil {
+		return "", err
+	}
+	tree, err := r.git(ctx, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// copyIndex copies the repository's index to path, if it has one, so
+// that git can reuse its record of unchanged files.
+func (r *Repo) copyIndex(ctx context.Context, path string) error {
+	out, err := r.git(ctx, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(strings.TrimSpace(out))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Diff is the difference between a snapshot and the working tree.
+type Diff struct {
+	// From and To are the snapshots compared.
+	From, To *Snapshot
+
+	// Files lists the changed files in path order. A renamed file is
+	// listed as deleted at its old path and created at its new one.
+	Files []FileChange
+
+	// Patch holds the changes as a git patch, including binary files, that
+	// git apply accepts.
+	Patch string
+}
+
+// FileChange is a file changed between two snapshots.
+type FileChange struct {
+	// Path is relative to the top of the working tree.
+	Path string
+
+	Op claudecode.ChangeOp
+}
+
+// Empty reports whether nothing changed.
+func (d *Diff) Empty() bool {
+	return len(d.Files) == 0
+}
+
+// Paths returns the paths of the changed files.
+func (d *Diff) Paths() []string {
+	paths := make([]string, len(d.Files))
+	for i, f := range d.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// Diff snapshots the working tree and compares it with since, typically a
+// snapshot taken before a query ran.
+func (r *Repo) Diff(ctx context.Context, since *Snapshot) (*Diff, error) {
+	now, err := r.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Compare(ctx, since, now)
+}
+
+// Compare returns the difference between two snapshots.
+func (r *Repo) Compare(ctx context.Context, from, to *Snapshot) (*Diff, error) {
+	diff := &Diff{From: from, To: to}
+	if from.Tree == to.Tree {
+		return diff, nil
+	}
+
+	status, err := r.git(ctx, nil, "diff-tree", "-r", "-z", "--no-renames", "--name-status", from.Tree, to.Tree)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSuffix(status, "\x00"), "\x00")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("gitops: unexpected diff-tree output %q", status)
+	}
+	for i := 0; i < len(fields); i += 2 {
+		diff.Files = append(diff.Files, FileChange{Path: fields[i+1], Op: changeOp(fields[i])})
+	}
+
+	diff.Patch, err = r.git(ctx, nil, "diff-tree", "-p", "--binary", "--no-renames", from.Tree, to.Tree)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// changeOp converts a git status letter.
+func changeOp(status string) claudecode.ChangeOp {
+	switch status {
+	case "A":
+		return claudecode.ChangeCreated
+	case "D":
+		return claudecode.ChangeDeleted
+	}
+	return claudecode.ChangeModified
+}
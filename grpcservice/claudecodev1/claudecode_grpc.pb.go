@@ -0,0 +1,170 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: claudecode/v1/claudecode.proto
+
+package claudecodev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClaudeCode_Query_FullMethodName   = "/claudecode.v1.ClaudeCode/Query"
+	ClaudeCode_Session_FullMethodName = "/claudecode.v1.ClaudeCode/Session"
+)
+
+// ClaudeCodeClient is the client API for ClaudeCode service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClaudeCode runs Claude Code queries and sessions.
+type ClaudeCodeClient interface {
+	// Query runs a single prompt and streams its messages and errors. The
+	// stream ends when the query finishes.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// Session runs an interactive session. The first request must be start;
+	// each prompt then begins a turn, which ends with a "result" message.
+	// Closing the request stream ends the session.
+	Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SessionRequest, Event], error)
+}
+
+type claudeCodeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClaudeCodeClient(cc grpc.ClientConnInterface) ClaudeCodeClient {
+	return &claudeCodeClient{cc}
+}
+
+func (c *claudeCodeClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClaudeCode_ServiceDesc.Streams[0], ClaudeCode_Query_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[QueryRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_QueryClient = grpc.ServerStreamingClient[Event]
+
+func (c *claudeCodeClient) Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SessionRequest, Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClaudeCode_ServiceDesc.Streams[1], ClaudeCode_Session_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SessionRequest, Event]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_SessionClient = grpc.BidiStreamingClient[SessionRequest, Event]
+
+// ClaudeCodeServer is the server API for ClaudeCode service.
+// All implementations must embed UnimplementedClaudeCodeServer
+// for forward compatibility.
+//
+// ClaudeCode runs Claude Code queries and sessions.
+type ClaudeCodeServer interface {
+	// Query runs a single prompt and streams its messages and errors. The
+	// stream ends when the query finishes.
+	Query(*QueryRequest, grpc.ServerStreamingServer[Event]) error
+	// Session runs an interactive session. The first request must be start;
+	// each prompt then begins a turn, which ends with a "result" message.
+	// Closing the request stream ends the session.
+	Session(grpc.BidiStreamingServer[SessionRequest, Event]) error
+	mustEmbedUnimplementedClaudeCodeServer()
+}
+
+// UnimplementedClaudeCodeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClaudeCodeServer struct{}
+
+func (UnimplementedClaudeCodeServer) Query(*QueryRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedClaudeCodeServer) Session(grpc.BidiStreamingServer[SessionRequest, Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedClaudeCodeServer) mustEmbedUnimplementedClaudeCodeServer() {}
+func (UnimplementedClaudeCodeServer) testEmbeddedByValue()                    {}
+
+// UnsafeClaudeCodeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClaudeCodeServer will
+// result in compilation errors.
+type UnsafeClaudeCodeServer interface {
+	mustEmbedUnimplementedClaudeCodeServer()
+}
+
+func RegisterClaudeCodeServer(s grpc.ServiceRegistrar, srv ClaudeCodeServer) {
+	// If the following call pancis, it indicates UnimplementedClaudeCodeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClaudeCode_ServiceDesc, srv)
+}
+
+func _ClaudeCode_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClaudeCodeServer).Query(m, &grpc.GenericServerStream[QueryRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_QueryServer = grpc.ServerStreamingServer[Event]
+
+func _ClaudeCode_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ClaudeCodeServer).Session(&grpc.GenericServerStream[SessionRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_SessionServer = grpc.BidiStreamingServer[SessionRequest, Event]
+
+// ClaudeCode_ServiceDesc is the grpc.ServiceDesc for ClaudeCode service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClaudeCode_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "claudecode.v1.ClaudeCode",
+	HandlerType: (*ClaudeCodeServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Query",
+			Handler:       _ClaudeCode_Query_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Session",
+			Handler:       _ClaudeCode_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "claudecode/v1/claudecode.proto",
+}
@@ -0,0 +1,2136 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: claudecode/v1/messages.proto
+
+package claudecodev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TypedMessage is a message from Claude Code with a field for each message
+// type, mirroring the SDK's Message types. Unlike Message, whose data is
+// schemaless JSON, it can be read without knowing the JSON layout.
+type TypedMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*TypedMessage_User
+	//	*TypedMessage_Assistant
+	//	*TypedMessage_System
+	//	*TypedMessage_Result
+	//	*TypedMessage_Progress
+	//	*TypedMessage_Unknown
+	Message isTypedMessage_Message `protobuf_oneof:"message"`
+}
+
+func (x *TypedMessage) Reset() {
+	*x = TypedMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TypedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypedMessage) ProtoMessage() {}
+
+func (x *TypedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypedMessage.ProtoReflect.Descriptor instead.
+func (*TypedMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *TypedMessage) GetMessage() isTypedMessage_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetUser() *UserMessage {
+	if x, ok := x.GetMessage().(*TypedMessage_User); ok {
+		return x.User
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetAssistant() *AssistantMessage {
+	if x, ok := x.GetMessage().(*TypedMessage_Assistant); ok {
+		return x.Assistant
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetSystem() *SystemMessage {
+	if x, ok := x.GetMessage().(*TypedMessage_System); ok {
+		return x.System
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetResult() *ResultMessage {
+	if x, ok := x.GetMessage().(*TypedMessage_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetProgress() *ProgressEvent {
+	if x, ok := x.GetMessage().(*TypedMessage_Progress); ok {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *TypedMessage) GetUnknown() *UnknownMessage {
+	if x, ok := x.GetMessage().(*TypedMessage_Unknown); ok {
+		return x.Unknown
+	}
+	return nil
+}
+
+type isTypedMessage_Message interface {
+	isTypedMessage_Message()
+}
+
+type TypedMessage_User struct {
+	User *UserMessage `protobuf:"bytes,1,opt,name=user,proto3,oneof"`
+}
+
+type TypedMessage_Assistant struct {
+	Assistant *AssistantMessage `protobuf:"bytes,2,opt,name=assistant,proto3,oneof"`
+}
+
+type TypedMessage_System struct {
+	System *SystemMessage `protobuf:"bytes,3,opt,name=system,proto3,oneof"`
+}
+
+type TypedMessage_Result struct {
+	Result *ResultMessage `protobuf:"bytes,4,opt,name=result,proto3,oneof"`
+}
+
+type TypedMessage_Progress struct {
+	Progress *ProgressEvent `protobuf:"bytes,5,opt,name=progress,proto3,oneof"`
+}
+
+type TypedMessage_Unknown struct {
+	Unknown *UnknownMessage `protobuf:"bytes,6,opt,name=unknown,proto3,oneof"`
+}
+
+func (*TypedMessage_User) isTypedMessage_Message() {}
+
+func (*TypedMessage_Assistant) isTypedMessage_Message() {}
+
+func (*TypedMessage_System) isTypedMessage_Message() {}
+
+func (*TypedMessage_Result) isTypedMessage_Message() {}
+
+func (*TypedMessage_Progress) isTypedMessage_Message() {}
+
+func (*TypedMessage_Unknown) isTypedMessage_Message() {}
+
+// UserMessage is a prompt, or the tool results sent back to Claude.
+type UserMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content string `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	// Blocks holds the content blocks of a message with array content, such
+	// as tool results. Content is then only a summary.
+	Blocks []*ContentBlock `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+}
+
+func (x *UserMessage) Reset() {
+	*x = UserMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserMessage) ProtoMessage() {}
+
+func (x *UserMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserMessage.ProtoReflect.Descriptor instead.
+func (*UserMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UserMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UserMessage) GetBlocks() []*ContentBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+// AssistantMessage is a reply from Claude.
+type AssistantMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content []*ContentBlock `protobuf:"bytes,1,rep,name=content,proto3" json:"content,omitempty"`
+	// ID is the API message ID, shared by the assistant messages the CLI
+	// splits one API message into.
+	Id    string           `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Model string           `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Usage *structpb.Struct `protobuf:"bytes,4,opt,name=usage,proto3" json:"usage,omitempty"`
+}
+
+func (x *AssistantMessage) Reset() {
+	*x = AssistantMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssistantMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssistantMessage) ProtoMessage() {}
+
+func (x *AssistantMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssistantMessage.ProtoReflect.Descriptor instead.
+func (*AssistantMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AssistantMessage) GetContent() []*ContentBlock {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *AssistantMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AssistantMessage) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *AssistantMessage) GetUsage() *structpb.Struct {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// SystemMessage is a system message, such as the "init" message that
+// starts a session.
+type SystemMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subtype string `protobuf:"bytes,1,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	// Data is the whole message as the CLI sent it.
+	Data *structpb.Struct `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *SystemMessage) Reset() {
+	*x = SystemMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemMessage) ProtoMessage() {}
+
+func (x *SystemMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemMessage.ProtoReflect.Descriptor instead.
+func (*SystemMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SystemMessage) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *SystemMessage) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// ResultMessage ends a query or a session turn.
+type ResultMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subtype           string              `protobuf:"bytes,1,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	DurationMs        int64               `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	DurationApiMs     int64               `protobuf:"varint,3,opt,name=duration_api_ms,json=durationApiMs,proto3" json:"duration_api_ms,omitempty"`
+	IsError           bool                `protobuf:"varint,4,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	NumTurns          int32               `protobuf:"varint,5,opt,name=num_turns,json=numTurns,proto3" json:"num_turns,omitempty"`
+	SessionId         string              `protobuf:"bytes,6,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TotalCostUsd      *float64            `protobuf:"fixed64,7,opt,name=total_cost_usd,json=totalCostUsd,proto3,oneof" json:"total_cost_usd,omitempty"`
+	Usage             *structpb.Struct    `protobuf:"bytes,8,opt,name=usage,proto3" json:"usage,omitempty"`
+	Result            *string             `protobuf:"bytes,9,opt,name=result,proto3,oneof" json:"result,omitempty"`
+	PermissionDenials []*PermissionDenial `protobuf:"bytes,10,rep,name=permission_denials,json=permissionDenials,proto3" json:"permission_denials,omitempty"`
+	// ModelUsage holds the usage of each model that answered, by model name.
+	ModelUsage map[string]*ModelUsage `protobuf:"bytes,11,rep,name=model_usage,json=modelUsage,proto3" json:"model_usage,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ResultMessage) Reset() {
+	*x = ResultMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultMessage) ProtoMessage() {}
+
+func (x *ResultMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultMessage.ProtoReflect.Descriptor instead.
+func (*ResultMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResultMessage) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *ResultMessage) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ResultMessage) GetDurationApiMs() int64 {
+	if x != nil {
+		return x.DurationApiMs
+	}
+	return 0
+}
+
+func (x *ResultMessage) GetIsError() bool {
+	if x != nil {
+		return x.IsError
+	}
+	return false
+}
+
+func (x *ResultMessage) GetNumTurns() int32 {
+	if x != nil {
+		return x.NumTurns
+	}
+	return 0
+}
+
+func (x *ResultMessage) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ResultMessage) GetTotalCostUsd() float64 {
+	if x != nil && x.TotalCostUsd != nil {
+		return *x.TotalCostUsd
+	}
+	return 0
+}
+
+func (x *ResultMessage) GetUsage() *structpb.Struct {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *ResultMessage) GetResult() string {
+	if x != nil && x.Result != nil {
+		return *x.Result
+	}
+	return ""
+}
+
+func (x *ResultMessage) GetPermissionDenials() []*PermissionDenial {
+	if x != nil {
+		return x.PermissionDenials
+	}
+	return nil
+}
+
+func (x *ResultMessage) GetModelUsage() map[string]*ModelUsage {
+	if x != nil {
+		return x.ModelUsage
+	}
+	return nil
+}
+
+// PermissionDenial is a tool use refused because permission was denied.
+type PermissionDenial struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ToolName  string           `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	ToolUseId string           `protobuf:"bytes,2,opt,name=tool_use_id,json=toolUseId,proto3" json:"tool_use_id,omitempty"`
+	ToolInput *structpb.Struct `protobuf:"bytes,3,opt,name=tool_input,json=toolInput,proto3" json:"tool_input,omitempty"`
+}
+
+func (x *PermissionDenial) Reset() {
+	*x = PermissionDenial{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PermissionDenial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionDenial) ProtoMessage() {}
+
+func (x *PermissionDenial) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionDenial.ProtoReflect.Descriptor instead.
+func (*PermissionDenial) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PermissionDenial) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *PermissionDenial) GetToolUseId() string {
+	if x != nil {
+		return x.ToolUseId
+	}
+	return ""
+}
+
+func (x *PermissionDenial) GetToolInput() *structpb.Struct {
+	if x != nil {
+		return x.ToolInput
+	}
+	return nil
+}
+
+// ModelUsage is the usage of one model during a run.
+type ModelUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InputTokens              int64   `protobuf:"varint,1,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
+	OutputTokens             int64   `protobuf:"varint,2,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	CacheReadInputTokens     int64   `protobuf:"varint,3,opt,name=cache_read_input_tokens,json=cacheReadInputTokens,proto3" json:"cache_read_input_tokens,omitempty"`
+	CacheCreationInputTokens int64   `protobuf:"varint,4,opt,name=cache_creation_input_tokens,json=cacheCreationInputTokens,proto3" json:"cache_creation_input_tokens,omitempty"`
+	WebSearchRequests        int64   `protobuf:"varint,5,opt,name=web_search_requests,json=webSearchRequests,proto3" json:"web_search_requests,omitempty"`
+	CostUsd                  float64 `protobuf:"fixed64,6,opt,name=cost_usd,json=costUsd,proto3" json:"cost_usd,omitempty"`
+}
+
+func (x *ModelUsage) Reset() {
+	*x = ModelUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModelUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelUsage) ProtoMessage() {}
+
+func (x *ModelUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelUsage.ProtoReflect.Descriptor instead.
+func (*ModelUsage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ModelUsage) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *ModelUsage) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *ModelUsage) GetCacheReadInputTokens() int64 {
+	if x != nil {
+		return x.CacheReadInputTokens
+	}
+	return 0
+}
+
+func (x *ModelUsage) GetCacheCreationInputTokens() int64 {
+	if x != nil {
+		return x.CacheCreationInputTokens
+	}
+	return 0
+}
+
+func (x *ModelUsage) GetWebSearchRequests() int64 {
+	if x != nil {
+		return x.WebSearchRequests
+	}
+	return 0
+}
+
+func (x *ModelUsage) GetCostUsd() float64 {
+	if x != nil {
+		return x.CostUsd
+	}
+	return 0
+}
+
+// ProgressEvent reports that a long-running tool call is still running.
+type ProgressEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ToolUseId       string               `protobuf:"bytes,1,opt,name=tool_use_id,json=toolUseId,proto3" json:"tool_use_id,omitempty"`
+	ToolName        string               `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	ParentToolUseId string               `protobuf:"bytes,3,opt,name=parent_tool_use_id,json=parentToolUseId,proto3" json:"parent_tool_use_id,omitempty"`
+	Elapsed         *durationpb.Duration `protobuf:"bytes,4,opt,name=elapsed,proto3" json:"elapsed,omitempty"`
+	Status          string               `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *ProgressEvent) Reset() {
+	*x = ProgressEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProgressEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressEvent) ProtoMessage() {}
+
+func (x *ProgressEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressEvent.ProtoReflect.Descriptor instead.
+func (*ProgressEvent) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProgressEvent) GetToolUseId() string {
+	if x != nil {
+		return x.ToolUseId
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetParentToolUseId() string {
+	if x != nil {
+		return x.ParentToolUseId
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetElapsed() *durationpb.Duration {
+	if x != nil {
+		return x.Elapsed
+	}
+	return nil
+}
+
+func (x *ProgressEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// UnknownMessage is a message of a type the SDK does not recognize.
+type UnknownMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Raw is the message's JSON object.
+	Raw []byte `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
+}
+
+func (x *UnknownMessage) Reset() {
+	*x = UnknownMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnknownMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnknownMessage) ProtoMessage() {}
+
+func (x *UnknownMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnknownMessage.ProtoReflect.Descriptor instead.
+func (*UnknownMessage) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UnknownMessage) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnknownMessage) GetRaw() []byte {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+// ContentBlock is a piece of content within a message.
+type ContentBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Block:
+	//	*ContentBlock_Text
+	//	*ContentBlock_Thinking
+	//	*ContentBlock_ToolUse
+	//	*ContentBlock_ToolResult
+	//	*ContentBlock_Unknown
+	Block isContentBlock_Block `protobuf_oneof:"block"`
+}
+
+func (x *ContentBlock) Reset() {
+	*x = ContentBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContentBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentBlock) ProtoMessage() {}
+
+func (x *ContentBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentBlock.ProtoReflect.Descriptor instead.
+func (*ContentBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{9}
+}
+
+func (m *ContentBlock) GetBlock() isContentBlock_Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (x *ContentBlock) GetText() *TextBlock {
+	if x, ok := x.GetBlock().(*ContentBlock_Text); ok {
+		return x.Text
+	}
+	return nil
+}
+
+func (x *ContentBlock) GetThinking() *ThinkingBlock {
+	if x, ok := x.GetBlock().(*ContentBlock_Thinking); ok {
+		return x.Thinking
+	}
+	return nil
+}
+
+func (x *ContentBlock) GetToolUse() *ToolUseBlock {
+	if x, ok := x.GetBlock().(*ContentBlock_ToolUse); ok {
+		return x.ToolUse
+	}
+	return nil
+}
+
+func (x *ContentBlock) GetToolResult() *ToolResultBlock {
+	if x, ok := x.GetBlock().(*ContentBlock_ToolResult); ok {
+		return x.ToolResult
+	}
+	return nil
+}
+
+func (x *ContentBlock) GetUnknown() *UnknownBlock {
+	if x, ok := x.GetBlock().(*ContentBlock_Unknown); ok {
+		return x.Unknown
+	}
+	return nil
+}
+
+type isContentBlock_Block interface {
+	isContentBlock_Block()
+}
+
+type ContentBlock_Text struct {
+	Text *TextBlock `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type ContentBlock_Thinking struct {
+	Thinking *ThinkingBlock `protobuf:"bytes,2,opt,name=thinking,proto3,oneof"`
+}
+
+type ContentBlock_ToolUse struct {
+	ToolUse *ToolUseBlock `protobuf:"bytes,3,opt,name=tool_use,json=toolUse,proto3,oneof"`
+}
+
+type ContentBlock_ToolResult struct {
+	ToolResult *ToolResultBlock `protobuf:"bytes,4,opt,name=tool_result,json=toolResult,proto3,oneof"`
+}
+
+type ContentBlock_Unknown struct {
+	Unknown *UnknownBlock `protobuf:"bytes,5,opt,name=unknown,proto3,oneof"`
+}
+
+func (*ContentBlock_Text) isContentBlock_Block() {}
+
+func (*ContentBlock_Thinking) isContentBlock_Block() {}
+
+func (*ContentBlock_ToolUse) isContentBlock_Block() {}
+
+func (*ContentBlock_ToolResult) isContentBlock_Block() {}
+
+func (*ContentBlock_Unknown) isContentBlock_Block() {}
+
+// TextBlock is text from Claude.
+type TextBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *TextBlock) Reset() {
+	*x = TextBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TextBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextBlock) ProtoMessage() {}
+
+func (x *TextBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextBlock.ProtoReflect.Descriptor instead.
+func (*TextBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TextBlock) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// ThinkingBlock is Claude's extended thinking.
+type ThinkingBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Thinking  string `protobuf:"bytes,1,opt,name=thinking,proto3" json:"thinking,omitempty"`
+	Signature string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *ThinkingBlock) Reset() {
+	*x = ThinkingBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThinkingBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThinkingBlock) ProtoMessage() {}
+
+func (x *ThinkingBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThinkingBlock.ProtoReflect.Descriptor instead.
+func (*ThinkingBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ThinkingBlock) GetThinking() string {
+	if x != nil {
+		return x.Thinking
+	}
+	return ""
+}
+
+func (x *ThinkingBlock) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+// ToolUseBlock is a tool call.
+type ToolUseBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    string           `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string           `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Input *structpb.Struct `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
+}
+
+func (x *ToolUseBlock) Reset() {
+	*x = ToolUseBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolUseBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolUseBlock) ProtoMessage() {}
+
+func (x *ToolUseBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolUseBlock.ProtoReflect.Descriptor instead.
+func (*ToolUseBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ToolUseBlock) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ToolUseBlock) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolUseBlock) GetInput() *structpb.Struct {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+// ToolResultBlock is the result of a tool call.
+type ToolResultBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ToolUseId string               `protobuf:"bytes,1,opt,name=tool_use_id,json=toolUseId,proto3" json:"tool_use_id,omitempty"`
+	Content   []*ToolResultContent `protobuf:"bytes,2,rep,name=content,proto3" json:"content,omitempty"`
+	IsError   *bool                `protobuf:"varint,3,opt,name=is_error,json=isError,proto3,oneof" json:"is_error,omitempty"`
+}
+
+func (x *ToolResultBlock) Reset() {
+	*x = ToolResultBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolResultBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolResultBlock) ProtoMessage() {}
+
+func (x *ToolResultBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolResultBlock.ProtoReflect.Descriptor instead.
+func (*ToolResultBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ToolResultBlock) GetToolUseId() string {
+	if x != nil {
+		return x.ToolUseId
+	}
+	return ""
+}
+
+func (x *ToolResultBlock) GetContent() []*ToolResultContent {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ToolResultBlock) GetIsError() bool {
+	if x != nil && x.IsError != nil {
+		return *x.IsError
+	}
+	return false
+}
+
+// UnknownBlock is a content block of a type the SDK does not recognize.
+type UnknownBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Raw is the block's JSON object.
+	Raw []byte `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
+}
+
+func (x *UnknownBlock) Reset() {
+	*x = UnknownBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnknownBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnknownBlock) ProtoMessage() {}
+
+func (x *UnknownBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnknownBlock.ProtoReflect.Descriptor instead.
+func (*UnknownBlock) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UnknownBlock) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnknownBlock) GetRaw() []byte {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+// ToolResultContent is one part of a tool result's content.
+type ToolResultContent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Content:
+	//	*ToolResultContent_Text
+	//	*ToolResultContent_Image
+	//	*ToolResultContent_Json
+	Content isToolResultContent_Content `protobuf_oneof:"content"`
+}
+
+func (x *ToolResultContent) Reset() {
+	*x = ToolResultContent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolResultContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolResultContent) ProtoMessage() {}
+
+func (x *ToolResultContent) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolResultContent.ProtoReflect.Descriptor instead.
+func (*ToolResultContent) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{15}
+}
+
+func (m *ToolResultContent) GetContent() isToolResultContent_Content {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (x *ToolResultContent) GetText() string {
+	if x, ok := x.GetContent().(*ToolResultContent_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ToolResultContent) GetImage() *ImageContent {
+	if x, ok := x.GetContent().(*ToolResultContent_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *ToolResultContent) GetJson() []byte {
+	if x, ok := x.GetContent().(*ToolResultContent_Json); ok {
+		return x.Json
+	}
+	return nil
+}
+
+type isToolResultContent_Content interface {
+	isToolResultContent_Content()
+}
+
+type ToolResultContent_Text struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type ToolResultContent_Image struct {
+	Image *ImageContent `protobuf:"bytes,2,opt,name=image,proto3,oneof"`
+}
+
+type ToolResultContent_Json struct {
+	// JSON is a part that is neither text nor an image, as a JSON value.
+	Json []byte `protobuf:"bytes,3,opt,name=json,proto3,oneof"`
+}
+
+func (*ToolResultContent_Text) isToolResultContent_Content() {}
+
+func (*ToolResultContent_Image) isToolResultContent_Content() {}
+
+func (*ToolResultContent_Json) isToolResultContent_Content() {}
+
+// ImageContent is an image in a tool result. Either data or url is set.
+type ImageContent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MediaType string `protobuf:"bytes,1,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	// Data is the base64-encoded image.
+	Data string `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Url  string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+}
+
+func (x *ImageContent) Reset() {
+	*x = ImageContent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImageContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageContent) ProtoMessage() {}
+
+func (x *ImageContent) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageContent.ProtoReflect.Descriptor instead.
+func (*ImageContent) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ImageContent) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+func (x *ImageContent) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *ImageContent) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// Transcript is the message history of a query or session.
+type Transcript struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Entries   []*TranscriptEntry     `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *Transcript) Reset() {
+	*x = Transcript{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transcript) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transcript) ProtoMessage() {}
+
+func (x *Transcript) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transcript.ProtoReflect.Descriptor instead.
+func (*Transcript) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Transcript) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Transcript) GetEntries() []*TranscriptEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// TranscriptEntry is a message in a transcript with the time it was added.
+type TranscriptEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Message *TypedMessage          `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *TranscriptEntry) Reset() {
+	*x = TranscriptEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranscriptEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscriptEntry) ProtoMessage() {}
+
+func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscriptEntry.ProtoReflect.Descriptor instead.
+func (*TranscriptEntry) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TranscriptEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *TranscriptEntry) GetMessage() *TypedMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// StreamEvent is a message published by the events package, with the host
+// that ran its stream and its place in the stream.
+type StreamEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time      *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Source    string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	SessionId string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Seq numbers the events of a stream from 1.
+	Seq     int64         `protobuf:"varint,4,opt,name=seq,proto3" json:"seq,omitempty"`
+	Message *TypedMessage `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *StreamEvent) Reset() {
+	*x = StreamEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_claudecode_v1_messages_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEvent) ProtoMessage() {}
+
+func (x *StreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_claudecode_v1_messages_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEvent.ProtoReflect.Descriptor instead.
+func (*StreamEvent) Descriptor() ([]byte, []int) {
+	return file_claudecode_v1_messages_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *StreamEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *StreamEvent) GetMessage() *TypedMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+var File_claudecode_v1_messages_proto protoreflect.FileDescriptor
+
+var file_claudecode_v1_messages_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2f, 0x76, 0x31, 0x2f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d,
+	0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73,
+	0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf3, 0x02, 0x0a,
+	0x0c, 0x54, 0x79, 0x70, 0x65, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a,
+	0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6c,
+	0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x3f, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x09, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x74,
+	0x12, 0x36, 0x0a, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00,
+	0x52, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x36, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64,
+	0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x07,
+	0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e,
+	0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x07,
+	0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x22, 0x5c, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x33, 0x0a, 0x06, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c,
+	0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x22, 0x9e, 0x01, 0x0a, 0x10, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x35, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63,
+	0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x12, 0x2d, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67,
+	0x65, 0x22, 0x56, 0x0a, 0x0d, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2b, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xd7, 0x04, 0x0a, 0x0d, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x75, 0x62, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75,
+	0x62, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0d, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x70, 0x69, 0x4d, 0x73, 0x12, 0x19,
+	0x0a, 0x08, 0x69, 0x73, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x69, 0x73, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x75, 0x6d,
+	0x5f, 0x74, 0x75, 0x72, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x75,
+	0x6d, 0x54, 0x75, 0x72, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63,
+	0x6f, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52,
+	0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x73, 0x74, 0x55, 0x73, 0x64, 0x88, 0x01, 0x01,
+	0x12, 0x2d, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x1b, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x01, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x88, 0x01, 0x01, 0x12, 0x4e, 0x0a, 0x12,
+	0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x6e, 0x69, 0x61,
+	0x6c, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64,
+	0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x44, 0x65, 0x6e, 0x69, 0x61, 0x6c, 0x52, 0x11, 0x70, 0x65, 0x72, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x6e, 0x69, 0x61, 0x6c, 0x73, 0x12, 0x4d, 0x0a, 0x0b,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2c, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0a, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x58, 0x0a, 0x0f, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x2f, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
+	0x63, 0x6f, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x87, 0x01, 0x0a, 0x10, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x44, 0x65, 0x6e, 0x69, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x6f, 0x6c,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6f, 0x6f,
+	0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x75, 0x73,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f, 0x6f, 0x6c,
+	0x55, 0x73, 0x65, 0x49, 0x64, 0x12, 0x36, 0x0a, 0x0a, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75,
+	0x63, 0x74, 0x52, 0x09, 0x74, 0x6f, 0x6f, 0x6c, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x95, 0x02,
+	0x0a, 0x0a, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x12, 0x35, 0x0a, 0x17, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x72, 0x65,
+	0x61, 0x64, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x63, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x61, 0x64,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x3d, 0x0a, 0x1b, 0x63,
+	0x61, 0x63, 0x68, 0x65, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x18, 0x63, 0x61, 0x63, 0x68, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49,
+	0x6e, 0x70, 0x75, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x77, 0x65,
+	0x62, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x77, 0x65, 0x62, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x6f,
+	0x73, 0x74, 0x5f, 0x75, 0x73, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x63, 0x6f,
+	0x73, 0x74, 0x55, 0x73, 0x64, 0x22, 0xc6, 0x01, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0b, 0x74, 0x6f, 0x6f, 0x6c, 0x5f,
+	0x75, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f,
+	0x6f, 0x6c, 0x55, 0x73, 0x65, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x6f, 0x6c, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6f, 0x6f, 0x6c,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x12, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x74,
+	0x6f, 0x6f, 0x6c, 0x5f, 0x75, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0f, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x65, 0x49,
+	0x64, 0x12, 0x33, 0x0a, 0x07, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x65,
+	0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x36,
+	0x0a, 0x0e, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x03, 0x72, 0x61, 0x77, 0x22, 0xb9, 0x02, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x78, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48,
+	0x00, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x3a, 0x0a, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x6b,
+	0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x6c, 0x61, 0x75,
+	0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69,
+	0x6e, 0x67, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x6b,
+	0x69, 0x6e, 0x67, 0x12, 0x38, 0x0a, 0x08, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x75, 0x73, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x65, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x48, 0x00, 0x52, 0x07, 0x74, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x65, 0x12, 0x41, 0x0a,
+	0x0b, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x37, 0x0a, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x00,
+	0x52, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x07, 0x0a, 0x05, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x22, 0x1f, 0x0a, 0x09, 0x54, 0x65, 0x78, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x22, 0x49, 0x0a, 0x0d, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x61,
+	0x0a, 0x0c, 0x54, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x22, 0x9a, 0x01, 0x0a, 0x0f, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1e, 0x0a, 0x0b, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x75, 0x73,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f, 0x6f, 0x6c,
+	0x55, 0x73, 0x65, 0x49, 0x64, 0x12, 0x3a, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63,
+	0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x12, 0x1e, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x69, 0x73, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x88, 0x01,
+	0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x69, 0x73, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x34,
+	0x0a, 0x0c, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x03, 0x72, 0x61, 0x77, 0x22, 0x7f, 0x0a, 0x11, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x04, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12,
+	0x33, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x69,
+	0x6d, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x53, 0x0a, 0x0c, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x81, 0x01, 0x0a, 0x0a, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x38, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x78,
+	0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x35, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xbd, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x73, 0x65,
+	0x71, 0x12, 0x35, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x50, 0x5a, 0x4e, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x72, 0x6f, 0x73, 0x73, 0x69, 0x2f, 0x63, 0x6c,
+	0x61, 0x75, 0x64, 0x65, 0x2d, 0x63, 0x6f, 0x64, 0x65, 0x2d, 0x73, 0x64, 0x6b, 0x2d, 0x67, 0x6f,
+	0x6c, 0x61, 0x6e, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2f, 0x63, 0x6c, 0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x76, 0x31, 0x3b, 0x63, 0x6c,
+	0x61, 0x75, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_claudecode_v1_messages_proto_rawDescOnce sync.Once
+	file_claudecode_v1_messages_proto_rawDescData = file_claudecode_v1_messages_proto_rawDesc
+)
+
+func file_claudecode_v1_messages_proto_rawDescGZIP() []byte {
+	file_claudecode_v1_messages_proto_rawDescOnce.Do(func() {
+		file_claudecode_v1_messages_proto_rawDescData = protoimpl.X.CompressGZIP(file_claudecode_v1_messages_proto_rawDescData)
+	})
+	return file_claudecode_v1_messages_proto_rawDescData
+}
+
+var file_claudecode_v1_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_claudecode_v1_messages_proto_goTypes = []any{
+	(*TypedMessage)(nil),          // 0: claudecode.v1.TypedMessage
+	(*UserMessage)(nil),           // 1: claudecode.v1.UserMessage
+	(*AssistantMessage)(nil),      // 2: claudecode.v1.AssistantMessage
+	(*SystemMessage)(nil),         // 3: claudecode.v1.SystemMessage
+	(*ResultMessage)(nil),         // 4: claudecode.v1.ResultMessage
+	(*PermissionDenial)(nil),      // 5: claudecode.v1.PermissionDenial
+	(*ModelUsage)(nil),            // 6: claudecode.v1.ModelUsage
+	(*ProgressEvent)(nil),         // 7: claudecode.v1.ProgressEvent
+	(*UnknownMessage)(nil),        // 8: claudecode.v1.UnknownMessage
+	(*ContentBlock)(nil),          // 9: claudecode.v1.ContentBlock
+	(*TextBlock)(nil),             // 10: claudecode.v1.TextBlock
+	(*ThinkingBlock)(nil),         // 11: claudecode.v1.ThinkingBlock
+	(*ToolUseBlock)(nil),          // 12: claudecode.v1.ToolUseBlock
+	(*ToolResultBlock)(nil),       // 13: claudecode.v1.ToolResultBlock
+	(*UnknownBlock)(nil),          // 14: claudecode.v1.UnknownBlock
+	(*ToolResultContent)(nil),     // 15: claudecode.v1.ToolResultContent
+	(*ImageContent)(nil),          // 16: claudecode.v1.ImageContent
+	(*Transcript)(nil),            // 17: claudecode.v1.Transcript
+	(*TranscriptEntry)(nil),       // 18: claudecode.v1.TranscriptEntry
+	(*StreamEvent)(nil),           // 19: claudecode.v1.StreamEvent
+	nil,                           // 20: claudecode.v1.ResultMessage.ModelUsageEntry
+	(*structpb.Struct)(nil),       // 21: google.protobuf.Struct
+	(*durationpb.Duration)(nil),   // 22: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 23: google.protobuf.Timestamp
+}
+var file_claudecode_v1_messages_proto_depIdxs = []int32{
+	1,  // 0: claudecode.v1.TypedMessage.user:type_name -> claudecode.v1.UserMessage
+	2,  // 1: claudecode.v1.TypedMessage.assistant:type_name -> claudecode.v1.AssistantMessage
+	3,  // 2: claudecode.v1.TypedMessage.system:type_name -> claudecode.v1.SystemMessage
+	4,  // 3: claudecode.v1.TypedMessage.result:type_name -> claudecode.v1.ResultMessage
+	7,  // 4: claudecode.v1.TypedMessage.progress:type_name -> claudecode.v1.ProgressEvent
+	8,  // 5: claudecode.v1.TypedMessage.unknown:type_name -> claudecode.v1.UnknownMessage
+	9,  // 6: claudecode.v1.UserMessage.blocks:type_name -> claudecode.v1.ContentBlock
+	9,  // 7: claudecode.v1.AssistantMessage.content:type_name -> claudecode.v1.ContentBlock
+	21, // 8: claudecode.v1.AssistantMessage.usage:type_name -> google.protobuf.Struct
+	21, // 9: claudecode.v1.SystemMessage.data:type_name -> google.protobuf.Struct
+	21, // 10: claudecode.v1.ResultMessage.usage:type_name -> google.protobuf.Struct
+	5,  // 11: claudecode.v1.ResultMessage.permission_denials:type_name -> claudecode.v1.PermissionDenial
+	20, // 12: claudecode.v1.ResultMessage.model_usage:type_name -> claudecode.v1.ResultMessage.ModelUsageEntry
+	21, // 13: claudecode.v1.PermissionDenial.tool_input:type_name -> google.protobuf.Struct
+	22, // 14: claudecode.v1.ProgressEvent.elapsed:type_name -> google.protobuf.Duration
+	10, // 15: claudecode.v1.ContentBlock.text:type_name -> claudecode.v1.TextBlock
+	11, // 16: claudecode.v1.ContentBlock.thinking:type_name -> claudecode.v1.ThinkingBlock
+	12, // 17: claudecode.v1.ContentBlock.tool_use:type_name -> claudecode.v1.ToolUseBlock
+	13, // 18: claudecode.v1.ContentBlock.tool_result:type_name -> claudecode.v1.ToolResultBlock
+	14, // 19: claudecode.v1.ContentBlock.unknown:type_name -> claudecode.v1.UnknownBlock
+	21, // 20: claudecode.v1.ToolUseBlock.input:type_name -> google.protobuf.Struct
+	15, // 21: claudecode.v1.ToolResultBlock.content:type_name -> claudecode.v1.ToolResultContent
+	16, // 22: claudecode.v1.ToolResultContent.image:type_name -> claudecode.v1.ImageContent
+	23, // 23: claudecode.v1.Transcript.started_at:type_name -> google.protobuf.Timestamp
+	18, // 24: claudecode.v1.Transcript.entries:type_name -> claudecode.v1.TranscriptEntry
+	23, // 25: claudecode.v1.TranscriptEntry.time:type_name -> google.protobuf.Timestamp
+	0,  // 26: claudecode.v1.TranscriptEntry.message:type_name -> claudecode.v1.TypedMessage
+	23, // 27: claudecode.v1.StreamEvent.time:type_name -> google.protobuf.Timestamp
+	0,  // 28: claudecode.v1.StreamEvent.message:type_name -> claudecode.v1.TypedMessage
+	6,  // 29: claudecode.v1.ResultMessage.ModelUsageEntry.value:type_name -> claudecode.v1.ModelUsage
+	30, // [30:30] is the sub-list for method output_type
+	30, // [30:30] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
+}
+
+func init() { file_claudecode_v1_messages_proto_init() }
+func file_claudecode_v1_messages_proto_init() {
+	if File_claudecode_v1_messages_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_claudecode_v1_messages_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*TypedMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*UserMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*AssistantMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SystemMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ResultMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*PermissionDenial); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ModelUsage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ProgressEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*UnknownMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ContentBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*TextBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ThinkingBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ToolUseBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*ToolResultBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*UnknownBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*ToolResultContent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*ImageContent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*Transcript); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*TranscriptEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_claudecode_v1_messages_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_claudecode_v1_messages_proto_msgTypes[0].OneofWrappers = []any{
+		(*TypedMessage_User)(nil),
+		(*TypedMessage_Assistant)(nil),
+		(*TypedMessage_System)(nil),
+		(*TypedMessage_Result)(nil),
+		(*TypedMessage_Progress)(nil),
+		(*TypedMessage_Unknown)(nil),
+	}
+	file_claudecode_v1_messages_proto_msgTypes[4].OneofWrappers = []any{}
+	file_claudecode_v1_messages_proto_msgTypes[9].OneofWrappers = []any{
+		(*ContentBlock_Text)(nil),
+		(*ContentBlock_Thinking)(nil),
+		(*ContentBlock_ToolUse)(nil),
+		(*ContentBlock_ToolResult)(nil),
+		(*ContentBlock_Unknown)(nil),
+	}
+	file_claudecode_v1_messages_proto_msgTypes[13].OneofWrappers = []any{}
+	file_claudecode_v1_messages_proto_msgTypes[15].OneofWrappers = []any{
+		(*ToolResultContent_Text)(nil),
+		(*ToolResultContent_Image)(nil),
+		(*ToolResultContent_Json)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_claudecode_v1_messages_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_claudecode_v1_messages_proto_goTypes,
+		DependencyIndexes: file_claudecode_v1_messages_proto_depIdxs,
+		MessageInfos:      file_claudecode_v1_messages_proto_msgTypes,
+	}.Build()
+	File_claudecode_v1_messages_proto = out.File
+	file_claudecode_v1_messages_proto_rawDesc = nil
+	file_claudecode_v1_messages_proto_goTypes = nil
+	file_claudecode_v1_messages_proto_depIdxs = nil
+}
@@ -0,0 +1,443 @@
+package grpcservice
+
+import (
+	"encoding/json"
+	"fmt"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/events"
+	"github.com/jrossi/claude-code-sdk-golang/grpcservice/claudecodev1"
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Converting Struct fields loses the distinction between integers and
+// floats: numbers come back as float64, as encoding/json decodes them.
+
+// MessageToProto converts a message to its protobuf form.
+func MessageToProto(msg claudecode.Message) (*claudecodev1.TypedMessage, error) {
+	switch m := msg.(type) {
+	case *claudecode.UserMessage:
+		blocks, err := blocksToProto(m.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_User{
+			User: &claudecodev1.UserMessage{Content: m.Content, Blocks: blocks},
+		}}, nil
+
+	case *claudecode.AssistantMessage:
+		content, err := blocksToProto(m.Content)
+		if err != nil {
+			return nil, err
+		}
+		usage, err := toStruct(m.Usage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage: %w", err)
+		}
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_Assistant{
+			Assistant: &claudecodev1.AssistantMessage{Content: content, Id: m.ID, Model: m.Model, Usage: usage},
+		}}, nil
+
+	case *claudecode.SystemMessage:
+		data, err := toStruct(m.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid system message data: %w", err)
+		}
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_System{
+			System: &claudecodev1.SystemMessage{Subtype: m.Subtype, Data: data},
+		}}, nil
+
+	case *claudecode.ResultMessage:
+		result, err := resultToProto(m)
+		if err != nil {
+			return nil, err
+		}
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_Result{Result: result}}, nil
+
+	case *claudecode.ProgressEvent:
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_Progress{
+			Progress: &claudecodev1.ProgressEvent{
+				ToolUseId:       m.ToolUseID,
+				ToolName:        m.ToolName,
+				ParentToolUseId: m.ParentToolUseID,
+				Elapsed:         durationpb.New(m.Elapsed),
+				Status:          m.Status,
+			},
+		}}, nil
+
+	case *claudecode.UnknownMessage:
+		return &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_Unknown{
+			Unknown: &claudecodev1.UnknownMessage{Type: m.MessageType, Raw: m.Raw},
+		}}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot convert message of type %T", msg)
+	}
+}
+
+// MessageFromProto converts a message from its protobuf form.
+func MessageFromProto(msg *claudecodev1.TypedMessage) (claudecode.Message, error) {
+	switch m := msg.GetMessage().(type) {
+	case *claudecodev1.TypedMessage_User:
+		blocks, err := blocksFromProto(m.User.GetBlocks())
+		if err != nil {
+			return nil, err
+		}
+		return &claudecode.UserMessage{Content: m.User.GetContent(), Blocks: blocks}, nil
+
+	case *claudecodev1.TypedMessage_Assistant:
+		content, err := blocksFromProto(m.Assistant.GetContent())
+		if err != nil {
+			return nil, err
+		}
+		return &claudecode.AssistantMessage{
+			Content: content,
+			ID:      m.Assistant.GetId(),
+			Model:   m.Assistant.GetModel(),
+			Usage:   fromStruct(m.Assistant.GetUsage()),
+		}, nil
+
+	case *claudecodev1.TypedMessage_System:
+		return &claudecode.SystemMessage{Subtype: m.System.GetSubtype(), Data: fromStruct(m.System.GetData())}, nil
+
+	case *claudecodev1.TypedMessage_Result:
+		return resultFromProto(m.Result), nil
+
+	case *claudecodev1.TypedMessage_Progress:
+		return &claudecode.ProgressEvent{
+			ToolUseID:       m.Progress.GetToolUseId(),
+			ToolName:        m.Progress.GetToolName(),
+			ParentToolUseID: m.Progress.GetParentToolUseId(),
+			Elapsed:         m.Progress.GetElapsed().AsDuration(),
+			Status:          m.Progress.GetStatus(),
+		}, nil
+
+	case *claudecodev1.TypedMessage_Unknown:
+		return &claudecode.UnknownMessage{MessageType: m.Unknown.GetType(), Raw: m.Unknown.GetRaw()}, nil
+
+	default:
+		return nil, fmt.Errorf("message has no content")
+	}
+}
+
+func resultToProto(m *claudecode.ResultMessage) (*claudecodev1.ResultMessage, error) {
+	usage, err := toStruct(m.Usage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid usage: %w", err)
+	}
+	result := &claudecodev1.ResultMessage{
+		Subtype:       m.Subtype,
+		DurationMs:    int64(m.DurationMs),
+		DurationApiMs: int64(m.DurationAPIMs),
+		IsError:       m.IsError,
+		NumTurns:      int32(m.NumTurns),
+		SessionId:     m.SessionID,
+		TotalCostUsd:  m.TotalCostUSD,
+		Usage:         usage,
+		Result:        m.Result,
+	}
+
+	for _, denial := range m.PermissionDenials {
+		input, err := toStruct(denial.ToolInput)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input of denied tool %s: %w", denial.ToolName, err)
+		}
+		result.PermissionDenials = append(result.PermissionDenials, &claudecodev1.PermissionDenial{
+			ToolName:  denial.ToolName,
+			ToolUseId: denial.ToolUseID,
+			ToolInput: input,
+		})
+	}
+
+	if m.ModelUsage != nil {
+		result.ModelUsage = make(map[string]*claudecodev1.ModelUsage, len(m.ModelUsage))
+		for model, u := range m.ModelUsage {
+			result.ModelUsage[model] = &claudecodev1.ModelUsage{
+				InputTokens:              int64(u.InputTokens),
+				OutputTokens:             int64(u.OutputTokens),
+				CacheReadInputTokens:     int64(u.CacheReadInputTokens),
+				CacheCreationInputTokens: int64(u.CacheCreationInputTokens),
+				WebSearchRequests:        int64(u.WebSearchRequests),
+				CostUsd:                  u.CostUSD,
+			}
+		}
+	}
+	return result, nil
+}
+
+func resultFromProto(m *claudecodev1.ResultMessage) *claudecode.ResultMessage {
+	result := &claudecode.ResultMessage{
+		Subtype:       m.GetSubtype(),
+		DurationMs:    int(m.GetDurationMs()),
+		DurationAPIMs: int(m.GetDurationApiMs()),
+		IsError:       m.GetIsError(),
+		NumTurns:      int(m.GetNumTurns()),
+		SessionID:     m.GetSessionId(),
+		TotalCostUSD:  m.TotalCostUsd,
+		Usage:         fromStruct(m.GetUsage()),
+		Result:        m.Result,
+	}
+
+	for _, denial := range m.GetPermissionDenials() {
+		result.PermissionDenials = append(result.PermissionDenials, claudecode.PermissionDenial{
+			ToolName:  denial.GetToolName(),
+			ToolUseID: denial.GetToolUseId(),
+			ToolInput: fromStruct(denial.GetToolInput()),
+		})
+	}
+
+	if len(m.GetModelUsage()) > 0 {
+		result.ModelUsage = make(map[string]claudecode.ModelUsage, len(m.GetModelUsage()))
+		for model, u := range m.GetModelUsage() {
+			result.ModelUsage[model] = claudecode.ModelUsage{
+				InputTokens:              int(u.GetInputTokens()),
+				OutputTokens:             int(u.GetOutputTokens()),
+				CacheReadInputTokens:     int(u.GetCacheReadInputTokens()),
+				CacheCreationInputTokens: int(u.GetCacheCreationInputTokens()),
+				WebSearchRequests:        int(u.GetWebSearchRequests()),
+				CostUSD:                  u.GetCostUsd(),
+			}
+		}
+	}
+	return result
+}
+
+// ContentBlockToProto converts a content block to its protobuf form.
+func ContentBlockToProto(block claudecode.ContentBlock) (*claudecodev1.ContentBlock, error) {
+	switch b := block.(type) {
+	case *claudecode.TextBlock:
+		return &claudecodev1.ContentBlock{Block: &claudecodev1.ContentBlock_Text{
+			Text: &claudecodev1.TextBlock{Text: b.Text},
+		}}, nil
+
+	case *claudecode.ThinkingBlock:
+		return &claudecodev1.ContentBlock{Block: &claudecodev1.ContentBlock_Thinking{
+			Thinking: &claudecodev1.ThinkingBlock{Thinking: b.Thinking, Signature: b.Signature},
+		}}, nil
+
+	case *claudecode.ToolUseBlock:
+		input, err := toStruct(b.Input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input of tool %s: %w", b.Name, err)
+		}
+		return &claudecodev1.ContentBlock{Block: &claudecodev1.ContentBlock_ToolUse{
+			ToolUse: &claudecodev1.ToolUseBlock{Id: b.ID, Name: b.Name, Input: input},
+		}}, nil
+
+	case *claudecode.ToolResultBlock:
+		result := &claudecodev1.ToolResultBlock{ToolUseId: b.ToolUseID, IsError: b.IsError}
+		for _, part := range b.Content {
+			converted, err := resultContentToProto(part)
+			if err != nil {
+				return nil, err
+			}
+			result.Content = append(result.Content, converted)
+		}
+		return &claudecodev1.ContentBlock{Block: &claudecodev1.ContentBlock_ToolResult{ToolResult: result}}, nil
+
+	case *claudecode.UnknownBlock:
+		return &claudecodev1.ContentBlock{Block: &claudecodev1.ContentBlock_Unknown{
+			Unknown: &claudecodev1.UnknownBlock{Type: b.BlockType, Raw: b.Raw},
+		}}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot convert content block of type %T", block)
+	}
+}
+
+// ContentBlockFromProto converts a content block from its protobuf form.
+func ContentBlockFromProto(block *claudecodev1.ContentBlock) (claudecode.ContentBlock, error) {
+	switch b := block.GetBlock().(type) {
+	case *claudecodev1.ContentBlock_Text:
+		return &claudecode.TextBlock{Text: b.Text.GetText()}, nil
+
+	case *claudecodev1.ContentBlock_Thinking:
+		return &claudecode.ThinkingBlock{Thinking: b.Thinking.GetThinking(), Signature: b.Thinking.GetSignature()}, nil
+
+	case *claudecodev1.ContentBlock_ToolUse:
+		return &claudecode.ToolUseBlock{
+			ID:    b.ToolUse.GetId(),
+			Name:  b.ToolUse.GetName(),
+			Input: fromStruct(b.ToolUse.GetInput()),
+		}, nil
+
+	case *claudecodev1.ContentBlock_ToolResult:
+		result := &claudecode.ToolResultBlock{ToolUseID: b.ToolResult.GetToolUseId(), IsError: b.ToolResult.IsError}
+		for _, part := range b.ToolResult.GetContent() {
+			converted, err := resultContentFromProto(part)
+			if err != nil {
+				return nil, err
+			}
+			result.Content = append(result.Content, converted)
+		}
+		return result, nil
+
+	case *claudecodev1.ContentBlock_Unknown:
+		return &claudecode.UnknownBlock{BlockType: b.Unknown.GetType(), Raw: b.Unknown.GetRaw()}, nil
+
+	default:
+		return nil, fmt.Errorf("content block has no content")
+	}
+}
+
+func blocksToProto(blocks []claudecode.ContentBlock) ([]*claudecodev1.ContentBlock, error) {
+	var converted []*claudecodev1.ContentBlock
+	for _, block := range blocks {
+		b, err := ContentBlockToProto(block)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, b)
+	}
+	return converted, nil
+}
+
+func blocksFromProto(blocks []*claudecodev1.ContentBlock) ([]claudecode.ContentBlock, error) {
+	var converted []claudecode.ContentBlock
+	for _, block := range blocks {
+		b, err := ContentBlockFromProto(block)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, b)
+	}
+	return converted, nil
+}
+
+func resultContentToProto(part claudecode.ToolResultContent) (*claudecodev1.ToolResultContent, error) {
+	switch p := part.(type) {
+	case *claudecode.TextContent:
+		return &claudecodev1.ToolResultContent{Content: &claudecodev1.ToolResultContent_Text{Text: p.Text}}, nil
+	case *claudecode.ImageContent:
+		return &claudecodev1.ToolResultContent{Content: &claudecodev1.ToolResultContent_Image{
+			Image: &claudecodev1.ImageContent{MediaType: p.MediaType, Data: p.Data, Url: p.URL},
+		}}, nil
+	case *claudecode.JSONContent:
+		return &claudecodev1.ToolResultContent{Content: &claudecodev1.ToolResultContent_Json{Json: p.Raw}}, nil
+	default:
+		return nil, fmt.Errorf("cannot convert tool result content of type %T", part)
+	}
+}
+
+func resultContentFromProto(part *claudecodev1.ToolResultContent) (claudecode.ToolResultContent, error) {
+	switch p := part.GetContent().(type) {
+	case *claudecodev1.ToolResultContent_Text:
+		return &claudecode.TextContent{Text: p.Text}, nil
+	case *claudecodev1.ToolResultContent_Image:
+		return &claudecode.ImageContent{MediaType: p.Image.GetMediaType(), Data: p.Image.GetData(), URL: p.Image.GetUrl()}, nil
+	case *claudecodev1.ToolResultContent_Json:
+		return &claudecode.JSONContent{Raw: p.Json}, nil
+	default:
+		return nil, fmt.Errorf("tool result content has no content")
+	}
+}
+
+// TranscriptToProto converts a transcript to its protobuf form.
+func TranscriptToProto(tr *transcript.Transcript) (*claudecodev1.Transcript, error) {
+	converted := &claudecodev1.Transcript{StartedAt: timestamppb.New(tr.StartedAt())}
+	for _, entry := range tr.Entries() {
+		msg, err := MessageToProto(entry.Message)
+		if err != nil {
+			return nil, err
+		}
+		converted.Entries = append(converted.Entries, &claudecodev1.TranscriptEntry{
+			Time:    timestamppb.New(entry.Time),
+			Message: msg,
+		})
+	}
+	return converted, nil
+}
+
+// TranscriptFromProto converts a transcript from its protobuf form.
+func TranscriptFromProto(tr *claudecodev1.Transcript) (*transcript.Transcript, error) {
+	entries := make([]transcript.Entry, 0, len(tr.GetEntries()))
+	for i, entry := range tr.GetEntries() {
+		msg, err := MessageFromProto(entry.GetMessage())
+		if err != nil {
+			return nil, fmt.Errorf("transcript entry %d: %w", i, err)
+		}
+		entries = append(entries, transcript.Entry{Time: entry.GetTime().AsTime(), Message: msg})
+	}
+	return transcript.Restore(tr.GetStartedAt().AsTime(), entries), nil
+}
+
+// EventToProto converts an event published by the events package to its
+// protobuf form.
+func EventToProto(event events.Event) (*claudecodev1.StreamEvent, error) {
+	msg, err := MessageToProto(event.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &claudecodev1.StreamEvent{
+		Time:      timestamppb.New(event.Time),
+		Source:    event.Source,
+		SessionId: event.SessionID,
+		Seq:       event.Seq,
+		Message:   msg,
+	}, nil
+}
+
+// EventFromProto converts an event from its protobuf form.
+func EventFromProto(event *claudecodev1.StreamEvent) (events.Event, error) {
+	msg, err := MessageFromProto(event.GetMessage())
+	if err != nil {
+		return events.Event{}, err
+	}
+	return events.Event{
+		Time:      event.GetTime().AsTime(),
+		Source:    event.GetSource(),
+		SessionID: event.GetSessionId(),
+		Seq:       event.GetSeq(),
+		Message:   msg,
+	}, nil
+}
+
+// ProtoEncoding encodes events published by the events package as
+// serialized StreamEvent messages.
+//
+//	sink := events.NewKafkaSink(producer, "agent-events")
+//	sink.Encoding = grpcservice.ProtoEncoding
+var ProtoEncoding = events.Encoding{
+	ContentType: "application/x-protobuf",
+	Marshal: func(event events.Event) ([]byte, error) {
+		converted, err := EventToProto(event)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(converted)
+	},
+}
+
+// toStruct converts a JSON object to a Struct. Values of any type that
+// encodes as JSON are accepted.
+func toStruct(m map[string]any) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	if s, err := structpb.NewStruct(m); err == nil {
+		return s, nil
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// fromStruct converts a Struct to a JSON object, with numbers as float64
+// as encoding/json decodes them.
+func fromStruct(s *structpb.Struct) map[string]any {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
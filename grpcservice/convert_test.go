@@ -0,0 +1,182 @@
+package grpcservice
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/events"
+	"github.com/jrossi/claude-code-sdk-golang/grpcservice/claudecodev1"
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMessageProtoRoundTrip(t *testing.T) {
+	cost := 0.25
+	text := "done"
+	isError := true
+
+	tests := []struct {
+		name string
+		msg  claudecode.Message
+	}{
+		{"user text", &claudecode.UserMessage{Content: "Hello"}},
+		{"user blocks", &claudecode.UserMessage{Content: "Tool results: 2 items", Blocks: []claudecode.ContentBlock{
+			&claudecode.ToolResultBlock{ToolUseID: "tool-1", Content: []claudecode.ToolResultContent{&claudecode.TextContent{Text: "ok"}}},
+			&claudecode.ToolResultBlock{ToolUseID: "tool-2", IsError: &isError, Content: []claudecode.ToolResultContent{
+				&claudecode.TextContent{Text: "see image"},
+				&claudecode.ImageContent{MediaType: "image/png", Data: "aGk="},
+				&claudecode.JSONContent{Raw: json.RawMessage(`{"type":"document"}`)},
+			}},
+		}}},
+		{"assistant", &claudecode.AssistantMessage{
+			ID:    "msg_1",
+			Model: "claude-sonnet-4",
+			Usage: map[string]any{"output_tokens": float64(10)},
+			Content: []claudecode.ContentBlock{
+				&claudecode.ThinkingBlock{Thinking: "hmm", Signature: "sig"},
+				&claudecode.TextBlock{Text: "Let me look"},
+				&claudecode.ToolUseBlock{ID: "tool-1", Name: "Read", Input: map[string]any{"file_path": "main.go", "limit": float64(20)}},
+				&claudecode.UnknownBlock{BlockType: "server_tool_use", Raw: json.RawMessage(`{"id":"srv-1","type":"server_tool_use"}`)},
+			},
+		}},
+		{"system", &claudecode.SystemMessage{Subtype: "init", Data: map[string]any{"type": "system", "subtype": "init", "tools": []any{"Read"}}}},
+		{"result", &claudecode.ResultMessage{
+			Subtype:           "success",
+			DurationMs:        1200,
+			NumTurns:          2,
+			SessionID:         "s1",
+			TotalCostUSD:      &cost,
+			Result:            &text,
+			PermissionDenials: []claudecode.PermissionDenial{{ToolName: "Bash", ToolUseID: "tool-3", ToolInput: map[string]any{"command": "rm -rf /"}}},
+			ModelUsage:        map[string]claudecode.ModelUsage{"claude-sonnet-4": {OutputTokens: 10, CostUSD: 0.25}},
+		}},
+		{"progress", &claudecode.ProgressEvent{ToolUseID: "tool-1", ToolName: "Bash", Elapsed: 2500 * time.Millisecond, Status: claudecode.ProgressStatusRunning}},
+		{"unknown", &claudecode.UnknownMessage{MessageType: "stream_event", Raw: json.RawMessage(`{"event":{"delta":"x"},"type":"stream_event"}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, err := MessageToProto(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Go through the wire format, as a service in another language
+			// would.
+			data, err := proto.Marshal(converted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &claudecodev1.TypedMessage{}
+			if err := proto.Unmarshal(data, decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := MessageFromProto(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(msg, tt.msg) {
+				t.Errorf("Expected %#v, got %#v", tt.msg, msg)
+			}
+		})
+	}
+}
+
+func TestMessageToProtoStructValues(t *testing.T) {
+	// Values that structpb does not accept directly are converted through
+	// their JSON encoding.
+	msg := &claudecode.ToolUseBlock{ID: "t1", Name: "Edit", Input: map[string]any{
+		"lines":   []string{"a", "b"},
+		"options": map[string]int{"n": 1},
+	}}
+	converted, err := ContentBlockToProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := converted.GetToolUse().GetInput().AsMap()
+	expected := map[string]any{"lines": []any{"a", "b"}, "options": map[string]any{"n": float64(1)}}
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Expected %v, got %v", expected, input)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	if _, err := MessageFromProto(&claudecodev1.TypedMessage{}); err == nil {
+		t.Error("Expected an empty message to be rejected")
+	}
+	empty := &claudecodev1.TypedMessage{Message: &claudecodev1.TypedMessage_Assistant{Assistant: &claudecodev1.AssistantMessage{
+		Content: []*claudecodev1.ContentBlock{{}},
+	}}}
+	if _, err := MessageFromProto(empty); err == nil {
+		t.Error("Expected an empty content block to be rejected")
+	}
+
+	bad := &claudecode.AssistantMessage{Usage: map[string]any{"ch": make(chan int)}}
+	if _, err := MessageToProto(bad); err == nil || !strings.Contains(err.Error(), "invalid usage") {
+		t.Errorf("Expected an invalid usage error, got %v", err)
+	}
+	if _, err := MessageToProto(nil); err == nil {
+		t.Error("Expected a nil message to be rejected")
+	}
+}
+
+func TestTranscriptProtoRoundTrip(t *testing.T) {
+	tr := transcript.New("Hello")
+	tr.Add(&claudecode.AssistantMessage{Content: []claudecode.ContentBlock{&claudecode.TextBlock{Text: "Hi"}}})
+
+	converted, err := TranscriptToProto(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := TranscriptFromProto(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !restored.StartedAt().Equal(tr.StartedAt()) {
+		t.Errorf("Expected start time %v, got %v", tr.StartedAt(), restored.StartedAt())
+	}
+	want, got := tr.Entries(), restored.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || !reflect.DeepEqual(got[i].Message, want[i].Message) {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestProtoEncoding(t *testing.T) {
+	event := events.Event{
+		Time:      time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Source:    "worker-1",
+		SessionID: "s1",
+		Seq:       3,
+		Message:   &claudecode.AssistantMessage{Content: []claudecode.ContentBlock{&claudecode.TextBlock{Text: "Hi"}}},
+	}
+	data, err := ProtoEncoding.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &claudecodev1.StreamEvent{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := EventFromProto(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored, event) {
+		t.Errorf("Expected %#v, got %#v", event, restored)
+	}
+
+	if _, err := ProtoEncoding.Marshal(events.Event{}); err == nil {
+		t.Error("Expected an event without a message to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package grpcservice
+
+import (
+	"encoding/json"
+	"fmt"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/grpcservice/claudecodev1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// messageEvent encodes a message as an event. Messages that cannot be
+// encoded are reported as error events.
+func messageEvent(msg claudecode.Message) *claudecodev1.Event {
+	data, err := messageData(msg)
+	if err != nil {
+		return errorEvent(fmt.Errorf("failed to encode %s message: %w", msg.Type(), err))
+	}
+
+	return &claudecodev1.Event{
+		Event: &claudecodev1.Event_Message{
+			Message: &claudecodev1.Message{Type: msg.Type(), Data: data},
+		},
+	}
+}
+
+// errorEvent encodes an error reported on the stream.
+func errorEvent(err error) *claudecodev1.Event {
+	return &claudecodev1.Event{
+		Event: &claudecodev1.Event_Error{
+			Error: &claudecodev1.Error{
+				Message: err.Error(),
+				Kind:    string(claudecode.ErrorKind(err)),
+			},
+		},
+	}
+}
+
+// messageData encodes a message as a Struct. The message's JSON encoding
+// holds its type, and the type of each of its content blocks.
+func messageData(msg claudecode.Message) (*structpb.Struct, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
@@ -0,0 +1,312 @@
+// Package grpcservice serves Claude Code queries and interactive sessions
+// over gRPC, so that services written in other languages can use the SDK
+// over the network. The service is defined in
+// proto/claudecode/v1/claudecode.proto; its generated code is in the
+// claudecodev1 package.
+//
+//	client := claudecode.NewClient(claudecode.ClientOptions{})
+//	server := grpc.NewServer()
+//	claudecodev1.RegisterClaudeCodeServer(server, grpcservice.NewServer(client, grpcservice.Options{}))
+//
+// Query streams the messages of a single prompt. Session is bidirectional:
+// callers start the session, then send prompts and interrupts while
+// receiving each turn's messages.
+//
+// Each query costs money and may run tools on the host, so the service must
+// not be exposed without Authorize. The tools a request allows and the
+// session it resumes are ignored unless Options allow them.
+//
+// proto/claudecode/v1/messages.proto defines TypedMessage, a protobuf
+// schema with a message for each message and content block type, and a
+// Transcript. MessageToProto, MessageFromProto, TranscriptToProto, and
+// TranscriptFromProto convert between them and the SDK's types, so that
+// streams and transcripts can be exchanged with other languages, over
+// gRPC or any other transport, without a JSON mapping.
+package grpcservice
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/grpcservice/claudecodev1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures the server.
+type Options struct {
+	// Authorize, if set, is called with the incoming metadata before each
+	// query or session. An error rejects the call; errors that are not
+	// gRPC statuses are reported as Unauthenticated. Without it, every
+	// call is accepted, so the server must not be exposed to untrusted
+	// networks.
+	Authorize func(ctx context.Context, md metadata.MD) error
+
+	// QueryOptions, if set, builds the options for each query or session,
+	// replacing the default of applying the request's options to
+	// NewOptions. An error rejects the call with InvalidArgument.
+	QueryOptions func(ctx context.Context, req *claudecodev1.QueryOptions) (*claudecode.Options, error)
+
+	// AllowClientTools applies a request's allowed_tools by default.
+	// Without it, any caller could allow itself tools such as Bash or
+	// Write, so the field is ignored and the CLI's permission settings
+	// apply.
+	AllowClientTools bool
+
+	// AllowResume applies a request's resume by default. Without it, any
+	// caller could continue a session whose ID it learned, so the field is
+	// ignored and every call starts a new session.
+	AllowResume bool
+}
+
+// Server implements the ClaudeCode gRPC service with a client.
+type Server struct {
+	claudecodev1.UnimplementedClaudeCodeServer
+
+	client *claudecode.Client
+	opts   Options
+}
+
+// NewServer returns a server that runs queries and sessions with client.
+func NewServer(client *claudecode.Client, opts Options) *Server {
+	return &Server{client: client, opts: opts}
+}
+
+// Query runs a single prompt, streaming its messages and errors until it
+// finishes. Cancelling the call stops the CLI.
+func (s *Server) Query(req *claudecodev1.QueryRequest, stream claudecodev1.ClaudeCode_QueryServer) error {
+	ctx := stream.Context()
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.GetPrompt()) == "" {
+		return status.Error(codes.InvalidArgument, "prompt is required")
+	}
+
+	options, err := s.queryOptions(ctx, req.GetOptions())
+	if err != nil {
+		return err
+	}
+
+	query, err := s.client.Query(ctx, req.GetPrompt(), options)
+	if err != nil {
+		return statusError(err)
+	}
+	defer query.Close()
+
+	messages := query.Messages()
+	errs := query.Errors()
+	for messages != nil || errs != nil {
+		var event *claudecodev1.Event
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			event = messageEvent(msg)
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			event = errorEvent(err)
+		}
+
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Session runs an interactive session. The first request must start it;
+// later requests send prompts or interrupt the current turn. When the
+// caller closes its side of the stream, the session ends once the turns
+// already sent have finished.
+func (s *Server) Session(stream claudecodev1.ClaudeCode_SessionServer) error {
+	ctx := stream.Context()
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "first request must start the session")
+	}
+
+	options, err := s.queryOptions(ctx, start)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.client.NewSession(ctx, options)
+	if err != nil {
+		return statusError(err)
+	}
+	defer session.Close()
+
+	requests := make(chan *claudecodev1.SessionRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// pending counts turns sent but not yet ended by a result message
+	pending := 0
+	closing := false
+
+	messages := session.Receive()
+	errs := session.Errors()
+	for messages != nil || errs != nil {
+		var event *claudecodev1.Event
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			event = messageEvent(msg)
+			if _, ok := msg.(*claudecode.ResultMessage); ok && pending > 0 {
+				pending--
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			event = errorEvent(err)
+
+		case req := <-requests:
+			switch r := req.GetRequest().(type) {
+			case *claudecodev1.SessionRequest_Prompt:
+				if err := session.Send(ctx, r.Prompt); err != nil {
+					return statusError(err)
+				}
+				pending++
+			case *claudecodev1.SessionRequest_Interrupt:
+				if err := session.Interrupt(ctx); err != nil {
+					event = errorEvent(err)
+				}
+			case *claudecodev1.SessionRequest_Start:
+				return status.Error(codes.FailedPrecondition, "session already started")
+			default:
+				return status.Error(codes.InvalidArgument, "empty session request")
+			}
+
+		case err := <-recvErr:
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			closing = true
+		}
+
+		if event != nil {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+		if closing && pending == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// authorize checks the incoming metadata with the Authorize hook.
+func (s *Server) authorize(ctx context.Context) error {
+	if s.opts.Authorize == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if err := s.opts.Authorize(ctx, md); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// queryOptions builds the options for a query or session.
+func (s *Server) queryOptions(ctx context.Context, req *claudecodev1.QueryOptions) (*claudecode.Options, error) {
+	build := s.opts.QueryOptions
+	if build == nil {
+		build = s.defaultOptions
+	}
+
+	options, err := build(ctx, req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return options, nil
+}
+
+// defaultOptions applies the request's options to new options, with its
+// tools and resume only if Options allow them.
+func (s *Server) defaultOptions(_ context.Context, req *claudecodev1.QueryOptions) (*claudecode.Options, error) {
+	options := claudecode.NewOptions()
+	if req.GetModel() != "" {
+		options.WithModel(req.GetModel())
+	}
+	if req.GetSystemPrompt() != "" {
+		options.WithSystemPrompt(req.GetSystemPrompt())
+	}
+	if req.GetMaxTurns() > 0 {
+		options.WithMaxTurns(int(req.GetMaxTurns()))
+	}
+	if s.opts.AllowClientTools && len(req.GetAllowedTools()) > 0 {
+		options.WithAllowedTools(req.GetAllowedTools()...)
+	}
+	if s.opts.AllowResume && req.GetResume() != "" {
+		options.WithResume(req.GetResume())
+	}
+	return options, nil
+}
+
+// statusError converts an error starting a query or session to a gRPC
+// status, choosing the code from the error's kind.
+func statusError(err error) error {
+	code := codes.Internal
+	switch claudecode.ErrorKind(err) {
+	case claudecode.KindUsage:
+		code = codes.InvalidArgument
+	case claudecode.KindCLINotFound, claudecode.KindCLIVersion, claudecode.KindAuth:
+		code = codes.FailedPrecondition
+	case claudecode.KindConnection, claudecode.KindProcess:
+		code = codes.Unavailable
+	case claudecode.KindRateLimit, claudecode.KindResourceLimit, claudecode.KindBudgetExceeded, claudecode.KindBufferOverflow:
+		code = codes.ResourceExhausted
+	case claudecode.KindPermissionDenied:
+		code = codes.PermissionDenied
+	case claudecode.KindTimeout:
+		code = codes.DeadlineExceeded
+	case claudecode.KindCanceled:
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,266 @@
+package grpcservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/grpcservice/claudecodev1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient returns a client whose CLI echoes its prompt, or in a
+// session answers each line of input with "turn N".
+func newTestClient(t *testing.T) *claudecode.Client {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	script := `#!/bin/sh
+streaming=
+for arg; do
+	[ "$arg" = "--input-format" ] && streaming=1
+	prompt="$arg"
+done
+if [ -n "$streaming" ]; then
+	n=0
+	while read -r line; do
+		n=$((n+1))
+		echo '{"type":"assistant","message":{"content":[{"type":"text","text":"turn '"$n"'"}]}}'
+		echo '{"type":"result","subtype":"success","session_id":"s"}'
+	done
+	exit 0
+fi
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$prompt"'"}]}}'
+echo '{"type":"result","subtype":"success","session_id":"s"}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return claudecode.NewClient(claudecode.ClientOptions{CLIPath: cliPath})
+}
+
+// dial serves server over an in-memory listener and returns a client for it.
+func dial(t *testing.T, server *Server) claudecodev1.ClaudeCodeClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	claudecodev1.RegisterClaudeCodeServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return claudecodev1.NewClaudeCodeClient(conn)
+}
+
+// eventStream is a stream of events from either RPC.
+type eventStream interface {
+	Recv() (*claudecodev1.Event, error)
+}
+
+// recvText receives events until a result, returning the assistant text.
+func recvText(t *testing.T, stream eventStream) string {
+	t.Helper()
+
+	var text string
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if e := event.GetError(); e != nil {
+			t.Fatalf("Unexpected error event: %v", e)
+		}
+
+		msg := event.GetMessage()
+		switch msg.GetType() {
+		case "assistant":
+			content := msg.GetData().GetFields()["content"].GetListValue().GetValues()
+			if len(content) != 1 {
+				t.Fatalf("Expected one content block, got %v", msg.GetData())
+			}
+			block := content[0].GetStructValue().GetFields()
+			if got := block["type"].GetStringValue(); got != "text" {
+				t.Errorf("Expected text block, got %q", got)
+			}
+			text = block["text"].GetStringValue()
+		case "result":
+			return text
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	client := dial(t, NewServer(newTestClient(t), Options{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Query(ctx, &claudecodev1.QueryRequest{Prompt: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recvText(t, stream); got != "hello" {
+		t.Errorf("Expected hello, got %q", got)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected end of stream, got %v", err)
+	}
+}
+
+func TestQueryRejects(t *testing.T) {
+	server := NewServer(newTestClient(t), Options{
+		Authorize: func(_ context.Context, md metadata.MD) error {
+			if token := md.Get("authorization"); len(token) == 0 || token[0] != "Bearer secret" {
+				return errors.New("invalid token")
+			}
+			return nil
+		},
+	})
+	client := dial(t, server)
+
+	tests := []struct {
+		name  string
+		token string
+		req   *claudecodev1.QueryRequest
+		code  codes.Code
+	}{
+		{"no token", "", &claudecodev1.QueryRequest{Prompt: "hello"}, codes.Unauthenticated},
+		{"wrong token", "Bearer nope", &claudecodev1.QueryRequest{Prompt: "hello"}, codes.Unauthenticated},
+		{"no prompt", "Bearer secret", &claudecodev1.QueryRequest{}, codes.InvalidArgument},
+		{"authorized", "Bearer secret", &claudecodev1.QueryRequest{Prompt: "hello"}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.token)
+			}
+
+			stream, err := client.Query(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for err == nil {
+				_, err = stream.Recv()
+			}
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("Expected %v, got %v (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	client := dial(t, NewServer(newTestClient(t), Options{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := &claudecodev1.SessionRequest{
+		Request: &claudecodev1.SessionRequest_Start{Start: &claudecodev1.QueryOptions{}},
+	}
+	if err := stream.Send(start); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"turn 1", "turn 2"} {
+		prompt := &claudecodev1.SessionRequest{
+			Request: &claudecodev1.SessionRequest_Prompt{Prompt: "next"},
+		}
+		if err := stream.Send(prompt); err != nil {
+			t.Fatal(err)
+		}
+		if got := recvText(t, stream); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+
+	// Closing the request side ends the session
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected end of stream, got %v", err)
+	}
+}
+
+func TestSessionRequiresStart(t *testing.T) {
+	client := dial(t, NewServer(newTestClient(t), Options{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Session(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := &claudecodev1.SessionRequest{
+		Request: &claudecodev1.SessionRequest_Prompt{Prompt: "hello"},
+	}
+	if err := stream.Send(prompt); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = stream.Recv()
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestDefaultOptionsIgnoresClientGrants(t *testing.T) {
+	req := &claudecodev1.QueryOptions{Model: "sonnet", AllowedTools: []string{"Bash"}, Resume: "other-session"}
+
+	options, err := NewServer(nil, Options{}).defaultOptions(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.AllowedTools) != 0 || options.Resume != nil {
+		t.Errorf("Expected the request's tools and resume to be ignored, got %v and %v", options.AllowedTools, options.Resume)
+	}
+	if options.Model == nil || *options.Model != "sonnet" {
+		t.Errorf("Expected the model to be applied, got %v", options.Model)
+	}
+
+	server := NewServer(nil, Options{AllowClientTools: true, AllowResume: true})
+	options, err = server.defaultOptions(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.AllowedTools) != 1 || options.AllowedTools[0] != "Bash" || options.Resume == nil || *options.Resume != "other-session" {
+		t.Errorf("Expected the request's tools and resume when allowed, got %v and %v", options.AllowedTools, options.Resume)
+	}
+}
@@ -0,0 +1,48 @@
+package claudecode
+
+import (
+	types2 "github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Re-export hook types from internal package
+type (
+	// HookEvent identifies the point in the agent loop at which a hook runs.
+	HookEvent = types2.HookEvent
+
+	// HookInput contains the data the CLI passes to a hook callback.
+	HookInput = types2.HookInput
+
+	// HookOutput is the decision a hook callback returns to the CLI.
+	HookOutput = types2.HookOutput
+
+	// HookCallback is a Go function invoked by the CLI when a hook event fires.
+	HookCallback = types2.HookCallback
+
+	// HookMatcher associates hook callbacks with the tools they apply to.
+	HookMatcher = types2.HookMatcher
+)
+
+// Re-export hook event constants
+const (
+	// HookEventPreToolUse runs before a tool is executed.
+	HookEventPreToolUse = types2.HookEventPreToolUse
+
+	// HookEventPostToolUse runs after a tool has produced its result.
+	HookEventPostToolUse = types2.HookEventPostToolUse
+
+	// HookEventUserPromptSubmit runs when a user prompt is submitted.
+	HookEventUserPromptSubmit = types2.HookEventUserPromptSubmit
+
+	// HookEventStop runs when the main agent finishes responding.
+	HookEventStop = types2.HookEventStop
+
+	// HookEventSubagentStop runs when a subagent finishes responding.
+	HookEventSubagentStop = types2.HookEventSubagentStop
+
+	// HookEventPreCompact runs before the conversation is compacted.
+	HookEventPreCompact = types2.HookEventPreCompact
+)
+
+// PreToolUseOutput builds a HookOutput that allows ("allow"), denies ("deny"),
+// or defers to the user ("ask") a tool call, optionally replacing its input.
+var PreToolUseOutput = types2.PreToolUseOutput
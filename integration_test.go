@@ -224,9 +224,9 @@ func TestRealCLIWithTools(t *testing.T) {
 					case *ToolResultBlock:
 						sawToolResult = true
 						if b.IsError != nil && *b.IsError {
-							t.Logf("Tool error for %s: %s", b.ToolUseID, safeStringPtr(b.Content))
+							t.Logf("Tool error for %s: %s", b.ToolUseID, b.Text())
 						} else {
-							t.Logf("Tool result for %s: %s", b.ToolUseID, safeStringPtr(b.Content))
+							t.Logf("Tool result for %s: %s", b.ToolUseID, b.Text())
 						}
 					}
 				}
@@ -0,0 +1,68 @@
+package claudecode
+
+import (
+	"context"
+
+	client2 "github.com/jrossi/claude-code-sdk-golang/client"
+)
+
+// Request is a query, session, or session prompt about to start, as seen
+// by an Interceptor, which may change its fields before proceeding.
+type Request = client2.Request
+
+// RequestKind is the kind of call a Request describes.
+type RequestKind = client2.RequestKind
+
+const (
+	// RequestQuery is a one-shot query.
+	RequestQuery = client2.RequestQuery
+
+	// RequestSession is the start of an interactive session.
+	RequestSession = client2.RequestSession
+
+	// RequestSend is a prompt sent to a running session.
+	RequestSend = client2.RequestSend
+)
+
+// QueryHandler starts the call a Request describes and returns its stream.
+// For sessions and sent prompts, it is the session's stream.
+type QueryHandler func(ctx context.Context, req *Request) (*QueryStream, error)
+
+// Interceptor wraps the start of a client's queries, sessions, and session
+// prompts, like a gRPC interceptor, for cross-cutting concerns such as
+// logging, credentials, prompt redaction, quotas, and caching. It may
+// inspect or change the request, call next to proceed, act on the result,
+// or return without calling next to refuse the call or, for queries,
+// answer it another way. Sessions start only if next is called, and the
+// stream it returned must be returned with them.
+//
+// Example:
+//
+//	client.Use(func(ctx context.Context, req *claudecode.Request, next claudecode.QueryHandler) (*claudecode.QueryStream, error) {
+//		req.Prompt = redact(req.Prompt)
+//		start := time.Now()
+//		stream, err := next(ctx, req)
+//		log.Printf("%s started in %v: %v", req.Kind, time.Since(start), err)
+//		return stream, err
+//	})
+type Interceptor func(ctx context.Context, req *Request, next QueryHandler) (*QueryStream, error)
+
+// Use adds interceptors to the client, for queries and sessions started
+// after the call. The first interceptor added is the outermost.
+func (c *Client) Use(interceptors ...Interceptor) {
+	for _, interceptor := range interceptors {
+		c.internal.Use(func(ctx context.Context, req *Request, next client2.Handler) (*client2.QueryStream, error) {
+			stream, err := interceptor(ctx, req, func(ctx context.Context, req *Request) (*QueryStream, error) {
+				internal, err := next(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				return wrapQueryStream(internal), nil
+			})
+			if stream == nil {
+				return nil, err
+			}
+			return stream.internal, err
+		})
+	}
+}
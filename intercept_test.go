@@ -0,0 +1,41 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientUse(t *testing.T) {
+	client := NewClient(ClientOptions{CLIPath: writeEchoCLI(t)})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var kinds []RequestKind
+	client.Use(func(ctx context.Context, req *Request, next QueryHandler) (*QueryStream, error) {
+		kinds = append(kinds, req.Kind)
+		req.Prompt = strings.ReplaceAll(req.Prompt, "secret", "redacted")
+		return next(ctx, req)
+	})
+
+	result, err := client.QuerySync(ctx, "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "redacted" {
+		t.Errorf("Expected the CLI to receive the intercepted prompt, got %q", result.Text)
+	}
+	if len(kinds) != 1 || kinds[0] != RequestQuery {
+		t.Errorf("Expected one query request, got %v", kinds)
+	}
+
+	quota := errors.New("quota exceeded")
+	client.Use(func(ctx context.Context, req *Request, next QueryHandler) (*QueryStream, error) {
+		return nil, quota
+	})
+	if _, err := client.Query(ctx, "hello", nil); !errors.Is(err, quota) {
+		t.Errorf("Expected the refusing interceptor's error, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package models lists the Claude models this SDK knows about: their IDs
+// and aliases, context windows, and prices. Options.Validate rejects model
+// names not found here, unless the options allow unknown models; Register
+// adds models released after this SDK.
+//
+//	if m, ok := models.Lookup("sonnet"); ok {
+//		fmt.Println(m.ID, m.ContextWindow, m.Pricing.InputPerMTok)
+//	}
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// LongContextSuffix selects a model's long context window, as in
+// "sonnet[1m]".
+const LongContextSuffix = "[1m]"
+
+// Pricing is what a model costs, in US dollars per million tokens.
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+
+	// CacheWritePerMTok is the price of writing to the five-minute
+	// prompt cache, and CacheReadPerMTok of reading from it.
+	CacheWritePerMTok float64
+	CacheReadPerMTok  float64
+}
+
+// Cost returns the price in US dollars of the given token counts.
+func (p Pricing) Cost(inputTokens, outputTokens, cacheWriteTokens, cacheReadTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok +
+		float64(outputTokens)*p.OutputPerMTok +
+		float64(cacheWriteTokens)*p.CacheWritePerMTok +
+		float64(cacheReadTokens)*p.CacheReadPerMTok) / 1_000_000
+}
+
+// Model describes a Claude model.
+type Model struct {
+	// ID is the model's full, dated name, such as
+	// "claude-sonnet-4-5-20250929".
+	ID string
+
+	// Aliases are other names for the model, such as "claude-sonnet-4-5",
+	// and the CLI's "sonnet", "opus", and "haiku" for the model each
+	// currently selects.
+	Aliases []string
+
+	// Family is "opus", "sonnet", or "haiku".
+	Family string
+
+	// ContextWindow is the model's context window in tokens, and
+	// LongContextWindow the larger window selected with LongContextSuffix,
+	// or zero if the model has none.
+	ContextWindow     int
+	LongContextWindow int
+
+	Pricing Pricing
+
+	// Deprecated reports whether the model is scheduled for retirement.
+	Deprecated bool
+}
+
+// Names returns the model's ID followed by its aliases.
+func (m Model) Names() []string {
+	return append([]string{m.ID}, m.Aliases...)
+}
+
+var (
+	opusPricing     = Pricing{InputPerMTok: 15, OutputPerMTok: 75, CacheWritePerMTok: 18.75, CacheReadPerMTok: 1.50}
+	opus45Pricing   = Pricing{InputPerMTok: 5, OutputPerMTok: 25, CacheWritePerMTok: 6.25, CacheReadPerMTok: 0.50}
+	sonnetPricing   = Pricing{InputPerMTok: 3, OutputPerMTok: 15, CacheWritePerMTok: 3.75, CacheReadPerMTok: 0.30}
+	haiku45Pricing  = Pricing{InputPerMTok: 1, OutputPerMTok: 5, CacheWritePerMTok: 1.25, CacheReadPerMTok: 0.10}
+	haiku35Pricing  = Pricing{InputPerMTok: 0.80, OutputPerMTok: 4, CacheWritePerMTok: 1, CacheReadPerMTok: 0.08}
+	haiku3Pricing   = Pricing{InputPerMTok: 0.25, OutputPerMTok: 1.25, CacheWritePerMTok: 0.30, CacheReadPerMTok: 0.03}
+	standardContext = 200_000
+	longContext     = 1_000_000
+)
+
+// builtin lists the models known to this release, newest first within
+// each family.
+var builtin = []Model{
+	{ID: "claude-opus-4-5-20251101", Aliases: []string{"claude-opus-4-5", "opus"}, Family: "opus", ContextWindow: standardContext, Pricing: opus45Pricing},
+	{ID: "claude-opus-4-1-20250805", Aliases: []string{"claude-opus-4-1"}, Family: "opus", ContextWindow: standardContext, Pricing: opusPricing},
+	{ID: "claude-opus-4-20250514", Aliases: []string{"claude-opus-4-0", "claude-opus-4"}, Family: "opus", ContextWindow: standardContext, Pricing: opusPricing},
+	{ID: "claude-3-opus-20240229", Aliases: []string{"claude-3-opus-latest"}, Family: "opus", ContextWindow: standardContext, Pricing: opusPricing, Deprecated: true},
+
+	{ID: "claude-sonnet-4-5-20250929", Aliases: []string{"claude-sonnet-4-5", "sonnet"}, Family: "sonnet", ContextWindow: standardContext, LongContextWindow: longContext, Pricing: sonnetPricing},
+	{ID: "claude-sonnet-4-20250514", Aliases: []string{"claude-sonnet-4-0", "claude-sonnet-4"}, Family: "sonnet", ContextWindow: standardContext, LongContextWindow: longContext, Pricing: sonnetPricing},
+	{ID: "claude-3-7-sonnet-20250219", Aliases: []string{"claude-3-7-sonnet-latest"}, Family: "sonnet", ContextWindow: standardContext, Pricing: sonnetPricing, Deprecated: true},
+	{ID: "claude-3-5-sonnet-20241022", Aliases: []string{"claude-3-5-sonnet-latest"}, Family: "sonnet", ContextWindow: standardContext, Pricing: sonnetPricing, Deprecated: true},
+	{ID: "claude-3-5-sonnet-20240620", Family: "sonnet", ContextWindow: standardContext, Pricing: sonnetPricing, Deprecated: true},
+
+	{ID: "claude-haiku-4-5-20251001", Aliases: []string{"claude-haiku-4-5", "haiku"}, Family: "haiku", ContextWindow: standardContext, Pricing: haiku45Pricing},
+	{ID: "claude-3-5-haiku-20241022", Aliases: []string{"claude-3-5-haiku-latest"}, Family: "haiku", ContextWindow: standardContext, Pricing: haiku35Pricing},
+	{ID: "claude-3-haiku-20240307", Family: "haiku", ContextWindow: standardContext, Pricing: haiku3Pricing},
+}
+
+// registry holds the known models by lowercase name.
+var registry = struct {
+	sync.RWMutex
+	models []Model
+	byName map[string]int
+}{byName: map[string]int{}}
+
+func init() {
+	for _, m := range builtin {
+		Register(m)
+	}
+}
+
+// Register adds a model, such as one released after this SDK, or replaces
+// the model with the same ID. Its aliases take over from any model that
+// had them, so registering a new "sonnet" moves the alias to it.
+func Register(m Model) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	i, ok := registry.byName[strings.ToLower(m.ID)]
+	if ok && strings.EqualFold(registry.models[i].ID, m.ID) {
+		for name, j := range registry.byName {
+			if j == i {
+				delete(registry.byName, name)
+			}
+		}
+		registry.models[i] = m
+	} else {
+		i = len(registry.models)
+		registry.models = append(registry.models, m)
+	}
+
+	for _, name := range m.Names() {
+		key := strings.ToLower(name)
+		if prev, ok := registry.byName[key]; ok && prev != i {
+			other := &registry.models[prev]
+			other.Aliases = slices.DeleteFunc(slices.Clone(other.Aliases), func(alias string) bool {
+				return strings.EqualFold(alias, name)
+			})
+		}
+		registry.byName[key] = i
+	}
+}
+
+// All returns every known model, in the order registered.
+func All() []Model {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	models := make([]Model, len(registry.models))
+	for i, m := range registry.models {
+		m.Aliases = slices.Clone(m.Aliases)
+		models[i] = m
+	}
+	return models
+}
+
+// providerPattern matches the Amazon Bedrock form of a model ID, such as
+// "us.anthropic.claude-sonnet-4-5-20250929-v1:0".
+var providerPattern = regexp.MustCompile(`^(?:[a-z]+\.)?anthropic\.(.+?)-v\d+(?::\d+)?$`)
+
+// canonical reduces a model name as given to the CLI to the name it is
+// registered under: without the long context suffix, in lowercase, and
+// without the decorations of Bedrock and Vertex AI IDs, such as
+// "claude-sonnet-4-5@20250929".
+func canonical(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(name, LongContextSuffix)
+	if m := providerPattern.FindStringSubmatch(name); m != nil {
+		name = m[1]
+	}
+	return strings.Replace(name, "@", "-", 1)
+}
+
+// Lookup returns the model a name selects: an ID, an alias, a Bedrock or
+// Vertex AI model ID, or any of these with LongContextSuffix. Names are
+// matched without regard to case.
+func Lookup(name string) (Model, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	i, ok := registry.byName[canonical(name)]
+	if !ok {
+		return Model{}, false
+	}
+	m := registry.models[i]
+	m.Aliases = slices.Clone(m.Aliases)
+	return m, true
+}
+
+// Known reports whether Lookup finds name.
+func Known(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Normalize returns name trimmed of surrounding space and, if it is a
+// known ID or alias, spelled as registered, keeping any
+// LongContextSuffix: " Sonnet[1M]" becomes "sonnet[1m]". Aliases are not
+// expanded, so the CLI still resolves them. Other names, including
+// provider IDs, are returned trimmed but otherwise unchanged.
+func Normalize(name string) string {
+	trimmed := strings.TrimSpace(name)
+	base, long := trimmed, false
+	if strings.HasSuffix(strings.ToLower(trimmed), LongContextSuffix) {
+		base, long = trimmed[:len(trimmed)-len(LongContextSuffix)], true
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	i, ok := registry.byName[strings.ToLower(base)]
+	if !ok {
+		return trimmed
+	}
+	for _, known := range registry.models[i].Names() {
+		if strings.EqualFold(known, base) {
+			base = known
+			break
+		}
+	}
+	if long {
+		base += LongContextSuffix
+	}
+	return base
+}
+
+// ContextWindow returns the context window in tokens of the model name
+// selects, taking LongContextSuffix into account, and false if the model
+// is unknown.
+func ContextWindow(name string) (int, bool) {
+	m, ok := Lookup(name)
+	if !ok {
+		return 0, false
+	}
+	if strings.HasSuffix(strings.ToLower(strings.TrimSpace(name)), LongContextSuffix) && m.LongContextWindow > 0 {
+		return m.LongContextWindow, true
+	}
+	return m.ContextWindow, true
+}
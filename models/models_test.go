@@ -0,0 +1,110 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"sonnet", "claude-sonnet-4-5-20250929"},
+		{"Opus", "claude-opus-4-5-20251101"},
+		{"haiku[1m]", "claude-haiku-4-5-20251001"},
+		{"claude-sonnet-4-0", "claude-sonnet-4-20250514"},
+		{"claude-3-5-haiku-latest", "claude-3-5-haiku-20241022"},
+		{"us.anthropic.claude-sonnet-4-5-20250929-v1:0", "claude-sonnet-4-5-20250929"},
+		{"anthropic.claude-3-haiku-20240307-v1:0", "claude-3-haiku-20240307"},
+		{"claude-opus-4-1@20250805", "claude-opus-4-1-20250805"},
+	}
+
+	for _, tt := range tests {
+		m, ok := Lookup(tt.name)
+		if !ok || m.ID != tt.id {
+			t.Errorf("Lookup(%q) = %q, %v, want %q", tt.name, m.ID, ok, tt.id)
+		}
+	}
+
+	for _, name := range []string{"", "claude-sonnet-9", "gpt-4"} {
+		if Known(name) {
+			t.Errorf("Expected %q to be unknown", name)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{" Sonnet ", "sonnet"},
+		{"HAIKU[1M]", "haiku[1m]"},
+		{"Claude-Opus-4-1", "claude-opus-4-1"},
+		{"Custom-Model", "Custom-Model"},
+		{"us.anthropic.claude-sonnet-4-5-20250929-v1:0", "us.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.name); got != tt.expected {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		window int
+		ok     bool
+	}{
+		{"sonnet", 200_000, true},
+		{"sonnet[1m]", 1_000_000, true},
+		{"opus[1m]", 200_000, true},
+		{"unknown", 0, false},
+	}
+
+	for _, tt := range tests {
+		window, ok := ContextWindow(tt.name)
+		if window != tt.window || ok != tt.ok {
+			t.Errorf("ContextWindow(%q) = %d, %v, want %d, %v", tt.name, window, ok, tt.window, tt.ok)
+		}
+	}
+}
+
+func TestPricingCost(t *testing.T) {
+	m, _ := Lookup("sonnet")
+	cost := m.Pricing.Cost(1_000_000, 100_000, 0, 2_000_000)
+	if math.Abs(cost-(3+1.5+0.6)) > 1e-9 {
+		t.Errorf("Expected $5.10, got $%.4f", cost)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	before, _ := Lookup("sonnet")
+	t.Cleanup(func() { Register(before) })
+
+	Register(Model{ID: "claude-sonnet-5-20270101", Aliases: []string{"claude-sonnet-5", "sonnet"}, Family: "sonnet", ContextWindow: 400_000})
+
+	if m, ok := Lookup("sonnet"); !ok || m.ID != "claude-sonnet-5-20270101" {
+		t.Errorf("Expected the alias to move to the new model, got %q", m.ID)
+	}
+	if window, _ := ContextWindow("claude-sonnet-5"); window != 400_000 {
+		t.Errorf("Expected the new model's context window, got %d", window)
+	}
+	if m, _ := Lookup("claude-sonnet-4-5"); slices.Contains(m.Aliases, "sonnet") {
+		t.Errorf("Expected the previous model to lose the alias, got %v", m.Aliases)
+	}
+
+	// Replacing a model keeps one entry for it
+	count := len(All())
+	Register(Model{ID: "claude-sonnet-5-20270101", Family: "sonnet", ContextWindow: 500_000})
+	if len(All()) != count {
+		t.Errorf("Expected re-registering to replace the model")
+	}
+	if Known("claude-sonnet-5") {
+		t.Error("Expected the replaced model's dropped alias to be forgotten")
+	}
+}
@@ -0,0 +1,321 @@
+// Package notify posts selected agent events, such as queries starting,
+// tools being denied, and runs completing with their cost, to a webhook
+// or a Slack channel, so that operators can follow autonomous agents.
+//
+//	n := notify.New(notify.NewSlack(webhookURL), notify.EventToolDenied, notify.EventRunCompleted)
+//	defer n.Close()
+//	client.Use(n.Interceptor())
+//
+// Events are sent in order on a background goroutine, so that a slow
+// endpoint does not hold up the stream.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// EventKind identifies what happened.
+type EventKind string
+
+// Event kinds.
+const (
+	// EventQueryStarted is a query, or a prompt sent to a session, that
+	// was started.
+	EventQueryStarted EventKind = "query_started"
+
+	// EventSessionStarted is an interactive session that was started.
+	EventSessionStarted EventKind = "session_started"
+
+	// EventToolDenied is a tool use refused because permission was denied,
+	// as reported in the run's result.
+	EventToolDenied EventKind = "tool_denied"
+
+	// EventRunCompleted is the result of a run, successful or not.
+	EventRunCompleted EventKind = "run_completed"
+)
+
+// Event is a notification.
+type Event struct {
+	Kind      EventKind `json:"kind"`
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id,omitempty"`
+	Model     string    `json:"model,omitempty"`
+
+	// Prompt is set for started queries.
+	Prompt string `json:"prompt,omitempty"`
+
+	// Tool and ToolInput are set for denied tools.
+	Tool      string         `json:"tool,omitempty"`
+	ToolInput map[string]any `json:"tool_input,omitempty"`
+
+	// The rest are set for completed runs.
+	Subtype    string   `json:"subtype,omitempty"`
+	IsError    bool     `json:"is_error,omitempty"`
+	NumTurns   int      `json:"num_turns,omitempty"`
+	DurationMs int      `json:"duration_ms,omitempty"`
+	CostUSD    *float64 `json:"cost_usd,omitempty"`
+}
+
+// maxPromptText is how much of a prompt Text shows.
+const maxPromptText = 200
+
+// Text returns a one-line description of the event for chat messages.
+func (e Event) Text() string {
+	var text string
+	switch e.Kind {
+	case EventQueryStarted:
+		prompt := strings.Join(strings.Fields(e.Prompt), " ")
+		if len(prompt) > maxPromptText {
+			// Cut on a rune boundary so the text stays valid UTF-8
+			cut := maxPromptText
+			for cut > 0 && !utf8.RuneStart(prompt[cut]) {
+				cut--
+			}
+			prompt = prompt[:cut] + "…"
+		}
+		text = fmt.Sprintf("Query started: %q", prompt)
+	case EventSessionStarted:
+		text = "Session started"
+	case EventToolDenied:
+		text = fmt.Sprintf("Tool denied: %s", e.Tool)
+	case EventRunCompleted:
+		status := "completed"
+		if e.IsError {
+			status = "failed (" + e.Subtype + ")"
+		}
+		text = fmt.Sprintf("Run %s in %d turns, %s", status, e.NumTurns,
+			(time.Duration(e.DurationMs) * time.Millisecond).Round(100*time.Millisecond))
+		if e.CostUSD != nil {
+			text += fmt.Sprintf(", $%.4f", *e.CostUSD)
+		}
+	default:
+		text = string(e.Kind)
+	}
+	if e.SessionID != "" {
+		text += " [session " + e.SessionID + "]"
+	}
+	return text
+}
+
+// Sender delivers events to an endpoint.
+type Sender interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// SenderFunc adapts a function to a Sender.
+type SenderFunc func(ctx context.Context, event Event) error
+
+// Send calls f.
+func (f SenderFunc) Send(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// DefaultBufferSize is how many events a Notifier queues before dropping
+// new ones.
+const DefaultBufferSize = 100
+
+// DefaultSendTimeout bounds each Send.
+const DefaultSendTimeout = 10 * time.Second
+
+// ErrDropped is reported to OnError for each event dropped because the
+// queue was full or the Notifier was closed.
+var ErrDropped = errors.New("notify: event dropped")
+
+// Notifier sends the events it is given, if selected, to a Sender. It is
+// safe for concurrent use.
+type Notifier struct {
+	sender Sender
+	events []EventKind
+
+	// OnError, if set, is called with each error returned by the sender
+	// and with ErrDropped for each dropped event. It runs on the
+	// Notifier's goroutine or the caller's.
+	OnError func(err error)
+
+	once   sync.Once
+	queue  chan Event
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New returns a Notifier sending events of the given kinds, or of every
+// kind if none are given, to sender.
+func New(sender Sender, events ...EventKind) *Notifier {
+	return &Notifier{
+		sender: sender,
+		events: events,
+		queue:  make(chan Event, DefaultBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// selected reports whether events of kind are sent.
+func (n *Notifier) selected(kind EventKind) bool {
+	return len(n.events) == 0 || slices.Contains(n.events, kind)
+}
+
+// Notify queues event to be sent if its kind is selected. An event without
+// a time is given the current time.
+func (n *Notifier) Notify(event Event) {
+	if !n.selected(event.Kind) {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	n.once.Do(func() { go n.run() })
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		n.fail(ErrDropped)
+		return
+	}
+	select {
+	case n.queue <- event:
+	default:
+		n.fail(ErrDropped)
+	}
+}
+
+// run sends queued events until the queue is closed.
+func (n *Notifier) run() {
+	defer close(n.done)
+	for event := range n.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultSendTimeout)
+		if err := n.sender.Send(ctx, event); err != nil {
+			n.fail(err)
+		}
+		cancel()
+	}
+}
+
+func (n *Notifier) fail(err error) {
+	if n.OnError != nil {
+		n.OnError(err)
+	}
+}
+
+// Close sends the events already queued and stops the Notifier. Events
+// given to it afterwards are dropped.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	close(n.queue)
+	n.mu.Unlock()
+
+	n.once.Do(func() { go n.run() })
+	<-n.done
+	return nil
+}
+
+// Observe notifies the events in msg: tool denials and the completion of
+// the run in a result message. Other messages are ignored.
+func (n *Notifier) Observe(msg types.Message) {
+	n.observe(msg, "")
+}
+
+func (n *Notifier) observe(msg types.Message, model string) {
+	result, ok := msg.(*types.ResultMessage)
+	if !ok {
+		return
+	}
+	for _, denial := range result.PermissionDenials {
+		n.Notify(Event{
+			Kind:      EventToolDenied,
+			SessionID: result.SessionID,
+			Model:     model,
+			Tool:      denial.ToolName,
+			ToolInput: denial.ToolInput,
+		})
+	}
+	n.Notify(Event{
+		Kind:       EventRunCompleted,
+		SessionID:  result.SessionID,
+		Model:      model,
+		Subtype:    result.Subtype,
+		IsError:    result.IsError,
+		NumTurns:   result.NumTurns,
+		DurationMs: result.DurationMs,
+		CostUSD:    result.TotalCostUSD,
+	})
+}
+
+// Interceptor returns a client interceptor notifying the start of queries,
+// sessions, and session prompts, and the denials and results of their
+// runs, which it reads through the options' RawMessageHandler.
+func (n *Notifier) Interceptor() claudecode.Interceptor {
+	return func(ctx context.Context, req *claudecode.Request, next claudecode.QueryHandler) (*claudecode.QueryStream, error) {
+		if req.Kind != claudecode.RequestSend {
+			n.watch(req.Options)
+		}
+
+		stream, err := next(ctx, req)
+		if err != nil {
+			return stream, err
+		}
+
+		event := Event{Kind: EventQueryStarted, Prompt: req.Prompt}
+		if req.Kind == claudecode.RequestSession {
+			event = Event{Kind: EventSessionStarted}
+		}
+		if req.Options != nil && req.Options.Model != nil {
+			event.Model = *req.Options.Model
+		}
+		n.Notify(event)
+		return stream, nil
+	}
+}
+
+// watch wraps the options' RawMessageHandler to observe result lines.
+func (n *Notifier) watch(options *types.Options) {
+	handler := options.RawMessageHandler
+	var mu sync.Mutex
+	var model string
+
+	options.RawMessageHandler = func(line json.RawMessage) {
+		if handler != nil {
+			handler(line)
+		}
+
+		var header struct {
+			Type    string `json:"type"`
+			Subtype string `json:"subtype"`
+			Model   string `json:"model"`
+		}
+		if json.Unmarshal(line, &header) != nil {
+			return
+		}
+		switch {
+		case header.Type == "system" && header.Subtype == types.SystemSubtypeInit:
+			mu.Lock()
+			model = header.Model
+			mu.Unlock()
+		case header.Type == "result":
+			var result types.ResultMessage
+			if json.Unmarshal(line, &result) != nil {
+				return
+			}
+			mu.Lock()
+			m := model
+			mu.Unlock()
+			n.observe(&result, m)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/claudecodetest"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// recorder keeps the events sent to it.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Send(ctx context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestInterceptor(t *testing.T) {
+	rec := &recorder{}
+	n := New(rec)
+
+	client := claudecode.NewClient(claudecode.ClientOptions{})
+	client.Use(n.Interceptor())
+
+	mock := claudecodetest.NewTransport().Add(
+		claudecodetest.Line(map[string]any{"type": "system", "subtype": "init", "session_id": "s1", "model": "claude-sonnet-4-5"}),
+		claudecodetest.AssistantText("done"),
+		claudecodetest.Result("s1",
+			claudecodetest.WithCost(0.0123),
+			claudecodetest.WithTurns(3),
+			claudecodetest.WithPermissionDenials(types.PermissionDenial{ToolName: "Bash", ToolUseID: "t1"}),
+		),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.QueryWithTransport(ctx, "deploy   the\nservice", nil, mock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range stream.Messages() {
+	}
+	n.Close()
+
+	if len(rec.events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", rec.events)
+	}
+	started, denied, completed := rec.events[0], rec.events[1], rec.events[2]
+	if started.Kind != EventQueryStarted || started.Text() != `Query started: "deploy the service"` {
+		t.Errorf("Unexpected start event: %+v", started)
+	}
+	if denied.Kind != EventToolDenied || denied.Tool != "Bash" || denied.Model != "claude-sonnet-4-5" {
+		t.Errorf("Unexpected denial event: %+v", denied)
+	}
+	if completed.Kind != EventRunCompleted || completed.CostUSD == nil || *completed.CostUSD != 0.0123 || completed.NumTurns != 3 {
+		t.Errorf("Unexpected completion event: %+v", completed)
+	}
+	if !strings.HasPrefix(completed.Text(), "Run completed in 3 turns") || !strings.HasSuffix(completed.Text(), "$0.0123 [session s1]") {
+		t.Errorf("Unexpected completion text: %q", completed.Text())
+	}
+}
+
+func TestSelectedEvents(t *testing.T) {
+	rec := &recorder{}
+	n := New(rec, EventRunCompleted)
+
+	n.Notify(Event{Kind: EventQueryStarted})
+	n.Observe(&types.ResultMessage{
+		Subtype:           "error_max_turns",
+		IsError:           true,
+		SessionID:         "s2",
+		PermissionDenials: []types.PermissionDenial{{ToolName: "Write"}},
+	})
+	n.Close()
+
+	if len(rec.events) != 1 || rec.events[0].Kind != EventRunCompleted {
+		t.Fatalf("Expected only the completion, got %+v", rec.events)
+	}
+	if text := rec.events[0].Text(); !strings.HasPrefix(text, "Run failed (error_max_turns)") {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestLongPromptText(t *testing.T) {
+	// Byte maxPromptText falls inside a two-byte rune
+	prompt := "a" + strings.Repeat("é", maxPromptText)
+	text := Event{Kind: EventQueryStarted, Prompt: prompt}.Text()
+
+	want := fmt.Sprintf("Query started: %q", prompt[:maxPromptText-1]+"…")
+	if text != want {
+		t.Errorf("Expected the prompt cut on a rune boundary, got %q", text)
+	}
+}
+
+func TestDroppedEvents(t *testing.T) {
+	release := make(chan struct{})
+	var dropped int
+	var mu sync.Mutex
+	n := New(SenderFunc(func(ctx context.Context, event Event) error {
+		<-release
+		return nil
+	}))
+	n.OnError = func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if errors.Is(err, ErrDropped) {
+			dropped++
+		}
+	}
+
+	for range DefaultBufferSize + 5 {
+		n.Notify(Event{Kind: EventSessionStarted})
+	}
+	close(release)
+	n.Close()
+	n.Notify(Event{Kind: EventSessionStarted})
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 4 or 5 extra events are dropped, as the sender may already hold one
+	if dropped < 5 || dropped > 6 {
+		t.Errorf("Expected the overflow and the late event to be dropped, got %d", dropped)
+	}
+}
+
+func TestSenders(t *testing.T) {
+	var bodies []map[string]any
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/webhook" {
+			auth = r.Header.Get("Authorization")
+		}
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	event := Event{Kind: EventToolDenied, Tool: "Bash", SessionID: "s1"}
+
+	webhook := NewWebhook(server.URL + "/webhook")
+	webhook.Header.Set("Authorization", "Bearer token")
+	if err := webhook.Send(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	slack := NewSlack(server.URL + "/slack")
+	slack.Prefix = ":robot_face:"
+	if err := slack.Send(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if bodies[0]["kind"] != "tool_denied" || bodies[0]["tool"] != "Bash" || auth != "Bearer token" {
+		t.Errorf("Unexpected webhook request: %v (%q)", bodies[0], auth)
+	}
+	if bodies[1]["text"] != ":robot_face: Tool denied: Bash [session s1]" {
+		t.Errorf("Unexpected Slack message: %v", bodies[1])
+	}
+
+	if err := NewSlack(server.URL+"/down").Send(context.Background(), event); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Expected an error for a 502 response, got %v", err)
+	}
+}
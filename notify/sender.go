@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Webhook posts each event as a JSON object to a URL.
+type Webhook struct {
+	url string
+
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Header is added to every request, for example to authenticate it.
+	Header http.Header
+}
+
+// NewWebhook returns a sender posting events to url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, Header: make(http.Header)}
+}
+
+// Send posts event.
+func (w *Webhook) Send(ctx context.Context, event Event) error {
+	return post(ctx, w.Client, w.url, w.Header, event)
+}
+
+// Slack posts events to a Slack incoming webhook, as the event's Text.
+type Slack struct {
+	url string
+
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Prefix is put before each message, such as an emoji or the name of
+	// the agent.
+	Prefix string
+}
+
+// NewSlack returns a sender posting to the Slack incoming webhook url.
+func NewSlack(url string) *Slack {
+	return &Slack{url: url}
+}
+
+// Send posts event as a Slack message.
+func (s *Slack) Send(ctx context.Context, event Event) error {
+	text := event.Text()
+	if s.Prefix != "" {
+		text = s.Prefix + " " + text
+	}
+	return post(ctx, s.Client, s.url, nil, map[string]string{"text": text})
+}
+
+// post sends payload as JSON to url. Responses other than 2xx are errors.
+func post(ctx context.Context, client *http.Client, url string, header http.Header, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Option changes options. Options can be passed to QueryWith and
+// QuerySyncWith as an alternative to building an *Options value, which
+// suits code that layers defaults from several places. Each builder method
+// of Options has an Option form with the same name and parameters.
+//
+// Example:
+//
+//	defaults := []claudecode.Option{claudecode.WithModel("sonnet"), claudecode.WithMaxTurns(5)}
+//	stream, err := claudecode.QueryWith(ctx, "Review this change",
+//		append(defaults, claudecode.WithAllowedTools("Read", "Grep"))...)
+type Option func(*Options)
+
+// BuildOptions applies opts in order to NewOptions(). Nil options are
+// skipped, so optional settings can be passed conditionally.
+func BuildOptions(opts ...Option) *Options {
+	options := NewOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// WithOptions is an Option that replaces the options built so far with a
+// copy of base, so that later options layer on top of it. base is not
+// changed.
+//
+// Example:
+//
+//	stream, err := claudecode.QueryWith(ctx, prompt,
+//		claudecode.WithOptions(serviceDefaults), claudecode.WithCwd(repoDir))
+func WithOptions(base *Options) Option {
+	return func(o *Options) {
+		if base != nil {
+			*o = *base.Clone()
+		}
+	}
+}
+
+// QueryWith initiates a query with options built by BuildOptions(opts...).
+// See Query for details.
+//
+// Example:
+//
+//	stream, err := claudecode.QueryWith(ctx, "Hello",
+//		claudecode.WithModel("sonnet"),
+//		claudecode.WithAllowedTools("Read", "Grep"))
+func QueryWith(ctx context.Context, prompt string, opts ...Option) (*QueryStream, error) {
+	return defaultClient.Query(ctx, prompt, BuildOptions(opts...))
+}
+
+// QuerySyncWith runs a query with options built by BuildOptions(opts...)
+// to completion. See QuerySync for details.
+func QuerySyncWith(ctx context.Context, prompt string, opts ...Option) (*QueryResult, error) {
+	return defaultClient.QuerySync(ctx, prompt, BuildOptions(opts...))
+}
+
+// QueryWith initiates a query with options built by BuildOptions(opts...).
+// See the package-level Query for details.
+func (c *Client) QueryWith(ctx context.Context, prompt string, opts ...Option) (*QueryStream, error) {
+	return c.Query(ctx, prompt, BuildOptions(opts...))
+}
+
+// QuerySyncWith runs a query with options built by BuildOptions(opts...)
+// to completion. See the package-level QuerySync for details.
+func (c *Client) QuerySyncWith(ctx context.Context, prompt string, opts ...Option) (*QueryResult, error) {
+	return c.QuerySync(ctx, prompt, BuildOptions(opts...))
+}
+
+// WithSystemPrompt is the Option form of Options.WithSystemPrompt.
+func WithSystemPrompt(prompt string) Option {
+	return func(o *Options) { o.WithSystemPrompt(prompt) }
+}
+
+// WithAppendSystemPrompt is the Option form of Options.WithAppendSystemPrompt.
+func WithAppendSystemPrompt(prompt string) Option {
+	return func(o *Options) { o.WithAppendSystemPrompt(prompt) }
+}
+
+// WithAllowedTools is the Option form of Options.WithAllowedTools.
+func WithAllowedTools(tools ...string) Option {
+	return func(o *Options) { o.WithAllowedTools(tools...) }
+}
+
+// WithDisallowedTools is the Option form of Options.WithDisallowedTools.
+func WithDisallowedTools(tools ...string) Option {
+	return func(o *Options) { o.WithDisallowedTools(tools...) }
+}
+
+// AllowTool is the Option form of Options.AllowTool.
+func AllowTool(tool string, opts ...RuleOption) Option {
+	return func(o *Options) { o.AllowTool(tool, opts...) }
+}
+
+// DenyTool is the Option form of Options.DenyTool.
+func DenyTool(tool string, opts ...RuleOption) Option {
+	return func(o *Options) { o.DenyTool(tool, opts...) }
+}
+
+// WithPermissionMode is the Option form of Options.WithPermissionMode.
+func WithPermissionMode(mode PermissionMode) Option {
+	return func(o *Options) { o.WithPermissionMode(mode) }
+}
+
+// WithMaxTurns is the Option form of Options.WithMaxTurns.
+func WithMaxTurns(turns int) Option {
+	return func(o *Options) { o.WithMaxTurns(turns) }
+}
+
+// WithModel is the Option form of Options.WithModel.
+func WithModel(model string) Option {
+	return func(o *Options) { o.WithModel(model) }
+}
+
+// WithFallbackModel is the Option form of Options.WithFallbackModel.
+func WithFallbackModel(model string) Option {
+	return func(o *Options) { o.WithFallbackModel(model) }
+}
+
+// WithCwd is the Option form of Options.WithCwd.
+func WithCwd(cwd string) Option {
+	return func(o *Options) { o.WithCwd(cwd) }
+}
+
+// WithAddDirs is the Option form of Options.WithAddDirs.
+func WithAddDirs(dirs ...string) Option {
+	return func(o *Options) { o.WithAddDirs(dirs...) }
+}
+
+// WithSettings is the Option form of Options.WithSettings.
+func WithSettings(path string) Option {
+	return func(o *Options) { o.WithSettings(path) }
+}
+
+// WithSettingsJSON is the Option form of Options.WithSettingsJSON.
+func WithSettingsJSON(raw []byte) Option {
+	return func(o *Options) { o.WithSettingsJSON(raw) }
+}
+
+// WithOutputStyle is the Option form of Options.WithOutputStyle.
+func WithOutputStyle(name string) Option {
+	return func(o *Options) { o.WithOutputStyle(name) }
+}
+
+// WithStrictModels is the Option form of Options.WithStrictModels.
+func WithStrictModels(strict bool) Option {
+	return func(o *Options) { o.WithStrictModels(strict) }
+}
+
+// WithAutoCompact is the Option form of Options.WithAutoCompact.
+func WithAutoCompact(enabled bool) Option {
+	return func(o *Options) { o.WithAutoCompact(enabled) }
+}
+
+// WithContinueConversation is the Option form of Options.WithContinueConversation.
+func WithContinueConversation() Option {
+	return func(o *Options) { o.WithContinueConversation() }
+}
+
+// WithResume is the Option form of Options.WithResume.
+func WithResume(sessionID string) Option {
+	return func(o *Options) { o.WithResume(sessionID) }
+}
+
+// WithForkSession is the Option form of Options.WithForkSession.
+func WithForkSession(fork bool) Option {
+	return func(o *Options) { o.WithForkSession(fork) }
+}
+
+// WithAPIKey is the Option form of Options.WithAPIKey.
+func WithAPIKey(key string) Option {
+	return func(o *Options) { o.WithAPIKey(key) }
+}
+
+// WithAuthToken is the Option form of Options.WithAuthToken.
+func WithAuthToken(token string) Option {
+	return func(o *Options) { o.WithAuthToken(token) }
+}
+
+// WithBaseURL is the Option form of Options.WithBaseURL.
+func WithBaseURL(url string) Option {
+	return func(o *Options) { o.WithBaseURL(url) }
+}
+
+// AddMcpServer is the Option form of Options.AddMcpServer.
+func AddMcpServer(name string, config McpServerConfig) Option {
+	return func(o *Options) { o.AddMcpServer(name, config) }
+}
+
+// WithMcpServers is the Option form of Options.WithMcpServers.
+func WithMcpServers(servers McpServers) Option {
+	return func(o *Options) { o.WithMcpServers(servers) }
+}
+
+// WithMcpConfigFile is the Option form of Options.WithMcpConfigFile.
+func WithMcpConfigFile(path string) Option {
+	return func(o *Options) { o.WithMcpConfigFile(path) }
+}
+
+// AddMcpTool is the Option form of Options.AddMcpTool.
+func AddMcpTool(tool string) Option {
+	return func(o *Options) { o.AddMcpTool(tool) }
+}
+
+// WithAgents is the Option form of Options.WithAgents.
+func WithAgents(agents map[string]AgentDefinition) Option {
+	return func(o *Options) { o.WithAgents(agents) }
+}
+
+// AddAgent is the Option form of Options.AddAgent.
+func AddAgent(name string, agent AgentDefinition) Option {
+	return func(o *Options) { o.AddAgent(name, agent) }
+}
+
+// WithPlugins is the Option form of Options.WithPlugins.
+func WithPlugins(plugins ...PluginConfig) Option {
+	return func(o *Options) { o.WithPlugins(plugins...) }
+}
+
+// WithHooks is the Option form of Options.WithHooks.
+func WithHooks(hooks map[HookEvent][]HookMatcher) Option {
+	return func(o *Options) { o.WithHooks(hooks) }
+}
+
+// AddHook is the Option form of Options.AddHook.
+func AddHook(event HookEvent, matcher string, hooks ...HookCallback) Option {
+	return func(o *Options) { o.AddHook(event, matcher, hooks...) }
+}
+
+// WithTerminationGracePeriod is the Option form of Options.WithTerminationGracePeriod.
+func WithTerminationGracePeriod(grace time.Duration) Option {
+	return func(o *Options) { o.WithTerminationGracePeriod(grace) }
+}
+
+// WithMaxBufferSize is the Option form of Options.WithMaxBufferSize.
+func WithMaxBufferSize(size int) Option {
+	return func(o *Options) { o.WithMaxBufferSize(size) }
+}
+
+// WithRetryPolicy is the Option form of Options.WithRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *Options) { o.WithRetryPolicy(policy) }
+}
+
+// WithReconnectPolicy is the Option form of Options.WithReconnectPolicy.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(o *Options) { o.WithReconnectPolicy(policy) }
+}
+
+// WithParseMode is the Option form of Options.WithParseMode.
+func WithParseMode(mode ParseMode) Option {
+	return func(o *Options) { o.WithParseMode(mode) }
+}
+
+// WithRawMessageHandler is the Option form of Options.WithRawMessageHandler.
+func WithRawMessageHandler(handler func(line json.RawMessage)) Option {
+	return func(o *Options) { o.WithRawMessageHandler(handler) }
+}
+
+// WithCompletionCallback is the Option form of Options.WithCompletionCallback.
+func WithCompletionCallback(callback func(summary *ResultSummary)) Option {
+	return func(o *Options) { o.WithCompletionCallback(callback) }
+}
+
+// WithMaxCostUSD is the Option form of Options.WithMaxCostUSD.
+func WithMaxCostUSD(limit float64) Option {
+	return func(o *Options) { o.WithMaxCostUSD(limit) }
+}
+
+// WithQueryTimeout is the Option form of Options.WithQueryTimeout.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(o *Options) { o.WithQueryTimeout(timeout) }
+}
+
+// WithIdleTimeout is the Option form of Options.WithIdleTimeout.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(o *Options) { o.WithIdleTimeout(timeout) }
+}
+
+// WithStartupTimeout is the Option form of Options.WithStartupTimeout.
+func WithStartupTimeout(timeout time.Duration) Option {
+	return func(o *Options) { o.WithStartupTimeout(timeout) }
+}
+
+// WithHeartbeatInterval is the Option form of Options.WithHeartbeatInterval.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(o *Options) { o.WithHeartbeatInterval(interval) }
+}
+
+// WithCLIVersionCheck is the Option form of Options.WithCLIVersionCheck.
+func WithCLIVersionCheck(check VersionCheck) Option {
+	return func(o *Options) { o.WithCLIVersionCheck(check) }
+}
+
+// WithProbeCLIFlags is the Option form of Options.WithProbeCLIFlags.
+func WithProbeCLIFlags(probe bool) Option {
+	return func(o *Options) { o.WithProbeCLIFlags(probe) }
+}
+
+// WithCLISearchPaths is the Option form of Options.WithCLISearchPaths.
+func WithCLISearchPaths(paths ...string) Option {
+	return func(o *Options) { o.WithCLISearchPaths(paths...) }
+}
+
+// WithResourceLimits is the Option form of Options.WithResourceLimits.
+func WithResourceLimits(limits ResourceLimits) Option {
+	return func(o *Options) { o.WithResourceLimits(limits) }
+}
+
+// WithRateLimiter is the Option form of Options.WithRateLimiter.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(o *Options) { o.WithRateLimiter(limiter) }
+}
+
+// WithExtraArgs is the Option form of Options.WithExtraArgs.
+func WithExtraArgs(args map[string]*string) Option {
+	return func(o *Options) { o.WithExtraArgs(args) }
+}
+
+// WithRateLimitTurns is the Option form of Options.WithRateLimitTurns.
+func WithRateLimitTurns(limit bool) Option {
+	return func(o *Options) { o.WithRateLimitTurns(limit) }
+}
+
+// WithResponseCache is the Option form of Options.WithResponseCache.
+func WithResponseCache(cache ResponseCache) Option {
+	return func(o *Options) { o.WithResponseCache(cache) }
+}
@@ -0,0 +1,77 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildOptions(t *testing.T) {
+	built := BuildOptions(
+		WithModel("sonnet"),
+		WithAllowedTools("Read", "Grep"),
+		nil,
+		WithMaxTurns(3),
+		WithContinueConversation(),
+		AddMcpServer("files", &StdioServerConfig{Command: "npx"}),
+		WithQueryTimeout(time.Minute),
+	)
+
+	want := NewOptions().
+		WithModel("sonnet").
+		WithAllowedTools("Read", "Grep").
+		WithMaxTurns(3).
+		WithContinueConversation().
+		AddMcpServer("files", &StdioServerConfig{Command: "npx"}).
+		WithQueryTimeout(time.Minute)
+	if !reflect.DeepEqual(built, want) {
+		t.Errorf("BuildOptions differs from the builder:\n got %+v\nwant %+v", built, want)
+	}
+
+	if !reflect.DeepEqual(BuildOptions(), NewOptions()) {
+		t.Error("Expected BuildOptions() to return the defaults")
+	}
+}
+
+func TestWithOptionsLayering(t *testing.T) {
+	base := NewOptions().WithModel("sonnet").WithAllowedTools("Read").
+		AddMcpServer("files", &StdioServerConfig{Command: "npx"})
+
+	options := BuildOptions(
+		WithMaxTurns(9),
+		WithOptions(base),
+		WithModel("opus"),
+		AddMcpServer("search", &HTTPServerConfig{URL: "https://example.com/mcp"}),
+	)
+
+	if *options.Model != "opus" || !reflect.DeepEqual(options.AllowedTools, []string{"Read"}) || len(options.McpServers) != 2 {
+		t.Errorf("Unexpected layered options: model %s, tools %v, servers %v", *options.Model, options.AllowedTools, options.McpServers)
+	}
+	if options.MaxTurns != nil {
+		t.Error("Expected WithOptions to replace earlier options")
+	}
+	if *base.Model != "sonnet" || len(base.McpServers) != 1 {
+		t.Errorf("Base options changed: model %s, servers %v", *base.Model, base.McpServers)
+	}
+
+	if got := BuildOptions(WithModel("haiku"), WithOptions(nil)); *got.Model != "haiku" {
+		t.Error("Expected WithOptions(nil) to change nothing")
+	}
+}
+
+func TestQueryWithValidatesOptions(t *testing.T) {
+	client := NewClient(ClientOptions{CLIPath: "/nonexistent/claude"})
+
+	_, err := client.QueryWith(context.Background(), "Hello", WithMaxTurns(-1))
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) {
+		t.Fatalf("Expected *UsageError, got %v", err)
+	}
+
+	_, err = client.QuerySyncWith(context.Background(), "Hello", WithResume("a"), WithContinueConversation())
+	if !errors.As(err, &usageErr) {
+		t.Fatalf("Expected *UsageError, got %v", err)
+	}
+}
@@ -18,11 +18,90 @@ type (
 	// HTTPServerConfig represents an MCP server that communicates via HTTP.
 	HTTPServerConfig = types2.HTTPServerConfig
 
+	// McpServers maps server names to MCP server configurations, decoding
+	// each server's concrete type from its "type" field.
+	McpServers = types2.McpServers
+
+	// AgentDefinition defines a subagent that Claude can delegate tasks to.
+	AgentDefinition = types2.AgentDefinition
+
+	// PluginConfig is a plugin to load into the CLI.
+	PluginConfig = types2.PluginConfig
+
+	// Prompt is a multi-part prompt of text, images, and file references.
+	Prompt = types2.Prompt
+
 	// Options contains configuration options for Claude Code queries.
 	Options = types2.Options
 
+	// OptionsTemplate is an immutable set of options that can be shared by
+	// concurrent requests.
+	OptionsTemplate = types2.OptionsTemplate
+
+	// PermissionRule is an allowed or disallowed tool, optionally limited
+	// to some uses, such as Bash(npm run test:*).
+	PermissionRule = types2.PermissionRule
+
+	// RuleOption limits a PermissionRule.
+	RuleOption = types2.RuleOption
+
 	// PermissionMode defines the permission handling mode for tool execution.
 	PermissionMode = types2.PermissionMode
+
+	// ParseMode controls how message and content block types unknown to
+	// this SDK are handled.
+	ParseMode = types2.ParseMode
+
+	// VersionCheck controls what happens when the installed CLI is older
+	// than the options of a query require.
+	VersionCheck = types2.VersionCheck
+
+	// Version is a Claude Code CLI version number.
+	Version = types2.Version
+
+	// ResourceLimits constrains the CLI subprocess.
+	ResourceLimits = types2.ResourceLimits
+
+	// Duration is a time.Duration written to options files as a string
+	// such as "5m".
+	Duration = types2.Duration
+
+	// RateLimiter paces the start of queries; *rate.Limiter satisfies it.
+	RateLimiter = types2.RateLimiter
+
+	// ResponseCache stores the output of successful queries so repeated
+	// identical queries are answered without starting the CLI.
+	ResponseCache = types2.ResponseCache
+
+	// RetryPolicy controls how queries are retried after transient CLI failures.
+	RetryPolicy = types2.RetryPolicy
+
+	// RetryEvent describes a retry about to be made.
+	RetryEvent = types2.RetryEvent
+
+	// ReconnectPolicy controls how a CLI process that fails mid-conversation
+	// is restarted.
+	ReconnectPolicy = types2.ReconnectPolicy
+
+	// ResultSummary describes how a query ended, for
+	// Options.CompletionCallback.
+	ResultSummary = types2.ResultSummary
+
+	// CompletionStatus is how a query ended.
+	CompletionStatus = types2.CompletionStatus
+)
+
+// Re-export completion status constants
+const (
+	// CompletionSuccess means the query ended with a successful result.
+	CompletionSuccess = types2.CompletionSuccess
+
+	// CompletionError means the query failed, or ended with an error result.
+	CompletionError = types2.CompletionError
+
+	// CompletionCanceled means the query was cancelled or closed before it
+	// finished.
+	CompletionCanceled = types2.CompletionCanceled
 )
 
 // Re-export permission mode constants
@@ -36,7 +115,153 @@ const (
 	// PermissionModeBypassPermissions allows all tools without prompting.
 	// Use with caution as this bypasses all safety checks.
 	PermissionModeBypassPermissions = types2.PermissionModeBypassPermissions
+
+	// PermissionModePlan lets Claude research and plan without making changes.
+	PermissionModePlan = types2.PermissionModePlan
 )
 
+// Re-export parse mode constants
+const (
+	// ParseModeLenient skips unrecognized types. This is the default.
+	ParseModeLenient = types2.ParseModeLenient
+
+	// ParseModePassthrough delivers unrecognized types as UnknownMessage
+	// and UnknownBlock values carrying the raw JSON.
+	ParseModePassthrough = types2.ParseModePassthrough
+
+	// ParseModeStrict reports unrecognized types as UnknownTypeError errors.
+	ParseModeStrict = types2.ParseModeStrict
+)
+
+// Re-export version check constants
+const (
+	// VersionCheckWarn runs the query anyway and delivers a SystemMessage
+	// with subtype SystemSubtypeWarning describing the problem.
+	VersionCheckWarn = types2.VersionCheckWarn
+
+	// VersionCheckError fails the query with a CLIVersionError.
+	VersionCheckError = types2.VersionCheckError
+
+	// SystemSubtypeWarning is the subtype of SystemMessages generated by
+	// the SDK to report non-fatal problems with a query.
+	SystemSubtypeWarning = types2.SystemSubtypeWarning
+
+	// SystemSubtypeInit is the subtype of the SystemMessage the CLI sends
+	// when a session starts; see SystemMessage.Init.
+	SystemSubtypeInit = types2.SystemSubtypeInit
+
+	// SystemSubtypeHeartbeat is the subtype of the SystemMessages delivered
+	// every Options.HeartbeatInterval while a stream runs.
+	SystemSubtypeHeartbeat = types2.SystemSubtypeHeartbeat
+
+	// SystemSubtypeCompactBoundary is the subtype of the SystemMessage the
+	// CLI sends when it compacts the conversation.
+	SystemSubtypeCompactBoundary = types2.SystemSubtypeCompactBoundary
+
+	// CompactTriggerAuto means the CLI compacted the conversation because
+	// it neared the context window.
+	CompactTriggerAuto = types2.CompactTriggerAuto
+
+	// CompactTriggerManual means compaction was requested with /compact.
+	CompactTriggerManual = types2.CompactTriggerManual
+
+	// SystemSubtypeReconnected is the subtype of the SystemMessage delivered
+	// when a failed CLI process is restarted under a ReconnectPolicy.
+	SystemSubtypeReconnected = types2.SystemSubtypeReconnected
+
+	// SystemSubtypeCacheHit is the subtype of the SystemMessage delivered
+	// first when a query is answered from Options.ResponseCache.
+	SystemSubtypeCacheHit = types2.SystemSubtypeCacheHit
+
+	// DefaultReconnectPrompt is sent to a resumed one-shot query when
+	// ReconnectPolicy.Prompt is empty.
+	DefaultReconnectPrompt = types2.DefaultReconnectPrompt
+
+	// PluginTypeLocal is the type of a plugin loaded from a local directory.
+	PluginTypeLocal = types2.PluginTypeLocal
+)
+
+// Re-export MCP server status constants
+const (
+	// McpStatusConnected means the server's tools are available.
+	McpStatusConnected = types2.McpStatusConnected
+
+	// McpStatusFailed means the server could not be started or connected.
+	McpStatusFailed = types2.McpStatusFailed
+
+	// McpStatusPending means the server is still connecting.
+	McpStatusPending = types2.McpStatusPending
+
+	// McpStatusNeedsAuth means the server requires authentication.
+	McpStatusNeedsAuth = types2.McpStatusNeedsAuth
+)
+
+// ProgressStatusRunning is the ProgressEvent status of a running tool.
+const ProgressStatusRunning = types2.ProgressStatusRunning
+
+// ParseVersion extracts the first major.minor.patch version number from a
+// string, such as the output of "claude --version".
+var ParseVersion = types2.ParseVersion
+
 // Re-export constructor function
 var NewOptions = types2.NewOptions
+
+// NewOptionsTemplate creates an immutable template from a copy of options.
+var NewOptionsTemplate = types2.NewOptionsTemplate
+
+// LoadOptions reads options from a JSON file written by
+// Options.Save or by hand.
+var LoadOptions = types2.LoadOptions
+
+// OptionsFromEnv returns options configured by CLAUDE_SDK_* environment
+// variables, which code can then override.
+var OptionsFromEnv = types2.OptionsFromEnv
+
+// NewPermissionRule creates a permission rule for a tool.
+var NewPermissionRule = types2.NewPermissionRule
+
+// ParsePermissionRule parses and validates a rule such as "Bash(git diff:*)".
+var ParsePermissionRule = types2.ParsePermissionRule
+
+// McpToolName returns the name Claude uses for a tool of an MCP server,
+// "mcp__server__tool".
+var McpToolName = types2.McpToolName
+
+// McpServerTools returns the permission rule matching every tool of an MCP
+// server, "mcp__server".
+var McpServerTools = types2.McpServerTools
+
+// ParseMcpToolName splits an MCP tool name into its server and tool.
+var ParseMcpToolName = types2.ParseMcpToolName
+
+// WithArgPattern limits a Bash rule to commands matching a pattern, such
+// as "npm run test:*".
+var WithArgPattern = types2.WithArgPattern
+
+// WithPathPattern limits a Read, Edit, or Write rule to matching files.
+var WithPathPattern = types2.WithPathPattern
+
+// WithDomain limits a WebFetch rule to a domain.
+var WithDomain = types2.WithDomain
+
+// NewPrompt creates a multi-part prompt starting with the given text parts.
+var NewPrompt = types2.NewPrompt
+
+// EstimateTokens estimates how many tokens text takes up in a prompt to
+// model, without a tokenizer, erring towards overestimating.
+var EstimateTokens = types2.EstimateTokens
+
+// ContextWindow returns the context window of a model, in tokens.
+var ContextWindow = types2.ContextWindow
+
+// DefaultContextWindow is the context window of Claude models, in tokens,
+// unless their name selects a larger one.
+const DefaultContextWindow = types2.DefaultContextWindow
+
+// ExponentialBackoff returns a RetryPolicy backoff that doubles the delay on
+// each retry, starting at base and capped at max.
+var ExponentialBackoff = types2.ExponentialBackoff
+
+// CacheKey returns the key a query's output is stored under in a
+// ResponseCache.
+var CacheKey = types2.CacheKey
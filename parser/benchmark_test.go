@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// assistantLine builds an assistant message line with a text block of the given size.
+func assistantLine(textSize int) []byte {
+	line, _ := json.Marshal(map[string]any{
+		"type": "assistant",
+		"message": map[string]any{
+			"content": []any{
+				map[string]any{"type": "text", "text": strings.Repeat("lorem \"ipsum\" {dolor} ", textSize/22)},
+			},
+		},
+	})
+	return append(line, '\n')
+}
+
+// benchmarkChunks feeds the chunks through the parser's buffering path.
+func benchmarkChunks(b *testing.B, chunks [][]byte) {
+	var total int
+	for _, chunk := range chunks {
+		total += len(chunk)
+	}
+
+	msgChan := make(chan types.Message, len(chunks))
+	errChan := make(chan error, len(chunks))
+
+	b.SetBytes(int64(total))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := NewParser(64 * 1024 * 1024)
+		for _, chunk := range chunks {
+			if err := p.processChunk(chunk, msgChan, errChan); err != nil {
+				b.Fatal(err)
+			}
+			for len(msgChan) > 0 {
+				<-msgChan
+			}
+			if len(errChan) > 0 {
+				b.Fatal(<-errChan)
+			}
+		}
+	}
+}
+
+// BenchmarkParseSmallMessages benchmarks many small messages, one per chunk
+func BenchmarkParseSmallMessages(b *testing.B) {
+	chunks := make([][]byte, 100)
+	for i := range chunks {
+		chunks[i] = assistantLine(200)
+	}
+	benchmarkChunks(b, chunks)
+}
+
+// BenchmarkParseLargeMessage benchmarks a single 1MB message
+func BenchmarkParseLargeMessage(b *testing.B) {
+	benchmarkChunks(b, [][]byte{assistantLine(1024 * 1024)})
+}
+
+// BenchmarkParseLargeMessageSplit benchmarks a 1MB message split into 64KB chunks
+func BenchmarkParseLargeMessageSplit(b *testing.B) {
+	line := assistantLine(1024 * 1024)
+	var chunks [][]byte
+	for len(line) > 0 {
+		n := min(64*1024, len(line))
+		chunks = append(chunks, line[:n])
+		line = line[n:]
+	}
+	benchmarkChunks(b, chunks)
+}
+
+// transcriptLines builds a session transcript of n turns, each an assistant
+// message with text and a tool use followed by the tool's result, between
+// an init message and a result message.
+func transcriptLines(n int) [][]byte {
+	line := func(v map[string]any) []byte {
+		data, _ := json.Marshal(v)
+		return append(data, '\n')
+	}
+
+	lines := [][]byte{line(map[string]any{"type": "system", "subtype": "init", "session_id": "s", "tools": []any{"Read", "Grep"}})}
+	for i := range n {
+		id := fmt.Sprintf("toolu_%04d", i)
+		lines = append(lines,
+			line(map[string]any{
+				"type": "assistant",
+				"message": map[string]any{
+					"id":    fmt.Sprintf("msg_%04d", i),
+					"model": "claude-sonnet-4-5",
+					"usage": map[string]any{"input_tokens": 1200, "output_tokens": 85},
+					"content": []any{
+						map[string]any{"type": "text", "text": strings.Repeat("Reading the file next. ", 20)},
+						map[string]any{"type": "tool_use", "id": id, "name": "Read", "input": map[string]any{"file_path": "/src/main.go"}},
+					},
+				},
+			}),
+			line(map[string]any{
+				"type": "user",
+				"message": map[string]any{
+					"content": []any{
+						map[string]any{"type": "tool_result", "tool_use_id": id, "content": strings.Repeat("package main\n", 200)},
+					},
+				},
+			}),
+		)
+	}
+	return append(lines, line(map[string]any{"type": "result", "subtype": "success", "num_turns": n, "session_id": "s", "total_cost_usd": 0.12}))
+}
+
+// BenchmarkParseTranscript benchmarks a long session, one line per chunk
+// as the transport delivers them
+func BenchmarkParseTranscript(b *testing.B) {
+	benchmarkChunks(b, transcriptLines(500))
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// The CLI delivers each message as one line, and most of a transcript is
+// assistant and user messages. Those are decoded straight into the structs
+// below, which allocates far less than decoding into map[string]any and
+// walking the maps. Anything the fast path does not recognize, including
+// malformed messages, is left to the generic path, which reports errors
+// exactly as before.
+//
+// Keys must match exactly, as they must in the generic path's maps, rather
+// than case-insensitively as encoding/json matches struct fields by
+// default. Releases before Go 1.25 ignore the case:strict option.
+
+// wireMessage is the part of a message the fast path reads.
+type wireMessage struct {
+	Type    string    `json:"type,case:strict"`
+	Message *wireBody `json:"message,case:strict"`
+}
+
+// wireBody is the "message" object of assistant and user messages. User
+// messages whose content is a string fail to decode and take the generic
+// path; the CLI sends those only when replaying the prompt.
+type wireBody struct {
+	ID      string         `json:"id,case:strict"`
+	Model   string         `json:"model,case:strict"`
+	Usage   map[string]any `json:"usage,case:strict"`
+	Content []wireBlock    `json:"content,case:strict"`
+}
+
+// wireBlock holds the fields of every known content block type. Required
+// fields are pointers so that missing ones can be told from empty ones.
+type wireBlock struct {
+	Type      string         `json:"type,case:strict"`
+	Text      *string        `json:"text,case:strict"`
+	Thinking  *string        `json:"thinking,case:strict"`
+	Signature string         `json:"signature,case:strict"`
+	ID        *string        `json:"id,case:strict"`
+	Name      *string        `json:"name,case:strict"`
+	Input     map[string]any `json:"input,case:strict"`
+	ToolUseID *string        `json:"tool_use_id,case:strict"`
+	Content   any            `json:"content,case:strict"`
+	IsError   *bool          `json:"is_error,case:strict"`
+}
+
+// parseLine parses data if it holds exactly one JSON object, reporting
+// false otherwise so the caller can fall back to buffered decoding.
+func (p *Parser) parseLine(data []byte) (msg types.Message, err error, ok bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' || data[len(data)-1] != '}' {
+		return nil, nil, false
+	}
+	if msg, ok := p.parseTyped(data); ok {
+		return msg, nil, true
+	}
+
+	var raw map[string]any
+	if json.Unmarshal(data, &raw) != nil {
+		return nil, nil, false
+	}
+	msg, err = p.parseRawMessage(raw)
+	return msg, err, true
+}
+
+// parseTyped parses well-formed assistant and user messages, reporting
+// false for everything else.
+func (p *Parser) parseTyped(data []byte) (types.Message, bool) {
+	var wire wireMessage
+	if err := json.Unmarshal(data, &wire); err != nil || wire.Message == nil {
+		return nil, false
+	}
+	body := wire.Message
+	if body.Content == nil {
+		return nil, false
+	}
+	blocks, ok := typedBlocks(body.Content)
+	if !ok {
+		return nil, false
+	}
+
+	switch wire.Type {
+	case "assistant":
+		return &types.AssistantMessage{Content: blocks, ID: body.ID, Model: body.Model, Usage: body.Usage}, true
+	case "user":
+		return &types.UserMessage{Content: toolResultsSummary(len(body.Content)), Blocks: blocks}, true
+	}
+	return nil, false
+}
+
+// typedBlocks converts decoded blocks, reporting false if any block is of
+// an unknown type or is missing a required field.
+func typedBlocks(wire []wireBlock) ([]types.ContentBlock, bool) {
+	var blocks []types.ContentBlock
+	for i := range wire {
+		block := &wire[i]
+		switch block.Type {
+		case "text":
+			if block.Text == nil {
+				return nil, false
+			}
+			blocks = append(blocks, &types.TextBlock{Text: *block.Text})
+		case "thinking":
+			if block.Thinking == nil {
+				return nil, false
+			}
+			blocks = append(blocks, &types.ThinkingBlock{Thinking: *block.Thinking, Signature: block.Signature})
+		case "tool_use":
+			if block.ID == nil || block.Name == nil || block.Input == nil {
+				return nil, false
+			}
+			blocks = append(blocks, &types.ToolUseBlock{ID: *block.ID, Name: *block.Name, Input: block.Input})
+		case "tool_result":
+			if block.ToolUseID == nil {
+				return nil, false
+			}
+			blocks = append(blocks, &types.ToolResultBlock{
+				ToolUseID: *block.ToolUseID,
+				Content:   types.ParseToolResultContent(block.Content),
+				IsError:   block.IsError,
+			})
+		default:
+			return nil, false
+		}
+	}
+	return blocks, true
+}
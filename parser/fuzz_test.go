@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// fuzzSeeds are CLI output lines and the pathological inputs the parser
+// must survive.
+var fuzzSeeds = []string{
+	`{"type":"assistant","message":{"id":"msg_1","model":"m","content":[{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/a"}}]}}` + "\n",
+	`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"out","is_error":false}]}}` + "\n",
+	`{"type":"user","message":{"content":"prompt"}}` + "\n",
+	`{"type":"result","subtype":"success","num_turns":1,"total_cost_usd":0.1,"modelUsage":{"m":{"inputTokens":1}}}` + "\n",
+	`{"type":"system","subtype":"init","tools":["Read"]}` + "\n",
+	`{"type":"assistant","message":{"content":[{"type":"text","text":"} { \"type\": \"user\" }"}]}}` + "\n",
+	`{"type":"assistant","message":{"content":[{"type":"text","text":"{{{{"}]}}{"type":"system","subtype":"x"}`,
+	"\ufeff" + `{"type":"system","subtype":"init"}` + "\n",
+	`{"type":"system","subtype":"init","data":"a` + "\x00" + `b"}` + "\n",
+	"\x00\x00{\"type\":\"system\",\"subtype\":\"init\"}\x00\n",
+	`{"type":"system","subtype":"init","deep":[[[[[[[[[[[[[[[[]]]]]]]]]]]]]]]]}` + "\n",
+	`{"type":"system","subtype":"init","deep":{"a":{"a":{"a":{"a":{"a":` + "\n",
+	`{"type":"assistant","message":{"content":[{"type":"text","TEXT":"x"}]}}` + "\n",
+	`{"type":"assistant","message":{"content":[{"type":"text","text":"a","text":5}]}}` + "\n",
+	"Welcome!\n{\"type\":\"system\",\"subtype\":\"init\"}\n{\"unterminated\":",
+	`}}}]]]"""{`,
+}
+
+// parseAll runs chunks through ParseMessages and returns the messages,
+// failing if parsing hangs or the buffer outgrows its limit.
+func parseAll(t *testing.T, chunks ...[]byte) []types.Message {
+	p := NewParser(64 * 1024)
+	p.SetParseMode(types.ParseModePassthrough)
+	input := make(chan []byte, len(chunks))
+	for _, chunk := range chunks {
+		input <- chunk
+	}
+	close(input)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	msgChan, errChan := p.ParseMessages(ctx, input)
+
+	var messages []types.Message
+	for msgChan != nil || errChan != nil {
+		select {
+		case msg, ok := <-msgChan:
+			if !ok {
+				msgChan = nil
+			} else if msg == nil {
+				t.Fatal("ParseMessages delivered a nil message")
+			} else {
+				messages = append(messages, msg)
+			}
+		case _, ok := <-errChan:
+			if !ok {
+				errChan = nil
+			}
+		case <-ctx.Done():
+			t.Fatalf("ParseMessages did not finish on %q", chunks)
+		}
+	}
+	if len(p.buffer) > p.maxBufferSize {
+		t.Fatalf("buffer grew to %d bytes, over the %d byte limit", len(p.buffer), p.maxBufferSize)
+	}
+	return messages
+}
+
+// FuzzParseMessages feeds arbitrary output through ParseMessages, whole and
+// split into two chunks at an arbitrary point. Parsing must neither panic
+// nor hang. Where well-formed output is split must not change the
+// messages; malformed objects are skipped up to the end of their line or
+// chunk, so for them it may.
+func FuzzParseMessages(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed), uint16(len(seed)/2))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, split uint16) {
+		at := 0
+		if len(data) > 0 {
+			at = int(split) % (len(data) + 1)
+		}
+
+		whole := parseAll(t, data)
+		pieces := parseAll(t, data[:at], data[at:])
+		if wellFormed(data) && !reflect.DeepEqual(whole, pieces) {
+			t.Fatalf("splitting %q at %d changed the messages:\n%#v\n%#v", data, at, whole, pieces)
+		}
+	})
+}
+
+// wellFormed reports whether every non-blank line of data is valid JSON.
+func wellFormed(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 && !json.Valid(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// FuzzParseLine checks that the typed fast path parses every complete line
+// exactly as the generic path does.
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := NewParser(0)
+		p.SetParseMode(types.ParseModePassthrough)
+
+		got, gotErr, ok := p.parseLine(data)
+		if !ok {
+			return
+		}
+		want, wantErr := p.parseMessage(strings.TrimSpace(string(data)))
+		if !reflect.DeepEqual(got, want) || (gotErr == nil) != (wantErr == nil) {
+			t.Fatalf("parseLine(%q) = %#v, %v; generic path gave %#v, %v", data, got, gotErr, want, wantErr)
+		}
+	})
+}
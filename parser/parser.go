@@ -2,11 +2,14 @@
 package parser
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/jrossi/claude-code-sdk-golang/types"
+	"io"
 	"strings"
+	"time"
 )
 
 const (
@@ -19,8 +22,16 @@ type Parser struct {
 	// maxBufferSize limits the size of the internal buffer to prevent memory issues.
 	maxBufferSize int
 
+	// mode controls how unknown message and content block types are handled.
+	mode types.ParseMode
+
 	// buffer accumulates partial JSON data until a complete message can be parsed.
 	buffer []byte
+
+	// pending is set when the buffer ends with an incomplete object;
+	// scanned is how much of the buffer has been checked since then.
+	pending bool
+	scanned int
 }
 
 // NewParser creates a new JSON parser with the specified maximum buffer size.
@@ -36,6 +47,17 @@ func NewParser(maxBufferSize int) *Parser {
 	}
 }
 
+// MaxBufferSize returns the parser's maximum buffer size in bytes.
+func (p *Parser) MaxBufferSize() int {
+	return p.maxBufferSize
+}
+
+// SetParseMode sets how messages and content blocks of unknown types are
+// handled. The default, ParseModeLenient, skips them.
+func (p *Parser) SetParseMode(mode types.ParseMode) {
+	p.mode = mode
+}
+
 // ParseMessages processes a stream of raw bytes and returns parsed messages.
 // This is the foundation - full implementation will be completed in Phase 4.
 func (p *Parser) ParseMessages(ctx context.Context, data <-chan []byte) (<-chan types.Message, <-chan error) {
@@ -78,125 +100,107 @@ func (p *Parser) ParseMessages(ctx context.Context, data <-chan []byte) (<-chan
 // - JSON objects split across multiple chunks
 // - Mixed complete and partial JSON messages
 // - Large JSON payloads that exceed single buffer reads
+//
+// A chunk holding exactly one message, which is what the transport
+// delivers, is parsed in place without being copied into the buffer.
 func (p *Parser) processChunk(chunk []byte, msgChan chan<- types.Message, errChan chan<- error) error {
+	if len(p.buffer) == 0 && len(chunk) <= p.maxBufferSize {
+		if msg, err, ok := p.parseLine(chunk); ok {
+			if err != nil {
+				errChan <- p.decodeError(bytes.TrimSpace(chunk), err)
+			} else if msg != nil {
+				msgChan <- msg
+			}
+			return nil
+		}
+	}
+
 	// Append new data to buffer
 	p.buffer = append(p.buffer, chunk...)
 
 	// Check buffer size limit to prevent memory exhaustion
 	if len(p.buffer) > p.maxBufferSize {
-		// Save the error data before clearing
-		truncatedData := string(p.buffer[:100]) + "..."
+		overflow := &types.BufferOverflowError{
+			Size:    len(p.buffer),
+			Limit:   p.maxBufferSize,
+			Partial: bytes.Clone(p.buffer[:p.maxBufferSize]),
+		}
 		p.buffer = p.buffer[:0] // Clear buffer to recover
-
-		return fmt.Errorf("JSON message exceeded maximum buffer size of %d bytes: buffer overflow: data starts with %q",
-			p.maxBufferSize,
-			truncatedData,
-		)
+		p.pending = false
+		p.scanned = 0
+		return overflow
 	}
 
 	// Process all complete JSON messages in the buffer
 	return p.extractCompleteMessages(msgChan, errChan)
 }
 
-// extractCompleteMessages processes the buffer to extract all complete JSON messages.
-// This implements robust buffering that handles edge cases from the Python SDK tests:
+// extractCompleteMessages decodes every complete JSON object in the buffer,
+// keeping any trailing incomplete object for the next chunk. Decoding uses
+// json.Decoder, so string escapes and nested structures are handled by the
+// standard library rather than by scanning for braces. It handles:
 // - Multiple JSON objects separated by newlines on the same line
-// - JSON with embedded newlines in string values
+// - JSON objects pretty-printed across lines
 // - Large JSON split across multiple reads
-// - Mixed complete and partial JSON messages
+// - Non-JSON output between objects, which is skipped
 func (p *Parser) extractCompleteMessages(msgChan chan<- types.Message, errChan chan<- error) error {
-	// Handle multiple JSON objects that may be concatenated on single lines
-	// Split by newlines first, but be careful about JSON with embedded newlines
-	var processedBytes int
-	var remainingBuffer []byte
-
-	// Process line by line, but handle JSON that spans multiple lines
-	for {
-		if processedBytes >= len(p.buffer) {
-			break
+	// While an object is incomplete, only decode again once the new data
+	// could complete it: the CLI ends every object with a newline or a chunk
+	// boundary. This keeps large split messages from being rescanned on every
+	// chunk.
+	if p.pending {
+		data := p.buffer[p.scanned:]
+		p.scanned = len(p.buffer)
+		if bytes.IndexByte(data, '\n') == -1 && !bytes.HasSuffix(bytes.TrimRight(data, " \t\r"), []byte("}")) {
+			return nil
 		}
+		p.pending = false
+	}
 
-		// Look for the next complete JSON object starting from current position
-		start := processedBytes
-		jsonStart := -1
-		braceCount := 0
-		inString := false
-		escaped := false
-
-		// Find the start of the next JSON object
-		for i := start; i < len(p.buffer); i++ {
-			b := p.buffer[i]
-
-			if !inString && b == '{' {
-				if jsonStart == -1 {
-					jsonStart = i
-				}
-				braceCount++
-			} else if !inString && b == '}' {
-				braceCount--
-				if braceCount == 0 && jsonStart != -1 {
-					// Found complete JSON object
-					jsonBytes := p.buffer[jsonStart : i+1]
-					jsonStr := string(jsonBytes)
-
-					// Try to parse this JSON object
-					msg, err := p.parseMessage(jsonStr)
-					if err != nil {
-						// Send error but continue processing
-						errChan <- fmt.Errorf("JSON decode error: %s: %w", jsonStr, err)
-					} else if msg != nil {
-						msgChan <- msg
-					}
-
-					processedBytes = i + 1
-					jsonStart = -1
-					braceCount = 0
-					break
-				}
-			} else if b == '"' && !escaped {
-				inString = !inString
-			}
-
-			// Handle escape sequences in strings
-			if inString {
-				escaped = !escaped && b == '\\'
-			} else {
-				escaped = false
-			}
+	offset := 0
+	for offset < len(p.buffer) {
+		// Skip non-JSON output up to the start of the next object
+		start := bytes.IndexByte(p.buffer[offset:], '{')
+		if start == -1 {
+			offset = len(p.buffer)
+			break
 		}
-
-		// If we didn't find a complete JSON object, we need more data
-		if jsonStart != -1 && braceCount > 0 {
-			// Incomplete JSON object - keep it in buffer
-			remainingBuffer = p.buffer[jsonStart:]
+		offset += start
+		objectStart := offset
+
+		decoder := json.NewDecoder(bytes.NewReader(p.buffer[offset:]))
+		var raw map[string]any
+		err := decoder.Decode(&raw)
+		if err == io.ErrUnexpectedEOF {
+			// Incomplete object - keep it in the buffer
+			p.pending = true
 			break
 		}
-
-		// If we didn't find any JSON start, skip non-JSON content
-		if jsonStart == -1 {
-			// Look for the next '{' or end of buffer
-			found := false
-			for i := processedBytes; i < len(p.buffer); i++ {
-				if p.buffer[i] == '{' {
-					processedBytes = i
-					found = true
-					break
-				}
-			}
-			if !found {
-				// No more JSON in buffer
-				break
+		if err != nil {
+			// Malformed object - report it and resume after the current line
+			end := bytes.IndexByte(p.buffer[offset:], '\n')
+			if end == -1 {
+				end = len(p.buffer) - offset
 			}
+			errChan <- p.decodeError(p.buffer[offset:offset+end], err)
+			offset += end
+			continue
 		}
-	}
+		offset += int(decoder.InputOffset())
 
-	// Update buffer with any remaining incomplete JSON
-	if len(remainingBuffer) > 0 {
-		p.buffer = remainingBuffer
-	} else {
-		p.buffer = p.buffer[:0]
+		msg, err := p.parseRawMessage(raw)
+		if err != nil {
+			// Send error but continue processing
+			errChan <- p.decodeError(p.buffer[objectStart:offset], err)
+		} else if msg != nil {
+			msgChan <- msg
+		}
 	}
 
+	// Keep any incomplete JSON at the start of the buffer
+	p.buffer = append(p.buffer[:0], p.buffer[offset:]...)
+	p.scanned = len(p.buffer)
+
 	return nil
 }
 
@@ -206,6 +210,13 @@ func (p *Parser) processRemainingBuffer(msgChan chan<- types.Message, errChan ch
 		return nil
 	}
 
+	// Objects completed by the last chunks may not have been decoded yet,
+	// if no newline or closing brace ended them
+	p.pending = false
+	if err := p.extractCompleteMessages(msgChan, errChan); err != nil {
+		return err
+	}
+
 	bufferStr := strings.TrimSpace(string(p.buffer))
 	if bufferStr == "" {
 		return nil
@@ -213,7 +224,7 @@ func (p *Parser) processRemainingBuffer(msgChan chan<- types.Message, errChan ch
 
 	msg, err := p.parseMessage(bufferStr)
 	if err != nil {
-		return fmt.Errorf("JSON decode error: %s: %w", bufferStr, err)
+		return p.decodeError([]byte(bufferStr), err)
 	}
 
 	if msg != nil {
@@ -223,6 +234,11 @@ func (p *Parser) processRemainingBuffer(msgChan chan<- types.Message, errChan ch
 	return nil
 }
 
+// decodeError reports that line could not be decoded into a message.
+func (p *Parser) decodeError(line []byte, err error) *types.JSONDecodeError {
+	return &types.JSONDecodeError{Line: string(line), OriginalErr: err, BufferLength: len(p.buffer)}
+}
+
 // parseMessage parses a single JSON line into a Message.
 func (p *Parser) parseMessage(line string) (types.Message, error) {
 	// Parse as generic JSON first
@@ -231,6 +247,11 @@ func (p *Parser) parseMessage(line string) (types.Message, error) {
 		return nil, err
 	}
 
+	return p.parseRawMessage(raw)
+}
+
+// parseRawMessage converts decoded JSON into a Message based on its type.
+func (p *Parser) parseRawMessage(raw map[string]any) (types.Message, error) {
 	// Determine message type
 	msgType, ok := raw["type"].(string)
 	if !ok {
@@ -247,8 +268,16 @@ func (p *Parser) parseMessage(line string) (types.Message, error) {
 		return p.parseSystemMessage(raw)
 	case "result":
 		return p.parseResultMessage(raw)
+	case "tool_progress":
+		return p.parseProgressEvent(raw)
 	default:
-		// Unknown message type, skip silently for forward compatibility
+		// Unknown message type, skipped by default for forward compatibility
+		switch p.mode {
+		case types.ParseModePassthrough:
+			return &types.UnknownMessage{MessageType: msgType, Raw: rawJSON(raw)}, nil
+		case types.ParseModeStrict:
+			return nil, &types.UnknownTypeError{Element: "message", Type: msgType, Raw: rawJSON(raw)}
+		}
 		return nil, nil
 	}
 }
@@ -267,12 +296,30 @@ func (p *Parser) parseUserMessage(raw map[string]any) (*types.UserMessage, error
 
 	if contentArray, ok := message["content"].([]any); ok {
 		// For tool result arrays, create a summary string
-		return &types.UserMessage{Content: fmt.Sprintf("Tool results: %d items", len(contentArray))}, nil
+		result := &types.UserMessage{Content: toolResultsSummary(len(contentArray))}
+
+		// Malformed blocks are skipped rather than failing the message,
+		// since the summary has always been delivered regardless
+		for _, blockData := range contentArray {
+			block, ok := blockData.(map[string]any)
+			if !ok {
+				continue
+			}
+			if contentBlock, err := p.parseContentBlock(block); err == nil && contentBlock != nil {
+				result.Blocks = append(result.Blocks, contentBlock)
+			}
+		}
+		return result, nil
 	}
 
 	return nil, fmt.Errorf("user message missing 'content' field")
 }
 
+// toolResultsSummary is the Content of a user message with n blocks.
+func toolResultsSummary(n int) string {
+	return fmt.Sprintf("Tool results: %d items", n)
+}
+
 // parseAssistantMessage parses an assistant message from raw JSON data.
 func (p *Parser) parseAssistantMessage(raw map[string]any) (*types.AssistantMessage, error) {
 	message, ok := raw["message"].(map[string]any)
@@ -302,7 +349,12 @@ func (p *Parser) parseAssistantMessage(raw map[string]any) (*types.AssistantMess
 		}
 	}
 
-	return &types.AssistantMessage{Content: contentBlocks}, nil
+	result := &types.AssistantMessage{Content: contentBlocks}
+	result.ID, _ = message["id"].(string)
+	result.Model, _ = message["model"].(string)
+	result.Usage, _ = message["usage"].(map[string]any)
+
+	return result, nil
 }
 
 // parseContentBlock parses a content block from raw JSON data.
@@ -320,6 +372,15 @@ func (p *Parser) parseContentBlock(block map[string]any) (types.ContentBlock, er
 		}
 		return &types.TextBlock{Text: text}, nil
 
+	case "thinking":
+		thinking, ok := block["thinking"].(string)
+		if !ok {
+			return nil, fmt.Errorf("thinking block missing 'thinking' field")
+		}
+		// Signature may be absent, e.g. for redacted or partial output
+		signature, _ := block["signature"].(string)
+		return &types.ThinkingBlock{Thinking: thinking, Signature: signature}, nil
+
 	case "tool_use":
 		id, ok := block["id"].(string)
 		if !ok {
@@ -341,12 +402,9 @@ func (p *Parser) parseContentBlock(block map[string]any) (types.ContentBlock, er
 			return nil, fmt.Errorf("tool_result block missing 'tool_use_id' field")
 		}
 
-		result := &types.ToolResultBlock{ToolUseID: toolUseID}
-
-		if content, exists := block["content"]; exists && content != nil {
-			if contentStr, ok := content.(string); ok {
-				result.Content = &contentStr
-			}
+		result := &types.ToolResultBlock{
+			ToolUseID: toolUseID,
+			Content:   types.ParseToolResultContent(block["content"]),
 		}
 
 		if isError, exists := block["is_error"]; exists && isError != nil {
@@ -358,11 +416,26 @@ func (p *Parser) parseContentBlock(block map[string]any) (types.ContentBlock, er
 		return result, nil
 
 	default:
-		// Unknown content block type, skip for forward compatibility
+		// Unknown content block type, skipped by default for forward compatibility
+		switch p.mode {
+		case types.ParseModePassthrough:
+			return &types.UnknownBlock{BlockType: blockType, Raw: rawJSON(block)}, nil
+		case types.ParseModeStrict:
+			return nil, &types.UnknownTypeError{Element: "content block", Type: blockType, Raw: rawJSON(block)}
+		}
 		return nil, nil
 	}
 }
 
+// rawJSON re-encodes decoded JSON for delivery with unknown types.
+func rawJSON(value map[string]any) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
 // parseSystemMessage parses a system message from raw JSON data.
 func (p *Parser) parseSystemMessage(raw map[string]any) (*types.SystemMessage, error) {
 	subtype, ok := raw["subtype"].(string)
@@ -376,6 +449,29 @@ func (p *Parser) parseSystemMessage(raw map[string]any) (*types.SystemMessage, e
 	}, nil
 }
 
+// parseProgressEvent parses a tool progress message from raw JSON data.
+func (p *Parser) parseProgressEvent(raw map[string]any) (*types.ProgressEvent, error) {
+	toolUseID, ok := raw["tool_use_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("tool progress message missing 'tool_use_id' field")
+	}
+
+	event := &types.ProgressEvent{ToolUseID: toolUseID, Status: types.ProgressStatusRunning}
+	if val, ok := raw["tool_name"].(string); ok {
+		event.ToolName = val
+	}
+	if val, ok := raw["parent_tool_use_id"].(string); ok {
+		event.ParentToolUseID = val
+	}
+	if val, ok := raw["elapsed_time_seconds"].(float64); ok {
+		event.Elapsed = time.Duration(val * float64(time.Second))
+	}
+	if val, ok := raw["status"].(string); ok && val != "" {
+		event.Status = val
+	}
+	return event, nil
+}
+
 // parseResultMessage parses a result message from raw JSON data.
 func (p *Parser) parseResultMessage(raw map[string]any) (*types.ResultMessage, error) {
 	subtype, ok := raw["subtype"].(string)
@@ -412,6 +508,60 @@ func (p *Parser) parseResultMessage(raw map[string]any) (*types.ResultMessage, e
 	if val, ok := raw["result"].(string); ok {
 		result.Result = &val
 	}
+	if val, ok := raw["permission_denials"].([]any); ok {
+		result.PermissionDenials = parsePermissionDenials(val)
+	}
+	if val, ok := raw["modelUsage"].(map[string]any); ok {
+		result.ModelUsage = parseModelUsage(val)
+	}
 
 	return result, nil
 }
+
+// parseModelUsage parses the usage of each model, skipping malformed
+// entries.
+func parseModelUsage(raw map[string]any) map[string]types.ModelUsage {
+	usage := make(map[string]types.ModelUsage, len(raw))
+	for model, item := range raw {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		count := func(key string) int {
+			val, _ := entry[key].(float64)
+			return int(val)
+		}
+		var u types.ModelUsage
+		u.InputTokens = count("inputTokens")
+		u.OutputTokens = count("outputTokens")
+		u.CacheReadInputTokens = count("cacheReadInputTokens")
+		u.CacheCreationInputTokens = count("cacheCreationInputTokens")
+		u.WebSearchRequests = count("webSearchRequests")
+		u.CostUSD, _ = entry["costUSD"].(float64)
+		usage[model] = u
+	}
+	return usage
+}
+
+// parsePermissionDenials parses the tool uses denied during a run,
+// skipping malformed entries.
+func parsePermissionDenials(raw []any) []types.PermissionDenial {
+	denials := make([]types.PermissionDenial, 0, len(raw))
+	for _, item := range raw {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var denial types.PermissionDenial
+		denial.ToolName, _ = entry["tool_name"].(string)
+		denial.ToolUseID, _ = entry["tool_use_id"].(string)
+		denial.ToolInput, _ = entry["tool_input"].(map[string]any)
+		if denial.ToolName == "" && denial.ToolUseID == "" {
+			continue
+		}
+		denials = append(denials, denial)
+	}
+	return denials
+}
@@ -2,9 +2,14 @@ package parser
 
 import (
 	"context"
-	"github.com/jrossi/claude-code-sdk-golang/types"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
 )
 
 func TestNewParser(t *testing.T) {
@@ -46,6 +51,36 @@ func TestParseUserMessage(t *testing.T) {
 	}
 }
 
+func TestParseUserMessageToolResults(t *testing.T) {
+	parser := NewParser(0)
+
+	raw := map[string]any{
+		"type": "user",
+		"message": map[string]any{
+			"content": []any{
+				map[string]any{"type": "tool_result", "tool_use_id": "tool_1", "content": "ok"},
+				map[string]any{"type": "tool_result"},
+				"malformed",
+			},
+		},
+	}
+
+	msg, err := parser.parseUserMessage(raw)
+	if err != nil {
+		t.Fatalf("parseUserMessage failed: %v", err)
+	}
+
+	if msg.Content != "Tool results: 3 items" {
+		t.Errorf("Expected summary content, got %q", msg.Content)
+	}
+	if len(msg.Blocks) != 1 {
+		t.Fatalf("Expected 1 parsed block, got %d", len(msg.Blocks))
+	}
+	if result, ok := msg.Blocks[0].(*types.ToolResultBlock); !ok || result.ToolUseID != "tool_1" || result.Text() != "ok" {
+		t.Errorf("Unexpected tool result block: %#v", msg.Blocks[0])
+	}
+}
+
 func TestParseTextBlock(t *testing.T) {
 	parser := NewParser(0)
 
@@ -73,6 +108,48 @@ func TestParseTextBlock(t *testing.T) {
 	}
 }
 
+func TestParseThinkingBlock(t *testing.T) {
+	parser := NewParser(0)
+
+	block := map[string]any{
+		"type":      "thinking",
+		"thinking":  "Let me work through this step by step",
+		"signature": "sig-abc123",
+	}
+
+	contentBlock, err := parser.parseContentBlock(block)
+	if err != nil {
+		t.Fatalf("parseContentBlock failed: %v", err)
+	}
+
+	thinkingBlock, ok := contentBlock.(*types.ThinkingBlock)
+	if !ok {
+		t.Fatalf("Expected ThinkingBlock, got %T", contentBlock)
+	}
+
+	if thinkingBlock.Thinking != "Let me work through this step by step" {
+		t.Errorf("Unexpected thinking text: %q", thinkingBlock.Thinking)
+	}
+	if thinkingBlock.Signature != "sig-abc123" {
+		t.Errorf("Expected signature 'sig-abc123', got %q", thinkingBlock.Signature)
+	}
+	if thinkingBlock.Type() != "thinking" {
+		t.Errorf("Expected type 'thinking', got '%s'", thinkingBlock.Type())
+	}
+
+	// Missing thinking text is an error, missing signature is not
+	if _, err := parser.parseContentBlock(map[string]any{"type": "thinking"}); err == nil {
+		t.Error("Expected error for thinking block without text")
+	}
+	unsigned, err := parser.parseContentBlock(map[string]any{"type": "thinking", "thinking": "hmm"})
+	if err != nil {
+		t.Fatalf("Unexpected error for unsigned thinking block: %v", err)
+	}
+	if unsigned.(*types.ThinkingBlock).Signature != "" {
+		t.Error("Expected empty signature")
+	}
+}
+
 func TestParseToolUseBlock(t *testing.T) {
 	parser := NewParser(0)
 
@@ -137,7 +214,7 @@ func TestParseToolResultBlock(t *testing.T) {
 		t.Errorf("Expected tool_use_id 'tool_123', got '%s'", resultBlock.ToolUseID)
 	}
 
-	if resultBlock.Content == nil || *resultBlock.Content != "File contents here" {
+	if resultBlock.Text() != "File contents here" {
 		t.Error("Expected content 'File contents here'")
 	}
 
@@ -206,6 +283,23 @@ func TestParseAssistantMessage(t *testing.T) {
 	}
 }
 
+func TestParseAssistantMessageWithThinking(t *testing.T) {
+	parser := NewParser(0)
+
+	msg, err := parser.parseMessage(`{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"2+2 is 4","signature":"s"},{"type":"text","text":"4"}]}}`)
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+
+	assistant := msg.(*types.AssistantMessage)
+	if len(assistant.Content) != 2 {
+		t.Fatalf("Expected 2 content blocks, got %d", len(assistant.Content))
+	}
+	if _, ok := assistant.Content[0].(*types.ThinkingBlock); !ok {
+		t.Errorf("Expected first block to be ThinkingBlock, got %T", assistant.Content[0])
+	}
+}
+
 func TestParseResultMessage(t *testing.T) {
 	parser := NewParser(0)
 
@@ -251,6 +345,129 @@ func TestParseResultMessage(t *testing.T) {
 	}
 }
 
+func TestParseResultPermissionDenials(t *testing.T) {
+	parser := NewParser(0)
+
+	raw := map[string]any{
+		"type":     "result",
+		"subtype":  "success",
+		"is_error": false,
+		"permission_denials": []any{
+			map[string]any{
+				"tool_name":   "Bash",
+				"tool_use_id": "tool_1",
+				"tool_input":  map[string]any{"command": "rm -rf build"},
+			},
+			"malformed",
+			map[string]any{"tool_name": "Write", "tool_use_id": "tool_2"},
+			map[string]any{"tool_name": "Bash", "tool_use_id": "tool_3"},
+		},
+	}
+
+	msg, err := parser.parseResultMessage(raw)
+	if err != nil {
+		t.Fatalf("parseResultMessage failed: %v", err)
+	}
+
+	if len(msg.PermissionDenials) != 3 {
+		t.Fatalf("Expected 3 denials, got %#v", msg.PermissionDenials)
+	}
+	first := msg.PermissionDenials[0]
+	if first.ToolName != "Bash" || first.ToolUseID != "tool_1" || first.ToolInput["command"] != "rm -rf build" {
+		t.Errorf("Unexpected first denial: %#v", first)
+	}
+	if tools := msg.DeniedTools(); len(tools) != 2 || tools[0] != "Bash" || tools[1] != "Write" {
+		t.Errorf("Expected denied tools [Bash Write], got %v", tools)
+	}
+	if !msg.FailedOnPermissions() {
+		t.Error("Expected run to have failed on permissions")
+	}
+}
+
+func TestParseResultModelUsage(t *testing.T) {
+	parser := NewParser(0)
+
+	raw := map[string]any{
+		"type":    "result",
+		"subtype": "success",
+		"modelUsage": map[string]any{
+			"claude-sonnet-4-5": map[string]any{
+				"inputTokens":          float64(10),
+				"outputTokens":         float64(200),
+				"cacheReadInputTokens": float64(3000),
+				"costUSD":              0.02,
+			},
+			"claude-haiku-4-5": map[string]any{"inputTokens": float64(5), "webSearchRequests": float64(1)},
+			"malformed":        "x",
+		},
+	}
+
+	msg, err := parser.parseResultMessage(raw)
+	if err != nil {
+		t.Fatalf("parseResultMessage failed: %v", err)
+	}
+
+	want := map[string]types.ModelUsage{
+		"claude-sonnet-4-5": {InputTokens: 10, OutputTokens: 200, CacheReadInputTokens: 3000, CostUSD: 0.02},
+		"claude-haiku-4-5":  {InputTokens: 5, WebSearchRequests: 1},
+	}
+	if !reflect.DeepEqual(msg.ModelUsage, want) {
+		t.Errorf("Unexpected model usage: %#v", msg.ModelUsage)
+	}
+	if models := msg.Models(); !reflect.DeepEqual(models, []string{"claude-haiku-4-5", "claude-sonnet-4-5"}) {
+		t.Errorf("Unexpected models: %v", models)
+	}
+}
+
+func TestParseProgressEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected *types.ProgressEvent
+		wantErr  bool
+	}{
+		{
+			name: "tool progress",
+			line: `{"type":"tool_progress","tool_use_id":"t1","tool_name":"Bash","parent_tool_use_id":"task1","elapsed_time_seconds":2.5}`,
+			expected: &types.ProgressEvent{
+				ToolUseID:       "t1",
+				ToolName:        "Bash",
+				ParentToolUseID: "task1",
+				Elapsed:         2500 * time.Millisecond,
+				Status:          types.ProgressStatusRunning,
+			},
+		},
+		{
+			name:     "reported status",
+			line:     `{"type":"tool_progress","tool_use_id":"t1","status":"waiting"}`,
+			expected: &types.ProgressEvent{ToolUseID: "t1", Status: "waiting"},
+		},
+		{
+			name:    "missing tool use ID",
+			line:    `{"type":"tool_progress","tool_name":"Bash"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewParser(0).parseMessage(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMessage failed: %v", err)
+			}
+			if !reflect.DeepEqual(msg, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, msg)
+			}
+		})
+	}
+}
+
 func TestParseMessagesBasic(t *testing.T) {
 	parser := NewParser(0)
 
@@ -320,3 +537,366 @@ func TestParseMessagesBasic(t *testing.T) {
 		t.Error("Assistant message should have 1 content block")
 	}
 }
+
+func TestProcessChunkDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		texts    []string
+		errCount int
+	}{
+		{
+			name:   "braces and escaped quotes in strings",
+			chunks: []string{`{"type": "assistant", "message": {"content": [{"type": "text", "text": "a \"}\" and {not json"}]}}`},
+			texts:  []string{`a "}" and {not json`},
+		},
+		{
+			name:   "multiple objects in one chunk",
+			chunks: []string{`{"type": "assistant", "message": {"content": [{"type": "text", "text": "one"}]}}{"type": "assistant", "message": {"content": [{"type": "text", "text": "two"}]}}` + "\n"},
+			texts:  []string{"one", "two"},
+		},
+		{
+			name:   "object split across chunks",
+			chunks: []string{`{"type": "assistant", "mess`, `age": {"content": [{"type": "text", "te`, `xt": "split }"}]}}`},
+			texts:  []string{"split }"},
+		},
+		{
+			name:   "non-JSON output is skipped",
+			chunks: []string{"Loading...\n", `{"type": "assistant", "message": {"content": [{"type": "text", "text": "after"}]}}`},
+			texts:  []string{"after"},
+		},
+		{
+			name:     "malformed object reports an error and resyncs",
+			chunks:   []string{`{"type": bad}` + "\n" + `{"type": "assistant", "message": {"content": [{"type": "text", "text": "ok"}]}}`},
+			texts:    []string{"ok"},
+			errCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(0)
+			msgChan := make(chan types.Message, 10)
+			errChan := make(chan error, 10)
+
+			for _, chunk := range tt.chunks {
+				if err := parser.processChunk([]byte(chunk), msgChan, errChan); err != nil {
+					t.Fatalf("processChunk failed: %v", err)
+				}
+			}
+			close(msgChan)
+			close(errChan)
+
+			var texts []string
+			for msg := range msgChan {
+				assistant, ok := msg.(*types.AssistantMessage)
+				if !ok {
+					t.Fatalf("Expected AssistantMessage, got %T", msg)
+				}
+				texts = append(texts, assistant.Content[0].(*types.TextBlock).Text)
+			}
+			if len(texts) != len(tt.texts) {
+				t.Fatalf("Expected texts %q, got %q", tt.texts, texts)
+			}
+			for i := range texts {
+				if texts[i] != tt.texts[i] {
+					t.Errorf("Expected text %q, got %q", tt.texts[i], texts[i])
+				}
+			}
+
+			errCount := 0
+			for range errChan {
+				errCount++
+			}
+			if errCount != tt.errCount {
+				t.Errorf("Expected %d errors, got %d", tt.errCount, errCount)
+			}
+			if len(parser.buffer) != 0 {
+				t.Errorf("Expected empty buffer, got %q", parser.buffer)
+			}
+		})
+	}
+}
+
+func TestProcessChunkBufferOverflow(t *testing.T) {
+	parser := NewParser(64)
+	msgChan := make(chan types.Message, 10)
+	errChan := make(chan error, 10)
+
+	// An incomplete object leaves the parser waiting for more data
+	if err := parser.processChunk([]byte(`{"type": "assistant", "message": {"content": [`), msgChan, errChan); err != nil {
+		t.Fatalf("processChunk failed: %v", err)
+	}
+	err := parser.processChunk([]byte(strings.Repeat("x", 64)), msgChan, errChan)
+	var overflow *types.BufferOverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("Expected *BufferOverflowError, got %v", err)
+	}
+	if overflow.Size != 110 || overflow.Limit != 64 || len(overflow.Partial) != 64 {
+		t.Errorf("Unexpected overflow: size %d, limit %d, partial %d bytes", overflow.Size, overflow.Limit, len(overflow.Partial))
+	}
+
+	// The parser recovers for the next message
+	if err := parser.processChunk([]byte(`{"type": "assistant", "message": {"content": []}}`), msgChan, errChan); err != nil {
+		t.Fatalf("processChunk failed after overflow: %v", err)
+	}
+	if len(msgChan) != 1 {
+		t.Errorf("Expected 1 message after overflow, got %d", len(msgChan))
+	}
+}
+
+func TestParseModeUnknownTypes(t *testing.T) {
+	unknownMessage := `{"type": "stream_event", "event": {"delta": "hi"}}`
+	unknownBlock := `{"type": "assistant", "message": {"content": [{"type": "text", "text": "a"}, {"type": "server_tool_use", "id": "x"}]}}`
+
+	tests := []struct {
+		name string
+		mode types.ParseMode
+		line string
+		want string // expected message or block type, or "" if skipped
+		err  string
+	}{
+		{"lenient message", types.ParseModeLenient, unknownMessage, "", ""},
+		{"lenient block", types.ParseModeLenient, unknownBlock, "", ""},
+		{"passthrough message", types.ParseModePassthrough, unknownMessage, "stream_event", ""},
+		{"passthrough block", types.ParseModePassthrough, unknownBlock, "server_tool_use", ""},
+		{"strict message", types.ParseModeStrict, unknownMessage, "", "message"},
+		{"strict block", types.ParseModeStrict, unknownBlock, "", "content block"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(0)
+			parser.SetParseMode(tt.mode)
+
+			msg, err := parser.parseMessage(tt.line)
+			if tt.err != "" {
+				var unknownErr *types.UnknownTypeError
+				if !errors.As(err, &unknownErr) {
+					t.Fatalf("Expected UnknownTypeError, got %v", err)
+				}
+				if unknownErr.Element != tt.err {
+					t.Errorf("Expected element %q, got %q", tt.err, unknownErr.Element)
+				}
+				if len(unknownErr.Raw) == 0 {
+					t.Error("Expected raw JSON in error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMessage failed: %v", err)
+			}
+
+			var got types.ContentBlock
+			switch m := msg.(type) {
+			case nil:
+			case *types.UnknownMessage:
+				got = m
+				if !json.Valid(m.Raw) {
+					t.Errorf("Expected valid raw JSON, got %s", m.Raw)
+				}
+			case *types.AssistantMessage:
+				if len(m.Content) > 1 {
+					got = m.Content[1]
+					if _, ok := got.(*types.UnknownBlock); !ok {
+						t.Fatalf("Expected UnknownBlock, got %T", got)
+					}
+				}
+			default:
+				t.Fatalf("Unexpected message %T", msg)
+			}
+
+			gotType := ""
+			if got != nil {
+				gotType = got.Type()
+			}
+			if gotType != tt.want {
+				t.Errorf("Expected type %q, got %q", tt.want, gotType)
+			}
+		})
+	}
+}
+
+func TestParseAssistantMessageMetadata(t *testing.T) {
+	parser := NewParser(0)
+
+	msg, err := parser.parseMessage(`{"type": "assistant", "message": {"id": "msg_1", "model": "claude-sonnet-4", "usage": {"output_tokens": 12}, "content": [{"type": "text", "text": "Hi"}]}}`)
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+
+	assistant := msg.(*types.AssistantMessage)
+	if assistant.ID != "msg_1" || assistant.Model != "claude-sonnet-4" {
+		t.Errorf("Expected ID and model to be parsed, got %q and %q", assistant.ID, assistant.Model)
+	}
+	if assistant.Usage["output_tokens"] != float64(12) {
+		t.Errorf("Expected usage to be parsed, got %v", assistant.Usage)
+	}
+}
+
+func TestParseLineMatchesGenericPath(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","message":{"id":"msg_1","model":"m","usage":{"input_tokens":3},"content":[{"type":"text","text":"hi"},{"type":"thinking","thinking":"hmm"},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/a","limit":5}}]}}`,
+		`{"type":"assistant","message":{"content":[]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":""}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"out","is_error":true}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"a"}],"is_error":null}]}}`,
+		`{"type":"user","message":{"content":"prompt"}}`,
+		`{"type":"user","message":{"content":[{"type":"text"},"stray"]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"server_tool_use","id":"s1"}]}}`,
+		`{"type":"result","subtype":"success","num_turns":2}`,
+		`{"type":"system","subtype":"init"}`,
+	}
+	invalid := []string{
+		`{"type":"assistant","message":{"content":[{"type":"text"}]}}`,
+		`{"type":"assistant","message":{"content":"text"}}`,
+		`{"type":"assistant","message":{}}`,
+		`{"message":{"content":[]}}`,
+	}
+
+	for _, mode := range []types.ParseMode{types.ParseModeLenient, types.ParseModePassthrough} {
+		for _, line := range append(lines, invalid...) {
+			p := NewParser(0)
+			p.SetParseMode(mode)
+
+			want, wantErr := p.parseMessage(line)
+			got, gotErr, ok := p.parseLine([]byte(line + "\n"))
+			if !ok {
+				t.Errorf("parseLine(%s) did not parse a complete line", line)
+				continue
+			}
+			if !reflect.DeepEqual(got, want) || (gotErr == nil) != (wantErr == nil) {
+				t.Errorf("parseLine(%s) = %#v, %v; generic path gave %#v, %v", line, got, gotErr, want, wantErr)
+			}
+		}
+	}
+
+	for _, line := range []string{`{"type":"system"`, `{"a":1} {"b":2}`, `text {"a":1}`} {
+		if _, _, ok := NewParser(0).parseLine([]byte(line)); ok {
+			t.Errorf("parseLine(%s) should leave partial and mixed data to the buffer", line)
+		}
+	}
+}
+
+func TestParsePathologicalInput(t *testing.T) {
+	system := `{"type":"system","subtype":"init"}`
+	tests := []struct {
+		name     string
+		chunks   []string
+		messages int
+		errCount int
+	}{
+		{
+			name:     "byte order mark",
+			chunks:   []string{"\ufeff" + system},
+			messages: 1,
+		},
+		{
+			name:     "NUL bytes around an object",
+			chunks:   []string{"\x00\x00" + system + "\x00\n" + system},
+			messages: 2,
+		},
+		{
+			name:     "NUL byte inside a string",
+			chunks:   []string{`{"type":"system","subtype":"a` + "\x00" + `"}` + "\n" + system},
+			messages: 1,
+			errCount: 1,
+		},
+		{
+			name:     "nesting beyond the decoder's limit",
+			chunks:   []string{`{"type":"system","subtype":"init","deep":` + strings.Repeat("[", 20000) + strings.Repeat("]", 20000) + "}\n", system},
+			messages: 1,
+			errCount: 1,
+		},
+		{
+			name:     "unbalanced braces inside strings",
+			chunks:   []string{`{"type":"system","subtype":"}}}{{{\"{"}`, `{"type":"system","subtype":"{"`, `}`},
+			messages: 2,
+		},
+		{
+			name:     "object completed by the last chunk without a newline",
+			chunks:   []string{`{"type":"system","subtype":"init"`, `} trailing output`},
+			messages: 1,
+		},
+		{
+			name:     "incomplete object at the end",
+			chunks:   []string{system + "\n" + `{"type":"system","subtype":`},
+			messages: 1,
+			errCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make(chan []byte, len(tt.chunks))
+			for _, chunk := range tt.chunks {
+				input <- []byte(chunk)
+			}
+			close(input)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			msgChan, errChan := NewParser(0).ParseMessages(ctx, input)
+
+			var messages, errCount int
+			for msgChan != nil || errChan != nil {
+				select {
+				case _, ok := <-msgChan:
+					if !ok {
+						msgChan = nil
+						continue
+					}
+					messages++
+				case _, ok := <-errChan:
+					if !ok {
+						errChan = nil
+						continue
+					}
+					errCount++
+				case <-ctx.Done():
+					t.Fatal("ParseMessages did not finish")
+				}
+			}
+			if messages != tt.messages || errCount != tt.errCount {
+				t.Errorf("Expected %d messages and %d errors, got %d and %d", tt.messages, tt.errCount, messages, errCount)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorsAreTyped(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		line   string
+	}{
+		{"malformed line", []string{`{"type": bad}` + "\n"}, `{"type": bad}`},
+		{"missing type", []string{`{"message": {}}`}, `{"message": {}}`},
+		{"missing type after other output", []string{"log\n" + `{"message": {}}` + "\n"}, `{"message": {}}`},
+		{"incomplete at the end", []string{`{"type": "assistant"`}, `{"type": "assistant"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make(chan []byte, len(tt.chunks))
+			for _, chunk := range tt.chunks {
+				input <- []byte(chunk)
+			}
+			close(input)
+
+			_, errChan := NewParser(0).ParseMessages(context.Background(), input)
+			err := <-errChan
+
+			var decodeErr *types.JSONDecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Expected *JSONDecodeError, got %T: %v", err, err)
+			}
+			if decodeErr.Line != tt.line {
+				t.Errorf("Expected line %q, got %q", tt.line, decodeErr.Line)
+			}
+			if !errors.Is(err, types.ErrJSONDecode) || decodeErr.OriginalErr == nil {
+				t.Errorf("Expected the cause and ErrJSONDecode in %v", err)
+			}
+		})
+	}
+}
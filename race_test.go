@@ -143,7 +143,7 @@ func TestConcurrentMessageCreation(t *testing.T) {
 						},
 						&ToolResultBlock{
 							ToolUseID: "tool_1",
-							Content:   stringPtr("tool result"),
+							Content:   []ToolResultContent{&TextContent{Text: "tool result"}},
 							IsError:   boolPtr(false),
 						},
 					},
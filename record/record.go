@@ -0,0 +1,202 @@
+// Package record captures the raw output of Claude Code queries and plays
+// it back, for deterministic tests and reproducible bug reports.
+//
+// A Recorder receives each raw line through Options.RawMessageHandler:
+//
+//	rec := record.NewRecorder(prompt, options)
+//	options.WithRawMessageHandler(rec.Record)
+//	stream, err := claudecode.Query(ctx, prompt, options)
+//	// ... consume the stream ...
+//	err = rec.Recording().WriteFile("testdata/query.jsonl")
+//
+// A ReplayTransport then feeds the recording through the normal parser and
+// stream pipeline:
+//
+//	recording, err := record.ReadFile("testdata/query.jsonl")
+//	stream, err := claudecode.QueryWithTransport(ctx, recording.Prompt, nil,
+//		record.NewReplayTransport(recording))
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Version is the recording format version written by this package.
+const Version = 1
+
+// Recording is a captured query: its prompt and options and every line the
+// CLI wrote to stdout, with the time each line arrived.
+//
+// Recordings are stored as JSON Lines: a header object followed by one
+// object per captured line.
+type Recording struct {
+	Version   int       `json:"version"`
+	Prompt    string    `json:"prompt"`
+	StartedAt time.Time `json:"started_at"`
+
+	// Options holds the query options as JSON, for reference. Credentials
+	// and callbacks are not serialized.
+	Options json.RawMessage `json:"options,omitempty"`
+
+	Lines []Line `json:"-"`
+}
+
+// Line is a single line of CLI output.
+type Line struct {
+	// Offset is when the line arrived, relative to the start of the
+	// recording. It is stored in nanoseconds.
+	Offset time.Duration `json:"offset"`
+
+	// Data holds the line when it is compact JSON, which CLI output
+	// normally is.
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// Text holds any other line, so that it is replayed byte for byte.
+	Text string `json:"text,omitempty"`
+}
+
+// Bytes returns the line as it was read from the CLI.
+func (l Line) Bytes() []byte {
+	if l.Data != nil {
+		return l.Data
+	}
+	return []byte(l.Text)
+}
+
+// Write writes the recording to w in JSON Lines format.
+func (r *Recording) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	header := *r
+	if header.Version == 0 {
+		header.Version = Version
+	}
+	if err := encoder.Encode(&header); err != nil {
+		return fmt.Errorf("failed to write recording header: %w", err)
+	}
+
+	for i, line := range r.Lines {
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to write recording line %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteFile writes the recording to the named file, creating or truncating it.
+func (r *Recording) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read reads a recording written by Recording.Write.
+func Read(r io.Reader) (*Recording, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var recording Recording
+	if err := decoder.Decode(&recording); err != nil {
+		return nil, fmt.Errorf("failed to read recording header: %w", err)
+	}
+	if recording.Version > Version {
+		return nil, fmt.Errorf("unsupported recording version %d", recording.Version)
+	}
+
+	for {
+		var line Line
+		err := decoder.Decode(&line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording line %d: %w", len(recording.Lines)+1, err)
+		}
+		recording.Lines = append(recording.Lines, line)
+	}
+
+	return &recording, nil
+}
+
+// ReadFile reads a recording from the named file.
+func ReadFile(name string) (*Recording, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Recorder captures the lines of a query as they arrive. Its Record method
+// is intended to be installed as the query's Options.RawMessageHandler.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	recording Recording
+}
+
+// NewRecorder creates a recorder for a query with the given prompt and
+// options. Timing offsets are measured from the call to NewRecorder.
+func NewRecorder(prompt string, options *types.Options) *Recorder {
+	rec := &Recorder{recording: Recording{
+		Version:   Version,
+		Prompt:    prompt,
+		StartedAt: time.Now(),
+	}}
+
+	if options != nil {
+		if data, err := json.Marshal(options); err == nil {
+			rec.recording.Options = data
+		}
+	}
+
+	return rec
+}
+
+// Record captures a line of CLI output.
+func (rec *Recorder) Record(line json.RawMessage) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	captured := Line{Offset: time.Since(rec.recording.StartedAt)}
+
+	// The file encoder compacts JSON, so only compact lines can be stored
+	// as JSON without changing them
+	var compact bytes.Buffer
+	if json.Compact(&compact, line) == nil && bytes.Equal(compact.Bytes(), line) {
+		captured.Data = append(json.RawMessage(nil), line...)
+	} else {
+		captured.Text = string(line)
+	}
+
+	rec.recording.Lines = append(rec.recording.Lines, captured)
+}
+
+// Recording returns a snapshot of everything recorded so far.
+func (rec *Recorder) Recording() *Recording {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	snapshot := rec.recording
+	snapshot.Lines = append([]Line(nil), rec.recording.Lines...)
+	return &snapshot
+}
@@ -0,0 +1,157 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/client"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+var testLines = []string{
+	`{"type":"system","subtype":"init","session_id":"abc"}`,
+	`{"type":"assistant","message":{"content":[{"type":"text","text":"Hi <there> & co!"}]}}`,
+	`{"type": "result", "subtype": "success", "session_id": "abc", "total_cost_usd": 0.01}`,
+}
+
+func newTestRecording(t *testing.T) *Recording {
+	t.Helper()
+
+	rec := NewRecorder("Hello", types.NewOptions().WithModel("sonnet").WithAPIKey("secret"))
+	for _, line := range testLines {
+		rec.Record(json.RawMessage(line))
+	}
+	rec.Record(json.RawMessage("Loading..."))
+	return rec.Recording()
+}
+
+func TestRecorderCapturesLines(t *testing.T) {
+	recording := newTestRecording(t)
+
+	if recording.Prompt != "Hello" {
+		t.Errorf("Expected prompt 'Hello', got %q", recording.Prompt)
+	}
+	if len(recording.Lines) != len(testLines)+1 {
+		t.Fatalf("Expected %d lines, got %d", len(testLines)+1, len(recording.Lines))
+	}
+	for i, line := range testLines {
+		if got := string(recording.Lines[i].Bytes()); got != line {
+			t.Errorf("Line %d: expected %q, got %q", i, line, got)
+		}
+	}
+	if recording.Lines[0].Data == nil {
+		t.Error("Expected compact JSON line to be kept as JSON")
+	}
+	if recording.Lines[2].Data != nil || recording.Lines[3].Data != nil {
+		t.Error("Expected non-compact and non-JSON lines to be kept as text")
+	}
+	if bytes.Contains(recording.Options, []byte("secret")) {
+		t.Error("Recorded options should not contain credentials")
+	}
+	if !bytes.Contains(recording.Options, []byte("sonnet")) {
+		t.Errorf("Expected recorded options to include the model, got %s", recording.Options)
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	recording := newTestRecording(t)
+	path := filepath.Join(t.TempDir(), "query.jsonl")
+
+	if err := recording.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	loaded, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	if loaded.Version != Version || loaded.Prompt != recording.Prompt {
+		t.Errorf("Header mismatch: got version %d prompt %q", loaded.Version, loaded.Prompt)
+	}
+	if !loaded.StartedAt.Equal(recording.StartedAt) {
+		t.Errorf("Expected start time %v, got %v", recording.StartedAt, loaded.StartedAt)
+	}
+	if len(loaded.Lines) != len(recording.Lines) {
+		t.Fatalf("Expected %d lines, got %d", len(recording.Lines), len(loaded.Lines))
+	}
+	for i := range recording.Lines {
+		if loaded.Lines[i].Offset != recording.Lines[i].Offset {
+			t.Errorf("Line %d: expected offset %v, got %v", i, recording.Lines[i].Offset, loaded.Lines[i].Offset)
+		}
+		if !bytes.Equal(loaded.Lines[i].Bytes(), recording.Lines[i].Bytes()) {
+			t.Errorf("Line %d: expected %q, got %q", i, recording.Lines[i].Bytes(), loaded.Lines[i].Bytes())
+		}
+	}
+}
+
+func TestReadRejectsNewerVersion(t *testing.T) {
+	_, err := Read(bytes.NewBufferString(`{"version": 99, "prompt": "x"}` + "\n"))
+	if err == nil {
+		t.Fatal("Expected error for unsupported version")
+	}
+}
+
+func TestReplayThroughClient(t *testing.T) {
+	recording := newTestRecording(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := client.NewClient().QueryWithTransport(ctx, recording.Prompt, nil, NewReplayTransport(recording))
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	var messages []types.Message
+	for msg := range stream.Messages() {
+		messages = append(messages, msg)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	result, ok := messages[2].(*types.ResultMessage)
+	if !ok {
+		t.Fatalf("Expected ResultMessage, got %T", messages[2])
+	}
+	if result.SessionID != "abc" {
+		t.Errorf("Expected session ID 'abc', got %q", result.SessionID)
+	}
+}
+
+func TestReplayHonorsSpeed(t *testing.T) {
+	recording := &Recording{Lines: []Line{
+		{Offset: 0, Data: json.RawMessage(testLines[0])},
+		{Offset: 100 * time.Millisecond, Data: json.RawMessage(testLines[1])},
+	}}
+
+	transport := NewReplayTransport(recording)
+	transport.Speed = 2
+
+	ctx := context.Background()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	start := time.Now()
+	data, _ := transport.Stream(ctx)
+	count := 0
+	for range data {
+		count++
+	}
+	elapsed := time.Since(start)
+
+	if count != 2 {
+		t.Errorf("Expected 2 lines, got %d", count)
+	}
+	if elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected about 50ms of replay delay, took %v", elapsed)
+	}
+}
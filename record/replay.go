@@ -0,0 +1,108 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReplayTransport is a transport that plays back a Recording instead of
+// running the CLI. By default lines are delivered as fast as they are
+// consumed; set Speed to reproduce the recorded timing.
+//
+// Replay covers the CLI's output only. Queries that need the control
+// protocol, such as those with hooks, cannot be replayed because the SDK's
+// requests would not match the recorded responses.
+type ReplayTransport struct {
+	recording *Recording
+
+	// Speed scales the recorded delays between lines: 1 replays in real
+	// time, 2 twice as fast. Zero or negative replays without delays.
+	Speed float64
+
+	mu        sync.Mutex
+	connected bool
+	cancel    context.CancelFunc
+}
+
+// NewReplayTransport creates a transport that plays back recording.
+func NewReplayTransport(recording *Recording) *ReplayTransport {
+	return &ReplayTransport{recording: recording}
+}
+
+// Connect prepares the transport for streaming.
+func (rt *ReplayTransport) Connect(ctx context.Context) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.recording == nil {
+		return fmt.Errorf("replay transport has no recording")
+	}
+	rt.connected = true
+	return nil
+}
+
+// Stream delivers the recorded lines in order.
+func (rt *ReplayTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, 10)
+	errChan := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	rt.mu.Lock()
+	rt.cancel = cancel
+	connected := rt.connected
+	rt.mu.Unlock()
+
+	go func() {
+		defer close(dataChan)
+		defer close(errChan)
+		defer cancel()
+
+		if !connected {
+			errChan <- fmt.Errorf("transport not connected")
+			return
+		}
+
+		var previous time.Duration
+		for _, line := range rt.recording.Lines {
+			if rt.Speed > 0 && line.Offset > previous {
+				timer := time.NewTimer(time.Duration(float64(line.Offset-previous) / rt.Speed))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+			previous = line.Offset
+
+			select {
+			case dataChan <- line.Bytes():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataChan, errChan
+}
+
+// Close stops playback. It is safe to call multiple times.
+func (rt *ReplayTransport) Close() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.connected = false
+	if rt.cancel != nil {
+		rt.cancel()
+	}
+	return nil
+}
+
+// IsConnected returns true between Connect and Close.
+func (rt *ReplayTransport) IsConnected() bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.connected
+}
@@ -0,0 +1,52 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+)
+
+// Interceptor returns a client interceptor that masks the prompts of
+// queries and session turns, including the text of multi-part prompts,
+// before they are sent to the CLI. If the options have a
+// RawMessageHandler, it receives masked lines.
+//
+// Messages delivered on a stream are not changed; read them through
+// Filter to mask them too.
+func (r *Redactor) Interceptor() claudecode.Interceptor {
+	return func(ctx context.Context, req *claudecode.Request, next claudecode.QueryHandler) (*claudecode.QueryStream, error) {
+		req.Prompt = r.String(req.Prompt)
+		req.Content = r.content(req.Content)
+
+		if handler := req.Options.RawMessageHandler; handler != nil && req.Kind != claudecode.RequestSend {
+			req.Options.RawMessageHandler = func(line json.RawMessage) {
+				handler(r.RawLine(line))
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// content returns copies of prompt content blocks with their text masked.
+// Images and other blocks are kept as they are.
+func (r *Redactor) content(blocks []map[string]any) []map[string]any {
+	if blocks == nil {
+		return nil
+	}
+	out := make([]map[string]any, len(blocks))
+	for i, block := range blocks {
+		text, ok := block["text"].(string)
+		if !ok || block["type"] != "text" {
+			out[i] = block
+			continue
+		}
+		c := make(map[string]any, len(block))
+		for key, value := range block {
+			c[key] = value
+		}
+		c["text"] = r.String(text)
+		out[i] = c
+	}
+	return out
+}
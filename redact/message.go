@@ -0,0 +1,154 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Message returns a copy of msg with the text of its content masked:
+// assistant text and thinking, tool inputs and results, user prompts,
+// result text, and system message data. msg itself is not changed, so it
+// stays intact for other consumers of the same stream. Messages without
+// such content, such as progress events, are returned as they are.
+func (r *Redactor) Message(msg types.Message) types.Message {
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		c := *m
+		c.Content = r.blocks(m.Content)
+		return &c
+
+	case *types.UserMessage:
+		c := *m
+		c.Content = r.String(m.Content)
+		c.Blocks = r.blocks(m.Blocks)
+		return &c
+
+	case *types.ResultMessage:
+		c := *m
+		if m.Result != nil {
+			result := r.String(*m.Result)
+			c.Result = &result
+		}
+		if m.PermissionDenials != nil {
+			c.PermissionDenials = make([]types.PermissionDenial, len(m.PermissionDenials))
+			for i, denial := range m.PermissionDenials {
+				denial.ToolInput = r.object(denial.ToolInput)
+				c.PermissionDenials[i] = denial
+			}
+		}
+		return &c
+
+	case *types.SystemMessage:
+		c := *m
+		c.Data = r.object(m.Data)
+		return &c
+
+	case *types.UnknownMessage:
+		c := *m
+		c.Raw = r.RawLine(m.Raw)
+		return &c
+	}
+	return msg
+}
+
+// blocks returns masked copies of content blocks.
+func (r *Redactor) blocks(blocks []types.ContentBlock) []types.ContentBlock {
+	if blocks == nil {
+		return nil
+	}
+	out := make([]types.ContentBlock, len(blocks))
+	for i, block := range blocks {
+		out[i] = r.block(block)
+	}
+	return out
+}
+
+// block returns a masked copy of a content block.
+func (r *Redactor) block(block types.ContentBlock) types.ContentBlock {
+	switch b := block.(type) {
+	case *types.TextBlock:
+		return &types.TextBlock{Text: r.String(b.Text)}
+
+	case *types.ThinkingBlock:
+		c := *b
+		c.Thinking = r.String(b.Thinking)
+		return &c
+
+	case *types.ToolUseBlock:
+		c := *b
+		c.Input = r.object(b.Input)
+		return &c
+
+	case *types.ToolResultBlock:
+		c := *b
+		if b.Content != nil {
+			c.Content = make([]types.ToolResultContent, len(b.Content))
+			for i, part := range b.Content {
+				c.Content[i] = r.resultPart(part)
+			}
+		}
+		return &c
+
+	case *types.UnknownBlock:
+		c := *b
+		c.Raw = r.RawLine(b.Raw)
+		return &c
+	}
+	return block
+}
+
+// resultPart returns a masked copy of a part of a tool result. Images are
+// kept as they are.
+func (r *Redactor) resultPart(part types.ToolResultContent) types.ToolResultContent {
+	switch p := part.(type) {
+	case *types.TextContent:
+		return &types.TextContent{Text: r.String(p.Text)}
+	case *types.JSONContent:
+		return &types.JSONContent{Raw: r.RawLine(p.Raw)}
+	}
+	return part
+}
+
+// object returns a masked copy of a JSON object.
+func (r *Redactor) object(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	return r.Value(m).(map[string]any)
+}
+
+// RawLine returns a line of JSON, such as one passed to
+// Options.RawMessageHandler, with every string in it masked. Input that is
+// not valid JSON is masked as text.
+func (r *Redactor) RawLine(line json.RawMessage) json.RawMessage {
+	// Numbers are kept as written
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return json.RawMessage(r.String(string(line)))
+	}
+	masked, err := json.Marshal(r.Value(v))
+	if err != nil {
+		return json.RawMessage(r.String(string(line)))
+	}
+	return masked
+}
+
+// Filter forwards a masked copy of each message received from messages on
+// the returned channel, which is closed once messages is. The returned
+// channel must be drained.
+func (r *Redactor) Filter(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			out <- r.Message(msg)
+		}
+	}()
+
+	return out
+}
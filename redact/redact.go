@@ -0,0 +1,197 @@
+// Package redact masks secrets and personal data, such as API keys, private
+// keys, and email addresses, in prompts before they reach the CLI and in
+// messages before they reach logs, transcripts, or other systems.
+//
+//	r := redact.New() // redact.Default() detectors
+//	client.Use(r.Interceptor())
+//
+//	stream, err := client.Query(ctx, prompt, options)
+//	for msg := range r.Filter(stream.Messages()) {
+//		log.Print(msg) // masked as "[REDACTED:email]"
+//	}
+//
+// Detectors find what to mask. Pattern builds one from a regular
+// expression, and DetectorFunc adapts any function, such as a call to a
+// dedicated scanning service.
+package redact
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"sync/atomic"
+)
+
+// Span is a range of text to mask, as byte offsets [Start, End), with
+// the kind of data found there, such as "email".
+type Span struct {
+	Start int
+	End   int
+	Kind  string
+}
+
+// Detector finds sensitive data in text.
+type Detector interface {
+	// Detect returns the spans of text to mask, in any order. Spans may
+	// overlap.
+	Detect(text string) []Span
+}
+
+// DetectorFunc adapts a function to a Detector.
+type DetectorFunc func(text string) []Span
+
+// Detect calls f.
+func (f DetectorFunc) Detect(text string) []Span {
+	return f(text)
+}
+
+// patternDetector finds the matches of regular expressions.
+type patternDetector struct {
+	kind     string
+	patterns []*regexp.Regexp
+}
+
+// Pattern returns a detector reporting each match of any of the patterns
+// as data of the given kind. It panics if a pattern does not compile.
+func Pattern(kind string, patterns ...string) Detector {
+	d := &patternDetector{kind: kind}
+	for _, pattern := range patterns {
+		d.patterns = append(d.patterns, regexp.MustCompile(pattern))
+	}
+	return d
+}
+
+func (d *patternDetector) Detect(text string) []Span {
+	var spans []Span
+	for _, re := range d.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			spans = append(spans, Span{Start: loc[0], End: loc[1], Kind: d.kind})
+		}
+	}
+	return spans
+}
+
+// APIKeys detects API keys and tokens with recognizable formats: those of
+// Anthropic, OpenAI, AWS access keys, GitHub, Slack, and Google, and
+// bearer tokens in Authorization headers.
+func APIKeys() Detector {
+	return Pattern("api_key",
+		`\bsk-(?:ant-)?[A-Za-z0-9_-]{20,}`,
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+		`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`,
+		`\bxox[abposr]-[A-Za-z0-9-]{10,}`,
+		`\bAIza[0-9A-Za-z_-]{35}`,
+		`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`,
+	)
+}
+
+// PrivateKeys detects PEM-encoded private keys.
+func PrivateKeys() Detector {
+	return Pattern("private_key",
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	)
+}
+
+// Emails detects email addresses.
+func Emails() Detector {
+	return Pattern("email", `[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+}
+
+// Default returns the detectors a Redactor uses when given none: APIKeys,
+// PrivateKeys, and Emails.
+func Default() []Detector {
+	return []Detector{APIKeys(), PrivateKeys(), Emails()}
+}
+
+// Redactor masks what its detectors find. It is safe for concurrent use.
+type Redactor struct {
+	detectors []Detector
+
+	// Mask returns the text replacing a match of the given kind. If nil,
+	// matches are replaced with "[REDACTED:kind]".
+	Mask func(kind, match string) string
+
+	redactions atomic.Int64
+}
+
+// New returns a redactor using the given detectors, or Default() if none
+// are given.
+func New(detectors ...Detector) *Redactor {
+	if len(detectors) == 0 {
+		detectors = Default()
+	}
+	return &Redactor{detectors: detectors}
+}
+
+// Redactions returns the number of matches masked so far.
+func (r *Redactor) Redactions() int64 {
+	return r.redactions.Load()
+}
+
+// String returns text with everything the detectors find masked.
+// Overlapping matches are masked as one, with the kind of the earliest.
+func (r *Redactor) String(text string) string {
+	var spans []Span
+	for _, d := range r.detectors {
+		for _, span := range d.Detect(text) {
+			if span.Start >= 0 && span.End <= len(text) && span.Start < span.End {
+				spans = append(spans, span)
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.Start < last.End {
+			last.End = max(last.End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	r.redactions.Add(int64(len(merged)))
+
+	var out []byte
+	prev := 0
+	for _, span := range merged {
+		out = append(out, text[prev:span.Start]...)
+		out = append(out, r.mask(span.Kind, text[span.Start:span.End])...)
+		prev = span.End
+	}
+	out = append(out, text[prev:]...)
+	return string(out)
+}
+
+// mask returns the replacement for a match.
+func (r *Redactor) mask(kind, match string) string {
+	if r.Mask != nil {
+		return r.Mask(kind, match)
+	}
+	return "[REDACTED:" + kind + "]"
+}
+
+// Value returns a copy of a decoded JSON value, such as a tool's input,
+// with every string in it masked. Map keys are kept.
+func (r *Redactor) Value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = r.Value(value)
+		}
+		return out
+	case []any:
+		out := slices.Clone(v)
+		for i, value := range out {
+			out[i] = r.Value(value)
+		}
+		return out
+	}
+	return v
+}
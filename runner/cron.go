@@ -0,0 +1,193 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record a "*" day field; a day matches if both
+	// day fields do when either is "*", and if either does otherwise.
+	domAny, dowAny bool
+}
+
+// field describes one field of a cron expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week 7 is Sunday, as is 0
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors are the shorthand schedules.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a standard five-field cron expression: minute,
+// hour, day of month, month, and day of week. Fields take "*", numbers,
+// ranges ("1-5"), steps ("*/15", "0-30/10"), and lists ("1,15"), and
+// months and days of the week may be given by their first three letters.
+// The descriptors @yearly, @monthly, @weekly, @daily, and @hourly are also
+// accepted.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{
+		domAny: fields[2] == "*" || fields[2] == "?",
+		dowAny: fields[4] == "*" || fields[4] == "?",
+	}
+	targets := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, f := range []field{minuteField, hourField, domField, monthField, dowField} {
+		bits, err := f.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		*targets[i] = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// MustParseSchedule is like ParseSchedule but panics on an invalid
+// expression.
+func MustParseSchedule(expr string) *Schedule {
+	s, err := ParseSchedule(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parse returns the set of values a field matches, as bits.
+func (f field) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangeText == "*" || rangeText == "?":
+		case strings.Contains(rangeText, "-"):
+			loText, hiText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if lo, err = f.value(loText); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(hiText); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(rangeText)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rangeText)
+		}
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepText)
+			}
+			step = n
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or name in a field.
+func (f field) value(text string) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, text)
+	}
+	return v, nil
+}
+
+// Next returns the first time the schedule matches after t, to the
+// minute, in t's location. It returns the zero time if the schedule never
+// matches, such as for February 30.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Any schedule that can match does so within a few years
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day fields match t.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
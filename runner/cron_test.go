@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"invalid", "expected 5 fields"},
+		{"60 * * * *", "invalid minute"},
+		{"* 24 * * *", "invalid hour"},
+		{"* * 0 * *", "invalid day of month"},
+		{"* * * foo *", "invalid month"},
+		{"* * * * 8", "invalid day of week"},
+		{"5-1 * * * *", "invalid minute range"},
+		{"*/0 * * * *", "invalid minute step"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := ParseSchedule(tt.expr); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2025-01-01 is a Wednesday
+	from := time.Date(2025, 1, 1, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"* * * * *", "2025-01-01 10:31"},
+		{"*/15 * * * *", "2025-01-01 10:45"},
+		{"0 2 * * *", "2025-01-02 02:00"},
+		{"@daily", "2025-01-02 00:00"},
+		{"@hourly", "2025-01-01 11:00"},
+		{"0 9 * * mon-fri", "2025-01-02 09:00"},
+		{"0 9 * * 1", "2025-01-06 09:00"},
+		{"0 9 * * 7", "2025-01-05 09:00"},
+		{"0 0 1 */3 *", "2025-04-01 00:00"},
+		{"30 8 15 feb *", "2025-02-15 08:30"},
+		{"0 0 29 2 *", "2028-02-29 00:00"},
+		{"10,40 10 * * *", "2025-01-01 10:40"},
+		{"5/20 * * * *", "2025-01-01 10:45"},
+		// Both day fields restricted: either matches
+		{"0 12 13 * fri", "2025-01-03 12:00"},
+		{"@weekly", "2025-01-05 00:00"},
+		{"@monthly", "2025-02-01 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			next := MustParseSchedule(tt.expr).Next(from)
+			if got := next.Format("2006-01-02 15:04"); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if next := MustParseSchedule("0 0 30 2 *").Next(from); !next.IsZero() {
+		t.Errorf("Expected no match for February 30, got %v", next)
+	}
+}
+
+func TestScheduledJobs(t *testing.T) {
+	r := newRunner(t, NewMemoryStore())
+
+	var mu sync.Mutex
+	now := time.Date(2025, 1, 1, 1, 59, 30, 0, time.UTC)
+	r.clock = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	timers := make(chan chan time.Time, 10)
+	var waits []time.Duration
+	r.timer = func(d time.Duration) <-chan time.Time {
+		mu.Lock()
+		waits = append(waits, d)
+		mu.Unlock()
+		c := make(chan time.Time, 1)
+		timers <- c
+		return c
+	}
+
+	if err := r.Schedule(Job{Name: "nightly", Schedule: "0 2 * * *", Location: time.UTC, Prompt: "audit", Metadata: map[string]string{"team": "infra"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Schedule(Job{Name: "bad", Schedule: "every night"}); err == nil {
+		t.Error("Expected an invalid schedule to be rejected")
+	}
+	<-r.jobsChanged
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go r.Run(ctx)
+
+	timer := <-timers
+	mu.Lock()
+	if len(waits) != 1 || waits[0] != 30*time.Second {
+		t.Errorf("Expected to wait 30s for 02:00, got %v", waits)
+	}
+	now = now.Add(30 * time.Second)
+	mu.Unlock()
+	timer <- now
+
+	// The scheduler asks for the next night once it has submitted the run
+	<-timers
+	history, err := r.History(ctx, "nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected one run, got %d", len(history))
+	}
+	task, err := r.Wait(ctx, history[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != StateDone || task.Metadata["team"] != "infra" || task.Metadata[MetadataScheduledAt] != "2025-01-01T02:00:00Z" {
+		t.Errorf("Unexpected scheduled task: %+v", task)
+	}
+	mu.Lock()
+	if waits[1] != 24*time.Hour {
+		t.Errorf("Expected to wait a day for the next run, got %v", waits[1])
+	}
+	mu.Unlock()
+
+	if !r.Unschedule("nightly") || r.Unschedule("nightly") {
+		t.Error("Expected the job to be removed once")
+	}
+}
+
+func TestOverlapPrevention(t *testing.T) {
+	store := NewMemoryStore()
+	r := New(store, nil)
+
+	var missed []error
+	r.OnMissed = func(job Job, at time.Time, err error) {
+		missed = append(missed, err)
+	}
+
+	ctx := context.Background()
+	at := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	weekly := &scheduledJob{Job: Job{Name: "deps", Prompt: "review dependencies"}}
+	overlapping := &scheduledJob{Job: Job{Name: "deps", Prompt: "review dependencies", AllowOverlap: true}}
+
+	r.fire(ctx, weekly, at)
+	r.fire(ctx, weekly, at.AddDate(0, 0, 7))
+	r.fire(ctx, overlapping, at.AddDate(0, 0, 14))
+
+	if len(missed) != 1 || !errors.Is(missed[0], ErrOverlap) {
+		t.Errorf("Expected the second run to be skipped, got %v", missed)
+	}
+	history, err := r.History(ctx, "deps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[1].Metadata[MetadataScheduledAt] != "2025-01-06T09:00:00Z" {
+		t.Errorf("Expected two runs, newest first, got %+v", history)
+	}
+
+	// A finished run does not block the next
+	history[0].State, history[1].State = StateDone, StateFailed
+	store.Put(ctx, history[0])
+	store.Put(ctx, history[1])
+	r.fire(ctx, weekly, at.AddDate(0, 0, 21))
+	if len(missed) != 1 {
+		t.Errorf("Expected the run to be submitted, got %v", missed)
+	}
+}
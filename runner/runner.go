@@ -0,0 +1,446 @@
+// Package runner runs queued agent tasks with bounded concurrency,
+// persisting each task's state, result, and transcript in a Store so that
+// work survives a restart of the host process.
+//
+//	store, err := runner.NewFileStore("/var/lib/agent/tasks")
+//	r := runner.New(store, claudecode.NewClient(claudecode.ClientOptions{}))
+//	r.Concurrency = 2
+//	go r.Run(ctx)
+//
+//	task, err := r.Submit(ctx, "Triage the open issues", options, map[string]string{"repo": "api"})
+//	task, err = r.Wait(ctx, task.ID)
+//
+// Tasks that were running when the process stopped are picked up by the
+// next Run. If the CLI had reported their session, they resume it with
+// ResumePrompt; otherwise they start again.
+//
+// Recurring jobs are scheduled with cron expressions, and submitted as
+// tasks by Run:
+//
+//	r.Schedule(runner.Job{Name: "nightly-audit", Schedule: "0 2 * * *", Prompt: "Audit the repository"})
+//
+// Options are stored as JSON, so callbacks such as hooks and handlers are
+// not kept. Set Configure to add them each time a task runs.
+package runner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// State is where a task is in its life.
+type State string
+
+// Task states.
+const (
+	StatePending State = "pending"
+	StateRunning State = "running"
+	StateDone    State = "done"
+	StateFailed  State = "failed"
+)
+
+// Finished reports whether the state is final.
+func (s State) Finished() bool {
+	return s == StateDone || s == StateFailed
+}
+
+// Task is a queued prompt and everything known about its run.
+type Task struct {
+	ID       string            `json:"id"`
+	Prompt   string            `json:"prompt"`
+	Options  *types.Options    `json:"options,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	State State `json:"state"`
+
+	// Attempts counts the runs started, including those interrupted by a
+	// restart.
+	Attempts int `json:"attempts"`
+
+	// SessionID is the CLI session the task runs in, once reported.
+	SessionID string `json:"session_id,omitempty"`
+
+	// Result is the final result message of a finished run.
+	Result *types.ResultMessage `json:"result,omitempty"`
+
+	// Error describes why a failed task failed.
+	Error string `json:"error,omitempty"`
+
+	// Transcript is the last run's transcript, in the JSON form of the
+	// transcript package.
+	Transcript json.RawMessage `json:"transcript,omitempty"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// DefaultConcurrency is the number of tasks a Runner runs at once by
+// default.
+const DefaultConcurrency = 4
+
+// DefaultMaxAttempts is how many runs a task gets by default before an
+// interrupted task is failed.
+const DefaultMaxAttempts = 3
+
+// DefaultResumePrompt is sent to resume the session of an interrupted task.
+const DefaultResumePrompt = "The previous run was interrupted. Continue the task where you left off."
+
+// Runner executes tasks from a Store.
+type Runner struct {
+	store  Store
+	client *claudecode.Client
+
+	// Concurrency is the maximum number of tasks running at once. If
+	// zero, DefaultConcurrency is used.
+	Concurrency int
+
+	// MaxAttempts is the number of runs a task may start. A task
+	// interrupted after its last attempt is failed. If zero,
+	// DefaultMaxAttempts is used.
+	MaxAttempts int
+
+	// ResumePrompt is sent when resuming the session of an interrupted
+	// task. If empty, DefaultResumePrompt is used.
+	ResumePrompt string
+
+	// Configure, if set, is called with a copy of a task's options before
+	// each run, to add what is not persisted, such as hooks.
+	Configure func(task *Task, options *types.Options)
+
+	// OnUpdate, if set, is called with each task saved by the Runner.
+	OnUpdate func(task Task)
+
+	// OnMissed, if set, is called for each run of a scheduled job that was
+	// not submitted, with ErrOverlap or the store's error.
+	OnMissed func(job Job, at time.Time, err error)
+
+	mu          sync.Mutex
+	wake        chan struct{}
+	changed     chan struct{}
+	jobs        map[string]*scheduledJob
+	jobsChanged chan struct{}
+
+	// clock and timer replace time.Now and time.After in tests
+	clock func() time.Time
+	timer func(d time.Duration) <-chan time.Time
+}
+
+// New returns a Runner running the tasks in store with client.
+func New(store Store, client *claudecode.Client) *Runner {
+	return &Runner{
+		store:       store,
+		client:      client,
+		wake:        make(chan struct{}, 1),
+		changed:     make(chan struct{}),
+		jobsChanged: make(chan struct{}, 1),
+	}
+}
+
+// Store returns the Runner's store.
+func (r *Runner) Store() Store {
+	return r.store
+}
+
+// Submit queues a task to run prompt with options, which may be nil.
+// Metadata is stored with the task for the caller's use.
+func (r *Runner) Submit(ctx context.Context, prompt string, options *types.Options, metadata map[string]string) (*Task, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	if options != nil {
+		options = options.Clone()
+	}
+	task := &Task{
+		ID:        id,
+		Prompt:    prompt,
+		Options:   options,
+		Metadata:  metadata,
+		State:     StatePending,
+		CreatedAt: time.Now(),
+	}
+	if err := r.save(ctx, task); err != nil {
+		return nil, err
+	}
+	r.signal()
+	return task, nil
+}
+
+// newID returns a random task ID.
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate task ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Get returns the task with the given ID.
+func (r *Runner) Get(ctx context.Context, id string) (*Task, error) {
+	return r.store.Get(ctx, id)
+}
+
+// Wait waits until the task with the given ID is finished and returns it.
+// Only updates made by this Runner are noticed.
+func (r *Runner) Wait(ctx context.Context, id string) (*Task, error) {
+	for {
+		r.mu.Lock()
+		changed := r.changed
+		r.mu.Unlock()
+
+		task, err := r.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if task.State.Finished() {
+			return task, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return task, ctx.Err()
+		}
+	}
+}
+
+// save stores task and tells waiters.
+func (r *Runner) save(ctx context.Context, task *Task) error {
+	if err := r.store.Put(ctx, task); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.mu.Unlock()
+
+	if r.OnUpdate != nil {
+		r.OnUpdate(*task)
+	}
+	return nil
+}
+
+// signal asks Run to look for pending tasks.
+func (r *Runner) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run executes pending tasks and submits the runs of scheduled jobs until
+// ctx is done, first requeuing tasks left running by an earlier process.
+// Tasks still running when ctx is done are left pending for the next Run.
+// Run returns ctx's error, or the store's if it fails.
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.recover(ctx); err != nil {
+		return err
+	}
+
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.schedule(ctx)
+	}()
+
+	for {
+		pending, err := r.store.List(ctx, StatePending)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+	dispatch:
+		for _, task := range pending {
+			select {
+			case slots <- struct{}{}:
+			default:
+				break dispatch
+			}
+			if err := r.start(ctx, task); err != nil {
+				<-slots
+				if ctx.Err() == nil {
+					return err
+				}
+				break
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.execute(ctx, task)
+				<-slots
+				r.signal()
+			}()
+		}
+
+		select {
+		case <-r.wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// recover requeues tasks left running by a process that stopped.
+func (r *Runner) recover(ctx context.Context) error {
+	running, err := r.store.List(ctx, StateRunning)
+	if err != nil {
+		return err
+	}
+	for _, task := range running {
+		task.State = StatePending
+		if err := r.save(ctx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start marks a pending task running, or fails it if it has no attempts
+// left.
+func (r *Runner) start(ctx context.Context, task *Task) error {
+	maxAttempts := r.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if task.Attempts >= maxAttempts {
+		task.State = StateFailed
+		task.Error = fmt.Sprintf("interrupted after %d attempts", task.Attempts)
+		task.FinishedAt = time.Now()
+		return r.save(ctx, task)
+	}
+
+	task.State = StateRunning
+	task.Attempts++
+	task.StartedAt = time.Now()
+	return r.save(ctx, task)
+}
+
+// execute runs a task marked running and saves the outcome.
+func (r *Runner) execute(ctx context.Context, task *Task) {
+	if task.State != StateRunning {
+		return
+	}
+
+	options := types.NewOptions()
+	if task.Options != nil {
+		options = task.Options.Clone()
+	}
+	prompt := task.Prompt
+	if task.SessionID != "" {
+		sessionID := task.SessionID
+		options.Resume = &sessionID
+		prompt = r.ResumePrompt
+		if prompt == "" {
+			prompt = DefaultResumePrompt
+		}
+	}
+	if r.Configure != nil {
+		r.Configure(task, options)
+	}
+
+	tr := transcript.New(prompt)
+	result, err := r.query(ctx, task, prompt, options, tr)
+
+	// The store must hear of the outcome even once ctx is done
+	saveCtx := context.WithoutCancel(ctx)
+	if data, err := tr.MarshalJSON(); err == nil {
+		task.Transcript = data
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		// Interrupted: the next Run picks the task up again
+		task.State = StatePending
+	case err != nil:
+		task.State = StateFailed
+		task.Error = err.Error()
+	case result == nil:
+		task.State = StateFailed
+		task.Error = "the CLI ended without a result"
+	default:
+		task.Result = result
+		task.State = StateDone
+		if result.IsError {
+			task.State = StateFailed
+			task.Error = "run ended with " + result.Subtype
+		}
+	}
+	if task.State.Finished() {
+		task.FinishedAt = time.Now()
+	}
+	r.save(saveCtx, task)
+}
+
+// query runs one attempt of a task, recording it in tr and saving the
+// session ID as soon as the CLI reports it.
+func (r *Runner) query(ctx context.Context, task *Task, prompt string, options *types.Options, tr *transcript.Transcript) (*types.ResultMessage, error) {
+	stream, err := r.client.Query(ctx, prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var result *types.ResultMessage
+	var errs []error
+	messages, streamErrs := stream.Messages(), stream.Errors()
+	for messages != nil || streamErrs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			tr.Add(msg)
+			switch m := msg.(type) {
+			case *types.SystemMessage:
+				if info, ok := m.Init(); ok && info.SessionID != "" && info.SessionID != task.SessionID {
+					task.SessionID = info.SessionID
+					r.save(context.WithoutCancel(ctx), task)
+				}
+			case *types.ResultMessage:
+				result = m
+				if m.SessionID != "" {
+					task.SessionID = m.SessionID
+				}
+			}
+
+		case err, ok := <-streamErrs:
+			if !ok {
+				streamErrs = nil
+				continue
+			}
+			errs = append(errs, err)
+
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+
+	if result == nil && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
@@ -0,0 +1,276 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// writeCLI writes a fake CLI that answers with its prompt and the session
+// it resumed. The prompt "fail" fails, and "hang" hangs after reporting
+// its session.
+func writeCLI(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--resume) resume="$2"; shift ;;
+	--print) prompt="$2"; shift ;;
+	esac
+	shift
+done
+if [ "$prompt" = "fail" ]; then
+	echo "something broke" >&2
+	exit 1
+fi
+session="${resume:-session-$prompt}"
+echo '{"type":"system","subtype":"init","session_id":"'"$session"'"}'
+if [ "$prompt" = "hang" ]; then
+	exec sleep 30
+fi
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"'"$prompt|$resume"'"}]}}'
+echo '{"type":"result","subtype":"success","session_id":"'"$session"'","num_turns":1}'
+`
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cliPath
+}
+
+func newRunner(t *testing.T, store Store) *Runner {
+	return New(store, claudecode.NewClient(claudecode.ClientOptions{CLIPath: writeCLI(t)}))
+}
+
+func TestRunner(t *testing.T) {
+	r := newRunner(t, NewMemoryStore())
+	r.Concurrency = 2
+
+	var mu sync.Mutex
+	maxRunning, running := 0, map[string]bool{}
+	r.OnUpdate = func(task Task) {
+		mu.Lock()
+		defer mu.Unlock()
+		running[task.ID] = task.State == StateRunning
+		n := 0
+		for _, ok := range running {
+			if ok {
+				n++
+			}
+		}
+		maxRunning = max(maxRunning, n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var tasks []*Task
+	for _, prompt := range []string{"one", "fail", "two", "three"} {
+		task, err := r.Submit(ctx, prompt, types.NewOptions().WithMaxTurns(5), map[string]string{"prompt": prompt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+
+	for _, submitted := range tasks {
+		task, err := r.Wait(ctx, submitted.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prompt := task.Metadata["prompt"]
+		if prompt == "fail" {
+			if task.State != StateFailed || !strings.Contains(task.Error, "something broke") {
+				t.Errorf("Expected the failing task to fail, got %s: %q", task.State, task.Error)
+			}
+			continue
+		}
+
+		if task.State != StateDone || task.Attempts != 1 || task.SessionID != "session-"+prompt {
+			t.Errorf("Unexpected task: %+v", task)
+		}
+		if task.Result == nil || task.Result.NumTurns != 1 || task.FinishedAt.IsZero() {
+			t.Errorf("Expected the result to be kept, got %+v", task.Result)
+		}
+		if !strings.Contains(string(task.Transcript), prompt+"|") {
+			t.Errorf("Expected the transcript to be kept, got %s", task.Transcript)
+		}
+		if task.Options == nil || task.Options.MaxTurns == nil || *task.Options.MaxTurns != 5 {
+			t.Errorf("Expected the options to be kept, got %+v", task.Options)
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Run to stop with ctx, got %v", err)
+	}
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 tasks running at once, got %d", maxRunning)
+	}
+}
+
+func TestResumeAfterRestart(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first process is stopped while the task runs
+	first := newRunner(t, store)
+	started := make(chan struct{})
+	var once sync.Once
+	first.OnUpdate = func(task Task) {
+		if task.SessionID != "" {
+			once.Do(func() { close(started) })
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	task, err := first.Submit(ctx, "hang", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- first.Run(ctx) }()
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the task to start")
+	}
+	cancel()
+	<-done
+
+	interrupted, err := store.Get(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interrupted.State != StatePending || interrupted.SessionID != "session-hang" || interrupted.Attempts != 1 {
+		t.Fatalf("Expected the task to be left pending with its session, got %+v", interrupted)
+	}
+
+	// A task left running by a crash is also picked up
+	crashed := &Task{ID: "crashed", Prompt: "again", State: StateRunning, Attempts: 1, CreatedAt: time.Now()}
+	if err := store.Put(context.Background(), crashed); err != nil {
+		t.Fatal(err)
+	}
+
+	second := newRunner(t, store)
+	second.ResumePrompt = "resume"
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go second.Run(ctx)
+
+	resumed, err := second.Wait(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.State != StateDone || resumed.Attempts != 2 || !strings.Contains(string(resumed.Transcript), "resume|session-hang") {
+		t.Errorf("Expected the task to resume its session, got %+v", resumed)
+	}
+
+	rerun, err := second.Wait(ctx, "crashed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rerun.State != StateDone || rerun.Attempts != 2 || !strings.Contains(string(rerun.Transcript), "again|") {
+		t.Errorf("Expected the crashed task to run again, got %+v", rerun)
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	store := NewMemoryStore()
+	exhausted := &Task{ID: "t1", Prompt: "one", State: StateRunning, Attempts: 2, CreatedAt: time.Now()}
+	if err := store.Put(context.Background(), exhausted); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRunner(t, store)
+	r.MaxAttempts = 2
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go r.Run(ctx)
+
+	task, err := r.Wait(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != StateFailed || task.Error != "interrupted after 2 attempts" {
+		t.Errorf("Expected the task to fail without running, got %+v", task)
+	}
+}
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(filepath.Join(t.TempDir(), "tasks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "file": fileStore} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			tasks := []*Task{
+				{ID: "b", Prompt: "second", State: StateDone, CreatedAt: base.Add(time.Minute)},
+				{ID: "a", Prompt: "first", State: StatePending, CreatedAt: base, Options: types.NewOptions().WithModel("sonnet")},
+				{ID: "c", Prompt: "third", State: StatePending, CreatedAt: base.Add(2 * time.Minute)},
+			}
+			for _, task := range tasks {
+				if err := store.Put(ctx, task); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := store.Get(ctx, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Prompt != "first" || got.Options == nil || got.Options.Model == nil || *got.Options.Model == "" {
+				t.Errorf("Unexpected task: %+v", got)
+			}
+			got.Prompt = "changed"
+			if again, _ := store.Get(ctx, "a"); again.Prompt != "first" {
+				t.Error("Expected the store to return copies")
+			}
+
+			all, err := store.List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 3 || all[0].ID != "a" || all[1].ID != "b" || all[2].ID != "c" {
+				t.Errorf("Expected all tasks oldest first, got %v", all)
+			}
+			pending, err := store.List(ctx, StatePending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != 2 || pending[0].ID != "a" || pending[1].ID != "c" {
+				t.Errorf("Expected the pending tasks, got %v", pending)
+			}
+
+			if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+
+	if err := fileStore.Put(context.Background(), &Task{ID: "../escape"}); err == nil {
+		t.Error("Expected an ID with a slash to be rejected")
+	}
+}
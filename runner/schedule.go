@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Metadata keys set on the tasks of scheduled jobs.
+const (
+	// MetadataJob holds the name of the job that submitted the task.
+	MetadataJob = "job"
+
+	// MetadataScheduledAt holds the time the run was scheduled for, in
+	// RFC 3339 format.
+	MetadataScheduledAt = "scheduled_at"
+)
+
+// ErrOverlap is passed to Runner.OnMissed for a scheduled run skipped
+// because the job's previous run had not finished.
+var ErrOverlap = errors.New("previous run has not finished")
+
+// Job is a recurring task, such as a nightly repository audit.
+type Job struct {
+	// Name identifies the job and its tasks. Scheduling a job with the
+	// name of another replaces it.
+	Name string
+
+	// Schedule is a cron expression, as accepted by ParseSchedule.
+	Schedule string
+
+	// Location is the time zone the schedule is read in. If nil, the
+	// local time zone is used.
+	Location *time.Location
+
+	Prompt   string
+	Options  *types.Options
+	Metadata map[string]string
+
+	// AllowOverlap submits a run even while the job's previous run is
+	// pending or running. By default such runs are skipped.
+	AllowOverlap bool
+}
+
+// scheduledJob is a job with its parsed schedule.
+type scheduledJob struct {
+	Job
+	schedule *Schedule
+}
+
+// Schedule adds a recurring job, run by Run at the times its schedule
+// matches. Runs missed while no Run was active are not made up.
+func (r *Runner) Schedule(job Job) error {
+	if job.Name == "" {
+		return errors.New("job name is required")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Location == nil {
+		job.Location = time.Local
+	}
+	if job.Options != nil {
+		job.Options = job.Options.Clone()
+	}
+
+	r.mu.Lock()
+	if r.jobs == nil {
+		r.jobs = make(map[string]*scheduledJob)
+	}
+	r.jobs[job.Name] = &scheduledJob{Job: job, schedule: schedule}
+	r.mu.Unlock()
+
+	r.reschedule()
+	return nil
+}
+
+// Unschedule removes the job with the given name and reports whether there
+// was one. Its tasks already submitted are not affected.
+func (r *Runner) Unschedule(name string) bool {
+	r.mu.Lock()
+	_, ok := r.jobs[name]
+	delete(r.jobs, name)
+	r.mu.Unlock()
+
+	if ok {
+		r.reschedule()
+	}
+	return ok
+}
+
+// History returns the tasks submitted by the job with the given name,
+// newest first.
+func (r *Runner) History(ctx context.Context, name string) ([]*Task, error) {
+	tasks, err := r.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var history []*Task
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].Metadata[MetadataJob] == name {
+			history = append(history, tasks[i])
+		}
+	}
+	return history, nil
+}
+
+// reschedule tells the scheduler that the jobs changed.
+func (r *Runner) reschedule() {
+	select {
+	case r.jobsChanged <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runner) now() time.Time {
+	if r.clock != nil {
+		return r.clock()
+	}
+	return time.Now()
+}
+
+func (r *Runner) after(d time.Duration) <-chan time.Time {
+	if r.timer != nil {
+		return r.timer(d)
+	}
+	return time.After(d)
+}
+
+// schedule submits the runs of scheduled jobs until ctx is done.
+func (r *Runner) schedule(ctx context.Context) {
+	next := make(map[string]time.Time)
+
+	for {
+		r.mu.Lock()
+		jobs := maps.Clone(r.jobs)
+		r.mu.Unlock()
+
+		now := r.now()
+		for name := range next {
+			if _, ok := jobs[name]; !ok {
+				delete(next, name)
+			}
+		}
+		var earliest time.Time
+		for name, job := range jobs {
+			at, ok := next[name]
+			if !ok {
+				at = job.schedule.Next(now.In(job.Location))
+				next[name] = at
+			}
+			if !at.IsZero() && (earliest.IsZero() || at.Before(earliest)) {
+				earliest = at
+			}
+		}
+
+		var fire <-chan time.Time
+		if !earliest.IsZero() {
+			fire = r.after(earliest.Sub(now))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.jobsChanged:
+			clear(next)
+			continue
+		case <-fire:
+		}
+
+		// Jobs are fired in name order, so that runs due together are
+		// submitted in a stable order
+		now = r.now()
+		names := make([]string, 0, len(jobs))
+		for name := range jobs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			job, at := jobs[name], next[name]
+			if at.IsZero() || at.After(now) {
+				continue
+			}
+			r.fire(ctx, job, at)
+			next[name] = job.schedule.Next(at)
+		}
+	}
+}
+
+// fire submits a run of job scheduled for at, unless it would overlap the
+// job's previous run.
+func (r *Runner) fire(ctx context.Context, job *scheduledJob, at time.Time) {
+	if !job.AllowOverlap {
+		active, err := r.store.List(ctx, StatePending, StateRunning)
+		if err != nil {
+			r.missed(job.Job, at, err)
+			return
+		}
+		for _, task := range active {
+			if task.Metadata[MetadataJob] == job.Name {
+				r.missed(job.Job, at, ErrOverlap)
+				return
+			}
+		}
+	}
+
+	metadata := maps.Clone(job.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata[MetadataJob] = job.Name
+	metadata[MetadataScheduledAt] = at.Format(time.RFC3339)
+
+	if _, err := r.Submit(ctx, job.Prompt, job.Options, metadata); err != nil {
+		r.missed(job.Job, at, err)
+	}
+}
+
+func (r *Runner) missed(job Job, at time.Time, err error) {
+	if r.OnMissed != nil {
+		r.OnMissed(job, at, err)
+	}
+}
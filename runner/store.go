@@ -0,0 +1,213 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by a Store for a task it does not have.
+var ErrNotFound = errors.New("task not found")
+
+// Store persists tasks. Implementations must be safe for concurrent use,
+// and must return tasks the caller may modify.
+type Store interface {
+	// Put creates or replaces the task with task.ID.
+	Put(ctx context.Context, task *Task) error
+
+	// Get returns the task with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Task, error)
+
+	// List returns the tasks in any of the given states, or all tasks if
+	// none are given, oldest first.
+	List(ctx context.Context, states ...State) ([]*Task, error)
+}
+
+// sortTasks orders tasks oldest first.
+func sortTasks(tasks []*Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// MemoryStore keeps tasks in memory, encoded as they would be on disk, so
+// that it behaves like a persistent store. It does not survive a restart,
+// and is meant for tests and short-lived hosts.
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tasks: make(map[string][]byte)}
+}
+
+// Put stores a copy of task.
+func (s *MemoryStore) Put(ctx context.Context, task *Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[task.ID] = data
+	return nil
+}
+
+// Get returns a copy of the task with the given ID.
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Task, error) {
+	s.mu.Lock()
+	data, ok := s.tasks[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return decodeTask(data)
+}
+
+// List returns copies of the tasks in any of the given states, oldest first.
+func (s *MemoryStore) List(ctx context.Context, states ...State) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tasks []*Task
+	for _, data := range s.tasks {
+		task, err := decodeTask(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(states) == 0 || slices.Contains(states, task.State) {
+			tasks = append(tasks, task)
+		}
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+func decodeTask(data []byte) (*Task, error) {
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	return &task, nil
+}
+
+// FileStore keeps each task as a JSON file in a directory. Files are
+// replaced atomically, so a crash leaves either the old or the new state.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a FileStore in dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create task directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the directory holding the tasks.
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// path returns the file of a task, rejecting IDs that are not plain file
+// names.
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid task ID: %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// Put writes task to its file.
+func (s *FileStore) Put(ctx context.Context, task *Task) error {
+	path, err := s.path(task.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".task-*")
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	return nil
+}
+
+// Get reads the task with the given ID.
+func (s *FileStore) Get(ctx context.Context, id string) (*Task, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+	return decodeTask(data)
+}
+
+// List reads the tasks in any of the given states, oldest first.
+func (s *FileStore) List(ctx context.Context, states ...State) ([]*Task, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var tasks []*Task
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		task, err := s.Get(ctx, strings.TrimSuffix(name, ".json"))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(states) == 0 || slices.Contains(states, task.State) {
+			tasks = append(tasks, task)
+		}
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
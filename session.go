@@ -0,0 +1,148 @@
+package claudecode
+
+import (
+	"context"
+	"time"
+
+	client2 "github.com/jrossi/claude-code-sdk-golang/client"
+)
+
+// Session is an interactive, multi-turn conversation with Claude Code.
+// Unlike Query, which starts a new CLI process per prompt, a Session keeps a
+// single process alive and sends each prompt over stdin.
+//
+// Example:
+//
+//	session, err := claudecode.NewSession(ctx, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer session.Close()
+//
+//	if err := session.Send(ctx, "What is 2+2?"); err != nil {
+//		log.Fatal(err)
+//	}
+//	for msg := range session.Receive() {
+//		if _, ok := msg.(*claudecode.ResultMessage); ok {
+//			break // Turn complete, ready for the next prompt
+//		}
+//	}
+type Session struct {
+	internal *client2.Session
+}
+
+// NewSession starts an interactive session with Claude Code.
+// The session must be closed when done to terminate the CLI process.
+func NewSession(ctx context.Context, options *Options) (*Session, error) {
+	return defaultClient.NewSession(ctx, options)
+}
+
+// NewSessionWithCLIPath starts an interactive session using a specific CLI binary path.
+func NewSessionWithCLIPath(ctx context.Context, options *Options, cliPath string) (*Session, error) {
+	return defaultClient.NewSessionWithCLIPath(ctx, options, cliPath)
+}
+
+// Send sends a prompt to Claude as a new conversation turn.
+// Each turn ends with a ResultMessage on the Receive channel.
+func (s *Session) Send(ctx context.Context, prompt string) error {
+	return s.internal.Send(ctx, prompt)
+}
+
+// Init returns the session information from the CLI's init message, such as
+// the available tools, slash commands, output style, and plugins, or nil if
+// it has not arrived yet.
+func (s *Session) Init() *InitInfo {
+	return s.internal.Init()
+}
+
+// LastActivity returns when the CLI last produced output, or when the
+// session was created if it has produced none.
+func (s *Session) LastActivity() time.Time {
+	return s.internal.LastActivity()
+}
+
+// Changes returns the files changed so far by the session's tool uses,
+// across all turns.
+func (s *Session) Changes() []FileChange {
+	return s.internal.Changes()
+}
+
+// SendPrompt sends a multi-part prompt built with NewPrompt, such as text
+// with images, as a new conversation turn.
+func (s *Session) SendPrompt(ctx context.Context, prompt *Prompt) error {
+	return s.internal.SendPrompt(ctx, prompt)
+}
+
+// Compact asks the CLI to compact the conversation now, freeing up the
+// context window. A SystemMessage whose CompactBoundary method reports the
+// compaction arrives on the Receive channel, and the turn ends with a
+// ResultMessage.
+func (s *Session) Compact(ctx context.Context) error {
+	return s.internal.Compact(ctx)
+}
+
+// Receive returns a channel that receives messages for all turns of the session.
+// The channel will be closed when the session ends.
+func (s *Session) Receive() <-chan Message {
+	return s.internal.Receive()
+}
+
+// Errors returns a channel that receives errors during the session.
+// The channel will be closed when the session ends.
+func (s *Session) Errors() <-chan error {
+	return s.internal.Errors()
+}
+
+// Interrupt stops the current turn without ending the session.
+func (s *Session) Interrupt(ctx context.Context) error {
+	return s.internal.Interrupt(ctx)
+}
+
+// SetPermissionMode changes the permission mode for the rest of the
+// session, such as leaving PermissionModePlan once a plan is approved.
+//
+// Example:
+//
+//	if plan, ok := msg.Plan(); ok && approve(plan) {
+//		err := session.SetPermissionMode(ctx, claudecode.PermissionModeAcceptEdits)
+//		...
+//	}
+func (s *Session) SetPermissionMode(ctx context.Context, mode PermissionMode) error {
+	return s.internal.SetPermissionMode(ctx, mode)
+}
+
+// SetModel switches the model for the rest of the session. An empty model
+// restores the CLI's default.
+func (s *Session) SetModel(ctx context.Context, model string) error {
+	return s.internal.SetModel(ctx, model)
+}
+
+// McpServerStatus asks the CLI for the current status of each configured
+// MCP server. The status at startup is in Init().McpServers, and
+// InitInfo.FailedMcpServers lists the servers whose tools are missing.
+//
+// Example:
+//
+//	servers, err := session.McpServerStatus(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	for _, server := range servers {
+//		if !server.Connected() {
+//			log.Printf("MCP server %s is %s", server.Name, server.Status)
+//		}
+//	}
+func (s *Session) McpServerStatus(ctx context.Context) ([]McpServerStatus, error) {
+	return s.internal.McpServerStatus(ctx)
+}
+
+// Close ends the session and terminates the CLI process.
+// It's safe to call Close multiple times.
+func (s *Session) Close() error {
+	return s.internal.Close()
+}
+
+// IsClosed returns true if the session has been closed.
+func (s *Session) IsClosed() bool {
+	return s.internal.IsClosed()
+}
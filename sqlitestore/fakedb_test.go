@@ -0,0 +1,254 @@
+package sqlitestore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql driver that runs the store's statements on
+// in-memory tables, standing in for SQLite, which needs a driver outside
+// the standard library.
+type fakeDB struct {
+	mu       sync.Mutex
+	created  int
+	sessions map[string][]driver.Value
+	messages [][]driver.Value
+	tools    [][]driver.Value
+	results  [][]driver.Value
+	tasks    map[string][]driver.Value
+
+	// fail, if set, makes statements containing it fail.
+	fail string
+}
+
+var (
+	fakeMu      sync.Mutex
+	fakeDrivers = map[string]*fakeDB{}
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeMu.Lock()
+	defer fakeMu.Unlock()
+	return &fakeConn{fakeDrivers[name]}, nil
+}
+
+func init() {
+	sql.Register("sqlitestore_fake", fakeDriver{})
+}
+
+// openFake returns a database with empty tables.
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	f := &fakeDB{sessions: map[string][]driver.Value{}, tasks: map[string][]driver.Value{}}
+	fakeMu.Lock()
+	fakeDrivers[t.Name()] = f
+	fakeMu.Unlock()
+
+	db, err := sql.Open("sqlitestore_fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, f
+}
+
+type fakeConn struct{ f *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.f, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	f     *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	f := s.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != "" && strings.Contains(s.query, f.fail) {
+		return nil, errors.New("disk I/O error")
+	}
+
+	switch s.query {
+	case upsertSession:
+		if row, ok := f.sessions[args[0].(string)]; ok {
+			row[2] = args[2]
+			for i := 3; i <= 4; i++ {
+				if args[i] != "" {
+					row[i] = args[i]
+				}
+			}
+		} else {
+			f.sessions[args[0].(string)] = append(slices.Clone(args), int64(0), 0.0, false)
+		}
+	case insertMessage:
+		f.messages = append(f.messages, args)
+	case insertToolUse:
+		for _, row := range f.tools {
+			if row[0] == args[0] {
+				return driver.RowsAffected(0), nil
+			}
+		}
+		f.tools = append(f.tools, append(slices.Clone(args), nil, nil))
+	case finishToolUse:
+		for _, row := range f.tools {
+			if row[0] == args[2] && row[5] == nil {
+				row[5], row[6] = args[0], args[1]
+			}
+		}
+	case insertResult:
+		f.results = append(f.results, args)
+	case addResult:
+		if row, ok := f.sessions[args[4].(string)]; ok {
+			row[2] = args[0]
+			row[5] = row[5].(int64) + args[1].(int64)
+			row[6] = row[6].(float64) + args[2].(float64)
+			row[7] = args[3]
+		}
+	case upsertTask:
+		if row, ok := f.tasks[args[0].(string)]; ok {
+			row[1], row[3] = args[1], args[3]
+		} else {
+			f.tasks[args[0].(string)] = args
+		}
+	default:
+		if slices.Contains(schema, s.query) {
+			f.created++
+			break
+		}
+		return nil, fmt.Errorf("unexpected statement: %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	f := s.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != "" && strings.Contains(s.query, f.fail) {
+		return nil, errors.New("disk I/O error")
+	}
+
+	var rows [][]driver.Value
+	switch {
+	case s.query == selectSessions:
+		for _, row := range f.sessions {
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i][2] != rows[j][2] {
+				return rows[i][2].(int64) > rows[j][2].(int64)
+			}
+			return rows[i][0].(string) < rows[j][0].(string)
+		})
+		if limit := int(args[0].(int64)); limit >= 0 && limit < len(rows) {
+			rows = rows[:limit]
+		}
+	case s.query == selectSession:
+		if row, ok := f.sessions[args[0].(string)]; ok {
+			rows = append(rows, row)
+		}
+	case s.query == selectMessages:
+		for _, row := range f.messages {
+			if row[0] == args[0] {
+				rows = append(rows, []driver.Value{row[1], row[3]})
+			}
+		}
+	case s.query == selectToolUses:
+		for _, row := range f.tools {
+			if row[1] == args[0] {
+				rows = append(rows, row)
+			}
+		}
+	case s.query == selectCostByDay:
+		totals := map[string][]driver.Value{}
+		for _, row := range f.results {
+			day := row[2].(string)
+			if day < args[0].(string) || day >= args[1].(string) {
+				continue
+			}
+			total, ok := totals[day]
+			if !ok {
+				total = []driver.Value{day, 0.0, int64(0), int64(0)}
+				totals[day] = total
+			}
+			total[1] = total[1].(float64) + row[7].(float64)
+			total[2] = total[2].(int64) + 1
+			total[3] = total[3].(int64) + row[5].(int64)
+		}
+		for _, total := range totals {
+			rows = append(rows, total)
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0].(string) < rows[j][0].(string) })
+	case s.query == selectTask:
+		if row, ok := f.tasks[args[0].(string)]; ok {
+			rows = append(rows, []driver.Value{row[3]})
+		}
+	case strings.HasPrefix(s.query, selectTasks) && strings.HasSuffix(s.query, orderTasks):
+		var tasks [][]driver.Value
+		for _, row := range f.tasks {
+			if len(args) == 0 || slices.Contains(args, row[1]) {
+				tasks = append(tasks, row)
+			}
+		}
+		sort.Slice(tasks, func(i, j int) bool {
+			if tasks[i][2] != tasks[j][2] {
+				return tasks[i][2].(int64) < tasks[j][2].(int64)
+			}
+			return tasks[i][0].(string) < tasks[j][0].(string)
+		})
+		for _, row := range tasks {
+			rows = append(rows, []driver.Value{row[3]})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", s.query)
+	}
+
+	copied := make([][]driver.Value, len(rows))
+	for i, row := range rows {
+		copied[i] = slices.Clone(row)
+	}
+	return &fakeRows{rows: copied}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,244 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Recorder records the messages of one query or session in a Store: each
+// message, the tool calls made and their outcomes, and each result with
+// its cost. It is safe for concurrent use.
+//
+// The CLI reports a stream's session ID in its first message, so the
+// messages before it, such as the prompt, are held until it arrives.
+// Heartbeats and tool progress reports are transient and are not
+// recorded.
+type Recorder struct {
+	store *Store
+
+	// OnError, if set, is called with each error returned by the database.
+	// Errors are also kept for Err and Close.
+	OnError func(err error)
+
+	mu        sync.Mutex
+	sessionID string
+	model     string
+	cwd       string
+	pending   []transcript.Entry
+	err       error
+}
+
+// NewRecorder returns a Recorder for a stream. If prompt is not empty, it
+// is recorded as the first user message; sessions record each prompt with
+// AddPrompt instead.
+func (s *Store) NewRecorder(prompt string) *Recorder {
+	r := &Recorder{store: s}
+	if prompt != "" {
+		r.AddPrompt(prompt)
+	}
+	return r
+}
+
+// AddPrompt records a prompt sent to Claude. The CLI does not echo
+// prompts, so they must be added for the stored transcript to show them.
+func (r *Recorder) AddPrompt(prompt string) {
+	r.Observe(context.Background(), &types.UserMessage{Content: prompt})
+}
+
+// SessionID returns the session ID of the stream, once reported.
+func (r *Recorder) SessionID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessionID
+}
+
+// Observe records msg, with any messages held until the session ID was
+// known.
+func (r *Recorder) Observe(ctx context.Context, msg types.Message) error {
+	err := r.observe(ctx, msg)
+	if err != nil {
+		r.fail(err)
+	}
+	return err
+}
+
+func (r *Recorder) observe(ctx context.Context, msg types.Message) error {
+	switch m := msg.(type) {
+	case nil, *types.ProgressEvent:
+		return nil
+	case *types.SystemMessage:
+		if m.Subtype == types.SystemSubtypeHeartbeat {
+			return nil
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch m := msg.(type) {
+	case *types.SystemMessage:
+		if info, ok := m.Init(); ok && info.SessionID != "" {
+			r.sessionID, r.model, r.cwd = info.SessionID, info.Model, info.Cwd
+		}
+	case *types.ResultMessage:
+		if r.sessionID == "" {
+			r.sessionID = m.SessionID
+		}
+	}
+
+	r.pending = append(r.pending, transcript.Entry{Time: time.Now(), Message: msg})
+	if r.sessionID == "" {
+		return nil
+	}
+	entries := r.pending
+	r.pending = nil
+	return r.store.record(ctx, r.sessionID, r.model, r.cwd, entries)
+}
+
+// Tee records every message received from messages and forwards it on the
+// returned channel, which is closed once messages is. The returned channel
+// must be drained.
+func (r *Recorder) Tee(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			r.Observe(context.Background(), msg)
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// fail keeps err and passes it to OnError.
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	r.err = errors.Join(r.err, err)
+	r.mu.Unlock()
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Err returns the errors the database has returned so far, joined.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close drops the messages still held because the stream ended without
+// reporting its session ID, reporting them as an error, and returns any
+// error the database returned.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	dropped := len(r.pending)
+	r.pending = nil
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		r.fail(errors.New("the stream ended without reporting its session ID, so its messages were not recorded"))
+	}
+	return r.Err()
+}
+
+// record stores the entries of a session in one transaction.
+func (s *Store) record(ctx context.Context, sessionID, model, cwd string, entries []transcript.Entry) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record messages: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	first, last := entries[0].Time, entries[len(entries)-1].Time
+	if _, err := tx.ExecContext(ctx, upsertSession, sessionID, first.UnixNano(), last.UnixNano(), model, cwd); err != nil {
+		return fmt.Errorf("failed to record session %s: %w", sessionID, err)
+	}
+	for _, entry := range entries {
+		if err := recordMessage(ctx, tx, sessionID, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record messages: %w", err)
+	}
+	return nil
+}
+
+// recordMessage stores a message with the tool calls, tool results, or
+// result it holds.
+func recordMessage(ctx context.Context, tx *sql.Tx, sessionID string, entry transcript.Entry) error {
+	data, err := json.Marshal(entry.Message)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", entry.Message.Type(), err)
+	}
+	at := entry.Time.UnixNano()
+	if _, err := tx.ExecContext(ctx, insertMessage, sessionID, at, entry.Message.Type(), string(data)); err != nil {
+		return fmt.Errorf("failed to record %s message: %w", entry.Message.Type(), err)
+	}
+
+	switch m := entry.Message.(type) {
+	case *types.AssistantMessage:
+		for _, block := range m.Content {
+			use, ok := block.(*types.ToolUseBlock)
+			if !ok {
+				continue
+			}
+			var input any
+			if use.Input != nil {
+				data, err := json.Marshal(use.Input)
+				if err != nil {
+					return fmt.Errorf("failed to encode input of tool call %s: %w", use.ID, err)
+				}
+				input = string(data)
+			}
+			if _, err := tx.ExecContext(ctx, insertToolUse, use.ID, sessionID, use.Name, input, at); err != nil {
+				return fmt.Errorf("failed to record tool call %s: %w", use.ID, err)
+			}
+		}
+
+	case *types.UserMessage:
+		for _, block := range m.Blocks {
+			result, ok := block.(*types.ToolResultBlock)
+			if !ok {
+				continue
+			}
+			isError := result.IsError != nil && *result.IsError
+			if _, err := tx.ExecContext(ctx, finishToolUse, at, isError, result.ToolUseID); err != nil {
+				return fmt.Errorf("failed to record result of tool call %s: %w", result.ToolUseID, err)
+			}
+		}
+
+	case *types.ResultMessage:
+		var cost float64
+		if m.TotalCostUSD != nil {
+			cost = *m.TotalCostUSD
+		}
+		var text any
+		if m.Result != nil {
+			text = *m.Result
+		}
+		day := entry.Time.UTC().Format(dayFormat)
+		if _, err := tx.ExecContext(ctx, insertResult, sessionID, at, day, m.Subtype, m.IsError, m.NumTurns, m.DurationMs, cost, text); err != nil {
+			return fmt.Errorf("failed to record result: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, addResult, at, m.NumTurns, cost, m.IsError, sessionID); err != nil {
+			return fmt.Errorf("failed to record result: %w", err)
+		}
+	}
+	return nil
+}
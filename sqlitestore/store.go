@@ -0,0 +1,342 @@
+// Package sqlitestore persists sessions, their messages, tool calls, and
+// results in a SQLite database, with queries for listing sessions,
+// fetching a session's transcript, and totaling cost by day. It also
+// stores runner tasks, so that one database holds a host's agent work.
+//
+// The caller registers a SQLite driver, such as modernc.org/sqlite or
+// github.com/mattn/go-sqlite3, and owns db:
+//
+//	db, err := sql.Open("sqlite", "agent.db")
+//	store := sqlitestore.New(db)
+//	err = store.CreateTables(ctx)
+//
+//	rec := store.NewRecorder(prompt)
+//	stream, err := client.Query(ctx, prompt, options)
+//	for msg := range rec.Tee(stream.Messages()) {
+//		// ...
+//	}
+//	err = rec.Close()
+//
+//	r := runner.New(store.Tasks(), client)
+//
+// Times are stored as Unix nanoseconds, and days are counted in UTC.
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/transcript"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// ErrNotFound is returned for a session the store does not have.
+var ErrNotFound = errors.New("session not found")
+
+// schema creates the store's tables and indexes.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS sessions (
+		session_id TEXT PRIMARY KEY,
+		started_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		model TEXT NOT NULL DEFAULT '',
+		cwd TEXT NOT NULL DEFAULT '',
+		num_turns INTEGER NOT NULL DEFAULT 0,
+		cost_usd REAL NOT NULL DEFAULT 0,
+		is_error BOOLEAN NOT NULL DEFAULT FALSE)`,
+	`CREATE TABLE IF NOT EXISTS messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		data TEXT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS messages_session ON messages (session_id, id)`,
+	`CREATE TABLE IF NOT EXISTS tool_calls (
+		tool_use_id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		tool TEXT NOT NULL,
+		input TEXT,
+		started_at INTEGER NOT NULL,
+		finished_at INTEGER,
+		is_error BOOLEAN)`,
+	`CREATE INDEX IF NOT EXISTS tool_calls_session ON tool_calls (session_id, started_at)`,
+	`CREATE TABLE IF NOT EXISTS results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		day TEXT NOT NULL,
+		subtype TEXT NOT NULL,
+		is_error BOOLEAN NOT NULL,
+		num_turns INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		cost_usd REAL NOT NULL,
+		result TEXT)`,
+	`CREATE INDEX IF NOT EXISTS results_day ON results (day)`,
+	`CREATE TABLE IF NOT EXISTS tasks (
+		id TEXT PRIMARY KEY,
+		state TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		data TEXT NOT NULL)`,
+}
+
+const (
+	upsertSession = `INSERT INTO sessions (session_id, started_at, updated_at, model, cwd) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (session_id) DO UPDATE SET updated_at = excluded.updated_at,
+		model = CASE WHEN excluded.model = '' THEN sessions.model ELSE excluded.model END,
+		cwd = CASE WHEN excluded.cwd = '' THEN sessions.cwd ELSE excluded.cwd END`
+	insertMessage = `INSERT INTO messages (session_id, time, type, data) VALUES (?, ?, ?, ?)`
+	insertToolUse = `INSERT INTO tool_calls (tool_use_id, session_id, tool, input, started_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (tool_use_id) DO NOTHING`
+	finishToolUse = `UPDATE tool_calls SET finished_at = ?, is_error = ? WHERE tool_use_id = ? AND finished_at IS NULL`
+	insertResult  = `INSERT INTO results (session_id, time, day, subtype, is_error, num_turns, duration_ms, cost_usd, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	addResult = `UPDATE sessions SET updated_at = ?, num_turns = num_turns + ?, cost_usd = cost_usd + ?, is_error = ?
+		WHERE session_id = ?`
+
+	sessionColumns = `session_id, started_at, updated_at, model, cwd, num_turns, cost_usd, is_error`
+	selectSessions = `SELECT ` + sessionColumns + ` FROM sessions ORDER BY updated_at DESC, session_id LIMIT ?`
+	selectSession  = `SELECT ` + sessionColumns + ` FROM sessions WHERE session_id = ?`
+	selectMessages = `SELECT time, data FROM messages WHERE session_id = ? ORDER BY id`
+	selectToolUses = `SELECT tool_use_id, session_id, tool, input, started_at, finished_at, is_error FROM tool_calls
+		WHERE session_id = ? ORDER BY started_at, tool_use_id`
+	selectCostByDay = `SELECT day, SUM(cost_usd), COUNT(*), SUM(num_turns) FROM results
+		WHERE day >= ? AND day < ? GROUP BY day ORDER BY day`
+)
+
+// dayFormat is the format of the day column.
+const dayFormat = time.DateOnly
+
+// Store persists sessions and tasks in a SQLite database. It is safe for
+// concurrent use.
+type Store struct {
+	db *sql.DB
+}
+
+// New returns a store using db, which must be a SQLite database.
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// DB returns the store's database.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// CreateTables creates the store's tables and indexes if they do not
+// exist.
+func (s *Store) CreateTables(ctx context.Context) error {
+	for _, stmt := range schema {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// SessionInfo summarizes a stored session.
+type SessionInfo struct {
+	ID        string    `json:"session_id"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Model     string    `json:"model,omitempty"`
+	Cwd       string    `json:"cwd,omitempty"`
+
+	// NumTurns and CostUSD total the results of the session's queries,
+	// including those that resumed it.
+	NumTurns int     `json:"num_turns"`
+	CostUSD  float64 `json:"cost_usd"`
+
+	// IsError reports whether the session's last result was an error.
+	IsError bool `json:"is_error"`
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row scanner) (*SessionInfo, error) {
+	var info SessionInfo
+	var startedAt, updatedAt int64
+	if err := row.Scan(&info.ID, &startedAt, &updatedAt, &info.Model, &info.Cwd, &info.NumTurns, &info.CostUSD, &info.IsError); err != nil {
+		return nil, err
+	}
+	info.StartedAt = time.Unix(0, startedAt)
+	info.UpdatedAt = time.Unix(0, updatedAt)
+	return &info, nil
+}
+
+// ListSessions returns up to limit sessions, most recently updated first.
+// If limit is zero or less, all sessions are returned.
+func (s *Store) ListSessions(ctx context.Context, limit int) ([]SessionInfo, error) {
+	if limit <= 0 {
+		// SQLite treats a negative limit as none
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, selectSessions, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []SessionInfo
+	for rows.Next() {
+		info, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		sessions = append(sessions, *info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// GetSession returns the session with the given ID, or ErrNotFound.
+func (s *Store) GetSession(ctx context.Context, id string) (*SessionInfo, error) {
+	info, err := scanSession(s.db.QueryRowContext(ctx, selectSession, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session %s: %w", id, err)
+	}
+	return info, nil
+}
+
+// Transcript returns the messages recorded for a session, in the order
+// they were recorded, or ErrNotFound.
+func (s *Store) Transcript(ctx context.Context, sessionID string) (*transcript.Transcript, error) {
+	info, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectMessages, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript of %s: %w", sessionID, err)
+	}
+	defer rows.Close()
+
+	var entries []transcript.Entry
+	for rows.Next() {
+		var at int64
+		var data string
+		if err := rows.Scan(&at, &data); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		msg, err := types.UnmarshalMessage([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, transcript.Entry{Time: time.Unix(0, at), Message: msg})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript of %s: %w", sessionID, err)
+	}
+	return transcript.Restore(info.StartedAt, entries), nil
+}
+
+// ToolCall is a tool call recorded for a session.
+type ToolCall struct {
+	ToolUseID string         `json:"tool_use_id"`
+	SessionID string         `json:"session_id"`
+	Tool      string         `json:"tool"`
+	Input     map[string]any `json:"input,omitempty"`
+	StartedAt time.Time      `json:"started_at"`
+
+	// Completed reports whether the call's result was recorded; FinishedAt
+	// and IsError are only set if it was.
+	Completed  bool      `json:"completed"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+	IsError    bool      `json:"is_error,omitempty"`
+}
+
+// ToolCalls returns the tool calls recorded for a session, in the order
+// they were made.
+func (s *Store) ToolCalls(ctx context.Context, sessionID string) ([]ToolCall, error) {
+	rows, err := s.db.QueryContext(ctx, selectToolUses, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tool calls of %s: %w", sessionID, err)
+	}
+	defer rows.Close()
+
+	var calls []ToolCall
+	for rows.Next() {
+		var call ToolCall
+		var input sql.NullString
+		var startedAt int64
+		var finishedAt sql.NullInt64
+		var isError sql.NullBool
+		if err := rows.Scan(&call.ToolUseID, &call.SessionID, &call.Tool, &input, &startedAt, &finishedAt, &isError); err != nil {
+			return nil, fmt.Errorf("failed to read tool call: %w", err)
+		}
+		if input.Valid {
+			if err := json.Unmarshal([]byte(input.String), &call.Input); err != nil {
+				return nil, fmt.Errorf("failed to decode input of tool call %s: %w", call.ToolUseID, err)
+			}
+		}
+		call.StartedAt = time.Unix(0, startedAt)
+		if finishedAt.Valid {
+			call.Completed = true
+			call.FinishedAt = time.Unix(0, finishedAt.Int64)
+			call.IsError = isError.Bool
+		}
+		calls = append(calls, call)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch tool calls of %s: %w", sessionID, err)
+	}
+	return calls, nil
+}
+
+// DailyCost totals the results recorded on one day.
+type DailyCost struct {
+	// Day is midnight UTC of the day.
+	Day      time.Time `json:"day"`
+	CostUSD  float64   `json:"cost_usd"`
+	Results  int       `json:"results"`
+	NumTurns int       `json:"num_turns"`
+}
+
+// CostByDay totals the cost of the recorded results by UTC day, in order,
+// for the days from the one since falls in through the one until falls
+// in. A zero since or until leaves that end open. Days without results
+// are omitted.
+func (s *Store) CostByDay(ctx context.Context, since, until time.Time) ([]DailyCost, error) {
+	from, to := "", "9999-12-31"
+	if !since.IsZero() {
+		from = since.UTC().Format(dayFormat)
+	}
+	if !until.IsZero() {
+		to = until.UTC().AddDate(0, 0, 1).Format(dayFormat)
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectCostByDay, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total cost by day: %w", err)
+	}
+	defer rows.Close()
+
+	var days []DailyCost
+	for rows.Next() {
+		var day string
+		var cost DailyCost
+		if err := rows.Scan(&day, &cost.CostUSD, &cost.Results, &cost.NumTurns); err != nil {
+			return nil, fmt.Errorf("failed to read daily cost: %w", err)
+		}
+		if cost.Day, err = time.Parse(dayFormat, day); err != nil {
+			return nil, fmt.Errorf("invalid day %q: %w", day, err)
+		}
+		days = append(days, cost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to total cost by day: %w", err)
+	}
+	return days, nil
+}
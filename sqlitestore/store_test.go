@@ -0,0 +1,264 @@
+package sqlitestore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/runner"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func messages(msgs ...types.Message) <-chan types.Message {
+	ch := make(chan types.Message, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	return ch
+}
+
+func initMessage(sessionID, model string) *types.SystemMessage {
+	return &types.SystemMessage{Subtype: "init", Data: map[string]any{
+		"type": "system", "subtype": "init", "session_id": sessionID, "model": model, "cwd": "/repo",
+	}}
+}
+
+func result(sessionID string, turns int, cost float64, isError bool) *types.ResultMessage {
+	subtype := "success"
+	if isError {
+		subtype = "error_max_turns"
+	}
+	return &types.ResultMessage{Subtype: subtype, SessionID: sessionID, NumTurns: turns, TotalCostUSD: &cost, IsError: isError}
+}
+
+func record(t *testing.T, store *Store, prompt string, msgs ...types.Message) *Recorder {
+	t.Helper()
+	rec := store.NewRecorder(prompt)
+	for range rec.Tee(messages(msgs...)) {
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestRecorder(t *testing.T) {
+	db, f := openFake(t)
+	store := New(db)
+	if err := store.CreateTables(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if f.created != len(schema) {
+		t.Errorf("Expected %d schema statements, got %d", len(schema), f.created)
+	}
+
+	isError := true
+	rec := record(t, store, "List the files",
+		initMessage("s1", "claude-sonnet-4"),
+		&types.SystemMessage{Subtype: types.SystemSubtypeHeartbeat, Data: map[string]any{}},
+		&types.AssistantMessage{Content: []types.ContentBlock{
+			&types.TextBlock{Text: "Listing"},
+			&types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]any{"command": "ls"}},
+			&types.ToolUseBlock{ID: "t2", Name: "Read"},
+		}},
+		&types.ProgressEvent{ToolUseID: "t1", Elapsed: time.Second},
+		&types.UserMessage{Blocks: []types.ContentBlock{&types.ToolResultBlock{ToolUseID: "t1", IsError: &isError}}},
+		result("s1", 2, 0.25, false),
+	)
+	if rec.SessionID() != "s1" {
+		t.Errorf("Expected session s1, got %q", rec.SessionID())
+	}
+
+	// Resuming the session adds to it
+	record(t, store, "Continue", initMessage("s1", ""), result("s1", 1, 0.5, true))
+
+	ctx := context.Background()
+	info, err := store.GetSession(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Model != "claude-sonnet-4" || info.Cwd != "/repo" || info.NumTurns != 3 || info.CostUSD != 0.75 || !info.IsError {
+		t.Errorf("Unexpected session: %+v", info)
+	}
+	if !info.UpdatedAt.After(info.StartedAt) {
+		t.Errorf("Expected the session to be updated after it started: %+v", info)
+	}
+
+	tr, err := store.Transcript(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, msg := range tr.Messages() {
+		kinds = append(kinds, msg.Type())
+	}
+	expected := []string{"user", "system", "assistant", "user", "result", "user", "system", "result"}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Expected messages %v, got %v", expected, kinds)
+	}
+	if prompt, ok := tr.Messages()[0].(*types.UserMessage); !ok || prompt.Content != "List the files" {
+		t.Errorf("Expected the prompt first, got %#v", tr.Messages()[0])
+	}
+	if use := tr.Messages()[2].(*types.AssistantMessage).Content[1].(*types.ToolUseBlock); use.Input["command"] != "ls" {
+		t.Errorf("Expected the tool call to be restored, got %#v", use)
+	}
+	if !tr.StartedAt().Equal(info.StartedAt) {
+		t.Errorf("Expected the transcript to start with the session, got %v", tr.StartedAt())
+	}
+
+	calls, err := store.ToolCalls(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 tool calls, got %+v", calls)
+	}
+	if calls[0].Tool != "Bash" || !calls[0].Completed || !calls[0].IsError || calls[0].Input["command"] != "ls" || calls[0].FinishedAt.IsZero() {
+		t.Errorf("Unexpected first tool call: %+v", calls[0])
+	}
+	if calls[1].Tool != "Read" || calls[1].Completed || calls[1].Input != nil {
+		t.Errorf("Unexpected second tool call: %+v", calls[1])
+	}
+
+	if _, err := store.Transcript(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	db, _ := openFake(t)
+	store := New(db)
+	for _, id := range []string{"s1", "s2", "s3"} {
+		record(t, store, "", initMessage(id, "claude-sonnet-4"), result(id, 1, 0.1, false))
+	}
+
+	sessions, err := store.ListSessions(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"s3", "s2", "s1"}) {
+		t.Errorf("Expected the newest session first, got %v", ids)
+	}
+
+	sessions, err = store.ListSessions(context.Background(), 2)
+	if err != nil || len(sessions) != 2 {
+		t.Errorf("Expected 2 sessions, got %d (%v)", len(sessions), err)
+	}
+}
+
+func TestCostByDay(t *testing.T) {
+	db, f := openFake(t)
+	store := New(db)
+	record(t, store, "", initMessage("s1", ""), result("s1", 2, 0.25, false), result("s1", 1, 0.5, false))
+	record(t, store, "", initMessage("s2", ""), result("s2", 3, 1, false))
+
+	// Move the second session's result to the day before
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+	f.results[2][2] = yesterday.Format(dayFormat)
+
+	ctx := context.Background()
+	days, err := store.CostByDay(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DailyCost{
+		{Day: yesterday.Truncate(24 * time.Hour), CostUSD: 1, Results: 1, NumTurns: 3},
+		{Day: today.Truncate(24 * time.Hour), CostUSD: 0.75, Results: 2, NumTurns: 3},
+	}
+	if !reflect.DeepEqual(days, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, days)
+	}
+
+	days, err = store.CostByDay(ctx, today, today)
+	if err != nil || len(days) != 1 || days[0].CostUSD != 0.75 {
+		t.Errorf("Expected only today's cost, got %+v (%v)", days, err)
+	}
+	days, err = store.CostByDay(ctx, time.Time{}, yesterday)
+	if err != nil || len(days) != 1 || days[0].CostUSD != 1 {
+		t.Errorf("Expected only yesterday's cost, got %+v (%v)", days, err)
+	}
+}
+
+func TestRecorderErrors(t *testing.T) {
+	db, f := openFake(t)
+	store := New(db)
+
+	rec := store.NewRecorder("Hello")
+	if err := rec.Close(); err == nil || !strings.Contains(err.Error(), "messages were not recorded") {
+		t.Errorf("Expected the held prompt to be reported, got %v", err)
+	}
+
+	f.fail = "INSERT INTO results"
+	rec = store.NewRecorder("Hello")
+	var reported []error
+	rec.OnError = func(err error) { reported = append(reported, err) }
+	if err := rec.Observe(context.Background(), initMessage("s1", "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Observe(context.Background(), result("s1", 1, 0.1, false)); err == nil || !strings.Contains(err.Error(), "failed to record result") {
+		t.Errorf("Expected the insert to fail, got %v", err)
+	}
+	if len(reported) != 1 || rec.Close() == nil {
+		t.Errorf("Expected the error to be reported and kept, got %v", reported)
+	}
+}
+
+func TestTaskStore(t *testing.T) {
+	db, _ := openFake(t)
+	tasks := New(db).Tasks()
+	ctx := context.Background()
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, state := range []runner.State{runner.StatePending, runner.StateDone, runner.StatePending} {
+		task := &runner.Task{
+			ID:        string(rune('c' - i)),
+			Prompt:    "task",
+			State:     state,
+			Metadata:  map[string]string{"n": string(rune('0' + i))},
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := tasks.Put(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task, err := tasks.Get(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != runner.StateDone || task.Metadata["n"] != "1" || !task.CreatedAt.Equal(base.Add(time.Minute)) {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+	task.State = runner.StateFailed
+	if err := tasks.Put(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := tasks.List(ctx, runner.StatePending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].ID != "c" || pending[1].ID != "a" {
+		t.Errorf("Expected the pending tasks oldest first, got %+v", pending)
+	}
+	all, err := tasks.List(ctx)
+	if err != nil || len(all) != 3 || all[1].State != runner.StateFailed {
+		t.Errorf("Expected all tasks with the update, got %+v (%v)", all, err)
+	}
+
+	if _, err := tasks.Get(ctx, "missing"); !errors.Is(err, runner.ErrNotFound) {
+		t.Errorf("Expected runner.ErrNotFound, got %v", err)
+	}
+	if err := tasks.Put(ctx, &runner.Task{}); err == nil {
+		t.Error("Expected a task without an ID to be rejected")
+	}
+}
@@ -0,0 +1,106 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jrossi/claude-code-sdk-golang/runner"
+)
+
+const (
+	upsertTask = `INSERT INTO tasks (id, state, created_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET state = excluded.state, data = excluded.data`
+	selectTask  = `SELECT data FROM tasks WHERE id = ?`
+	selectTasks = `SELECT data FROM tasks`
+	orderTasks  = ` ORDER BY created_at, id`
+)
+
+// TaskStore keeps runner tasks in the store's tasks table, as JSON with
+// their state and creation time in columns of their own.
+type TaskStore struct {
+	db *sql.DB
+}
+
+var _ runner.Store = (*TaskStore)(nil)
+
+// Tasks returns a runner.Store keeping tasks in the store's database.
+func (s *Store) Tasks() *TaskStore {
+	return &TaskStore{db: s.db}
+}
+
+// Put creates or replaces the task with task.ID.
+func (s *TaskStore) Put(ctx context.Context, task *runner.Task) error {
+	if task.ID == "" {
+		return fmt.Errorf("invalid task ID: %q", task.ID)
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, upsertTask, task.ID, string(task.State), task.CreatedAt.UnixNano(), string(data)); err != nil {
+		return fmt.Errorf("failed to store task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Get returns the task with the given ID, or runner.ErrNotFound.
+func (s *TaskStore) Get(ctx context.Context, id string) (*runner.Task, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, selectTask, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, runner.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task %s: %w", id, err)
+	}
+	return decodeTask(data)
+}
+
+// List returns the tasks in any of the given states, or all tasks if none
+// are given, oldest first.
+func (s *TaskStore) List(ctx context.Context, states ...runner.State) ([]*runner.Task, error) {
+	query := selectTasks
+	args := make([]any, len(states))
+	if len(states) > 0 {
+		placeholders := strings.Repeat(", ?", len(states))[2:]
+		query += " WHERE state IN (" + placeholders + ")"
+		for i, state := range states {
+			args[i] = string(state)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+orderTasks, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*runner.Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+		task, err := decodeTask(data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func decodeTask(data string) (*runner.Task, error) {
+	var task runner.Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	return &task, nil
+}
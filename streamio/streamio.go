@@ -0,0 +1,262 @@
+// Package streamio writes the messages of a query or session as
+// newline-delimited JSON in the CLI's own stream-json format, so a run can
+// be saved as it happens and replayed later through the normal parser.
+//
+// An NDJSONWriter writes each message as it is read from the stream:
+//
+//	w := streamio.NewNDJSONWriter(file)
+//	for msg := range w.Tee(stream.Messages()) {
+//		// ... handle msg as usual ...
+//	}
+//	err := w.Err()
+//
+// Each line is an object whose "type" field gives the message's type, as
+// in the CLI's output, so the file can be fed back through a transport
+// such as claudecodetest.Transport. Unlike the record package, which
+// captures the CLI's raw lines through Options.RawMessageHandler, the
+// writer works from parsed messages, so it can be attached to any message
+// channel and writes only what the SDK understood.
+package streamio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// NDJSONWriter writes messages to an io.Writer, one JSON object per line.
+// It is safe for concurrent use.
+//
+// Heartbeat messages are generated by the SDK rather than the CLI and are
+// not written.
+type NDJSONWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewNDJSONWriter creates a writer to w. Each message is written with a
+// single Write call, so w need not be buffered.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w}
+}
+
+// Write writes msg as one line. Once a write has failed, every later
+// write returns the same error.
+func (nw *NDJSONWriter) Write(msg types.Message) error {
+	if m, ok := msg.(*types.SystemMessage); ok && m.Subtype == types.SystemSubtypeHeartbeat {
+		return nil
+	}
+
+	line, err := Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	if nw.err != nil {
+		return nw.err
+	}
+	if _, err := nw.w.Write(append(line, '\n')); err != nil {
+		nw.err = fmt.Errorf("failed to write message: %w", err)
+	}
+	return nw.err
+}
+
+// Tee writes every message received from messages and forwards it on the
+// returned channel, which is closed once messages is. The returned channel
+// must be drained. Write errors do not stop forwarding; check Err once the
+// stream ends.
+func (nw *NDJSONWriter) Tee(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			nw.Write(msg)
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// Err returns the error that stopped writing, or nil.
+func (nw *NDJSONWriter) Err() error {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	return nw.err
+}
+
+// Marshal encodes msg as a line of the CLI's stream-json output, without
+// the trailing newline. Parsing the line gives back an equal message,
+// except that a user message's Content summary is regenerated from its
+// Blocks.
+func Marshal(msg types.Message) ([]byte, error) {
+	object, err := messageObject(msg)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := object.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(object)
+}
+
+// messageObject returns the JSON value the CLI would have sent for msg.
+func messageObject(msg types.Message) (any, error) {
+	switch m := msg.(type) {
+	case *types.UserMessage:
+		var content any = m.Content
+		if len(m.Blocks) > 0 {
+			blocks, err := blockObjects(m.Blocks)
+			if err != nil {
+				return nil, err
+			}
+			content = blocks
+		}
+		return map[string]any{
+			"type":    "user",
+			"message": map[string]any{"role": "user", "content": content},
+		}, nil
+
+	case *types.AssistantMessage:
+		blocks, err := blockObjects(m.Content)
+		if err != nil {
+			return nil, err
+		}
+		message := map[string]any{"role": "assistant", "content": blocks}
+		if m.ID != "" {
+			message["id"] = m.ID
+		}
+		if m.Model != "" {
+			message["model"] = m.Model
+		}
+		if m.Usage != nil {
+			message["usage"] = m.Usage
+		}
+		return map[string]any{"type": "assistant", "message": message}, nil
+
+	case *types.SystemMessage:
+		// Data holds the whole message as the CLI sent it
+		object := make(map[string]any, len(m.Data)+2)
+		for key, value := range m.Data {
+			object[key] = value
+		}
+		object["type"] = "system"
+		object["subtype"] = m.Subtype
+		return object, nil
+
+	case *types.ResultMessage:
+		object := map[string]any{
+			"type":            "result",
+			"subtype":         m.Subtype,
+			"duration_ms":     m.DurationMs,
+			"duration_api_ms": m.DurationAPIMs,
+			"is_error":        m.IsError,
+			"num_turns":       m.NumTurns,
+			"session_id":      m.SessionID,
+		}
+		if m.TotalCostUSD != nil {
+			object["total_cost_usd"] = *m.TotalCostUSD
+		}
+		if m.Usage != nil {
+			object["usage"] = m.Usage
+		}
+		if m.Result != nil {
+			object["result"] = *m.Result
+		}
+		if m.PermissionDenials != nil {
+			object["permission_denials"] = m.PermissionDenials
+		}
+		if m.ModelUsage != nil {
+			object["modelUsage"] = m.ModelUsage
+		}
+		return object, nil
+
+	case *types.ProgressEvent:
+		object := map[string]any{
+			"type":                 "tool_progress",
+			"tool_use_id":          m.ToolUseID,
+			"elapsed_time_seconds": m.Elapsed.Seconds(),
+		}
+		if m.ToolName != "" {
+			object["tool_name"] = m.ToolName
+		}
+		if m.ParentToolUseID != "" {
+			object["parent_tool_use_id"] = m.ParentToolUseID
+		}
+		if m.Status != "" {
+			object["status"] = m.Status
+		}
+		return object, nil
+
+	case *types.UnknownMessage:
+		if len(m.Raw) > 0 {
+			return m.Raw, nil
+		}
+		return map[string]any{"type": m.MessageType}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot encode message of type %T", msg)
+	}
+}
+
+// blockObjects returns the JSON values the CLI would have sent for blocks.
+func blockObjects(blocks []types.ContentBlock) ([]any, error) {
+	objects := make([]any, 0, len(blocks))
+	for _, block := range blocks {
+		object, err := blockObject(block)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func blockObject(block types.ContentBlock) (any, error) {
+	switch b := block.(type) {
+	case *types.TextBlock:
+		return map[string]any{"type": "text", "text": b.Text}, nil
+
+	case *types.ThinkingBlock:
+		return map[string]any{"type": "thinking", "thinking": b.Thinking, "signature": b.Signature}, nil
+
+	case *types.ToolUseBlock:
+		input := b.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+		return map[string]any{"type": "tool_use", "id": b.ID, "name": b.Name, "input": input}, nil
+
+	case *types.ToolResultBlock:
+		object := map[string]any{"type": "tool_result", "tool_use_id": b.ToolUseID}
+		// The CLI sends most results as a single string
+		if len(b.Content) == 1 {
+			if text, ok := b.Content[0].(*types.TextContent); ok {
+				object["content"] = text.Text
+			}
+		}
+		if _, ok := object["content"]; !ok && len(b.Content) > 0 {
+			object["content"] = b.Content
+		}
+		if b.IsError != nil {
+			object["is_error"] = *b.IsError
+		}
+		return object, nil
+
+	case *types.UnknownBlock:
+		if len(b.Raw) > 0 {
+			return b.Raw, nil
+		}
+		return map[string]any{"type": b.BlockType}, nil
+
+	default:
+		return nil, fmt.Errorf("cannot encode content block of type %T", block)
+	}
+}
@@ -0,0 +1,150 @@
+package streamio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	claudecode "github.com/jrossi/claude-code-sdk-golang"
+	"github.com/jrossi/claude-code-sdk-golang/claudecodetest"
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+var script = []string{
+	claudecodetest.SystemInit("session-1"),
+	claudecodetest.User("Hello"),
+	claudecodetest.Line(map[string]any{
+		"type": "assistant",
+		"message": map[string]any{
+			"id":    "msg_1",
+			"model": "claude-sonnet-4",
+			"usage": map[string]any{"output_tokens": 12},
+			"content": []any{
+				claudecodetest.Thinking("hmm", "sig"),
+				claudecodetest.ToolUse("tool-1", "Read", map[string]any{"file_path": "main.go"}),
+				map[string]any{"type": "server_tool_use", "id": "srv-1"},
+			},
+		},
+	}),
+	claudecodetest.Line(map[string]any{"type": "tool_progress", "tool_use_id": "tool-1", "tool_name": "Read", "elapsed_time_seconds": 2.5}),
+	claudecodetest.ToolResults(
+		claudecodetest.ToolResult("tool-1", "package main", false),
+		map[string]any{"type": "tool_result", "tool_use_id": "tool-2", "content": []any{
+			map[string]any{"type": "text", "text": "a"},
+			map[string]any{"type": "text", "text": "b"},
+		}},
+	),
+	claudecodetest.Line(map[string]any{"type": "stream_event", "event": map[string]any{"delta": "x"}}),
+	claudecodetest.AssistantText("Done"),
+	claudecodetest.Result("session-1",
+		claudecodetest.WithCost(0.02),
+		claudecodetest.WithTurns(2),
+		claudecodetest.WithResultText("Done"),
+		claudecodetest.WithPermissionDenials(types.PermissionDenial{ToolName: "Bash", ToolUseID: "tool-3"}),
+	),
+}
+
+// query runs a query over lines, writing its messages to w if it is not nil.
+func query(t *testing.T, lines []string, w *NDJSONWriter) []types.Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	options := types.NewOptions().WithParseMode(types.ParseModePassthrough)
+	stream, err := claudecode.QueryWithTransport(ctx, "Hello", options, claudecodetest.NewTransport().Add(lines...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	messages := stream.Messages()
+	if w != nil {
+		messages = w.Tee(messages)
+	}
+	var got []types.Message
+	for msg := range messages {
+		got = append(got, msg)
+	}
+	for err := range stream.Errors() {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestNDJSONWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	original := query(t, script, w)
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(original) != len(script) {
+		t.Fatalf("Expected %d messages, got %d", len(script), len(original))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(script) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(script), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "{") || !strings.Contains(line, `"type":`) {
+			t.Errorf("Line %d is not a typed object: %s", i, line)
+		}
+	}
+
+	replayed := query(t, lines, nil)
+	if !reflect.DeepEqual(original, replayed) {
+		for i := range original {
+			if i < len(replayed) && !reflect.DeepEqual(original[i], replayed[i]) {
+				t.Errorf("Message %d: expected %#v, got %#v", i, original[i], replayed[i])
+			}
+		}
+		t.Fatalf("Expected the replayed messages to match")
+	}
+}
+
+func TestNDJSONWriterSkipsHeartbeats(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	heartbeat := &types.SystemMessage{Subtype: types.SystemSubtypeHeartbeat, Data: map[string]any{"idle_ms": 10}}
+	if err := w.Write(heartbeat); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(&types.SystemMessage{Subtype: types.SystemSubtypeWarning, Data: map[string]any{"message": "old CLI"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != `{"message":"old CLI","subtype":"sdk_warning","type":"system"}`+"\n" {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
+
+type failingWriter struct{ n int }
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	fw.n++
+	return 0, errors.New("disk full")
+}
+
+func TestNDJSONWriterErrors(t *testing.T) {
+	fw := &failingWriter{}
+	w := NewNDJSONWriter(fw)
+	text := &types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "hi"}}}
+
+	if err := w.Write(text); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+	if err := w.Write(text); err == nil || fw.n != 1 {
+		t.Errorf("Expected later writes to fail without writing, got %v after %d writes", err, fw.n)
+	}
+	if w.Err() == nil {
+		t.Error("Expected Err to report the failure")
+	}
+
+	if _, err := Marshal(nil); err == nil {
+		t.Error("Expected a nil message to be rejected")
+	}
+}
@@ -0,0 +1,226 @@
+package claudecode
+
+// Handler receives the events of a stream passed to Subscribe. It may
+// implement any of AssistantHandler, ToolUseHandler, ToolResultHandler,
+// ProgressHandler, PlanHandler, SystemHandler, ResultHandler, and
+// ErrorHandler; events without a matching method are skipped. HandlerFuncs implements all of them with
+// optional function fields.
+type Handler any
+
+// AssistantHandler is called with each assistant message.
+type AssistantHandler interface {
+	OnAssistant(msg *AssistantMessage)
+}
+
+// ToolUseHandler is called with each tool Claude calls, after
+// OnAssistant for the message containing it.
+type ToolUseHandler interface {
+	OnToolUse(block *ToolUseBlock)
+}
+
+// ToolResultHandler is called with the result of each tool call.
+type ToolResultHandler interface {
+	OnToolResult(block *ToolResultBlock)
+}
+
+// ProgressHandler is called with each progress report for a running
+// tool call, between OnToolUse and OnToolResult for the call.
+type ProgressHandler interface {
+	OnProgress(event *ProgressEvent)
+}
+
+// PlanHandler is called with each plan Claude proposes in plan mode by
+// calling the ExitPlanMode tool, after OnToolUse for the call.
+type PlanHandler interface {
+	OnPlan(plan string)
+}
+
+// SystemHandler is called with each system message.
+type SystemHandler interface {
+	OnSystem(msg *SystemMessage)
+}
+
+// ResultHandler is called with the result message ending each query or
+// session turn.
+type ResultHandler interface {
+	OnResult(msg *ResultMessage)
+}
+
+// ErrorHandler is called with each error reported on the stream.
+type ErrorHandler interface {
+	OnError(err error)
+}
+
+// HandlerFuncs is a Handler built from functions. Nil functions are
+// skipped.
+//
+// Example:
+//
+//	err := stream.Subscribe(claudecode.HandlerFuncs{
+//		Assistant: func(msg *claudecode.AssistantMessage) { fmt.Print(msg.Text()) },
+//		ToolUse:   func(tool *claudecode.ToolUseBlock) { log.Printf("using %s", tool.Name) },
+//	})
+type HandlerFuncs struct {
+	Assistant  func(msg *AssistantMessage)
+	ToolUse    func(block *ToolUseBlock)
+	ToolResult func(block *ToolResultBlock)
+	Progress   func(event *ProgressEvent)
+	Plan       func(plan string)
+	System     func(msg *SystemMessage)
+	Result     func(msg *ResultMessage)
+	Error      func(err error)
+}
+
+// OnAssistant calls Assistant, if set.
+func (h HandlerFuncs) OnAssistant(msg *AssistantMessage) {
+	if h.Assistant != nil {
+		h.Assistant(msg)
+	}
+}
+
+// OnToolUse calls ToolUse, if set.
+func (h HandlerFuncs) OnToolUse(block *ToolUseBlock) {
+	if h.ToolUse != nil {
+		h.ToolUse(block)
+	}
+}
+
+// OnToolResult calls ToolResult, if set.
+func (h HandlerFuncs) OnToolResult(block *ToolResultBlock) {
+	if h.ToolResult != nil {
+		h.ToolResult(block)
+	}
+}
+
+// OnProgress calls Progress, if set.
+func (h HandlerFuncs) OnProgress(event *ProgressEvent) {
+	if h.Progress != nil {
+		h.Progress(event)
+	}
+}
+
+// OnPlan calls Plan, if set.
+func (h HandlerFuncs) OnPlan(plan string) {
+	if h.Plan != nil {
+		h.Plan(plan)
+	}
+}
+
+// OnSystem calls System, if set.
+func (h HandlerFuncs) OnSystem(msg *SystemMessage) {
+	if h.System != nil {
+		h.System(msg)
+	}
+}
+
+// OnResult calls Result, if set.
+func (h HandlerFuncs) OnResult(msg *ResultMessage) {
+	if h.Result != nil {
+		h.Result(msg)
+	}
+}
+
+// OnError calls Error, if set.
+func (h HandlerFuncs) OnError(err error) {
+	if h.Error != nil {
+		h.Error(err)
+	}
+}
+
+// Subscribe consumes the stream, calling the handler's methods for each
+// event until the stream ends, as an alternative to reading the Messages
+// and Errors channels. Handler methods are called from the calling
+// goroutine, one at a time. It returns the first error reported on the
+// stream, if any.
+func (qs *QueryStream) Subscribe(handler Handler) error {
+	return subscribe(qs.Messages(), qs.Errors(), handler)
+}
+
+// Subscribe consumes the session's messages and errors, calling the
+// handler's methods for each event until the session is closed. It returns
+// the first error reported on the session, if any.
+func (s *Session) Subscribe(handler Handler) error {
+	return subscribe(s.Receive(), s.Errors(), handler)
+}
+
+// subscribe dispatches messages and errors to handler until both channels
+// are closed.
+func subscribe(messages <-chan Message, errs <-chan error, handler Handler) error {
+	var firstErr error
+
+	for messages != nil || errs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			dispatchMessage(msg, handler)
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if h, ok := handler.(ErrorHandler); ok {
+				h.OnError(err)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// dispatchMessage calls the handler methods for a message and its blocks.
+func dispatchMessage(msg Message, handler Handler) {
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		if h, ok := handler.(AssistantHandler); ok {
+			h.OnAssistant(m)
+		}
+		dispatchBlocks(m.Content, handler)
+
+	case *UserMessage:
+		dispatchBlocks(m.Blocks, handler)
+
+	case *ProgressEvent:
+		if h, ok := handler.(ProgressHandler); ok {
+			h.OnProgress(m)
+		}
+
+	case *SystemMessage:
+		if h, ok := handler.(SystemHandler); ok {
+			h.OnSystem(m)
+		}
+
+	case *ResultMessage:
+		if h, ok := handler.(ResultHandler); ok {
+			h.OnResult(m)
+		}
+	}
+}
+
+// dispatchBlocks calls the tool handlers for tool use and result blocks,
+// and the plan handler for ExitPlanMode calls.
+func dispatchBlocks(blocks []ContentBlock, handler Handler) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *ToolUseBlock:
+			if h, ok := handler.(ToolUseHandler); ok {
+				h.OnToolUse(b)
+			}
+			if h, ok := handler.(PlanHandler); ok && b.Name == "ExitPlanMode" {
+				if input, err := DecodeToolInput[ExitPlanModeInput](b); err == nil {
+					h.OnPlan(input.Plan)
+				}
+			}
+		case *ToolResultBlock:
+			if h, ok := handler.(ToolResultHandler); ok {
+				h.OnToolResult(b)
+			}
+		}
+	}
+}
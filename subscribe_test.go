@@ -0,0 +1,104 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// toolLogger implements only some of the handler interfaces.
+type toolLogger struct {
+	events []string
+}
+
+func (tl *toolLogger) OnToolUse(block *ToolUseBlock) {
+	tl.events = append(tl.events, "use:"+block.Name)
+}
+
+func (tl *toolLogger) OnToolResult(block *ToolResultBlock) {
+	tl.events = append(tl.events, "result:"+block.Text())
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	lines := []string{
+		`{"type":"system","subtype":"init","session_id":"abc"}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Reading"},{"type":"tool_use","id":"t1","name":"Read","input":{}}]}}`,
+		`{"type":"tool_progress","tool_use_id":"t1","tool_name":"Read","elapsed_time_seconds":1.5}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"package main"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}`,
+		`{"type":"result","subtype":"success","session_id":"abc"}`,
+	}
+
+	t.Run("funcs", func(t *testing.T) {
+		stream := startScriptedStream(t, ctx, &scriptedTransport{lines: lines})
+		defer stream.Close()
+
+		var events []string
+		err := stream.Subscribe(HandlerFuncs{
+			System:     func(msg *SystemMessage) { events = append(events, "system:"+msg.Subtype) },
+			Assistant:  func(msg *AssistantMessage) { events = append(events, "assistant:"+msg.Text()) },
+			ToolUse:    func(block *ToolUseBlock) { events = append(events, "use:"+block.Name) },
+			ToolResult: func(block *ToolResultBlock) { events = append(events, "result:"+block.Text()) },
+			Progress:   func(event *ProgressEvent) { events = append(events, "progress:"+event.Elapsed.String()) },
+			Result:     func(msg *ResultMessage) { events = append(events, "done:"+msg.SessionID) },
+		})
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+
+		want := "system:init assistant:Reading use:Read progress:1.5s result:package main assistant:Done done:abc"
+		if got := strings.Join(events, " "); got != want {
+			t.Errorf("Events = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("partial handler", func(t *testing.T) {
+		stream := startScriptedStream(t, ctx, &scriptedTransport{lines: lines})
+		defer stream.Close()
+
+		logger := &toolLogger{}
+		if err := stream.Subscribe(logger); err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		if got := strings.Join(logger.events, ","); got != "use:Read,result:package main" {
+			t.Errorf("Events = %q", got)
+		}
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		stream := startScriptedStream(t, ctx, &scriptedTransport{lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"ExitPlanMode","input":{"plan":"1. Edit main.go"}}]}}`,
+			`{"type":"result","subtype":"success","session_id":"abc"}`,
+		}})
+		defer stream.Close()
+
+		var events []string
+		err := stream.Subscribe(HandlerFuncs{
+			ToolUse: func(block *ToolUseBlock) { events = append(events, "use:"+block.Name) },
+			Plan:    func(plan string) { events = append(events, "plan:"+plan) },
+		})
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		if got := strings.Join(events, ","); got != "use:ExitPlanMode,plan:1. Edit main.go" {
+			t.Errorf("Events = %q", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		streamErr := errors.New("process failed")
+		stream := startScriptedStream(t, ctx, &scriptedTransport{errs: []error{streamErr}})
+		defer stream.Close()
+
+		var handled error
+		err := stream.Subscribe(HandlerFuncs{Error: func(err error) { handled = err }})
+		if !errors.Is(err, streamErr) || !errors.Is(handled, streamErr) {
+			t.Errorf("Expected stream error to be returned and handled, got %v and %v", err, handled)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package claudecode
+
+import (
+	"context"
+	"strings"
+)
+
+// QueryResult contains everything collected from a query that ran to completion.
+type QueryResult struct {
+	// AssistantMessages holds every assistant message in the order received.
+	AssistantMessages []*AssistantMessage
+
+	// Result is the final result message, or nil if the CLI exited without one.
+	Result *ResultMessage
+
+	// SessionID is the ID of the session the query ran in. When resuming
+	// with WithForkSession, this is the new forked session's ID.
+	SessionID string
+
+	// Init is the session information from the CLI's init message, or nil
+	// if the CLI sent none.
+	Init *InitInfo
+
+	// Changes lists the files changed by the query's tool uses.
+	Changes []FileChange
+
+	// Text is the concatenated text of all TextBlocks from the assistant messages.
+	Text string
+
+	// Errors holds any non-fatal errors reported on the stream.
+	Errors []error
+}
+
+// QuerySync runs a query to completion and returns the collected results.
+// It is a convenience wrapper around Query for callers that don't need to
+// process messages as they stream in.
+//
+// An error is returned if the query cannot be started, if the context is
+// cancelled before the stream ends, or if the stream ends without a
+// ResultMessage and at least one error was reported. In the latter two cases
+// the partially collected QueryResult is returned alongside the error.
+//
+// Example:
+//
+//	result, err := claudecode.QuerySync(ctx, "What is 2+2?", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(result.Text)
+func QuerySync(ctx context.Context, prompt string, options *Options) (*QueryResult, error) {
+	return defaultClient.QuerySync(ctx, prompt, options)
+}
+
+// CollectText drains a stream and returns the concatenated text of its
+// assistant messages. The error is the first one reported if the stream
+// ended without a result, as for QuerySync. The stream is closed on return.
+//
+// Example:
+//
+//	stream, err := claudecode.Query(ctx, "Write a haiku about Go", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	text, err := claudecode.CollectText(stream)
+func CollectText(stream *QueryStream) (string, error) {
+	defer stream.Close()
+
+	result, err := collectQueryResult(context.Background(), stream)
+	return result.Text, err
+}
+
+// collectQueryResult drains the stream until both channels are closed.
+func collectQueryResult(ctx context.Context, stream *QueryStream) (*QueryResult, error) {
+	result := &QueryResult{}
+	var text strings.Builder
+
+	messages := stream.Messages()
+	errs := stream.Errors()
+
+	for messages != nil || errs != nil {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			switch m := msg.(type) {
+			case *AssistantMessage:
+				result.AssistantMessages = append(result.AssistantMessages, m)
+				text.WriteString(m.Text())
+			case *ResultMessage:
+				result.Result = m
+				result.SessionID = m.SessionID
+			case *SystemMessage:
+				// The init message carries the session ID before any result
+				if id, ok := m.Data["session_id"].(string); ok && result.SessionID == "" {
+					result.SessionID = id
+				}
+				if info, ok := m.Init(); ok {
+					result.Init = info
+				}
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			result.Errors = append(result.Errors, err)
+
+		case <-ctx.Done():
+			result.Text = text.String()
+			result.Changes = stream.Changes()
+			return result, ctx.Err()
+		}
+	}
+
+	result.Text = text.String()
+	result.Changes = stream.Changes()
+
+	if result.Result == nil && len(result.Errors) > 0 {
+		return result, result.Errors[0]
+	}
+
+	return result, nil
+}
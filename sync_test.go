@@ -0,0 +1,256 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/client"
+	"github.com/jrossi/claude-code-sdk-golang/parser"
+)
+
+// scriptedTransport replays a fixed set of stdout lines and errors.
+type scriptedTransport struct {
+	lines     []string
+	errs      []error
+	connected bool
+}
+
+func (st *scriptedTransport) Connect(ctx context.Context) error {
+	st.connected = true
+	return nil
+}
+
+func (st *scriptedTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	dataChan := make(chan []byte, len(st.lines))
+	errChan := make(chan error, len(st.errs))
+
+	go func() {
+		defer close(dataChan)
+		for _, line := range st.lines {
+			select {
+			case dataChan <- []byte(line):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for _, err := range st.errs {
+		errChan <- err
+	}
+	close(errChan)
+
+	return dataChan, errChan
+}
+
+func (st *scriptedTransport) Close() error {
+	st.connected = false
+	return nil
+}
+
+func (st *scriptedTransport) IsConnected() bool {
+	return st.connected
+}
+
+func startScriptedStream(t *testing.T, ctx context.Context, st *scriptedTransport) *QueryStream {
+	t.Helper()
+	internal := client.NewQueryStream(ctx, st, parser.NewParser(0))
+	if err := internal.Start(); err != nil {
+		t.Fatalf("Failed to start stream: %v", err)
+	}
+	return wrapQueryStream(internal)
+}
+
+func TestCollectQueryResult(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"system","subtype":"init"}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello, "}]}}`,
+			`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Read","input":{}},{"type":"text","text":"world"}]}}`,
+			`{"type":"result","subtype":"success","session_id":"abc","num_turns":2,"total_cost_usd":0.01}`,
+		},
+	})
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.AssistantMessages) != 2 {
+		t.Errorf("Expected 2 assistant messages, got %d", len(result.AssistantMessages))
+	}
+	if result.Text != "Hello, world" {
+		t.Errorf("Expected text %q, got %q", "Hello, world", result.Text)
+	}
+	if result.Result == nil {
+		t.Fatal("Expected result message")
+	}
+	if result.Result.SessionID != "abc" || result.SessionID != "abc" {
+		t.Errorf("Expected session ID 'abc', got %q", result.SessionID)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+}
+
+func TestCollectText(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello, "},{"type":"thinking","thinking":"hmm"},{"type":"text","text":"world"}]}}`,
+			`{"type":"result","subtype":"success","session_id":"abc"}`,
+		},
+	})
+
+	text, err := CollectText(stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("Expected text %q, got %q", "Hello, world", text)
+	}
+	if !stream.IsClosed() {
+		t.Error("Expected stream to be closed")
+	}
+
+	streamErr := errors.New("process failed")
+	stream = startScriptedStream(t, ctx, &scriptedTransport{errs: []error{streamErr}})
+	if _, err := CollectText(stream); !errors.Is(err, streamErr) {
+		t.Errorf("Expected stream error, got %v", err)
+	}
+}
+
+func TestCollectQueryResultErrorWithoutResult(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	streamErr := errors.New("process failed")
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{`{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]}}`},
+		errs:  []error{streamErr},
+	})
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if !errors.Is(err, streamErr) {
+		t.Errorf("Expected stream error, got %v", err)
+	}
+	if result == nil || result.Text != "partial" {
+		t.Errorf("Expected partial result to be returned, got %+v", result)
+	}
+}
+
+func TestCollectQueryResultErrorWithResult(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{`{"type":"result","subtype":"success","session_id":"abc"}`},
+		errs:  []error{errors.New("stderr warning")},
+	})
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if err != nil {
+		t.Errorf("Expected no error when a result was received, got %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected 1 collected error, got %d", len(result.Errors))
+	}
+}
+
+func TestCollectQueryResultContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// A transport whose channels never close.
+	internal := client.NewQueryStream(context.Background(), &blockingTransport{}, parser.NewParser(0))
+	if err := internal.Start(); err != nil {
+		t.Fatalf("Failed to start stream: %v", err)
+	}
+	stream := wrapQueryStream(internal)
+	defer stream.Close()
+
+	cancel()
+	_, err := collectQueryResult(ctx, stream)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// blockingTransport returns channels that stay open until Close is called.
+type blockingTransport struct {
+	data chan []byte
+	errs chan error
+}
+
+func (bt *blockingTransport) Connect(ctx context.Context) error {
+	bt.data = make(chan []byte)
+	bt.errs = make(chan error)
+	return nil
+}
+
+func (bt *blockingTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
+	return bt.data, bt.errs
+}
+
+func (bt *blockingTransport) Close() error {
+	return nil
+}
+
+func (bt *blockingTransport) IsConnected() bool {
+	return true
+}
+
+func TestCollectQueryResultForkedSessionID(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Without a result, the session ID comes from the init message
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"system","subtype":"init","session_id":"forked"}`,
+		},
+	})
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.SessionID != "forked" {
+		t.Errorf("Expected session ID 'forked', got %q", result.SessionID)
+	}
+}
+
+func TestCollectQueryResultChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/repo/hello.txt","content":"hi"}}]}}`,
+			`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"File created successfully at: /repo/hello.txt"}]}}`,
+			`{"type":"result","subtype":"success","session_id":"abc"}`,
+		},
+	})
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Changes) != 1 {
+		t.Fatalf("Expected one change, got %+v", result.Changes)
+	}
+	if change := result.Changes[0]; change.Path != "/repo/hello.txt" || change.Op != ChangeCreated {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+}
@@ -0,0 +1,50 @@
+package claudecode
+
+import "io"
+
+// TextReader returns a reader that yields the stream's assistant text as
+// it arrives, for piping into templates, HTTP responses, or terminals:
+//
+//	_, err := io.Copy(os.Stdout, stream.TextReader())
+//
+// The reader consumes the stream, so Messages and Errors must not be read
+// as well. It returns io.EOF once the stream ends, or the first error
+// reported on the stream if it ended without a result. Closing the reader
+// closes the stream.
+func (qs *QueryStream) TextReader() io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		sawResult := false
+		err := qs.Subscribe(HandlerFuncs{
+			Assistant: func(msg *AssistantMessage) {
+				if text := msg.Text(); text != "" {
+					if _, err := io.WriteString(pw, text); err != nil {
+						// The reader was closed; stop the CLI
+						qs.Close()
+					}
+				}
+			},
+			Result: func(*ResultMessage) { sawResult = true },
+		})
+
+		if sawResult {
+			err = nil
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return &textReader{PipeReader: pr, stream: qs}
+}
+
+// textReader closes its stream along with the pipe.
+type textReader struct {
+	*io.PipeReader
+	stream *QueryStream
+}
+
+// Close stops reading and closes the stream.
+func (tr *textReader) Close() error {
+	tr.PipeReader.Close()
+	return tr.stream.Close()
+}
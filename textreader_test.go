@@ -0,0 +1,76 @@
+package claudecode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTextReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello, "}]}}`,
+			`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Read","input":{}}]}}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"world"}]}}`,
+			`{"type":"result","subtype":"success","session_id":"abc"}`,
+		},
+	})
+	defer stream.Close()
+
+	text, err := io.ReadAll(stream.TextReader())
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(text) != "Hello, world" {
+		t.Errorf("Expected %q, got %q", "Hello, world", text)
+	}
+}
+
+func TestTextReaderError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	streamErr := errors.New("process failed")
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{`{"type":"assistant","message":{"content":[{"type":"text","text":"partial"}]}}`},
+		errs:  []error{streamErr},
+	})
+	defer stream.Close()
+
+	text, err := io.ReadAll(stream.TextReader())
+	if !errors.Is(err, streamErr) {
+		t.Errorf("Expected stream error, got %v", err)
+	}
+	if string(text) != "partial" {
+		t.Errorf("Expected partial text, got %q", text)
+	}
+}
+
+func TestTextReaderClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream := startScriptedStream(t, ctx, &scriptedTransport{
+		lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"one"}]}}`,
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"two"}]}}`,
+		},
+	})
+
+	reader := stream.TextReader()
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(reader, buf); err != nil || string(buf) != "one" {
+		t.Fatalf("Expected to read %q, got %q: %v", "one", buf, err)
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !stream.IsClosed() {
+		t.Error("Expected stream to be closed")
+	}
+}
@@ -0,0 +1,184 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// WriteMarkdown writes the transcript as Markdown, with tool calls and
+// results in fenced code blocks.
+func (t *Transcript) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Transcript\n\n_Started %s_\n", t.startedAt.Format(time.RFC3339))
+
+	for _, s := range t.sections() {
+		fmt.Fprintf(bw, "\n## %s\n", s.Heading())
+		for _, it := range s.Items {
+			bw.WriteString("\n")
+			writeMarkdownItem(bw, it)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Markdown returns the transcript as Markdown.
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+	t.WriteMarkdown(&b)
+	return b.String()
+}
+
+func writeMarkdownItem(w *bufio.Writer, it item) {
+	switch it.Kind {
+	case kindText:
+		w.WriteString(strings.TrimRight(it.Body, "\n") + "\n")
+	case kindThinking:
+		w.WriteString("> _Thinking_\n>\n")
+		for _, line := range strings.Split(strings.TrimRight(it.Body, "\n"), "\n") {
+			w.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+	case kindToolUse:
+		fmt.Fprintf(w, "**Tool call:** `%s`\n\n", it.Title)
+		writeFence(w, "json", it.Body)
+	case kindToolResult:
+		label := "Tool result"
+		if it.Error {
+			label = "Tool error"
+		}
+		fmt.Fprintf(w, "**%s:** `%s`\n\n", label, it.Title)
+		writeFence(w, "", it.Body)
+	case kindNote:
+		fmt.Fprintf(w, "_%s_\n", it.Body)
+	}
+}
+
+// writeFence writes body as a fenced code block, with a fence longer than
+// any run of backticks in body.
+func writeFence(w *bufio.Writer, lang, body string) {
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(body, "\n"), fence)
+}
+
+// htmlTemplate renders a transcript as a standalone page. Tool calls and
+// results are collapsible.
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Transcript</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #1f2328; }
+section { border-left: 4px solid #d0d7de; margin: 1rem 0; padding: 0.25rem 1rem; }
+section.user { border-color: #0969da; }
+section.assistant { border-color: #8250df; }
+section.tools { border-color: #9a6700; }
+section.result { border-color: #1a7f37; }
+h2 { font-size: 0.85rem; text-transform: uppercase; letter-spacing: 0.05em; color: #59636e; margin: 0.5rem 0; }
+h2 time { font-weight: normal; margin-left: 0.5rem; }
+.text { white-space: pre-wrap; }
+.thinking { white-space: pre-wrap; color: #59636e; font-style: italic; }
+.note { color: #59636e; font-style: italic; }
+.error { color: #d1242f; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; }
+summary { cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>Transcript</h1>
+<p class="note">Started {{.StartedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+{{range .Sections}}<section class="{{.Role}}">
+<h2>{{.Heading}}<time datetime="{{.Time.Format "2006-01-02T15:04:05Z07:00"}}">{{.Time.Format "15:04:05"}}</time></h2>
+{{range .Items}}{{if eq .Kind "text"}}<div class="text{{if .Error}} error{{end}}">{{.Body}}</div>
+{{else if eq .Kind "thinking"}}<details><summary>Thinking</summary><div class="thinking">{{.Body}}</div></details>
+{{else if eq .Kind "tool_use"}}<details><summary>Tool call: <code>{{.Title}}</code></summary><pre>{{.Body}}</pre></details>
+{{else if eq .Kind "tool_result"}}<details><summary{{if .Error}} class="error"{{end}}>{{if .Error}}Tool error{{else}}Tool result{{end}}: <code>{{.Title}}</code></summary><pre>{{.Body}}</pre></details>
+{{else}}<p class="note{{if .Error}} error{{end}}">{{.Body}}</p>
+{{end}}{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the transcript as a standalone HTML page, with tool
+// calls and results in collapsible sections.
+func (t *Transcript) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, struct {
+		StartedAt time.Time
+		Sections  []section
+	}{t.startedAt, t.sections()})
+}
+
+// MarshalJSON encodes the transcript as an object holding its start time
+// and entries. Each entry holds the time it was added and its message,
+// whose "type" field, like those of its content blocks, gives its type.
+func (t *Transcript) MarshalJSON() ([]byte, error) {
+	entries := t.Entries()
+	encoded := make([]jsonEntry, len(entries))
+	for i, entry := range entries {
+		encoded[i] = jsonEntry{Time: entry.Time, Message: entry.Message}
+	}
+
+	return json.Marshal(jsonTranscript{StartedAt: t.startedAt, Entries: encoded})
+}
+
+// UnmarshalJSON decodes a transcript encoded by MarshalJSON, restoring
+// each message's concrete type, so that a stored transcript can be
+// exported again.
+func (t *Transcript) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		StartedAt time.Time `json:"started_at"`
+		Entries   []struct {
+			Time    time.Time       `json:"time"`
+			Message json.RawMessage `json:"message"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	entries := make([]Entry, 0, len(decoded.Entries))
+	for _, entry := range decoded.Entries {
+		msg, err := types.UnmarshalMessage(entry.Message)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Time: entry.Time, Message: msg})
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startedAt = decoded.StartedAt
+	t.entries = entries
+	return nil
+}
+
+// jsonTranscript and jsonEntry are the JSON encoding of a transcript.
+type jsonTranscript struct {
+	StartedAt time.Time   `json:"started_at"`
+	Entries   []jsonEntry `json:"entries"`
+}
+
+type jsonEntry struct {
+	Time    time.Time     `json:"time"`
+	Message types.Message `json:"message"`
+}
+
+// WriteJSON writes the transcript as indented JSON.
+func (t *Transcript) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
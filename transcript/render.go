@@ -0,0 +1,200 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Section roles.
+const (
+	roleUser      = "user"
+	roleAssistant = "assistant"
+	roleTools     = "tools"
+	roleSystem    = "system"
+	roleResult    = "result"
+)
+
+// Item kinds.
+const (
+	kindText       = "text"
+	kindThinking   = "thinking"
+	kindToolUse    = "tool_use"
+	kindToolResult = "tool_result"
+	kindNote       = "note"
+)
+
+// section is a run of consecutive messages from one role, as rendered.
+// The CLI splits one assistant reply into several messages, so they are
+// shown together.
+type section struct {
+	Role  string
+	Time  time.Time
+	Items []item
+}
+
+// item is one rendered part of a section.
+type item struct {
+	Kind string
+
+	// Title names the tool of tool calls and results
+	Title string
+
+	Body  string
+	Error bool
+}
+
+// Heading returns the section's heading.
+func (s section) Heading() string {
+	switch s.Role {
+	case roleUser:
+		return "User"
+	case roleAssistant:
+		return "Assistant"
+	case roleTools:
+		return "Tool results"
+	case roleResult:
+		return "Result"
+	}
+	return "System"
+}
+
+// sections converts the transcript into the form both renderers use.
+func (t *Transcript) sections() []section {
+	var sections []section
+	toolNames := make(map[string]string)
+
+	add := func(entry Entry, role string, items ...item) {
+		if len(items) == 0 {
+			return
+		}
+		if n := len(sections); n > 0 && sections[n-1].Role == role && role != roleUser {
+			sections[n-1].Items = append(sections[n-1].Items, items...)
+			return
+		}
+		sections = append(sections, section{Role: role, Time: entry.Time, Items: items})
+	}
+
+	for _, entry := range t.Entries() {
+		switch m := entry.Message.(type) {
+		case *types.UserMessage:
+			if len(m.Blocks) == 0 {
+				add(entry, roleUser, item{Kind: kindText, Body: m.Content})
+				continue
+			}
+			add(entry, roleTools, blockItems(m.Blocks, toolNames)...)
+
+		case *types.AssistantMessage:
+			add(entry, roleAssistant, blockItems(m.Content, toolNames)...)
+
+		case *types.SystemMessage:
+			add(entry, roleSystem, item{Kind: kindNote, Body: systemNote(m)})
+
+		case *types.ResultMessage:
+			items := []item{{Kind: kindNote, Body: resultSummary(m), Error: m.IsError}}
+			if m.IsError && m.Result != nil && *m.Result != "" {
+				items = append(items, item{Kind: kindText, Body: *m.Result, Error: true})
+			}
+			add(entry, roleResult, items...)
+
+		default:
+			add(entry, roleSystem, item{Kind: kindNote, Body: fmt.Sprintf("Unrecognized %q message", m.Type())})
+		}
+	}
+
+	return sections
+}
+
+// blockItems converts content blocks, recording the names of called tools
+// so that their results can be labeled.
+func blockItems(blocks []types.ContentBlock, toolNames map[string]string) []item {
+	var items []item
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *types.TextBlock:
+			if strings.TrimSpace(b.Text) != "" {
+				items = append(items, item{Kind: kindText, Body: b.Text})
+			}
+		case *types.ThinkingBlock:
+			items = append(items, item{Kind: kindThinking, Body: b.Thinking})
+		case *types.ToolUseBlock:
+			toolNames[b.ID] = b.Name
+			items = append(items, item{Kind: kindToolUse, Title: b.Name, Body: indentJSON(b.Input)})
+		case *types.ToolResultBlock:
+			name := toolNames[b.ToolUseID]
+			if name == "" {
+				name = b.ToolUseID
+			}
+			items = append(items, item{Kind: kindToolResult, Title: name, Body: resultText(b), Error: b.Failed()})
+		default:
+			items = append(items, item{Kind: kindNote, Body: fmt.Sprintf("Unrecognized %q block", block.Type())})
+		}
+	}
+	return items
+}
+
+// resultText returns the text of a tool result, with placeholders for its
+// images and structured parts.
+func resultText(block *types.ToolResultBlock) string {
+	var parts []string
+	for _, part := range block.Content {
+		switch p := part.(type) {
+		case *types.TextContent:
+			parts = append(parts, p.Text)
+		case *types.ImageContent:
+			parts = append(parts, fmt.Sprintf("[image %s]", p.MediaType))
+		case *types.JSONContent:
+			parts = append(parts, string(p.Raw))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// systemNote describes a system message.
+func systemNote(msg *types.SystemMessage) string {
+	if info, ok := msg.Init(); ok {
+		if info.Model == "" {
+			return fmt.Sprintf("Session %s started", info.SessionID)
+		}
+		return fmt.Sprintf("Session %s started with %s", info.SessionID, info.Model)
+	}
+
+	if boundary, ok := msg.CompactBoundary(); ok {
+		return fmt.Sprintf("Conversation compacted (%s, %d tokens before)", boundary.Trigger, boundary.PreTokens)
+	}
+
+	switch msg.Subtype {
+	case types.SystemSubtypeWarning:
+		return fmt.Sprintf("Warning: %v", msg.Data["message"])
+	case types.SystemSubtypeReconnected:
+		return fmt.Sprintf("Reconnected to session %v after: %v", msg.Data["session_id"], msg.Data["error"])
+	}
+	return "System: " + msg.Subtype
+}
+
+// resultSummary describes how a run ended.
+func resultSummary(msg *types.ResultMessage) string {
+	parts := []string{msg.Subtype}
+	if msg.NumTurns > 0 {
+		parts = append(parts, fmt.Sprintf("%d turns", msg.NumTurns))
+	}
+	if msg.TotalCostUSD != nil {
+		parts = append(parts, fmt.Sprintf("$%.4f", *msg.TotalCostUSD))
+	}
+	if msg.DurationMs > 0 {
+		parts = append(parts, (time.Duration(msg.DurationMs) * time.Millisecond).String())
+	}
+	return strings.Join(parts, " · ")
+}
+
+// indentJSON formats a tool's input for display.
+func indentJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
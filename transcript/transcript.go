@@ -0,0 +1,134 @@
+// Package transcript accumulates the messages of a query or session and
+// exports them as Markdown, JSON, or HTML, so applications can store and
+// display complete runs.
+//
+// A Transcript records each message as it is read from the stream:
+//
+//	tr := transcript.New(prompt)
+//	for msg := range tr.Tee(stream.Messages()) {
+//		// ... handle msg as usual ...
+//	}
+//	err := tr.WriteHTML(file)
+//
+// Unlike the record package, which captures the CLI's raw output for
+// replay, a Transcript holds parsed messages and renders them for people,
+// with tool calls and their results shown together.
+package transcript
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Entry is a message in a transcript with the time it was added.
+type Entry struct {
+	Time    time.Time
+	Message types.Message
+}
+
+// Transcript is the ordered message history of a query or session. It is
+// safe for concurrent use.
+//
+// Heartbeats and tool progress reports are transient and are not
+// recorded.
+type Transcript struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	entries   []Entry
+}
+
+// New creates a transcript. If prompt is not empty, it is recorded as the
+// first user message; sessions record each prompt with AddPrompt instead.
+func New(prompt string) *Transcript {
+	t := &Transcript{startedAt: time.Now()}
+	if prompt != "" {
+		t.AddPrompt(prompt)
+	}
+	return t
+}
+
+// Restore creates a transcript holding entries, such as those of a
+// transcript decoded from storage.
+func Restore(startedAt time.Time, entries []Entry) *Transcript {
+	return &Transcript{startedAt: startedAt, entries: append([]Entry(nil), entries...)}
+}
+
+// AddPrompt records a prompt sent to Claude. The CLI does not echo
+// prompts, so they must be added for the transcript to show them.
+func (t *Transcript) AddPrompt(prompt string) {
+	t.Add(&types.UserMessage{Content: prompt})
+}
+
+// Add records a message.
+func (t *Transcript) Add(msg types.Message) {
+	if !recorded(msg) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, Entry{Time: time.Now(), Message: msg})
+}
+
+// recorded reports whether msg belongs in a transcript.
+func recorded(msg types.Message) bool {
+	switch m := msg.(type) {
+	case nil, *types.ProgressEvent:
+		return false
+	case *types.SystemMessage:
+		return m.Subtype != types.SystemSubtypeHeartbeat
+	}
+	return true
+}
+
+// Tee records every message received from messages and forwards it on the
+// returned channel, which is closed once messages is. The returned channel
+// must be drained.
+func (t *Transcript) Tee(messages <-chan types.Message) <-chan types.Message {
+	out := make(chan types.Message, cap(messages))
+
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			t.Add(msg)
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// StartedAt returns when the transcript was created.
+func (t *Transcript) StartedAt() time.Time {
+	return t.startedAt
+}
+
+// Entries returns a copy of the recorded entries, in order.
+func (t *Transcript) Entries() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Entry(nil), t.entries...)
+}
+
+// Messages returns the recorded messages, in order.
+func (t *Transcript) Messages() []types.Message {
+	entries := t.Entries()
+	messages := make([]types.Message, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Result returns the last result message, or nil if none was recorded.
+func (t *Transcript) Result() *types.ResultMessage {
+	entries := t.Entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if result, ok := entries[i].Message.(*types.ResultMessage); ok {
+			return result
+		}
+	}
+	return nil
+}
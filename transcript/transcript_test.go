@@ -0,0 +1,168 @@
+package transcript
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func sampleTranscript() *Transcript {
+	cost := 0.0123
+	failed := true
+	tr := New("Fix the <b>build</b>")
+	for _, msg := range []types.Message{
+		&types.SystemMessage{Subtype: types.SystemSubtypeInit, Data: map[string]any{"session_id": "s1", "model": "sonnet"}},
+		&types.AssistantMessage{Content: []types.ContentBlock{
+			&types.ThinkingBlock{Thinking: "Check the Makefile"},
+			&types.TextBlock{Text: "Running the build."},
+			&types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]any{"command": "make"}},
+		}},
+		&types.ProgressEvent{ToolUseID: "t1", Status: types.ProgressStatusRunning},
+		&types.SystemMessage{Subtype: types.SystemSubtypeHeartbeat},
+		&types.UserMessage{Content: "1 tool result", Blocks: []types.ContentBlock{
+			&types.ToolResultBlock{ToolUseID: "t1", Content: []types.ToolResultContent{&types.TextContent{Text: "```\nerror: missing ;"}}, IsError: &failed},
+		}},
+		&types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "Fixed it."}}},
+		&types.ResultMessage{Subtype: "success", NumTurns: 2, SessionID: "s1", TotalCostUSD: &cost},
+	} {
+		tr.Add(msg)
+	}
+	return tr
+}
+
+func TestTranscriptSkipsTransientMessages(t *testing.T) {
+	tr := sampleTranscript()
+
+	messages := tr.Messages()
+	if len(messages) != 6 {
+		t.Fatalf("Expected 6 messages, got %d", len(messages))
+	}
+	if _, ok := messages[0].(*types.UserMessage); !ok {
+		t.Errorf("Expected the prompt first, got %T", messages[0])
+	}
+	if result := tr.Result(); result == nil || result.SessionID != "s1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	md := sampleTranscript().Markdown()
+
+	for _, want := range []string{
+		"## User\n\nFix the <b>build</b>\n",
+		"_Session s1 started with sonnet_",
+		"> _Thinking_\n>\n> Check the Makefile\n",
+		"**Tool call:** `Bash`\n\n```json\n{\n  \"command\": \"make\"\n}\n```\n",
+		"**Tool error:** `Bash`\n\n````\n```\nerror: missing ;\n````\n",
+		"## Assistant\n\nFixed it.\n",
+		"_success · 2 turns · $0.0123_",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Count(md, "## Assistant") != 2 {
+		t.Errorf("Expected tool results to separate the assistant sections:\n%s", md)
+	}
+}
+
+func TestTranscriptHTML(t *testing.T) {
+	var b strings.Builder
+	if err := sampleTranscript().WriteHTML(&b); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	page := b.String()
+
+	for _, want := range []string{
+		"Fix the &lt;b&gt;build&lt;/b&gt;",
+		`<summary>Tool call: <code>Bash</code></summary>`,
+		`<summary class="error">Tool error: <code>Bash</code></summary>`,
+		`<section class="result">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<b>build") {
+		t.Error("Expected message text to be escaped")
+	}
+}
+
+func TestTranscriptJSON(t *testing.T) {
+	data, err := json.Marshal(sampleTranscript())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded struct {
+		Entries []struct {
+			Message map[string]any `json:"message"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	var kinds []string
+	for _, entry := range decoded.Entries {
+		kinds = append(kinds, entry.Message["type"].(string))
+	}
+	if got := strings.Join(kinds, " "); got != "user system assistant user assistant result" {
+		t.Errorf("Unexpected message types: %s", got)
+	}
+
+	content := decoded.Entries[2].Message["content"].([]any)
+	if tool := content[2].(map[string]any); tool["type"] != "tool_use" || tool["name"] != "Bash" {
+		t.Errorf("Unexpected tool use block: %v", tool)
+	}
+}
+
+func TestTranscriptJSONRoundTrip(t *testing.T) {
+	tr := sampleTranscript()
+	data, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var restored Transcript
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(restored.Messages(), tr.Messages()) {
+		t.Errorf("Expected the messages to be restored, got %#v", restored.Messages())
+	}
+	entries := restored.Entries()
+	for i, entry := range tr.Entries() {
+		if !entries[i].Time.Equal(entry.Time) {
+			t.Errorf("Entry %d: expected time %v, got %v", i, entry.Time, entries[i].Time)
+		}
+	}
+	if restored.Markdown() != tr.Markdown() {
+		t.Errorf("Expected the restored transcript to render the same")
+	}
+
+	if err := json.Unmarshal([]byte(`{"entries":[{"message":{"content":"untyped"}}]}`), &restored); err == nil {
+		t.Error("Expected a message without a type to be rejected")
+	}
+}
+
+func TestTranscriptTee(t *testing.T) {
+	in := make(chan types.Message, 2)
+	in <- &types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "hi"}}}
+	in <- &types.ResultMessage{Subtype: "success"}
+	close(in)
+
+	tr := New("")
+	count := 0
+	for range tr.Tee(in) {
+		count++
+	}
+
+	if count != 2 || len(tr.Messages()) != 2 {
+		t.Errorf("Expected 2 messages forwarded and recorded, got %d and %d", count, len(tr.Messages()))
+	}
+}
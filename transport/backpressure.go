@@ -0,0 +1,247 @@
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultDataBufferSize is the number of CLI output lines the data channel
+// holds when Config.DataBufferSize is zero.
+const DefaultDataBufferSize = 100
+
+// OverflowPolicy decides what the transport does with CLI output when the
+// data channel is full because its reader is slower than the CLI.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock stops reading the CLI's stdout until the reader catches
+	// up. The CLI then blocks writing its output, so memory stays bounded.
+	// This is the default.
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDrop discards lines that do not fit, counting them in
+	// SubprocessTransport.Dropped. Dropped lines may include the result
+	// message, so it suits only consumers that can tolerate gaps, such as
+	// live progress displays.
+	OverflowDrop OverflowPolicy = "drop"
+
+	// OverflowSpill writes lines that do not fit to a temporary file in
+	// Config.SpillDir and delivers them, in order, as the reader catches up.
+	// The CLI never blocks and memory stays bounded, at the cost of disk.
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+// dataBufferSize returns the capacity of the data channel.
+func (c *Config) dataBufferSize() int {
+	if c != nil && c.DataBufferSize > 0 {
+		return c.DataBufferSize
+	}
+	return DefaultDataBufferSize
+}
+
+// Dropped returns the number of output lines discarded under OverflowDrop.
+func (st *SubprocessTransport) Dropped() int64 {
+	return st.dropped.Load()
+}
+
+// newDeliverer returns the function streamStdout uses to send each line
+// to the data channel under the configured overflow policy, and a function
+// to call once stdout is exhausted, which returns when every accepted line
+// has been delivered or delivery was abandoned. deliver reports false if
+// streaming should stop.
+func (st *SubprocessTransport) newDeliverer(ctx context.Context) (deliver func([]byte) bool, finish func(), err error) {
+	block := func(line []byte) bool {
+		select {
+		case st.dataChan <- line:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-st.doneChan:
+			return false
+		}
+	}
+
+	switch st.config.OverflowPolicy {
+	case "", OverflowBlock:
+		return block, func() {}, nil
+
+	case OverflowDrop:
+		drop := func(line []byte) bool {
+			select {
+			case st.dataChan <- line:
+			case <-ctx.Done():
+				return false
+			case <-st.doneChan:
+				return false
+			default:
+				st.dropped.Add(1)
+			}
+			return true
+		}
+		return drop, func() {}, nil
+
+	case OverflowSpill:
+		spool, err := newSpool(st.config.SpillDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			spool.forward(block)
+		}()
+		finish = func() {
+			// The forwarder stops promptly once the stream is canceled, and
+			// must stop before the data channel is closed
+			spool.closeWrites()
+			<-forwarded
+			spool.remove()
+		}
+		return func(line []byte) bool { return spool.offer(st.dataChan, line) }, finish, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown overflow policy %q", st.config.OverflowPolicy)
+	}
+}
+
+// spool is a disk-backed FIFO of lines that did not fit in the data
+// channel. Lines are written to the file with a length prefix and read
+// back in order by forward.
+type spool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	file *os.File
+
+	// pending counts lines spilled but not yet delivered; while it is
+	// non-zero, new lines are spilled too so that order is kept
+	pending  int
+	writeOff int64
+	readOff  int64
+	closed   bool
+
+	// abandoned is set once forward stops early; later lines are discarded
+	abandoned atomic.Bool
+}
+
+// newSpool creates a spool backed by a temporary file in dir, or in the
+// default temporary directory if dir is empty.
+func newSpool(dir string) (*spool, error) {
+	file, err := os.CreateTemp(dir, "claude-spill-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file: %w", err)
+	}
+	s := &spool{file: file}
+	s.cond = sync.NewCond(&s.mu)
+	return s, nil
+}
+
+// offer sends line to ch if it has room and nothing is spilled, and
+// spills it otherwise. It reports false if the line could not be kept.
+func (s *spool) offer(ch chan<- []byte, line []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.abandoned.Load() {
+		return false
+	}
+	if s.pending == 0 {
+		select {
+		case ch <- line:
+			return true
+		default:
+		}
+	}
+
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(len(line)))
+	if _, err := s.file.WriteAt(append(header[:n], line...), s.writeOff); err != nil {
+		return false
+	}
+	s.writeOff += int64(n + len(line))
+	s.pending++
+	s.cond.Signal()
+	return true
+}
+
+// forward reads spilled lines in order and passes them to send until the
+// spool is closed and empty, or send reports false.
+func (s *spool) forward(send func([]byte) bool) {
+	for {
+		s.mu.Lock()
+		for s.pending == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.pending == 0 {
+			s.mu.Unlock()
+			return
+		}
+		line, err := s.readNext()
+		s.mu.Unlock()
+
+		if err != nil || !send(line) {
+			s.abandoned.Store(true)
+			return
+		}
+
+		s.mu.Lock()
+		s.pending--
+		if s.pending == 0 {
+			// Reuse the file from the start once everything is delivered
+			s.readOff, s.writeOff = 0, 0
+			s.file.Truncate(0)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// readNext reads the line at the read offset. The caller holds s.mu.
+func (s *spool) readNext() ([]byte, error) {
+	reader := io.NewSectionReader(s.file, s.readOff, s.writeOff-s.readOff)
+	counter := &countingByteReader{r: reader}
+	size, err := binary.ReadUvarint(counter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	line := make([]byte, size)
+	if _, err := io.ReadFull(reader, line); err != nil {
+		return nil, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	s.readOff += int64(counter.n) + int64(size)
+	return line, nil
+}
+
+// closeWrites tells forward that no more lines will be spilled.
+func (s *spool) closeWrites() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Signal()
+	s.mu.Unlock()
+}
+
+// remove deletes the spill file.
+func (s *spool) remove() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+// countingByteReader counts the bytes read through ReadByte.
+type countingByteReader struct {
+	r *io.SectionReader
+	n int
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := c.r.Read(b[:]); err != nil {
+		return 0, err
+	}
+	c.n++
+	return b[0], nil
+}
@@ -0,0 +1,136 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// newPipeTransport returns a transport whose stdout is the given lines,
+// ready for streamStdout.
+func newPipeTransport(config *Config, lines []string) *SubprocessTransport {
+	if config.Options == nil {
+		config.Options = types.NewOptions()
+	}
+	st := NewSubprocessTransport(config)
+	st.stdout = &mockPipe{data: []byte(strings.Join(lines, "\n") + "\n")}
+	return st
+}
+
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf(`{"n":%d}`, i)
+	}
+	return lines
+}
+
+func TestDataBufferSize(t *testing.T) {
+	if got := cap(NewSubprocessTransport(&Config{}).dataChan); got != DefaultDataBufferSize {
+		t.Errorf("Expected default capacity %d, got %d", DefaultDataBufferSize, got)
+	}
+	if got := cap(NewSubprocessTransport(&Config{DataBufferSize: 7}).dataChan); got != 7 {
+		t.Errorf("Expected capacity 7, got %d", got)
+	}
+}
+
+func TestOverflowDrop(t *testing.T) {
+	st := newPipeTransport(&Config{DataBufferSize: 2, OverflowPolicy: OverflowDrop}, numberedLines(5))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Nothing reads while the CLI writes, so only the buffered lines survive
+	st.streamStdout(ctx)
+
+	var got []string
+	for line := range st.dataChan {
+		got = append(got, string(line))
+	}
+	if strings.Join(got, ",") != `{"n":0},{"n":1}` {
+		t.Errorf("Unexpected lines: %q", got)
+	}
+	if st.Dropped() != 3 {
+		t.Errorf("Expected 3 dropped lines, got %d", st.Dropped())
+	}
+}
+
+func TestOverflowSpill(t *testing.T) {
+	dir := t.TempDir()
+	lines := numberedLines(200)
+	st := newPipeTransport(&Config{DataBufferSize: 2, OverflowPolicy: OverflowSpill, SpillDir: dir}, lines)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The whole output is read before the slow reader starts
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		st.streamStdout(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	var got []string
+	for line := range st.dataChan {
+		got = append(got, string(line))
+	}
+	<-done
+
+	if strings.Join(got, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("Expected all %d lines in order, got %d", len(lines), len(got))
+	}
+	if st.Dropped() != 0 {
+		t.Errorf("Expected no dropped lines, got %d", st.Dropped())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the spill file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestOverflowSpillCanceled(t *testing.T) {
+	dir := t.TempDir()
+	st := newPipeTransport(&Config{DataBufferSize: 1, OverflowPolicy: OverflowSpill, SpillDir: dir}, numberedLines(20))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		st.streamStdout(ctx)
+	}()
+
+	<-st.dataChan
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("streamStdout did not stop after cancellation")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the spill file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestOverflowUnknownPolicy(t *testing.T) {
+	st := newPipeTransport(&Config{OverflowPolicy: "grow"}, numberedLines(1))
+	st.errChan = make(chan error, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	st.streamStdout(ctx)
+
+	select {
+	case err := <-st.errChan:
+		if !strings.Contains(err.Error(), `unknown overflow policy "grow"`) {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	default:
+		t.Error("Expected an error for an unknown policy")
+	}
+}
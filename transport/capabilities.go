@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sync"
+)
+
+// flagFallback describes how to adapt an optional flag that the installed
+// CLI doesn't support: by switching to an alias, or by dropping it.
+type flagFallback struct {
+	// alias is an equivalent spelling of the flag to try instead
+	alias string
+
+	// option names the Options field that is ignored if the flag is dropped
+	option string
+}
+
+// optionalFlags lists the flags that can be adapted or dropped when
+// Options.ProbeCLIFlags finds the CLI lacks them. Other flags are always
+// passed, since the query could not behave as requested without them.
+var optionalFlags = map[string]flagFallback{
+	"--allowedTools":           {alias: "--allowed-tools", option: "AllowedTools"},
+	"--disallowedTools":        {alias: "--disallowed-tools", option: "DisallowedTools"},
+	"--append-system-prompt":   {option: "AppendSystemPrompt"},
+	"--max-turns":              {option: "MaxTurns"},
+	"--fallback-model":         {option: "FallbackModel"},
+	"--permission-prompt-tool": {option: "PermissionPromptToolName"},
+}
+
+// valueFlags lists the flags built by buildArgs that take a value.
+var valueFlags = map[string]bool{
+	"--system-prompt":          true,
+	"--append-system-prompt":   true,
+	"--allowedTools":           true,
+	"--disallowedTools":        true,
+	"--max-turns":              true,
+	"--resume":                 true,
+	"--model":                  true,
+	"--fallback-model":         true,
+	"--permission-mode":        true,
+	"--permission-prompt-tool": true,
+	"--mcp-config":             true,
+	"--settings":               true,
+	"--add-dir":                true,
+	"--agents":                 true,
+	"--plugin-dir":             true,
+	"--input-format":           true,
+	"--output-format":          true,
+	"--print":                  true,
+}
+
+var flagPattern = regexp.MustCompile(`--[a-zA-Z][a-zA-Z-]*`)
+
+// flagCache holds the flags supported by CLI binaries that have already
+// been probed, keyed by path.
+var flagCache sync.Map
+
+// probeCLIFlags runs "claude --help" and returns the set of flags it lists.
+func probeCLIFlags(ctx context.Context, cliPath string) (map[string]bool, error) {
+	if flags, ok := flagCache.Load(cliPath); ok {
+		return flags.(map[string]bool), nil
+	}
+
+	output, err := exec.CommandContext(ctx, cliPath, "--help").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe CLI flags: %w", err)
+	}
+
+	flags := make(map[string]bool)
+	for _, flag := range flagPattern.FindAllString(string(output), -1) {
+		flags[flag] = true
+	}
+	if len(flags) == 0 {
+		return nil, fmt.Errorf("failed to probe CLI flags: no flags found in help output")
+	}
+
+	flagCache.Store(cliPath, flags)
+	return flags, nil
+}
+
+// adaptArgs rewrites args for a CLI supporting the given flags, switching
+// optional flags to a supported alias or dropping them. It returns the
+// adapted arguments and a description of each change.
+func adaptArgs(args []string, supported map[string]bool) ([]string, []string) {
+	adapted := make([]string, 0, len(args))
+	var changes []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		fallback, optional := optionalFlags[arg]
+		if !optional || supported[arg] {
+			adapted = append(adapted, arg)
+			continue
+		}
+
+		if fallback.alias != "" && supported[fallback.alias] {
+			adapted = append(adapted, fallback.alias)
+			continue
+		}
+
+		changes = append(changes, fmt.Sprintf("CLI does not support %s; option %s was ignored", arg, fallback.option))
+		if valueFlags[arg] {
+			i++
+		}
+	}
+
+	return adapted, changes
+}
+
+// probeFlags applies Options.ProbeCLIFlags, recording the flags supported
+// by the CLI at cliPath for buildCommand. A failed probe is reported as a
+// warning and leaves the arguments unchanged.
+func (st *SubprocessTransport) probeFlags(ctx context.Context, cliPath string) {
+	opts := st.config.Options
+	if opts == nil || !opts.ProbeCLIFlags {
+		return
+	}
+
+	flags, err := probeCLIFlags(ctx, cliPath)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err != nil {
+		st.warnings = append(st.warnings, err.Error())
+		return
+	}
+	st.supportedFlags = flags
+}
@@ -0,0 +1,123 @@
+package transport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestAdaptArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		supported []string
+		expected  []string
+		changes   int
+	}{
+		{
+			name:      "supported flags kept",
+			args:      []string{"--max-turns", "3", "--model", "sonnet"},
+			supported: []string{"--max-turns", "--model"},
+			expected:  []string{"--max-turns", "3", "--model", "sonnet"},
+		},
+		{
+			name:      "alias used",
+			args:      []string{"--allowedTools", "Read,Write"},
+			supported: []string{"--allowed-tools"},
+			expected:  []string{"--allowed-tools", "Read,Write"},
+		},
+		{
+			name:      "unsupported optional flag dropped with its value",
+			args:      []string{"--permission-prompt-tool", "mcp__auth", "--print", "hi"},
+			supported: []string{"--print"},
+			expected:  []string{"--print", "hi"},
+			changes:   1,
+		},
+		{
+			name:      "unsupported fallback model dropped",
+			args:      []string{"--model", "opus", "--fallback-model", "sonnet", "--print", "hi"},
+			supported: []string{"--model", "--print"},
+			expected:  []string{"--model", "opus", "--print", "hi"},
+			changes:   1,
+		},
+		{
+			name:      "required flags always kept",
+			args:      []string{"--resume", "abc", "--fork-session"},
+			supported: []string{},
+			expected:  []string{"--resume", "abc", "--fork-session"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supported := make(map[string]bool)
+			for _, flag := range tt.supported {
+				supported[flag] = true
+			}
+
+			got, changes := adaptArgs(tt.args, supported)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected args %v, got %v", tt.expected, got)
+			}
+			if len(changes) != tt.changes {
+				t.Errorf("Expected %d changes, got %v", tt.changes, changes)
+			}
+		})
+	}
+}
+
+func TestConnectProbesCLIFlags(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	help := `Usage: claude [options] [prompt]
+
+Options:
+  -p, --print                  Print response and exit
+  --output-format <format>     Output format
+  --verbose                    Verbose output
+  --allowed-tools <tools...>   Tools to allow
+  --model <model>              Model for the session`
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + help + "\nEOF\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	promptTool := "mcp__auth__prompt"
+	options := types.NewOptions().WithProbeCLIFlags(true).WithAllowedTools("Read")
+	options.PermissionPromptToolName = &promptTool
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: options,
+		CLIPath: cliPath,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	args := transport.cmd.Args
+	if !slices.Contains(args, "--allowed-tools") || slices.Contains(args, "--allowedTools") {
+		t.Errorf("Expected --allowed-tools alias, got %v", args)
+	}
+	if slices.Contains(args, "--permission-prompt-tool") || slices.Contains(args, "mcp__auth__prompt") {
+		t.Errorf("Expected --permission-prompt-tool to be dropped, got %v", args)
+	}
+	if warnings := transport.Warnings(); len(warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", warnings)
+	}
+}
@@ -0,0 +1,126 @@
+package transport
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cliEntrypoint is the CLI's JavaScript entrypoint relative to an npm
+// node_modules parent directory.
+var cliEntrypoint = filepath.Join("node_modules", "@anthropic-ai", "claude-code", "cli.js")
+
+// cliLauncher describes how to start the CLI found at a path.
+type cliLauncher struct {
+	// program is the executable to run
+	program string
+
+	// prefix holds arguments placed before the CLI's own, such as the
+	// script run by node
+	prefix []string
+
+	// viaShell runs program through cmd.exe, as Windows requires for
+	// batch files
+	viaShell bool
+}
+
+// newCLILauncher decides how to run the CLI at cliPath on the given OS.
+// JavaScript entrypoints are run with node. On Windows, npm's claude.cmd
+// shim is bypassed in favor of running its cli.js with node when possible,
+// since cmd.exe cannot pass every argument through unchanged.
+func newCLILauncher(cliPath, goos string) cliLauncher {
+	ext := strings.ToLower(filepath.Ext(cliPath))
+
+	switch {
+	case ext == ".js" || ext == ".mjs" || ext == ".cjs":
+		return nodeLauncher(cliPath)
+
+	case goos == "windows" && (ext == ".cmd" || ext == ".bat"):
+		script := filepath.Join(filepath.Dir(cliPath), cliEntrypoint)
+		if info, err := os.Stat(script); err == nil && !info.IsDir() {
+			return nodeLauncher(script)
+		}
+		return cliLauncher{program: cliPath, viaShell: true}
+	}
+
+	return cliLauncher{program: cliPath}
+}
+
+// nodeLauncher runs a JavaScript entrypoint with node.
+func nodeLauncher(script string) cliLauncher {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		// Let the command fail with a clear "node not found" error
+		node = "node"
+	}
+	return cliLauncher{program: node, prefix: []string{script}}
+}
+
+// canPass reports whether args can be passed to the CLI unchanged. Line
+// breaks end a cmd.exe command line, so they cannot be passed via the shell.
+func (l cliLauncher) canPass(args ...string) bool {
+	if !l.viaShell {
+		return true
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// cmdMetaChars matches characters cmd.exe interprets specially.
+var cmdMetaChars = regexp.MustCompile(`([()\][%!^"` + "`" + `<>&|;, *?])`)
+
+// quoteWindowsArg quotes arg so that CommandLineToArgvW, which most Windows
+// programs use to split their command line, recovers it unchanged.
+func quoteWindowsArg(arg string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	backslashes := 0
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			// Backslashes before a quote are escaped, as is the quote
+			b.WriteString(strings.Repeat(`\`, backslashes*2+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		b.WriteRune(r)
+	}
+
+	// Backslashes before the closing quote are escaped
+	b.WriteString(strings.Repeat(`\`, backslashes*2))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// quoteCmdArg quotes arg for a batch file run through cmd.exe. The quoted
+// argument has cmd.exe metacharacters escaped with carets twice: once for
+// cmd.exe parsing the command line, and once for the batch file passing
+// the argument on with %*.
+func quoteCmdArg(arg string) string {
+	quoted := quoteWindowsArg(arg)
+	quoted = cmdMetaChars.ReplaceAllString(quoted, "^$1")
+	return cmdMetaChars.ReplaceAllString(quoted, "^$1")
+}
+
+// cmdLine builds the command line that runs a batch file with args through
+// cmd.exe. /s makes cmd.exe strip only the outermost quotes, leaving the
+// escaped command intact.
+func cmdLine(comspec, batch string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, cmdMetaChars.ReplaceAllString(batch, "^$1"))
+	for _, arg := range args {
+		parts = append(parts, quoteCmdArg(arg))
+	}
+	return quoteWindowsArg(comspec) + ` /d /s /c "` + strings.Join(parts, " ") + `"`
+}
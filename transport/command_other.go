@@ -0,0 +1,11 @@
+//go:build !windows
+
+package transport
+
+import "os/exec"
+
+// shellCommand runs a batch file with args. Batch files only run through
+// cmd.exe on Windows, so elsewhere it is run directly.
+func shellCommand(batch string, args []string) *exec.Cmd {
+	return exec.Command(batch, args...)
+}
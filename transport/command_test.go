@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cmdArgs are arguments that are awkward to pass on Windows.
+var cmdArgs = []string{
+	"hello",
+	"",
+	`say "hi"`,
+	`caret ^ and percent %PATH% and !bang!`,
+	`pipes | & < > ( )`,
+	`C:\path with spaces\`,
+	`back\\"slash`,
+	"héllo 世界 🎉",
+}
+
+// unescapeCmd removes one level of cmd.exe caret escaping.
+func unescapeCmd(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '^' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitWindowsArgs splits a command line as CommandLineToArgvW does.
+func splitWindowsArgs(s string) []string {
+	var args []string
+	var b strings.Builder
+	inQuotes, inArg := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			n := 0
+			for i < len(s) && s[i] == '\\' {
+				n++
+				i++
+			}
+			if i < len(s) && s[i] == '"' {
+				b.WriteString(strings.Repeat(`\`, n/2))
+				if n%2 == 1 {
+					b.WriteByte('"')
+				} else {
+					inQuotes = !inQuotes
+				}
+			} else {
+				b.WriteString(strings.Repeat(`\`, n))
+				i--
+			}
+			inArg = true
+		case c == '"':
+			inQuotes = !inQuotes
+			inArg = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args
+}
+
+func TestQuoteWindowsArgRoundTrip(t *testing.T) {
+	for _, arg := range cmdArgs {
+		quoted := quoteWindowsArg(arg)
+		if got := splitWindowsArgs(quoted); len(got) != 1 || got[0] != arg {
+			t.Errorf("%q: quoted as %s, split back to %q", arg, quoted, got)
+		}
+	}
+}
+
+func TestQuoteCmdArgRoundTrip(t *testing.T) {
+	for _, arg := range cmdArgs {
+		quoted := quoteCmdArg(arg)
+
+		// cmd.exe removes one level of escaping when parsing the command
+		// line, and another when the batch file expands %*
+		passed := unescapeCmd(unescapeCmd(quoted))
+		if got := splitWindowsArgs(passed); len(got) != 1 || got[0] != arg {
+			t.Errorf("%q: quoted as %s, received as %q", arg, quoted, got)
+		}
+	}
+}
+
+func TestCmdLine(t *testing.T) {
+	line := cmdLine(`C:\Windows\system32\cmd.exe`, `C:\npm\claude.cmd`, []string{"--print", `a "b"`})
+	expected := `"C:\Windows\system32\cmd.exe" /d /s /c "C:\npm\claude.cmd ^^^"--print^^^" ^^^"a^^^ \^^^"b\^^^"^^^""`
+	if line != expected {
+		t.Errorf("Expected %s, got %s", expected, line)
+	}
+}
+
+func TestNewCLILauncher(t *testing.T) {
+	shimDir := t.TempDir()
+	script := filepath.Join(shimDir, cliEntrypoint)
+	if err := os.MkdirAll(filepath.Dir(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("// cli"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cliPath  string
+		goos     string
+		prefix   []string
+		viaShell bool
+	}{
+		{name: "binary", cliPath: "/usr/local/bin/claude", goos: "linux"},
+		{name: "javascript entrypoint", cliPath: "/opt/claude/cli.js", goos: "linux", prefix: []string{"/opt/claude/cli.js"}},
+		{name: "npm shim with cli.js", cliPath: filepath.Join(shimDir, "claude.cmd"), goos: "windows", prefix: []string{script}},
+		{name: "shim without cli.js", cliPath: filepath.Join(t.TempDir(), "claude.cmd"), goos: "windows", viaShell: true},
+		{name: "cmd file off Windows", cliPath: "/tmp/claude.cmd", goos: "linux"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			launcher := newCLILauncher(tt.cliPath, tt.goos)
+			if !reflect.DeepEqual(launcher.prefix, tt.prefix) {
+				t.Errorf("Expected prefix %v, got %v", tt.prefix, launcher.prefix)
+			}
+			if launcher.viaShell != tt.viaShell {
+				t.Errorf("Expected viaShell %v, got %v", tt.viaShell, launcher.viaShell)
+			}
+			if tt.prefix == nil && launcher.program != tt.cliPath {
+				t.Errorf("Expected program %s, got %s", tt.cliPath, launcher.program)
+			}
+		})
+	}
+}
+
+func TestCLILauncherCanPass(t *testing.T) {
+	shell := cliLauncher{program: "claude.cmd", viaShell: true}
+	if !shell.canPass(`quotes "and" ^carets^`) {
+		t.Error("Expected single-line argument to pass through cmd.exe")
+	}
+	if shell.canPass("two\nlines") {
+		t.Error("Expected line breaks not to pass through cmd.exe")
+	}
+	if !(cliLauncher{program: "claude"}).canPass("two\nlines") {
+		t.Error("Expected line breaks to pass when not using cmd.exe")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package transport
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand runs a batch file through cmd.exe with args escaped so it
+// receives them unchanged.
+func shellCommand(batch string, args []string) *exec.Cmd {
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+
+	cmd := exec.Command(comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: cmdLine(comspec, batch, args)}
+	return cmd
+}
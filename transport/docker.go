@@ -0,0 +1,217 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultDockerWorkDir is the container path where Options.Cwd is mounted
+// when running the CLI in a new container.
+const DefaultDockerWorkDir = "/workspace"
+
+// DefaultDockerPassEnv lists the host environment variables forwarded into
+// the container by default so the CLI can authenticate.
+var DefaultDockerPassEnv = []string{
+	"ANTHROPIC_API_KEY",
+	"ANTHROPIC_AUTH_TOKEN",
+	"ANTHROPIC_BASE_URL",
+	"ANTHROPIC_MODEL",
+	"CLAUDE_CODE_USE_BEDROCK",
+	"CLAUDE_CODE_USE_VERTEX",
+	"AWS_REGION",
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"CLOUD_ML_REGION",
+	"ANTHROPIC_VERTEX_PROJECT_ID",
+}
+
+// DockerConfig configures how the CLI is run inside a container.
+type DockerConfig struct {
+	// Container runs the CLI in an existing, running container via
+	// "docker exec". Options.Cwd is then interpreted as a path inside
+	// the container.
+	Container string
+
+	// Image starts a new, disposable container via "docker run --rm" when
+	// Container is empty. Options.Cwd is a host directory, bind-mounted at
+	// WorkDir.
+	Image string
+
+	// DockerPath is the path to the docker binary. Defaults to "docker".
+	DockerPath string
+
+	// CLIPath is the path to the Claude Code CLI inside the container.
+	// Defaults to "claude".
+	CLIPath string
+
+	// WorkDir is the working directory inside the container. For new
+	// containers it is where Options.Cwd is mounted; defaults to
+	// DefaultDockerWorkDir.
+	WorkDir string
+
+	// PassEnv lists host environment variables forwarded into the container
+	// when set. If nil, DefaultDockerPassEnv is used.
+	PassEnv []string
+
+	// Env sets additional environment variables inside the container.
+	// Values are passed through the docker client's environment rather than
+	// its arguments, so they do not appear in process listings.
+	Env map[string]string
+
+	// ExtraArgs are appended to the docker exec/run arguments before the
+	// container or image name, e.g. "--network", "none".
+	ExtraArgs []string
+}
+
+// DockerTransport runs the Claude Code CLI inside a Docker container.
+// It has the same streaming semantics as SubprocessTransport; only the
+// command used to start the CLI differs.
+type DockerTransport struct {
+	*SubprocessTransport
+	docker DockerConfig
+}
+
+// NewDockerTransport creates a transport that runs the CLI with docker exec
+// (when docker.Container is set) or docker run (when docker.Image is set).
+func NewDockerTransport(config *Config, docker DockerConfig) *DockerTransport {
+	return &DockerTransport{
+		SubprocessTransport: NewSubprocessTransport(config),
+		docker:              docker,
+	}
+}
+
+// Connect prepares the docker command for the CLI.
+func (dt *DockerTransport) Connect(ctx context.Context) error {
+	if dt.connected {
+		return nil
+	}
+
+	cmd, err := dt.buildDockerCommand()
+	if err != nil {
+		return fmt.Errorf("failed to build command: %w", err)
+	}
+
+	dt.cmd = cmd
+	dt.connected = true
+	return nil
+}
+
+// buildDockerCommand constructs the docker invocation wrapping the CLI.
+func (dt *DockerTransport) buildDockerCommand() (*exec.Cmd, error) {
+	if dt.docker.Container == "" && dt.docker.Image == "" {
+		return nil, fmt.Errorf("docker transport requires a container or an image")
+	}
+
+	cliArgs, err := dt.buildArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerPath := dt.docker.DockerPath
+	if dockerPath == "" {
+		dockerPath = "docker"
+	}
+	cliPath := dt.docker.CLIPath
+	if cliPath == "" {
+		cliPath = "claude"
+	}
+
+	opts := dt.config.Options
+	var args []string
+
+	if dt.docker.Container != "" {
+		args = append(args, "exec", "-i")
+		if opts.Cwd != nil {
+			args = append(args, "-w", *opts.Cwd)
+		} else if dt.docker.WorkDir != "" {
+			args = append(args, "-w", dt.docker.WorkDir)
+		}
+	} else {
+		workDir := dt.docker.WorkDir
+		if workDir == "" {
+			workDir = DefaultDockerWorkDir
+		}
+
+		args = append(args, "run", "--rm", "-i", "-w", workDir)
+		if opts.Cwd != nil {
+			hostDir, err := filepath.Abs(*opts.Cwd)
+			if err != nil {
+				return nil, fmt.Errorf("invalid working directory: %w", err)
+			}
+			args = append(args, "-v", hostDir+":"+workDir)
+		}
+	}
+
+	// Environment values are placed in the docker client's environment and
+	// forwarded by name, keeping credentials out of the argument list
+	env := append(os.Environ(), dt.buildEnv()...)
+	names := envNames(dt.buildEnv())
+
+	passEnv := dt.docker.PassEnv
+	if passEnv == nil {
+		passEnv = DefaultDockerPassEnv
+	}
+	for _, name := range passEnv {
+		if _, ok := os.LookupEnv(name); ok {
+			names = append(names, name)
+		}
+	}
+
+	extra := make([]string, 0, len(dt.docker.Env))
+	for name := range dt.docker.Env {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		env = append(env, name+"="+dt.docker.Env[name])
+		names = append(names, name)
+	}
+
+	for _, name := range dedupe(names) {
+		args = append(args, "-e", name)
+	}
+
+	args = append(args, dt.docker.ExtraArgs...)
+
+	if dt.docker.Container != "" {
+		args = append(args, dt.docker.Container)
+	} else {
+		args = append(args, dt.docker.Image)
+	}
+
+	args = append(args, cliPath)
+	args = append(args, cliArgs...)
+
+	cmd := exec.Command(dockerPath, args...)
+	cmd.Env = env
+	return cmd, nil
+}
+
+// envNames returns the variable names from KEY=VALUE pairs.
+func envNames(env []string) []string {
+	names := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// dedupe removes repeated strings, keeping the first occurrence.
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := values[:0:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestDockerTransportExec(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+
+	options := types.NewOptions().WithCwd("/src/project").WithModel("claude-sonnet-4")
+	transport := NewDockerTransport(&Config{Prompt: "hello", Options: options}, DockerConfig{
+		Container: "dev",
+		Env:       map[string]string{"FOO": "bar"},
+	})
+
+	if err := transport.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	args := strings.Join(transport.cmd.Args, " ")
+	for _, want := range []string{
+		"docker exec -i -w /src/project",
+		"-e CLAUDE_CODE_ENTRYPOINT",
+		"-e ANTHROPIC_API_KEY",
+		"-e FOO",
+		"dev claude --output-format stream-json --verbose",
+		"--model claude-sonnet-4",
+		"--print hello",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected args to contain %q, got %q", want, args)
+		}
+	}
+
+	// Credentials must not appear on the command line
+	if strings.Contains(args, "sk-test") || strings.Contains(args, "bar") {
+		t.Errorf("Environment values leaked into args: %q", args)
+	}
+
+	env := strings.Join(transport.cmd.Env, "\n")
+	if !strings.Contains(env, "FOO=bar") || !strings.Contains(env, "CLAUDE_CODE_ENTRYPOINT=sdk-go") {
+		t.Error("Expected env values to be set on the docker client")
+	}
+
+	// The working directory applies inside the container, not to docker itself
+	if transport.cmd.Dir != "" {
+		t.Errorf("Expected no host working directory, got %q", transport.cmd.Dir)
+	}
+}
+
+func TestDockerTransportRun(t *testing.T) {
+	options := types.NewOptions().WithCwd("/home/me/project")
+	transport := NewDockerTransport(&Config{Prompt: "hello", Options: options}, DockerConfig{
+		Image:      "ghcr.io/example/claude:latest",
+		DockerPath: "/usr/bin/podman",
+		CLIPath:    "/usr/local/bin/claude",
+		PassEnv:    []string{},
+		ExtraArgs:  []string{"--network", "none"},
+	})
+
+	if err := transport.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	args := transport.cmd.Args
+	if args[0] != "/usr/bin/podman" {
+		t.Errorf("Expected custom docker path, got %q", args[0])
+	}
+
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm -i -w /workspace -v /home/me/project:/workspace",
+		"--network none ghcr.io/example/claude:latest /usr/local/bin/claude --output-format",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected args to contain %q, got %q", want, joined)
+		}
+	}
+}
+
+func TestDockerTransportRequiresTarget(t *testing.T) {
+	transport := NewDockerTransport(&Config{Options: types.NewOptions()}, DockerConfig{})
+
+	if err := transport.Connect(context.Background()); err == nil {
+		t.Error("Expected error without container or image")
+	}
+	if transport.IsConnected() {
+		t.Error("Transport should not be connected after failed Connect")
+	}
+}
+
+func TestDockerTransportStreamingInput(t *testing.T) {
+	var _ InputTransport = &DockerTransport{}
+
+	transport := NewDockerTransport(&Config{Options: types.NewOptions(), StreamingInput: true}, DockerConfig{Container: "dev"})
+	if err := transport.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	args := strings.Join(transport.cmd.Args, " ")
+	if !strings.Contains(args, "--input-format stream-json") || strings.Contains(args, "--print") {
+		t.Errorf("Expected streaming input args, got %q", args)
+	}
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"encoding/json"
+)
+
+// DefaultSessionID is the session identifier used for stream-json input
+// when the caller has not been assigned one by the CLI yet.
+const DefaultSessionID = "default"
+
+// userInputMessage is the stream-json envelope for a user turn written to stdin.
+type userInputMessage struct {
+	Type            string           `json:"type"`
+	Message         userInputContent `json:"message"`
+	ParentToolUseID *string          `json:"parent_tool_use_id"`
+	SessionID       string           `json:"session_id"`
+}
+
+type userInputContent struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// EncodeUserMessage encodes a user turn in the CLI's stream-json input format.
+// Content is either a plain string or a slice of content blocks.
+// If sessionID is empty, DefaultSessionID is used.
+func EncodeUserMessage(content any, sessionID string) ([]byte, error) {
+	if sessionID == "" {
+		sessionID = DefaultSessionID
+	}
+
+	return json.Marshal(userInputMessage{
+		Type: "user",
+		Message: userInputContent{
+			Role:    "user",
+			Content: content,
+		},
+		SessionID: sessionID,
+	})
+}
@@ -0,0 +1,202 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestEncodeUserMessage(t *testing.T) {
+	data, err := EncodeUserMessage("Hello", "")
+	if err != nil {
+		t.Fatalf("EncodeUserMessage failed: %v", err)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if msg["type"] != "user" {
+		t.Errorf("Expected type 'user', got %v", msg["type"])
+	}
+	if msg["session_id"] != DefaultSessionID {
+		t.Errorf("Expected default session ID, got %v", msg["session_id"])
+	}
+	if v, ok := msg["parent_tool_use_id"]; !ok || v != nil {
+		t.Errorf("Expected null parent_tool_use_id, got %v", v)
+	}
+
+	inner := msg["message"].(map[string]any)
+	if inner["role"] != "user" || inner["content"] != "Hello" {
+		t.Errorf("Unexpected message body: %v", inner)
+	}
+}
+
+func TestBuildCommandStreamingInput(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Prompt:         "ignored",
+		Options:        types.NewOptions(),
+		StreamingInput: true,
+	})
+
+	cmd, err := transport.buildCommand("claude")
+	if err != nil {
+		t.Fatalf("buildCommand failed: %v", err)
+	}
+
+	args := cmd.Args[1:]
+	expected := []string{"--output-format", "stream-json", "--verbose", "--input-format", "stream-json"}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected args %v, got %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Arg %d: expected %q, got %q", i, expected[i], args[i])
+		}
+	}
+}
+
+func TestWriteWithoutStreamingInput(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{Options: types.NewOptions()})
+
+	err := transport.Write(context.Background(), []byte(`{}`))
+	var connErr *types.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("Expected *ConnectionError writing without streaming input, got %v", err)
+	}
+	if err := transport.CloseInput(); err != nil {
+		t.Errorf("CloseInput without stdin should be a no-op, got %v", err)
+	}
+}
+
+func TestWriteStreamingInputRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	// A fake CLI that echoes stdin back on stdout
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Options:        types.NewOptions(),
+		CLIPath:        cliPath,
+		StreamingInput: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	dataChan, errChan := transport.Stream(ctx)
+
+	msg, _ := EncodeUserMessage("ping", "")
+	if err := transport.Write(ctx, msg); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	select {
+	case line := <-dataChan:
+		if string(line) != string(msg) {
+			t.Errorf("Expected echoed message %s, got %s", msg, line)
+		}
+	case err := <-errChan:
+		t.Fatalf("Unexpected error: %v", err)
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for echo")
+	}
+
+	// Closing stdin lets the fake CLI exit cleanly
+	if err := transport.CloseInput(); err != nil {
+		t.Errorf("CloseInput failed: %v", err)
+	}
+	if err := transport.Write(ctx, msg); err == nil {
+		t.Error("Expected error writing after CloseInput")
+	}
+
+	for range dataChan {
+	}
+}
+
+func TestStreamingInputWritesPrompt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Larger than the pipe buffer, so the write must not block Stream
+	prompt := strings.Repeat("x", 256*1024)
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:         prompt,
+		Options:        types.NewOptions(),
+		CLIPath:        cliPath,
+		StreamingInput: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	dataChan, errChan := transport.Stream(ctx)
+
+	var lines [][]byte
+	for line := range dataChan {
+		lines = append(lines, line)
+	}
+	for err := range errChan {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// stdin is closed after the prompt, so the fake CLI exits after one line
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 echoed line, got %d", len(lines))
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(lines[0], &msg); err != nil {
+		t.Fatalf("Invalid JSON echoed: %v", err)
+	}
+	content := msg["message"].(map[string]any)["content"]
+	if content != prompt {
+		t.Error("Echoed prompt does not match")
+	}
+}
+
+func TestStreamWithoutConnect(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{Options: types.NewOptions()})
+
+	_, errChan := transport.Stream(context.Background())
+	select {
+	case err := <-errChan:
+		var connErr *types.ConnectionError
+		if !errors.As(err, &connErr) || !errors.Is(err, types.ErrCLIConnection) {
+			t.Errorf("Expected *ConnectionError, got %T: %v", err, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an error streaming before Connect")
+	}
+}
@@ -0,0 +1,297 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// CLIPackage is the npm package that provides the Claude Code CLI.
+const CLIPackage = "@anthropic-ai/claude-code"
+
+// EnsureOptions configures EnsureCLI.
+type EnsureOptions struct {
+	// Dir is the managed directory the CLI is installed into. If empty, a
+	// directory under the user's cache directory is used.
+	Dir string
+
+	// Version pins the CLI version, such as "1.0.51". CLIs of other
+	// versions are not used. If empty, any version is accepted and the
+	// latest is installed.
+	Version string
+
+	// SHA256 is the expected hex-encoded checksum of the package tarball,
+	// or of the file at DownloadURL. If set, the CLI is only installed when
+	// the checksum matches.
+	SHA256 string
+
+	// DownloadURL fetches the CLI from this URL instead of the npm
+	// registry. It may name an npm package tarball (.tgz) or a standalone
+	// CLI binary.
+	DownloadURL string
+
+	// ManagedOnly ignores CLIs found by discovery, so only the CLI in Dir
+	// is used.
+	ManagedOnly bool
+
+	// NPMPath is the npm binary used to install packages. If empty, npm is
+	// looked up on PATH.
+	NPMPath string
+
+	// HTTPClient is used for DownloadURL. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// installMu serializes installs within the process.
+var installMu sync.Mutex
+
+// EnsureCLI returns the path of a usable Claude Code CLI, installing it
+// into the managed directory if none is found. Unless ManagedOnly is set,
+// a CLI found by discovery is used when it satisfies the pinned version.
+//
+// Pass the returned path to ClientOptions.CLIPath or QueryWithCLIPath.
+func EnsureCLI(ctx context.Context, opts EnsureOptions) (string, error) {
+	dir, err := ensureDir(opts)
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.ManagedOnly {
+		if path, err := findCLI(nil); err == nil && versionMatches(ctx, path, opts.Version) {
+			return path, nil
+		}
+	}
+
+	installMu.Lock()
+	defer installMu.Unlock()
+
+	if path, ok := managedCLI(dir); ok && versionMatches(ctx, path, opts.Version) {
+		return path, nil
+	}
+
+	if err := installCLI(ctx, opts, dir); err != nil {
+		return "", fmt.Errorf("failed to install CLI: %w", err)
+	}
+
+	path, ok := managedCLI(dir)
+	if !ok {
+		return "", types.NewCLINotFoundError("CLI not found after install", dir)
+	}
+	if !versionMatches(ctx, path, opts.Version) {
+		return "", fmt.Errorf("installed CLI at %s is not version %s", path, opts.Version)
+	}
+	return path, nil
+}
+
+// ensureDir returns the managed directory for opts.
+func ensureDir(opts EnsureOptions) (string, error) {
+	if opts.Dir != "" {
+		return opts.Dir, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find managed CLI directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "claude-code-sdk-go", "cli"), nil
+}
+
+// managedCLI returns the CLI installed in dir. npm places global binaries
+// in dir/bin, except on Windows where they go in dir itself.
+func managedCLI(dir string) (string, bool) {
+	return findCLIIn([]string{filepath.Join(dir, "bin"), dir})
+}
+
+// versionMatches reports whether the CLI at path is the wanted version.
+// An empty version matches any CLI.
+func versionMatches(ctx context.Context, path, version string) bool {
+	if version == "" {
+		return true
+	}
+
+	want, err := types.ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	got, err := CLIVersion(ctx, path)
+	return err == nil && got == want
+}
+
+// installCLI installs the CLI described by opts into dir.
+func installCLI(ctx context.Context, opts EnsureOptions, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	spec := CLIPackage
+	if opts.Version != "" {
+		spec += "@" + opts.Version
+	}
+
+	var artifact string
+	switch {
+	case opts.DownloadURL != "":
+		path, err := download(ctx, opts, dir)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		artifact = path
+
+	case opts.SHA256 != "":
+		// Fetch the tarball so its checksum can be verified before install
+		path, err := npmPack(ctx, opts, dir, spec)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+		artifact = path
+	}
+
+	if artifact != "" {
+		if err := verifyChecksum(artifact, opts.SHA256); err != nil {
+			return err
+		}
+		if opts.DownloadURL != "" && !isTarball(opts.DownloadURL) {
+			return installBinary(artifact, dir)
+		}
+		spec = artifact
+	}
+
+	return runNPM(ctx, opts, dir, "install", "--global", "--prefix", dir, spec)
+}
+
+// download fetches opts.DownloadURL into a temporary file in dir.
+func download(ctx context.Context, opts EnsureOptions, dir string) (string, error) {
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.DownloadURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", opts.DownloadURL, resp.Status)
+	}
+
+	file, err := os.CreateTemp(dir, "download-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// npmPack downloads the package tarball for spec into dir.
+func npmPack(ctx context.Context, opts EnsureOptions, dir, spec string) (string, error) {
+	output, err := npmOutput(ctx, opts, dir, "pack", spec, "--pack-destination", dir)
+	if err != nil {
+		return "", err
+	}
+
+	// npm prints the tarball's file name last
+	lines := strings.Fields(strings.TrimSpace(output))
+	if len(lines) == 0 {
+		return "", fmt.Errorf("npm pack did not report a tarball")
+	}
+	return filepath.Join(dir, lines[len(lines)-1]), nil
+}
+
+// verifyChecksum checks that the file at path has the given SHA-256
+// checksum. An empty checksum is not checked.
+func verifyChecksum(path, want string) error {
+	if want == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", want, got)
+	}
+	return nil
+}
+
+// isTarball reports whether url names an npm package tarball.
+func isTarball(url string) bool {
+	return strings.HasSuffix(url, ".tgz") || strings.HasSuffix(url, ".tar.gz")
+}
+
+// installBinary moves a standalone CLI binary into dir/bin.
+func installBinary(path, dir string) error {
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+
+	name := "claude"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		return err
+	}
+	return os.Rename(path, filepath.Join(binDir, name))
+}
+
+// runNPM runs npm with args in dir.
+func runNPM(ctx context.Context, opts EnsureOptions, dir string, args ...string) error {
+	_, err := npmOutput(ctx, opts, dir, args...)
+	return err
+}
+
+// npmOutput runs npm with args in dir and returns its standard output.
+func npmOutput(ctx context.Context, opts EnsureOptions, dir string, args ...string) (string, error) {
+	npm := opts.NPMPath
+	if npm == "" {
+		var err error
+		npm, err = exec.LookPath("npm")
+		if err != nil {
+			return "", types.NewCLINotFoundError("npm not found: installing Claude Code requires Node.js", "")
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, npm, args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("npm %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnsureCLIDownload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	binary := []byte("#!/bin/sh\necho '1.0.51 (Claude Code)'\n")
+	sum := sha256.Sum256(binary)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		sha256  string
+		version string
+		wantErr string
+	}{
+		{name: "checksum matches", sha256: checksum, version: "1.0.51"},
+		{name: "checksum mismatch", sha256: strings.Repeat("0", 64), wantErr: "checksum mismatch"},
+		{name: "wrong version", version: "2.0.0", wantErr: "not version 2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			dir := t.TempDir()
+			path, err := EnsureCLI(ctx, EnsureOptions{
+				Dir:         dir,
+				Version:     tt.version,
+				SHA256:      tt.sha256,
+				DownloadURL: server.URL + "/claude",
+				ManagedOnly: true,
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureCLI failed: %v", err)
+			}
+			if path != filepath.Join(dir, "bin", "claude") {
+				t.Errorf("Expected CLI in managed directory, got %s", path)
+			}
+
+			// A second call reuses the installed CLI rather than downloading
+			again, err := EnsureCLI(ctx, EnsureOptions{
+				Dir:         dir,
+				Version:     tt.version,
+				DownloadURL: "http://127.0.0.1:0/claude",
+				ManagedOnly: true,
+			})
+			if err != nil || again != path {
+				t.Errorf("Expected installed CLI to be reused, got %s, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestEnsureCLINPMInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	// The fake npm records its arguments and installs a CLI under --prefix
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "npm-args")
+	npmPath := filepath.Join(tempDir, "npm")
+	script := `#!/bin/sh
+echo "$@" > ` + argsFile + `
+mkdir -p "$4/bin"
+printf '#!/bin/sh\necho 1.0.60\n' > "$4/bin/claude"
+chmod 755 "$4/bin/claude"
+`
+	if err := os.WriteFile(npmPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dir := filepath.Join(tempDir, "managed")
+	path, err := EnsureCLI(ctx, EnsureOptions{
+		Dir:         dir,
+		Version:     "1.0.60",
+		ManagedOnly: true,
+		NPMPath:     npmPath,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCLI failed: %v", err)
+	}
+	if path != filepath.Join(dir, "bin", "claude") {
+		t.Errorf("Expected CLI in managed directory, got %s", path)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "install --global --prefix " + dir + " " + CLIPackage + "@1.0.60\n"
+	if string(args) != expected {
+		t.Errorf("Expected npm %q, got %q", expected, args)
+	}
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// resourcePollInterval is how often the CLI's memory use is checked.
+var resourcePollInterval = 250 * time.Millisecond
+
+// checkLimits returns a UsageError if the options ask for a resource limit
+// that cannot be enforced on this platform.
+func checkLimits(opts *types.Options) error {
+	if opts == nil || opts.ResourceLimits == nil {
+		return nil
+	}
+	limits := opts.ResourceLimits
+
+	var problems []string
+	if limits.MaxMemoryBytes > 0 && !memoryLimitSupported {
+		problems = append(problems, "ResourceLimits.MaxMemoryBytes is not supported on "+runtime.GOOS)
+	}
+	if limits.Nice != 0 && !niceSupported {
+		problems = append(problems, "ResourceLimits.Nice is not supported on "+runtime.GOOS)
+	}
+	if len(problems) > 0 {
+		return &types.UsageError{Message: problems[0], Problems: problems}
+	}
+	return nil
+}
+
+// applyLimits sets the priority and memory cap of the started process.
+func (st *SubprocessTransport) applyLimits(pid int) error {
+	opts := st.config.Options
+	if opts == nil || opts.ResourceLimits == nil {
+		return nil
+	}
+	limits := opts.ResourceLimits
+
+	if limits.Nice != 0 {
+		if err := setNice(pid, limits.Nice); err != nil {
+			return fmt.Errorf("failed to set CLI priority to %d: %w", limits.Nice, err)
+		}
+	}
+	if limits.MaxMemoryBytes > 0 {
+		if err := setMemoryLimit(pid, limits.MaxMemoryBytes); err != nil {
+			return fmt.Errorf("failed to limit CLI memory to %d bytes: %w", limits.MaxMemoryBytes, err)
+		}
+	}
+	return nil
+}
+
+// enforceLimits watches the process until stop is closed. It returns a
+// channel that receives a ResourceLimitError if a limit is exceeded, or nil
+// if no limits need watching.
+func (st *SubprocessTransport) enforceLimits(pid int, stop <-chan struct{}) <-chan error {
+	opts := st.config.Options
+	if opts == nil || opts.ResourceLimits == nil {
+		return nil
+	}
+	limits := *opts.ResourceLimits
+
+	watchMemory := limits.MaxMemoryBytes > 0
+	if !watchMemory && limits.MaxWallClock <= 0 {
+		return nil
+	}
+
+	exceeded := make(chan error, 1)
+	go func() {
+		started := time.Now()
+
+		var deadline <-chan time.Time
+		if limits.MaxWallClock > 0 {
+			timer := time.NewTimer(limits.MaxWallClock)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+
+		var poll <-chan time.Time
+		if watchMemory {
+			ticker := time.NewTicker(resourcePollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
+
+		for {
+			select {
+			case <-stop:
+				return
+
+			case <-deadline:
+				exceeded <- &types.ResourceLimitError{
+					Resource: types.ResourceWallClock,
+					Limit:    int64(limits.MaxWallClock),
+					Used:     int64(time.Since(started)),
+				}
+				return
+
+			case <-poll:
+				if rss, ok := processRSS(pid); ok && rss > limits.MaxMemoryBytes {
+					exceeded <- &types.ResourceLimitError{
+						Resource: types.ResourceMemory,
+						Limit:    limits.MaxMemoryBytes,
+						Used:     rss,
+					}
+					return
+				}
+			}
+		}
+	}()
+	return exceeded
+}
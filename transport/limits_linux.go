@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// memoryLimitSupported reports whether ResourceLimits.MaxMemoryBytes can
+// be enforced on this platform.
+const memoryLimitSupported = true
+
+// processRSS returns the resident memory of a process in bytes.
+func processRSS(pid int) (int64, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0, false
+	}
+
+	// statm fields are in pages: size resident shared ...
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * int64(os.Getpagesize()), true
+}
+
+// setMemoryLimit caps the private writable memory of a running process
+// with RLIMIT_DATA, so that its allocations past the limit fail even
+// between polls of its resident memory. A lower existing limit is kept.
+func setMemoryLimit(pid int, limit int64) error {
+	var old syscall.Rlimit
+	if err := prlimit(pid, nil, &old); err != nil {
+		return err
+	}
+	bytes := uint64(limit)
+	if old.Max < bytes {
+		bytes = old.Max
+	}
+	return prlimit(pid, &syscall.Rlimit{Cur: bytes, Max: bytes}, nil)
+}
+
+// prlimit gets or sets the RLIMIT_DATA limit of another process.
+func prlimit(pid int, limit, old *syscall.Rlimit) error {
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), syscall.RLIMIT_DATA,
+		uintptr(unsafe.Pointer(limit)), uintptr(unsafe.Pointer(old)), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
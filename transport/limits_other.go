@@ -0,0 +1,20 @@
+//go:build !linux
+
+package transport
+
+import "errors"
+
+// memoryLimitSupported reports whether ResourceLimits.MaxMemoryBytes can
+// be enforced on this platform.
+const memoryLimitSupported = false
+
+// processRSS reports that memory use cannot be measured on this platform.
+func processRSS(pid int) (int64, bool) {
+	return 0, false
+}
+
+// setMemoryLimit reports that memory limits are not supported on this
+// platform. Connect rejects them before a process is started.
+func setMemoryLimit(pid int, limit int64) error {
+	return errors.New("memory limits are not supported on this platform")
+}
@@ -0,0 +1,174 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestResourceLimitsEnforced(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	tests := []struct {
+		name     string
+		limits   types.ResourceLimits
+		resource string
+	}{
+		{
+			name:     "wall clock",
+			limits:   types.ResourceLimits{MaxWallClock: 100 * time.Millisecond},
+			resource: types.ResourceWallClock,
+		},
+		{
+			// The shell's resident memory, which counts its shared
+			// libraries, exceeds 1MB while its own data stays under the cap
+			name:     "memory",
+			limits:   types.ResourceLimits{MaxMemoryBytes: 1 << 20},
+			resource: types.ResourceMemory,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.resource == types.ResourceMemory && runtime.GOOS != "linux" {
+				t.Skip("Memory limits are only enforced on Linux")
+			}
+
+			cliPath := filepath.Join(t.TempDir(), "claude")
+			if err := os.WriteFile(cliPath, []byte("#!/bin/sh\nsleep 5\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			transport := NewSubprocessTransport(&Config{
+				Prompt:  "hello",
+				Options: types.NewOptions().WithResourceLimits(tt.limits),
+				CLIPath: cliPath,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			if err := transport.Connect(ctx); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer transport.Close()
+
+			start := time.Now()
+			dataChan, errChan := transport.Stream(ctx)
+			for range dataChan {
+			}
+
+			var limitErr *types.ResourceLimitError
+			for err := range errChan {
+				if !errors.As(err, &limitErr) {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+
+			if limitErr == nil {
+				t.Fatal("Expected a ResourceLimitError")
+			}
+			if limitErr.Resource != tt.resource {
+				t.Errorf("Expected %s limit, got %s", tt.resource, limitErr.Resource)
+			}
+			if limitErr.Used <= limitErr.Limit {
+				t.Errorf("Expected usage %d above limit %d", limitErr.Used, limitErr.Limit)
+			}
+			if types.ErrorKind(limitErr) != types.KindResourceLimit {
+				t.Errorf("Expected KindResourceLimit, got %v", types.ErrorKind(limitErr))
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Expected the process to be killed promptly, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestSetNice(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Reads priority from /proc")
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	if err := setNice(cmd.Process.Pid, 10); err != nil {
+		t.Fatalf("setNice failed: %v", err)
+	}
+
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "stat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The nice value is the 19th field; the command name in field 2 has no spaces here
+	if fields := strings.Fields(string(stat)); len(fields) < 19 || fields[18] != "10" {
+		t.Errorf("Expected nice 10, got stat %q", stat)
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	if err := checkLimits(types.NewOptions().WithResourceLimits(types.ResourceLimits{MaxWallClock: time.Minute})); err != nil {
+		t.Errorf("Expected a wall clock limit to be accepted, got %v", err)
+	}
+
+	err := checkLimits(types.NewOptions().WithResourceLimits(types.ResourceLimits{MaxMemoryBytes: 1 << 30, Nice: 10}))
+	var usageErr *types.UsageError
+	switch {
+	case memoryLimitSupported && niceSupported:
+		if err != nil {
+			t.Errorf("Expected the limits to be accepted, got %v", err)
+		}
+	case !errors.As(err, &usageErr):
+		t.Errorf("Expected a UsageError on %s, got %v", runtime.GOOS, err)
+	}
+}
+
+func TestSetMemoryLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Reads limits from /proc")
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	if err := setMemoryLimit(cmd.Process.Pid, 64<<20); err != nil {
+		t.Fatalf("setMemoryLimit failed: %v", err)
+	}
+
+	limits, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "limits"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(limits), "\n") {
+		if strings.HasPrefix(line, "Max data size") {
+			if fields := strings.Fields(line); len(fields) < 5 || fields[3] != "67108864" || fields[4] != "67108864" {
+				t.Errorf("Expected a 64MB data limit, got %q", line)
+			}
+			return
+		}
+	}
+	t.Errorf("No data size limit in %s", limits)
+}
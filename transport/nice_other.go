@@ -0,0 +1,15 @@
+//go:build !unix
+
+package transport
+
+import "errors"
+
+// niceSupported reports whether ResourceLimits.Nice can be applied on this
+// platform.
+const niceSupported = false
+
+// setNice reports that scheduling priorities are not supported on this
+// platform.
+func setNice(pid, nice int) error {
+	return errors.New("process priority is not supported on this platform")
+}
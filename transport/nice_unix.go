@@ -0,0 +1,14 @@
+//go:build unix
+
+package transport
+
+import "syscall"
+
+// niceSupported reports whether ResourceLimits.Nice can be applied on this
+// platform.
+const niceSupported = true
+
+// setNice sets the scheduling priority of a process.
+func setNice(pid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+}
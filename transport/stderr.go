@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// Known CLI stderr patterns, matched against lowercased lines
+var (
+	authPatterns = []string{
+		"invalid api key",
+		"invalid x-api-key",
+		"authentication_error",
+		"oauth token has expired",
+		"please run /login",
+		"not logged in",
+		"api error: 401",
+	}
+
+	rateLimitPatterns = []string{
+		"rate_limit_error",
+		"rate limit",
+		"overloaded_error",
+		"api error: 429",
+		"api error: 529",
+	}
+
+	usagePatterns = []string{
+		"error: unknown option",
+		"error: too many arguments",
+		"error: required option",
+		"error: option '",
+		"error: missing required argument",
+	}
+
+	nodeVersionPattern = regexp.MustCompile(`node(\.js| version) .*(not supported|required|or higher)|requires node`)
+	retryAfterPattern  = regexp.MustCompile(`retry[- ]after[":= ]+(\d+)`)
+)
+
+// parseStderr converts the CLI's stderr output into a typed error when it
+// matches a known failure, or a generic error carrying the output otherwise.
+func parseStderr(lines []string) error {
+	output := strings.Join(lines, "\n")
+
+	var usageProblems []string
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		message := strings.TrimSpace(line)
+
+		if containsAny(lower, authPatterns) {
+			return &types.AuthError{Message: message, Stderr: output}
+		}
+
+		if containsAny(lower, rateLimitPatterns) {
+			err := &types.RateLimitError{Message: message, Stderr: output}
+			if m := retryAfterPattern.FindStringSubmatch(strings.ToLower(output)); m != nil {
+				if seconds, convErr := strconv.Atoi(m[1]); convErr == nil {
+					err.RetryAfter = time.Duration(seconds) * time.Second
+				}
+			}
+			return err
+		}
+
+		if containsAny(lower, usagePatterns) || nodeVersionPattern.MatchString(lower) {
+			usageProblems = append(usageProblems, strings.TrimPrefix(message, "error: "))
+		}
+	}
+
+	if len(usageProblems) > 0 {
+		return &types.UsageError{
+			Message:  usageProblems[0],
+			Problems: usageProblems,
+			Stderr:   output,
+		}
+	}
+
+	return fmt.Errorf("connection error: CLI stderr output: %s", output)
+}
+
+// containsAny reports whether s contains any of the patterns.
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
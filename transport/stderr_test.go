@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+func TestParseStderr(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		kind  types.Kind
+	}{
+		{"auth", []string{"Invalid API key · Please run /login"}, types.KindAuth},
+		{"rate limit", []string{`API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`}, types.KindRateLimit},
+		{"unknown option", []string{"error: unknown option '--bogus'"}, types.KindUsage},
+		{"node version", []string{"Claude Code requires Node.js version 18 or higher"}, types.KindUsage},
+		{"unrecognized", []string{"something went wrong"}, types.KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseStderr(tt.lines)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if got := types.ErrorKind(err); got != tt.kind {
+				t.Errorf("Expected kind %q, got %q (%v)", tt.kind, got, err)
+			}
+		})
+	}
+}
+
+func TestParseStderrRateLimitRetryAfter(t *testing.T) {
+	err := parseStderr([]string{"API Error: 429 rate limit exceeded", "retry-after: 30"})
+
+	var rateErr *types.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Expected RateLimitError, got %T", err)
+	}
+	if rateErr.RetryAfter != 30*time.Second {
+		t.Errorf("Expected RetryAfter 30s, got %v", rateErr.RetryAfter)
+	}
+	if rateErr.Stderr == "" {
+		t.Error("Expected stderr to be captured")
+	}
+}
+
+func TestParseStderrCollectsUsageProblems(t *testing.T) {
+	err := parseStderr([]string{
+		"error: unknown option '--foo'",
+		"error: unknown option '--bar'",
+	})
+
+	var usageErr *types.UsageError
+	if !errors.As(err, &usageErr) {
+		t.Fatalf("Expected UsageError, got %T", err)
+	}
+	if len(usageErr.Problems) != 2 || usageErr.Message != "unknown option '--foo'" {
+		t.Errorf("Unexpected usage error: %+v", usageErr)
+	}
+}
@@ -2,8 +2,10 @@ package transport
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jrossi/claude-code-sdk-golang/types"
 	"io"
@@ -11,9 +13,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // SubprocessTransport implements Transport using Claude Code CLI subprocess.
@@ -27,31 +32,83 @@ type SubprocessTransport struct {
 	doneChan chan struct{}
 
 	// Process pipes
+	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
 
+	// stderrOutput holds the CLI's stderr once it has been fully read, for
+	// inclusion in process errors
+	stderrOutput string
+
 	// State management
 	connected bool
 	streaming bool
 	mu        sync.RWMutex // Protects state
+	writeMu   sync.Mutex   // Serializes writes to stdin
+
+	// senders tracks goroutines other than waitForProcess that may send on
+	// errChan, so it is only closed once they have all finished
+	senders sync.WaitGroup
+
+	// readers tracks the stdout/stderr pipe readers; the process is only
+	// reaped once they are done, since Wait closes the pipes
+	readers sync.WaitGroup
+
+	// waitDone is closed when waitForProcess has reaped the process
+	waitDone chan struct{}
+
+	// warnings holds non-fatal problems found while connecting
+	warnings []string
+
+	// supportedFlags holds the CLI's flags when Options.ProbeCLIFlags is set
+	supportedFlags map[string]bool
+
+	// limitExceeded receives an error if the process exceeds one of
+	// Options.ResourceLimits
+	limitExceeded <-chan error
+
+	// dropped counts output lines discarded under OverflowDrop
+	dropped atomic.Int64
 }
 
 // NewSubprocessTransport creates a new subprocess transport with the given configuration.
 func NewSubprocessTransport(config *Config) *SubprocessTransport {
 	return &SubprocessTransport{
 		config:   config,
-		dataChan: make(chan []byte, 100), // Buffered for performance
+		dataChan: make(chan []byte, config.dataBufferSize()),
 		errChan:  make(chan error, 10),
 		doneChan: make(chan struct{}),
 	}
 }
 
+// Configure replaces the query-level fields of the transport's configuration.
+// It must be called before Connect.
+func (st *SubprocessTransport) Configure(config *Config) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	merged := Config{}
+	if st.config != nil {
+		merged = *st.config
+	}
+	merged.Prompt = config.Prompt
+	merged.PromptContent = config.PromptContent
+	merged.Options = config.Options
+	merged.StreamingInput = config.StreamingInput
+	merged.KeepInputOpen = config.KeepInputOpen
+	st.config = &merged
+}
+
 // Connect establishes connection by discovering CLI and preparing the command.
 func (st *SubprocessTransport) Connect(ctx context.Context) error {
 	if st.connected {
 		return nil
 	}
 
+	if err := checkLimits(st.config.Options); err != nil {
+		return err
+	}
+
 	// Discover CLI path if not specified
 	cliPath := st.config.CLIPath
 	if cliPath == "" {
@@ -62,6 +119,11 @@ func (st *SubprocessTransport) Connect(ctx context.Context) error {
 		}
 	}
 
+	if err := st.checkVersion(ctx, cliPath); err != nil {
+		return err
+	}
+	st.probeFlags(ctx, cliPath)
+
 	// Build command
 	cmd, err := st.buildCommand(cliPath)
 	if err != nil {
@@ -73,6 +135,14 @@ func (st *SubprocessTransport) Connect(ctx context.Context) error {
 	return nil
 }
 
+// Warnings returns non-fatal problems found while connecting, such as an
+// outdated CLI when Options.CLIVersionCheck is VersionCheckWarn.
+func (st *SubprocessTransport) Warnings() []string {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.warnings
+}
+
 // Stream starts the subprocess and returns channels for receiving data and errors.
 func (st *SubprocessTransport) Stream(ctx context.Context) (<-chan []byte, <-chan error) {
 	st.mu.Lock()
@@ -86,7 +156,7 @@ func (st *SubprocessTransport) Stream(ctx context.Context) (<-chan []byte, <-cha
 	if !st.connected || st.cmd == nil {
 		// Send error and return
 		go func() {
-			st.errChan <- fmt.Errorf("connection error: not connected")
+			st.errChan <- types.NewConnectionError("connection error: not connected", nil)
 		}()
 		return st.dataChan, st.errChan
 	}
@@ -96,7 +166,7 @@ func (st *SubprocessTransport) Stream(ctx context.Context) (<-chan []byte, <-cha
 	st.stdout, err = st.cmd.StdoutPipe()
 	if err != nil {
 		go func() {
-			st.errChan <- fmt.Errorf("connection error: failed to create stdout pipe: %w", err)
+			st.errChan <- types.NewConnectionError("connection error: failed to create stdout pipe", err)
 		}()
 		return st.dataChan, st.errChan
 	}
@@ -104,15 +174,33 @@ func (st *SubprocessTransport) Stream(ctx context.Context) (<-chan []byte, <-cha
 	st.stderr, err = st.cmd.StderrPipe()
 	if err != nil {
 		go func() {
-			st.errChan <- fmt.Errorf("connection error: failed to create stderr pipe: %w", err)
+			st.errChan <- types.NewConnectionError("connection error: failed to create stderr pipe", err)
 		}()
 		return st.dataChan, st.errChan
 	}
 
+	if st.config.StreamingInput {
+		st.stdin, err = st.cmd.StdinPipe()
+		if err != nil {
+			go func() {
+				st.errChan <- types.NewConnectionError("connection error: failed to create stdin pipe", err)
+			}()
+			return st.dataChan, st.errChan
+		}
+	}
+
 	// Start the process
 	if err := st.cmd.Start(); err != nil {
 		go func() {
-			st.errChan <- fmt.Errorf("connection error: failed to start CLI process: %w", err)
+			st.errChan <- types.NewConnectionError("connection error: failed to start CLI process", err)
+		}()
+		return st.dataChan, st.errChan
+	}
+	if err := st.applyLimits(st.cmd.Process.Pid); err != nil {
+		st.cmd.Process.Kill()
+		st.cmd.Wait()
+		go func() {
+			st.errChan <- types.NewConnectionError("connection error: failed to apply resource limits", err)
 		}()
 		return st.dataChan, st.errChan
 	}
@@ -120,8 +208,26 @@ func (st *SubprocessTransport) Stream(ctx context.Context) (<-chan []byte, <-cha
 	st.streaming = true
 
 	// Start goroutines for streaming
-	go st.streamStdout(ctx)
-	go st.streamStderr(ctx)
+	st.readers.Add(2)
+	st.senders.Add(1)
+	go func() {
+		defer st.readers.Done()
+		st.streamStdout(ctx)
+	}()
+	go func() {
+		defer st.readers.Done()
+		defer st.senders.Done()
+		st.streamStderr(ctx)
+	}()
+	if st.config.StreamingInput && (st.config.Prompt != "" || st.config.PromptContent != nil) {
+		st.senders.Add(1)
+		go func() {
+			defer st.senders.Done()
+			st.writePrompt(ctx)
+		}()
+	}
+	st.waitDone = make(chan struct{})
+	st.limitExceeded = st.enforceLimits(st.cmd.Process.Pid, st.waitDone)
 	go st.waitForProcess(ctx)
 
 	return st.dataChan, st.errChan
@@ -136,6 +242,7 @@ func (st *SubprocessTransport) Close() error {
 		return nil
 	}
 
+	wasStreaming := st.streaming
 	st.connected = false
 	st.streaming = false
 
@@ -147,6 +254,26 @@ func (st *SubprocessTransport) Close() error {
 		close(st.doneChan)
 	}
 
+	// Close stdin so the CLI sees end of input
+	st.writeMu.Lock()
+	if st.stdin != nil {
+		st.stdin.Close()
+		st.stdin = nil
+	}
+	st.writeMu.Unlock()
+
+	// Clean up command if it exists
+	if st.cmd != nil && st.cmd.Process != nil {
+		if wasStreaming && st.waitDone != nil {
+			<-st.waitDone // waitForProcess terminates and reaps the process
+		} else {
+			if err := st.cmd.Process.Kill(); err != nil {
+				// Process might already be dead
+			}
+			st.cmd.Wait() // Clean up zombie
+		}
+	}
+
 	// Close pipes if they exist
 	if st.stdout != nil {
 		st.stdout.Close()
@@ -155,14 +282,6 @@ func (st *SubprocessTransport) Close() error {
 		st.stderr.Close()
 	}
 
-	// Clean up command if it exists
-	if st.cmd != nil && st.cmd.Process != nil {
-		if err := st.cmd.Process.Kill(); err != nil {
-			// Process might already be dead
-		}
-		st.cmd.Wait() // Clean up zombie
-	}
-
 	return nil
 }
 
@@ -173,70 +292,305 @@ func (st *SubprocessTransport) IsConnected() bool {
 	return st.connected
 }
 
+// Interrupt sends an interrupt signal to the CLI process.
+// It is not supported on Windows.
+func (st *SubprocessTransport) Interrupt() error {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	if !st.streaming || st.cmd == nil || st.cmd.Process == nil {
+		return types.NewConnectionError("connection error: process not running", nil)
+	}
+	if err := st.cmd.Process.Signal(os.Interrupt); err != nil {
+		return fmt.Errorf("failed to interrupt CLI process: %w", err)
+	}
+	return nil
+}
+
+// Write sends a single JSON message to the CLI's stdin.
+// It is only available when the transport was configured with StreamingInput.
+func (st *SubprocessTransport) Write(ctx context.Context, data []byte) error {
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+
+	if st.stdin == nil {
+		return types.NewConnectionError("connection error: stdin not available (streaming input not enabled or input closed)", nil)
+	}
+
+	if len(data) == 0 || data[len(data)-1] != '\n' {
+		data = append(data[:len(data):len(data)], '\n')
+	}
+
+	// Write in a separate goroutine so a CLI that stops reading cannot block
+	// the caller past its context deadline
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := st.stdin.Write(data)
+		writeErr <- err
+	}()
+
+	select {
+	case err := <-writeErr:
+		if err != nil {
+			return types.NewConnectionError("connection error: failed to write to stdin", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-st.doneChan:
+		return types.NewConnectionError("connection error: transport closed", nil)
+	}
+}
+
+// CloseInput closes the CLI's stdin, signalling that no more input follows.
+func (st *SubprocessTransport) CloseInput() error {
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+
+	if st.stdin == nil {
+		return nil
+	}
+
+	err := st.stdin.Close()
+	st.stdin = nil
+	return err
+}
+
+// writePrompt sends the configured prompt as the first user message in
+// streaming input mode, then closes stdin unless KeepInputOpen is set.
+func (st *SubprocessTransport) writePrompt(ctx context.Context) {
+	var content any = st.config.Prompt
+	if st.config.PromptContent != nil {
+		content = st.config.PromptContent
+	}
+
+	data, err := EncodeUserMessage(content, "")
+	if err == nil {
+		err = st.Write(ctx, data)
+	}
+	if err == nil && !st.config.KeepInputOpen {
+		err = st.CloseInput()
+	}
+
+	if err != nil {
+		select {
+		case st.errChan <- types.NewConnectionError("connection error: failed to send prompt", err):
+		case <-ctx.Done():
+		case <-st.doneChan:
+		}
+	}
+}
+
 // discoverCLI attempts to find the Claude Code CLI binary.
 func (st *SubprocessTransport) discoverCLI() (string, error) {
-	// First try which/where command
+	var searchPaths []string
+	if opts := st.config.Options; opts != nil {
+		searchPaths = opts.CLISearchPaths
+	}
+	return findCLI(searchPaths)
+}
+
+// CLIPathEnvVar names the environment variable that sets the CLI binary
+// used when no path is configured, taking precedence over discovery.
+const CLIPathEnvVar = "CLAUDE_CLI_PATH"
+
+// cliBinaryNames returns the file names the CLI may be installed under.
+func cliBinaryNames() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"claude.cmd", "claude.exe", "claude"}
+	}
+	return []string{"claude"}
+}
+
+// findCLI locates the Claude Code CLI binary. It checks CLIPathEnvVar,
+// then searchPaths, which may name binaries or directories containing one,
+// then PATH, and finally common installation directories.
+func findCLI(searchPaths []string) (string, error) {
+	if path := os.Getenv(CLIPathEnvVar); path != "" {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return "", types.NewCLINotFoundError("CLI not found: "+CLIPathEnvVar+" does not name a file", path)
+		}
+		return path, nil
+	}
+
+	if path, ok := findCLIIn(searchPaths); ok {
+		return path, nil
+	}
+
+	// Then try which/where command
 	if path, err := exec.LookPath("claude"); err == nil {
 		return path, nil
 	}
 
-	// Common installation paths to check
-	var searchPaths []string
+	if path, ok := findCLIIn(defaultCLIDirs()); ok {
+		return path, nil
+	}
+
+	// Fall back to the JavaScript entrypoint of an npm install whose bin
+	// link is missing
+	if path, ok := findCLIEntrypoint(defaultCLIDirs()); ok {
+		return path, nil
+	}
+
+	// Check if Node.js is installed
+	if _, err := exec.LookPath("node"); err != nil {
+		return "", types.NewCLINotFoundError("CLI not found: Claude Code requires Node.js, which is not installed.\n\n"+
+			"Install Node.js from: https://nodejs.org/\n"+
+			"\nAfter installing Node.js, install Claude Code:\n"+
+			"  npm install -g @anthropic-ai/claude-code", "")
+	}
+
+	return "", types.NewCLINotFoundError("CLI not found: Claude Code not found. Install with:\n"+
+		"  npm install -g @anthropic-ai/claude-code\n"+
+		"\nIf already installed locally, try:\n"+
+		"  export PATH=\"$HOME/node_modules/.bin:$PATH\"\n"+
+		"\nOr specify the path when creating transport, with Options.CLISearchPaths,\n"+
+		"or with the "+CLIPathEnvVar+" environment variable", "")
+}
+
+// findCLIIn returns the first CLI binary found among paths, each of which
+// may be a binary or a directory containing one.
+func findCLIIn(paths []string) (string, bool) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			return path, true
+		}
+
+		for _, name := range cliBinaryNames() {
+			candidate := filepath.Join(path, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// findCLIEntrypoint returns the first cli.js found in the npm package
+// directories associated with bin directories: dir/node_modules on Windows
+// and dir/../lib/node_modules elsewhere.
+func findCLIEntrypoint(binDirs []string) (string, bool) {
+	for _, dir := range binDirs {
+		for _, candidate := range []string{
+			filepath.Join(dir, cliEntrypoint),
+			filepath.Join(filepath.Dir(dir), "lib", cliEntrypoint),
+		} {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// defaultCLIDirs returns the directories package managers and installers
+// commonly place the CLI in, for when it is not on PATH.
+func defaultCLIDirs() []string {
+	var dirs []string
 
 	homeDir, err := os.UserHomeDir()
 	if err == nil {
-		searchPaths = append(searchPaths,
-			filepath.Join(homeDir, ".npm-global", "bin", "claude"),
-			filepath.Join(homeDir, ".local", "bin", "claude"),
-			filepath.Join(homeDir, "node_modules", ".bin", "claude"),
-			filepath.Join(homeDir, ".yarn", "bin", "claude"),
+		dirs = append(dirs,
+			filepath.Join(homeDir, ".claude", "local"), // Claude Code's local installer
+			filepath.Join(homeDir, ".npm-global", "bin"),
+			filepath.Join(homeDir, ".local", "bin"),
+			filepath.Join(homeDir, "node_modules", ".bin"),
+			filepath.Join(homeDir, ".yarn", "bin"),
+			filepath.Join(homeDir, ".volta", "bin"),
+			filepath.Join(homeDir, ".asdf", "shims"),
+			filepath.Join(homeDir, ".bun", "bin"),
 		)
 	}
 
+	// pnpm global bin directory
+	if pnpmHome := os.Getenv("PNPM_HOME"); pnpmHome != "" {
+		dirs = append(dirs, pnpmHome)
+	} else if err == nil {
+		if runtime.GOOS == "darwin" {
+			dirs = append(dirs, filepath.Join(homeDir, "Library", "pnpm"))
+		} else {
+			dirs = append(dirs, filepath.Join(homeDir, ".local", "share", "pnpm"))
+		}
+	}
+
 	// System paths
-	searchPaths = append(searchPaths,
-		"/usr/local/bin/claude",
-		"/opt/homebrew/bin/claude", // macOS with Homebrew on Apple Silicon
+	dirs = append(dirs,
+		"/usr/local/bin",
+		"/opt/homebrew/bin",              // macOS with Homebrew on Apple Silicon
+		"/home/linuxbrew/.linuxbrew/bin", // Homebrew on Linux
 	)
 
 	// Windows-specific paths
 	if runtime.GOOS == "windows" {
 		if appData := os.Getenv("APPDATA"); appData != "" {
-			searchPaths = append(searchPaths,
-				filepath.Join(appData, "npm", "claude.cmd"),
-			)
+			dirs = append(dirs, filepath.Join(appData, "npm"))
 		}
 		if programFiles := os.Getenv("PROGRAMFILES"); programFiles != "" {
-			searchPaths = append(searchPaths,
-				filepath.Join(programFiles, "nodejs", "claude.cmd"),
-			)
+			dirs = append(dirs, filepath.Join(programFiles, "nodejs"))
 		}
 	}
 
-	// Check each path
-	for _, path := range searchPaths {
-		if info, err := os.Stat(path); err == nil && !info.IsDir() {
-			return path, nil
+	return dirs
+}
+
+// buildCommand constructs the CLI command with all options.
+func (st *SubprocessTransport) buildCommand(cliPath string) (*exec.Cmd, error) {
+	launcher := newCLILauncher(cliPath, runtime.GOOS)
+
+	// A prompt that can't be passed as an argument is sent over stdin
+	if !st.config.StreamingInput && !launcher.canPass(st.config.Prompt) {
+		config := *st.config
+		config.StreamingInput = true
+		st.config = &config
+	}
+
+	if err := validateAddDirs(st.config.Options); err != nil {
+		return nil, err
+	}
+
+	args, err := st.buildArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Adapt optional flags to what the CLI supports
+	if st.supportedFlags != nil {
+		var changes []string
+		args, changes = adaptArgs(args, st.supportedFlags)
+		st.mu.Lock()
+		st.warnings = append(st.warnings, changes...)
+		st.mu.Unlock()
+	}
+
+	// Create command
+	var cmd *exec.Cmd
+	if launcher.viaShell {
+		if !launcher.canPass(args...) {
+			return nil, fmt.Errorf("cannot pass options containing line breaks to %s through cmd.exe; "+
+				"set the CLI path to its cli.js instead", cliPath)
 		}
+		cmd = shellCommand(launcher.program, args)
+	} else {
+		cmd = exec.Command(launcher.program, append(launcher.prefix, args...)...)
 	}
 
-	// Check if Node.js is installed
-	if _, err := exec.LookPath("node"); err != nil {
-		return "", fmt.Errorf("CLI not found: Claude Code requires Node.js, which is not installed.\n\n" +
-			"Install Node.js from: https://nodejs.org/\n" +
-			"\nAfter installing Node.js, install Claude Code:\n" +
-			"  npm install -g @anthropic-ai/claude-code")
+	// Set working directory if specified
+	if opts := st.config.Options; opts.Cwd != nil {
+		cmd.Dir = *opts.Cwd
 	}
 
-	return "", fmt.Errorf("CLI not found: Claude Code not found. Install with:\n" +
-		"  npm install -g @anthropic-ai/claude-code\n" +
-		"\nIf already installed locally, try:\n" +
-		"  export PATH=\"$HOME/node_modules/.bin:$PATH\"\n" +
-		"\nOr specify the path when creating transport")
+	// Set environment
+	cmd.Env = append(os.Environ(), st.buildEnv()...)
+
+	return cmd, nil
 }
 
-// buildCommand constructs the CLI command with all options.
-func (st *SubprocessTransport) buildCommand(cliPath string) (*exec.Cmd, error) {
+// buildArgs constructs the CLI arguments for all options.
+func (st *SubprocessTransport) buildArgs() ([]string, error) {
 	args := []string{"--output-format", "stream-json", "--verbose"}
 
 	opts := st.config.Options
@@ -270,11 +624,20 @@ func (st *SubprocessTransport) buildCommand(cliPath string) (*exec.Cmd, error) {
 	if opts.Resume != nil {
 		args = append(args, "--resume", *opts.Resume)
 	}
+	if opts.ForkSession {
+		if opts.Resume == nil && !opts.ContinueConversation {
+			return nil, fmt.Errorf("fork session requires resume or continue conversation")
+		}
+		args = append(args, "--fork-session")
+	}
 
 	// Model and permissions
 	if opts.Model != nil {
 		args = append(args, "--model", *opts.Model)
 	}
+	if opts.FallbackModel != nil {
+		args = append(args, "--fallback-model", *opts.FallbackModel)
+	}
 	if opts.PermissionMode != nil {
 		args = append(args, "--permission-mode", string(*opts.PermissionMode))
 	}
@@ -282,7 +645,30 @@ func (st *SubprocessTransport) buildCommand(cliPath string) (*exec.Cmd, error) {
 		args = append(args, "--permission-prompt-tool", *opts.PermissionPromptToolName)
 	}
 
+	// Additional directories
+	for _, dir := range opts.AddDirs {
+		args = append(args, "--add-dir", expandPath(dir))
+	}
+
+	// Settings, compacted when inline so they pass through any shell
+	if opts.OutputStyle != nil {
+		settings, err := outputStyleSettings(opts)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--settings", settings)
+	} else if opts.Settings != nil {
+		settings, err := settingsArg(*opts.Settings)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--settings", settings)
+	}
+
 	// MCP configuration
+	for _, path := range opts.McpConfigFiles {
+		args = append(args, "--mcp-config", path)
+	}
 	if len(opts.McpServers) > 0 {
 		mcpConfig := map[string]any{
 			"mcpServers": st.convertMcpServers(opts.McpServers),
@@ -294,21 +680,161 @@ func (st *SubprocessTransport) buildCommand(cliPath string) (*exec.Cmd, error) {
 		args = append(args, "--mcp-config", string(mcpJSON))
 	}
 
-	// Add the prompt
-	args = append(args, "--print", st.config.Prompt)
+	// Subagents
+	if len(opts.Agents) > 0 {
+		for name, agent := range opts.Agents {
+			if agent.Description == "" || agent.Prompt == "" {
+				return nil, fmt.Errorf("agent %q requires a description and a prompt", name)
+			}
+		}
+		agentsJSON, err := json.Marshal(opts.Agents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal agents: %w", err)
+		}
+		args = append(args, "--agents", string(agentsJSON))
+	}
 
-	// Create command
-	cmd := exec.Command(cliPath, args...)
+	// Plugins
+	for _, plugin := range opts.Plugins {
+		if plugin.Type != types.PluginTypeLocal {
+			return nil, fmt.Errorf("unsupported plugin type %q", plugin.Type)
+		}
+		args = append(args, "--plugin-dir", expandPath(plugin.Path))
+	}
 
-	// Set working directory if specified
-	if opts.Cwd != nil {
-		cmd.Dir = *opts.Cwd
+	// Flags without an option of their own
+	flags := make([]string, 0, len(opts.ExtraArgs))
+	for flag := range opts.ExtraArgs {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	for _, flag := range flags {
+		args = append(args, "--"+strings.TrimPrefix(flag, "--"))
+		if value := opts.ExtraArgs[flag]; value != nil {
+			args = append(args, *value)
+		}
 	}
 
-	// Set environment
-	cmd.Env = append(os.Environ(), "CLAUDE_CODE_ENTRYPOINT=sdk-go")
+	// Add the prompt, or read messages from stdin in streaming input mode
+	if st.config.StreamingInput {
+		args = append(args, "--input-format", "stream-json")
+	} else {
+		args = append(args, "--print", st.config.Prompt)
+	}
 
-	return cmd, nil
+	return args, nil
+}
+
+// expandPath expands environment variables and a leading ~ in path.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// validateAddDirs checks that each additional directory exists, resolving
+// relative paths against the working directory the CLI will run in.
+func validateAddDirs(opts *types.Options) error {
+	if opts == nil {
+		return nil
+	}
+
+	for _, dir := range opts.AddDirs {
+		path := expandPath(dir)
+		if path == "" {
+			return fmt.Errorf("additional directory cannot be empty")
+		}
+		if !filepath.IsAbs(path) && opts.Cwd != nil {
+			path = filepath.Join(*opts.Cwd, path)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("additional directory %q: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("additional directory %q is not a directory", dir)
+		}
+	}
+	return nil
+}
+
+// settingsArg returns the --settings value for a settings file path or an
+// inline JSON document, validating and compacting inline JSON.
+func settingsArg(settings string) (string, error) {
+	trimmed := strings.TrimSpace(settings)
+	if !strings.HasPrefix(trimmed, "{") {
+		return settings, nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(trimmed)); err != nil {
+		return "", fmt.Errorf("invalid settings JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// outputStyleSettings returns an inline settings document selecting the
+// output style, merged with the settings file or document in opts.Settings.
+// A relative settings path is read from Cwd, as the CLI would.
+func outputStyleSettings(opts *types.Options) (string, error) {
+	settings := make(map[string]any)
+	if opts.Settings != nil {
+		data := []byte(strings.TrimSpace(*opts.Settings))
+		if !bytes.HasPrefix(data, []byte("{")) {
+			path := expandPath(*opts.Settings)
+			if !filepath.IsAbs(path) && opts.Cwd != nil {
+				path = filepath.Join(*opts.Cwd, path)
+			}
+			var err error
+			if data, err = os.ReadFile(path); err != nil {
+				return "", fmt.Errorf("failed to read settings: %w", err)
+			}
+		}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return "", fmt.Errorf("invalid settings JSON: %w", err)
+		}
+	}
+
+	settings["outputStyle"] = *opts.OutputStyle
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// buildEnv returns the environment variables set for the CLI in addition to
+// the inherited environment. Later entries take precedence, so these
+// override any ambient values.
+func (st *SubprocessTransport) buildEnv() []string {
+	env := []string{"CLAUDE_CODE_ENTRYPOINT=sdk-go"}
+
+	opts := st.config.Options
+	if opts == nil {
+		return env
+	}
+
+	// Per-query credentials
+	if opts.APIKey != nil {
+		env = append(env, "ANTHROPIC_API_KEY="+*opts.APIKey)
+	}
+	if opts.AuthToken != nil {
+		env = append(env, "ANTHROPIC_AUTH_TOKEN="+*opts.AuthToken)
+	}
+	if opts.BaseURL != nil {
+		env = append(env, "ANTHROPIC_BASE_URL="+*opts.BaseURL)
+	}
+
+	if opts.AutoCompact != nil && !*opts.AutoCompact {
+		env = append(env, "DISABLE_AUTO_COMPACT=1")
+	}
+
+	return env
 }
 
 // convertMcpServers converts Go MCP server configs to the format expected by CLI.
@@ -356,6 +882,9 @@ func (st *SubprocessTransport) convertMcpServers(servers map[string]types.McpSer
 }
 
 // streamStdout reads from stdout and sends data to the data channel.
+// A line longer than the buffer limit is reported as a BufferOverflowError
+// and skipped, and reading continues with the next line, so the CLI never
+// blocks writing to a pipe nobody reads.
 func (st *SubprocessTransport) streamStdout(ctx context.Context) {
 	defer func() {
 		// Close channels when done streaming
@@ -366,11 +895,19 @@ func (st *SubprocessTransport) streamStdout(ctx context.Context) {
 		return
 	}
 
-	scanner := bufio.NewScanner(st.stdout)
-	// Set a larger buffer for scanner to handle large JSON lines
-	const maxCapacity = 1024 * 1024 // 1MB per line
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
+	deliver, finish, err := st.newDeliverer(ctx)
+	if err != nil {
+		select {
+		case st.errChan <- types.NewConnectionError("connection error", err):
+		case <-ctx.Done():
+		case <-st.doneChan:
+		}
+		return
+	}
+	defer finish()
+
+	maxLineSize := st.maxBufferSize()
+	reader := bufio.NewReaderSize(st.stdout, min(64*1024, maxLineSize))
 
 	for {
 		select {
@@ -379,42 +916,106 @@ func (st *SubprocessTransport) streamStdout(ctx context.Context) {
 		case <-st.doneChan:
 			return
 		default:
-			// Continue scanning
+			// Continue reading
 		}
 
-		if !scanner.Scan() {
-			// Check for error or EOF
-			if err := scanner.Err(); err != nil {
+		line, size, err := readLine(reader, maxLineSize)
+		switch {
+		case size > maxLineSize:
+			overflow := &types.BufferOverflowError{Size: size, Limit: maxLineSize, Partial: line}
+			select {
+			case st.errChan <- overflow:
+			case <-ctx.Done():
+				return
+			case <-st.doneChan:
+				return
+			}
+		case len(line) > 0:
+			if !deliver(line) {
+				return
+			}
+		}
+
+		if err != nil {
+			// Closed pipes mean the process was stopped deliberately,
+			// which is reported elsewhere.
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
 				select {
-				case st.errChan <- fmt.Errorf("connection error: error reading stdout: %w", err):
+				case st.errChan <- types.NewConnectionError("connection error: error reading stdout", err):
 				case <-ctx.Done():
 				case <-st.doneChan:
 				}
 			}
 			return
 		}
+	}
+}
 
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
+// linePool holds the scratch buffers readLine assembles long lines in.
+// Lines are handed to the data channel's reader, so they cannot be pooled
+// themselves; the scratch buffer lets each line be copied out exactly once
+// rather than regrown for every piece read.
+var linePool = sync.Pool{New: func() any { return new([]byte) }}
+
+// maxPooledLine is the largest scratch buffer returned to linePool, so that
+// one huge line does not pin its memory.
+const maxPooledLine = 1024 * 1024
+
+// readLine reads the next line from r without its line ending. Only the
+// first limit bytes of a longer line are kept, but the whole line is
+// consumed; size is its full length.
+func readLine(r *bufio.Reader, limit int) (line []byte, size int, err error) {
+	chunk, err := r.ReadSlice('\n')
+	if !errors.Is(err, bufio.ErrBufferFull) {
+		// The whole line is in the reader's buffer
+		if err == nil {
+			chunk = bytes.TrimSuffix(chunk[:len(chunk)-1], []byte("\r"))
 		}
+		if len(chunk) > 0 {
+			line = bytes.Clone(chunk[:min(limit, len(chunk))])
+		}
+		return line, len(chunk), err
+	}
 
-		// Make a copy of the line data since scanner reuses the buffer
-		lineCopy := make([]byte, len(line))
-		copy(lineCopy, line)
+	scratch := linePool.Get().(*[]byte)
+	buf := (*scratch)[:0]
+	defer func() {
+		if cap(buf) <= maxPooledLine {
+			*scratch = buf
+			linePool.Put(scratch)
+		}
+	}()
 
-		// Send the line to the data channel
-		select {
-		case st.dataChan <- lineCopy:
-		case <-ctx.Done():
-			return
-		case <-st.doneChan:
-			return
+	for {
+		if err == nil {
+			chunk = bytes.TrimSuffix(chunk[:len(chunk)-1], []byte("\r"))
+		}
+
+		size += len(chunk)
+		if room := limit - len(buf); room > 0 {
+			buf = append(buf, chunk[:min(room, len(chunk))]...)
+		}
+
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return bytes.Clone(buf), size, err
 		}
+		chunk, err = r.ReadSlice('\n')
+	}
+}
+
+// maxBufferSize returns the longest line accepted from stdout.
+func (st *SubprocessTransport) maxBufferSize() int {
+	if opts := st.config.Options; opts != nil && opts.MaxBufferSize != nil && *opts.MaxBufferSize > 0 {
+		return *opts.MaxBufferSize
+	}
+	if st.config.MaxBufferSize > 0 {
+		return st.config.MaxBufferSize
 	}
+	return DefaultMaxBufferSize
 }
 
-// streamStderr reads from stderr and collects error output.
+// streamStderr reads from stderr and reports the collected output as an
+// error once the process closes it.
 func (st *SubprocessTransport) streamStderr(ctx context.Context) {
 	if st.stderr == nil {
 		return
@@ -438,10 +1039,12 @@ func (st *SubprocessTransport) streamStderr(ctx context.Context) {
 		if !scanner.Scan() {
 			// EOF or error - process stderr content
 			if len(stderrLines) > 0 {
-				stderrOutput := strings.Join(stderrLines, "\n")
-				// Send stderr as a connection error (non-blocking)
+				// Read by waitForProcess after the readers are done
+				st.stderrOutput = strings.Join(stderrLines, "\n")
+
+				// Send stderr as a typed error when recognized (non-blocking)
 				select {
-				case st.errChan <- fmt.Errorf("connection error: CLI stderr output: %s", stderrOutput):
+				case st.errChan <- parseStderr(stderrLines):
 				case <-ctx.Done():
 				case <-st.doneChan:
 				}
@@ -463,38 +1066,97 @@ func (st *SubprocessTransport) streamStderr(ctx context.Context) {
 	}
 }
 
+// killAndReleasePipes kills the process and closes the output pipes so
+// readers blocked on output held open by child processes are released.
+func (st *SubprocessTransport) killAndReleasePipes() {
+	if st.cmd.Process != nil {
+		st.cmd.Process.Kill()
+	}
+	if st.stdout != nil {
+		st.stdout.Close()
+	}
+	if st.stderr != nil {
+		st.stderr.Close()
+	}
+}
+
+// terminate stops the process and waits for it to exit. With a termination
+// grace period, the process is first interrupted so the CLI can save its
+// session, and only killed if it is still running once the period ends.
+func (st *SubprocessTransport) terminate(exited <-chan error) {
+	grace := st.terminationGracePeriod()
+	if grace > 0 && st.cmd.Process != nil {
+		// Interrupt is not supported on Windows; fall through to kill
+		if err := st.cmd.Process.Signal(os.Interrupt); err == nil {
+			timer := time.NewTimer(grace)
+			defer timer.Stop()
+
+			select {
+			case <-exited:
+				return
+			case <-timer.C:
+			}
+		}
+	}
+
+	st.killAndReleasePipes()
+	<-exited // Wait for process to actually exit
+}
+
+// terminationGracePeriod returns how long to wait after interrupting the
+// process before killing it.
+func (st *SubprocessTransport) terminationGracePeriod() time.Duration {
+	if st.config.Options == nil || st.config.Options.TerminationGracePeriod == nil {
+		return 0
+	}
+	return *st.config.Options.TerminationGracePeriod
+}
+
 // waitForProcess waits for the subprocess to complete and handles exit codes.
 func (st *SubprocessTransport) waitForProcess(ctx context.Context) {
 	defer func() {
-		// Close error channel when process monitoring is done
+		// Close error channel once process monitoring and all other
+		// senders are done
+		st.senders.Wait()
 		close(st.errChan)
 	}()
 
+	if st.waitDone != nil {
+		defer close(st.waitDone)
+	}
+
 	if st.cmd == nil {
 		return
 	}
 
-	// Wait for process in a separate goroutine to allow context cancellation
+	// Wait for process in a separate goroutine to allow context cancellation.
+	// Output must be fully read before Wait, which closes the pipes.
 	processErrChan := make(chan error, 1)
 	go func() {
+		st.readers.Wait()
 		processErrChan <- st.cmd.Wait()
 	}()
 
 	select {
 	case <-ctx.Done():
-		// Context cancelled, kill the process
-		if st.cmd.Process != nil {
-			st.cmd.Process.Kill()
-		}
-		<-processErrChan // Wait for process to actually exit
+		// Context cancelled, stop the process
+		st.terminate(processErrChan)
 		return
 
 	case <-st.doneChan:
-		// Transport closed, kill the process
-		if st.cmd.Process != nil {
-			st.cmd.Process.Kill()
+		// Transport closed, stop the process
+		st.terminate(processErrChan)
+		return
+
+	case err := <-st.limitExceeded:
+		// Resource limit exceeded, kill the process
+		st.killAndReleasePipes()
+		<-processErrChan
+		select {
+		case st.errChan <- err:
+		case <-ctx.Done():
+		case <-st.doneChan:
 		}
-		<-processErrChan // Wait for process to actually exit
 		return
 
 	case err := <-processErrChan:
@@ -502,7 +1164,12 @@ func (st *SubprocessTransport) waitForProcess(ctx context.Context) {
 		if err != nil {
 			// Process failed
 			if exitErr, ok := err.(*exec.ExitError); ok {
-				processErr := fmt.Errorf("process error: CLI process failed with exit code %d: %w", exitErr.ExitCode(), err)
+				processErr := &types.ProcessError{
+					Message:  "CLI process failed",
+					ExitCode: exitErr.ExitCode(),
+					Stderr:   st.stderrOutput,
+					Err:      err,
+				}
 
 				// Send process error (non-blocking)
 				select {
@@ -513,7 +1180,7 @@ func (st *SubprocessTransport) waitForProcess(ctx context.Context) {
 			} else {
 				// Other error
 				select {
-				case st.errChan <- fmt.Errorf("connection error: process wait failed: %w", err):
+				case st.errChan <- types.NewConnectionError("connection error: process wait failed", err):
 				case <-ctx.Done():
 				case <-st.doneChan:
 				}
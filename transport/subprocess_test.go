@@ -1,10 +1,20 @@
 package transport
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	types2 "github.com/jrossi/claude-code-sdk-golang/types"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewSubprocessTransport(t *testing.T) {
@@ -43,6 +53,7 @@ func TestCLIDiscovery(t *testing.T) {
 }
 
 func TestCommandBuilding(t *testing.T) {
+	beta := "context-1m"
 	tests := []struct {
 		name     string
 		options  *types2.Options
@@ -90,6 +101,18 @@ func TestCommandBuilding(t *testing.T) {
 				"--print", "test prompt",
 			},
 		},
+		{
+			name: "with fallback model",
+			options: types2.NewOptions().
+				WithModel("opus").
+				WithFallbackModel("sonnet"),
+			expected: []string{
+				"--output-format", "stream-json", "--verbose",
+				"--model", "opus",
+				"--fallback-model", "sonnet",
+				"--print", "test prompt",
+			},
+		},
 		{
 			name: "with permission mode",
 			options: types2.NewOptions().
@@ -100,6 +123,28 @@ func TestCommandBuilding(t *testing.T) {
 				"--print", "test prompt",
 			},
 		},
+		{
+			name: "with MCP config files",
+			options: types2.NewOptions().
+				WithMcpConfigFile(".mcp.json").
+				WithMcpConfigFile("/etc/claude/mcp.json"),
+			expected: []string{
+				"--output-format", "stream-json", "--verbose",
+				"--mcp-config", ".mcp.json",
+				"--mcp-config", "/etc/claude/mcp.json",
+				"--print", "test prompt",
+			},
+		},
+		{
+			name: "with plan mode",
+			options: types2.NewOptions().
+				WithPermissionMode(types2.PermissionModePlan),
+			expected: []string{
+				"--output-format", "stream-json", "--verbose",
+				"--permission-mode", "plan",
+				"--print", "test prompt",
+			},
+		},
 		{
 			name: "with continue conversation and resume",
 			options: types2.NewOptions().
@@ -112,6 +157,61 @@ func TestCommandBuilding(t *testing.T) {
 				"--print", "test prompt",
 			},
 		},
+		{
+			name: "with fork session",
+			options: types2.NewOptions().
+				WithResume("session_123").
+				WithForkSession(true),
+			expected: []string{
+				"--resume", "session_123",
+				"--fork-session",
+			},
+		},
+		{
+			name:    "with settings file",
+			options: types2.NewOptions().WithSettings("/etc/claude/settings.json"),
+			expected: []string{
+				"--settings", "/etc/claude/settings.json",
+			},
+		},
+		{
+			name: "with inline settings",
+			options: types2.NewOptions().WithSettingsJSON([]byte(`{
+				"sandbox": {"enabled": true}
+			}`)),
+			expected: []string{
+				"--settings", `{"sandbox":{"enabled":true}}`,
+			},
+		},
+		{
+			name: "with extra args",
+			options: types2.NewOptions().
+				WithModel("sonnet").
+				WithExtraArgs(map[string]*string{"--debug": nil, "betas": &beta}),
+			expected: []string{
+				"--output-format", "stream-json", "--verbose",
+				"--model", "sonnet",
+				"--betas", "context-1m",
+				"--debug",
+				"--print", "test prompt",
+			},
+		},
+		{
+			name:    "with output style",
+			options: types2.NewOptions().WithOutputStyle("Explanatory"),
+			expected: []string{
+				"--settings", `{"outputStyle":"Explanatory"}`,
+			},
+		},
+		{
+			name: "with output style and inline settings",
+			options: types2.NewOptions().
+				WithSettingsJSON([]byte(`{"sandbox": {"enabled": true}, "outputStyle": "default"}`)).
+				WithOutputStyle("Learning"),
+			expected: []string{
+				"--settings", `{"outputStyle":"Learning","sandbox":{"enabled":true}}`,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,3 +362,634 @@ func TestTransportLifecycle(t *testing.T) {
 		t.Fatalf("Double close failed: %v", err)
 	}
 }
+
+func TestConfigureKeepsTransportSettings(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		CLIPath:       "/opt/claude",
+		MaxBufferSize: 4096,
+	})
+
+	options := types2.NewOptions()
+	transport.Configure(&Config{
+		Prompt:         "hello",
+		Options:        options,
+		StreamingInput: true,
+		CLIPath:        "/ignored",
+	})
+
+	if transport.config.Prompt != "hello" || transport.config.Options != options {
+		t.Errorf("Expected query fields to be replaced, got %+v", transport.config)
+	}
+	if !transport.config.StreamingInput {
+		t.Error("Expected StreamingInput to be set")
+	}
+	if transport.config.CLIPath != "/opt/claude" || transport.config.MaxBufferSize != 4096 {
+		t.Errorf("Expected transport settings to be kept, got %+v", transport.config)
+	}
+}
+
+func TestBuildEnvCredentials(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Options: types2.NewOptions().
+			WithAPIKey("sk-test").
+			WithBaseURL("https://gateway.example.com"),
+	})
+
+	env := transport.buildEnv()
+	expected := []string{
+		"CLAUDE_CODE_ENTRYPOINT=sdk-go",
+		"ANTHROPIC_API_KEY=sk-test",
+		"ANTHROPIC_BASE_URL=https://gateway.example.com",
+	}
+	if len(env) != len(expected) {
+		t.Fatalf("Expected env %v, got %v", expected, env)
+	}
+	for i := range expected {
+		if env[i] != expected[i] {
+			t.Errorf("Env %d: expected %q, got %q", i, expected[i], env[i])
+		}
+	}
+
+	// Credentials must not appear on the command line
+	cmd, err := transport.buildCommand("claude")
+	if err != nil {
+		t.Fatalf("buildCommand failed: %v", err)
+	}
+	for _, arg := range cmd.Args {
+		if arg == "sk-test" {
+			t.Error("API key leaked into command arguments")
+		}
+	}
+	if cmd.Env[len(cmd.Env)-1] != "ANTHROPIC_BASE_URL=https://gateway.example.com" {
+		t.Errorf("Expected credentials to override ambient env, got %v", cmd.Env[len(cmd.Env)-3:])
+	}
+}
+
+func TestBuildEnvAutoCompact(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *types2.Options
+		disabled bool
+	}{
+		{"default", types2.NewOptions(), false},
+		{"enabled", types2.NewOptions().WithAutoCompact(true), false},
+		{"disabled", types2.NewOptions().WithAutoCompact(false), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := NewSubprocessTransport(&Config{Options: tt.options}).buildEnv()
+			if got := slices.Contains(env, "DISABLE_AUTO_COMPACT=1"); got != tt.disabled {
+				t.Errorf("Expected auto-compaction disabled %v, got env %v", tt.disabled, env)
+			}
+		})
+	}
+}
+
+func TestForkSessionRequiresResume(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().WithForkSession(true),
+	})
+
+	if _, err := transport.buildArgs(); err == nil {
+		t.Error("Expected error for fork session without resume")
+	}
+}
+
+func TestAddDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	project := t.TempDir()
+	t.Setenv("PROJECT_ROOT", project)
+
+	for _, dir := range []string{filepath.Join(home, "docs"), filepath.Join(project, "lib")} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(project, "README.md")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().WithCwd(project).WithAddDirs("~/docs", "$PROJECT_ROOT/lib", "lib"),
+	})
+	cmd, err := transport.buildCommand("/fake/claude")
+	if err != nil {
+		t.Fatalf("buildCommand failed: %v", err)
+	}
+
+	var dirs []string
+	for i, arg := range cmd.Args {
+		if arg == "--add-dir" && i+1 < len(cmd.Args) {
+			dirs = append(dirs, cmd.Args[i+1])
+		}
+	}
+	want := []string{filepath.Join(home, "docs"), filepath.Join(project, "lib"), "lib"}
+	if strings.Join(dirs, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected --add-dir values %v, got %v", want, dirs)
+	}
+
+	invalid := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"missing", filepath.Join(project, "missing"), "additional directory"},
+		{"file", file, "is not a directory"},
+		{"empty", "", "cannot be empty"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := NewSubprocessTransport(&Config{
+				Prompt:  "test prompt",
+				Options: types2.NewOptions().WithAddDirs(tt.dir),
+			})
+			if _, err := transport.buildCommand("/fake/claude"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAgentsArg(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Prompt: "test prompt",
+		Options: types2.NewOptions().AddAgent("reviewer", types2.AgentDefinition{
+			Description: "Reviews code changes",
+			Prompt:      "You review Go code.",
+			Tools:       []string{"Read", "Grep"},
+			Model:       "sonnet",
+		}),
+	})
+
+	args, err := transport.buildArgs()
+	if err != nil {
+		t.Fatalf("buildArgs failed: %v", err)
+	}
+
+	var agents map[string]map[string]any
+	for i, arg := range args {
+		if arg == "--agents" && i+1 < len(args) {
+			if err := json.Unmarshal([]byte(args[i+1]), &agents); err != nil {
+				t.Fatalf("Invalid --agents JSON %q: %v", args[i+1], err)
+			}
+		}
+	}
+	reviewer := agents["reviewer"]
+	if reviewer["description"] != "Reviews code changes" || reviewer["prompt"] != "You review Go code." || reviewer["model"] != "sonnet" {
+		t.Errorf("Unexpected agent definition: %v", reviewer)
+	}
+	if tools, _ := reviewer["tools"].([]any); len(tools) != 2 {
+		t.Errorf("Expected 2 tools, got %v", reviewer["tools"])
+	}
+
+	transport = NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().AddAgent("empty", types2.AgentDefinition{Description: "No prompt"}),
+	})
+	if _, err := transport.buildArgs(); err == nil {
+		t.Error("Expected error for agent without a prompt")
+	}
+}
+
+func TestPluginArgs(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Prompt: "test prompt",
+		Options: types2.NewOptions().WithPlugins(
+			types2.PluginConfig{Type: types2.PluginTypeLocal, Path: "/plugins/deploy"},
+			types2.PluginConfig{Type: types2.PluginTypeLocal, Path: "/plugins/lint"},
+		),
+	})
+
+	args, err := transport.buildArgs()
+	if err != nil {
+		t.Fatalf("buildArgs failed: %v", err)
+	}
+	if joined := strings.Join(args, " "); !strings.Contains(joined, "--plugin-dir /plugins/deploy --plugin-dir /plugins/lint") {
+		t.Errorf("Expected --plugin-dir for each plugin, got %v", args)
+	}
+
+	transport = NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().WithPlugins(types2.PluginConfig{Type: "marketplace", Path: "deploy"}),
+	})
+	if _, err := transport.buildArgs(); err == nil {
+		t.Error("Expected error for unsupported plugin type")
+	}
+}
+
+func TestInvalidSettingsJSON(t *testing.T) {
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().WithSettingsJSON([]byte(`{"sandbox": `)),
+	})
+
+	if _, err := transport.buildArgs(); err == nil || !strings.Contains(err.Error(), "invalid settings JSON") {
+		t.Errorf("Expected invalid settings error, got %v", err)
+	}
+}
+
+func TestOutputStyleMergesSettingsFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(`{"model": "sonnet"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "test prompt",
+		Options: types2.NewOptions().WithCwd(dir).WithSettings("settings.json").WithOutputStyle("Explanatory"),
+	})
+	args, err := transport.buildArgs()
+	if err != nil {
+		t.Fatalf("buildArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(args, " "), `--settings {"model":"sonnet","outputStyle":"Explanatory"}`) {
+		t.Errorf("Expected merged settings, got %v", args)
+	}
+
+	transport.config.Options.WithSettings("missing.json")
+	if _, err := transport.buildArgs(); err == nil || !strings.Contains(err.Error(), "failed to read settings") {
+		t.Errorf("Expected settings read error, got %v", err)
+	}
+}
+
+func TestProcessErrorFromExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\necho 'something broke' >&2\nexit 3\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: types2.NewOptions(),
+		CLIPath: cliPath,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	dataChan, errChan := transport.Stream(ctx)
+	for range dataChan {
+	}
+
+	var procErr *types2.ProcessError
+	for err := range errChan {
+		errors.As(err, &procErr)
+	}
+
+	if procErr == nil {
+		t.Fatal("Expected a ProcessError")
+	}
+	if procErr.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", procErr.ExitCode)
+	}
+	if procErr.Stderr != "something broke" {
+		t.Errorf("Expected captured stderr, got %q", procErr.Stderr)
+	}
+	if !types2.IsRetryable(procErr) {
+		t.Error("Expected process failure to be retryable")
+	}
+}
+
+func TestCloseInterruptsBeforeKill(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupt not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "saved")
+	cliPath := filepath.Join(dir, "claude")
+	script := "#!/bin/sh\ntrap 'echo saved > " + marker + "; exit 0' INT\nwhile :; do sleep 0.05; done\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: types2.NewOptions().WithTerminationGracePeriod(5 * time.Second),
+		CLIPath: cliPath,
+	})
+
+	ctx := context.Background()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	transport.Stream(ctx)
+	time.Sleep(100 * time.Millisecond) // Let the script install its trap
+
+	start := time.Now()
+	if err := transport.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected CLI to exit on interrupt, Close took %v", elapsed)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("Expected CLI to handle the interrupt before exiting")
+	}
+}
+
+func TestCloseKillsAfterGracePeriod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupt not supported on Windows")
+	}
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\ntrap '' INT\nwhile :; do sleep 0.05; done\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: types2.NewOptions().WithTerminationGracePeriod(200 * time.Millisecond),
+		CLIPath: cliPath,
+	})
+
+	ctx := context.Background()
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	transport.Stream(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		transport.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected process to be killed after the grace period")
+	}
+}
+
+func TestInterruptSignalsProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupt not supported on Windows")
+	}
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\ntrap 'echo \"{\\\"type\\\":\\\"result\\\"}\"; exit 0' INT\nwhile :; do sleep 0.05; done\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: types2.NewOptions(),
+		CLIPath: cliPath,
+	})
+
+	if err := transport.Interrupt(); err == nil {
+		t.Error("Expected error interrupting before the process starts")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	dataChan, _ := transport.Stream(ctx)
+	time.Sleep(100 * time.Millisecond) // Let the script install its trap
+
+	if err := transport.Interrupt(); err != nil {
+		t.Fatalf("Interrupt failed: %v", err)
+	}
+
+	select {
+	case line, ok := <-dataChan:
+		if !ok || string(line) != `{"type":"result"}` {
+			t.Errorf("Expected final result after interrupt, got %q", line)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for output after interrupt")
+	}
+}
+
+func TestMaxBufferSizePrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected int
+	}{
+		{"default", &Config{Options: types2.NewOptions()}, DefaultMaxBufferSize},
+		{"config", &Config{Options: types2.NewOptions(), MaxBufferSize: 2048}, 2048},
+		{"options", &Config{Options: types2.NewOptions().WithMaxBufferSize(4096), MaxBufferSize: 2048}, 4096},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSubprocessTransport(tt.config).maxBufferSize(); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaxBufferSizeAllowsLargeLines(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	// A fake CLI that prints a single 2MB line, over the default limit
+	dir := t.TempDir()
+	payload := filepath.Join(dir, "payload")
+	if err := os.WriteFile(payload, []byte(strings.Repeat("x", 2*1024*1024)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cliPath := filepath.Join(dir, "claude")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\ncat "+payload+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewSubprocessTransport(&Config{
+		Prompt:  "hello",
+		Options: types2.NewOptions().WithMaxBufferSize(4 * 1024 * 1024),
+		CLIPath: cliPath,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer transport.Close()
+
+	dataChan, errChan := transport.Stream(ctx)
+	var lines int
+	for line := range dataChan {
+		if len(line) != 2*1024*1024 {
+			t.Errorf("Expected a 2MB line, got %d bytes", len(line))
+		}
+		lines++
+	}
+	for err := range errChan {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if lines != 1 {
+		t.Errorf("Expected 1 line, got %d", lines)
+	}
+}
+
+func TestStreamStdoutSkipsOversizedLines(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	input := "first\r\n" + long + "\n" + "second\n" + long + "tail"
+
+	transport := NewSubprocessTransport(&Config{Options: types2.NewOptions().WithMaxBufferSize(32)})
+	transport.dataChan = make(chan []byte, 10)
+	transport.errChan = make(chan error, 10)
+	transport.doneChan = make(chan struct{})
+	transport.stdout = &mockPipe{data: []byte(input)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	transport.streamStdout(ctx)
+	close(transport.errChan)
+
+	var lines []string
+	for line := range transport.dataChan {
+		lines = append(lines, string(line))
+	}
+	if strings.Join(lines, ",") != "first,second" {
+		t.Errorf("Expected lines around the oversized ones, got %q", lines)
+	}
+
+	var sizes []int
+	for err := range transport.errChan {
+		var overflow *types2.BufferOverflowError
+		if !errors.As(err, &overflow) {
+			t.Fatalf("Expected *BufferOverflowError, got %v", err)
+		}
+		if overflow.Limit != 32 || string(overflow.Partial) != long[:32] {
+			t.Errorf("Unexpected overflow: limit %d, partial %q", overflow.Limit, overflow.Partial)
+		}
+		if types2.ErrorKind(err) != types2.KindBufferOverflow {
+			t.Errorf("Expected KindBufferOverflow, got %v", types2.ErrorKind(err))
+		}
+		sizes = append(sizes, overflow.Size)
+	}
+	if len(sizes) != 2 || sizes[0] != 100 || sizes[1] != 104 {
+		t.Errorf("Expected overflows of 100 and 104 bytes, got %v", sizes)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	// A reader smaller than the lines exercises reading a line in pieces
+	reader := bufio.NewReaderSize(strings.NewReader(strings.Repeat("a", 40)+"\r\nshort\nlast"), 16)
+
+	tests := []struct {
+		line string
+		size int
+		err  error
+	}{
+		{strings.Repeat("a", 20), 40, nil},
+		{"short", 5, nil},
+		{"last", 4, io.EOF},
+	}
+	for _, tt := range tests {
+		line, size, err := readLine(reader, 20)
+		if string(line) != tt.line || size != tt.size || err != tt.err {
+			t.Errorf("readLine = %q, %d, %v; want %q, %d, %v", line, size, err, tt.line, tt.size, tt.err)
+		}
+	}
+}
+
+func TestDiscoverCLISearchOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	writeCLI := func(dir string) string {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "claude")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tempDir := t.TempDir()
+	envCLI := writeCLI(filepath.Join(tempDir, "env"))
+	searchCLI := writeCLI(filepath.Join(tempDir, "search"))
+	pathCLI := writeCLI(filepath.Join(tempDir, "path"))
+	t.Setenv("PATH", filepath.Dir(pathCLI))
+
+	tests := []struct {
+		name        string
+		env         string
+		searchPaths []string
+		expected    string
+		wantErr     bool
+	}{
+		{name: "PATH", expected: pathCLI},
+		{name: "search directory", searchPaths: []string{filepath.Join(tempDir, "missing"), filepath.Dir(searchCLI)}, expected: searchCLI},
+		{name: "search binary", searchPaths: []string{searchCLI}, expected: searchCLI},
+		{name: "environment variable", env: envCLI, searchPaths: []string{searchCLI}, expected: envCLI},
+		{name: "environment variable missing file", env: filepath.Join(tempDir, "nope"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CLIPathEnvVar, tt.env)
+
+			transport := NewSubprocessTransport(&Config{
+				Options: types2.NewOptions().WithCLISearchPaths(tt.searchPaths...),
+			})
+			path, err := transport.discoverCLI()
+			if tt.wantErr {
+				if types2.ErrorKind(err) != types2.KindCLINotFound {
+					t.Errorf("Expected CLI not found error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("discoverCLI failed: %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, path)
+			}
+		})
+	}
+}
+
+// BenchmarkReadLine benchmarks reading 256KB lines through a 64KB reader,
+// as streamStdout reads large messages.
+func BenchmarkReadLine(b *testing.B) {
+	data := []byte(strings.Repeat(strings.Repeat("a", 256*1024)+"\n", 16))
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		reader := bufio.NewReaderSize(bytes.NewReader(data), 64*1024)
+		for {
+			if _, _, err := readLine(reader, DefaultMaxBufferSize); err != nil {
+				break
+			}
+		}
+	}
+}
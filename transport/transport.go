@@ -29,11 +29,76 @@ type Transport interface {
 	IsConnected() bool
 }
 
+// InputTransport is implemented by transports that can write stream-json
+// messages to the CLI's stdin. It is required for interactive sessions and
+// for the control protocol (interrupts, hooks, permission callbacks).
+type InputTransport interface {
+	Transport
+
+	// Write sends a single JSON message to the CLI's stdin.
+	// A trailing newline is added if data does not already end with one.
+	Write(ctx context.Context, data []byte) error
+
+	// CloseInput closes the CLI's stdin, signalling that no more input follows.
+	// It should be safe to call multiple times.
+	CloseInput() error
+}
+
+// Interrupter is implemented by transports that can interrupt the CLI
+// process directly, for use when the control protocol is unavailable.
+type Interrupter interface {
+	// Interrupt signals the CLI to stop its current work, as if the user
+	// pressed Ctrl+C.
+	Interrupt() error
+}
+
+// Warner is implemented by transports that can report non-fatal problems
+// found while connecting. Clients deliver them as SystemMessages with
+// subtype types.SystemSubtypeWarning at the start of the stream.
+type Warner interface {
+	// Warnings returns the problems found by Connect.
+	Warnings() []string
+}
+
+// Configurable is implemented by transports that accept the prompt and
+// options of the query they serve. Clients call Configure before Connect
+// when a caller supplies its own transport instance.
+type Configurable interface {
+	// Configure replaces the query-level fields of the transport's
+	// configuration (Prompt, Options, StreamingInput, and KeepInputOpen).
+	// Transport-level settings such as CLIPath are kept.
+	Configure(config *Config)
+}
+
+// MaxPromptArgLength is the longest prompt passed on the command line.
+// Longer prompts should be sent over stdin in streaming input mode to stay
+// clear of argv limits (notably the 32K command line limit on Windows).
+const MaxPromptArgLength = 16 * 1024
+
+// DefaultMaxBufferSize is the longest line read from the CLI's stdout when
+// no limit is configured.
+const DefaultMaxBufferSize = 1024 * 1024 // 1MB
+
 // Config contains configuration for creating a transport.
 type Config struct {
 	// Prompt is the user prompt to send to Claude.
 	Prompt string
 
+	// PromptContent, if set, is sent instead of Prompt as the content of
+	// the first user message, such as the content blocks of a multi-part
+	// prompt. It requires StreamingInput.
+	PromptContent any
+
+	// StreamingInput runs the CLI with --input-format stream-json and reads
+	// messages from stdin instead of passing Prompt with --print. If Prompt is
+	// non-empty it is written to stdin as the first user message.
+	StreamingInput bool
+
+	// KeepInputOpen leaves stdin open after Prompt has been written in
+	// streaming input mode, so further messages can be sent with Write.
+	// When false, stdin is closed once the prompt is written.
+	KeepInputOpen bool
+
 	// Options contains query options.
 	Options *types.Options
 
@@ -46,9 +111,23 @@ type Config struct {
 	Timeout string
 
 	// MaxBufferSize specifies the maximum size for internal buffers.
-	// If zero, a reasonable default will be used.
+	// If zero, DefaultMaxBufferSize is used. Options.MaxBufferSize takes
+	// precedence when set.
 	MaxBufferSize int
 
+	// DataBufferSize is the number of output lines the data channel
+	// returned by Stream holds for a slow reader. If zero,
+	// DefaultDataBufferSize is used.
+	DataBufferSize int
+
+	// OverflowPolicy decides what happens to output lines when the data
+	// channel is full. If empty, OverflowBlock is used.
+	OverflowPolicy OverflowPolicy
+
+	// SpillDir is the directory for the temporary file used by
+	// OverflowSpill. If empty, the default temporary directory is used.
+	SpillDir string
+
 	// Stdout and Stderr can be set for testing to capture CLI output.
 	// In normal operation, these should be nil.
 	Stdout io.Writer
@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// MinimumCLIVersion is the oldest CLI version the SDK supports.
+var MinimumCLIVersion = types.Version{Major: 1, Minor: 0, Patch: 0}
+
+// cliRequirement is a CLI feature used by some configurations and the
+// version that introduced it.
+type cliRequirement struct {
+	feature string
+	version types.Version
+	uses    func(config *Config) bool
+}
+
+// cliRequirements lists the CLI features the SDK relies on beyond those of
+// MinimumCLIVersion.
+var cliRequirements = []cliRequirement{
+	{
+		feature: "--input-format stream-json",
+		version: types.Version{Major: 1, Minor: 0, Patch: 20},
+		uses:    func(config *Config) bool { return config.StreamingInput },
+	},
+	{
+		feature: "--permission-mode",
+		version: types.Version{Major: 1, Minor: 0, Patch: 12},
+		uses:    func(config *Config) bool { return config.Options.PermissionMode != nil },
+	},
+	{
+		feature: "--mcp-config",
+		version: types.Version{Major: 1, Minor: 0, Patch: 12},
+		uses: func(config *Config) bool {
+			return len(config.Options.McpServers) > 0 || len(config.Options.McpConfigFiles) > 0
+		},
+	},
+	{
+		feature: "--fork-session",
+		version: types.Version{Major: 1, Minor: 0, Patch: 97},
+		uses:    func(config *Config) bool { return config.Options.ForkSession },
+	},
+	{
+		feature: "--agents",
+		version: types.Version{Major: 2},
+		uses:    func(config *Config) bool { return len(config.Options.Agents) > 0 },
+	},
+	{
+		feature: "--plugin-dir",
+		version: types.Version{Major: 2},
+		uses:    func(config *Config) bool { return len(config.Options.Plugins) > 0 },
+	},
+}
+
+// versionCache holds the versions of CLI binaries that have already been
+// run, keyed by path, so queries don't pay for a version check each time.
+var versionCache sync.Map
+
+// CLIVersion runs "claude --version" and returns the installed CLI's
+// version. If cliPath is empty, the CLI is discovered as it is for queries.
+func CLIVersion(ctx context.Context, cliPath string) (types.Version, error) {
+	if cliPath == "" {
+		var err error
+		cliPath, err = findCLI(nil)
+		if err != nil {
+			return types.Version{}, err
+		}
+	}
+
+	output, err := exec.CommandContext(ctx, cliPath, "--version").Output()
+	if err != nil {
+		return types.Version{}, fmt.Errorf("failed to get CLI version: %w", err)
+	}
+	return types.ParseVersion(string(output))
+}
+
+// cachedCLIVersion returns the version of the CLI at cliPath, running it
+// only the first time.
+func cachedCLIVersion(ctx context.Context, cliPath string) (types.Version, error) {
+	if version, ok := versionCache.Load(cliPath); ok {
+		return version.(types.Version), nil
+	}
+
+	version, err := CLIVersion(ctx, cliPath)
+	if err != nil {
+		return types.Version{}, err
+	}
+	versionCache.Store(cliPath, version)
+	return version, nil
+}
+
+// checkCLIVersion returns a CLIVersionError if installed is older than
+// config requires.
+func checkCLIVersion(installed types.Version, config *Config) error {
+	required := MinimumCLIVersion
+	var features []string
+	if !installed.AtLeast(MinimumCLIVersion) {
+		features = append(features, "the Go SDK")
+	}
+
+	for _, req := range cliRequirements {
+		if !req.uses(config) || installed.AtLeast(req.version) {
+			continue
+		}
+		features = append(features, req.feature)
+		if req.version.Compare(required) > 0 {
+			required = req.version
+		}
+	}
+
+	if len(features) == 0 {
+		return nil
+	}
+	return &types.CLIVersionError{Installed: installed, Required: required, Features: features}
+}
+
+// checkVersion applies Options.CLIVersionCheck to the CLI at cliPath. With
+// VersionCheckWarn, problems are recorded as warnings instead of failing.
+func (st *SubprocessTransport) checkVersion(ctx context.Context, cliPath string) error {
+	opts := st.config.Options
+	if opts == nil || opts.CLIVersionCheck == nil {
+		return nil
+	}
+
+	version, err := cachedCLIVersion(ctx, cliPath)
+	if err == nil {
+		err = checkCLIVersion(version, st.config)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if *opts.CLIVersionCheck == types.VersionCheckWarn {
+		st.mu.Lock()
+		st.warnings = append(st.warnings, err.Error())
+		st.mu.Unlock()
+		return nil
+	}
+	return err
+}
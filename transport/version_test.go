@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/types"
+)
+
+// writeVersionCLI writes a fake CLI that reports the given version.
+func writeVersionCLI(t *testing.T, version string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script CLI not supported on Windows")
+	}
+
+	cliPath := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\necho '" + version + " (Claude Code)'\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cliPath
+}
+
+func TestCLIVersion(t *testing.T) {
+	cliPath := writeVersionCLI(t, "1.0.51")
+
+	version, err := CLIVersion(context.Background(), cliPath)
+	if err != nil {
+		t.Fatalf("CLIVersion failed: %v", err)
+	}
+	if version != (types.Version{Major: 1, Minor: 0, Patch: 51}) {
+		t.Errorf("Expected 1.0.51, got %v", version)
+	}
+}
+
+func TestCheckCLIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  types.Version
+		config   *Config
+		required types.Version
+		features []string
+	}{
+		{
+			name:    "supported",
+			version: types.Version{Major: 1, Minor: 0, Patch: 97},
+			config: &Config{
+				StreamingInput: true,
+				Options:        types.NewOptions().WithResume("abc").WithForkSession(true),
+			},
+		},
+		{
+			name:     "older than minimum",
+			version:  types.Version{Minor: 9},
+			config:   &Config{Options: types.NewOptions()},
+			required: MinimumCLIVersion,
+			features: []string{"the Go SDK"},
+		},
+		{
+			name:    "option needs newer CLI",
+			version: types.Version{Major: 1, Minor: 0, Patch: 15},
+			config: &Config{
+				StreamingInput: true,
+				Options:        types.NewOptions().WithPermissionMode(types.PermissionModeAcceptEdits),
+			},
+			required: types.Version{Major: 1, Minor: 0, Patch: 20},
+			features: []string{"--input-format stream-json"},
+		},
+		{
+			name:    "agents need CLI 2",
+			version: types.Version{Major: 1, Minor: 0, Patch: 128},
+			config: &Config{
+				Options: types.NewOptions().AddAgent("reviewer", types.AgentDefinition{Description: "d", Prompt: "p"}),
+			},
+			required: types.Version{Major: 2},
+			features: []string{"--agents"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCLIVersion(tt.version, tt.config)
+			if tt.features == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var versionErr *types.CLIVersionError
+			if !errors.As(err, &versionErr) {
+				t.Fatalf("Expected CLIVersionError, got %v", err)
+			}
+			if versionErr.Required != tt.required {
+				t.Errorf("Expected required version %v, got %v", tt.required, versionErr.Required)
+			}
+			if len(versionErr.Features) != len(tt.features) || versionErr.Features[0] != tt.features[0] {
+				t.Errorf("Expected features %v, got %v", tt.features, versionErr.Features)
+			}
+			if types.ErrorKind(err) != types.KindCLIVersion {
+				t.Errorf("Expected KindCLIVersion, got %v", types.ErrorKind(err))
+			}
+		})
+	}
+}
+
+func TestConnectVersionCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    types.VersionCheck
+		wantErr  bool
+		warnings int
+	}{
+		{name: "warn", check: types.VersionCheckWarn, warnings: 1},
+		{name: "error", check: types.VersionCheckError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliPath := writeVersionCLI(t, "0.2.0")
+			transport := NewSubprocessTransport(&Config{
+				Prompt:  "hello",
+				Options: types.NewOptions().WithCLIVersionCheck(tt.check),
+				CLIPath: cliPath,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := transport.Connect(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connect: expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := len(transport.Warnings()); got != tt.warnings {
+				t.Errorf("Expected %d warnings, got %d", tt.warnings, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// configurableTransport records the configuration it receives.
+type configurableTransport struct {
+	scriptedTransport
+	config *TransportConfig
+}
+
+func (ct *configurableTransport) Configure(config *TransportConfig) {
+	ct.config = config
+}
+
+func TestQueryWithTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var custom Transport = &scriptedTransport{
+		lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"text","text":"from custom transport"}]}}`,
+			`{"type":"result","subtype":"success","session_id":"custom"}`,
+		},
+	}
+
+	stream, err := QueryWithTransport(ctx, "Hello", nil, custom)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	result, err := collectQueryResult(ctx, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Text != "from custom transport" {
+		t.Errorf("Unexpected text: %q", result.Text)
+	}
+	if result.Result == nil || result.Result.SessionID != "custom" {
+		t.Errorf("Unexpected result: %+v", result.Result)
+	}
+}
+
+func TestQueryWithConfigurableTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	ct := &configurableTransport{}
+	options := NewOptions().WithModel("claude-sonnet-4")
+
+	stream, err := QueryWithTransport(ctx, "Hello", options, ct)
+	if err != nil {
+		t.Fatalf("QueryWithTransport failed: %v", err)
+	}
+	defer stream.Close()
+
+	if ct.config == nil {
+		t.Fatal("Expected Configure to be called")
+	}
+	if ct.config.Prompt != "Hello" {
+		t.Errorf("Expected prompt 'Hello', got %q", ct.config.Prompt)
+	}
+	if ct.config.Options != options {
+		t.Error("Expected options to be passed through")
+	}
+}
+
+func TestQueryWithTransportHooksNeedInput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	options := NewOptions().AddHook(HookEventStop, "", func(ctx context.Context, input HookInput, toolUseID string) (HookOutput, error) {
+		return HookOutput{}, nil
+	})
+
+	_, err := QueryWithTransport(ctx, "Hello", options, &scriptedTransport{})
+	if err == nil {
+		t.Error("Expected error using hooks with a transport that cannot accept input")
+	}
+}
@@ -1,9 +1,32 @@
 package claudecode
 
 import (
+	"github.com/jrossi/claude-code-sdk-golang/transport"
 	"github.com/jrossi/claude-code-sdk-golang/types"
 )
 
+// Re-export transport interfaces so custom transports can be plugged in
+type (
+	// Transport is a communication channel with Claude Code CLI.
+	// Implement it to run the CLI somewhere other than a local subprocess.
+	Transport = transport.Transport
+
+	// InputTransport is a Transport that can also write stream-json messages
+	// to the CLI, enabling sessions and the control protocol.
+	InputTransport = transport.InputTransport
+
+	// ConfigurableTransport is a Transport that accepts the prompt and
+	// options of the query it serves.
+	ConfigurableTransport = transport.Configurable
+
+	// InterruptibleTransport is a Transport that can interrupt the CLI
+	// directly, enabling QueryStream.Interrupt without the control protocol.
+	InterruptibleTransport = transport.Interrupter
+
+	// TransportConfig contains configuration for creating a transport.
+	TransportConfig = transport.Config
+)
+
 // Re-export message and content block types from internal package
 type (
 	// ContentBlock represents a piece of content within a message.
@@ -12,12 +35,31 @@ type (
 	// TextBlock represents a text content block.
 	TextBlock = types.TextBlock
 
+	// ThinkingBlock represents Claude's extended thinking output.
+	ThinkingBlock = types.ThinkingBlock
+
 	// ToolUseBlock represents a tool use content block.
 	ToolUseBlock = types.ToolUseBlock
 
 	// ToolResultBlock represents a tool result content block.
 	ToolResultBlock = types.ToolResultBlock
 
+	// ToolResultContent is one part of a tool result's content.
+	ToolResultContent = types.ToolResultContent
+
+	// TextContent is a text part of a tool result.
+	TextContent = types.TextContent
+
+	// ImageContent is an image part of a tool result.
+	ImageContent = types.ImageContent
+
+	// JSONContent is a structured part of a tool result, kept as raw JSON.
+	JSONContent = types.JSONContent
+
+	// UnknownBlock is a content block of an unrecognized type, delivered
+	// when parsing with ParseModePassthrough.
+	UnknownBlock = types.UnknownBlock
+
 	// Message represents a message in the conversation.
 	Message = types.Message
 
@@ -32,4 +74,89 @@ type (
 
 	// ResultMessage represents a result message with cost and usage information.
 	ResultMessage = types.ResultMessage
+
+	// InitInfo describes what is available in a session, as reported by
+	// the CLI's init message.
+	InitInfo = types.InitInfo
+
+	// McpServerStatus is the connection status of an MCP server.
+	McpServerStatus = types.McpServerStatus
+
+	// McpServerInfo identifies a connected MCP server's implementation.
+	McpServerInfo = types.McpServerInfo
+
+	// PluginInfo is a plugin loaded by the CLI.
+	PluginInfo = types.PluginInfo
+
+	// PermissionDenial is a tool use refused because permission was denied.
+	PermissionDenial = types.PermissionDenial
+
+	// ModelUsage is the usage of one model during a run.
+	ModelUsage = types.ModelUsage
+
+	// UnknownMessage is a message of an unrecognized type, delivered when
+	// parsing with ParseModePassthrough.
+	UnknownMessage = types.UnknownMessage
+
+	// ProgressEvent reports that a long-running tool call is still running.
+	ProgressEvent = types.ProgressEvent
+
+	// CompactBoundary describes a compaction of the conversation.
+	CompactBoundary = types.CompactBoundary
 )
+
+// Re-export the typed inputs of built-in tools
+type (
+	// ToolInput is implemented by the typed inputs of built-in tools.
+	ToolInput = types.ToolInput
+
+	// ReadInput is the input of the Read tool.
+	ReadInput = types.ReadInput
+
+	// WriteInput is the input of the Write tool.
+	WriteInput = types.WriteInput
+
+	// EditInput is the input of the Edit tool.
+	EditInput = types.EditInput
+
+	// BashInput is the input of the Bash tool.
+	BashInput = types.BashInput
+
+	// GrepInput is the input of the Grep tool.
+	GrepInput = types.GrepInput
+
+	// ExitPlanModeInput is the input of the ExitPlanMode tool.
+	ExitPlanModeInput = types.ExitPlanModeInput
+)
+
+// DecodeToolInput decodes a tool use block's input into T, such as
+// BashInput for a Bash tool use. If T is a ToolInput for a different tool
+// than the block's, an error is returned.
+//
+// Example:
+//
+//	for _, block := range msg.Content {
+//		if tool, ok := block.(*claudecode.ToolUseBlock); ok && tool.Name == "Bash" {
+//			input, err := claudecode.DecodeToolInput[claudecode.BashInput](tool)
+//			if err == nil {
+//				fmt.Println("Running:", input.Command)
+//			}
+//		}
+//	}
+func DecodeToolInput[T any](block *ToolUseBlock) (T, error) {
+	return types.DecodeToolInput[T](block)
+}
+
+// UnmarshalMessage decodes a message encoded with json.Marshal, choosing
+// its concrete type, and those of its content blocks, from their "type"
+// fields. Unrecognized types are returned as UnknownMessage and
+// UnknownBlock values.
+func UnmarshalMessage(data []byte) (Message, error) {
+	return types.UnmarshalMessage(data)
+}
+
+// UnmarshalContentBlock decodes a content block encoded with json.Marshal,
+// choosing its concrete type from its "type" field.
+func UnmarshalContentBlock(data []byte) (ContentBlock, error) {
+	return types.UnmarshalContentBlock(data)
+}
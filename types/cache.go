@@ -0,0 +1,80 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// SystemSubtypeCacheHit is the subtype of the SystemMessage the SDK
+// delivers first when a query's output is replayed from
+// Options.ResponseCache instead of running the CLI. Data["key"] is the
+// cache key. The replayed ResultMessage reports the cost of the original
+// run; nothing was spent on the replay, and usage trackers do not record
+// it.
+const SystemSubtypeCacheHit = "sdk_cache_hit"
+
+// ResponseCache stores the output of successful queries so that repeated
+// identical queries are answered without starting the CLI. Values are the
+// CLI's output as JSON Lines. Implementations decide how long entries
+// live and must be safe for concurrent use; a failure to read or write an
+// entry should be treated as a miss rather than reported.
+//
+// The cache package provides in-memory and on-disk implementations.
+type ResponseCache interface {
+	// Get returns the value stored under key, and false if there is none
+	// or it has expired.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key.
+	Set(key string, value []byte)
+}
+
+// cacheKeyVersion changes whenever the key derivation does, so that old
+// entries are not served for differently derived keys.
+const cacheKeyVersion = "v1"
+
+// CacheKey returns the key a query's output is cached under: a SHA-256
+// hash of the prompt, with surrounding space removed and line endings
+// normalized, and of the options that shape the CLI's answer, such as the
+// model, tools, system prompt, and working directory. Settings the SDK
+// applies itself, such as timeouts, limits, and callbacks, do not affect
+// the key.
+func CacheKey(prompt string, options *Options) string {
+	prompt = strings.TrimSpace(strings.ReplaceAll(prompt, "\r\n", "\n"))
+	if options == nil {
+		options = NewOptions()
+	}
+	// Marshal never fails for Options: every serialized field is plain data
+	encoded, _ := json.Marshal(cacheKeyOptions(options))
+
+	h := sha256.New()
+	h.Write([]byte(cacheKeyVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(prompt))
+	h.Write([]byte{0})
+	h.Write(encoded)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheKeyOptions returns a copy of options without the serialized fields
+// that only the SDK acts on.
+func cacheKeyOptions(o *Options) *Options {
+	c := *o
+	c.StrictModels = false
+	c.TerminationGracePeriod = nil
+	c.MaxBufferSize = nil
+	c.ParseMode = nil
+	c.MaxCostUSD = nil
+	c.QueryTimeout = nil
+	c.IdleTimeout = nil
+	c.StartupTimeout = nil
+	c.HeartbeatInterval = nil
+	c.CLIVersionCheck = nil
+	c.ProbeCLIFlags = false
+	c.CLISearchPaths = nil
+	c.ResourceLimits = nil
+	c.RateLimitTurns = false
+	return &c
+}
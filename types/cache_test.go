@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey("Review main.go", NewOptions().WithModel("sonnet"))
+
+	tests := []struct {
+		name    string
+		prompt  string
+		options *Options
+		same    bool
+	}{
+		{"identical", "Review main.go", NewOptions().WithModel("sonnet"), true},
+		{"surrounding space", "  Review main.go\n", NewOptions().WithModel("sonnet"), true},
+		{"model spelling", "Review main.go", NewOptions().WithModel(" Sonnet"), true},
+		{"query timeout", "Review main.go", NewOptions().WithModel("sonnet").WithQueryTimeout(time.Minute), true},
+		{"max cost", "Review main.go", NewOptions().WithModel("sonnet").WithMaxCostUSD(1), true},
+		{"prompt", "Review util.go", NewOptions().WithModel("sonnet"), false},
+		{"model", "Review main.go", NewOptions().WithModel("opus"), false},
+		{"tools", "Review main.go", NewOptions().WithModel("sonnet").WithAllowedTools("Read"), false},
+		{"working directory", "Review main.go", NewOptions().WithModel("sonnet").WithCwd("/src"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := CacheKey(tt.prompt, tt.options)
+			if (key == base) != tt.same {
+				t.Errorf("Expected same key %v, got %s and %s", tt.same, key, base)
+			}
+		})
+	}
+
+	if CacheKey("a\r\nb", nil) != CacheKey("a\nb", NewOptions()) {
+		t.Error("Expected line endings and nil options to be normalized")
+	}
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the options, so that the copy's builder
+// methods and fields can be changed without affecting the original. Values
+// that are shared by design, such as hook callbacks, the rate limiter, and
+// the raw message handler, are shared by the copy.
+//
+// Example:
+//
+//	base := claudecode.NewOptions().WithModel("sonnet").WithMaxTurns(3)
+//	options := base.Clone().WithSystemPrompt(systemPromptFor(user))
+func (o *Options) Clone() *Options {
+	if o == nil {
+		return nil
+	}
+
+	c := *o
+	c.AllowedTools = slices.Clone(o.AllowedTools)
+	c.DisallowedTools = slices.Clone(o.DisallowedTools)
+	c.McpTools = slices.Clone(o.McpTools)
+	c.AddDirs = slices.Clone(o.AddDirs)
+	c.CLISearchPaths = slices.Clone(o.CLISearchPaths)
+	c.Plugins = slices.Clone(o.Plugins)
+	c.McpConfigFiles = slices.Clone(o.McpConfigFiles)
+
+	if o.McpServers != nil {
+		c.McpServers = make(McpServers, len(o.McpServers))
+		for name, server := range o.McpServers {
+			c.McpServers[name] = cloneMcpServer(server)
+		}
+	}
+	if o.Agents != nil {
+		c.Agents = make(map[string]AgentDefinition, len(o.Agents))
+		for name, agent := range o.Agents {
+			agent.Tools = slices.Clone(agent.Tools)
+			c.Agents[name] = agent
+		}
+	}
+	if o.ExtraArgs != nil {
+		c.ExtraArgs = make(map[string]*string, len(o.ExtraArgs))
+		for flag, value := range o.ExtraArgs {
+			c.ExtraArgs[flag] = clonePtr(value)
+		}
+	}
+	if o.Hooks != nil {
+		c.Hooks = make(map[HookEvent][]HookMatcher, len(o.Hooks))
+		for event, matchers := range o.Hooks {
+			matchers = slices.Clone(matchers)
+			for i := range matchers {
+				matchers[i].Hooks = slices.Clone(matchers[i].Hooks)
+			}
+			c.Hooks[event] = matchers
+		}
+	}
+
+	c.SystemPrompt = clonePtr(o.SystemPrompt)
+	c.AppendSystemPrompt = clonePtr(o.AppendSystemPrompt)
+	c.PermissionMode = clonePtr(o.PermissionMode)
+	c.Resume = clonePtr(o.Resume)
+	c.MaxTurns = clonePtr(o.MaxTurns)
+	c.Model = clonePtr(o.Model)
+	c.FallbackModel = clonePtr(o.FallbackModel)
+	c.PermissionPromptToolName = clonePtr(o.PermissionPromptToolName)
+	c.Cwd = clonePtr(o.Cwd)
+	c.Settings = clonePtr(o.Settings)
+	c.OutputStyle = clonePtr(o.OutputStyle)
+	c.AutoCompact = clonePtr(o.AutoCompact)
+	c.APIKey = clonePtr(o.APIKey)
+	c.AuthToken = clonePtr(o.AuthToken)
+	c.BaseURL = clonePtr(o.BaseURL)
+	c.RetryPolicy = clonePtr(o.RetryPolicy)
+	c.ReconnectPolicy = clonePtr(o.ReconnectPolicy)
+	c.TerminationGracePeriod = clonePtr(o.TerminationGracePeriod)
+	c.MaxBufferSize = clonePtr(o.MaxBufferSize)
+	c.ParseMode = clonePtr(o.ParseMode)
+	c.MaxCostUSD = clonePtr(o.MaxCostUSD)
+	c.QueryTimeout = clonePtr(o.QueryTimeout)
+	c.IdleTimeout = clonePtr(o.IdleTimeout)
+	c.StartupTimeout = clonePtr(o.StartupTimeout)
+	c.HeartbeatInterval = clonePtr(o.HeartbeatInterval)
+	c.CLIVersionCheck = clonePtr(o.CLIVersionCheck)
+	c.ResourceLimits = clonePtr(o.ResourceLimits)
+
+	return &c
+}
+
+// clonePtr returns a pointer to a copy of *p, or nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// cloneMcpServer copies the built-in server configurations. Other
+// implementations are shared.
+func cloneMcpServer(server McpServerConfig) McpServerConfig {
+	switch s := server.(type) {
+	case *StdioServerConfig:
+		c := *s
+		c.Args = slices.Clone(s.Args)
+		c.Env = maps.Clone(s.Env)
+		return &c
+	case *SSEServerConfig:
+		c := *s
+		c.Headers = maps.Clone(s.Headers)
+		return &c
+	case *HTTPServerConfig:
+		c := *s
+		c.Headers = maps.Clone(s.Headers)
+		return &c
+	default:
+		return server
+	}
+}
+
+// OptionsTemplate is an immutable set of options to base queries on. Unlike
+// Options, whose builder methods change the receiver, a template is never
+// changed after it is created, so one template can be shared by concurrent
+// requests.
+//
+// Example:
+//
+//	template := claudecode.NewOptionsTemplate(
+//		claudecode.NewOptions().WithModel("sonnet").WithAllowedTools("Read", "Grep"))
+//
+//	// In each request handler:
+//	options := template.Options().WithCwd(repoDir)
+type OptionsTemplate struct {
+	base *Options
+}
+
+// NewOptionsTemplate creates a template from a copy of options, which may
+// be nil for the defaults. Later changes to options do not affect it.
+func NewOptionsTemplate(options *Options) OptionsTemplate {
+	if options == nil {
+		options = NewOptions()
+	}
+	return OptionsTemplate{base: options.Clone()}
+}
+
+// Options returns a new copy of the template's options, which the caller
+// may change freely.
+func (t OptionsTemplate) Options() *Options {
+	if t.base == nil {
+		return NewOptions()
+	}
+	return t.base.Clone()
+}
+
+// With returns a new template with the changes made by modify applied to a
+// copy of this template's options. This template is unchanged.
+//
+// Example:
+//
+//	readOnly := template.With(func(o *claudecode.Options) {
+//		o.WithPermissionMode(claudecode.PermissionModeDefault).WithDisallowedTools("Write", "Edit")
+//	})
+func (t OptionsTemplate) With(modify func(*Options)) OptionsTemplate {
+	options := t.Options()
+	modify(options)
+	return OptionsTemplate{base: options}
+}
@@ -0,0 +1,197 @@
+package types
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// noopHook is a hook callback that does nothing.
+func noopHook(context.Context, HookInput, string) (HookOutput, error) {
+	return HookOutput{}, nil
+}
+
+// fullOptions returns options with every pointer, slice, and map field set.
+func fullOptions() *Options {
+	beta := "context-1m"
+	options := NewOptions().
+		WithAllowedTools("Read").
+		WithDisallowedTools("Bash").
+		WithSystemPrompt("system").
+		WithAppendSystemPrompt("append").
+		WithAgents(map[string]AgentDefinition{"reviewer": {Description: "Reviews", Prompt: "Review", Tools: []string{"Read"}}}).
+		WithPlugins(PluginConfig{Type: PluginTypeLocal, Path: "/plugins/a"}).
+		WithPermissionMode(PermissionModeAcceptEdits).
+		WithResume("abc").
+		WithMaxTurns(3).
+		WithModel("sonnet").
+		WithFallbackModel("haiku").
+		WithStrictModels(true).
+		WithCwd("/repo").
+		WithAddDirs("/shared").
+		WithSettings("settings.json").
+		WithOutputStyle("Explanatory").
+		WithAutoCompact(false).
+		WithAPIKey("key").
+		WithAuthToken("token").
+		WithBaseURL("http://localhost").
+		WithHooks(map[HookEvent][]HookMatcher{
+			HookEventPreToolUse: {{Matcher: "Bash", Hooks: []HookCallback{noopHook}}},
+		}).
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}).
+		WithReconnectPolicy(ReconnectPolicy{MaxReconnects: 1}).
+		WithTerminationGracePeriod(time.Second).
+		WithMaxBufferSize(1024).
+		WithParseMode(ParseModeStrict).
+		WithMaxCostUSD(1).
+		WithQueryTimeout(time.Minute).
+		WithIdleTimeout(time.Minute).
+		WithStartupTimeout(time.Minute).
+		WithHeartbeatInterval(time.Minute).
+		WithCLIVersionCheck(VersionCheckError).
+		WithCLISearchPaths("/opt/bin").
+		WithResourceLimits(ResourceLimits{MaxMemoryBytes: 1 << 30}).
+		WithExtraArgs(map[string]*string{"betas": &beta, "strict-mcp-config": nil}).
+		WithMcpConfigFile(".mcp.json")
+
+	options.McpTools = []string{"mcp__db__query"}
+	options.McpServers = map[string]McpServerConfig{
+		"db":  &StdioServerConfig{Command: "db", Args: []string{"-v"}, Env: map[string]string{"A": "1"}},
+		"web": &HTTPServerConfig{URL: "http://localhost", Headers: map[string]string{"X": "1"}},
+	}
+	prompt := "prompt"
+	options.PermissionPromptToolName = &prompt
+	return options
+}
+
+func TestOptionsCloneCopiesEveryReference(t *testing.T) {
+	original := fullOptions()
+	clone := original.Clone()
+
+	if !reflect.DeepEqual(clone.AllowedTools, original.AllowedTools) || *clone.Model != *original.Model {
+		t.Fatal("Expected the clone to equal the original")
+	}
+
+	ov := reflect.ValueOf(original).Elem()
+	cv := reflect.ValueOf(clone).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Type().Field(i)
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+		default:
+			continue
+		}
+		if ov.Field(i).IsNil() {
+			t.Errorf("fullOptions does not set %s", field.Name)
+			continue
+		}
+		if ov.Field(i).Pointer() == cv.Field(i).Pointer() {
+			t.Errorf("Clone shares %s with the original", field.Name)
+		}
+	}
+}
+
+func TestOptionsCloneIsIndependent(t *testing.T) {
+	original := fullOptions()
+	clone := original.Clone()
+
+	clone.AllowedTools[0] = "Write"
+	*clone.Model = "opus"
+	clone.McpServers["db"].(*StdioServerConfig).Args[0] = "-q"
+	clone.McpServers["db"].(*StdioServerConfig).Env["A"] = "2"
+	clone.McpServers["web"].(*HTTPServerConfig).Headers["X"] = "2"
+	clone.Agents["reviewer"].Tools[0] = "Grep"
+	clone.Hooks[HookEventPreToolUse][0].Matcher = "Edit"
+	clone.Hooks[HookEventPreToolUse][0].Hooks[0] = nil
+	clone.Hooks[HookEventPostToolUse] = []HookMatcher{{Hooks: []HookCallback{noopHook}}}
+	clone.RetryPolicy.MaxAttempts = 5
+	clone.ResourceLimits.MaxMemoryBytes = 1
+	*clone.ExtraArgs["betas"] = "other"
+
+	if original.AllowedTools[0] != "Read" {
+		t.Error("Changing the clone's AllowedTools changed the original")
+	}
+	if *original.Model != "sonnet" {
+		t.Error("Changing the clone's Model changed the original")
+	}
+	db := original.McpServers["db"].(*StdioServerConfig)
+	if db.Args[0] != "-v" || db.Env["A"] != "1" {
+		t.Error("Changing the clone's stdio server changed the original")
+	}
+	if original.McpServers["web"].(*HTTPServerConfig).Headers["X"] != "1" {
+		t.Error("Changing the clone's HTTP server changed the original")
+	}
+	if original.Agents["reviewer"].Tools[0] != "Read" {
+		t.Error("Changing the clone's agent changed the original")
+	}
+	matcher := original.Hooks[HookEventPreToolUse][0]
+	if matcher.Matcher != "Bash" || matcher.Hooks[0] == nil || len(original.Hooks) != 1 {
+		t.Error("Changing the clone's hooks changed the original")
+	}
+	if *original.ExtraArgs["betas"] != "context-1m" {
+		t.Error("Changing the clone's extra args changed the original")
+	}
+	if original.RetryPolicy.MaxAttempts != 2 || original.ResourceLimits.MaxMemoryBytes != 1<<30 {
+		t.Error("Changing the clone's policies changed the original")
+	}
+}
+
+func TestOptionsCloneNil(t *testing.T) {
+	var options *Options
+	if options.Clone() != nil {
+		t.Error("Expected nil clone of nil options")
+	}
+}
+
+func TestOptionsTemplate(t *testing.T) {
+	base := NewOptions().WithModel("sonnet").WithAllowedTools("Read")
+	template := NewOptionsTemplate(base)
+
+	// Changes to the options the template was made from are not seen
+	base.WithModel("opus")
+	if got := *template.Options().Model; got != "sonnet" {
+		t.Errorf("Expected template model sonnet, got %s", got)
+	}
+
+	// Changes to options from the template are not seen either
+	template.Options().WithAllowedTools("Write").WithMaxTurns(1)
+	options := template.Options()
+	if !reflect.DeepEqual(options.AllowedTools, []string{"Read"}) || options.MaxTurns != nil {
+		t.Errorf("Template changed by its options: %+v", options)
+	}
+
+	derived := template.With(func(o *Options) { o.WithMaxTurns(2) })
+	if template.Options().MaxTurns != nil {
+		t.Error("With changed the original template")
+	}
+	if got := derived.Options(); got.MaxTurns == nil || *got.MaxTurns != 2 || *got.Model != "sonnet" {
+		t.Errorf("Unexpected derived options: %+v", got)
+	}
+
+	var zero OptionsTemplate
+	if !reflect.DeepEqual(zero.Options(), NewOptions()) || !reflect.DeepEqual(NewOptionsTemplate(nil).Options(), NewOptions()) {
+		t.Error("Expected the zero and nil templates to give default options")
+	}
+}
+
+func TestOptionsTemplateConcurrentUse(t *testing.T) {
+	template := NewOptionsTemplate(fullOptions())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			options := template.Options().WithAllowedTools("Grep").WithModel("opus")
+			options.McpServers["db"].(*StdioServerConfig).Env["B"] = "2"
+			options.Hooks[HookEventStop] = []HookMatcher{{Hooks: []HookCallback{noopHook}}}
+		}()
+	}
+	wg.Wait()
+
+	if got := *template.Options().Model; got != "sonnet" {
+		t.Errorf("Expected template model sonnet, got %s", got)
+	}
+}
@@ -0,0 +1,51 @@
+package types
+
+import "encoding/json"
+
+// SystemSubtypeCompactBoundary is the subtype of the system message the
+// CLI sends when it compacts the conversation, summarizing earlier turns
+// to free up the context window. Messages before it are no longer in
+// Claude's context.
+const SystemSubtypeCompactBoundary = "compact_boundary"
+
+// Compaction triggers reported in CompactBoundary.Trigger.
+const (
+	// CompactTriggerAuto means the CLI compacted the conversation because
+	// it neared the context window.
+	CompactTriggerAuto = "auto"
+
+	// CompactTriggerManual means compaction was requested with /compact,
+	// such as by Session.Compact.
+	CompactTriggerManual = "manual"
+)
+
+// CompactBoundary describes a compaction of the conversation, as reported
+// by a compact_boundary system message.
+type CompactBoundary struct {
+	// Trigger is what started the compaction, CompactTriggerAuto or
+	// CompactTriggerManual.
+	Trigger string `json:"trigger"`
+
+	// PreTokens is how many tokens the context held before compaction.
+	PreTokens int `json:"pre_tokens"`
+}
+
+// CompactBoundary returns the compaction described by a compact_boundary
+// system message. It reports false for other system messages.
+func (sm *SystemMessage) CompactBoundary() (*CompactBoundary, bool) {
+	if sm.Subtype != SystemSubtypeCompactBoundary {
+		return nil, false
+	}
+
+	var boundary CompactBoundary
+	if metadata, ok := sm.Data["compact_metadata"]; ok {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, false
+		}
+		if err := json.Unmarshal(data, &boundary); err != nil {
+			return nil, false
+		}
+	}
+	return &boundary, true
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"errors"
+)
+
+// CompletionStatus is how a query ended.
+type CompletionStatus string
+
+const (
+	// CompletionSuccess means the query ended with a successful result.
+	CompletionSuccess CompletionStatus = "success"
+
+	// CompletionError means the query failed, or ended with an error result.
+	CompletionError CompletionStatus = "error"
+
+	// CompletionCanceled means the query was cancelled or closed before it
+	// finished.
+	CompletionCanceled CompletionStatus = "canceled"
+)
+
+// ResultSummary describes how a query ended. It is passed to
+// Options.CompletionCallback.
+type ResultSummary struct {
+	Status CompletionStatus `json:"status"`
+
+	// SessionID, CostUSD, NumTurns, and DurationMs are taken from the
+	// query's last result, and are zero if no result arrived.
+	SessionID  string  `json:"session_id,omitempty"`
+	CostUSD    float64 `json:"cost_usd,omitempty"`
+	NumTurns   int     `json:"num_turns,omitempty"`
+	DurationMs int     `json:"duration_ms,omitempty"`
+
+	// Subtype is the subtype of the last result, such as "success" or
+	// "error_max_turns".
+	Subtype string `json:"subtype,omitempty"`
+
+	// Result is the query's last result message, or nil.
+	Result *ResultMessage `json:"-"`
+
+	// Err is the first error the query reported, or describes why it did
+	// not succeed. It is nil on success.
+	Err error `json:"-"`
+
+	// ErrorKind classifies Err. It is empty on success.
+	ErrorKind Kind `json:"error_kind,omitempty"`
+}
+
+// NewResultSummary summarizes a query from its last result, which may be
+// nil, and the first error it reported, which takes precedence.
+func NewResultSummary(result *ResultMessage, err error) *ResultSummary {
+	s := &ResultSummary{Status: CompletionSuccess, Result: result}
+	if result != nil {
+		s.SessionID = result.SessionID
+		s.NumTurns = result.NumTurns
+		s.DurationMs = result.DurationMs
+		s.Subtype = result.Subtype
+		if result.TotalCostUSD != nil {
+			s.CostUSD = *result.TotalCostUSD
+		}
+	}
+
+	switch {
+	case err != nil:
+	case result == nil:
+		err = ErrNoResult
+	case result.IsError:
+		err = errors.New("query ended with " + result.Subtype)
+	default:
+		return s
+	}
+
+	s.Err = err
+	s.ErrorKind = ErrorKind(err)
+	s.Status = CompletionError
+	if s.ErrorKind == KindCanceled {
+		s.Status = CompletionCanceled
+	}
+	return s
+}
+
+// Succeeded reports whether the query ended with a successful result.
+func (s *ResultSummary) Succeeded() bool {
+	return s.Status == CompletionSuccess
+}
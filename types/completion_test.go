@@ -0,0 +1,54 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewResultSummary(t *testing.T) {
+	cost := 1.5
+	success := &ResultMessage{Subtype: "success", SessionID: "s1", NumTurns: 3, DurationMs: 900, TotalCostUSD: &cost}
+	maxTurns := &ResultMessage{Subtype: "error_max_turns", IsError: true, SessionID: "s2"}
+
+	tests := []struct {
+		name   string
+		result *ResultMessage
+		err    error
+		status CompletionStatus
+		kind   Kind
+	}{
+		{"success", success, nil, CompletionSuccess, ""},
+		{"error result", maxTurns, nil, CompletionError, KindUnknown},
+		{"no result", nil, nil, CompletionError, KindUnknown},
+		{"rate limited", nil, &RateLimitError{Message: "overloaded"}, CompletionError, KindRateLimit},
+		{"error after result", success, &BudgetExceededError{LimitUSD: 1, SpentUSD: 1.5}, CompletionError, KindBudgetExceeded},
+		{"canceled", nil, context.Canceled, CompletionCanceled, KindCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewResultSummary(tt.result, tt.err)
+			if s.Status != tt.status || s.ErrorKind != tt.kind {
+				t.Errorf("Expected %s/%q, got %s/%q", tt.status, tt.kind, s.Status, s.ErrorKind)
+			}
+			if s.Succeeded() != (tt.status == CompletionSuccess) {
+				t.Errorf("Expected Succeeded to be %v", !s.Succeeded())
+			}
+			if tt.err != nil && !errors.Is(s.Err, tt.err) {
+				t.Errorf("Expected the error to be kept, got %v", s.Err)
+			}
+			if tt.result != nil && s.SessionID != tt.result.SessionID {
+				t.Errorf("Expected session %s, got %s", tt.result.SessionID, s.SessionID)
+			}
+		})
+	}
+
+	s := NewResultSummary(success, nil)
+	if s.CostUSD != 1.5 || s.NumTurns != 3 || s.DurationMs != 900 || s.Subtype != "success" || s.Result != success {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+	if s := NewResultSummary(nil, nil); !errors.Is(s.Err, ErrNoResult) {
+		t.Errorf("Expected ErrNoResult, got %v", s.Err)
+	}
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LoadOptions reads options from a JSON file. Fields missing from the file
+// keep their NewOptions defaults. Settings that cannot be serialized, such
+// as credentials, hooks, and the retry policy, are never loaded.
+//
+// Presets kept in YAML can be converted to JSON with any YAML library
+// before loading.
+//
+// Example:
+//
+//	options, err := claudecode.LoadOptions("presets/reviewer.json")
+//	if err != nil {
+//		return err
+//	}
+//	result, err := claudecode.QueryPrompt(ctx, prompt, options.WithCwd(repoDir))
+func LoadOptions(path string) (*Options, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	options := NewOptions()
+	if err := json.Unmarshal(data, options); err != nil {
+		return nil, fmt.Errorf("load options %s: %w", path, err)
+	}
+	return options, nil
+}
+
+// Save writes the options as indented JSON to a file that LoadOptions can
+// read. Credentials, hooks, the retry policy, and other settings that
+// cannot be serialized are left out.
+//
+// MCP server headers and environment variables often hold tokens, so the
+// file is only readable by its owner, even if it already existed.
+func (o *Options) Save(path string) error {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save options %s: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	// Tighten the mode before writing in case the file was shared
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return fmt.Errorf("save options %s: %w", path, err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("save options %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// optionsJSON is Options without its JSON methods.
+type optionsJSON Options
+
+// optionsWithDurations encodes Options with its duration fields, which
+// shadow those of optionsJSON, as Duration strings.
+type optionsWithDurations struct {
+	*optionsJSON
+	TerminationGracePeriod *Duration `json:"terminationGracePeriod,omitempty"`
+	QueryTimeout           *Duration `json:"queryTimeout,omitempty"`
+	IdleTimeout            *Duration `json:"idleTimeout,omitempty"`
+	StartupTimeout         *Duration `json:"startupTimeout,omitempty"`
+	HeartbeatInterval      *Duration `json:"heartbeatInterval,omitempty"`
+}
+
+func withDurations(o *Options) *optionsWithDurations {
+	return &optionsWithDurations{
+		optionsJSON:            (*optionsJSON)(o),
+		TerminationGracePeriod: durationPtr(o.TerminationGracePeriod),
+		QueryTimeout:           durationPtr(o.QueryTimeout),
+		IdleTimeout:            durationPtr(o.IdleTimeout),
+		StartupTimeout:         durationPtr(o.StartupTimeout),
+		HeartbeatInterval:      durationPtr(o.HeartbeatInterval),
+	}
+}
+
+// MarshalJSON encodes the options with durations as strings such as "5m".
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(withDurations(&o))
+}
+
+// UnmarshalJSON decodes options, taking durations as strings such as "5m"
+// or as nanoseconds. Fields missing from data are left unchanged.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	aux := withDurations(o)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	o.TerminationGracePeriod = (*time.Duration)(aux.TerminationGracePeriod)
+	o.QueryTimeout = (*time.Duration)(aux.QueryTimeout)
+	o.IdleTimeout = (*time.Duration)(aux.IdleTimeout)
+	o.StartupTimeout = (*time.Duration)(aux.StartupTimeout)
+	o.HeartbeatInterval = (*time.Duration)(aux.HeartbeatInterval)
+	return nil
+}
+
+// resourceLimitsJSON is ResourceLimits without its JSON methods.
+type resourceLimitsJSON ResourceLimits
+
+// MarshalJSON encodes the limits with MaxWallClock as a string such as
+// "10m".
+func (l ResourceLimits) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		resourceLimitsJSON
+		MaxWallClock Duration `json:"maxWallClock,omitempty"`
+	}{resourceLimitsJSON(l), Duration(l.MaxWallClock)})
+}
+
+// UnmarshalJSON decodes limits, taking MaxWallClock as a string such as
+// "10m" or as nanoseconds.
+func (l *ResourceLimits) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*resourceLimitsJSON
+		MaxWallClock *Duration `json:"maxWallClock,omitempty"`
+	}{resourceLimitsJSON: (*resourceLimitsJSON)(l)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.MaxWallClock != nil {
+		l.MaxWallClock = time.Duration(*aux.MaxWallClock)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// presetOptions returns options using every serializable kind of field.
+func presetOptions() *Options {
+	return NewOptions().
+		WithModel("sonnet").
+		WithSystemPrompt("You review Go code.\nBe brief.\n").
+		WithAllowedTools("Read", "Grep").
+		WithDisallowedTools("Bash").
+		WithMaxTurns(5).
+		WithPermissionMode(PermissionModeAcceptEdits).
+		WithQueryTimeout(2*time.Minute).
+		WithAgents(map[string]AgentDefinition{"tester": {Description: "Writes tests", Prompt: "Write tests", Tools: []string{"Write"}}}).
+		AddMcpServer("files", &StdioServerConfig{Command: "npx", Args: []string{"-y", "server-files"}, Env: map[string]string{"ROOT": "/repo"}}).
+		AddMcpServer("events", &SSEServerConfig{URL: "https://example.com/sse"}).
+		AddMcpServer("search", &HTTPServerConfig{URL: "https://example.com/mcp", Headers: map[string]string{"Authorization": "Bearer x"}})
+}
+
+func TestOptionsSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "options.json")
+	want := presetOptions()
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := LoadOptions(path)
+	if err != nil {
+		t.Fatalf("LoadOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		data, _ := os.ReadFile(path)
+		t.Errorf("Options changed by round trip:\n got %+v\nwant %+v\nfile:\n%s", got, want, data)
+	}
+}
+
+func TestOptionsSaveOmitsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "options.json")
+	if err := presetOptions().WithAPIKey("sk-secret").Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret") {
+		t.Errorf("API key saved to file:\n%s", data)
+	}
+}
+
+func TestOptionsSaveIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "options.json")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := presetOptions().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected mode 0600, got %o", mode)
+	}
+}
+
+func TestLoadOptionsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewer.json")
+	config := `{
+  "model": "opus",
+  "systemPrompt": "Review the change.\nReport only real problems.\n",
+  "allowedTools": ["Read", "Grep", "Glob"],
+  "maxTurns": 3,
+  "mcpServers": {
+    "files": {"command": "npx", "args": ["-y", "server-files"]},
+    "search": {"type": "http", "url": "https://example.com/mcp"}
+  }
+}
+`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options, err := LoadOptions(path)
+	if err != nil {
+		t.Fatalf("LoadOptions failed: %v", err)
+	}
+
+	if *options.Model != "opus" || *options.MaxTurns != 3 {
+		t.Errorf("Unexpected model or max turns: %s, %d", *options.Model, *options.MaxTurns)
+	}
+	if *options.SystemPrompt != "Review the change.\nReport only real problems.\n" {
+		t.Errorf("Unexpected system prompt: %q", *options.SystemPrompt)
+	}
+	if !reflect.DeepEqual(options.AllowedTools, []string{"Read", "Grep", "Glob"}) {
+		t.Errorf("Unexpected allowed tools: %v", options.AllowedTools)
+	}
+	if options.MaxThinkingTokens != 8000 {
+		t.Errorf("Expected default max thinking tokens, got %d", options.MaxThinkingTokens)
+	}
+	wantServers := McpServers{
+		"files":  &StdioServerConfig{Command: "npx", Args: []string{"-y", "server-files"}},
+		"search": &HTTPServerConfig{URL: "https://example.com/mcp"},
+	}
+	if !reflect.DeepEqual(options.McpServers, wantServers) {
+		t.Errorf("Unexpected MCP servers: %+v", options.McpServers)
+	}
+}
+
+func TestLoadOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"bad json", "options.json", `{"model": }`, "invalid character"},
+		{"wrong field type", "options.json", `{"maxTurns": "five"}`, "maxTurns"},
+		{"unknown server type", "options.json", `{"mcpServers": {"x": {"type": "ws"}}}`, `MCP server "x": unknown server type "ws"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadOptions(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := LoadOptions(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+}
+
+func TestMcpServerConfigJSON(t *testing.T) {
+	tests := []struct {
+		server McpServerConfig
+		want   string
+	}{
+		{&StdioServerConfig{Command: "node", Args: []string{"server.js"}}, `{"type":"stdio","command":"node","args":["server.js"]}`},
+		{&SSEServerConfig{URL: "https://example.com/sse"}, `{"type":"sse","url":"https://example.com/sse"}`},
+		{&HTTPServerConfig{URL: "https://example.com/mcp"}, `{"type":"http","url":"https://example.com/mcp"}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%T) = %s, want %s", tt.server, data, tt.want)
+		}
+
+		server, err := decodeMcpServer(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(server, tt.server) {
+			t.Errorf("decodeMcpServer(%s) = %+v", data, server)
+		}
+	}
+}
+
+func TestMcpServersUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+		"files": {"command": "npx", "args": ["-y", "server-files"]},
+		"events": {"type": "sse", "url": "https://example.com/sse"},
+		"search": {"type": "http", "url": "https://example.com/mcp", "headers": {"Authorization": "Bearer x"}}
+	}`)
+
+	servers := McpServers{"local": &StdioServerConfig{Command: "local-server"}}
+	if err := json.Unmarshal(data, &servers); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := McpServers{
+		"local":  &StdioServerConfig{Command: "local-server"},
+		"files":  &StdioServerConfig{Command: "npx", Args: []string{"-y", "server-files"}},
+		"events": &SSEServerConfig{URL: "https://example.com/sse"},
+		"search": &HTTPServerConfig{URL: "https://example.com/mcp", Headers: map[string]string{"Authorization": "Bearer x"}},
+	}
+	if !reflect.DeepEqual(servers, want) {
+		t.Errorf("Unexpected servers: %+v", servers)
+	}
+
+	options := NewOptions().WithMcpServers(servers)
+	if !reflect.DeepEqual(options.McpServers, want) {
+		t.Errorf("Unexpected options servers: %+v", options.McpServers)
+	}
+
+	var empty McpServers
+	if err := json.Unmarshal([]byte(`null`), &empty); err != nil || empty != nil {
+		t.Errorf("Expected null to leave the map nil, got %v, %v", empty, err)
+	}
+	if err := json.Unmarshal([]byte(`{"x": {"type": "ws"}}`), &empty); err == nil || err.Error() != `MCP server "x": unknown server type "ws"` {
+		t.Errorf("Expected unknown type error, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration that is written to JSON as a string that
+// time.ParseDuration accepts, such as "5m" or "1h30m", so options files
+// can be edited by hand. Integers are read as nanoseconds, as written by
+// earlier versions.
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string such as "5m0s".
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string or a number of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if json.Unmarshal(data, &n) != nil {
+			return fmt.Errorf("invalid duration %s: expected a string such as \"5m\"", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// durationPtr returns a copy of *d as a Duration, or nil.
+func durationPtr(d *time.Duration) *Duration {
+	if d == nil {
+		return nil
+	}
+	v := Duration(*d)
+	return &v
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsDurationsJSON(t *testing.T) {
+	options := NewOptions().
+		WithQueryTimeout(5 * time.Minute).
+		WithIdleTimeout(90 * time.Second).
+		WithHeartbeatInterval(15 * time.Second).
+		WithResourceLimits(ResourceLimits{MaxWallClock: time.Hour, Nice: 5})
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"queryTimeout":"5m0s"`, `"idleTimeout":"1m30s"`, `"heartbeatInterval":"15s"`, `"maxWallClock":"1h0m0s"`, `"nice":5`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "startupTimeout") {
+		t.Errorf("Expected unset durations to be omitted: %s", data)
+	}
+
+	got := NewOptions()
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, options) {
+		t.Errorf("Options changed by round trip:\n got %+v\nwant %+v", got, options)
+	}
+}
+
+func TestOptionsDurationsFromFile(t *testing.T) {
+	data := `{"model": "sonnet", "queryTimeout": "5m", "terminationGracePeriod": 2000000000, "resourceLimits": {"maxWallClock": "1h30m"}}`
+
+	options := NewOptions()
+	if err := json.Unmarshal([]byte(data), options); err != nil {
+		t.Fatal(err)
+	}
+	if *options.Model != "sonnet" {
+		t.Errorf("Expected the model to be decoded, got %v", options.Model)
+	}
+	if *options.QueryTimeout != 5*time.Minute {
+		t.Errorf("Expected a 5m query timeout, got %v", *options.QueryTimeout)
+	}
+	if *options.TerminationGracePeriod != 2*time.Second {
+		t.Errorf("Expected nanoseconds to be accepted, got %v", *options.TerminationGracePeriod)
+	}
+	if options.ResourceLimits.MaxWallClock != 90*time.Minute {
+		t.Errorf("Expected a 1h30m wall clock limit, got %v", options.ResourceLimits.MaxWallClock)
+	}
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"queryTimeout": "five minutes"}`, `invalid duration "five minutes"`},
+		{`{"idleTimeout": true}`, `invalid duration true`},
+		{`{"resourceLimits": {"maxWallClock": "1 hour"}}`, `invalid duration "1 hour"`},
+	}
+	for _, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.data), NewOptions()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Unmarshal(%s): expected error containing %q, got %v", tt.data, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by OptionsFromEnv.
+const (
+	// envConfig names an options file, read with LoadOptions, that the
+	// other variables override.
+	envConfig = "CLAUDE_SDK_CONFIG"
+
+	envModel              = "CLAUDE_SDK_MODEL"
+	envFallbackModel      = "CLAUDE_SDK_FALLBACK_MODEL"
+	envPermissionMode     = "CLAUDE_SDK_PERMISSION_MODE"
+	envMaxTurns           = "CLAUDE_SDK_MAX_TURNS"
+	envMaxThinkingTokens  = "CLAUDE_SDK_MAX_THINKING_TOKENS"
+	envCwd                = "CLAUDE_SDK_CWD"
+	envAddDirs            = "CLAUDE_SDK_ADD_DIRS"
+	envSystemPrompt       = "CLAUDE_SDK_SYSTEM_PROMPT"
+	envAppendSystemPrompt = "CLAUDE_SDK_APPEND_SYSTEM_PROMPT"
+	envAllowedTools       = "CLAUDE_SDK_ALLOWED_TOOLS"
+	envDisallowedTools    = "CLAUDE_SDK_DISALLOWED_TOOLS"
+	envSettings           = "CLAUDE_SDK_SETTINGS"
+	envMcpConfig          = "CLAUDE_SDK_MCP_CONFIG"
+	envOutputStyle        = "CLAUDE_SDK_OUTPUT_STYLE"
+	envMaxCostUSD         = "CLAUDE_SDK_MAX_COST_USD"
+	envQueryTimeout       = "CLAUDE_SDK_QUERY_TIMEOUT"
+	envIdleTimeout        = "CLAUDE_SDK_IDLE_TIMEOUT"
+	envStartupTimeout     = "CLAUDE_SDK_STARTUP_TIMEOUT"
+)
+
+// OptionsFromEnv returns options configured by CLAUDE_SDK_* environment
+// variables, so deployments can change behavior without code changes:
+//
+//	CLAUDE_SDK_CONFIG                JSON options file to start from
+//	CLAUDE_SDK_MODEL                 model name or alias
+//	CLAUDE_SDK_FALLBACK_MODEL        model used when the main model is overloaded
+//	CLAUDE_SDK_PERMISSION_MODE       permission mode, such as acceptEdits
+//	CLAUDE_SDK_MAX_TURNS             maximum number of turns
+//	CLAUDE_SDK_MAX_THINKING_TOKENS   maximum thinking tokens
+//	CLAUDE_SDK_CWD                   working directory
+//	CLAUDE_SDK_ADD_DIRS              extra directories, separated like PATH
+//	CLAUDE_SDK_SYSTEM_PROMPT         system prompt
+//	CLAUDE_SDK_APPEND_SYSTEM_PROMPT  text appended to the system prompt
+//	CLAUDE_SDK_ALLOWED_TOOLS         comma-separated allowed tools
+//	CLAUDE_SDK_DISALLOWED_TOOLS      comma-separated disallowed tools
+//	CLAUDE_SDK_SETTINGS              CLI settings file
+//	CLAUDE_SDK_MCP_CONFIG            MCP configuration files, separated like PATH
+//	CLAUDE_SDK_OUTPUT_STYLE          output style, such as Explanatory
+//	CLAUDE_SDK_MAX_COST_USD          cost limit in US dollars
+//	CLAUDE_SDK_QUERY_TIMEOUT         query time limit, such as 5m
+//	CLAUDE_SDK_IDLE_TIMEOUT          idle time limit
+//	CLAUDE_SDK_STARTUP_TIMEOUT       startup time limit
+//
+// Unset and empty variables leave the defaults. Settings are applied in
+// increasing order of precedence: NewOptions defaults, the CLAUDE_SDK_CONFIG
+// file, the other variables, and then whatever the caller sets on the
+// returned options, so code can still override the environment:
+//
+//	options, err := claudecode.OptionsFromEnv()
+//	if err != nil {
+//		return err
+//	}
+//	options.WithAllowedTools("Read", "Grep") // always wins
+//
+// Malformed values are reported together in a *UsageError.
+func OptionsFromEnv() (*Options, error) {
+	get := func(name string) (string, bool) {
+		value, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(value) == "" {
+			return "", false
+		}
+		return value, true
+	}
+
+	options := NewOptions()
+	if path, ok := get(envConfig); ok {
+		loaded, err := LoadOptions(path)
+		if err != nil {
+			problem := fmt.Sprintf("%s: %v", envConfig, err)
+			return nil, &UsageError{Message: problem, Problems: []string{problem}}
+		}
+		options = loaded
+	}
+
+	var problems []string
+	invalid := func(name, value, kind string) {
+		problems = append(problems, fmt.Sprintf("%s: invalid %s %q", name, kind, value))
+	}
+
+	if value, ok := get(envModel); ok {
+		options.WithModel(strings.TrimSpace(value))
+	}
+	if value, ok := get(envFallbackModel); ok {
+		options.WithFallbackModel(strings.TrimSpace(value))
+	}
+	if value, ok := get(envPermissionMode); ok {
+		options.WithPermissionMode(PermissionMode(strings.TrimSpace(value)))
+	}
+	if value, ok := get(envMaxTurns); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			invalid(envMaxTurns, value, "integer")
+		} else {
+			options.WithMaxTurns(n)
+		}
+	}
+	if value, ok := get(envMaxThinkingTokens); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			invalid(envMaxThinkingTokens, value, "integer")
+		} else {
+			options.MaxThinkingTokens = n
+		}
+	}
+	if value, ok := get(envCwd); ok {
+		options.WithCwd(value)
+	}
+	if value, ok := get(envAddDirs); ok {
+		options.WithAddDirs(filepath.SplitList(value)...)
+	}
+	if value, ok := get(envSystemPrompt); ok {
+		options.WithSystemPrompt(value)
+	}
+	if value, ok := get(envAppendSystemPrompt); ok {
+		options.WithAppendSystemPrompt(value)
+	}
+	if value, ok := get(envAllowedTools); ok {
+		options.WithAllowedTools(splitEnvList(value)...)
+	}
+	if value, ok := get(envDisallowedTools); ok {
+		options.WithDisallowedTools(splitEnvList(value)...)
+	}
+	if value, ok := get(envSettings); ok {
+		options.WithSettings(value)
+	}
+	if value, ok := get(envMcpConfig); ok {
+		for _, path := range filepath.SplitList(value) {
+			if path != "" {
+				options.WithMcpConfigFile(path)
+			}
+		}
+	}
+	if value, ok := get(envOutputStyle); ok {
+		options.WithOutputStyle(strings.TrimSpace(value))
+	}
+	if value, ok := get(envMaxCostUSD); ok {
+		if limit, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil || limit <= 0 {
+			invalid(envMaxCostUSD, value, "cost")
+		} else {
+			options.WithMaxCostUSD(limit)
+		}
+	}
+
+	durations := []struct {
+		name string
+		set  func(time.Duration) *Options
+	}{
+		{envQueryTimeout, options.WithQueryTimeout},
+		{envIdleTimeout, options.WithIdleTimeout},
+		{envStartupTimeout, options.WithStartupTimeout},
+	}
+	for _, d := range durations {
+		if value, ok := get(d.name); ok {
+			if timeout, err := time.ParseDuration(strings.TrimSpace(value)); err != nil || timeout <= 0 {
+				invalid(d.name, value, "duration")
+			} else {
+				d.set(timeout)
+			}
+		}
+	}
+
+	switch len(problems) {
+	case 0:
+		return options, nil
+	case 1:
+		return nil, &UsageError{Message: problems[0], Problems: problems}
+	default:
+		return nil, &UsageError{Message: "invalid environment", Problems: problems}
+	}
+}
+
+// splitEnvList splits a comma-separated list, dropping empty entries.
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
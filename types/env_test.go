@@ -0,0 +1,101 @@
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "preset.json")
+	if err := os.WriteFile(config, []byte(`{"model": "haiku", "maxTurns": 2, "systemPrompt": "From file"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CLAUDE_SDK_CONFIG", config)
+	t.Setenv("CLAUDE_SDK_MODEL", "sonnet")
+	t.Setenv("CLAUDE_SDK_FALLBACK_MODEL", "haiku")
+	t.Setenv("CLAUDE_SDK_PERMISSION_MODE", "acceptEdits")
+	t.Setenv("CLAUDE_SDK_MAX_TURNS", " 7 ")
+	t.Setenv("CLAUDE_SDK_MAX_THINKING_TOKENS", "1000")
+	t.Setenv("CLAUDE_SDK_CWD", dir)
+	t.Setenv("CLAUDE_SDK_ADD_DIRS", strings.Join([]string{"/a", "/b"}, string(os.PathListSeparator)))
+	t.Setenv("CLAUDE_SDK_ALLOWED_TOOLS", "Read, Grep,,")
+	t.Setenv("CLAUDE_SDK_DISALLOWED_TOOLS", "Bash")
+	t.Setenv("CLAUDE_SDK_OUTPUT_STYLE", "Learning")
+	t.Setenv("CLAUDE_SDK_MCP_CONFIG", strings.Join([]string{".mcp.json", "/etc/mcp.json"}, string(os.PathListSeparator)))
+	t.Setenv("CLAUDE_SDK_MAX_COST_USD", "0.5")
+	t.Setenv("CLAUDE_SDK_QUERY_TIMEOUT", "5m")
+	t.Setenv("CLAUDE_SDK_IDLE_TIMEOUT", "")
+
+	options, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatalf("OptionsFromEnv failed: %v", err)
+	}
+
+	want := NewOptions().
+		WithModel("sonnet").
+		WithFallbackModel("haiku").
+		WithPermissionMode(PermissionModeAcceptEdits).
+		WithMaxTurns(7).
+		WithSystemPrompt("From file").
+		WithCwd(dir).
+		WithAddDirs("/a", "/b").
+		WithAllowedTools("Read", "Grep").
+		WithDisallowedTools("Bash").
+		WithOutputStyle("Learning").
+		WithMcpConfigFile(".mcp.json").
+		WithMcpConfigFile("/etc/mcp.json").
+		WithMaxCostUSD(0.5).
+		WithQueryTimeout(5 * time.Minute)
+	want.MaxThinkingTokens = 1000
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("Unexpected options:\n got %+v\nwant %+v", options, want)
+	}
+}
+
+func TestOptionsFromEnvDefaults(t *testing.T) {
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, "CLAUDE_SDK_") {
+			t.Setenv(name, "")
+		}
+	}
+
+	options, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatalf("OptionsFromEnv failed: %v", err)
+	}
+	if !reflect.DeepEqual(options, NewOptions()) {
+		t.Errorf("Expected default options, got %+v", options)
+	}
+}
+
+func TestOptionsFromEnvErrors(t *testing.T) {
+	t.Setenv("CLAUDE_SDK_MAX_TURNS", "many")
+	t.Setenv("CLAUDE_SDK_MAX_COST_USD", "-1")
+	t.Setenv("CLAUDE_SDK_STARTUP_TIMEOUT", "30")
+
+	_, err := OptionsFromEnv()
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) {
+		t.Fatalf("Expected *UsageError, got %v", err)
+	}
+	want := []string{
+		`CLAUDE_SDK_MAX_TURNS: invalid integer "many"`,
+		`CLAUDE_SDK_MAX_COST_USD: invalid cost "-1"`,
+		`CLAUDE_SDK_STARTUP_TIMEOUT: invalid duration "30"`,
+	}
+	if !reflect.DeepEqual(usageErr.Problems, want) {
+		t.Errorf("Unexpected problems: %q", usageErr.Problems)
+	}
+
+	t.Setenv("CLAUDE_SDK_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := OptionsFromEnv(); !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "CLAUDE_SDK_CONFIG") {
+		t.Errorf("Expected a config file error, got %v", err)
+	}
+}
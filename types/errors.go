@@ -0,0 +1,522 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for common cases
+var (
+	// ErrCLINotFound indicates that the Claude Code CLI is not installed or not found
+	ErrCLINotFound = errors.New("claude code cli not found")
+
+	// ErrCLIConnection indicates a connection error with the Claude Code CLI
+	ErrCLIConnection = errors.New("cli connection error")
+
+	// ErrJSONDecode indicates a JSON decoding error from CLI output
+	ErrJSONDecode = errors.New("json decode error")
+
+	// ErrStreamClosed indicates that the message stream has been closed
+	ErrStreamClosed = errors.New("message stream closed")
+
+	// ErrInvalidWorkingDirectory indicates an invalid working directory
+	ErrInvalidWorkingDirectory = errors.New("invalid working directory")
+
+	// ErrNoResult indicates that a query ended without a result message
+	ErrNoResult = errors.New("query ended without a result")
+)
+
+// CLINotFoundError represents an error when Claude Code CLI is not found.
+// It provides additional context about where the CLI was searched for.
+type CLINotFoundError struct {
+	Message string
+	CLIPath string
+	Err     error
+}
+
+func (e *CLINotFoundError) Error() string {
+	if e.CLIPath != "" {
+		return fmt.Sprintf("%s: %s", e.Message, e.CLIPath)
+	}
+	return e.Message
+}
+
+func (e *CLINotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Kind returns KindCLINotFound.
+func (e *CLINotFoundError) Kind() Kind {
+	return KindCLINotFound
+}
+
+// ProcessError represents an error from a failed CLI process.
+// It includes the exit code and stderr output for debugging.
+type ProcessError struct {
+	Message  string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *ProcessError) Error() string {
+	msg := fmt.Sprintf("%s (exit code: %d)", e.Message, e.ExitCode)
+	if e.Stderr != "" {
+		msg = fmt.Sprintf("%s\nError output: %s", msg, e.Stderr)
+	}
+	return msg
+}
+
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// Kind returns KindProcess.
+func (e *ProcessError) Kind() Kind {
+	return KindProcess
+}
+
+// JSONDecodeError represents an error when unable to decode JSON from CLI output.
+// It preserves the original line and underlying error for debugging.
+// The parser reports malformed JSON and JSON that is not a valid message,
+// such as one without a type, with this error.
+type JSONDecodeError struct {
+	Line         string
+	OriginalErr  error
+	BufferLength int
+}
+
+func (e *JSONDecodeError) Error() string {
+	truncated := e.Line
+	if len(truncated) > 100 {
+		truncated = truncated[:100] + "..."
+	}
+	if e.OriginalErr != nil {
+		return fmt.Sprintf("failed to decode JSON: %s: %v", truncated, e.OriginalErr)
+	}
+	return fmt.Sprintf("failed to decode JSON: %s", truncated)
+}
+
+func (e *JSONDecodeError) Unwrap() error {
+	return e.OriginalErr
+}
+
+// Is reports whether target is ErrJSONDecode.
+func (e *JSONDecodeError) Is(target error) bool {
+	return target == ErrJSONDecode
+}
+
+// Kind returns KindJSONDecode.
+func (e *JSONDecodeError) Kind() Kind {
+	return KindJSONDecode
+}
+
+// ConnectionError represents a connection-related error with additional context.
+type ConnectionError struct {
+	Message string
+	Err     error
+}
+
+func (e *ConnectionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrCLIConnection.
+func (e *ConnectionError) Is(target error) bool {
+	return target == ErrCLIConnection
+}
+
+// Kind returns KindConnection.
+func (e *ConnectionError) Kind() Kind {
+	return KindConnection
+}
+
+// BufferOverflowError reports a message from the CLI longer than the
+// buffer limit set by Options.MaxBufferSize. The message is skipped and
+// the stream continues with the next one.
+type BufferOverflowError struct {
+	// Size is the length of the message in bytes.
+	Size int
+
+	// Limit is the buffer limit in bytes.
+	Limit int
+
+	// Partial holds the start of the message, up to Limit bytes.
+	Partial []byte
+}
+
+func (e *BufferOverflowError) Error() string {
+	start := e.Partial
+	if len(start) > 100 {
+		start = start[:100]
+	}
+	return fmt.Sprintf("message of %d bytes exceeds the buffer limit of %d bytes (raise it with WithMaxBufferSize): data starts with %q",
+		e.Size, e.Limit, start)
+}
+
+// Kind returns KindBufferOverflow.
+func (e *BufferOverflowError) Kind() Kind {
+	return KindBufferOverflow
+}
+
+// AuthError indicates the CLI could not authenticate with the API, for
+// example because the API key is missing, invalid, or expired.
+type AuthError struct {
+	Message string
+	Stderr  string
+}
+
+func (e *AuthError) Error() string {
+	return "authentication failed: " + e.Message
+}
+
+// Kind returns KindAuth.
+func (e *AuthError) Kind() Kind {
+	return KindAuth
+}
+
+// RateLimitError indicates the API rejected a request because of rate
+// limits or overload.
+type RateLimitError struct {
+	Message string
+	// RetryAfter is the delay suggested by the API, or zero if unknown.
+	RetryAfter time.Duration
+	Stderr     string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited: %s (retry after %v)", e.Message, e.RetryAfter)
+	}
+	return "rate limited: " + e.Message
+}
+
+// Kind returns KindRateLimit.
+func (e *RateLimitError) Kind() Kind {
+	return KindRateLimit
+}
+
+// UsageError indicates the CLI was invoked incorrectly, such as with an
+// unknown flag, or cannot run in the current environment, such as with an
+// unsupported Node.js version.
+type UsageError struct {
+	Message string
+	// Problems lists each individual problem found, if more than one.
+	Problems []string
+	Stderr   string
+}
+
+func (e *UsageError) Error() string {
+	if len(e.Problems) > 1 {
+		return fmt.Sprintf("invalid usage: %s: %s", e.Message, strings.Join(e.Problems, "; "))
+	}
+	return "invalid usage: " + e.Message
+}
+
+// Kind returns KindUsage.
+func (e *UsageError) Kind() Kind {
+	return KindUsage
+}
+
+// UnknownTypeError indicates the CLI produced a message or content block of
+// a type this SDK does not recognize, reported when parsing with
+// ParseModeStrict.
+type UnknownTypeError struct {
+	// Element is "message" or "content block".
+	Element string
+	Type    string
+	Raw     json.RawMessage
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("unknown %s type %q", e.Element, e.Type)
+}
+
+// Kind returns KindJSONDecode.
+func (e *UnknownTypeError) Kind() Kind {
+	return KindJSONDecode
+}
+
+// BudgetExceededError indicates a query was stopped because its cost
+// exceeded Options.MaxCostUSD.
+type BudgetExceededError struct {
+	LimitUSD float64
+	SpentUSD float64
+	// Estimated is true when SpentUSD includes an estimate from token usage
+	// that the CLI had not yet confirmed in a result message.
+	Estimated bool
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.Estimated {
+		return fmt.Sprintf("budget exceeded: estimated cost $%.4f exceeds limit $%.4f", e.SpentUSD, e.LimitUSD)
+	}
+	return fmt.Sprintf("budget exceeded: cost $%.4f exceeds limit $%.4f", e.SpentUSD, e.LimitUSD)
+}
+
+// Kind returns KindBudgetExceeded.
+func (e *BudgetExceededError) Kind() Kind {
+	return KindBudgetExceeded
+}
+
+// TimeoutError indicates a query was stopped by Options.QueryTimeout or
+// Options.IdleTimeout. It matches context.DeadlineExceeded with errors.Is.
+type TimeoutError struct {
+	Timeout time.Duration
+	// Idle is true when the CLI produced no output for Timeout, and false
+	// when the query as a whole ran longer than Timeout.
+	Idle bool
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Idle {
+		return fmt.Sprintf("timeout: no output from CLI for %v", e.Timeout)
+	}
+	return fmt.Sprintf("timeout: query exceeded %v", e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Kind returns KindTimeout.
+func (e *TimeoutError) Kind() Kind {
+	return KindTimeout
+}
+
+// StartupTimeoutError indicates the CLI produced no output within
+// Options.StartupTimeout, suggesting it hung while starting rather than
+// that the model is slow to respond. It matches context.DeadlineExceeded
+// with errors.Is.
+type StartupTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *StartupTimeoutError) Error() string {
+	return fmt.Sprintf("timeout: CLI produced no output within %v of starting", e.Timeout)
+}
+
+func (e *StartupTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Kind returns KindTimeout.
+func (e *StartupTimeoutError) Kind() Kind {
+	return KindTimeout
+}
+
+// CLIVersionError indicates the installed CLI is older than the options of
+// a query require.
+type CLIVersionError struct {
+	// Installed is the version of the installed CLI.
+	Installed Version
+
+	// Required is the oldest version supporting all of Features.
+	Required Version
+
+	// Features lists the CLI features needed by the query that the
+	// installed version lacks.
+	Features []string
+}
+
+func (e *CLIVersionError) Error() string {
+	return fmt.Sprintf("CLI version %s is older than %s, required for %s",
+		e.Installed, e.Required, strings.Join(e.Features, ", "))
+}
+
+// Kind returns KindCLIVersion.
+func (e *CLIVersionError) Kind() Kind {
+	return KindCLIVersion
+}
+
+// Resources reported by ResourceLimitError.
+const (
+	ResourceMemory    = "memory"
+	ResourceWallClock = "wall_clock"
+)
+
+// ResourceLimitError indicates the CLI was killed for exceeding one of its
+// ResourceLimits.
+type ResourceLimitError struct {
+	// Resource is the exceeded resource, ResourceMemory or ResourceWallClock.
+	Resource string
+
+	// Limit and Used are in bytes for memory and nanoseconds for wall
+	// clock time.
+	Limit int64
+	Used  int64
+}
+
+func (e *ResourceLimitError) Error() string {
+	if e.Resource == ResourceWallClock {
+		return fmt.Sprintf("resource limit exceeded: CLI ran for %v, limit %v",
+			time.Duration(e.Used), time.Duration(e.Limit))
+	}
+	return fmt.Sprintf("resource limit exceeded: CLI used %d bytes of %s, limit %d",
+		e.Used, e.Resource, e.Limit)
+}
+
+// Kind returns KindResourceLimit.
+func (e *ResourceLimitError) Kind() Kind {
+	return KindResourceLimit
+}
+
+// NewCLINotFoundError creates a new CLINotFoundError with the given message and optional CLI path.
+func NewCLINotFoundError(message, cliPath string) *CLINotFoundError {
+	return &CLINotFoundError{
+		Message: message,
+		CLIPath: cliPath,
+		Err:     ErrCLINotFound,
+	}
+}
+
+// NewProcessError creates a new ProcessError with the given details.
+func NewProcessError(message string, exitCode int, stderr string) *ProcessError {
+	return &ProcessError{
+		Message:  message,
+		ExitCode: exitCode,
+		Stderr:   stderr,
+	}
+}
+
+// NewJSONDecodeError creates a new JSONDecodeError with the given line and original error.
+func NewJSONDecodeError(line string, originalErr error) *JSONDecodeError {
+	return &JSONDecodeError{
+		Line:        line,
+		OriginalErr: originalErr,
+	}
+}
+
+// NewConnectionError creates a new ConnectionError with the given message and underlying error.
+func NewConnectionError(message string, err error) *ConnectionError {
+	return &ConnectionError{
+		Message: message,
+		Err:     err,
+	}
+}
+
+// Kind classifies an error by its cause.
+type Kind string
+
+const (
+	// KindUnknown is an error that could not be classified.
+	KindUnknown Kind = "unknown"
+
+	// KindCLINotFound means the Claude Code CLI could not be located.
+	KindCLINotFound Kind = "cli_not_found"
+
+	// KindConnection means communication with the CLI failed.
+	KindConnection Kind = "connection"
+
+	// KindProcess means the CLI process exited unsuccessfully.
+	KindProcess Kind = "process"
+
+	// KindJSONDecode means the CLI produced output that could not be decoded.
+	KindJSONDecode Kind = "json_decode"
+
+	// KindAuth means the CLI could not authenticate with the API.
+	KindAuth Kind = "auth"
+
+	// KindRateLimit means the API rejected the request due to rate limits
+	// or overload.
+	KindRateLimit Kind = "rate_limit"
+
+	// KindPermissionDenied means an operation was not permitted.
+	KindPermissionDenied Kind = "permission_denied"
+
+	// KindUsage means the CLI was invoked with invalid arguments or options.
+	KindUsage Kind = "usage"
+
+	// KindTimeout means a deadline was exceeded.
+	KindTimeout Kind = "timeout"
+
+	// KindCanceled means the operation was cancelled by the caller.
+	KindCanceled Kind = "canceled"
+
+	// KindBudgetExceeded means a query was stopped for exceeding its cost limit.
+	KindBudgetExceeded Kind = "budget_exceeded"
+
+	// KindCLIVersion means the installed CLI is too old for the query.
+	KindCLIVersion Kind = "cli_version"
+
+	// KindResourceLimit means the CLI was killed for exceeding a resource limit.
+	KindResourceLimit Kind = "resource_limit"
+
+	// KindBufferOverflow means a message from the CLI exceeded the buffer
+	// limit and was skipped.
+	KindBufferOverflow Kind = "buffer_overflow"
+)
+
+// kinded is implemented by errors that know their own Kind.
+type kinded interface {
+	Kind() Kind
+}
+
+// ErrorKind classifies err, looking through wrapped errors. It returns
+// KindUnknown for nil or unrecognized errors.
+func ErrorKind(err error) Kind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	var k kinded
+	if errors.As(err, &k) {
+		return k.Kind()
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, ErrCLINotFound), errors.Is(err, exec.ErrNotFound):
+		return KindCLINotFound
+	case errors.Is(err, os.ErrPermission):
+		return KindPermissionDenied
+	case errors.Is(err, ErrJSONDecode):
+		return KindJSONDecode
+	case errors.Is(err, ErrCLIConnection):
+		return KindConnection
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return KindProcess
+	}
+
+	return KindUnknown
+}
+
+// IsRetryable reports whether err is likely transient, so that retrying
+// the same query may succeed.
+func IsRetryable(err error) bool {
+	switch ErrorKind(err) {
+	case KindConnection, KindProcess, KindRateLimit, KindTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPermissionDenied reports whether err was caused by a denied permission.
+func IsPermissionDenied(err error) bool {
+	return ErrorKind(err) == KindPermissionDenied
+}
+
+// IsRateLimited reports whether err was caused by API rate limiting.
+func IsRateLimited(err error) bool {
+	return ErrorKind(err) == KindRateLimit
+}
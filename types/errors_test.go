@@ -0,0 +1,68 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      Kind
+		retryable bool
+	}{
+		{"nil", nil, KindUnknown, false},
+		{"plain", errors.New("something"), KindUnknown, false},
+		{"cli not found", NewCLINotFoundError("not found", "/bin/claude"), KindCLINotFound, false},
+		{"exec not found", fmt.Errorf("start: %w", exec.ErrNotFound), KindCLINotFound, false},
+		{"connection", NewConnectionError("pipe closed", nil), KindConnection, true},
+		{"wrapped process", fmt.Errorf("query: %w", NewProcessError("failed", 1, "")), KindProcess, true},
+		{"json decode", NewJSONDecodeError("{", nil), KindJSONDecode, false},
+		{"permission", fmt.Errorf("start: %w", os.ErrPermission), KindPermissionDenied, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), KindTimeout, true},
+		{"canceled", context.Canceled, KindCanceled, false},
+		{"joined", errors.Join(errors.New("other"), NewConnectionError("reset", nil)), KindConnection, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorKind(tt.err); got != tt.kind {
+				t.Errorf("Expected kind %q, got %q", tt.kind, got)
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("Expected IsRetryable %v, got %v", tt.retryable, got)
+			}
+		})
+	}
+}
+
+func TestErrorSentinels(t *testing.T) {
+	cause := errors.New("invalid character 'x'")
+	decodeErr := NewJSONDecodeError(`{"type": x}`, cause)
+	if !errors.Is(decodeErr, ErrJSONDecode) || !errors.Is(decodeErr, cause) {
+		t.Errorf("Expected %v to match ErrJSONDecode and its cause", decodeErr)
+	}
+	if got, want := decodeErr.Error(), `failed to decode JSON: {"type": x}: invalid character 'x'`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	connErr := fmt.Errorf("query: %w", NewConnectionError("connection error: transport closed", nil))
+	if !errors.Is(connErr, ErrCLIConnection) {
+		t.Errorf("Expected %v to match ErrCLIConnection", connErr)
+	}
+	if errors.Is(connErr, ErrJSONDecode) {
+		t.Errorf("Expected %v not to match ErrJSONDecode", connErr)
+	}
+}
+
+func TestIsPermissionDeniedAndRateLimited(t *testing.T) {
+	err := fmt.Errorf("exec: %w", os.ErrPermission)
+	if !IsPermissionDenied(err) || IsRateLimited(err) {
+		t.Errorf("Expected permission denied only for %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package types
+
+// SystemSubtypeHeartbeat is the subtype of the SystemMessages the SDK
+// delivers every Options.HeartbeatInterval while a stream runs.
+// Data["last_activity"] is when the CLI last produced output, in RFC 3339
+// format with nanoseconds, and Data["idle_ms"] how many milliseconds ago
+// that was. A CLI running a long tool call still holds its pipes open, so
+// a growing idle time alone does not mean it is wedged; consumers compare
+// it with their own limits to drive watchdogs.
+const SystemSubtypeHeartbeat = "sdk_heartbeat"
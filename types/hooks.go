@@ -0,0 +1,116 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// HookEvent identifies the point in the agent loop at which a hook runs.
+type HookEvent string
+
+const (
+	// HookEventPreToolUse runs before a tool is executed and can allow, deny,
+	// or modify the tool call.
+	HookEventPreToolUse HookEvent = "PreToolUse"
+
+	// HookEventPostToolUse runs after a tool has produced its result.
+	HookEventPostToolUse HookEvent = "PostToolUse"
+
+	// HookEventUserPromptSubmit runs when a user prompt is submitted.
+	HookEventUserPromptSubmit HookEvent = "UserPromptSubmit"
+
+	// HookEventStop runs when the main agent finishes responding.
+	HookEventStop HookEvent = "Stop"
+
+	// HookEventSubagentStop runs when a subagent finishes responding.
+	HookEventSubagentStop HookEvent = "SubagentStop"
+
+	// HookEventPreCompact runs before the conversation is compacted.
+	HookEventPreCompact HookEvent = "PreCompact"
+)
+
+// HookInput contains the data the CLI passes to a hook callback.
+// Which fields are populated depends on the hook event.
+type HookInput struct {
+	HookEventName  string `json:"hook_event_name"`
+	SessionID      string `json:"session_id,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	Cwd            string `json:"cwd,omitempty"`
+
+	// ToolName and ToolInput are set for PreToolUse and PostToolUse.
+	ToolName string `json:"tool_name,omitempty"`
+	// ToolInput contains arbitrary JSON data that varies by tool.
+	ToolInput map[string]any `json:"tool_input,omitempty"`
+
+	// ToolResponse is set for PostToolUse.
+	ToolResponse any `json:"tool_response,omitempty"`
+
+	// Prompt is set for UserPromptSubmit.
+	Prompt string `json:"prompt,omitempty"`
+
+	// Raw contains the complete hook input as sent by the CLI, including
+	// any fields not mapped above.
+	Raw map[string]any `json:"-"`
+}
+
+// HookOutput is the decision a hook callback returns to the CLI.
+// The zero value lets execution continue unchanged.
+type HookOutput struct {
+	// Continue set to false stops the agent after the hook runs.
+	Continue *bool `json:"continue,omitempty"`
+
+	// StopReason is shown to the user when Continue is false.
+	StopReason string `json:"stopReason,omitempty"`
+
+	// SuppressOutput hides the hook's output from the transcript.
+	SuppressOutput bool `json:"suppressOutput,omitempty"`
+
+	// Decision is "approve" or "block" for events that support it.
+	Decision string `json:"decision,omitempty"`
+
+	// Reason explains the decision to Claude.
+	Reason string `json:"reason,omitempty"`
+
+	// SystemMessage is shown to the user.
+	SystemMessage string `json:"systemMessage,omitempty"`
+
+	// HookSpecificOutput carries event-specific fields, such as the
+	// permission decision for PreToolUse.
+	HookSpecificOutput map[string]any `json:"hookSpecificOutput,omitempty"`
+}
+
+// HookCallback is a Go function invoked by the CLI when a hook event fires.
+// The toolUseID is empty for events not associated with a tool call.
+// Returning an error reports the hook as failed to the CLI.
+type HookCallback func(ctx context.Context, input HookInput, toolUseID string) (HookOutput, error)
+
+// HookMatcher associates hook callbacks with the tools they apply to.
+type HookMatcher struct {
+	// Matcher is a tool name pattern such as "Bash" or "Write|Edit".
+	// An empty matcher applies to all tools.
+	Matcher string
+
+	// Hooks are the callbacks to run, in order.
+	Hooks []HookCallback
+
+	// Timeout is how long the CLI waits for the callbacks before giving
+	// up on them. If zero, the CLI's default applies.
+	Timeout time.Duration
+}
+
+// PreToolUseOutput builds a HookOutput carrying a PreToolUse permission
+// decision. Decision is "allow", "deny", or "ask". If updatedInput is
+// non-nil, the tool runs with that input instead of the original.
+func PreToolUseOutput(decision, reason string, updatedInput map[string]any) HookOutput {
+	specific := map[string]any{
+		"hookEventName":      string(HookEventPreToolUse),
+		"permissionDecision": decision,
+	}
+	if reason != "" {
+		specific["permissionDecisionReason"] = reason
+	}
+	if updatedInput != nil {
+		specific["updatedInput"] = updatedInput
+	}
+	return HookOutput{HookSpecificOutput: specific}
+}
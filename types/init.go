@@ -0,0 +1,104 @@
+package types
+
+import "encoding/json"
+
+// SystemSubtypeInit is the subtype of the system message the CLI sends
+// when a session starts.
+const SystemSubtypeInit = "init"
+
+// InitInfo describes what is available in a session, as reported by the
+// CLI's init system message. Fields the CLI does not report are empty.
+type InitInfo struct {
+	SessionID      string         `json:"session_id"`
+	CLIVersion     string         `json:"claude_code_version,omitempty"`
+	Cwd            string         `json:"cwd,omitempty"`
+	Model          string         `json:"model,omitempty"`
+	PermissionMode PermissionMode `json:"permissionMode,omitempty"`
+	APIKeySource   string         `json:"apiKeySource,omitempty"`
+
+	// Tools lists the tools Claude may call, including MCP tools.
+	Tools []string `json:"tools,omitempty"`
+
+	// SlashCommands lists the slash commands that can be sent as prompts,
+	// including those provided by plugins.
+	SlashCommands []string `json:"slash_commands,omitempty"`
+
+	// OutputStyle is the active output style.
+	OutputStyle string `json:"output_style,omitempty"`
+
+	// Agents lists the subagents Claude can delegate to.
+	Agents []string `json:"agents,omitempty"`
+
+	// Skills lists the skills available to Claude.
+	Skills []string `json:"skills,omitempty"`
+
+	McpServers []McpServerStatus `json:"mcp_servers,omitempty"`
+	Plugins    []PluginInfo      `json:"plugins,omitempty"`
+}
+
+// MCP server connection statuses reported in McpServerStatus.Status.
+const (
+	McpStatusConnected = "connected"
+	McpStatusFailed    = "failed"
+	McpStatusPending   = "pending"
+	McpStatusNeedsAuth = "needs-auth"
+)
+
+// McpServerStatus is the connection status of an MCP server, such as
+// McpStatusConnected or McpStatusFailed.
+type McpServerStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// ServerInfo is the name and version the server reported when it
+	// connected, if known.
+	ServerInfo *McpServerInfo `json:"serverInfo,omitempty"`
+}
+
+// McpServerInfo identifies a connected MCP server's implementation.
+type McpServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Connected reports whether the server's tools are available.
+func (s McpServerStatus) Connected() bool {
+	return s.Status == McpStatusConnected
+}
+
+// FailedMcpServers returns the MCP servers that could not be used when the
+// session started: those that failed to start or need authentication.
+// Their tools are missing from Tools.
+func (i *InitInfo) FailedMcpServers() []McpServerStatus {
+	var failed []McpServerStatus
+	for _, server := range i.McpServers {
+		if server.Status == McpStatusFailed || server.Status == McpStatusNeedsAuth {
+			failed = append(failed, server)
+		}
+	}
+	return failed
+}
+
+// PluginInfo is a plugin loaded by the CLI.
+type PluginInfo struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+}
+
+// Init returns the session information carried by an init system message.
+// It reports false for other system messages.
+func (sm *SystemMessage) Init() (*InitInfo, bool) {
+	if sm.Subtype != SystemSubtypeInit {
+		return nil, false
+	}
+
+	data, err := json.Marshal(sm.Data)
+	if err != nil {
+		return nil, false
+	}
+	var info InitInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, false
+	}
+	return &info, true
+}
@@ -0,0 +1,95 @@
+package types
+
+import "testing"
+
+func TestSystemMessageInit(t *testing.T) {
+	msg := &SystemMessage{
+		Subtype: SystemSubtypeInit,
+		Data: map[string]any{
+			"type":                "system",
+			"subtype":             "init",
+			"session_id":          "session_123",
+			"claude_code_version": "2.0.14",
+			"cwd":                 "/work",
+			"model":               "claude-sonnet-4-5",
+			"permissionMode":      "acceptEdits",
+			"apiKeySource":        "ANTHROPIC_API_KEY",
+			"tools":               []any{"Read", "Bash"},
+			"slash_commands":      []any{"compact", "review"},
+			"output_style":        "default",
+			"agents":              []any{"reviewer"},
+			"mcp_servers": []any{
+				map[string]any{"name": "github", "status": "connected"},
+			},
+			"plugins": []any{
+				map[string]any{"name": "deploy", "path": "/plugins/deploy"},
+			},
+		},
+	}
+
+	info, ok := msg.Init()
+	if !ok {
+		t.Fatal("Expected init information")
+	}
+	if info.SessionID != "session_123" || info.CLIVersion != "2.0.14" || info.Model != "claude-sonnet-4-5" {
+		t.Errorf("Unexpected session fields: %+v", info)
+	}
+	if info.PermissionMode != PermissionModeAcceptEdits {
+		t.Errorf("Expected acceptEdits permission mode, got %q", info.PermissionMode)
+	}
+	if len(info.Tools) != 2 || len(info.SlashCommands) != 2 || info.SlashCommands[1] != "review" {
+		t.Errorf("Unexpected tools or commands: %v %v", info.Tools, info.SlashCommands)
+	}
+	if info.OutputStyle != "default" || len(info.Agents) != 1 {
+		t.Errorf("Unexpected output style or agents: %q %v", info.OutputStyle, info.Agents)
+	}
+	if len(info.McpServers) != 1 || info.McpServers[0] != (McpServerStatus{Name: "github", Status: "connected"}) {
+		t.Errorf("Unexpected MCP servers: %v", info.McpServers)
+	}
+	if len(info.Plugins) != 1 || info.Plugins[0] != (PluginInfo{Name: "deploy", Path: "/plugins/deploy"}) {
+		t.Errorf("Unexpected plugins: %v", info.Plugins)
+	}
+
+	if _, ok := (&SystemMessage{Subtype: SystemSubtypeWarning}).Init(); ok {
+		t.Error("Expected no init information for a warning")
+	}
+}
+
+func TestFailedMcpServers(t *testing.T) {
+	info := &InitInfo{McpServers: []McpServerStatus{
+		{Name: "github", Status: McpStatusConnected, ServerInfo: &McpServerInfo{Name: "github-mcp", Version: "1.2.0"}},
+		{Name: "postgres", Status: McpStatusFailed},
+		{Name: "linear", Status: McpStatusNeedsAuth},
+		{Name: "search", Status: McpStatusPending},
+	}}
+
+	failed := info.FailedMcpServers()
+	if len(failed) != 2 || failed[0].Name != "postgres" || failed[1].Name != "linear" {
+		t.Errorf("Unexpected failed servers: %v", failed)
+	}
+	if !info.McpServers[0].Connected() || info.McpServers[3].Connected() {
+		t.Error("Expected only the first server to be connected")
+	}
+	if (&InitInfo{}).FailedMcpServers() != nil {
+		t.Error("Expected no failed servers without MCP servers")
+	}
+}
+
+func TestSystemMessageCompactBoundary(t *testing.T) {
+	msg := &SystemMessage{
+		Subtype: SystemSubtypeCompactBoundary,
+		Data: map[string]any{
+			"subtype":          SystemSubtypeCompactBoundary,
+			"compact_metadata": map[string]any{"trigger": "auto", "pre_tokens": float64(180000)},
+		},
+	}
+
+	boundary, ok := msg.CompactBoundary()
+	if !ok || *boundary != (CompactBoundary{Trigger: CompactTriggerAuto, PreTokens: 180000}) {
+		t.Errorf("Unexpected compact boundary: %+v", boundary)
+	}
+
+	if _, ok := (&SystemMessage{Subtype: SystemSubtypeInit}).CompactBoundary(); ok {
+		t.Error("Expected init message not to be a compact boundary")
+	}
+}
@@ -0,0 +1,269 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Messages and content blocks are encoded as JSON objects whose "type"
+// field holds their Type, so that a decoder can tell which concrete type
+// to produce. json.Marshal adds the field through the MarshalJSON methods
+// below; UnmarshalMessage and UnmarshalContentBlock read it back. This is
+// the SDK's own encoding of the Go types, not the CLI's stream-json
+// format, in which assistant and user messages are wrapped in a "message"
+// object.
+
+// marshalTyped encodes v, which must encode as a JSON object, with a
+// leading "type" field.
+func marshalTyped(typ string, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	typeField, err := json.Marshal(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.Grow(len(data) + len(typeField) + 9)
+	b.WriteString(`{"type":`)
+	b.Write(typeField)
+	if rest := data[1:]; !bytes.Equal(rest, []byte("}")) {
+		b.WriteByte(',')
+		b.Write(rest)
+	} else {
+		b.WriteByte('}')
+	}
+	return b.Bytes(), nil
+}
+
+// typeOf reads the "type" field of a JSON object.
+func typeOf(data []byte) (string, error) {
+	var head struct {
+		Type *string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return "", err
+	}
+	if head.Type == nil {
+		return "", fmt.Errorf("missing 'type' field")
+	}
+	return *head.Type, nil
+}
+
+// UnmarshalMessage decodes a message encoded by json.Marshal, choosing its
+// concrete type from its "type" field. Messages of types this SDK does not
+// recognize are returned as UnknownMessage values holding the object.
+func UnmarshalMessage(data []byte) (Message, error) {
+	typ, err := typeOf(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+
+	var msg Message
+	switch typ {
+	case "user":
+		msg = &UserMessage{}
+	case "assistant":
+		msg = &AssistantMessage{}
+	case "system":
+		msg = &SystemMessage{}
+	case "result":
+		msg = &ResultMessage{}
+	case "tool_progress":
+		msg = &ProgressEvent{}
+	default:
+		msg = &UnknownMessage{}
+	}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s message: %w", typ, err)
+	}
+	return msg, nil
+}
+
+// UnmarshalContentBlock decodes a content block encoded by json.Marshal,
+// choosing its concrete type from its "type" field. Blocks of types this
+// SDK does not recognize are returned as UnknownBlock values holding the
+// object.
+func UnmarshalContentBlock(data []byte) (ContentBlock, error) {
+	typ, err := typeOf(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content block: %w", err)
+	}
+
+	var block ContentBlock
+	switch typ {
+	case "text":
+		block = &TextBlock{}
+	case "thinking":
+		block = &ThinkingBlock{}
+	case "tool_use":
+		block = &ToolUseBlock{}
+	case "tool_result":
+		block = &ToolResultBlock{}
+	default:
+		block = &UnknownBlock{}
+	}
+	if err := json.Unmarshal(data, block); err != nil {
+		return nil, fmt.Errorf("failed to decode %s block: %w", typ, err)
+	}
+	return block, nil
+}
+
+// unmarshalContentBlocks decodes an array of content blocks. A missing or
+// null array decodes to nil.
+func unmarshalContentBlocks(data []json.RawMessage) ([]ContentBlock, error) {
+	if data == nil {
+		return nil, nil
+	}
+	blocks := make([]ContentBlock, 0, len(data))
+	for _, item := range data {
+		block, err := UnmarshalContentBlock(item)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// MarshalJSON encodes the block with its "type" field.
+func (tb *TextBlock) MarshalJSON() ([]byte, error) {
+	type plain TextBlock
+	return marshalTyped(tb.Type(), (*plain)(tb))
+}
+
+// MarshalJSON encodes the block with its "type" field.
+func (tb *ThinkingBlock) MarshalJSON() ([]byte, error) {
+	type plain ThinkingBlock
+	return marshalTyped(tb.Type(), (*plain)(tb))
+}
+
+// MarshalJSON encodes the block with its "type" field.
+func (tub *ToolUseBlock) MarshalJSON() ([]byte, error) {
+	type plain ToolUseBlock
+	return marshalTyped(tub.Type(), (*plain)(tub))
+}
+
+// MarshalJSON encodes the block with its "type" field. Its content parts
+// are encoded in the CLI's content format, which UnmarshalJSON reads.
+func (trb *ToolResultBlock) MarshalJSON() ([]byte, error) {
+	type plain ToolResultBlock
+	return marshalTyped(trb.Type(), (*plain)(trb))
+}
+
+// MarshalJSON encodes the block as the object it was received as, which
+// holds its type. A block without one is encoded with its type alone.
+func (ub *UnknownBlock) MarshalJSON() ([]byte, error) {
+	if len(ub.Raw) > 0 {
+		return ub.Raw, nil
+	}
+	return marshalTyped(ub.BlockType, struct{}{})
+}
+
+// UnmarshalJSON keeps the object as the block's Raw JSON.
+func (ub *UnknownBlock) UnmarshalJSON(data []byte) error {
+	typ, err := typeOf(data)
+	if err != nil {
+		return err
+	}
+	ub.BlockType = typ
+	ub.Raw = bytes.Clone(data)
+	return nil
+}
+
+// MarshalJSON encodes the message with its "type" field, and the type of
+// each of its content blocks.
+func (um *UserMessage) MarshalJSON() ([]byte, error) {
+	type plain UserMessage
+	return marshalTyped(um.Type(), (*plain)(um))
+}
+
+// UnmarshalJSON decodes the message and its typed content blocks.
+func (um *UserMessage) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Content string            `json:"content"`
+		Blocks  []json.RawMessage `json:"blocks"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	blocks, err := unmarshalContentBlocks(raw.Blocks)
+	if err != nil {
+		return err
+	}
+
+	um.Content = raw.Content
+	um.Blocks = blocks
+	return nil
+}
+
+// MarshalJSON encodes the message with its "type" field, and the type of
+// each of its content blocks.
+func (am *AssistantMessage) MarshalJSON() ([]byte, error) {
+	type plain AssistantMessage
+	return marshalTyped(am.Type(), (*plain)(am))
+}
+
+// UnmarshalJSON decodes the message and its typed content blocks.
+func (am *AssistantMessage) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Content []json.RawMessage `json:"content"`
+		ID      string            `json:"id"`
+		Model   string            `json:"model"`
+		Usage   map[string]any    `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	content, err := unmarshalContentBlocks(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	am.Content = content
+	am.ID = raw.ID
+	am.Model = raw.Model
+	am.Usage = raw.Usage
+	return nil
+}
+
+// MarshalJSON encodes the message with its "type" field.
+func (sm *SystemMessage) MarshalJSON() ([]byte, error) {
+	type plain SystemMessage
+	return marshalTyped(sm.Type(), (*plain)(sm))
+}
+
+// MarshalJSON encodes the message with its "type" field.
+func (rm *ResultMessage) MarshalJSON() ([]byte, error) {
+	type plain ResultMessage
+	return marshalTyped(rm.Type(), (*plain)(rm))
+}
+
+// MarshalJSON encodes the event with its "type" field.
+func (pe *ProgressEvent) MarshalJSON() ([]byte, error) {
+	type plain ProgressEvent
+	return marshalTyped(pe.Type(), (*plain)(pe))
+}
+
+// MarshalJSON encodes the message as the object it was received as, which
+// holds its type. A message without one is encoded with its type alone.
+func (um *UnknownMessage) MarshalJSON() ([]byte, error) {
+	if len(um.Raw) > 0 {
+		return um.Raw, nil
+	}
+	return marshalTyped(um.MessageType, struct{}{})
+}
+
+// UnmarshalJSON keeps the object as the message's Raw JSON.
+func (um *UnknownMessage) UnmarshalJSON(data []byte) error {
+	typ, err := typeOf(data)
+	if err != nil {
+		return err
+	}
+	um.MessageType = typ
+	um.Raw = bytes.Clone(data)
+	return nil
+}
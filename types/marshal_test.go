@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	cost := 0.25
+	text := "done"
+	isError := true
+
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"user text", &UserMessage{Content: "Hello"}},
+		{"user blocks", &UserMessage{Content: "Tool results: 2 items", Blocks: []ContentBlock{
+			&ToolResultBlock{ToolUseID: "tool-1", Content: []ToolResultContent{&TextContent{Text: "ok"}}},
+			&ToolResultBlock{ToolUseID: "tool-2", IsError: &isError, Content: []ToolResultContent{
+				&TextContent{Text: "see image"},
+				&ImageContent{MediaType: "image/png", Data: "aGk="},
+			}},
+		}}},
+		{"assistant", &AssistantMessage{
+			ID:    "msg_1",
+			Model: "claude-sonnet-4",
+			Usage: map[string]any{"output_tokens": float64(10)},
+			Content: []ContentBlock{
+				&ThinkingBlock{Thinking: "hmm", Signature: "sig"},
+				&TextBlock{Text: "Let me look"},
+				&ToolUseBlock{ID: "tool-1", Name: "Read", Input: map[string]any{"file_path": "main.go"}},
+				&UnknownBlock{BlockType: "server_tool_use", Raw: json.RawMessage(`{"id":"srv-1","type":"server_tool_use"}`)},
+			},
+		}},
+		{"system", &SystemMessage{Subtype: "init", Data: map[string]any{"type": "system", "subtype": "init", "session_id": "s1"}}},
+		{"result", &ResultMessage{
+			Subtype:           "success",
+			DurationMs:        1200,
+			NumTurns:          2,
+			SessionID:         "s1",
+			TotalCostUSD:      &cost,
+			Result:            &text,
+			PermissionDenials: []PermissionDenial{{ToolName: "Bash", ToolUseID: "tool-3"}},
+			ModelUsage:        map[string]ModelUsage{"claude-sonnet-4": {OutputTokens: 10, CostUSD: 0.25}},
+		}},
+		{"progress", &ProgressEvent{ToolUseID: "tool-1", ToolName: "Bash", Elapsed: 2 * time.Second, Status: ProgressStatusRunning}},
+		{"unknown", &UnknownMessage{MessageType: "stream_event", Raw: json.RawMessage(`{"event":{"delta":"x"},"type":"stream_event"}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), `{"type":"`+tt.msg.Type()+`"`) && tt.name != "unknown" {
+				t.Errorf("Expected the type field first, got %s", data)
+			}
+
+			decoded, err := UnmarshalMessage(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, tt.msg) {
+				t.Errorf("Expected %#v, got %#v from %s", tt.msg, decoded, data)
+			}
+		})
+	}
+}
+
+func TestContentBlockJSON(t *testing.T) {
+	data, err := json.Marshal([]ContentBlock{&TextBlock{Text: "hi"}, &ToolUseBlock{ID: "t1", Name: "Bash"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"type":"text","text":"hi"},{"type":"tool_use","id":"t1","name":"Bash","input":null}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	block, err := UnmarshalContentBlock([]byte(`{"type":"thinking","thinking":"hmm"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thinking, ok := block.(*ThinkingBlock); !ok || thinking.Thinking != "hmm" {
+		t.Errorf("Expected a thinking block, got %#v", block)
+	}
+
+	block, err = UnmarshalContentBlock([]byte(`{"type":"image","source":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unknown, ok := block.(*UnknownBlock); !ok || unknown.BlockType != "image" {
+		t.Errorf("Expected an unknown block, got %#v", block)
+	}
+
+	if data, _ := json.Marshal(&UnknownBlock{BlockType: "image"}); string(data) != `{"type":"image"}` {
+		t.Errorf("Expected an unknown block without raw JSON to encode its type, got %s", data)
+	}
+}
+
+func TestUnmarshalMessageErrors(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{`{"content":"no type"}`, "missing 'type' field"},
+		{`[1]`, "failed to decode message"},
+		{`{"type":"assistant","content":[{"text":"untyped"}]}`, "missing 'type' field"},
+		{`{"type":"result","num_turns":"two"}`, "failed to decode result message"},
+	}
+
+	for _, tt := range tests {
+		if _, err := UnmarshalMessage([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.data, tt.expected, err)
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package types
 
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
 // ContentBlock represents a piece of content within a message.
-// Implementations include TextBlock, ToolUseBlock, and ToolResultBlock.
+// Implementations include TextBlock, ThinkingBlock, ToolUseBlock, and ToolResultBlock.
 type ContentBlock interface {
 	Type() string
 }
@@ -16,6 +22,17 @@ func (tb *TextBlock) Type() string {
 	return "text"
 }
 
+// ThinkingBlock represents Claude's extended thinking output.
+type ThinkingBlock struct {
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature"`
+}
+
+// Type returns the content block type identifier.
+func (tb *ThinkingBlock) Type() string {
+	return "thinking"
+}
+
 // ToolUseBlock represents a tool use content block.
 type ToolUseBlock struct {
 	ID   string `json:"id"`
@@ -32,9 +49,13 @@ func (tub *ToolUseBlock) Type() string {
 
 // ToolResultBlock represents a tool result content block.
 type ToolResultBlock struct {
-	ToolUseID string  `json:"tool_use_id"`
-	Content   *string `json:"content,omitempty"`
-	IsError   *bool   `json:"is_error,omitempty"`
+	ToolUseID string `json:"tool_use_id"`
+
+	// Content holds the result's parts. The CLI reports most results as a
+	// single string, which is delivered as one TextContent.
+	Content []ToolResultContent `json:"content,omitempty"`
+
+	IsError *bool `json:"is_error,omitempty"`
 }
 
 // Type returns the content block type identifier.
@@ -42,8 +63,21 @@ func (trb *ToolResultBlock) Type() string {
 	return "tool_result"
 }
 
+// UnknownBlock is a content block of a type this SDK does not recognize,
+// delivered when parsing with ParseModePassthrough.
+type UnknownBlock struct {
+	BlockType string          `json:"type"`
+	Raw       json.RawMessage `json:"raw"`
+}
+
+// Type returns the content block type reported by the CLI.
+func (ub *UnknownBlock) Type() string {
+	return ub.BlockType
+}
+
 // Message represents a message in the conversation.
-// Implementations include UserMessage, AssistantMessage, SystemMessage, and ResultMessage.
+// Implementations include UserMessage, AssistantMessage, SystemMessage,
+// ResultMessage, and ProgressEvent.
 type Message interface {
 	Type() string
 }
@@ -51,6 +85,11 @@ type Message interface {
 // UserMessage represents a message from the user.
 type UserMessage struct {
 	Content string `json:"content"`
+
+	// Blocks holds the content blocks of a message with array content,
+	// such as the ToolResultBlocks the CLI sends after running tools. For
+	// such messages Content is only a summary.
+	Blocks []ContentBlock `json:"blocks,omitempty"`
 }
 
 // Type returns the message type identifier.
@@ -61,6 +100,16 @@ func (um *UserMessage) Type() string {
 // AssistantMessage represents a message from the assistant with content blocks.
 type AssistantMessage struct {
 	Content []ContentBlock `json:"content"`
+
+	// ID is the API message ID. The CLI may split one API message into
+	// several assistant messages that share an ID.
+	ID string `json:"id,omitempty"`
+
+	// Model is the model that produced the message.
+	Model string `json:"model,omitempty"`
+
+	// Usage contains the token usage reported for the API message so far.
+	Usage map[string]any `json:"usage,omitempty"`
 }
 
 // Type returns the message type identifier.
@@ -68,6 +117,31 @@ func (am *AssistantMessage) Type() string {
 	return "assistant"
 }
 
+// Text returns the concatenated text of the message's TextBlocks.
+func (am *AssistantMessage) Text() string {
+	var text strings.Builder
+	for _, block := range am.Content {
+		if textBlock, ok := block.(*TextBlock); ok {
+			text.WriteString(textBlock.Text)
+		}
+	}
+	return text.String()
+}
+
+// Plan returns the plan Claude proposed in the message by calling the
+// ExitPlanMode tool, which it does in plan mode once it is ready to make
+// changes. ok is false if the message contains no such call.
+func (am *AssistantMessage) Plan() (plan string, ok bool) {
+	for _, block := range am.Content {
+		if tool, isTool := block.(*ToolUseBlock); isTool && tool.Name == "ExitPlanMode" {
+			if input, err := DecodeToolInput[ExitPlanModeInput](tool); err == nil {
+				return input.Plan, true
+			}
+		}
+	}
+	return "", false
+}
+
 // SystemMessage represents a system message with metadata.
 type SystemMessage struct {
 	Subtype string `json:"subtype"`
@@ -94,6 +168,36 @@ type ResultMessage struct {
 	// Using map[string]any here is necessary to handle dynamic usage metrics.
 	Usage  map[string]any `json:"usage,omitempty"`
 	Result *string        `json:"result,omitempty"`
+
+	// PermissionDenials lists the tool uses refused during the run because
+	// permission was denied. Older CLIs do not report denials.
+	PermissionDenials []PermissionDenial `json:"permission_denials,omitempty"`
+
+	// ModelUsage holds the usage of each model that answered during the
+	// run, by model name, including a fallback model used because the
+	// main model was overloaded. Older CLIs do not report it.
+	ModelUsage map[string]ModelUsage `json:"modelUsage,omitempty"`
+}
+
+// ModelUsage is the usage of one model during a run.
+type ModelUsage struct {
+	InputTokens              int     `json:"inputTokens"`
+	OutputTokens             int     `json:"outputTokens"`
+	CacheReadInputTokens     int     `json:"cacheReadInputTokens"`
+	CacheCreationInputTokens int     `json:"cacheCreationInputTokens"`
+	WebSearchRequests        int     `json:"webSearchRequests"`
+	CostUSD                  float64 `json:"costUSD"`
+}
+
+// Models returns the names of the models that answered during the run,
+// sorted, such as the main model and any fallback model that was used.
+func (rm *ResultMessage) Models() []string {
+	models := make([]string, 0, len(rm.ModelUsage))
+	for model := range rm.ModelUsage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
 }
 
 // Type returns the message type identifier.
@@ -101,6 +205,64 @@ func (rm *ResultMessage) Type() string {
 	return "result"
 }
 
+// DeniedTools returns the names of the tools that were denied permission,
+// in the order they were first denied.
+func (rm *ResultMessage) DeniedTools() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, denial := range rm.PermissionDenials {
+		if !seen[denial.ToolName] {
+			seen[denial.ToolName] = true
+			names = append(names, denial.ToolName)
+		}
+	}
+	return names
+}
+
+// FailedOnPermissions reports whether denied permissions are the only
+// problem the run reported: at least one tool use was denied, yet the
+// result is not otherwise an error. Such runs end normally, but usually
+// without finishing the task; allow the tools in DeniedTools and retry.
+func (rm *ResultMessage) FailedOnPermissions() bool {
+	return len(rm.PermissionDenials) > 0 && !rm.IsError && rm.Subtype == "success"
+}
+
+// PermissionDenial is a tool use the CLI refused because permission was
+// denied, reported in ResultMessage.PermissionDenials.
+type PermissionDenial struct {
+	ToolName  string         `json:"tool_name"`
+	ToolUseID string         `json:"tool_use_id"`
+	ToolInput map[string]any `json:"tool_input,omitempty"`
+}
+
+// UnknownMessage is a message of a type this SDK does not recognize,
+// delivered when parsing with ParseModePassthrough.
+type UnknownMessage struct {
+	MessageType string          `json:"type"`
+	Raw         json.RawMessage `json:"raw"`
+}
+
+// Type returns the message type reported by the CLI.
+func (um *UnknownMessage) Type() string {
+	return um.MessageType
+}
+
+// ParseMode controls how messages and content blocks of unrecognized types
+// are handled, such as those added by newer CLI versions.
+type ParseMode string
+
+const (
+	// ParseModeLenient skips unrecognized types. This is the default.
+	ParseModeLenient ParseMode = "lenient"
+
+	// ParseModePassthrough delivers unrecognized types as UnknownMessage
+	// and UnknownBlock values carrying the raw JSON.
+	ParseModePassthrough ParseMode = "passthrough"
+
+	// ParseModeStrict reports unrecognized types as UnknownTypeError errors.
+	ParseModeStrict ParseMode = "strict"
+)
+
 // PermissionMode defines the permission handling mode for tool execution.
 type PermissionMode string
 
@@ -114,4 +276,9 @@ const (
 	// PermissionModeBypassPermissions allows all tools without prompting.
 	// Use with caution as this bypasses all safety checks.
 	PermissionModeBypassPermissions PermissionMode = "bypassPermissions"
+
+	// PermissionModePlan lets Claude read and research but not edit files or
+	// run commands. Claude ends planning by calling the ExitPlanMode tool
+	// with its plan; see AssistantMessage.Plan.
+	PermissionModePlan PermissionMode = "plan"
 )
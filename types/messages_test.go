@@ -41,7 +41,7 @@ func TestTextBlockJSON(t *testing.T) {
 		t.Fatalf("Failed to marshal TextBlock: %v", err)
 	}
 	
-	expected := `{"text":"test content"}`
+	expected := `{"type":"text","text":"test content"}`
 	if string(data) != expected {
 		t.Errorf("JSON marshal = %v, want %v", string(data), expected)
 	}
@@ -57,6 +57,20 @@ func TestTextBlockJSON(t *testing.T) {
 	}
 }
 
+func TestThinkingBlockJSON(t *testing.T) {
+	tb := &ThinkingBlock{Thinking: "reasoning", Signature: "sig"}
+
+	data, err := json.Marshal(tb)
+	if err != nil {
+		t.Fatalf("Failed to marshal ThinkingBlock: %v", err)
+	}
+
+	expected := `{"type":"thinking","thinking":"reasoning","signature":"sig"}`
+	if string(data) != expected {
+		t.Errorf("JSON marshal = %v, want %v", string(data), expected)
+	}
+}
+
 func TestToolUseBlock(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -153,20 +167,20 @@ func TestToolUseBlockJSON(t *testing.T) {
 }
 
 func TestToolResultBlock(t *testing.T) {
-	content := "File read successfully"
+	content := []ToolResultContent{&TextContent{Text: "File read successfully"}}
 	isError := false
 	isErrorTrue := true
 
 	tests := []struct {
 		name      string
 		toolUseID string
-		content   *string
+		content   []ToolResultContent
 		isError   *bool
 	}{
 		{
 			name:      "successful result",
 			toolUseID: "tool_123",
-			content:   &content,
+			content:   content,
 			isError:   &isError,
 		},
 		{
@@ -208,12 +222,12 @@ func TestToolResultBlock(t *testing.T) {
 }
 
 func TestToolResultBlockJSON(t *testing.T) {
-	content := "Operation completed"
+	content := []ToolResultContent{&TextContent{Text: "Operation completed"}}
 	isError := false
 	
 	trb := &ToolResultBlock{
 		ToolUseID: "tool_123",
-		Content:   &content,
+		Content:   content,
 		IsError:   &isError,
 	}
 	
@@ -269,7 +283,7 @@ func TestUserMessageJSON(t *testing.T) {
 		t.Fatalf("Failed to marshal UserMessage: %v", err)
 	}
 	
-	expected := `{"content":"Test message"}`
+	expected := `{"type":"user","content":"Test message"}`
 	if string(data) != expected {
 		t.Errorf("JSON marshal = %v, want %v", string(data), expected)
 	}
@@ -317,7 +331,7 @@ func TestAssistantMessage(t *testing.T) {
 				&TextBlock{Text: "Here's the result:"},
 				&ToolResultBlock{
 					ToolUseID: "tool_123",
-					Content:   stringPtr("File contents here"),
+					Content:   []ToolResultContent{&TextContent{Text: "File contents here"}},
 					IsError:   boolPtr(false),
 				},
 				&TextBlock{Text: "The file was read successfully."},
@@ -520,6 +534,45 @@ func TestResultMessageJSON(t *testing.T) {
 	}
 }
 
+func TestAssistantMessageText(t *testing.T) {
+	msg := &AssistantMessage{Content: []ContentBlock{
+		&TextBlock{Text: "Reading the file. "},
+		&ToolUseBlock{ID: "t1", Name: "Read", Input: map[string]any{}},
+		&ThinkingBlock{Thinking: "not text"},
+		&TextBlock{Text: "Done."},
+	}}
+
+	if got := msg.Text(); got != "Reading the file. Done." {
+		t.Errorf("Text() = %q", got)
+	}
+	if got := (&AssistantMessage{}).Text(); got != "" {
+		t.Errorf("Text() of empty message = %q", got)
+	}
+}
+
+func TestResultMessageFailedOnPermissions(t *testing.T) {
+	denied := []PermissionDenial{{ToolName: "Bash", ToolUseID: "tool_1"}}
+
+	tests := []struct {
+		name   string
+		result ResultMessage
+		want   bool
+	}{
+		{"no denials", ResultMessage{Subtype: "success"}, false},
+		{"denied", ResultMessage{Subtype: "success", PermissionDenials: denied}, true},
+		{"also errored", ResultMessage{Subtype: "success", IsError: true, PermissionDenials: denied}, false},
+		{"max turns", ResultMessage{Subtype: "error_max_turns", PermissionDenials: denied}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.FailedOnPermissions(); got != tt.want {
+				t.Errorf("FailedOnPermissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPermissionModeConstants(t *testing.T) {
 	tests := []struct {
 		name string
@@ -549,6 +602,12 @@ func TestContentBlockInterface(t *testing.T) {
 		t.Errorf("TextBlock.Type() = %v, want %v", block.Type(), "text")
 	}
 	
+	// Test ThinkingBlock implements ContentBlock
+	block = &ThinkingBlock{Thinking: "hmm", Signature: "sig"}
+	if block.Type() != "thinking" {
+		t.Errorf("ThinkingBlock.Type() = %v, want %v", block.Type(), "thinking")
+	}
+	
 	// Test ToolUseBlock implements ContentBlock
 	block = &ToolUseBlock{ID: "1", Name: "test", Input: nil}
 	if block.Type() != "tool_use" {
@@ -1,5 +1,13 @@
 package types
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jrossi/claude-code-sdk-golang/models"
+)
+
 // McpServerConfig represents configuration for an MCP (Model Context Protocol) server.
 // Different server types (stdio, SSE, HTTP) implement this interface.
 type McpServerConfig interface {
@@ -19,6 +27,16 @@ func (s *StdioServerConfig) ServerType() string {
 	return "stdio"
 }
 
+// MarshalJSON encodes the configuration with its "type" field, as in the
+// CLI's MCP configuration format.
+func (s *StdioServerConfig) MarshalJSON() ([]byte, error) {
+	type config StdioServerConfig
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*config
+	}{"stdio", (*config)(s)})
+}
+
 // SSEServerConfig represents an MCP server that communicates via Server-Sent Events.
 type SSEServerConfig struct {
 	URL     string            `json:"url"`
@@ -30,6 +48,16 @@ func (s *SSEServerConfig) ServerType() string {
 	return "sse"
 }
 
+// MarshalJSON encodes the configuration with its "type" field, as in the
+// CLI's MCP configuration format.
+func (s *SSEServerConfig) MarshalJSON() ([]byte, error) {
+	type config SSEServerConfig
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*config
+	}{"sse", (*config)(s)})
+}
+
 // HTTPServerConfig represents an MCP server that communicates via HTTP.
 type HTTPServerConfig struct {
 	URL     string            `json:"url"`
@@ -41,6 +69,138 @@ func (s *HTTPServerConfig) ServerType() string {
 	return "http"
 }
 
+// MarshalJSON encodes the configuration with its "type" field, as in the
+// CLI's MCP configuration format.
+func (s *HTTPServerConfig) MarshalJSON() ([]byte, error) {
+	type config HTTPServerConfig
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*config
+	}{"http", (*config)(s)})
+}
+
+// McpServers maps server names to MCP server configurations. It decodes
+// JSON in the format of the "mcpServers" object of the CLI's .mcp.json
+// files, choosing each server's concrete type by its "type" field: "sse",
+// "http", or "stdio", which is the default when the field is absent.
+//
+// Example:
+//
+//	var servers claudecode.McpServers
+//	if err := json.Unmarshal(payload, &servers); err != nil {
+//		return err
+//	}
+//	options := claudecode.NewOptions().WithMcpServers(servers)
+type McpServers map[string]McpServerConfig
+
+// UnmarshalJSON decodes server configurations, adding them to any already
+// in the map.
+func (m *McpServers) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		return nil
+	}
+
+	if *m == nil {
+		*m = make(McpServers, len(raw))
+	}
+	for name, data := range raw {
+		server, err := decodeMcpServer(data)
+		if err != nil {
+			return fmt.Errorf("MCP server %q: %w", name, err)
+		}
+		(*m)[name] = server
+	}
+	return nil
+}
+
+// decodeMcpServer decodes an MCP server configuration. As in the CLI's
+// configuration files, a server without a type is a stdio server.
+func decodeMcpServer(data []byte) (McpServerConfig, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	var server McpServerConfig
+	switch header.Type {
+	case "", "stdio":
+		server = &StdioServerConfig{}
+	case "sse":
+		server = &SSEServerConfig{}
+	case "http":
+		server = &HTTPServerConfig{}
+	default:
+		return nil, fmt.Errorf("unknown server type %q", header.Type)
+	}
+
+	// The concrete types' MarshalJSON does not affect decoding
+	if err := json.Unmarshal(data, server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// AgentDefinition defines a subagent that Claude can delegate tasks to.
+type AgentDefinition struct {
+	// Description tells Claude when to use the agent.
+	Description string `json:"description"`
+
+	// Prompt is the agent's system prompt.
+	Prompt string `json:"prompt"`
+
+	// Tools limits the tools the agent may use. If empty, it inherits the
+	// tools of the main conversation.
+	Tools []string `json:"tools,omitempty"`
+
+	// Model selects the agent's model: "sonnet", "opus", "haiku", or
+	// "inherit". If empty, the CLI's default for subagents is used.
+	Model string `json:"model,omitempty"`
+}
+
+// PluginTypeLocal is the type of a plugin loaded from a local directory.
+const PluginTypeLocal = "local"
+
+// PluginConfig is a plugin to load into the CLI, adding slash commands,
+// agents, hooks, or MCP servers.
+type PluginConfig struct {
+	// Type is the plugin source. Only PluginTypeLocal is supported.
+	Type string `json:"type"`
+
+	// Path is the plugin's directory.
+	Path string `json:"path"`
+}
+
+// ResourceLimits constrains the CLI subprocess. Zero fields are not limited.
+// Connect returns a UsageError for a limit that cannot be enforced on the
+// current platform.
+//
+// Limits apply to the main CLI process only: MCP servers and commands it
+// starts are not counted against them.
+type ResourceLimits struct {
+	// MaxMemoryBytes limits the CLI's memory on Linux. Its private writable
+	// memory is capped with RLIMIT_DATA, so allocations past the limit
+	// fail, and it is killed with a ResourceLimitError once its resident
+	// memory, checked a few times a second, exceeds the limit. Processes
+	// the CLI starts inherit the cap as their own.
+	MaxMemoryBytes int64 `json:"maxMemoryBytes,omitempty"`
+
+	// Nice lowers (or, with privileges, raises) the CLI's CPU scheduling
+	// priority, from -20 (highest) to 19 (lowest), on Unix systems. The
+	// query fails if the priority cannot be set.
+	Nice int `json:"nice,omitempty"`
+
+	// MaxWallClock kills the CLI once it has run this long. Unlike
+	// Options.QueryTimeout it applies to each process, so every retry
+	// attempt gets the full limit.
+	MaxWallClock time.Duration `json:"maxWallClock,omitempty"`
+}
+
 // Options contains configuration options for Claude Code queries.
 type Options struct {
 	// AllowedTools specifies which tools Claude is allowed to use.
@@ -61,7 +221,19 @@ type Options struct {
 	McpTools []string `json:"mcpTools,omitempty"`
 
 	// McpServers configures MCP servers by name.
-	McpServers map[string]McpServerConfig `json:"mcpServers,omitempty"`
+	McpServers McpServers `json:"mcpServers,omitempty"`
+
+	// McpConfigFiles lists MCP configuration files, such as a project's
+	// .mcp.json, whose servers the CLI loads along with McpServers.
+	// Relative paths are relative to Cwd.
+	McpConfigFiles []string `json:"mcpConfigFiles,omitempty"`
+
+	// Agents defines subagents by name, in addition to those configured in
+	// the CLI's settings.
+	Agents map[string]AgentDefinition `json:"agents,omitempty"`
+
+	// Plugins are loaded into the CLI for this query.
+	Plugins []PluginConfig `json:"plugins,omitempty"`
 
 	// PermissionMode controls how tool permissions are handled.
 	PermissionMode *PermissionMode `json:"permissionMode,omitempty"`
@@ -72,6 +244,10 @@ type Options struct {
 	// Resume specifies a session ID to resume from.
 	Resume *string `json:"resume,omitempty"`
 
+	// ForkSession branches a resumed or continued conversation into a new
+	// session ID instead of appending to the original session.
+	ForkSession bool `json:"forkSession,omitempty"`
+
 	// MaxTurns limits the number of conversation turns.
 	MaxTurns *int `json:"maxTurns,omitempty"`
 
@@ -81,11 +257,170 @@ type Options struct {
 	// Model specifies which Claude model to use.
 	Model *string `json:"model,omitempty"`
 
+	// FallbackModel is used automatically when Model is overloaded. The
+	// models that answered are reported in ResultMessage.ModelUsage.
+	FallbackModel *string `json:"fallbackModel,omitempty"`
+
+	// StrictModels makes Validate reject a Model or FallbackModel missing
+	// from the models package. Otherwise such models, which may have been
+	// released after this SDK, are only reported by Warnings.
+	StrictModels bool `json:"strictModels,omitempty"`
+
 	// PermissionPromptToolName specifies which tool to use for permission prompts.
 	PermissionPromptToolName *string `json:"permissionPromptToolName,omitempty"`
 
 	// Cwd sets the working directory for the Claude Code session.
 	Cwd *string `json:"cwd,omitempty"`
+
+	// AddDirs grants the CLI access to directories beyond Cwd. Paths may
+	// start with ~ or contain environment variables, and relative paths
+	// are resolved against Cwd.
+	AddDirs []string `json:"addDirs,omitempty"`
+
+	// Settings is passed to the CLI's --settings flag: either the path of
+	// a settings file or an inline JSON settings document, such as hooks
+	// or sandbox configuration to apply to this query only.
+	Settings *string `json:"settings,omitempty"`
+
+	// OutputStyle selects an output style, such as "Explanatory" or
+	// "Learning", or a custom style by name. It is passed to the CLI as the
+	// outputStyle setting, merged into Settings. The active style is
+	// reported in InitInfo.OutputStyle.
+	OutputStyle *string `json:"outputStyle,omitempty"`
+
+	// AutoCompact controls whether the CLI compacts the conversation on its
+	// own as it nears the context window. Each compaction is reported by a
+	// system message with subtype SystemSubtypeCompactBoundary. If false,
+	// DISABLE_AUTO_COMPACT is set for the CLI process, and long sessions
+	// fail once the context is full unless compacted with Session.Compact.
+	// If nil, the CLI's default applies.
+	AutoCompact *bool `json:"autoCompact,omitempty"`
+
+	// APIKey sets ANTHROPIC_API_KEY for the CLI process, overriding the
+	// ambient environment. It is never serialized.
+	APIKey *string `json:"-"`
+
+	// AuthToken sets ANTHROPIC_AUTH_TOKEN for the CLI process, overriding
+	// the ambient environment. It is never serialized.
+	AuthToken *string `json:"-"`
+
+	// BaseURL sets ANTHROPIC_BASE_URL for the CLI process, e.g. to route
+	// requests through a proxy or gateway.
+	BaseURL *string `json:"baseURL,omitempty"`
+
+	// Hooks registers Go callbacks for hook events. Queries with hooks run
+	// in streaming input mode so the CLI can call back into the SDK.
+	Hooks map[HookEvent][]HookMatcher `json:"-"`
+
+	// RetryPolicy retries queries whose CLI process fails before producing
+	// any output. If nil, queries are not retried.
+	RetryPolicy *RetryPolicy `json:"-"`
+
+	// ReconnectPolicy restarts a CLI process that fails after a query or
+	// session has started, resuming its conversation. If nil, failures end
+	// the stream.
+	ReconnectPolicy *ReconnectPolicy `json:"-"`
+
+	// TerminationGracePeriod is how long the CLI is given to exit after
+	// being interrupted when a query is cancelled or closed, so it can save
+	// its session. If nil or zero, the process is killed immediately.
+	TerminationGracePeriod *time.Duration `json:"terminationGracePeriod,omitempty"`
+
+	// MaxBufferSize limits the size in bytes of a single message read from
+	// the CLI. If nil, the transport and parser defaults (1MB) are used.
+	MaxBufferSize *int `json:"maxBufferSize,omitempty"`
+
+	// ParseMode controls how message and content block types unknown to
+	// this SDK are handled. If nil, they are skipped.
+	ParseMode *ParseMode `json:"parseMode,omitempty"`
+
+	// RawMessageHandler is called with each JSON line read from the CLI,
+	// including control protocol traffic, before it is parsed. It runs on
+	// the stream's reading goroutine, so a slow handler delays messages. The
+	// handler must not modify the line.
+	RawMessageHandler func(line json.RawMessage) `json:"-"`
+
+	// CompletionCallback is called exactly once when a query ends, however
+	// it ends, with a summary of its final status, cost, session, and
+	// error. It runs once the Messages and Errors channels are closed, or
+	// before the query returns its error if it fails to start. For
+	// sessions it is called when the session ends, with its last result.
+	CompletionCallback func(summary *ResultSummary) `json:"-"`
+
+	// MaxCostUSD stops a query or session once its cost exceeds this many
+	// US dollars, killing the CLI and reporting a BudgetExceededError. Cost
+	// is estimated from token usage as messages arrive and corrected by the
+	// cost the CLI reports in each result. If nil, cost is not limited.
+	MaxCostUSD *float64 `json:"maxCostUSD,omitempty"`
+
+	// QueryTimeout stops a query that runs longer than this, killing the
+	// CLI and reporting a TimeoutError. For sessions it limits the whole
+	// session. If nil or zero, only the context limits the query.
+	QueryTimeout *time.Duration `json:"queryTimeout,omitempty"`
+
+	// IdleTimeout stops a query when the CLI produces no output for this
+	// long, killing it and reporting a TimeoutError. Sessions only count
+	// time spent waiting for a response to a sent message. If nil or zero,
+	// idle CLIs are not detected.
+	IdleTimeout *time.Duration `json:"idleTimeout,omitempty"`
+
+	// StartupTimeout stops a query when the CLI produces no output at all
+	// within this long, reporting a StartupTimeoutError. For sessions it is
+	// measured from the first sent message. If nil or zero, startup is not
+	// limited separately.
+	StartupTimeout *time.Duration `json:"startupTimeout,omitempty"`
+
+	// HeartbeatInterval delivers a SystemMessage with subtype
+	// SystemSubtypeHeartbeat this often while a query or session runs,
+	// reporting when the CLI last produced output. If nil or zero, no
+	// heartbeats are sent.
+	HeartbeatInterval *time.Duration `json:"heartbeatInterval,omitempty"`
+
+	// CLIVersionCheck checks the installed CLI's version before a query
+	// starts, warning or failing when it is older than the query's options
+	// require. If nil, the version is not checked.
+	CLIVersionCheck *VersionCheck `json:"cliVersionCheck,omitempty"`
+
+	// ProbeCLIFlags runs "claude --help" before a query and adapts options
+	// the installed CLI doesn't support, using an alternative flag or
+	// ignoring the option. Each ignored option is reported in a
+	// SystemMessage with subtype SystemSubtypeWarning at the start of the
+	// stream. Options the query cannot run without are always passed.
+	ProbeCLIFlags bool `json:"probeCLIFlags,omitempty"`
+
+	// CLISearchPaths lists CLI binaries, or directories containing one, to
+	// try before PATH and the usual installation directories when no CLI
+	// path is configured. The CLAUDE_CLI_PATH environment variable takes
+	// precedence over them.
+	CLISearchPaths []string `json:"cliSearchPaths,omitempty"`
+
+	// ResourceLimits constrains the CLI subprocess, which is killed with a
+	// ResourceLimitError when a limit is exceeded. If nil, the process is
+	// not limited.
+	ResourceLimits *ResourceLimits `json:"resourceLimits,omitempty"`
+
+	// RateLimiter, if set, is waited on before each CLI process starts,
+	// including retry attempts. Share one limiter between queries to
+	// limit their combined rate.
+	RateLimiter RateLimiter `json:"-"`
+
+	// RateLimitTurns also waits on RateLimiter before each message sent in
+	// an interactive session, since every turn makes API requests.
+	RateLimitTurns bool `json:"rateLimitTurns,omitempty"`
+
+	// ResponseCache, if set, is checked before a query starts the CLI,
+	// under the key CacheKey derives from the prompt and options. A hit
+	// replays the cached output; a miss runs the CLI and caches its output
+	// if the query succeeds. Queries that resume or continue a
+	// conversation, or use the control protocol for hooks or permission
+	// callbacks, are not cached, and neither are sessions.
+	ResponseCache ResponseCache `json:"-"`
+
+	// ExtraArgs passes CLI flags the SDK has no option for, by name with
+	// or without the leading "--". A nil value passes the flag alone;
+	// otherwise the value follows it. Flags are passed in name order,
+	// before the prompt.
+	ExtraArgs map[string]*string `json:"extraArgs,omitempty"`
 }
 
 // NewOptions creates a new Options instance with sensible defaults.
@@ -94,7 +429,7 @@ func NewOptions() *Options {
 		AllowedTools:         []string{},
 		MaxThinkingTokens:    8000,
 		McpTools:             []string{},
-		McpServers:           make(map[string]McpServerConfig),
+		McpServers:           make(McpServers),
 		ContinueConversation: false,
 		DisallowedTools:      []string{},
 	}
@@ -136,18 +471,70 @@ func (o *Options) WithMaxTurns(turns int) *Options {
 	return o
 }
 
-// WithModel sets the model for the options.
+// WithModel sets the model for the options. Known models are spelled as
+// the models package registers them, so " Sonnet" becomes "sonnet";
+// aliases are not expanded.
 func (o *Options) WithModel(model string) *Options {
+	model = models.Normalize(model)
 	o.Model = &model
 	return o
 }
 
+// WithFallbackModel sets the model used when the main model is
+// overloaded, normalized as WithModel does.
+func (o *Options) WithFallbackModel(model string) *Options {
+	model = models.Normalize(model)
+	o.FallbackModel = &model
+	return o
+}
+
 // WithCwd sets the working directory for the options.
 func (o *Options) WithCwd(cwd string) *Options {
 	o.Cwd = &cwd
 	return o
 }
 
+// WithAddDirs grants the CLI access to additional directories, such as
+// other project roots.
+func (o *Options) WithAddDirs(dirs ...string) *Options {
+	o.AddDirs = append(o.AddDirs, dirs...)
+	return o
+}
+
+// WithSettings loads additional CLI settings from the file at path.
+func (o *Options) WithSettings(path string) *Options {
+	o.Settings = &path
+	return o
+}
+
+// WithSettingsJSON passes a JSON settings document to the CLI, so settings
+// can ship with the query instead of being written to disk first.
+func (o *Options) WithSettingsJSON(raw []byte) *Options {
+	settings := string(raw)
+	o.Settings = &settings
+	return o
+}
+
+// WithOutputStyle selects the output style for the options.
+func (o *Options) WithOutputStyle(name string) *Options {
+	o.OutputStyle = &name
+	return o
+}
+
+// WithStrictModels sets whether Validate rejects models missing from the
+// models package.
+func (o *Options) WithStrictModels(strict bool) *Options {
+	o.StrictModels = strict
+	return o
+}
+
+// WithAutoCompact sets whether the CLI compacts the conversation
+// automatically as it nears the context window.
+func (o *Options) WithAutoCompact(enabled bool) *Options {
+	o.AutoCompact = &enabled
+	return o
+}
+
 // WithContinueConversation enables conversation continuation.
 func (o *Options) WithContinueConversation() *Options {
 	o.ContinueConversation = true
@@ -160,17 +547,244 @@ func (o *Options) WithResume(sessionID string) *Options {
 	return o
 }
 
+// WithForkSession controls whether resuming a session branches into a new
+// session ID. The new ID is reported in the ResultMessage.
+func (o *Options) WithForkSession(fork bool) *Options {
+	o.ForkSession = fork
+	return o
+}
+
+// WithAPIKey sets the Anthropic API key used by the CLI for this query.
+func (o *Options) WithAPIKey(key string) *Options {
+	o.APIKey = &key
+	return o
+}
+
+// WithAuthToken sets the bearer token used by the CLI for this query.
+func (o *Options) WithAuthToken(token string) *Options {
+	o.AuthToken = &token
+	return o
+}
+
+// WithBaseURL sets the API base URL used by the CLI for this query.
+func (o *Options) WithBaseURL(url string) *Options {
+	o.BaseURL = &url
+	return o
+}
+
 // AddMcpServer adds an MCP server configuration.
 func (o *Options) AddMcpServer(name string, config McpServerConfig) *Options {
 	if o.McpServers == nil {
-		o.McpServers = make(map[string]McpServerConfig)
+		o.McpServers = make(McpServers)
 	}
 	o.McpServers[name] = config
 	return o
 }
 
+// WithMcpServers adds MCP server configurations, such as those decoded
+// from a .mcp.json file, replacing servers with the same names.
+func (o *Options) WithMcpServers(servers McpServers) *Options {
+	for name, config := range servers {
+		o.AddMcpServer(name, config)
+	}
+	return o
+}
+
+// WithMcpConfigFile adds an MCP configuration file in the CLI's format,
+// such as the .mcp.json developers already maintain for a project. The
+// path is passed to the CLI, which loads the file's servers, expanding
+// environment variables as it does for its own configuration, along with
+// those added by AddMcpServer.
+func (o *Options) WithMcpConfigFile(path string) *Options {
+	o.McpConfigFiles = append(o.McpConfigFiles, path)
+	return o
+}
+
 // AddMcpTool adds an MCP tool to the enabled tools list.
 func (o *Options) AddMcpTool(tool string) *Options {
 	o.McpTools = append(o.McpTools, tool)
 	return o
 }
+
+// WithAgents defines subagents by name, replacing any defined earlier.
+func (o *Options) WithAgents(agents map[string]AgentDefinition) *Options {
+	o.Agents = agents
+	return o
+}
+
+// AddAgent defines a subagent.
+func (o *Options) AddAgent(name string, agent AgentDefinition) *Options {
+	if o.Agents == nil {
+		o.Agents = make(map[string]AgentDefinition)
+	}
+	o.Agents[name] = agent
+	return o
+}
+
+// WithPlugins loads plugins into the CLI.
+func (o *Options) WithPlugins(plugins ...PluginConfig) *Options {
+	o.Plugins = append(o.Plugins, plugins...)
+	return o
+}
+
+// WithHooks sets the hook callbacks for the options, replacing any existing hooks.
+func (o *Options) WithHooks(hooks map[HookEvent][]HookMatcher) *Options {
+	o.Hooks = hooks
+	return o
+}
+
+// AddHook registers callbacks for a hook event, limited to tools matching matcher.
+// An empty matcher applies the callbacks to all tools.
+func (o *Options) AddHook(event HookEvent, matcher string, hooks ...HookCallback) *Options {
+	if o.Hooks == nil {
+		o.Hooks = make(map[HookEvent][]HookMatcher)
+	}
+	o.Hooks[event] = append(o.Hooks[event], HookMatcher{Matcher: matcher, Hooks: hooks})
+	return o
+}
+
+// WithTerminationGracePeriod sets how long the CLI may take to shut down
+// after an interrupt before it is killed.
+func (o *Options) WithTerminationGracePeriod(grace time.Duration) *Options {
+	o.TerminationGracePeriod = &grace
+	return o
+}
+
+// WithMaxBufferSize sets the maximum size in bytes of a single message
+// read from the CLI, for queries that produce very large tool results.
+func (o *Options) WithMaxBufferSize(size int) *Options {
+	o.MaxBufferSize = &size
+	return o
+}
+
+// WithRetryPolicy sets the policy for retrying transient CLI failures.
+func (o *Options) WithRetryPolicy(policy RetryPolicy) *Options {
+	o.RetryPolicy = &policy
+	return o
+}
+
+// WithReconnectPolicy sets the policy for restarting a CLI process that
+// fails mid-conversation.
+func (o *Options) WithReconnectPolicy(policy ReconnectPolicy) *Options {
+	o.ReconnectPolicy = &policy
+	return o
+}
+
+// WithParseMode sets how messages and content blocks of unknown types are handled.
+func (o *Options) WithParseMode(mode ParseMode) *Options {
+	o.ParseMode = &mode
+	return o
+}
+
+// WithRawMessageHandler sets a callback that receives each raw JSON line
+// from the CLI, for logging transcripts or recording sessions.
+func (o *Options) WithRawMessageHandler(handler func(line json.RawMessage)) *Options {
+	o.RawMessageHandler = handler
+	return o
+}
+
+// WithCompletionCallback sets a callback called once when a query ends,
+// for reporting outcomes to job orchestration systems.
+func (o *Options) WithCompletionCallback(callback func(summary *ResultSummary)) *Options {
+	o.CompletionCallback = callback
+	return o
+}
+
+// WithMaxCostUSD limits the cost of the query or session in US dollars.
+func (o *Options) WithMaxCostUSD(limit float64) *Options {
+	o.MaxCostUSD = &limit
+	return o
+}
+
+// WithQueryTimeout limits how long the query may run.
+func (o *Options) WithQueryTimeout(timeout time.Duration) *Options {
+	o.QueryTimeout = &timeout
+	return o
+}
+
+// WithIdleTimeout stops the query if the CLI produces no output for the
+// given duration.
+func (o *Options) WithIdleTimeout(timeout time.Duration) *Options {
+	o.IdleTimeout = &timeout
+	return o
+}
+
+// WithStartupTimeout fails the query if the CLI produces no output within
+// the given duration of starting.
+func (o *Options) WithStartupTimeout(timeout time.Duration) *Options {
+	o.StartupTimeout = &timeout
+	return o
+}
+
+// WithHeartbeatInterval delivers a heartbeat message at the given
+// interval while the query runs.
+func (o *Options) WithHeartbeatInterval(interval time.Duration) *Options {
+	o.HeartbeatInterval = &interval
+	return o
+}
+
+// WithCLIVersionCheck checks the installed CLI's version against the
+// options before each query.
+func (o *Options) WithCLIVersionCheck(check VersionCheck) *Options {
+	o.CLIVersionCheck = &check
+	return o
+}
+
+// WithProbeCLIFlags sets whether to adapt options to the flags supported
+// by the installed CLI.
+func (o *Options) WithProbeCLIFlags(probe bool) *Options {
+	o.ProbeCLIFlags = probe
+	return o
+}
+
+// WithCLISearchPaths sets binaries or directories to search for the CLI
+// before the default locations.
+func (o *Options) WithCLISearchPaths(paths ...string) *Options {
+	o.CLISearchPaths = paths
+	return o
+}
+
+// WithResourceLimits constrains the memory, CPU priority, and run time of
+// the CLI subprocess.
+func (o *Options) WithResourceLimits(limits ResourceLimits) *Options {
+	o.ResourceLimits = &limits
+	return o
+}
+
+// WithRateLimiter waits on limiter before starting the CLI. A
+// *rate.Limiter from golang.org/x/time/rate can be passed directly.
+func (o *Options) WithRateLimiter(limiter RateLimiter) *Options {
+	o.RateLimiter = limiter
+	return o
+}
+
+// WithResponseCache answers repeated identical queries from cache instead
+// of running the CLI again.
+func (o *Options) WithResponseCache(cache ResponseCache) *Options {
+	o.ResponseCache = cache
+	return o
+}
+
+// WithRateLimitTurns sets whether sessions also wait on the rate limiter
+// before each sent message.
+func (o *Options) WithRateLimitTurns(limit bool) *Options {
+	o.RateLimitTurns = limit
+	return o
+}
+
+// WithExtraArgs adds CLI flags the SDK has no option for, such as flags
+// from a newer CLI. A nil value passes the flag without a value.
+//
+// Example:
+//
+//	beta := "interleaved-thinking"
+//	options.WithExtraArgs(map[string]*string{"betas": &beta, "strict-mcp-config": nil})
+func (o *Options) WithExtraArgs(args map[string]*string) *Options {
+	if o.ExtraArgs == nil {
+		o.ExtraArgs = make(map[string]*string, len(args))
+	}
+	for flag, value := range args {
+		o.ExtraArgs[flag] = value
+	}
+	return o
+}
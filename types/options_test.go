@@ -1,8 +1,10 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -811,4 +813,89 @@ func TestOptionsChaining(t *testing.T) {
 	if len(opts.McpTools) != 1 {
 		t.Error("McpTools not set correctly in chain")
 	}
-}
\ No newline at end of file
+}
+func TestOptionsHooks(t *testing.T) {
+	callback := func(ctx context.Context, input HookInput, toolUseID string) (HookOutput, error) {
+		return HookOutput{}, nil
+	}
+
+	opts := NewOptions().
+		AddHook(HookEventPreToolUse, "Bash", callback).
+		AddHook(HookEventPreToolUse, "Write|Edit", callback, callback)
+
+	if len(opts.Hooks[HookEventPreToolUse]) != 2 {
+		t.Fatalf("Expected 2 PreToolUse matchers, got %d", len(opts.Hooks[HookEventPreToolUse]))
+	}
+	if got := opts.Hooks[HookEventPreToolUse][1]; got.Matcher != "Write|Edit" || len(got.Hooks) != 2 {
+		t.Errorf("Unexpected matcher: %+v", got)
+	}
+
+	opts.WithHooks(map[HookEvent][]HookMatcher{HookEventStop: {{Hooks: []HookCallback{callback}}}})
+	if len(opts.Hooks) != 1 || len(opts.Hooks[HookEventStop]) != 1 {
+		t.Errorf("Expected WithHooks to replace hooks, got %v", opts.Hooks)
+	}
+}
+
+func TestOptionsCredentials(t *testing.T) {
+	opts := NewOptions().
+		WithAPIKey("sk-test").
+		WithAuthToken("token").
+		WithBaseURL("https://gateway.example.com")
+
+	if opts.APIKey == nil || *opts.APIKey != "sk-test" {
+		t.Error("APIKey not set correctly")
+	}
+	if opts.AuthToken == nil || *opts.AuthToken != "token" {
+		t.Error("AuthToken not set correctly")
+	}
+	if opts.BaseURL == nil || *opts.BaseURL != "https://gateway.example.com" {
+		t.Error("BaseURL not set correctly")
+	}
+
+	// Secrets must never be serialized
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatalf("Failed to marshal options: %v", err)
+	}
+	if strings.Contains(string(data), "sk-test") || strings.Contains(string(data), "token\"") {
+		t.Errorf("Credentials leaked into JSON: %s", data)
+	}
+	if !strings.Contains(string(data), "gateway.example.com") {
+		t.Errorf("Expected baseURL in JSON: %s", data)
+	}
+}
+
+func TestPreToolUseOutput(t *testing.T) {
+	out := PreToolUseOutput("allow", "", map[string]any{"command": "ls -la"})
+
+	specific := out.HookSpecificOutput
+	if specific["hookEventName"] != "PreToolUse" || specific["permissionDecision"] != "allow" {
+		t.Errorf("Unexpected hook specific output: %v", specific)
+	}
+	if _, ok := specific["permissionDecisionReason"]; ok {
+		t.Error("Expected empty reason to be omitted")
+	}
+	if specific["updatedInput"].(map[string]any)["command"] != "ls -la" {
+		t.Errorf("Expected updated input, got %v", specific["updatedInput"])
+	}
+}
+
+func TestOptionsWithModelNormalizes(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected string
+	}{
+		{" Sonnet ", "sonnet"},
+		{"OPUS[1M]", "opus[1m]"},
+		{"Claude-Sonnet-4-5-20250929", "claude-sonnet-4-5-20250929"},
+		{"claude-3-sonnet-20240229", "claude-3-sonnet-20240229"},
+		{"us.anthropic.claude-sonnet-4-5-20250929-v1:0", "us.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+	}
+
+	for _, tt := range tests {
+		opts := NewOptions().WithModel(tt.model).WithFallbackModel(tt.model)
+		if *opts.Model != tt.expected || *opts.FallbackModel != tt.expected {
+			t.Errorf("WithModel(%q) = %q, fallback %q, want %q", tt.model, *opts.Model, *opts.FallbackModel, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/jrossi/claude-code-sdk-golang/models"
+)
+
+// ModelPricing is the price of a model's tokens in US dollars per million
+// tokens.
+type ModelPricing struct {
+	InputPerMTok      float64
+	OutputPerMTok     float64
+	CacheWritePerMTok float64
+	CacheReadPerMTok  float64
+}
+
+// DefaultModelPricing maps model families to list prices, used to estimate
+// the cost of a query before the CLI reports it for models missing from
+// the models package. Such a model is matched by the first family name it
+// contains. Prices change over time; replace entries, or register models
+// with models.Register, to match your account.
+var DefaultModelPricing = map[string]ModelPricing{
+	"opus":   {InputPerMTok: 15, OutputPerMTok: 75, CacheWritePerMTok: 18.75, CacheReadPerMTok: 1.5},
+	"sonnet": {InputPerMTok: 3, OutputPerMTok: 15, CacheWritePerMTok: 3.75, CacheReadPerMTok: 0.3},
+	"haiku":  {InputPerMTok: 0.8, OutputPerMTok: 4, CacheWritePerMTok: 1, CacheReadPerMTok: 0.08},
+}
+
+// EstimateCostUSD estimates the cost of the token usage reported in an
+// assistant message for the given model. Unknown models are priced as the
+// most expensive family so that budget checks err on the side of stopping.
+func EstimateCostUSD(model string, usage map[string]any) float64 {
+	pricing, ok := pricingFor(model)
+	if !ok {
+		for _, p := range DefaultModelPricing {
+			if p.OutputPerMTok > pricing.OutputPerMTok {
+				pricing = p
+			}
+		}
+	}
+
+	tokens := func(key string) float64 {
+		val, _ := usage[key].(float64)
+		return val
+	}
+
+	return (tokens("input_tokens")*pricing.InputPerMTok +
+		tokens("output_tokens")*pricing.OutputPerMTok +
+		tokens("cache_creation_input_tokens")*pricing.CacheWritePerMTok +
+		tokens("cache_read_input_tokens")*pricing.CacheReadPerMTok) / 1e6
+}
+
+// pricingFor finds the pricing for a model in the models package, or by
+// family name.
+func pricingFor(model string) (ModelPricing, bool) {
+	if m, ok := models.Lookup(model); ok {
+		return ModelPricing(m.Pricing), true
+	}
+
+	model = strings.ToLower(model)
+	for family, pricing := range DefaultModelPricing {
+		if strings.Contains(model, family) {
+			return pricing, true
+		}
+	}
+	return ModelPricing{}, false
+}
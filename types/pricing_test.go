@@ -0,0 +1,32 @@
+package types
+
+import "testing"
+
+func TestEstimateCostUSD(t *testing.T) {
+	usage := map[string]any{
+		"input_tokens":                float64(1000000),
+		"output_tokens":               float64(1000000),
+		"cache_creation_input_tokens": float64(1000000),
+		"cache_read_input_tokens":     float64(1000000),
+	}
+
+	tests := []struct {
+		model    string
+		expected float64
+	}{
+		{"claude-sonnet-4-20250514", 3 + 15 + 3.75 + 0.3},
+		{"claude-3-5-haiku-latest", 0.8 + 4 + 1 + 0.08},
+		{"claude-opus-4-1", 15 + 75 + 18.75 + 1.5},
+		{"claude-opus-4-5", 5 + 25 + 6.25 + 0.5},
+		{"haiku", 1 + 5 + 1.25 + 0.1},
+		{"claude-3-haiku-custom", 0.8 + 4 + 1 + 0.08},
+		{"some-future-model", 15 + 75 + 18.75 + 1.5},
+	}
+
+	for _, tt := range tests {
+		got := EstimateCostUSD(tt.model, usage)
+		if got < tt.expected-1e-9 || got > tt.expected+1e-9 {
+			t.Errorf("%s: expected %v, got %v", tt.model, tt.expected, got)
+		}
+	}
+}
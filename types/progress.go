@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// Progress statuses reported in ProgressEvent.Status.
+const (
+	// ProgressStatusRunning means the tool is still running. The CLI
+	// reports progress only for running tools; the tool's ToolResultBlock
+	// marks its end.
+	ProgressStatusRunning = "running"
+)
+
+// ProgressEvent reports that a tool call is still running, as sent by the
+// CLI for long tool executions. UIs can show a spinner per tool call from
+// its ToolUseBlock until its ToolResultBlock, updating the elapsed time
+// with each ProgressEvent for the same ToolUseID.
+type ProgressEvent struct {
+	// ToolUseID identifies the running tool call.
+	ToolUseID string `json:"tool_use_id"`
+
+	// ToolName is the name of the running tool.
+	ToolName string `json:"tool_name,omitempty"`
+
+	// ParentToolUseID identifies the Task tool call running the tool in a
+	// subagent, or is empty for tools called by the main agent.
+	ParentToolUseID string `json:"parent_tool_use_id,omitempty"`
+
+	// Elapsed is how long the tool has been running.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Status is the tool's status, ProgressStatusRunning unless the CLI
+	// reports another.
+	Status string `json:"status"`
+}
+
+// Type returns the message type identifier.
+func (pe *ProgressEvent) Type() string {
+	return "tool_progress"
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prompt is a user prompt made of several parts, such as text, images, and
+// file references. It is sent to the CLI as content blocks in stream-json
+// input mode. Errors from building the prompt, such as an unreadable image
+// file, are reported when it is sent.
+//
+// Example:
+//
+//	prompt := claudecode.NewPrompt("What changed in this screenshot?").
+//		ImageFile("after.png").
+//		File("docs/design.md")
+type Prompt struct {
+	blocks []map[string]any
+	err    error
+}
+
+// supportedImageTypes are the image media types the API accepts.
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// NewPrompt creates a prompt starting with the given text parts.
+func NewPrompt(text ...string) *Prompt {
+	p := &Prompt{}
+	for _, t := range text {
+		p.Text(t)
+	}
+	return p
+}
+
+// Text appends a text part.
+func (p *Prompt) Text(text string) *Prompt {
+	p.blocks = append(p.blocks, map[string]any{"type": "text", "text": text})
+	return p
+}
+
+// File appends a reference to a file, which the CLI reads into the
+// conversation as it does for @-mentions.
+func (p *Prompt) File(path string) *Prompt {
+	return p.Text("@" + path)
+}
+
+// Image appends an image with the given media type, such as "image/png".
+func (p *Prompt) Image(mediaType string, data []byte) *Prompt {
+	if !supportedImageTypes[mediaType] {
+		p.setErr(fmt.Errorf("unsupported image type %q", mediaType))
+		return p
+	}
+
+	p.blocks = append(p.blocks, map[string]any{
+		"type": "image",
+		"source": map[string]any{
+			"type":       "base64",
+			"media_type": mediaType,
+			"data":       base64.StdEncoding.EncodeToString(data),
+		},
+	})
+	return p
+}
+
+// ImageFile appends an image read from path, with its media type taken
+// from the file extension.
+func (p *Prompt) ImageFile(path string) *Prompt {
+	mediaType, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(path)), ";")
+	if !supportedImageTypes[mediaType] {
+		p.setErr(fmt.Errorf("image %s: unsupported file type", path))
+		return p
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		p.setErr(fmt.Errorf("image %s: %w", path, err))
+		return p
+	}
+	return p.Image(mediaType, data)
+}
+
+// ImageURL appends an image fetched from url.
+func (p *Prompt) ImageURL(url string) *Prompt {
+	p.blocks = append(p.blocks, map[string]any{
+		"type":   "image",
+		"source": map[string]any{"type": "url", "url": url},
+	})
+	return p
+}
+
+// CacheControl marks the prompt up to the last part added as a prompt
+// caching breakpoint, so that a long shared prefix, such as a document
+// sent with many questions, is cached between queries.
+func (p *Prompt) CacheControl() *Prompt {
+	if len(p.blocks) == 0 {
+		p.setErr(fmt.Errorf("cache control requires a preceding prompt part"))
+		return p
+	}
+	p.blocks[len(p.blocks)-1]["cache_control"] = map[string]any{"type": "ephemeral"}
+	return p
+}
+
+// Content returns the prompt's content blocks in the CLI's stream-json
+// input format, or the first error from building the prompt.
+func (p *Prompt) Content() ([]map[string]any, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.blocks) == 0 {
+		return nil, fmt.Errorf("prompt is empty")
+	}
+	return p.blocks, nil
+}
+
+// String returns the text parts of the prompt, one per line.
+func (p *Prompt) String() string {
+	var parts []string
+	for _, block := range p.blocks {
+		if text, ok := block["text"].(string); ok {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// setErr records the first error from building the prompt.
+func (p *Prompt) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptContent(t *testing.T) {
+	image := filepath.Join(t.TempDir(), "diagram.png")
+	if err := os.WriteFile(image, []byte("png bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := NewPrompt("Compare these").
+		File("docs/design.md").
+		CacheControl().
+		ImageFile(image).
+		ImageURL("https://example.com/b.png")
+
+	blocks, err := prompt.Content()
+	if err != nil {
+		t.Fatalf("Content failed: %v", err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("Expected 4 blocks, got %d", len(blocks))
+	}
+
+	if blocks[1]["text"] != "@docs/design.md" {
+		t.Errorf("Expected file reference, got %v", blocks[1])
+	}
+	if blocks[1]["cache_control"] == nil || blocks[0]["cache_control"] != nil {
+		t.Errorf("Expected cache control on the file reference only, got %v", blocks)
+	}
+
+	source := blocks[2]["source"].(map[string]any)
+	if source["media_type"] != "image/png" || source["data"] != base64.StdEncoding.EncodeToString([]byte("png bytes")) {
+		t.Errorf("Unexpected image source: %v", source)
+	}
+	if source := blocks[3]["source"].(map[string]any); source["url"] != "https://example.com/b.png" {
+		t.Errorf("Unexpected URL source: %v", source)
+	}
+
+	if got := prompt.String(); got != "Compare these\n@docs/design.md" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestPromptErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt *Prompt
+		want   string
+	}{
+		{"empty", NewPrompt(), "prompt is empty"},
+		{"image type", NewPrompt("x").Image("image/tiff", nil), "unsupported image type"},
+		{"image file type", NewPrompt("x").ImageFile("notes.txt"), "unsupported file type"},
+		{"missing image", NewPrompt("x").ImageFile(filepath.Join(t.TempDir(), "missing.png")), "missing.png"},
+		{"cache control first", NewPrompt().CacheControl().Text("x"), "cache control"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.prompt.Content(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
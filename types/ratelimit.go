@@ -0,0 +1,12 @@
+package types
+
+import "context"
+
+// RateLimiter paces the start of queries, for example to stay within an
+// organization's API rate limits across many callers. *rate.Limiter from
+// golang.org/x/time/rate satisfies it.
+type RateLimiter interface {
+	// Wait blocks until the caller may proceed, or returns an error if ctx
+	// ends first or the wait can never succeed.
+	Wait(ctx context.Context) error
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"time"
+)
+
+// DefaultReconnectPrompt is sent to a resumed one-shot query when
+// ReconnectPolicy.Prompt is empty.
+const DefaultReconnectPrompt = "Continue from where you left off."
+
+// SystemSubtypeReconnected is the subtype of the SystemMessage the SDK
+// delivers when it restarts a failed CLI process under a ReconnectPolicy.
+// Data["session_id"] is the resumed session, Data["reconnect"] the number
+// of the restart, starting at 1, and Data["error"] the failure that caused
+// it.
+const SystemSubtypeReconnected = "sdk_reconnected"
+
+// ReconnectPolicy controls how the client restarts a CLI process that
+// fails after a query or session has started. Where RetryPolicy only
+// covers failures before any output, a reconnect resumes the conversation
+// with --resume and the new process's output continues on the same
+// stream, after a SystemMessage with subtype SystemSubtypeReconnected.
+//
+// A resumed one-shot query is sent Prompt to finish its task; a resumed
+// session waits for the next prompt, so a turn in progress when the CLI
+// failed is not completed. The session ID is taken from the CLI's init
+// message, so a process that fails before sending it is not reconnected.
+// Queries and sessions with hooks are never reconnected.
+type ReconnectPolicy struct {
+	// MaxReconnects is the number of restarts allowed. Values below 1
+	// disable reconnection.
+	MaxReconnects int
+
+	// Backoff returns the delay before the given restart, starting at 1.
+	// If nil, ExponentialBackoff(time.Second, 30*time.Second) is used.
+	Backoff func(reconnect int) time.Duration
+
+	// RetryableClassifier reports whether a failed process should be
+	// restarted. The error passed to it joins every error the process
+	// reported. If nil, IsRetryable is used.
+	RetryableClassifier func(err error) bool
+
+	// Prompt is sent to a resumed one-shot query. If empty,
+	// DefaultReconnectPrompt is used.
+	Prompt string
+}
+
+// ShouldReconnect reports whether the given restart should follow a
+// process that failed with err.
+func (p *ReconnectPolicy) ShouldReconnect(reconnect int, err error) bool {
+	if p == nil || err == nil || reconnect > p.MaxReconnects {
+		return false
+	}
+	if p.RetryableClassifier == nil {
+		return IsRetryable(err)
+	}
+	return p.RetryableClassifier(err)
+}
+
+// Delay returns the backoff before the given restart.
+func (p *ReconnectPolicy) Delay(reconnect int) time.Duration {
+	if p.Backoff == nil {
+		return ExponentialBackoff(time.Second, 30*time.Second)(reconnect)
+	}
+	return p.Backoff(reconnect)
+}
+
+// ResumePrompt returns the prompt sent to a resumed one-shot query.
+func (p *ReconnectPolicy) ResumePrompt() string {
+	if p.Prompt == "" {
+		return DefaultReconnectPrompt
+	}
+	return p.Prompt
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReconnectPolicyShouldReconnect(t *testing.T) {
+	err := NewProcessError("CLI process failed", 1, "")
+
+	var nilPolicy *ReconnectPolicy
+	if nilPolicy.ShouldReconnect(1, err) {
+		t.Error("Expected nil policy not to reconnect")
+	}
+
+	policy := &ReconnectPolicy{MaxReconnects: 1}
+	if !policy.ShouldReconnect(1, err) {
+		t.Error("Expected first reconnect to be allowed")
+	}
+	if policy.ShouldReconnect(2, err) {
+		t.Error("Expected no reconnect after MaxReconnects")
+	}
+	if policy.ShouldReconnect(1, errors.New("unclassified")) {
+		t.Error("Expected default classifier not to reconnect on unknown errors")
+	}
+
+	policy.RetryableClassifier = func(error) bool { return false }
+	if policy.ShouldReconnect(1, err) {
+		t.Error("Expected classifier to prevent reconnect")
+	}
+}
+
+func TestReconnectPolicyResumePrompt(t *testing.T) {
+	policy := &ReconnectPolicy{}
+	if got := policy.ResumePrompt(); got != DefaultReconnectPrompt {
+		t.Errorf("Expected default prompt, got %q", got)
+	}
+
+	policy.Prompt = "Finish the task."
+	if got := policy.ResumePrompt(); got != "Finish the task." {
+		t.Errorf("Expected custom prompt, got %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"time"
+)
+
+// RetryPolicy controls how the client retries queries whose CLI process
+// fails before producing any output, such as a crash on startup, a reset
+// connection, or a rate limit reported on stderr.
+//
+// Once the first message has been received, a query is never retried, so
+// callers never see duplicated output.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry attempt, starting
+	// at 1 for the first retry. If nil, ExponentialBackoff(time.Second,
+	// 30*time.Second) is used.
+	Backoff func(attempt int) time.Duration
+
+	// RetryableClassifier reports whether a failed attempt should be
+	// retried. The error passed to it joins every error reported by the
+	// attempt. If nil, IsRetryable is used.
+	RetryableClassifier func(err error) bool
+
+	// OnRetry, if set, is called before each retry.
+	OnRetry func(event RetryEvent)
+}
+
+// RetryEvent describes a retry about to be made.
+type RetryEvent struct {
+	// Attempt is the number of the attempt about to start, starting at 2.
+	Attempt int
+
+	// Err is the error that caused the previous attempt to fail.
+	Err error
+
+	// Delay is how long the client waits before starting the attempt.
+	Delay time.Duration
+}
+
+// ExponentialBackoff returns a backoff function that doubles the delay on
+// each retry, starting at base and capped at max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// ShouldRetry reports whether another attempt should follow the given
+// failed attempt.
+func (p *RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.RetryableClassifier == nil {
+		return IsRetryable(err)
+	}
+	return p.RetryableClassifier(err)
+}
+
+// Delay returns the backoff before the given retry attempt.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return ExponentialBackoff(time.Second, 30*time.Second)(attempt)
+	}
+	return p.Backoff(attempt)
+}
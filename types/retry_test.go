@@ -0,0 +1,54 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.expected {
+			t.Errorf("Attempt %d: expected %v, got %v", tt.attempt, tt.expected, got)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	err := NewProcessError("CLI process failed", 1, "")
+
+	var nilPolicy *RetryPolicy
+	if nilPolicy.ShouldRetry(1, err) {
+		t.Error("Expected nil policy not to retry")
+	}
+
+	policy := &RetryPolicy{MaxAttempts: 2}
+	if !policy.ShouldRetry(1, err) {
+		t.Error("Expected retry on first failed attempt")
+	}
+	if policy.ShouldRetry(2, err) {
+		t.Error("Expected no retry after MaxAttempts")
+	}
+
+	if policy.ShouldRetry(1, errors.New("unclassified")) {
+		t.Error("Expected default classifier not to retry unknown errors")
+	}
+
+	policy.RetryableClassifier = func(error) bool { return false }
+	if policy.ShouldRetry(1, err) {
+		t.Error("Expected classifier to prevent retry")
+	}
+}
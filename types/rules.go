@@ -0,0 +1,159 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// toolNamePattern matches tool names such as "Bash", "WebFetch", and
+// "mcp__github__create_issue".
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// mcpToolPrefix starts the names of the tools of MCP servers.
+const mcpToolPrefix = "mcp__"
+
+// mcpNameInvalidChars matches the characters the CLI replaces with "_"
+// when it builds MCP tool names from server and tool names.
+var mcpNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// McpToolName returns the name Claude uses for a tool of an MCP server,
+// "mcp__server__tool", for use in AllowedTools, DisallowedTools, and
+// hook matchers. Characters the CLI does not allow in tool names are
+// replaced with "_", as the CLI does.
+//
+// Example:
+//
+//	options := claudecode.NewOptions().
+//		AddMcpServer("github", githubServer).
+//		AllowTool(claudecode.McpToolName("github", "create_issue"))
+func McpToolName(server, tool string) string {
+	return McpServerTools(server) + "__" + mcpNameInvalidChars.ReplaceAllString(tool, "_")
+}
+
+// McpServerTools returns the permission rule "mcp__server", which matches
+// every tool of an MCP server.
+func McpServerTools(server string) string {
+	return mcpToolPrefix + mcpNameInvalidChars.ReplaceAllString(server, "_")
+}
+
+// ParseMcpToolName splits a name built by McpToolName into its server and
+// tool. ok is false if name is not the name of an MCP tool.
+func ParseMcpToolName(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, mcpToolPrefix)
+	if !found {
+		return "", "", false
+	}
+	server, tool, found = strings.Cut(rest, "__")
+	if !found || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
+
+// PermissionRule is an entry of AllowedTools or DisallowedTools: a tool,
+// optionally limited to the uses its specifier matches. Its String form is
+// the CLI's rule syntax, such as "Bash(npm run test:*)" or "Read(./src/**)".
+type PermissionRule struct {
+	// Tool is the tool's name.
+	Tool string
+
+	// Specifier limits the rule to some uses of the tool: a command prefix
+	// for Bash, a gitignore-style path pattern for Read, Edit, and Write,
+	// or "domain:host" for WebFetch. If empty, the rule covers every use.
+	Specifier string
+}
+
+// RuleOption limits a PermissionRule.
+type RuleOption func(*PermissionRule)
+
+// WithArgPattern limits a Bash rule to commands matching pattern. A
+// trailing ":*" matches any command with the given prefix, as in
+// "npm run test:*"; otherwise the command must match exactly.
+func WithArgPattern(pattern string) RuleOption {
+	return func(r *PermissionRule) { r.Specifier = pattern }
+}
+
+// WithPathPattern limits a Read, Edit, or Write rule to files matching a
+// gitignore-style pattern. Patterns starting with "/" are relative to the
+// settings file, "./" or none to the working directory, "~/" to the home
+// directory, and "//" are absolute paths.
+func WithPathPattern(pattern string) RuleOption {
+	return func(r *PermissionRule) { r.Specifier = pattern }
+}
+
+// WithDomain limits a WebFetch rule to requests to a domain.
+func WithDomain(domain string) RuleOption {
+	return func(r *PermissionRule) { r.Specifier = "domain:" + domain }
+}
+
+// NewPermissionRule creates a rule for tool limited by opts.
+//
+// Example:
+//
+//	rule := claudecode.NewPermissionRule("Bash", claudecode.WithArgPattern("npm run test:*"))
+//	rule.String() // "Bash(npm run test:*)"
+func NewPermissionRule(tool string, opts ...RuleOption) PermissionRule {
+	rule := PermissionRule{Tool: tool}
+	for _, opt := range opts {
+		opt(&rule)
+	}
+	return rule
+}
+
+// ParsePermissionRule parses a rule in the CLI's syntax, such as "Edit" or
+// "Bash(git diff:*)", and validates it.
+func ParsePermissionRule(s string) (PermissionRule, error) {
+	rule := PermissionRule{Tool: s}
+	if open := strings.IndexByte(s, '('); open >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return PermissionRule{}, fmt.Errorf("permission rule %q is missing a closing parenthesis", s)
+		}
+		rule = PermissionRule{Tool: s[:open], Specifier: s[open+1 : len(s)-1]}
+		if rule.Specifier == "" {
+			return PermissionRule{}, fmt.Errorf("permission rule %q has an empty specifier", s)
+		}
+	}
+	return rule, rule.Validate()
+}
+
+// Validate checks that the tool name is well formed and that the specifier
+// can be passed to the CLI, which separates rules with commas.
+func (r PermissionRule) Validate() error {
+	if !toolNamePattern.MatchString(r.Tool) {
+		return fmt.Errorf("invalid tool name %q in permission rule", r.Tool)
+	}
+	if strings.ContainsAny(r.Specifier, ",\n") {
+		return fmt.Errorf("permission rule %s cannot contain commas or newlines", r)
+	}
+	return nil
+}
+
+// String returns the rule in the CLI's syntax.
+func (r PermissionRule) String() string {
+	if r.Specifier == "" {
+		return r.Tool
+	}
+	return r.Tool + "(" + r.Specifier + ")"
+}
+
+// AllowTool adds a rule to AllowedTools for uses of tool matching opts,
+// or for every use without opts.
+//
+// Example:
+//
+//	options := claudecode.NewOptions().
+//		AllowTool("Read", claudecode.WithPathPattern("./src/**")).
+//		AllowTool("Bash", claudecode.WithArgPattern("npm run test:*")).
+//		AllowTool("WebFetch", claudecode.WithDomain("pkg.go.dev"))
+func (o *Options) AllowTool(tool string, opts ...RuleOption) *Options {
+	o.AllowedTools = append(o.AllowedTools, NewPermissionRule(tool, opts...).String())
+	return o
+}
+
+// DenyTool adds a rule to DisallowedTools for uses of tool matching opts,
+// or for every use without opts.
+func (o *Options) DenyTool(tool string, opts ...RuleOption) *Options {
+	o.DisallowedTools = append(o.DisallowedTools, NewPermissionRule(tool, opts...).String())
+	return o
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPermissionRuleString(t *testing.T) {
+	tests := []struct {
+		rule PermissionRule
+		want string
+	}{
+		{NewPermissionRule("Edit"), "Edit"},
+		{NewPermissionRule("Bash", WithArgPattern("npm run test:*")), "Bash(npm run test:*)"},
+		{NewPermissionRule("Read", WithPathPattern("./src/**")), "Read(./src/**)"},
+		{NewPermissionRule("WebFetch", WithDomain("pkg.go.dev")), "WebFetch(domain:pkg.go.dev)"},
+		{NewPermissionRule("mcp__github__create_issue"), "mcp__github__create_issue"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		parsed, err := ParsePermissionRule(tt.want)
+		if err != nil || parsed != tt.rule {
+			t.Errorf("ParsePermissionRule(%q) = %+v, %v; want %+v", tt.want, parsed, err, tt.rule)
+		}
+	}
+}
+
+func TestParsePermissionRuleErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"", `invalid tool name "" in permission rule`},
+		{"Bash(", `permission rule "Bash(" is missing a closing parenthesis`},
+		{"Bash()", `permission rule "Bash()" has an empty specifier`},
+		{"(ls)", `invalid tool name "" in permission rule`},
+		{"Read Write", `invalid tool name "Read Write" in permission rule`},
+		{"Bash(echo a,b)", "permission rule Bash(echo a,b) cannot contain commas or newlines"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParsePermissionRule(tt.rule); err == nil || err.Error() != tt.want {
+			t.Errorf("ParsePermissionRule(%q) error = %v, want %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestAllowAndDenyTool(t *testing.T) {
+	options := NewOptions().
+		WithAllowedTools("Grep").
+		AllowTool("Read", WithPathPattern("./src/**")).
+		AllowTool("Bash", WithArgPattern("go test:*")).
+		DenyTool("Bash", WithArgPattern("rm:*")).
+		DenyTool("WebSearch")
+
+	if want := []string{"Grep", "Read(./src/**)", "Bash(go test:*)"}; !reflect.DeepEqual(options.AllowedTools, want) {
+		t.Errorf("AllowedTools = %q, want %q", options.AllowedTools, want)
+	}
+	if want := []string{"Bash(rm:*)", "WebSearch"}; !reflect.DeepEqual(options.DisallowedTools, want) {
+		t.Errorf("DisallowedTools = %q, want %q", options.DisallowedTools, want)
+	}
+}
+
+func TestMcpToolName(t *testing.T) {
+	tests := []struct {
+		server, tool string
+		want         string
+	}{
+		{"github", "create_issue", "mcp__github__create_issue"},
+		{"my-db", "run-query", "mcp__my-db__run-query"},
+		{"files.local", "read file", "mcp__files_local__read_file"},
+	}
+
+	for _, tt := range tests {
+		got := McpToolName(tt.server, tt.tool)
+		if got != tt.want {
+			t.Errorf("McpToolName(%q, %q) = %q, want %q", tt.server, tt.tool, got, tt.want)
+		}
+		if _, err := ParsePermissionRule(got); err != nil {
+			t.Errorf("Expected %q to be a valid rule, got %v", got, err)
+		}
+	}
+
+	if got := McpServerTools("github"); got != "mcp__github" {
+		t.Errorf("McpServerTools = %q", got)
+	}
+	options := NewOptions().AllowTool(McpServerTools("github")).DenyTool(McpToolName("github", "delete_repo"))
+	if !reflect.DeepEqual(options.AllowedTools, []string{"mcp__github"}) ||
+		!reflect.DeepEqual(options.DisallowedTools, []string{"mcp__github__delete_repo"}) {
+		t.Errorf("Unexpected rules: %v %v", options.AllowedTools, options.DisallowedTools)
+	}
+}
+
+func TestParseMcpToolName(t *testing.T) {
+	tests := []struct {
+		name         string
+		server, tool string
+		ok           bool
+	}{
+		{"mcp__github__create_issue", "github", "create_issue", true},
+		{"mcp__db__run__query", "db", "run__query", true},
+		{"mcp__github", "", "", false},
+		{"mcp____tool", "", "", false},
+		{"Bash", "", "", false},
+	}
+
+	for _, tt := range tests {
+		server, tool, ok := ParseMcpToolName(tt.name)
+		if server != tt.server || tool != tt.tool || ok != tt.ok {
+			t.Errorf("ParseMcpToolName(%q) = %q, %q, %v", tt.name, server, tool, ok)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jrossi/claude-code-sdk-golang/models"
+)
+
+// DefaultContextWindow is the context window of Claude models, in tokens,
+// unless their name selects a larger one.
+const DefaultContextWindow = 200_000
+
+// charsPerToken is how many ASCII letters and digits of a word are
+// counted as one token. Common English words and identifiers are single
+// tokens; longer ones are split.
+const charsPerToken = 6
+
+// EstimateTokens estimates how many tokens text takes up in a prompt to
+// model, without a tokenizer. It errs towards overestimating, so that
+// prompts it accepts fit within ContextWindow: words cost a token per six
+// letters or digits, each punctuation mark, symbol, and line break a
+// token, and each non-ASCII character a token. The estimate is the same
+// for all current Claude models; model is accepted so that callers need
+// not change should their tokenizers diverge.
+//
+// The estimate covers the prompt text only. The CLI adds its system
+// prompt and tool definitions, typically several thousand tokens.
+func EstimateTokens(text, model string) int {
+	tokens := 0
+	word := 0 // letters and digits in the current word
+	flush := func() {
+		tokens += (word + charsPerToken - 1) / charsPerToken
+		word = 0
+	}
+
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word++
+		case r == '\n':
+			flush()
+			tokens++
+		case unicode.IsSpace(r):
+			// Spaces are part of the following token
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// ContextWindow returns the context window of model, in tokens, as listed
+// by the models package, or DefaultContextWindow for unknown models.
+// Models selected with a "[1m]" suffix, such as "sonnet[1m]", have a
+// context window of a million tokens.
+func ContextWindow(model string) int {
+	if strings.HasSuffix(strings.ToLower(model), models.LongContextSuffix) {
+		return 1_000_000
+	}
+	if window, ok := models.ContextWindow(model); ok {
+		return window
+	}
+	return DefaultContextWindow
+}
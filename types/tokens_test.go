@@ -0,0 +1,56 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"words", "Hello, world!", 4},
+		{"long word", "internationalization", 4},
+		{"line breaks", "one\ntwo\n", 4},
+		{"code", "if x > 0 {\n\treturn x\n}", 10},
+		{"non-ASCII", "日本語", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateTokens(tt.text, "sonnet"); got != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateTokensProse(t *testing.T) {
+	// English prose averages four to five characters per token with
+	// Claude's tokenizer; the estimate should not fall below that
+	prose := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	if got, floor := EstimateTokens(prose, "sonnet"), len(prose)/5; got < floor {
+		t.Errorf("Expected at least %d tokens, got %d", floor, got)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected int
+	}{
+		{"claude-sonnet-4-5", DefaultContextWindow},
+		{"sonnet[1m]", 1_000_000},
+		{"claude-sonnet-4-5[1M]", 1_000_000},
+		{"", DefaultContextWindow},
+	}
+
+	for _, tt := range tests {
+		if got := ContextWindow(tt.model); got != tt.expected {
+			t.Errorf("ContextWindow(%q) = %d, want %d", tt.model, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolInput is implemented by the typed inputs of built-in tools, such as
+// BashInput, naming the tool whose input they decode.
+type ToolInput interface {
+	ToolName() string
+}
+
+// ReadInput is the input of the Read tool.
+type ReadInput struct {
+	FilePath string `json:"file_path"`
+
+	// Offset is the line to start reading from, if set.
+	Offset int `json:"offset,omitempty"`
+
+	// Limit is the number of lines to read, if set.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ToolName returns "Read".
+func (ReadInput) ToolName() string { return "Read" }
+
+// WriteInput is the input of the Write tool.
+type WriteInput struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// ToolName returns "Write".
+func (WriteInput) ToolName() string { return "Write" }
+
+// EditInput is the input of the Edit tool.
+type EditInput struct {
+	FilePath  string `json:"file_path"`
+	OldString string `json:"old_string"`
+	NewString string `json:"new_string"`
+
+	// ReplaceAll replaces every occurrence of OldString instead of one.
+	ReplaceAll bool `json:"replace_all,omitempty"`
+}
+
+// ToolName returns "Edit".
+func (EditInput) ToolName() string { return "Edit" }
+
+// BashInput is the input of the Bash tool.
+type BashInput struct {
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+
+	// Timeout is the command's timeout in milliseconds, if set.
+	Timeout int `json:"timeout,omitempty"`
+
+	// RunInBackground runs the command without waiting for it to finish.
+	RunInBackground bool `json:"run_in_background,omitempty"`
+}
+
+// ToolName returns "Bash".
+func (BashInput) ToolName() string { return "Bash" }
+
+// GrepInput is the input of the Grep tool.
+type GrepInput struct {
+	Pattern string `json:"pattern"`
+
+	// Path is the file or directory to search, if not the working directory.
+	Path string `json:"path,omitempty"`
+
+	// Glob filters the files searched, such as "*.go".
+	Glob string `json:"glob,omitempty"`
+
+	// FileType filters the files searched by type, such as "go".
+	FileType string `json:"type,omitempty"`
+
+	// OutputMode is "content", "files_with_matches", or "count".
+	OutputMode string `json:"output_mode,omitempty"`
+
+	CaseInsensitive bool `json:"-i,omitempty"`
+	LineNumbers     bool `json:"-n,omitempty"`
+	Multiline       bool `json:"multiline,omitempty"`
+
+	// ContextBefore, ContextAfter, and Context are the lines of context
+	// shown around each match.
+	ContextBefore int `json:"-B,omitempty"`
+	ContextAfter  int `json:"-A,omitempty"`
+	Context       int `json:"-C,omitempty"`
+
+	// HeadLimit limits the number of results, if set.
+	HeadLimit int `json:"head_limit,omitempty"`
+}
+
+// ToolName returns "Grep".
+func (GrepInput) ToolName() string { return "Grep" }
+
+// ExitPlanModeInput is the input of the ExitPlanMode tool, which Claude
+// calls in plan mode to present its plan and ask to start making changes.
+type ExitPlanModeInput struct {
+	// Plan is the proposed plan, in Markdown.
+	Plan string `json:"plan"`
+}
+
+// ToolName returns "ExitPlanMode".
+func (ExitPlanModeInput) ToolName() string { return "ExitPlanMode" }
+
+// DecodeToolInput decodes a tool use block's input into T, such as
+// BashInput for a Bash tool use. If T is a ToolInput for a different tool
+// than the block's, an error is returned.
+//
+// Example:
+//
+//	if block.Name == "Bash" {
+//		input, err := types.DecodeToolInput[types.BashInput](block)
+//		if err == nil {
+//			fmt.Println("Running:", input.Command)
+//		}
+//	}
+func DecodeToolInput[T any](block *ToolUseBlock) (T, error) {
+	var input T
+	if block == nil {
+		return input, fmt.Errorf("no tool use block")
+	}
+
+	if named, ok := any(input).(ToolInput); ok && named.ToolName() != block.Name {
+		return input, fmt.Errorf("cannot decode %s tool input as %s input", block.Name, named.ToolName())
+	}
+
+	data, err := json.Marshal(block.Input)
+	if err != nil {
+		return input, fmt.Errorf("failed to encode %s tool input: %w", block.Name, err)
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return input, fmt.Errorf("failed to decode %s tool input: %w", block.Name, err)
+	}
+	return input, nil
+}
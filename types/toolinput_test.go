@@ -0,0 +1,85 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeToolInput(t *testing.T) {
+	bash := &ToolUseBlock{
+		ID:   "toolu_1",
+		Name: "Bash",
+		Input: map[string]any{
+			"command":     "go test ./...",
+			"description": "Run tests",
+			"timeout":     float64(120000),
+		},
+	}
+
+	input, err := DecodeToolInput[BashInput](bash)
+	if err != nil {
+		t.Fatalf("DecodeToolInput failed: %v", err)
+	}
+	if input.Command != "go test ./..." || input.Description != "Run tests" || input.Timeout != 120000 {
+		t.Errorf("Unexpected input: %+v", input)
+	}
+
+	grep, err := DecodeToolInput[GrepInput](&ToolUseBlock{
+		Name:  "Grep",
+		Input: map[string]any{"pattern": "func main", "-i": true, "-C": float64(2), "type": "go"},
+	})
+	if err != nil {
+		t.Fatalf("DecodeToolInput failed: %v", err)
+	}
+	if grep.Pattern != "func main" || !grep.CaseInsensitive || grep.Context != 2 || grep.FileType != "go" {
+		t.Errorf("Unexpected input: %+v", grep)
+	}
+
+	// Types other than the built-in inputs decode any tool
+	custom, err := DecodeToolInput[map[string]string](&ToolUseBlock{
+		Name:  "mcp__github__get_issue",
+		Input: map[string]any{"repo": "sdk"},
+	})
+	if err != nil || custom["repo"] != "sdk" {
+		t.Errorf("Expected custom input to decode, got %v, %v", custom, err)
+	}
+}
+
+func TestAssistantMessagePlan(t *testing.T) {
+	msg := &AssistantMessage{Content: []ContentBlock{
+		&TextBlock{Text: "Here is my plan."},
+		&ToolUseBlock{ID: "toolu_1", Name: "ExitPlanMode", Input: map[string]any{"plan": "1. Add tests\n2. Fix bug"}},
+	}}
+	if plan, ok := msg.Plan(); !ok || plan != "1. Add tests\n2. Fix bug" {
+		t.Errorf("Plan() = %q, %v", plan, ok)
+	}
+
+	msg = &AssistantMessage{Content: []ContentBlock{
+		&ToolUseBlock{Name: "Read", Input: map[string]any{"file_path": "main.go"}},
+		&ToolUseBlock{Name: "ExitPlanMode", Input: map[string]any{"plan": 42}},
+	}}
+	if plan, ok := msg.Plan(); ok {
+		t.Errorf("Expected no plan, got %q", plan)
+	}
+}
+
+func TestDecodeToolInputErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		block *ToolUseBlock
+		want  string
+	}{
+		{"nil block", nil, "no tool use block"},
+		{"wrong tool", &ToolUseBlock{Name: "Write", Input: map[string]any{}}, "cannot decode Write tool input as Edit input"},
+		{"wrong type", &ToolUseBlock{Name: "Edit", Input: map[string]any{"file_path": 42}}, "failed to decode Edit tool input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeToolInput[EditInput](tt.block)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
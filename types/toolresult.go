@@ -0,0 +1,177 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ToolResultContent is one part of a tool result's content.
+// Implementations are TextContent, ImageContent, and JSONContent.
+type ToolResultContent interface {
+	Type() string
+}
+
+// TextContent is a text part of a tool result.
+type TextContent struct {
+	Text string `json:"text"`
+}
+
+// Type returns the content type identifier.
+func (tc *TextContent) Type() string {
+	return "text"
+}
+
+// MarshalJSON encodes the part in the CLI's content format.
+func (tc *TextContent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "text", "text": tc.Text})
+}
+
+// ImageContent is an image part of a tool result, such as a screenshot or
+// an image file read by a tool. Either Data or URL is set.
+type ImageContent struct {
+	// MediaType is the image's MIME type, such as "image/png".
+	MediaType string `json:"media_type,omitempty"`
+
+	// Data is the base64-encoded image.
+	Data string `json:"data,omitempty"`
+
+	// URL locates the image when it is not included inline.
+	URL string `json:"url,omitempty"`
+}
+
+// Type returns the content type identifier.
+func (ic *ImageContent) Type() string {
+	return "image"
+}
+
+// MarshalJSON encodes the part in the CLI's content format.
+func (ic *ImageContent) MarshalJSON() ([]byte, error) {
+	source := map[string]any{"type": "base64", "media_type": ic.MediaType, "data": ic.Data}
+	if ic.URL != "" {
+		source = map[string]any{"type": "url", "url": ic.URL}
+	}
+	return json.Marshal(map[string]any{"type": "image", "source": source})
+}
+
+// JSONContent is a part of a tool result that is neither text nor an
+// image, such as structured output from an MCP tool, kept as raw JSON.
+type JSONContent struct {
+	Raw json.RawMessage `json:"raw"`
+}
+
+// Type returns the content type identifier.
+func (jc *JSONContent) Type() string {
+	return "json"
+}
+
+// MarshalJSON encodes the part as it was received.
+func (jc *JSONContent) MarshalJSON() ([]byte, error) {
+	if len(jc.Raw) == 0 {
+		return []byte("null"), nil
+	}
+	return jc.Raw, nil
+}
+
+// Unmarshal decodes the part into v.
+func (jc *JSONContent) Unmarshal(v any) error {
+	return json.Unmarshal(jc.Raw, v)
+}
+
+// ParseToolResultContent converts the decoded "content" field of a
+// tool_result block into typed parts. A string becomes a single
+// TextContent, and each element of an array becomes a TextContent,
+// ImageContent, or JSONContent. It returns nil for absent content.
+func ParseToolResultContent(content any) []ToolResultContent {
+	switch c := content.(type) {
+	case nil:
+		return nil
+	case string:
+		return []ToolResultContent{&TextContent{Text: c}}
+	case []any:
+		parts := make([]ToolResultContent, 0, len(c))
+		for _, item := range c {
+			parts = append(parts, parseContentPart(item))
+		}
+		return parts
+	default:
+		return []ToolResultContent{jsonContent(c)}
+	}
+}
+
+// parseContentPart converts one element of a content array.
+func parseContentPart(item any) ToolResultContent {
+	part, ok := item.(map[string]any)
+	if !ok {
+		return jsonContent(item)
+	}
+
+	switch part["type"] {
+	case "text":
+		if text, ok := part["text"].(string); ok {
+			return &TextContent{Text: text}
+		}
+	case "image":
+		if source, ok := part["source"].(map[string]any); ok {
+			image := &ImageContent{}
+			image.MediaType, _ = source["media_type"].(string)
+			image.Data, _ = source["data"].(string)
+			image.URL, _ = source["url"].(string)
+			return image
+		}
+	}
+	return jsonContent(part)
+}
+
+// jsonContent re-encodes a decoded value as JSONContent.
+func jsonContent(value any) *JSONContent {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return &JSONContent{}
+	}
+	return &JSONContent{Raw: data}
+}
+
+// Text returns the concatenated text parts of the result.
+func (trb *ToolResultBlock) Text() string {
+	var parts []string
+	for _, part := range trb.Content {
+		if text, ok := part.(*TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Images returns the image parts of the result.
+func (trb *ToolResultBlock) Images() []*ImageContent {
+	var images []*ImageContent
+	for _, part := range trb.Content {
+		if image, ok := part.(*ImageContent); ok {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// Failed reports whether the tool reported an error.
+func (trb *ToolResultBlock) Failed() bool {
+	return trb.IsError != nil && *trb.IsError
+}
+
+// UnmarshalJSON decodes a tool result whose content is either a string or
+// an array of content parts.
+func (trb *ToolResultBlock) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ToolUseID string `json:"tool_use_id"`
+		Content   any    `json:"content"`
+		IsError   *bool  `json:"is_error"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	trb.ToolUseID = raw.ToolUseID
+	trb.Content = ParseToolResultContent(raw.Content)
+	trb.IsError = raw.IsError
+	return nil
+}
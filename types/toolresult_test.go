@@ -0,0 +1,86 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseToolResultContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content any
+		types   []string
+	}{
+		{"absent", nil, nil},
+		{"string", "file contents", []string{"text"}},
+		{
+			name: "parts",
+			content: []any{
+				map[string]any{"type": "text", "text": "Screenshot taken"},
+				map[string]any{"type": "image", "source": map[string]any{
+					"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgo=",
+				}},
+				map[string]any{"type": "resource", "uri": "file:///tmp/out.json"},
+			},
+			types: []string{"text", "image", "json"},
+		},
+		{"object", map[string]any{"status": "ok"}, []string{"json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := ParseToolResultContent(tt.content)
+			if len(parts) != len(tt.types) {
+				t.Fatalf("Expected %d parts, got %d", len(tt.types), len(parts))
+			}
+			for i, part := range parts {
+				if part.Type() != tt.types[i] {
+					t.Errorf("Part %d: expected type %q, got %q", i, tt.types[i], part.Type())
+				}
+			}
+		})
+	}
+}
+
+func TestToolResultBlockHelpers(t *testing.T) {
+	var block ToolResultBlock
+	data := `{"tool_use_id": "tool_1", "is_error": true, "content": [
+		{"type": "text", "text": "line one"},
+		{"type": "image", "source": {"type": "url", "url": "https://example.com/a.png"}},
+		{"type": "text", "text": "line two"},
+		{"type": "resource", "uri": "file:///tmp/out.json"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &block); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if got := block.Text(); got != "line one\nline two" {
+		t.Errorf("Text() = %q", got)
+	}
+	if images := block.Images(); len(images) != 1 || images[0].URL != "https://example.com/a.png" {
+		t.Errorf("Unexpected images: %v", images)
+	}
+	if !block.Failed() {
+		t.Error("Expected failed result")
+	}
+
+	var resource struct {
+		URI string `json:"uri"`
+	}
+	if err := block.Content[3].(*JSONContent).Unmarshal(&resource); err != nil || resource.URI != "file:///tmp/out.json" {
+		t.Errorf("Unexpected resource %+v: %v", resource, err)
+	}
+
+	// Structured content survives a round trip
+	encoded, err := json.Marshal(&block)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded ToolResultBlock
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded.Content) != 4 || decoded.Text() != block.Text() || decoded.Images()[0].URL != "https://example.com/a.png" {
+		t.Errorf("Round trip changed content: %s", encoded)
+	}
+}
@@ -0,0 +1,171 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jrossi/claude-code-sdk-golang/models"
+)
+
+// modelNamePattern matches model aliases such as "sonnet", full names such
+// as "claude-sonnet-4-5", and provider IDs such as
+// "us.anthropic.claude-sonnet-4-5-20250929-v1:0" or "sonnet[1m]".
+var modelNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/@\[\]-]*$`)
+
+// flagNamePattern matches CLI flag names without their leading dashes.
+var flagNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// Validate checks the options for mistakes the CLI would otherwise reject
+// with an unhelpful exit status: a malformed permission rule, a tool both
+// allowed and disallowed, a negative turn or token limit, conflicting ways
+// to pick the conversation, a malformed model name, an unknown model name
+// when StrictModels is set, a fallback model equal to the model, an
+// unreadable MCP configuration file, or a working directory that does not
+// exist. It returns a *UsageError listing every problem found, or nil.
+//
+// Queries and sessions validate their options before starting the CLI,
+// except those run with QueryWithTransport, whose transport decides where
+// Cwd is.
+func (o *Options) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, tools := range [][]string{o.AllowedTools, o.DisallowedTools} {
+		for _, tool := range tools {
+			if _, err := ParsePermissionRule(tool); err != nil {
+				add("%v", err)
+			}
+		}
+	}
+
+	disallowed := make(map[string]bool, len(o.DisallowedTools))
+	for _, tool := range o.DisallowedTools {
+		disallowed[tool] = true
+	}
+	for _, tool := range o.AllowedTools {
+		if disallowed[tool] {
+			add("tool %q is both allowed and disallowed", tool)
+		}
+	}
+
+	if o.MaxTurns != nil && *o.MaxTurns < 0 {
+		add("max turns must not be negative, got %d", *o.MaxTurns)
+	}
+	if o.MaxThinkingTokens < 0 {
+		add("max thinking tokens must not be negative, got %d", o.MaxThinkingTokens)
+	}
+
+	if o.Resume != nil && o.ContinueConversation {
+		add("resume and continue conversation cannot be used together")
+	}
+	if o.Resume != nil && *o.Resume == "" {
+		add("resume requires a session ID")
+	}
+	if o.ForkSession && o.Resume == nil && !o.ContinueConversation {
+		add("fork session requires resume or continue conversation")
+	}
+
+	if o.Model != nil {
+		switch {
+		case !modelNamePattern.MatchString(*o.Model):
+			add("invalid model name %q", *o.Model)
+		case o.StrictModels && !models.Known(*o.Model):
+			add("unknown model %q; register it with models.Register or unset strict models", *o.Model)
+		}
+	}
+	if o.FallbackModel != nil {
+		switch {
+		case !modelNamePattern.MatchString(*o.FallbackModel):
+			add("invalid fallback model name %q", *o.FallbackModel)
+		case o.StrictModels && !models.Known(*o.FallbackModel):
+			add("unknown fallback model %q; register it with models.Register or unset strict models", *o.FallbackModel)
+		case o.Model != nil && *o.FallbackModel == *o.Model:
+			add("fallback model must differ from the main model")
+		}
+	}
+
+	for flag := range o.ExtraArgs {
+		if !flagNamePattern.MatchString(strings.TrimPrefix(flag, "--")) {
+			add("invalid extra flag name %q", flag)
+		}
+	}
+
+	if o.OutputStyle != nil && strings.TrimSpace(*o.OutputStyle) == "" {
+		add("output style requires a name")
+	}
+
+	for _, path := range o.McpConfigFiles {
+		if err := checkMcpConfigFile(path, o.Cwd); err != nil {
+			add("MCP config file %q: %v", path, err)
+		}
+	}
+
+	if o.Cwd != nil {
+		info, err := os.Stat(*o.Cwd)
+		switch {
+		case err != nil:
+			add("working directory %q does not exist", *o.Cwd)
+		case !info.IsDir():
+			add("working directory %q is not a directory", *o.Cwd)
+		}
+	}
+
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return &UsageError{Message: problems[0], Problems: problems}
+	default:
+		return &UsageError{Message: "invalid options", Problems: problems}
+	}
+}
+
+// Warnings returns problems with the options that do not stop a query:
+// models missing from the models package, unless StrictModels makes them
+// errors. Queries and sessions deliver each warning in a SystemMessage with
+// subtype SystemSubtypeWarning at the start of the stream.
+func (o *Options) Warnings() []string {
+	if o.StrictModels {
+		return nil
+	}
+
+	var warnings []string
+	if o.Model != nil && modelNamePattern.MatchString(*o.Model) && !models.Known(*o.Model) {
+		warnings = append(warnings, fmt.Sprintf("unknown model %q, which may be newer than this SDK", *o.Model))
+	}
+	if o.FallbackModel != nil && modelNamePattern.MatchString(*o.FallbackModel) && !models.Known(*o.FallbackModel) {
+		warnings = append(warnings, fmt.Sprintf("unknown fallback model %q, which may be newer than this SDK", *o.FallbackModel))
+	}
+	return warnings
+}
+
+// checkMcpConfigFile reads an MCP configuration file, relative to cwd if
+// set, and checks that its servers can be decoded.
+func checkMcpConfigFile(path string, cwd *string) error {
+	if path == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if cwd != nil && !filepath.IsAbs(path) {
+		path = filepath.Join(*cwd, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("file does not exist")
+		}
+		return err
+	}
+	var config struct {
+		McpServers McpServers `json:"mcpServers"`
+	}
+	return json.Unmarshal(data, &config)
+}
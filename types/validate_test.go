@@ -0,0 +1,181 @@
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mcpConfig := `{"mcpServers": {"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"}, "files": {"command": "npx"}}}`
+	if err := os.WriteFile(filepath.Join(dir, ".mcp.json"), []byte(mcpConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"mcpServers": {"x": {"type": "ws"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		options  *Options
+		problems []string
+	}{
+		{"defaults", NewOptions(), nil},
+		{
+			"valid",
+			NewOptions().WithModel("us.anthropic.claude-sonnet-4-5-20250929-v1:0").WithCwd(dir).
+				WithAllowedTools("Read").WithDisallowedTools("Bash").WithMaxTurns(0).
+				WithResume("abc").WithForkSession(true),
+			nil,
+		},
+		{"model alias", NewOptions().WithModel("sonnet[1m]"), nil},
+		{
+			"tool conflict",
+			NewOptions().WithAllowedTools("Read", "Bash").WithDisallowedTools("Bash"),
+			[]string{`tool "Bash" is both allowed and disallowed`},
+		},
+		{"negative turns", NewOptions().WithMaxTurns(-1), []string{"max turns must not be negative, got -1"}},
+		{
+			"resume and continue",
+			NewOptions().WithResume("abc").WithContinueConversation(),
+			[]string{"resume and continue conversation cannot be used together"},
+		},
+		{"empty resume", NewOptions().WithResume(""), []string{"resume requires a session ID"}},
+		{
+			"fork without resume",
+			NewOptions().WithForkSession(true),
+			[]string{"fork session requires resume or continue conversation"},
+		},
+		{"fallback model", NewOptions().WithModel("opus").WithFallbackModel("sonnet"), nil},
+		{
+			"fallback model same as model",
+			NewOptions().WithModel("opus").WithFallbackModel("opus"),
+			[]string{"fallback model must differ from the main model"},
+		},
+		{"invalid fallback model", NewOptions().WithFallbackModel("a b"), []string{`invalid fallback model name "a b"`}},
+		{"unknown models", NewOptions().WithModel("claude-sonnet-9").WithFallbackModel("claude-haiku-9"), nil},
+		{
+			"strict unknown models",
+			NewOptions().WithModel("claude-sonnet-9").WithFallbackModel("claude-haiku-9").WithStrictModels(true),
+			[]string{
+				`unknown model "claude-sonnet-9"; register it with models.Register or unset strict models`,
+				`unknown fallback model "claude-haiku-9"; register it with models.Register or unset strict models`,
+			},
+		},
+		{"provider model ID", NewOptions().WithModel("us.anthropic.claude-sonnet-4-5-20250929-v1:0"), nil},
+		{"empty output style", NewOptions().WithOutputStyle(" "), []string{"output style requires a name"}},
+		{"extra args", NewOptions().WithExtraArgs(map[string]*string{"--betas": nil, "debug": nil}), nil},
+		{
+			"invalid extra flag",
+			NewOptions().WithExtraArgs(map[string]*string{"-x": nil}),
+			[]string{`invalid extra flag name "-x"`},
+		},
+		{
+			"permission rules",
+			NewOptions().AllowTool("Bash", WithArgPattern("npm run test:*")).DenyTool("WebFetch", WithDomain("evil.example")),
+			nil,
+		},
+		{
+			"malformed permission rules",
+			NewOptions().WithAllowedTools("Bash(ls", "Read(a,b)").WithDisallowedTools("9tool"),
+			[]string{
+				`permission rule "Bash(ls" is missing a closing parenthesis`,
+				"permission rule Read(a,b) cannot contain commas or newlines",
+				`invalid tool name "9tool" in permission rule`,
+			},
+		},
+		{"model with spaces", NewOptions().WithModel("claude sonnet"), []string{`invalid model name "claude sonnet"`}},
+		{
+			"missing cwd",
+			NewOptions().WithCwd(filepath.Join(dir, "missing")),
+			[]string{`working directory "` + filepath.Join(dir, "missing") + `" does not exist`},
+		},
+		{"MCP config file", NewOptions().WithCwd(dir).WithMcpConfigFile(".mcp.json"), nil},
+		{
+			"bad MCP config files",
+			NewOptions().WithCwd(dir).WithMcpConfigFile("missing.json").WithMcpConfigFile(filepath.Join(dir, "bad.json")),
+			[]string{
+				`MCP config file "missing.json": file does not exist`,
+				`MCP config file "` + filepath.Join(dir, "bad.json") + `": MCP server "x": unknown server type "ws"`,
+			},
+		},
+		{"cwd is a file", NewOptions().WithCwd(file), []string{`working directory "` + file + `" is not a directory`}},
+		{
+			"several problems",
+			NewOptions().WithMaxTurns(-2).WithModel(""),
+			[]string{"max turns must not be negative, got -2", `invalid model name ""`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.problems == nil {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var usageErr *UsageError
+			if !errors.As(err, &usageErr) {
+				t.Fatalf("Expected *UsageError, got %v", err)
+			}
+			if !reflect.DeepEqual(usageErr.Problems, tt.problems) {
+				t.Errorf("Expected problems %q, got %q", tt.problems, usageErr.Problems)
+			}
+			if ErrorKind(err) != KindUsage {
+				t.Errorf("Expected KindUsage, got %v", ErrorKind(err))
+			}
+		})
+	}
+}
+
+func TestOptionsValidateMessage(t *testing.T) {
+	err := NewOptions().WithMaxTurns(-1).Validate()
+	if got := err.Error(); got != "invalid usage: max turns must not be negative, got -1" {
+		t.Errorf("Unexpected message for one problem: %q", got)
+	}
+
+	err = NewOptions().WithMaxTurns(-1).WithResume("a").WithContinueConversation().Validate()
+	want := "invalid usage: invalid options: max turns must not be negative, got -1; resume and continue conversation cannot be used together"
+	if got := err.Error(); got != want {
+		t.Errorf("Unexpected message for several problems: %q", got)
+	}
+}
+
+func TestOptionsWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *Options
+		warnings []string
+	}{
+		{"known models", NewOptions().WithModel("opus").WithFallbackModel("sonnet"), nil},
+		{"provider model ID", NewOptions().WithModel("us.anthropic.claude-sonnet-4-5-20250929-v1:0"), nil},
+		{
+			"unknown models",
+			NewOptions().WithModel("claude-sonnet-9").WithFallbackModel("claude-haiku-9"),
+			[]string{
+				`unknown model "claude-sonnet-9", which may be newer than this SDK`,
+				`unknown fallback model "claude-haiku-9", which may be newer than this SDK`,
+			},
+		},
+		{"strict models", NewOptions().WithModel("claude-sonnet-9").WithStrictModels(true), nil},
+		{"invalid model", NewOptions().WithModel("a b"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Warnings(); !reflect.DeepEqual(got, tt.warnings) {
+				t.Errorf("Expected warnings %q, got %q", tt.warnings, got)
+			}
+		})
+	}
+}
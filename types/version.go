@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version is a Claude Code CLI version number.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion extracts the first major.minor.patch version number from s,
+// such as the output of "claude --version".
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("no version number found in %q", s)
+	}
+
+	var parts [3]int
+	for i := range parts {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version number %q: %w", match[0], err)
+		}
+		parts[i] = n
+	}
+	return Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}, nil
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0, or 1 depending on whether v is older than, the
+// same as, or newer than other.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.Major != other.Major:
+		return compareInts(v.Major, other.Major)
+	case v.Minor != other.Minor:
+		return compareInts(v.Minor, other.Minor)
+	default:
+		return compareInts(v.Patch, other.Patch)
+	}
+}
+
+// AtLeast reports whether v is the same as or newer than other.
+func (v Version) AtLeast(other Version) bool {
+	return v.Compare(other) >= 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// VersionCheck controls what happens when the installed CLI is older than
+// the options of a query require.
+type VersionCheck string
+
+const (
+	// VersionCheckWarn runs the query anyway and delivers a SystemMessage
+	// with subtype SystemSubtypeWarning describing the problem.
+	VersionCheckWarn VersionCheck = "warn"
+
+	// VersionCheckError fails the query with a CLIVersionError.
+	VersionCheckError VersionCheck = "error"
+)
+
+// SystemSubtypeWarning is the subtype of SystemMessages generated by the
+// SDK, rather than the CLI, to report non-fatal problems with a query. The
+// message text is in Data["message"].
+const SystemSubtypeWarning = "sdk_warning"
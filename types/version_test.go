@@ -0,0 +1,48 @@
+package types
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		wantErr  bool
+	}{
+		{input: "1.0.51 (Claude Code)\n", expected: Version{1, 0, 51}},
+		{input: "2.0.0", expected: Version{2, 0, 0}},
+		{input: "claude v10.2.3-beta", expected: Version{10, 2, 3}},
+		{input: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b     Version
+		expected int
+	}{
+		{Version{1, 0, 0}, Version{1, 0, 0}, 0},
+		{Version{1, 0, 9}, Version{1, 0, 10}, -1},
+		{Version{1, 2, 0}, Version{1, 1, 99}, 1},
+		{Version{0, 9, 9}, Version{1, 0, 0}, -1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.expected {
+			t.Errorf("%v.Compare(%v): expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+		if got := tt.a.AtLeast(tt.b); got != (tt.expected >= 0) {
+			t.Errorf("%v.AtLeast(%v): expected %v, got %v", tt.a, tt.b, tt.expected >= 0, got)
+		}
+	}
+}